@@ -0,0 +1,910 @@
+// Package backup snapshots and restores a Gas Town's configuration and state.
+//
+// A backup is a single gzipped tarball (optionally encrypted) holding the
+// town's configuration files, each rig's settings and runtime state, and the
+// Dolt databases. Git worktrees are not archived: the manifest records each
+// rig's remote and crew branches so restore can rebuild them from git.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// FormatVersion is the archive layout version written by Create. Restore
+// refuses archives from a newer format.
+const FormatVersion = 1
+
+const (
+	manifestEntry = "manifest.json"
+	townPrefix    = "town/"
+	doltPrefix    = "dolt/"
+	gitPrefix     = "git/"
+
+	archivePrefix = "gt-backup-"
+	archiveExt    = ".tar.gz"
+	encryptedExt  = ".tar.gz.enc"
+	checksumExt   = ".sha256"
+	timeLayout    = "20060102T150405Z"
+)
+
+// Database formats recorded in the manifest.
+const (
+	// FormatDoltBackup is a Dolt backup taken from the running server.
+	FormatDoltBackup = "dolt-backup"
+	// FormatDir is a copy of a stopped database's directory.
+	FormatDir = "dir"
+)
+
+// Archive kinds recorded in the manifest.
+const (
+	// KindBackup is a town backup (gt backup create).
+	KindBackup = "backup"
+	// KindTown is a town export for moving to another machine.
+	KindTown = "town"
+	// KindRig is a single-rig export.
+	KindRig = "rig"
+)
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	Version   int        `json:"version"`
+	Kind      string     `json:"kind,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	GTVersion string     `json:"gt_version,omitempty"`
+	Town      string     `json:"town"`
+	Root      string     `json:"root,omitempty"` // Town root on the source machine
+	Home      string     `json:"home,omitempty"` // Home directory on the source machine
+	Encrypted bool       `json:"encrypted"`
+	Rigs      []Rig      `json:"rigs"`
+	Databases []Database `json:"databases,omitempty"`
+	Files     []File     `json:"files"`
+	Warnings  []string   `json:"warnings,omitempty"`
+}
+
+// Rig records what restore needs to register a rig and rebuild its git
+// checkouts.
+type Rig struct {
+	Name          string              `json:"name"`
+	GitURL        string              `json:"git_url"`
+	LocalRepo     string              `json:"local_repo,omitempty"`
+	DefaultBranch string              `json:"default_branch,omitempty"`
+	Beads         *config.BeadsConfig `json:"beads,omitempty"`
+	Routes        []beads.Route       `json:"routes,omitempty"`
+	Database      string              `json:"database,omitempty"`
+	Crew          []Crew              `json:"crew,omitempty"`
+	Polecats      []Polecat           `json:"polecats,omitempty"`
+
+	// Bundle is the archive entry holding the rig repo's unpushed
+	// branches and WIP snapshot refs (exports only).
+	Bundle string `json:"bundle,omitempty"`
+}
+
+// Crew records a crew workspace and the branch it was on.
+type Crew struct {
+	Name   string `json:"name"`
+	Branch string `json:"branch,omitempty"`
+
+	// Bundle is the archive entry holding the clone's unpushed work, and
+	// Snapshot the WIP ref in it with uncommitted changes (exports only).
+	Bundle   string `json:"bundle,omitempty"`
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// Polecat records an in-flight polecat worktree (exports only).
+type Polecat struct {
+	Name       string `json:"name"`
+	Branch     string `json:"branch"`
+	HookedBead string `json:"hooked_bead,omitempty"`
+
+	// Snapshot is the WIP ref (in the rig bundle) holding the worktree's
+	// uncommitted changes, if it had any.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// Database is a Dolt database stored under dolt/<name>/ in the archive.
+type Database struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+}
+
+// File is one archived file with its checksum.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Mode   uint32 `json:"mode"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive is a backup file found on disk.
+type Archive struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+	Encrypted bool      `json:"encrypted"`
+}
+
+// CreateOptions configures Create.
+type CreateOptions struct {
+	Dir        string    // Destination directory for the archive
+	Output     string    // Exact archive path (overrides Dir and the generated name)
+	Passphrase string    // Encrypt the archive when set
+	GTVersion  string    // Recorded in the manifest
+	SkipDolt   bool      // Don't include Dolt databases
+	Now        time.Time // Archive timestamp (defaults to time.Now)
+
+	// Kind selects what is archived: a backup (the default), a town
+	// export, or a single-rig export (Rig). Exports also carry in-flight
+	// git work: unpushed branches and uncommitted changes of polecat and
+	// crew checkouts.
+	Kind string
+	Rig  string
+}
+
+// Town-level directories archived recursively.
+var townDirs = []string{"settings", "config", "roles", "plugins", ".beads"}
+
+// Rig-level paths archived recursively (directories) or as single files.
+var rigPaths = []string{
+	"settings", "roles", "plugins", ".beads",
+	"mayor/rig/.beads",
+	".runtime/overlay",
+	".runtime/namepool-state.json",
+}
+
+// rigSecretsStore is the rig's secrets store, sealed with the town master
+// key. It only travels with the town it belongs to.
+const rigSecretsStore = ".runtime/secrets.enc"
+
+// Beads subdirectories and file suffixes that hold database or process
+// state rather than configuration. Their contents live in Dolt.
+var (
+	skipBeadsDirs     = map[string]bool{"dolt": true, ".dolt": true, "embeddeddolt": true, "backup": true}
+	skipFileSuffixes  = []string{".db", ".db-wal", ".db-shm", ".db-journal", ".sock", ".lock", ".pid", ".log"}
+	skipDoltDirSuffix = []string{".lock"}
+)
+
+// DefaultDir returns the default backup directory for a town:
+// ~/.gt/backups/<town>.
+func DefaultDir(townRoot string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(townRoot, ".backups")
+	}
+	return filepath.Join(home, ".gt", "backups", townName(townRoot))
+}
+
+// Create writes a new backup of the town into opts.Dir, along with a
+// sha256sum-style checksum file, and returns the archive and its manifest.
+func Create(townRoot string, opts CreateOptions) (*Archive, *Manifest, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC().Truncate(time.Second)
+	if opts.Kind == "" {
+		opts.Kind = KindBackup
+	}
+
+	m := &Manifest{
+		Version:   FormatVersion,
+		Kind:      opts.Kind,
+		CreatedAt: now,
+		GTVersion: opts.GTVersion,
+		Town:      townName(townRoot),
+		Root:      townRoot,
+		Encrypted: opts.Passphrase != "",
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		m.Home = home
+	}
+
+	rigsCfg, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+
+	spec := archiveSpec{
+		passphrase: opts.Passphrase,
+		skipDolt:   opts.SkipDolt,
+		town:       opts.Kind != KindRig,
+		rigs:       rigsCfg.Rigs,
+		worktrees:  opts.Kind != KindBackup,
+	}
+	label, prefix := m.Town, archivePrefix
+	switch opts.Kind {
+	case KindBackup:
+	case KindTown:
+		prefix = "gt-town-"
+	case KindRig:
+		entry, ok := rigsCfg.Rigs[opts.Rig]
+		if !ok {
+			return nil, nil, fmt.Errorf("rig %q not found", opts.Rig)
+		}
+		spec.rigs = map[string]config.RigEntry{opts.Rig: entry}
+		spec.databases = map[string]bool{rigDatabase(townRoot, opts.Rig): true}
+		label, prefix = opts.Rig, "gt-rig-"
+	default:
+		return nil, nil, fmt.Errorf("unknown archive kind %q", opts.Kind)
+	}
+
+	name := prefix + sanitize(label) + "-" + now.Format(timeLayout) + archiveExt
+	if m.Encrypted {
+		name = prefix + sanitize(label) + "-" + now.Format(timeLayout) + encryptedExt
+	}
+	dir := opts.Dir
+	if opts.Output != "" {
+		dir, name = filepath.Split(opts.Output)
+		if dir == "" {
+			dir = "."
+		}
+	} else if dir == "" {
+		dir = DefaultDir(townRoot)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, fmt.Errorf("creating backup dir: %w", err)
+	}
+	finalPath := filepath.Join(dir, name)
+
+	tmp, err := os.CreateTemp(dir, ".tmp-"+name+"-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	sum := sha256.New()
+	if err := writeArchive(io.MultiWriter(tmp, sum), townRoot, m, spec); err != nil {
+		_ = tmp.Close()
+		return nil, nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return nil, nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, nil, err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return nil, nil, fmt.Errorf("finalizing archive: %w", err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum.Sum(nil)), name)
+	if err := os.WriteFile(finalPath+checksumExt, []byte(checksum), 0600); err != nil {
+		return nil, nil, fmt.Errorf("writing checksum: %w", err)
+	}
+
+	info, err := os.Stat(finalPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Archive{Path: finalPath, CreatedAt: now, Size: info.Size(), Encrypted: m.Encrypted}, m, nil
+}
+
+// archiveSpec selects what writeArchive includes.
+type archiveSpec struct {
+	passphrase string
+	skipDolt   bool
+	town       bool                       // Town-level files
+	rigs       map[string]config.RigEntry // Rigs to include
+	databases  map[string]bool            // Only these databases (nil = all)
+	worktrees  bool                       // In-flight git work of polecats and crew
+}
+
+// writeArchive streams the tarball (through encryption when requested) to w.
+// The manifest is written last so it can carry every file's checksum.
+func writeArchive(w io.Writer, townRoot string, m *Manifest, spec archiveSpec) error {
+	var enc *encryptWriter
+	if spec.passphrase != "" {
+		var err error
+		if enc, err = newEncryptWriter(w, spec.passphrase); err != nil {
+			return fmt.Errorf("initializing encryption: %w", err)
+		}
+		w = enc
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	aw := &archiveWriter{tw: tw, m: m}
+
+	if spec.town {
+		if err := collectTown(aw, townRoot, spec.passphrase != ""); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(spec.rigs))
+	for name := range spec.rigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := collectRig(aw, townRoot, name, spec.rigs[name], spec.town, spec.worktrees); err != nil {
+			return err
+		}
+	}
+
+	if !spec.skipDolt {
+		if err := collectDolt(aw, townRoot, spec.databases); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name: manifestEntry, Mode: 0600, Size: int64(len(data)),
+		ModTime: m.CreatedAt, Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if enc != nil {
+		return enc.Close()
+	}
+	return nil
+}
+
+// archiveWriter adds files to the tarball and records them in the manifest.
+type archiveWriter struct {
+	tw *tar.Writer
+	m  *Manifest
+}
+
+func (a *archiveWriter) addFile(src, name string) error {
+	f, err := os.Open(src) //nolint:gosec // G304: src is a path inside the town being backed up
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	mode := uint32(info.Mode().Perm())
+	if err := a.tw.WriteHeader(&tar.Header{
+		Name: name, Mode: int64(mode), Size: info.Size(),
+		ModTime: info.ModTime(), Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(a.tw, h), f)
+	if err != nil {
+		return fmt.Errorf("archiving %s: %w", src, err)
+	}
+	if n != info.Size() {
+		return fmt.Errorf("archiving %s: file changed size during backup", src)
+	}
+	a.m.Files = append(a.m.Files, File{Path: name, Size: n, Mode: mode, SHA256: hex.EncodeToString(h.Sum(nil))})
+	return nil
+}
+
+// addTree archives the regular files under src (a file or directory) as
+// prefix/<relative path>. skip filters entries by base name; missing
+// sources are ignored.
+func (a *archiveWriter) addTree(src, prefix string, skip func(name string, dir bool) bool) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Mode().IsRegular() {
+		return a.addFile(src, prefix)
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == src {
+			return nil
+		}
+		if skip != nil && skip(d.Name(), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil // Directories are implied; symlinks and sockets aren't archived
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return a.addFile(p, path.Join(prefix, filepath.ToSlash(rel)))
+	})
+}
+
+func collectTown(a *archiveWriter, townRoot string, encrypted bool) error {
+	// Top-level files (CLAUDE.md, AGENTS.md, ...) and mayor/*.json.
+	for _, dir := range []string{"", "mayor"} {
+		entries, err := os.ReadDir(filepath.Join(townRoot, dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if !e.Type().IsRegular() || skipFile(e.Name()) {
+				continue
+			}
+			if err := a.addFile(filepath.Join(townRoot, dir, e.Name()), townPrefix+path.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, dir := range townDirs {
+		if err := a.addTree(filepath.Join(townRoot, dir), townPrefix+dir, skipState); err != nil {
+			return err
+		}
+	}
+
+	// Town secrets: the encrypted store is always safe to archive, but the
+	// master key only goes into encrypted backups.
+	secretsDir := filepath.Join(townRoot, ".runtime", "secrets")
+	err := a.addTree(secretsDir, townPrefix+".runtime/secrets", func(name string, dir bool) bool {
+		return !dir && name == "master.key" && !encrypted
+	})
+	if err != nil {
+		return err
+	}
+	if !encrypted {
+		if _, err := os.Stat(filepath.Join(secretsDir, "master.key")); err == nil {
+			a.m.Warnings = append(a.m.Warnings,
+				"secrets master key not included (backup is not encrypted); keep a copy of .runtime/secrets/master.key")
+		}
+	}
+	return nil
+}
+
+func collectRig(a *archiveWriter, townRoot, name string, entry config.RigEntry, town, worktrees bool) error {
+	rigPath := filepath.Join(townRoot, name)
+	info := Rig{
+		Name:      name,
+		GitURL:    entry.GitURL,
+		LocalRepo: entry.LocalRepo,
+		Beads:     entry.BeadsConfig,
+		Database:  rigDatabase(townRoot, name),
+	}
+	if cfg, err := rig.LoadRigConfig(rigPath); err == nil {
+		info.DefaultBranch = cfg.DefaultBranch
+	}
+	if routes, err := beads.LoadRoutes(filepath.Join(townRoot, ".beads")); err == nil {
+		for _, r := range routes {
+			if r.Path == name || strings.HasPrefix(r.Path, name+"/") {
+				info.Routes = append(info.Routes, r)
+			}
+		}
+	}
+
+	// Top-level rig files (config.json, ...).
+	entries, err := os.ReadDir(rigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			a.m.Warnings = append(a.m.Warnings, fmt.Sprintf("rig %s is registered but its directory is missing", name))
+			a.m.Rigs = append(a.m.Rigs, info)
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || skipFile(e.Name()) {
+			continue
+		}
+		if err := a.addFile(filepath.Join(rigPath, e.Name()), townPrefix+path.Join(name, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range rigPaths {
+		src := filepath.Join(rigPath, filepath.FromSlash(p))
+		if err := a.addTree(src, townPrefix+path.Join(name, p), skipState); err != nil {
+			return err
+		}
+	}
+
+	// A rig export lands in another town with a different master key, so
+	// its secrets store couldn't be opened there.
+	secretsSrc := filepath.Join(rigPath, filepath.FromSlash(rigSecretsStore))
+	if town {
+		if err := a.addTree(secretsSrc, townPrefix+path.Join(name, rigSecretsStore), nil); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(secretsSrc); err == nil {
+		a.m.Warnings = append(a.m.Warnings, fmt.Sprintf(
+			"rig %s secrets not exported (sealed with this town's master key); re-add them with gt secrets set --rig %s after importing", name, name))
+	}
+
+	crewEntries, _ := os.ReadDir(filepath.Join(rigPath, "crew"))
+	for _, e := range crewEntries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		c := Crew{Name: e.Name()}
+		if branch, err := git.NewGit(filepath.Join(rigPath, "crew", e.Name())).CurrentBranch(); err == nil {
+			c.Branch = branch
+		}
+		info.Crew = append(info.Crew, c)
+	}
+
+	if worktrees {
+		if err := collectGitState(a, rigPath, &info); err != nil {
+			return fmt.Errorf("capturing git work for rig %s: %w", name, err)
+		}
+	}
+
+	a.m.Rigs = append(a.m.Rigs, info)
+	return nil
+}
+
+// collectDolt archives every database. A running local server is backed up
+// through DOLT_BACKUP so the copy is consistent; a stopped server's data
+// directories are copied as-is.
+func collectDolt(a *archiveWriter, townRoot string, only map[string]bool) error {
+	cfg := doltserver.DefaultConfig(townRoot)
+	if cfg.IsRemote() {
+		a.m.Warnings = append(a.m.Warnings,
+			fmt.Sprintf("Dolt server %s is remote; databases not included", cfg.HostPort()))
+		return nil
+	}
+	dbs, err := doltserver.ListDatabases(townRoot)
+	if err != nil {
+		return fmt.Errorf("listing databases: %w", err)
+	}
+	if len(dbs) == 0 {
+		return nil
+	}
+	running, _, _ := doltserver.IsRunning(townRoot)
+
+	for _, db := range dbs {
+		if only != nil && !only[db] {
+			continue
+		}
+		prefix := doltPrefix + db
+		if !running {
+			skip := func(name string, dir bool) bool { return !dir && hasAnySuffix(name, skipDoltDirSuffix) }
+			if err := a.addTree(doltserver.RigDatabaseDir(townRoot, db), prefix, skip); err != nil {
+				return fmt.Errorf("archiving database %s: %w", db, err)
+			}
+			a.m.Databases = append(a.m.Databases, Database{Name: db, Format: FormatDir})
+			continue
+		}
+
+		staging, err := os.MkdirTemp("", "gt-backup-"+db+"-*")
+		if err != nil {
+			return err
+		}
+		err = doltserver.SyncDatabaseBackup(townRoot, db, staging)
+		if err == nil {
+			err = a.addTree(staging, prefix, nil)
+		}
+		_ = os.RemoveAll(staging)
+		if err != nil {
+			return fmt.Errorf("archiving database %s: %w", db, err)
+		}
+		a.m.Databases = append(a.m.Databases, Database{Name: db, Format: FormatDoltBackup})
+	}
+	return nil
+}
+
+// skipState filters database and process state out of archived trees.
+func skipState(name string, dir bool) bool {
+	if dir {
+		return skipBeadsDirs[name]
+	}
+	return skipFile(name)
+}
+
+func skipFile(name string) bool {
+	return hasAnySuffix(name, skipFileSuffixes)
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// townName returns the town's configured name, falling back to the
+// directory name.
+func townName(townRoot string) string {
+	if cfg, err := config.LoadTownConfig(filepath.Join(townRoot, "mayor", "town.json")); err == nil && cfg.Name != "" {
+		return cfg.Name
+	}
+	return filepath.Base(townRoot)
+}
+
+// sanitize makes a town name safe for use in a file name.
+func sanitize(name string) string {
+	out := []rune(name)
+	for i, r := range out {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			out[i] = '_'
+		}
+	}
+	if len(out) == 0 {
+		return "town"
+	}
+	return string(out)
+}
+
+// List returns the backups in dir, newest first.
+func List(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var archives []Archive
+	for _, e := range entries {
+		created, encrypted, ok := parseArchiveName(e.Name())
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, Archive{
+			Path:      filepath.Join(dir, e.Name()),
+			CreatedAt: created,
+			Size:      info.Size(),
+			Encrypted: encrypted,
+		})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].CreatedAt.After(archives[j].CreatedAt)
+	})
+	return archives, nil
+}
+
+// parseArchiveName extracts the timestamp from an archive file name.
+func parseArchiveName(name string) (time.Time, bool, bool) {
+	if !strings.HasPrefix(name, archivePrefix) {
+		return time.Time{}, false, false
+	}
+	var stem string
+	var encrypted bool
+	switch {
+	case strings.HasSuffix(name, encryptedExt):
+		stem, encrypted = strings.TrimSuffix(name, encryptedExt), true
+	case strings.HasSuffix(name, archiveExt):
+		stem = strings.TrimSuffix(name, archiveExt)
+	default:
+		return time.Time{}, false, false
+	}
+	i := strings.LastIndex(stem, "-")
+	if i < 0 {
+		return time.Time{}, false, false
+	}
+	t, err := time.Parse(timeLayout, stem[i+1:])
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	return t, encrypted, true
+}
+
+// Retention decides which backups Prune keeps. Zero values disable a limit.
+type Retention struct {
+	Keep   int           // Keep at most this many backups
+	MaxAge time.Duration // Remove backups older than this
+}
+
+// Prune removes backups in dir that fall outside the retention policy and
+// returns the removed paths. The newest backup is always kept.
+func Prune(dir string, r Retention, now time.Time) ([]string, error) {
+	archives, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for i, a := range archives {
+		if i == 0 {
+			continue
+		}
+		tooMany := r.Keep > 0 && i >= r.Keep
+		tooOld := r.MaxAge > 0 && now.Sub(a.CreatedAt) > r.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(a.Path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		_ = os.Remove(a.Path + checksumExt)
+		removed = append(removed, a.Path)
+	}
+	return removed, nil
+}
+
+// Verify checks an archive end to end: the checksum file (when present),
+// decryption, and every file against the manifest. It returns the manifest.
+func Verify(archivePath, passphrase string) (*Manifest, error) {
+	if err := verifyChecksumFile(archivePath); err != nil {
+		return nil, err
+	}
+	return readArchive(archivePath, passphrase, "")
+}
+
+// ErrChecksumMismatch is returned when an archive doesn't match its
+// checksum file or a file doesn't match the manifest.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+func verifyChecksumFile(archivePath string) error {
+	data, err := os.ReadFile(archivePath + checksumExt) //nolint:gosec // G304: sidecar of a user-supplied archive
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: empty checksum file", archivePath+checksumExt)
+	}
+	f, err := os.Open(archivePath) //nolint:gosec // G304: user-supplied archive path
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != fields[0] {
+		return fmt.Errorf("%w: %s does not match %s", ErrChecksumMismatch, filepath.Base(archivePath), filepath.Base(archivePath)+checksumExt)
+	}
+	return nil
+}
+
+// readArchive reads every entry, checking each against the manifest. When
+// dest is set, files are extracted beneath it.
+func readArchive(archivePath, passphrase, dest string) (*Manifest, error) {
+	f, err := os.Open(archivePath) //nolint:gosec // G304: user-supplied archive path
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if isEncrypted(br) {
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		if r, err = newDecryptReader(br, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		if errors.Is(err, ErrDecrypt) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	seen := make(map[string]File)
+	var m *Manifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unexpected archive entry %q", hdr.Name)
+		}
+		if hdr.Name == manifestEntry {
+			m = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return nil, fmt.Errorf("reading manifest: %w", err)
+			}
+			continue
+		}
+		if !validEntryName(hdr.Name) {
+			return nil, fmt.Errorf("unsafe archive entry %q", hdr.Name)
+		}
+
+		var w io.Writer = io.Discard
+		var out *os.File
+		if dest != "" {
+			target := filepath.Join(dest, filepath.FromSlash(hdr.Name))
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return nil, err
+			}
+			if out, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil { //nolint:gosec // G304: validated entry under dest
+				return nil, err
+			}
+			w = out
+		}
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(w, h), tr)
+		if out != nil {
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		seen[hdr.Name] = File{Path: hdr.Name, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}
+	}
+	if m == nil {
+		return nil, errors.New("archive has no manifest")
+	}
+	if m.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format version %d is newer than this gt supports (%d); upgrade gt", m.Version, FormatVersion)
+	}
+
+	for _, want := range m.Files {
+		got, ok := seen[want.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing from the archive", ErrChecksumMismatch, want.Path)
+		}
+		if got.Size != want.Size || got.SHA256 != want.SHA256 {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, want.Path)
+		}
+		delete(seen, want.Path)
+	}
+	for name := range seen {
+		return nil, fmt.Errorf("%w: %s is not in the manifest", ErrChecksumMismatch, name)
+	}
+	return m, nil
+}
+
+// validEntryName rejects absolute paths and parent traversal.
+func validEntryName(name string) bool {
+	if !strings.HasPrefix(name, townPrefix) && !strings.HasPrefix(name, doltPrefix) && !strings.HasPrefix(name, gitPrefix) {
+		return false
+	}
+	clean := path.Clean(name)
+	return clean == name && !path.IsAbs(clean) && !strings.HasPrefix(clean, "../") && !strings.Contains(clean, "/../")
+}
@@ -0,0 +1,522 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func init() {
+	pbkdf2Iterations = 1000
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t",
+		"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupTown builds a minimal town with one rig whose remote is a local bare
+// repo, and returns the town root.
+func setupTown(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GT_DOLT_HOST", "")
+	base := t.TempDir()
+
+	src := filepath.Join(base, "src")
+	writeFile(t, filepath.Join(src, "README.md"), "hello\n")
+	runGit(t, base, "init", "-q", "-b", "main", src)
+	runGit(t, src, "add", ".")
+	runGit(t, src, "commit", "-q", "-m", "init")
+	remote := filepath.Join(base, "remote.git")
+	runGit(t, base, "clone", "-q", "--bare", src, remote)
+
+	town := filepath.Join(base, "town")
+	writeFile(t, filepath.Join(town, "mayor", "town.json"), `{"type":"town","version":2,"name":"testtown"}`)
+	rigs := map[string]any{
+		"version": 1,
+		"rigs":    map[string]any{"demo": map[string]any{"git_url": remote}},
+	}
+	data, _ := json.Marshal(rigs)
+	writeFile(t, filepath.Join(town, "mayor", "rigs.json"), string(data))
+	writeFile(t, filepath.Join(town, "CLAUDE.md"), "# town\n")
+	writeFile(t, filepath.Join(town, "settings", "config.json"), `{"type":"town-settings"}`)
+	writeFile(t, filepath.Join(town, "roles", "polecat.toml"), "role = \"polecat\"\n")
+	writeFile(t, filepath.Join(town, ".beads", "formulas", "release.formula.toml"), "formula = \"release\"\n")
+	writeFile(t, filepath.Join(town, ".beads", "beads.db"), "database")
+	writeFile(t, filepath.Join(town, ".runtime", "secrets", "town.enc"), "sealed")
+	writeFile(t, filepath.Join(town, ".runtime", "secrets", "master.key"), "key")
+
+	rigPath := filepath.Join(town, "demo")
+	writeFile(t, filepath.Join(rigPath, "config.json"), `{"type":"rig","version":1,"name":"demo","default_branch":"main"}`)
+	writeFile(t, filepath.Join(rigPath, "settings", "config.json"), `{"type":"rig-settings"}`)
+	writeFile(t, filepath.Join(rigPath, ".runtime", "overlay", ".env"), "TOKEN=1\n")
+	writeFile(t, filepath.Join(rigPath, ".runtime", "namepool-state.json"), `{"in_use":[]}`)
+	writeFile(t, filepath.Join(rigPath, ".runtime", "secrets.enc"), "sealed")
+	writeFile(t, filepath.Join(rigPath, ".beads", "config.yaml"), "prefix: dm\n")
+	writeFile(t, filepath.Join(rigPath, ".beads", "dolt", "noms", "x"), "db state")
+	writeFile(t, filepath.Join(rigPath, "refinery", "rig", "scratch.txt"), "worktree file")
+	return town
+}
+
+func manifestPaths(m *Manifest) map[string]bool {
+	paths := make(map[string]bool)
+	for _, f := range m.Files {
+		paths[f.Path] = true
+	}
+	return paths
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	town := setupTown(t)
+	dir := t.TempDir()
+
+	archive, m, err := Create(town, CreateOptions{Dir: dir, GTVersion: "1.2.3"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(archive.Path), "gt-backup-testtown-") || archive.Encrypted {
+		t.Errorf("unexpected archive %+v", archive)
+	}
+	if _, err := os.Stat(archive.Path + checksumExt); err != nil {
+		t.Errorf("checksum file missing: %v", err)
+	}
+
+	paths := manifestPaths(m)
+	for _, want := range []string{
+		"town/mayor/town.json",
+		"town/mayor/rigs.json",
+		"town/CLAUDE.md",
+		"town/settings/config.json",
+		"town/roles/polecat.toml",
+		"town/.beads/formulas/release.formula.toml",
+		"town/.runtime/secrets/town.enc",
+		"town/demo/config.json",
+		"town/demo/settings/config.json",
+		"town/demo/.runtime/overlay/.env",
+		"town/demo/.runtime/namepool-state.json",
+		"town/demo/.runtime/secrets.enc",
+		"town/demo/.beads/config.yaml",
+	} {
+		if !paths[want] {
+			t.Errorf("manifest missing %s", want)
+		}
+	}
+	for _, unwanted := range []string{
+		"town/.beads/beads.db",
+		"town/.runtime/secrets/master.key",
+		"town/demo/.beads/dolt/noms/x",
+		"town/demo/refinery/rig/scratch.txt",
+	} {
+		if paths[unwanted] {
+			t.Errorf("manifest should not include %s", unwanted)
+		}
+	}
+	if len(m.Warnings) == 0 {
+		t.Error("expected a warning about the unarchived master key")
+	}
+	if len(m.Rigs) != 1 || m.Rigs[0].Name != "demo" || m.Rigs[0].DefaultBranch != "main" {
+		t.Errorf("unexpected rigs %+v", m.Rigs)
+	}
+
+	verified, err := Verify(archive.Path, "")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.GTVersion != "1.2.3" || len(verified.Files) != len(m.Files) {
+		t.Errorf("verified manifest = %+v", verified)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	town := setupTown(t)
+	archive, _, err := Create(town, CreateOptions{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(archive.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(archive.Path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(archive.Path, ""); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Verify = %v, want checksum mismatch", err)
+	}
+
+	// Without the sidecar the archive contents are still checked.
+	_ = os.Remove(archive.Path + checksumExt)
+	if _, err := Verify(archive.Path, ""); err == nil {
+		t.Error("Verify accepted a corrupted archive")
+	}
+}
+
+func TestEncryptedBackup(t *testing.T) {
+	town := setupTown(t)
+	archive, m, err := Create(town, CreateOptions{Dir: t.TempDir(), Passphrase: "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !archive.Encrypted || !strings.HasSuffix(archive.Path, encryptedExt) {
+		t.Errorf("archive not marked encrypted: %+v", archive)
+	}
+	if !manifestPaths(m)["town/.runtime/secrets/master.key"] {
+		t.Error("encrypted backup should include the master key")
+	}
+
+	data, _ := os.ReadFile(archive.Path)
+	if strings.Contains(string(data), "TOKEN=1") {
+		t.Error("archive contains plaintext")
+	}
+
+	if _, err := Verify(archive.Path, ""); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Verify without passphrase = %v", err)
+	}
+	if _, err := Verify(archive.Path, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Verify with wrong passphrase = %v", err)
+	}
+	if _, err := Verify(archive.Path, "hunter2"); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
+
+func TestDecryptRejectsExcessiveIterations(t *testing.T) {
+	header := append([]byte(encMagic), make([]byte, encSaltSize)...)
+	header = binary.BigEndian.AppendUint32(header, math.MaxUint32)
+	start := time.Now()
+	_, err := newDecryptReader(bufio.NewReader(bytes.NewReader(header)), "hunter2")
+	if !errors.Is(err, ErrDecrypt) {
+		t.Errorf("newDecryptReader = %v, want ErrDecrypt", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rejecting the header took %v; the work factor was not capped", elapsed)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	town := setupTown(t)
+	archive, _, err := Create(town, CreateOptions{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Restore(archive.Path, town, RestoreOptions{}); !errors.Is(err, ErrTownExists) {
+		t.Errorf("Restore over existing town = %v, want ErrTownExists", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "restored")
+	res, err := Restore(archive.Path, target, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", res.Warnings)
+	}
+	if len(res.Rigs) != 1 || res.Rigs[0] != "demo" {
+		t.Errorf("rebuilt rigs = %v", res.Rigs)
+	}
+
+	got, err := os.ReadFile(filepath.Join(target, "demo", ".runtime", "overlay", ".env"))
+	if err != nil || string(got) != "TOKEN=1\n" {
+		t.Errorf("overlay = %q, %v", got, err)
+	}
+	for _, p := range []string{
+		"mayor/rigs.json",
+		"roles/polecat.toml",
+		".beads/formulas/release.formula.toml",
+		"demo/.repo.git/HEAD",
+		"demo/mayor/rig/README.md",
+		"demo/refinery/rig/README.md",
+		"demo/refinery/rig/.env",
+	} {
+		if _, err := os.Stat(filepath.Join(target, p)); err != nil {
+			t.Errorf("restored town missing %s", p)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, "demo", "refinery", "rig", "scratch.txt")); err == nil {
+		t.Error("worktree contents should come from git, not the archive")
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		ts := now.Add(-time.Duration(i) * 24 * time.Hour).Format(timeLayout)
+		name := filepath.Join(dir, "gt-backup-town-"+ts+archiveExt)
+		writeFile(t, name, "x")
+		writeFile(t, name+checksumExt, "y")
+	}
+	writeFile(t, filepath.Join(dir, "unrelated.tar.gz"), "z")
+
+	archives, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 5 || !archives[0].CreatedAt.Equal(now) {
+		t.Fatalf("List = %+v", archives)
+	}
+
+	removed, err := Prune(dir, Retention{Keep: 3, MaxAge: 36 * time.Hour}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("removed %d archives, want 3", len(removed))
+	}
+	archives, _ = List(dir)
+	if len(archives) != 2 {
+		t.Errorf("%d archives left, want 2", len(archives))
+	}
+	if _, err := os.Stat(removed[0] + checksumExt); !os.IsNotExist(err) {
+		t.Error("checksum file not pruned")
+	}
+
+	// The newest backup survives any policy.
+	removed, _ = Prune(dir, Retention{MaxAge: time.Minute}, now.Add(time.Hour*24*30))
+	if len(removed) != 1 {
+		t.Errorf("removed %d, want 1", len(removed))
+	}
+}
+
+// addWorkers gives the demo rig a shared repo, a polecat with an unpushed
+// commit and an uncommitted change, and a crew clone with an unpushed commit.
+func addWorkers(t *testing.T, town string) {
+	t.Helper()
+	rigPath := filepath.Join(town, "demo")
+	remote := filepath.Join(filepath.Dir(town), "remote.git")
+	if err := git.NewGit(town).CloneBare(remote, filepath.Join(rigPath, ".repo.git")); err != nil {
+		t.Fatal(err)
+	}
+
+	wt := filepath.Join(rigPath, "polecats", "nux", "demo")
+	runGit(t, filepath.Join(rigPath, ".repo.git"), "worktree", "add", "-q", "-b", "polecat/nux-1", wt, "origin/main")
+	writeFile(t, filepath.Join(wt, "feature.txt"), "done\n")
+	runGit(t, wt, "add", "feature.txt")
+	runGit(t, wt, "commit", "-q", "-m", "feature")
+	writeFile(t, filepath.Join(wt, "README.md"), "hello, edited\n")
+
+	clone := filepath.Join(rigPath, "crew", "max")
+	runGit(t, town, "clone", "-q", remote, clone)
+	writeFile(t, filepath.Join(clone, "notes.txt"), "crew work\n")
+	runGit(t, clone, "add", "notes.txt")
+	runGit(t, clone, "commit", "-q", "-m", "notes")
+}
+
+func TestTownExportImport(t *testing.T) {
+	town := setupTown(t)
+	addWorkers(t, town)
+	writeFile(t, filepath.Join(town, "config", "paths.json"), `{"scripts":"`+town+`/scripts"}`)
+
+	archive, m, err := Create(town, CreateOptions{Dir: t.TempDir(), Kind: KindTown})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(archive.Path), "gt-town-") {
+		t.Errorf("archive name = %s", filepath.Base(archive.Path))
+	}
+	demo := m.Rigs[0]
+	if demo.Bundle == "" || len(demo.Polecats) != 1 || demo.Polecats[0].Snapshot == "" {
+		t.Fatalf("polecat work not captured: %+v", demo)
+	}
+	if len(demo.Crew) != 1 || demo.Crew[0].Bundle == "" {
+		t.Fatalf("crew work not captured: %+v", demo.Crew)
+	}
+
+	target := filepath.Join(t.TempDir(), "moved")
+	res, err := Restore(archive.Path, target, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(res.Polecats) != 1 || len(res.Crew) != 1 {
+		t.Fatalf("workers = %v / %v, warnings %v", res.Polecats, res.Crew, res.Warnings)
+	}
+
+	wt := filepath.Join(target, "demo", "polecats", "nux", "demo")
+	if got, _ := os.ReadFile(filepath.Join(wt, "feature.txt")); string(got) != "done\n" {
+		t.Errorf("unpushed polecat commit not restored: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, "README.md")); string(got) != "hello, edited\n" {
+		t.Errorf("uncommitted polecat change not restored: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(target, "demo", "crew", "max", "notes.txt")); string(got) != "crew work\n" {
+		t.Errorf("unpushed crew commit not restored: %q", got)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(target, "config", "paths.json"))
+	if !strings.Contains(string(got), target+"/scripts") || res.Rewritten == 0 {
+		t.Errorf("paths not rewritten: %s", got)
+	}
+}
+
+func TestRigExportImport(t *testing.T) {
+	town := setupTown(t)
+	addWorkers(t, town)
+	writeFile(t, filepath.Join(town, ".beads", "routes.jsonl"), `{"prefix":"dm-","path":"demo"}`+"\n")
+
+	archive, m, err := Create(town, CreateOptions{Dir: t.TempDir(), Kind: KindRig, Rig: "demo"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, f := range m.Files {
+		if !strings.HasPrefix(f.Path, "town/demo/") && !strings.HasPrefix(f.Path, gitPrefix) {
+			t.Errorf("rig export includes %s", f.Path)
+		}
+	}
+	if manifestPaths(m)["town/demo/.runtime/secrets.enc"] {
+		t.Error("rig export includes the rig secrets store sealed with this town's key")
+	}
+	if !strings.Contains(strings.Join(m.Warnings, "\n"), "gt secrets set --rig demo") {
+		t.Errorf("warnings = %v, want a note to re-add rig secrets", m.Warnings)
+	}
+
+	if _, err := Restore(archive.Path, filepath.Join(t.TempDir(), "x"), RestoreOptions{}); err == nil {
+		t.Error("Restore accepted a rig export")
+	}
+
+	other := filepath.Join(t.TempDir(), "other")
+	writeFile(t, filepath.Join(other, "mayor", "town.json"), `{"type":"town","version":2,"name":"other"}`)
+	writeFile(t, filepath.Join(other, "mayor", "rigs.json"), `{"version":1,"rigs":{}}`)
+	writeFile(t, filepath.Join(other, ".beads", "routes.jsonl"), `{"prefix":"hq-","path":"."}`+"\n")
+
+	res, err := ImportRig(archive.Path, other, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("ImportRig: %v", err)
+	}
+	if len(res.Polecats) != 1 {
+		t.Errorf("polecats = %v, warnings %v", res.Polecats, res.Warnings)
+	}
+	rigs, _ := os.ReadFile(filepath.Join(other, "mayor", "rigs.json"))
+	if !strings.Contains(string(rigs), `"demo"`) {
+		t.Errorf("rig not registered: %s", rigs)
+	}
+	routes, _ := os.ReadFile(filepath.Join(other, ".beads", "routes.jsonl"))
+	if !strings.Contains(string(routes), `"dm-"`) {
+		t.Errorf("route not added: %s", routes)
+	}
+
+	if _, err := ImportRig(archive.Path, other, RestoreOptions{}); err == nil {
+		t.Error("second import of the same rig should fail")
+	}
+}
+
+// renameManifestRig rewrites an unencrypted archive so its manifest carries
+// the rig name, as a hand-crafted archive could.
+func renameManifestRig(t *testing.T, archivePath, name string) {
+	t.Helper()
+	in, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	gr, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == manifestEntry {
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				t.Fatal(err)
+			}
+			m.Rigs[0].Name = name
+			if data, err = json.Marshal(&m); err != nil {
+				t.Fatal(err)
+			}
+			hdr.Size = int64(len(data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(archivePath + checksumExt)
+}
+
+func TestImportRigRejectsUnsafeName(t *testing.T) {
+	town := setupTown(t)
+	archive, _, err := Create(town, CreateOptions{Dir: t.TempDir(), Kind: KindRig, Rig: "demo"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, name := range []string{"../escape", "a/b", `a\b`, "..", "hq", "my-rig", ""} {
+		renameManifestRig(t, archive.Path, name)
+
+		parent := t.TempDir()
+		other := filepath.Join(parent, "other")
+		writeFile(t, filepath.Join(other, "mayor", "town.json"), `{"type":"town","version":2,"name":"other"}`)
+		writeFile(t, filepath.Join(other, "mayor", "rigs.json"), `{"version":1,"rigs":{}}`)
+		writeFile(t, filepath.Join(other, ".beads", "routes.jsonl"), `{"prefix":"hq-","path":"."}`+"\n")
+
+		if _, err := ImportRig(archive.Path, other, RestoreOptions{}); err == nil {
+			t.Errorf("ImportRig accepted rig name %q", name)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "escape")); !os.IsNotExist(err) {
+			t.Errorf("rig name %q wrote outside the town", name)
+		}
+		rigs, _ := os.ReadFile(filepath.Join(other, "mayor", "rigs.json"))
+		if string(rigs) != `{"version":1,"rigs":{}}` {
+			t.Errorf("rig name %q changed rigs.json: %s", name, rigs)
+		}
+	}
+}
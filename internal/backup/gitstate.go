@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Exports carry work that exists only on this machine: polecat branches and
+// crew commits that were never pushed, and uncommitted changes, which are
+// first saved as WIP snapshot refs (see checkpoint.TakeSnapshot). Only
+// history missing from the remote goes into the bundles; import clones the
+// remote and fetches the bundles on top.
+
+// collectGitState snapshots and bundles a rig's in-flight polecat and crew
+// work, recording it in info.
+func collectGitState(a *archiveWriter, rigPath string, info *Rig) error {
+	staging, err := os.MkdirTemp("", "gt-export-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	now := a.m.CreatedAt
+
+	bare := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(bare); err == nil {
+		repo := git.NewGitWithDir(bare, "")
+		var refs []string
+		entries, _ := os.ReadDir(filepath.Join(rigPath, "polecats"))
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			wt := polecatWorktree(rigPath, info.Name, e.Name())
+			if wt == "" {
+				continue
+			}
+			branch, err := git.NewGit(wt).CurrentBranch()
+			if err != nil || branch == "" || branch == "HEAD" {
+				a.m.Warnings = append(a.m.Warnings, fmt.Sprintf("polecat %s/%s is not on a branch; not exported", info.Name, e.Name()))
+				continue
+			}
+			p := Polecat{Name: e.Name(), Branch: branch}
+			p.Snapshot, p.HookedBead, err = snapshotWork(wt, repo, e.Name(), now)
+			if err != nil {
+				a.m.Warnings = append(a.m.Warnings, fmt.Sprintf("polecat %s/%s: uncommitted changes not exported: %v", info.Name, e.Name(), err))
+			}
+			if p.HookedBead == "" {
+				if cp, err := checkpoint.Read(wt); err == nil && cp != nil {
+					p.HookedBead = cp.HookedBead
+				}
+			}
+			info.Polecats = append(info.Polecats, p)
+			refs = append(refs, "refs/heads/"+branch)
+			if p.Snapshot != "" {
+				refs = append(refs, p.Snapshot)
+			}
+		}
+		entry, err := addBundle(a, repo, staging, info.Name+"/repo.bundle", refs)
+		if err != nil {
+			return err
+		}
+		info.Bundle = entry
+	}
+
+	for i := range info.Crew {
+		c := &info.Crew[i]
+		clone := git.NewGit(filepath.Join(rigPath, "crew", c.Name))
+		var refs []string
+		if c.Branch != "" && c.Branch != "HEAD" {
+			refs = append(refs, "refs/heads/"+c.Branch)
+		}
+		snap, _, err := snapshotWork(clone.Path(), nil, "crew-"+c.Name, now)
+		if err != nil {
+			a.m.Warnings = append(a.m.Warnings, fmt.Sprintf("crew %s/%s: uncommitted changes not exported: %v", info.Name, c.Name, err))
+		}
+		if snap != "" {
+			refs = append(refs, snap)
+		}
+		entry, err := addBundle(a, clone, staging, info.Name+"/crew/"+c.Name+".bundle", refs)
+		if snap != "" {
+			_ = clone.DeleteRef(snap) // Only needed for the bundle
+		}
+		if err != nil {
+			return err
+		}
+		if entry != "" {
+			c.Bundle = entry
+			if snap != "" {
+				c.Snapshot = snap
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotWork saves a checkout's uncommitted changes as a WIP snapshot and
+// returns its ref, or "" when there is nothing uncommitted. An existing
+// snapshot of the same state is reused.
+func snapshotWork(worktree string, repo *git.Git, name string, now time.Time) (string, string, error) {
+	snap, err := checkpoint.TakeSnapshot(worktree, repo, name, now)
+	if err != nil {
+		return "", "", err
+	}
+	if snap != nil {
+		return snap.Ref, snap.HookedBead, nil
+	}
+
+	// Nothing new: either the checkout is clean or its state is already in
+	// the latest snapshot.
+	wt := git.NewGit(worktree)
+	status, err := wt.CheckUncommittedWork()
+	if err != nil || !status.HasUncommittedChanges {
+		return "", "", nil
+	}
+	if repo == nil {
+		repo = wt
+	}
+	snaps, err := checkpoint.ListSnapshots(repo, name)
+	head, headErr := wt.Rev("HEAD")
+	if err != nil || headErr != nil || len(snaps) == 0 || snaps[0].Head != head {
+		return "", "", nil
+	}
+	return snaps[0].Ref, snaps[0].HookedBead, nil
+}
+
+// addBundle bundles the refs not yet on the remote and archives the bundle
+// as git/<name>. Returns the archive entry, or "" if nothing was unpushed.
+func addBundle(a *archiveWriter, g *git.Git, staging, name string, refs []string) (string, error) {
+	var unpushed []string
+	for _, ref := range refs {
+		sha, err := g.Rev(ref)
+		if err != nil {
+			continue
+		}
+		if pushed, err := g.RemoteContains(sha); err == nil && pushed {
+			continue
+		}
+		unpushed = append(unpushed, ref)
+	}
+	if len(unpushed) == 0 {
+		return "", nil
+	}
+
+	file := filepath.Join(staging, strings.ReplaceAll(name, "/", "_"))
+	if err := g.CreateBundle(file, unpushed...); err != nil {
+		return "", fmt.Errorf("bundling %s: %w", name, err)
+	}
+	entry := gitPrefix + name
+	if err := a.addFile(file, entry); err != nil {
+		return "", err
+	}
+	return entry, nil
+}
+
+// polecatWorktree returns a polecat's worktree path: polecats/<name>/<rig>,
+// or the older polecats/<name>. Returns "" if there is none.
+func polecatWorktree(rigPath, rigName, name string) string {
+	for _, p := range []string{
+		filepath.Join(rigPath, "polecats", name, rigName),
+		filepath.Join(rigPath, "polecats", name),
+	} {
+		if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// rigDatabase returns the Dolt database holding a rig's beads, from its
+// beads metadata, defaulting to the rig name.
+func rigDatabase(townRoot, rigName string) string {
+	beadsDir := beads.ResolveBeadsDir(filepath.Join(townRoot, rigName))
+	data, err := os.ReadFile(filepath.Join(beadsDir, "metadata.json")) //nolint:gosec // G304: path inside the town
+	if err == nil {
+		var meta struct {
+			DoltDatabase string `json:"dolt_database"`
+		}
+		if json.Unmarshal(data, &meta) == nil && meta.DoltDatabase != "" {
+			return meta.DoltDatabase
+		}
+	}
+	return rigName
+}
@@ -0,0 +1,567 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// ErrTownExists is returned when restoring over an existing town without
+// RestoreOptions.Force.
+var ErrTownExists = errors.New("a town already exists at the restore target (use --force to overwrite)")
+
+// RestoreOptions configures Restore and ImportRig.
+type RestoreOptions struct {
+	Passphrase string
+	Force      bool // Overwrite an existing town's files and databases
+	SkipGit    bool // Don't rebuild rig repos, worktrees and crew clones
+	SkipDolt   bool // Don't restore Dolt databases
+}
+
+// RestoreResult summarizes what Restore did.
+type RestoreResult struct {
+	Manifest  *Manifest
+	Files     int
+	Rewritten int      // Files whose source-machine paths were rewritten
+	Rigs      []string // Rigs whose git checkouts were rebuilt
+	Crew      []string // Crew workspaces re-created, as rig/name
+	Polecats  []string // Polecat worktrees re-created, as rig/name
+	Databases []string // Databases restored
+	Warnings  []string
+}
+
+// Restore verifies archivePath and rebuilds the town at townRoot from it.
+// Configuration and state files are written back; rig repos, the mayor and
+// refinery checkouts, and crew clones are re-created from each rig's git
+// remote rather than from the archive. Archives from exports also bring
+// back polecat worktrees and unpushed work. Absolute paths of the source
+// town and home directory are rewritten for the new location.
+func Restore(archivePath, townRoot string, opts RestoreOptions) (*RestoreResult, error) {
+	if !opts.Force {
+		if _, err := os.Stat(filepath.Join(townRoot, "mayor", "town.json")); err == nil {
+			return nil, ErrTownExists
+		}
+	}
+	r, err := openRestore(archivePath, townRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.cleanup()
+	if r.m.Kind == KindRig {
+		return nil, errors.New("archive is a rig export; use gt rig import")
+	}
+	if err := r.checkDolt(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(townRoot, 0755); err != nil {
+		return nil, err
+	}
+	return r.run()
+}
+
+// ImportRig adds the rig in a rig export (see CreateOptions.Rig) to the
+// town at townRoot: its files, registration and beads routes, its database,
+// and its git checkouts with in-flight work.
+func ImportRig(archivePath, townRoot string, opts RestoreOptions) (*RestoreResult, error) {
+	rigsPath := filepath.Join(townRoot, "mayor", "rigs.json")
+	rigsCfg, err := config.LoadRigsConfig(rigsPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+
+	r, err := openRestore(archivePath, townRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.cleanup()
+	if r.m.Kind != KindRig || len(r.m.Rigs) != 1 {
+		return nil, errors.New("archive is not a rig export")
+	}
+	info := r.m.Rigs[0]
+
+	if _, ok := rigsCfg.Rigs[info.Name]; ok {
+		return nil, fmt.Errorf("rig %q already exists in this town", info.Name)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, info.Name)); err == nil {
+		return nil, fmt.Errorf("directory %s already exists", filepath.Join(townRoot, info.Name))
+	}
+	townBeads := filepath.Join(townRoot, ".beads")
+	routes, err := beads.LoadRoutes(townBeads)
+	if err != nil {
+		return nil, fmt.Errorf("loading routes: %w", err)
+	}
+	for _, want := range info.Routes {
+		for _, have := range routes {
+			if have.Prefix == want.Prefix {
+				return nil, fmt.Errorf("beads prefix %s is already used by %s", want.Prefix, have.Path)
+			}
+		}
+	}
+	if err := r.checkDolt(); err != nil {
+		return nil, err
+	}
+
+	entry := config.RigEntry{
+		GitURL:      info.GitURL,
+		LocalRepo:   r.rewriteString(info.LocalRepo),
+		AddedAt:     time.Now(),
+		BeadsConfig: info.Beads,
+	}
+	if rigsCfg.Rigs == nil {
+		rigsCfg.Rigs = make(map[string]config.RigEntry)
+	}
+	rigsCfg.Rigs[info.Name] = entry
+	if err := config.SaveRigsConfig(rigsPath, rigsCfg); err != nil {
+		return nil, fmt.Errorf("registering rig: %w", err)
+	}
+	for _, route := range info.Routes {
+		if err := beads.AppendRoute(townRoot, route); err != nil {
+			r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("adding route %s: %v", route.Prefix, err))
+		}
+	}
+	return r.run()
+}
+
+// restorer carries the state of one restore or import.
+type restorer struct {
+	staging  string
+	townRoot string
+	opts     RestoreOptions
+	m        *Manifest
+	res      *RestoreResult
+	rewrite  *strings.Replacer // Source-machine paths to this town's; nil if unchanged
+}
+
+func openRestore(archivePath, townRoot string, opts RestoreOptions) (*restorer, error) {
+	townRoot, err := filepath.Abs(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksumFile(archivePath); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp("", "gt-restore-*")
+	if err != nil {
+		return nil, err
+	}
+	m, err := readArchive(archivePath, opts.Passphrase, staging)
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return nil, err
+	}
+	// Rig names come from the archive and are joined onto the town root.
+	for _, info := range m.Rigs {
+		if err := rig.ValidateName(info.Name); err != nil {
+			_ = os.RemoveAll(staging)
+			return nil, fmt.Errorf("archive manifest: %w", err)
+		}
+	}
+	r := &restorer{
+		staging:  staging,
+		townRoot: townRoot,
+		opts:     opts,
+		m:        m,
+		res:      &RestoreResult{Manifest: m},
+	}
+
+	// The town root is replaced before the home directory it usually sits in.
+	var pairs []string
+	if m.Root != "" && m.Root != townRoot {
+		pairs = append(pairs, m.Root, townRoot)
+	}
+	if home, err := os.UserHomeDir(); err == nil && m.Home != "" && m.Home != home {
+		pairs = append(pairs, m.Home, home)
+	}
+	if len(pairs) > 0 {
+		r.rewrite = strings.NewReplacer(pairs...)
+	}
+	return r, nil
+}
+
+func (r *restorer) cleanup() {
+	_ = os.RemoveAll(r.staging)
+}
+
+func (r *restorer) rewriteString(s string) string {
+	if r.rewrite == nil {
+		return s
+	}
+	return r.rewrite.Replace(s)
+}
+
+// checkDolt refuses to go ahead when databases would be restored into a
+// running or remote server. Called before anything is written.
+func (r *restorer) checkDolt() error {
+	if r.opts.SkipDolt || len(r.m.Databases) == 0 {
+		return nil
+	}
+	if doltserver.DefaultConfig(r.townRoot).IsRemote() {
+		return errors.New("town is configured for a remote Dolt server; restore databases there directly or use --no-dolt")
+	}
+	if running, _, _ := doltserver.IsRunning(r.townRoot); running {
+		return errors.New("the Dolt server is running; stop it (gt dolt stop) before restoring databases, or use --no-dolt")
+	}
+	return nil
+}
+
+// run writes files, rebuilds git checkouts and restores databases.
+func (r *restorer) run() (*RestoreResult, error) {
+	// Files inside a rig's mayor clone wait until the clone exists.
+	var deferred []File
+	for _, f := range r.m.Files {
+		rel, ok := strings.CutPrefix(f.Path, townPrefix)
+		if !ok {
+			continue
+		}
+		if insideMayorClone(rel, r.m.Rigs) {
+			deferred = append(deferred, f)
+			continue
+		}
+		if err := r.restoreFile(rel, f); err != nil {
+			return r.res, err
+		}
+	}
+
+	if !r.opts.SkipGit {
+		for _, info := range r.m.Rigs {
+			rebuilt, err := r.rebuildRig(info)
+			if err != nil {
+				r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("rig %s: %v", info.Name, err))
+				continue
+			}
+			if rebuilt {
+				r.res.Rigs = append(r.res.Rigs, info.Name)
+			}
+		}
+	}
+
+	for _, f := range deferred {
+		if err := r.restoreFile(strings.TrimPrefix(f.Path, townPrefix), f); err != nil {
+			return r.res, err
+		}
+	}
+
+	if !r.opts.SkipGit {
+		r.restoreWorkers()
+	}
+
+	if !r.opts.SkipDolt {
+		for _, db := range r.m.Databases {
+			restored, err := restoreDatabase(r.staging, r.townRoot, db, r.opts.Force)
+			if err != nil {
+				return r.res, err
+			}
+			if restored {
+				r.res.Databases = append(r.res.Databases, db.Name)
+			} else {
+				r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("database %s already exists; skipped (use --force to replace)", db.Name))
+			}
+		}
+	}
+
+	return r.res, nil
+}
+
+// insideMayorClone reports whether a town-relative path lives in a rig's
+// mayor/rig checkout.
+func insideMayorClone(rel string, rigs []Rig) bool {
+	for _, r := range rigs {
+		if strings.HasPrefix(rel, r.Name+"/mayor/rig/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *restorer) restoreFile(rel string, f File) error {
+	src := filepath.Join(r.staging, filepath.FromSlash(f.Path))
+	dst := filepath.Join(r.townRoot, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	mode := os.FileMode(f.Mode).Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	r.res.Files++
+
+	if r.rewrite != nil && f.Size <= maxRewriteSize {
+		data, err := os.ReadFile(src) //nolint:gosec // G304: staged file extracted by readArchive
+		if err != nil {
+			return err
+		}
+		if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+			if out := r.rewrite.Replace(string(data)); out != string(data) {
+				r.res.Rewritten++
+				if err := os.WriteFile(dst, []byte(out), mode); err != nil {
+					return err
+				}
+				return os.Chmod(dst, mode)
+			}
+		}
+	}
+	return copyFile(src, dst, mode)
+}
+
+// maxRewriteSize bounds the files scanned for source-machine paths; config
+// files are small.
+const maxRewriteSize = 4 << 20
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src) //nolint:gosec // G304: staged file extracted by readArchive
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode) //nolint:gosec // G304: path under the restore target
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dst, mode)
+}
+
+// rebuildRig re-creates a rig's shared bare repo, mayor clone and refinery
+// worktree from its git remote, leaving existing checkouts alone, and
+// fetches exported unpushed work into the shared repo. It reports whether
+// anything was created.
+func (r *restorer) rebuildRig(info Rig) (bool, error) {
+	if info.GitURL == "" {
+		return false, errors.New("no git URL recorded")
+	}
+	townRoot := r.townRoot
+	rigPath := filepath.Join(townRoot, info.Name)
+	g := git.NewGit(townRoot)
+	localRepo := r.rewriteString(info.LocalRepo)
+	if localRepo != "" {
+		if _, err := os.Stat(localRepo); err != nil {
+			localRepo = ""
+		}
+	}
+	created := false
+
+	bareRepoPath := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(bareRepoPath); os.IsNotExist(err) {
+		if err := cloneWithFallback(g.CloneBareWithReference, g.CloneBare, info.GitURL, bareRepoPath, localRepo); err != nil {
+			return false, fmt.Errorf("cloning bare repo: %w", err)
+		}
+		created = true
+	}
+	bareGit := git.NewGitWithDir(bareRepoPath, "")
+	if info.Bundle != "" {
+		bundle := filepath.Join(r.staging, filepath.FromSlash(info.Bundle))
+		if err := bareGit.FetchBundle(bundle, "refs/heads/*:refs/heads/*", "refs/gt/*:refs/gt/*"); err != nil {
+			r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("rig %s: unpushed work not restored: %v", info.Name, err))
+		}
+	}
+
+	defaultBranch := info.DefaultBranch
+	if defaultBranch == "" {
+		if defaultBranch = bareGit.RemoteDefaultBranch(); defaultBranch == "" {
+			defaultBranch = bareGit.DefaultBranch()
+		}
+	}
+
+	mayorRigPath := filepath.Join(rigPath, "mayor", "rig")
+	if _, err := os.Stat(mayorRigPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(mayorRigPath), 0755); err != nil {
+			return created, err
+		}
+		if err := cloneWithFallback(g.CloneWithReference, g.Clone, info.GitURL, mayorRigPath, localRepo); err != nil {
+			return created, fmt.Errorf("cloning for mayor: %w", err)
+		}
+		if err := git.NewGitWithDir("", mayorRigPath).Checkout(defaultBranch); err != nil {
+			return created, fmt.Errorf("checking out %s for mayor: %w", defaultBranch, err)
+		}
+		created = true
+	}
+
+	refineryRigPath := filepath.Join(rigPath, "refinery", "rig")
+	if _, err := os.Stat(refineryRigPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(refineryRigPath), 0755); err != nil {
+			return created, err
+		}
+		if err := bareGit.WorktreeAddExisting(refineryRigPath, defaultBranch); err != nil {
+			return created, fmt.Errorf("creating refinery worktree: %w", err)
+		}
+		if err := git.NewGit(refineryRigPath).ConfigureHooksPath(); err != nil {
+			return created, fmt.Errorf("configuring hooks for refinery: %w", err)
+		}
+		// Non-fatal, as in rig add.
+		_ = beads.SetupRedirect(townRoot, refineryRigPath)
+		_ = rig.CopyOverlay(rigPath, refineryRigPath)
+		created = true
+	}
+
+	for _, dir := range []string{"crew", "polecats", "witness"} {
+		if err := os.MkdirAll(filepath.Join(rigPath, dir), 0755); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// cloneWithFallback clones using a local reference repo when one is
+// available, falling back to a plain clone.
+func cloneWithFallback(withRef func(url, dest, ref string) error, plain func(url, dest string) error, url, dest, localRepo string) error {
+	if localRepo != "" {
+		if err := withRef(url, dest, localRepo); err == nil {
+			return nil
+		}
+		_ = os.RemoveAll(dest)
+	}
+	return plain(url, dest)
+}
+
+// restoreWorkers re-creates missing crew workspaces on the branches they
+// were on, and the polecat worktrees of exports, with their unpushed and
+// uncommitted work.
+func (r *restorer) restoreWorkers() {
+	rigsCfg, err := config.LoadRigsConfig(filepath.Join(r.townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("crew and polecats not restored: %v", err))
+		return
+	}
+	mgr := rig.NewManager(r.townRoot, rigsCfg, git.NewGit(r.townRoot))
+
+	for _, info := range r.m.Rigs {
+		if len(info.Crew) == 0 && len(info.Polecats) == 0 {
+			continue
+		}
+		rigObj, err := mgr.GetRig(info.Name)
+		if err != nil {
+			r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("workers for rig %s not restored: %v", info.Name, err))
+			continue
+		}
+		crewMgr := crew.NewManager(rigObj, git.NewGit(rigObj.Path))
+		for _, c := range info.Crew {
+			if _, err := os.Stat(filepath.Join(rigObj.Path, "crew", c.Name)); err == nil {
+				continue
+			}
+			if err := r.restoreCrew(crewMgr, rigObj, c); err != nil {
+				r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("crew %s/%s: %v", info.Name, c.Name, err))
+				continue
+			}
+			r.res.Crew = append(r.res.Crew, info.Name+"/"+c.Name)
+		}
+
+		polecatMgr := polecat.NewManager(rigObj, git.NewGit(rigObj.Path), tmux.NewTmux())
+		for _, p := range info.Polecats {
+			wt, err := polecatMgr.Reattach(p.Name, p.Branch)
+			if err != nil {
+				r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("polecat %s/%s: %v", info.Name, p.Name, err))
+				continue
+			}
+			if p.Snapshot != "" {
+				if err := git.NewGit(wt).ReadTreeReset(p.Snapshot); err != nil {
+					r.res.Warnings = append(r.res.Warnings, fmt.Sprintf("polecat %s/%s: uncommitted changes not restored: %v", info.Name, p.Name, err))
+				}
+			}
+			r.res.Polecats = append(r.res.Polecats, info.Name+"/"+p.Name)
+		}
+	}
+}
+
+// restoreCrew clones one crew workspace and puts it back on its branch.
+func (r *restorer) restoreCrew(crewMgr *crew.Manager, rigObj *rig.Rig, c Crew) error {
+	worker, err := crewMgr.Add(c.Name, c.Branch == "crew/"+c.Name)
+	if err != nil {
+		return err
+	}
+	cg := git.NewGit(worker.ClonePath)
+	if c.Bundle != "" {
+		bundle := filepath.Join(r.staging, filepath.FromSlash(c.Bundle))
+		if err := cg.FetchBundle(bundle, "refs/heads/*:refs/heads/*", "refs/gt/*:refs/gt/*"); err != nil {
+			return fmt.Errorf("unpushed work not restored: %w", err)
+		}
+	}
+	if c.Branch != "" && c.Branch != "HEAD" {
+		if current, _ := cg.CurrentBranch(); current == c.Branch {
+			// The fetch may have moved the checked-out branch.
+			if err := cg.ResetHard("HEAD"); err != nil {
+				return err
+			}
+		} else if err := cg.Checkout(c.Branch); err != nil {
+			return fmt.Errorf("branch %s not found; left on %s", c.Branch, rigObj.DefaultBranch())
+		}
+	}
+	if c.Snapshot != "" {
+		if err := cg.ReadTreeReset(c.Snapshot); err != nil {
+			return fmt.Errorf("uncommitted changes not restored: %w", err)
+		}
+		_ = cg.DeleteRef(c.Snapshot)
+	}
+	return nil
+}
+
+// restoreDatabase writes one database back into the town's data directory.
+// An existing database is only replaced with force.
+func restoreDatabase(staging, townRoot string, db Database, force bool) (bool, error) {
+	src := filepath.Join(staging, "dolt", db.Name)
+	dest := doltserver.RigDatabaseDir(townRoot, db.Name)
+	exists := false
+	if _, err := os.Stat(dest); err == nil {
+		exists = true
+	}
+	if exists && !force {
+		return false, nil
+	}
+
+	switch db.Format {
+	case FormatDoltBackup:
+		if err := doltserver.RestoreDatabaseBackup(townRoot, db.Name, src, exists); err != nil {
+			return false, err
+		}
+	case FormatDir:
+		if exists {
+			if err := os.RemoveAll(dest); err != nil {
+				return false, err
+			}
+		}
+		if err := copyTree(src, dest); err != nil {
+			return false, fmt.Errorf("restoring %s: %w", db.Name, err)
+		}
+	default:
+		return false, fmt.Errorf("database %s has unknown format %q", db.Name, db.Format)
+	}
+	return true, nil
+}
+
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(p, target, 0644)
+	})
+}
@@ -142,6 +142,7 @@ type Beads struct {
 	workDir  string
 	beadsDir string // Optional BEADS_DIR override for cross-database access
 	isolated bool   // If true, suppress inherited beads env vars (for test isolation)
+	sqlReads bool   // If true, answer supported reads from the Dolt server (see WithSQLReads)
 
 	// Lazy-cached town root for routing resolution.
 	// Populated on first call to getTownRoot() to avoid filesystem walk on every operation.
@@ -167,6 +168,15 @@ func NewWithBeadsDir(workDir, beadsDir string) *Beads {
 	return &Beads{workDir: workDir, beadsDir: beadsDir}
 }
 
+// WithSQLReads makes this wrapper answer list, show and dep list reads from
+// the Dolt SQL server when it serves the database (see ReadSQL), falling
+// back to bd otherwise. It is for hot read paths such as gt status; other
+// callers keep bd as the single source of its JSON.
+func (b *Beads) WithSQLReads() *Beads {
+	b.sqlReads = true
+	return b
+}
+
 // getActor returns the BD_ACTOR value for this context.
 // Returns empty string when in isolated mode (tests) to prevent
 // inherited actors from routing to production databases.
@@ -218,6 +228,13 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 		beadsDir = ResolveBeadsDir(b.workDir)
 	}
 
+	// Serve reads straight from the Dolt server when asked to and possible.
+	if b.sqlReads && !b.isolated {
+		if out, ok := ReadSQL(beadsDir, args...); ok {
+			return out, nil
+		}
+	}
+
 	// In isolated mode, use --db flag to force specific database path
 	// This bypasses bd's routing logic that can redirect to .beads-planning
 	// Skip --db for init command since it creates the database
@@ -598,6 +598,9 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		Rig:         "gastown",
 		MergeCommit: "abc123def789",
 		CloseReason: "merged",
+		Boost:       -50,
+		Pinned:      true,
+		Hold:        "waiting on release freeze",
 	}
 
 	// Format to string
@@ -198,6 +198,31 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Pre-merge verification (set by the Refinery)
+	VerifyResult string // passed, cached, or "failed: <stage>"
+	VerifyLog    string // Directory holding the stage logs of the last run
+
+	// Code review (set by the Refinery and the reviewer when the rig has a
+	// review policy)
+	ReviewStatus      string // pending, approved, changes_requested, rework_requested
+	ReviewSHA         string // Branch head the review applies to
+	Reviewer          string // Who reviewed (or approved, for overrides)
+	ReviewTask        string // Review task the MR is blocked on
+	ReviewRequestedAt string // When the review was requested (RFC 3339)
+	ReviewedAt        string // When the verdict was recorded (RFC 3339)
+	ReviewFindings    string // Findings as a single-line JSON array
+
+	// Pull-request landing (set by the Refinery when the rig lands via PR)
+	PRNumber int    // Forge pull request number
+	PRURL    string // Forge pull request URL
+	PRStatus string // Last landing outcome: waiting, merged, checks_failed, ...
+	PRSHA    string // PR head the landing outcome applies to
+
+	// Queue overrides (set by gt mq bump/pin/hold)
+	Boost  int    // Points added to the MR's priority score (may be negative)
+	Pinned bool   // Always processed ahead of unpinned MRs
+	Hold   string // Why the MR is held out of the queue ("" = not held)
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -272,6 +297,58 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "verify_result", "verify-result", "verifyresult":
+			fields.VerifyResult = value
+			hasFields = true
+		case "verify_log", "verify-log", "verifylog":
+			fields.VerifyLog = value
+			hasFields = true
+		case "review_status", "review-status", "reviewstatus":
+			fields.ReviewStatus = value
+			hasFields = true
+		case "review_sha", "review-sha", "reviewsha":
+			fields.ReviewSHA = value
+			hasFields = true
+		case "reviewer":
+			fields.Reviewer = value
+			hasFields = true
+		case "review_task", "review-task", "reviewtask":
+			fields.ReviewTask = value
+			hasFields = true
+		case "review_requested_at", "review-requested-at", "reviewrequestedat":
+			fields.ReviewRequestedAt = value
+			hasFields = true
+		case "reviewed_at", "reviewed-at", "reviewedat":
+			fields.ReviewedAt = value
+			hasFields = true
+		case "review_findings", "review-findings", "reviewfindings":
+			fields.ReviewFindings = value
+			hasFields = true
+		case "pr_number", "pr-number", "prnumber":
+			if n, err := parseIntField(value); err == nil {
+				fields.PRNumber = n
+				hasFields = true
+			}
+		case "pr_url", "pr-url", "prurl":
+			fields.PRURL = value
+			hasFields = true
+		case "pr_status", "pr-status", "prstatus":
+			fields.PRStatus = value
+			hasFields = true
+		case "pr_sha", "pr-sha", "prsha":
+			fields.PRSHA = value
+			hasFields = true
+		case "boost":
+			if n, err := parseIntField(value); err == nil {
+				fields.Boost = n
+				hasFields = true
+			}
+		case "pinned":
+			fields.Pinned = value == "true"
+			hasFields = true
+		case "hold":
+			fields.Hold = value
+			hasFields = true
 		}
 	}
 
@@ -336,6 +413,54 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.VerifyResult != "" {
+		lines = append(lines, "verify_result: "+fields.VerifyResult)
+	}
+	if fields.VerifyLog != "" {
+		lines = append(lines, "verify_log: "+fields.VerifyLog)
+	}
+	if fields.ReviewStatus != "" {
+		lines = append(lines, "review_status: "+fields.ReviewStatus)
+	}
+	if fields.ReviewSHA != "" {
+		lines = append(lines, "review_sha: "+fields.ReviewSHA)
+	}
+	if fields.Reviewer != "" {
+		lines = append(lines, "reviewer: "+fields.Reviewer)
+	}
+	if fields.ReviewTask != "" {
+		lines = append(lines, "review_task: "+fields.ReviewTask)
+	}
+	if fields.ReviewRequestedAt != "" {
+		lines = append(lines, "review_requested_at: "+fields.ReviewRequestedAt)
+	}
+	if fields.ReviewedAt != "" {
+		lines = append(lines, "reviewed_at: "+fields.ReviewedAt)
+	}
+	if fields.ReviewFindings != "" {
+		lines = append(lines, "review_findings: "+fields.ReviewFindings)
+	}
+	if fields.PRNumber > 0 {
+		lines = append(lines, fmt.Sprintf("pr_number: %d", fields.PRNumber))
+	}
+	if fields.PRURL != "" {
+		lines = append(lines, "pr_url: "+fields.PRURL)
+	}
+	if fields.PRStatus != "" {
+		lines = append(lines, "pr_status: "+fields.PRStatus)
+	}
+	if fields.PRSHA != "" {
+		lines = append(lines, "pr_sha: "+fields.PRSHA)
+	}
+	if fields.Boost != 0 {
+		lines = append(lines, fmt.Sprintf("boost: %d", fields.Boost))
+	}
+	if fields.Pinned {
+		lines = append(lines, "pinned: true")
+	}
+	if fields.Hold != "" {
+		lines = append(lines, "hold: "+fields.Hold)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -350,38 +475,78 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 
 	// Known MR field keys (lowercase)
 	mrKeys := map[string]bool{
-		"branch":             true,
-		"target":             true,
-		"source_issue":       true,
-		"source-issue":       true,
-		"sourceissue":        true,
-		"worker":             true,
-		"rig":                true,
-		"merge_commit":       true,
-		"merge-commit":       true,
-		"mergecommit":        true,
-		"close_reason":       true,
-		"close-reason":       true,
-		"closereason":        true,
-		"agent_bead":         true,
-		"agent-bead":         true,
-		"agentbead":          true,
-		"retry_count":        true,
-		"retry-count":        true,
-		"retrycount":         true,
-		"last_conflict_sha":  true,
-		"last-conflict-sha":  true,
-		"lastconflictsha":    true,
-		"conflict_task_id":   true,
-		"conflict-task-id":   true,
-		"conflicttaskid":     true,
-		"convoy_id":          true,
-		"convoy-id":          true,
-		"convoyid":           true,
-		"convoy":             true,
-		"convoy_created_at":  true,
-		"convoy-created-at":  true,
-		"convoycreatedat":    true,
+		"branch":              true,
+		"target":              true,
+		"source_issue":        true,
+		"source-issue":        true,
+		"sourceissue":         true,
+		"worker":              true,
+		"rig":                 true,
+		"merge_commit":        true,
+		"merge-commit":        true,
+		"mergecommit":         true,
+		"close_reason":        true,
+		"close-reason":        true,
+		"closereason":         true,
+		"agent_bead":          true,
+		"agent-bead":          true,
+		"agentbead":           true,
+		"retry_count":         true,
+		"retry-count":         true,
+		"retrycount":          true,
+		"last_conflict_sha":   true,
+		"last-conflict-sha":   true,
+		"lastconflictsha":     true,
+		"conflict_task_id":    true,
+		"conflict-task-id":    true,
+		"conflicttaskid":      true,
+		"convoy_id":           true,
+		"convoy-id":           true,
+		"convoyid":            true,
+		"convoy":              true,
+		"convoy_created_at":   true,
+		"convoy-created-at":   true,
+		"convoycreatedat":     true,
+		"verify_result":       true,
+		"verify-result":       true,
+		"verifyresult":        true,
+		"verify_log":          true,
+		"verify-log":          true,
+		"verifylog":           true,
+		"review_status":       true,
+		"review-status":       true,
+		"reviewstatus":        true,
+		"review_sha":          true,
+		"review-sha":          true,
+		"reviewsha":           true,
+		"reviewer":            true,
+		"review_task":         true,
+		"review-task":         true,
+		"reviewtask":          true,
+		"review_requested_at": true,
+		"review-requested-at": true,
+		"reviewrequestedat":   true,
+		"reviewed_at":         true,
+		"reviewed-at":         true,
+		"reviewedat":          true,
+		"review_findings":     true,
+		"review-findings":     true,
+		"reviewfindings":      true,
+		"pr_number":           true,
+		"pr-number":           true,
+		"prnumber":            true,
+		"pr_url":              true,
+		"pr-url":              true,
+		"prurl":               true,
+		"pr_status":           true,
+		"pr-status":           true,
+		"prstatus":            true,
+		"pr_sha":              true,
+		"pr-sha":              true,
+		"prsha":               true,
+		"boost":               true,
+		"pinned":              true,
+		"hold":                true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -253,6 +253,28 @@ func TestParseAgentFieldsFromDescription(t *testing.T) {
 	}
 }
 
+func TestAgentFieldsCapabilitiesRoundTrip(t *testing.T) {
+	desc := FormatAgentDescription("Polecat Toast", &AgentFields{
+		RoleType:     "polecat",
+		Rig:          "gastown",
+		AgentState:   "idle",
+		Capabilities: []string{"go", "docker"},
+	})
+	if !contains(desc, "capabilities: go, docker") {
+		t.Errorf("description missing capabilities line:\n%s", desc)
+	}
+	got := ParseAgentFields(desc)
+	if len(got.Capabilities) != 2 || got.Capabilities[0] != "go" || got.Capabilities[1] != "docker" {
+		t.Errorf("Capabilities = %v, want [go docker]", got.Capabilities)
+	}
+
+	// No capabilities: no line, so existing descriptions are unchanged.
+	desc = FormatAgentDescription("Polecat Nux", &AgentFields{RoleType: "polecat"})
+	if contains(desc, "capabilities") {
+		t.Errorf("description has capabilities line without capabilities:\n%s", desc)
+	}
+}
+
 // helper - strings.Contains alias for readability in checks
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 || indexSubstring(s, substr) >= 0)
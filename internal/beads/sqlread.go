@@ -0,0 +1,880 @@
+package beads
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Direct SQL read path.
+//
+// Spawning bd costs tens of milliseconds per call, and hot paths such as
+// gt status, the mail inbox and convoy observers make dozens of calls. When
+// a beads database is served by the Dolt SQL server (metadata.json has
+// dolt_mode "server"), supported read-only bd invocations are answered
+// straight from the server (database/sql with the MySQL driver, pooled per
+// database, parameterized queries) and decoded into structs mirroring bd's
+// issue types to print the JSON bd would. Writes, unsupported flags, a
+// server that is down and any SQL error fall back to running bd.
+//
+// Set GT_BEADS_SQL=0 to always use bd.
+
+const (
+	sqlDialTimeout  = time.Second
+	sqlQueryTimeout = 10 * time.Second
+	sqlDownCooldown = 30 * time.Second // Skip a server that refused us for this long
+	sqlMaxIdle      = 4                // Idle connections kept per database
+
+	sqlDefaultHost = "127.0.0.1"
+	sqlDefaultPort = 3307
+	sqlDefaultUser = "root"
+
+	bdDefaultListLimit = 50
+)
+
+// errSQLServerDown marks a server skipped during its cooldown.
+var errSQLServerDown = errors.New("dolt server marked down")
+
+// sqlTarget identifies a database on a Dolt SQL server.
+type sqlTarget struct {
+	Addr     string
+	User     string
+	Password string
+	Database string
+}
+
+// sqlTargetFor returns the server database backing beadsDir, if it is in
+// server mode. The GT_DOLT_* variables override metadata.json, which
+// overrides the defaults, as for the server itself.
+func sqlTargetFor(beadsDir string) (sqlTarget, bool) {
+	data, err := os.ReadFile(filepath.Join(beadsDir, "metadata.json")) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return sqlTarget{}, false
+	}
+	var meta struct {
+		DoltMode     string `json:"dolt_mode"`
+		DoltDatabase string `json:"dolt_database"`
+		Host         string `json:"dolt_server_host"`
+		Port         int    `json:"dolt_server_port"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil || meta.DoltMode != "server" || meta.DoltDatabase == "" {
+		return sqlTarget{}, false
+	}
+
+	host, port := sqlDefaultHost, sqlDefaultPort
+	if meta.Host != "" {
+		host = meta.Host
+	}
+	if meta.Port > 0 {
+		port = meta.Port
+	}
+	if h := os.Getenv("GT_DOLT_HOST"); h != "" {
+		host = h
+	}
+	if p, err := strconv.Atoi(os.Getenv("GT_DOLT_PORT")); err == nil && p > 0 {
+		port = p
+	}
+	user := sqlDefaultUser
+	if u := os.Getenv("GT_DOLT_USER"); u != "" {
+		user = u
+	}
+	return sqlTarget{
+		Addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		User:     user,
+		Password: os.Getenv("GT_DOLT_PASSWORD"),
+		Database: meta.DoltDatabase,
+	}, true
+}
+
+// sqlReadEnabled reports whether the SQL read path may be used.
+func sqlReadEnabled() bool {
+	switch strings.ToLower(os.Getenv("GT_BEADS_SQL")) {
+	case "0", "false", "off", "no":
+		return false
+	}
+	return true
+}
+
+// sqlPool keeps a connection pool per database and remembers servers that
+// recently refused connections.
+type sqlPool struct {
+	mu        sync.Mutex
+	dbs       map[sqlTarget]*sql.DB
+	downUntil map[string]time.Time // addr -> end of cooldown
+
+	open func(t sqlTarget) (*sql.DB, error)
+	now  func() time.Time
+}
+
+func newSQLPool() *sqlPool {
+	return &sqlPool{
+		dbs:       make(map[sqlTarget]*sql.DB),
+		downUntil: make(map[string]time.Time),
+		open:      openSQL,
+		now:       time.Now,
+	}
+}
+
+var defaultSQLPool = newSQLPool()
+
+// openSQL opens a pool for t with the MySQL driver. Connections are made
+// lazily, on the first query.
+func openSQL(t sqlTarget) (*sql.DB, error) {
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = t.Addr
+	cfg.User = t.User
+	cfg.Passwd = t.Password
+	cfg.DBName = t.Database
+	cfg.Timeout = sqlDialTimeout
+	cfg.ReadTimeout = sqlQueryTimeout
+	cfg.WriteTimeout = sqlQueryTimeout
+	cfg.ParseTime = true
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxIdleConns(sqlMaxIdle)
+	return db, nil
+}
+
+func (p *sqlPool) get(t sqlTarget) (*sql.DB, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until, ok := p.downUntil[t.Addr]; ok && p.now().Before(until) {
+		return nil, errSQLServerDown
+	}
+	if db := p.dbs[t]; db != nil {
+		return db, nil
+	}
+	db, err := p.open(t)
+	if err != nil {
+		return nil, err
+	}
+	p.dbs[t] = db
+	return db, nil
+}
+
+// do runs fn against t's pool with the query timeout. A server that can't
+// be reached is skipped for sqlDownCooldown; SQL errors (schema drift, a
+// rejected login) are returned without marking it down.
+func (p *sqlPool) do(t sqlTarget, fn func(ctx context.Context, db *sql.DB) error) error {
+	db, err := p.get(t)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sqlQueryTimeout)
+	defer cancel()
+	err = fn(ctx, db)
+
+	var netErr net.Error
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case err == nil:
+		delete(p.downUntil, t.Addr)
+	case errors.As(err, &netErr):
+		p.downUntil[t.Addr] = p.now().Add(sqlDownCooldown)
+	}
+	return err
+}
+
+// ReadSQL answers a read-only bd invocation (list, show or dep list, with
+// --json) for the database in beadsDir straight from the Dolt SQL server.
+// It returns false when the caller should run bd instead: the database is
+// not in server mode, the command or a flag is not supported, an issue is
+// not found locally (bd may route it elsewhere), or the server fails.
+func ReadSQL(beadsDir string, args ...string) ([]byte, bool) {
+	if !sqlReadEnabled() {
+		return nil, false
+	}
+	q, ok := parseReadArgs(args)
+	if !ok {
+		return nil, false
+	}
+	t, ok := sqlTargetFor(beadsDir)
+	if !ok {
+		return nil, false
+	}
+	var out []byte
+	err := defaultSQLPool.do(t, func(ctx context.Context, db *sql.DB) error {
+		var err error
+		out, err = q.run(ctx, db)
+		return err
+	})
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// RunRead runs a read-only bd command in workDir and returns its stdout,
+// answering it from the Dolt SQL server when ReadSQL can. It is a drop-in
+// for exec'ing bd in workDir without setting BEADS_DIR.
+func RunRead(workDir string, args ...string) ([]byte, error) {
+	beadsDir := os.Getenv("BEADS_DIR")
+	if beadsDir == "" {
+		beadsDir = ResolveBeadsDir(workDir)
+	}
+	if out, ok := ReadSQL(beadsDir, args...); ok {
+		return out, nil
+	}
+
+	cmd := exec.Command("bd", args...) //nolint:gosec // G204: bd is a trusted internal tool
+	cmd.Dir = workDir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// readQuery is a parsed read-only bd invocation.
+type readQuery struct {
+	cmd string // list, show, dep-list
+
+	// list
+	status     string
+	labels     []string
+	priority   int // -1 for any
+	parent     string
+	assignee   string
+	noAssignee bool
+	issueType  string
+	limit      int
+
+	// show, dep-list
+	ids       []string
+	direction string // down, up
+	depType   string
+}
+
+// parseReadArgs parses the bd invocations the SQL path supports. Flags take
+// --flag=value or --flag value; anything unrecognized is unsupported.
+func parseReadArgs(args []string) (*readQuery, bool) {
+	var rest []string
+	for _, a := range args {
+		if a != "--allow-stale" {
+			rest = append(rest, a)
+		}
+	}
+	switch {
+	case len(rest) > 0 && rest[0] == "list":
+		return parseListArgs(rest[1:])
+	case len(rest) > 0 && rest[0] == "show":
+		return parseShowArgs(rest[1:])
+	case len(rest) > 1 && rest[0] == "dep" && rest[1] == "list":
+		return parseDepListArgs(rest[2:])
+	}
+	return nil, false
+}
+
+// flagValue returns the value of the flag at args[i], from --flag=value or
+// the next argument, and the index of the last argument used.
+func flagValue(args []string, i int) (value string, last int, ok bool) {
+	if _, value, hasValue := strings.Cut(args[i], "="); hasValue {
+		return value, i, true
+	}
+	if i+1 >= len(args) {
+		return "", i, false
+	}
+	return args[i+1], i + 1, true
+}
+
+func parseListArgs(args []string) (*readQuery, bool) {
+	q := &readQuery{cmd: "list", priority: -1, limit: bdDefaultListLimit}
+	jsonOut := false
+	for i := 0; i < len(args); i++ {
+		flag, _, _ := strings.Cut(args[i], "=")
+		switch flag {
+		case "--json":
+			jsonOut = true
+		case "--all":
+			q.status = "all"
+		case "--no-assignee":
+			q.noAssignee = true
+		case "--status", "-s", "--label", "-l", "--priority", "-p", "--parent",
+			"--assignee", "-a", "--type", "-t", "--limit", "-n":
+			value, last, ok := flagValue(args, i)
+			if !ok {
+				return nil, false
+			}
+			i = last
+			switch flag {
+			case "--status", "-s":
+				q.status = value
+			case "--label", "-l":
+				for _, l := range strings.Split(value, ",") {
+					if l = strings.TrimSpace(l); l != "" {
+						q.labels = append(q.labels, l)
+					}
+				}
+			case "--priority", "-p":
+				p, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(value), "P"))
+				if err != nil {
+					return nil, false
+				}
+				q.priority = p
+			case "--parent":
+				q.parent = value
+			case "--assignee", "-a":
+				q.assignee = value
+			case "--type", "-t":
+				q.issueType = value
+			case "--limit", "-n":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					return nil, false
+				}
+				q.limit = n
+			}
+		default:
+			return nil, false
+		}
+	}
+	return q, jsonOut
+}
+
+func parseShowArgs(args []string) (*readQuery, bool) {
+	q := &readQuery{cmd: "show"}
+	jsonOut := false
+	for _, a := range args {
+		switch {
+		case a == "--json":
+			jsonOut = true
+		case strings.HasPrefix(a, "-"):
+			return nil, false
+		default:
+			q.ids = append(q.ids, a)
+		}
+	}
+	return q, jsonOut && len(q.ids) > 0
+}
+
+func parseDepListArgs(args []string) (*readQuery, bool) {
+	q := &readQuery{cmd: "dep-list", direction: "down"}
+	jsonOut := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			q.ids = append(q.ids, a)
+			continue
+		}
+		flag, _, _ := strings.Cut(a, "=")
+		switch flag {
+		case "--json":
+			jsonOut = true
+		case "--direction", "--type", "-t":
+			value, last, ok := flagValue(args, i)
+			if !ok {
+				return nil, false
+			}
+			i = last
+			if flag == "--direction" {
+				if value != "down" && value != "up" {
+					return nil, false
+				}
+				q.direction = value
+			} else {
+				q.depType = value
+			}
+		default:
+			return nil, false
+		}
+	}
+	return q, jsonOut && len(q.ids) == 1
+}
+
+// run executes the query and renders bd's JSON output.
+func (q *readQuery) run(ctx context.Context, db *sql.DB) ([]byte, error) {
+	var out interface{}
+	var err error
+	switch q.cmd {
+	case "list":
+		out, err = q.runList(ctx, db)
+	case "show":
+		out, err = q.runShow(ctx, db)
+	case "dep-list":
+		out, err = q.runDepList(ctx, db)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// errNotLocal makes show fall back to bd, which can route the ID to
+// another database or report it missing.
+var errNotLocal = errors.New("issue not in this database")
+
+// bdIssue is an issue as bd prints it with --json: bd's field names and
+// omitempty rules. Columns bd keeps for storage only (content hashes,
+// routing prefixes, source repo) have no field and are dropped.
+type bdIssue struct {
+	ID                 string          `json:"id"`
+	Title              string          `json:"title"`
+	Description        string          `json:"description,omitempty"`
+	Design             string          `json:"design,omitempty"`
+	AcceptanceCriteria string          `json:"acceptance_criteria,omitempty"`
+	Notes              string          `json:"notes,omitempty"`
+	Status             string          `json:"status,omitempty"`
+	Priority           int             `json:"priority"`
+	IssueType          string          `json:"issue_type,omitempty"`
+	Assignee           string          `json:"assignee,omitempty"`
+	Owner              string          `json:"owner,omitempty"`
+	EstimatedMinutes   *int            `json:"estimated_minutes,omitempty"`
+	CreatedAt          time.Time       `json:"created_at,omitzero"`
+	CreatedBy          string          `json:"created_by,omitempty"`
+	UpdatedAt          time.Time       `json:"updated_at,omitzero"`
+	ClosedAt           *time.Time      `json:"closed_at,omitempty"`
+	CloseReason        string          `json:"close_reason,omitempty"`
+	DueAt              *time.Time      `json:"due_at,omitempty"`
+	DeferUntil         *time.Time      `json:"defer_until,omitempty"`
+	ExternalRef        string          `json:"external_ref,omitempty"`
+	Metadata           json.RawMessage `json:"metadata,omitempty"`
+	Sender             string          `json:"sender,omitempty"`
+	Ephemeral          bool            `json:"ephemeral,omitempty"`
+	WispType           string          `json:"wisp_type,omitempty"`
+	Pinned             bool            `json:"pinned,omitempty"`
+	IsTemplate         bool            `json:"is_template,omitempty"`
+	MolType            string          `json:"mol_type,omitempty"`
+	HookBead           string          `json:"hook_bead,omitempty"`
+	RoleBead           string          `json:"role_bead,omitempty"`
+	AgentState         string          `json:"agent_state,omitempty"`
+	LastActivity       *time.Time      `json:"last_activity,omitempty"`
+	RoleType           string          `json:"role_type,omitempty"`
+	Rig                string          `json:"rig,omitempty"`
+	Labels             []string        `json:"labels,omitempty"`
+}
+
+// columns maps issues columns to scan destinations in i.
+func (i *bdIssue) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  nullValue[string]{&i.ID},
+		"title":               nullValue[string]{&i.Title},
+		"description":         nullValue[string]{&i.Description},
+		"design":              nullValue[string]{&i.Design},
+		"acceptance_criteria": nullValue[string]{&i.AcceptanceCriteria},
+		"notes":               nullValue[string]{&i.Notes},
+		"status":              nullValue[string]{&i.Status},
+		"priority":            nullValue[int]{&i.Priority},
+		"issue_type":          nullValue[string]{&i.IssueType},
+		"assignee":            nullValue[string]{&i.Assignee},
+		"owner":               nullValue[string]{&i.Owner},
+		"estimated_minutes":   nullPointer[int]{&i.EstimatedMinutes},
+		"created_at":          nullValue[time.Time]{&i.CreatedAt},
+		"created_by":          nullValue[string]{&i.CreatedBy},
+		"updated_at":          nullValue[time.Time]{&i.UpdatedAt},
+		"closed_at":           nullPointer[time.Time]{&i.ClosedAt},
+		"close_reason":        nullValue[string]{&i.CloseReason},
+		"due_at":              nullPointer[time.Time]{&i.DueAt},
+		"defer_until":         nullPointer[time.Time]{&i.DeferUntil},
+		"external_ref":        nullValue[string]{&i.ExternalRef},
+		"metadata":            jsonColumn{&i.Metadata},
+		"sender":              nullValue[string]{&i.Sender},
+		"ephemeral":           nullValue[bool]{&i.Ephemeral},
+		"wisp_type":           nullValue[string]{&i.WispType},
+		"pinned":              nullValue[bool]{&i.Pinned},
+		"is_template":         nullValue[bool]{&i.IsTemplate},
+		"mol_type":            nullValue[string]{&i.MolType},
+		"hook_bead":           nullValue[string]{&i.HookBead},
+		"role_bead":           nullValue[string]{&i.RoleBead},
+		"agent_state":         nullValue[string]{&i.AgentState},
+		"last_activity":       nullPointer[time.Time]{&i.LastActivity},
+		"role_type":           nullValue[string]{&i.RoleType},
+		"rig":                 nullValue[string]{&i.Rig},
+	}
+}
+
+// bdListIssue is a bd list entry: the issue and its relation counts,
+// which bd prints even when zero.
+type bdListIssue struct {
+	*bdIssue
+	DependencyCount int `json:"dependency_count"`
+	DependentCount  int `json:"dependent_count"`
+	CommentCount    int `json:"comment_count"`
+}
+
+// bdIssueDetails is a bd show entry.
+type bdIssueDetails struct {
+	*bdIssue
+	Parent       string          `json:"parent,omitempty"`
+	Dependencies []*bdDependency `json:"dependencies,omitempty"`
+	Dependents   []*bdDependency `json:"dependents,omitempty"`
+}
+
+// bdDependency is a related issue in bd show.
+type bdDependency struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Status         string `json:"status,omitempty"`
+	Priority       int    `json:"priority"`
+	IssueType      string `json:"issue_type,omitempty"`
+	DependencyType string `json:"dependency_type"`
+}
+
+// bdDepListIssue is a bd dep list entry.
+type bdDepListIssue struct {
+	*bdIssue
+	DependencyType string `json:"dependency_type"`
+}
+
+// nullValue scans a nullable column into dst, leaving it zero for NULL.
+type nullValue[T any] struct{ dst *T }
+
+func (v nullValue[T]) Scan(src interface{}) error {
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil || !n.Valid {
+		return err
+	}
+	*v.dst = n.V
+	return nil
+}
+
+// nullPointer scans a nullable column into dst, leaving it nil for NULL.
+type nullPointer[T any] struct{ dst **T }
+
+func (v nullPointer[T]) Scan(src interface{}) error {
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil || !n.Valid {
+		return err
+	}
+	*v.dst = &n.V
+	return nil
+}
+
+// jsonColumn scans a JSON column, dropping values that aren't valid JSON.
+type jsonColumn struct{ dst *json.RawMessage }
+
+func (v jsonColumn) Scan(src interface{}) error {
+	var n sql.NullString
+	if err := n.Scan(src); err != nil || !n.Valid {
+		return err
+	}
+	if json.Valid([]byte(n.String)) {
+		*v.dst = json.RawMessage(n.String)
+	}
+	return nil
+}
+
+// scanIssues reads issue rows, matching columns to fields by name. extra,
+// if set, returns destinations for a row's non-issue columns.
+func scanIssues(rows *sql.Rows, extra func(*bdIssue) map[string]interface{}) ([]*bdIssue, error) {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var issues []*bdIssue
+	for rows.Next() {
+		issue := &bdIssue{}
+		dests := issue.columns()
+		if extra != nil {
+			for col, dst := range extra(issue) {
+				dests[col] = dst
+			}
+		}
+		args := make([]interface{}, len(cols))
+		for i, col := range cols {
+			if dst, ok := dests[col]; ok {
+				args[i] = dst
+				delete(dests, col) // A repeated name (from a join) is dropped
+			} else {
+				args[i] = new(interface{})
+			}
+		}
+		if err := rows.Scan(args...); err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, rows.Err()
+}
+
+// listSQL builds the list query with bd's filters: non-closed issues by
+// default, and never tombstones (deleted issues) unless asked for by status.
+// Wisps (ephemeral issues) are listed like any other issue, as bd does; the
+// mail inbox and wisp compaction rely on that. Ordered by priority then
+// newest first.
+func (q *readQuery) listSQL() (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	switch q.status {
+	case "":
+		where = append(where, "status NOT IN ('closed', 'tombstone')")
+	case "all":
+		where = append(where, "status <> 'tombstone'")
+	default:
+		statuses := strings.Split(q.status, ",")
+		where = append(where, "status IN ("+placeholders(len(statuses))+")")
+		args = appendArgs(args, statuses)
+	}
+	for _, l := range q.labels {
+		where = append(where, "id IN (SELECT issue_id FROM labels WHERE label = ?)")
+		args = append(args, l)
+	}
+	if q.priority >= 0 {
+		where = append(where, "priority = ?")
+		args = append(args, q.priority)
+	}
+	if q.parent != "" {
+		where = append(where, "id IN (SELECT issue_id FROM dependencies WHERE depends_on_id = ? AND type = 'parent-child')")
+		args = append(args, q.parent)
+	}
+	if q.assignee != "" {
+		where = append(where, "assignee = ?")
+		args = append(args, q.assignee)
+	}
+	if q.noAssignee {
+		where = append(where, "(assignee IS NULL OR assignee = '')")
+	}
+	if q.issueType != "" {
+		where = append(where, "issue_type = ?")
+		args = append(args, q.issueType)
+	}
+
+	s := "SELECT * FROM issues"
+	if len(where) > 0 {
+		s += " WHERE " + strings.Join(where, " AND ")
+	}
+	s += " ORDER BY priority ASC, created_at DESC"
+	if q.limit > 0 {
+		s += " LIMIT ?"
+		args = append(args, q.limit)
+	}
+	return s, args
+}
+
+func (q *readQuery) runList(ctx context.Context, db *sql.DB) ([]*bdListIssue, error) {
+	query, args := q.listSQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := scanIssues(rows, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*bdListIssue, len(issues))
+	if len(issues) == 0 {
+		return out, nil
+	}
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+		out[i] = &bdListIssue{bdIssue: issue}
+	}
+	if err := addLabels(ctx, db, issues, ids); err != nil {
+		return nil, err
+	}
+	for _, count := range []struct {
+		table, col string
+		set        func(*bdListIssue, int)
+	}{
+		{"dependencies", "issue_id", func(i *bdListIssue, n int) { i.DependencyCount = n }},
+		{"dependencies", "depends_on_id", func(i *bdListIssue, n int) { i.DependentCount = n }},
+		{"comments", "issue_id", func(i *bdListIssue, n int) { i.CommentCount = n }},
+	} {
+		rows, err := db.QueryContext(ctx, "SELECT "+count.col+", COUNT(*) FROM "+count.table+
+			" WHERE "+count.col+" IN ("+placeholders(len(ids))+") GROUP BY "+count.col, appendArgs(nil, ids)...)
+		if err != nil {
+			return nil, err
+		}
+		n := make(map[string]int)
+		err = eachRow(rows, func() error {
+			var id string
+			var c int
+			if err := rows.Scan(&id, &c); err != nil {
+				return err
+			}
+			n[id] = c
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range out {
+			count.set(issue, n[issue.ID])
+		}
+	}
+	return out, nil
+}
+
+func (q *readQuery) runShow(ctx context.Context, db *sql.DB) ([]*bdIssueDetails, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM issues WHERE id IN ("+placeholders(len(q.ids))+")",
+		appendArgs(nil, q.ids)...)
+	if err != nil {
+		return nil, err
+	}
+	found, err := scanIssues(rows, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*bdIssueDetails, len(found))
+	for _, issue := range found {
+		byID[issue.ID] = &bdIssueDetails{bdIssue: issue}
+	}
+	out := make([]*bdIssueDetails, 0, len(q.ids))
+	issues := make([]*bdIssue, 0, len(q.ids))
+	for _, id := range q.ids {
+		details, ok := byID[id]
+		if !ok {
+			return nil, errNotLocal
+		}
+		out = append(out, details)
+		issues = append(issues, details.bdIssue)
+	}
+	if err := addLabels(ctx, db, issues, q.ids); err != nil {
+		return nil, err
+	}
+
+	// Dependencies (what each issue depends on) and dependents.
+	for _, rel := range []struct {
+		self, other string
+		list        func(*bdIssueDetails) *[]*bdDependency
+	}{
+		{"issue_id", "depends_on_id", func(d *bdIssueDetails) *[]*bdDependency { return &d.Dependencies }},
+		{"depends_on_id", "issue_id", func(d *bdIssueDetails) *[]*bdDependency { return &d.Dependents }},
+	} {
+		rows, err := db.QueryContext(ctx, "SELECT d."+rel.self+", d."+rel.other+", d.type, i.title, i.status, i.priority, i.issue_type"+
+			" FROM dependencies d LEFT JOIN issues i ON i.id = d."+rel.other+
+			" WHERE d."+rel.self+" IN ("+placeholders(len(q.ids))+") ORDER BY d."+rel.other, appendArgs(nil, q.ids)...)
+		if err != nil {
+			return nil, err
+		}
+		err = eachRow(rows, func() error {
+			var self string
+			dep := &bdDependency{}
+			if err := rows.Scan(&self, &dep.ID, &dep.DependencyType, nullValue[string]{&dep.Title},
+				nullValue[string]{&dep.Status}, nullValue[int]{&dep.Priority}, nullValue[string]{&dep.IssueType}); err != nil {
+				return err
+			}
+			details := byID[self]
+			if details == nil {
+				return nil
+			}
+			list := rel.list(details)
+			*list = append(*list, dep)
+			if rel.self == "issue_id" && dep.DependencyType == "parent-child" {
+				details.Parent = dep.ID
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (q *readQuery) runDepList(ctx context.Context, db *sql.DB) ([]*bdDepListIssue, error) {
+	id := q.ids[0]
+	var s string
+	var args []interface{}
+	if q.direction == "up" {
+		// Issues that depend on id, including cross-rig references
+		// (external:<prefix>:<id>).
+		s = "SELECT i.*, d.issue_id AS gt_dep_target, d.type AS gt_dep_type" +
+			" FROM dependencies d LEFT JOIN issues i ON i.id = d.issue_id" +
+			" WHERE (d.depends_on_id = ? OR d.depends_on_id LIKE ?)"
+		args = append(args, id, "external:%:"+sqlLikeEscape(id))
+	} else {
+		s = "SELECT i.*, d.depends_on_id AS gt_dep_target, d.type AS gt_dep_type" +
+			" FROM dependencies d LEFT JOIN issues i ON i.id = d.depends_on_id" +
+			" WHERE d.issue_id = ?"
+		args = append(args, id)
+	}
+	if q.depType != "" {
+		s += " AND d.type = ?"
+		args = append(args, q.depType)
+	}
+	s += " ORDER BY gt_dep_target"
+	rows, err := db.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	// Targets outside this database (cross-rig references) have no issue
+	// row; they are reported by ID alone, like bd. The target column comes
+	// after i.* so it sets the ID either way.
+	out := []*bdDepListIssue{}
+	_, err = scanIssues(rows, func(issue *bdIssue) map[string]interface{} {
+		entry := &bdDepListIssue{bdIssue: issue}
+		out = append(out, entry)
+		return map[string]interface{}{
+			"gt_dep_target": nullValue[string]{&issue.ID},
+			"gt_dep_type":   nullValue[string]{&entry.DependencyType},
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// addLabels attaches each issue's labels.
+func addLabels(ctx context.Context, db *sql.DB, issues []*bdIssue, ids []string) error {
+	rows, err := db.QueryContext(ctx, "SELECT issue_id, label FROM labels WHERE issue_id IN ("+
+		placeholders(len(ids))+") ORDER BY label", appendArgs(nil, ids)...)
+	if err != nil {
+		return err
+	}
+	labels := make(map[string][]string)
+	err = eachRow(rows, func() error {
+		var id, label string
+		if err := rows.Scan(&id, &label); err != nil {
+			return err
+		}
+		labels[id] = append(labels[id], label)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		issue.Labels = labels[issue.ID]
+	}
+	return nil
+}
+
+// eachRow calls fn for every row and closes rows.
+func eachRow(rows *sql.Rows, fn func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// placeholders returns n comma-separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// appendArgs appends values as query arguments.
+func appendArgs(args []interface{}, values []string) []interface{} {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+// sqlLikeEscape escapes LIKE wildcards in s.
+func sqlLikeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
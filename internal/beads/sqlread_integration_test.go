@@ -0,0 +1,115 @@
+//go:build integration
+
+package beads
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Run with: go test -tags=integration ./internal/beads -run TestReadSQL_MatchesLiveBd -v
+//
+// Needs bd on PATH and a Dolt SQL server on the default port (gt dolt start).
+
+// TestReadSQL_MatchesLiveBd builds one fixture with bd and checks that every
+// read the SQL path serves prints what bd prints for it.
+func TestReadSQL_MatchesLiveBd(t *testing.T) {
+	if _, err := exec.LookPath("bd"); err != nil {
+		t.Skip("bd not installed")
+	}
+	addr := net.JoinHostPort(sqlDefaultHost, fmt.Sprint(sqlDefaultPort))
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Skipf("no Dolt server on %s", addr)
+	}
+	_ = conn.Close()
+	clearDoltEnv(t)
+
+	dir := t.TempDir()
+	prefix := fmt.Sprintf("sq%d", time.Now().UnixNano()%1_000_000)
+	initCmd := exec.Command("bd", "init", "--prefix", prefix, "--backend", "dolt", "--server", "--quiet")
+	initCmd.Dir = dir
+	if out, err := initCmd.CombinedOutput(); err != nil {
+		t.Fatalf("bd init: %v\n%s", err, out)
+	}
+	beadsDir := filepath.Join(dir, ".beads")
+	if _, ok := sqlTargetFor(beadsDir); !ok {
+		t.Fatal("bd init did not create a server-mode database")
+	}
+
+	// The fixture: an epic with a child, labels, a blocker, a wisp and a
+	// closed issue.
+	b := NewWithBeadsDir(dir, beadsDir)
+	create := func(opts CreateOptions) *Issue {
+		t.Helper()
+		issue, err := b.Create(opts)
+		if err != nil {
+			t.Fatalf("Create(%q): %v", opts.Title, err)
+		}
+		return issue
+	}
+	epic := create(CreateOptions{Title: "Epic", Type: "epic", Priority: 1, Description: "the plan"})
+	child := create(CreateOptions{Title: "Child", Type: "task", Priority: 2, Parent: epic.ID})
+	blocker := create(CreateOptions{Title: "Blocker", Type: "bug", Priority: 0})
+	wisp := create(CreateOptions{Title: "Wisp", Type: "task", Priority: 3, Ephemeral: true})
+	closed := create(CreateOptions{Title: "Done", Type: "task", Priority: 2})
+	assignee := "gastown/Toast"
+	if err := b.Update(child.ID, UpdateOptions{Assignee: &assignee, AddLabels: []string{"gt:task", "area:sql"}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := b.Update(wisp.ID, UpdateOptions{AddLabels: []string{"gt:message"}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := b.AddDependency(child.ID, blocker.ID); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if err := b.Close(closed.ID); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"list", "--json"},
+		{"list", "--all", "--json"},
+		{"list", "--status=closed", "--json"},
+		{"list", "--label=gt:task", "--json"},
+		{"list", "--label=gt:message", "--json"},
+		{"list", "--assignee=" + assignee, "--json"},
+		{"list", "--no-assignee", "--priority=2", "--json"},
+		{"list", "--parent=" + epic.ID, "--json"},
+		{"list", "--type=bug", "--limit=0", "--json"},
+		{"show", child.ID, epic.ID, "--json"},
+		{"dep", "list", child.ID, "--json"},
+		{"dep", "list", blocker.ID, "--direction=up", "--json"},
+	} {
+		got, ok := ReadSQL(beadsDir, args...)
+		if !ok {
+			t.Errorf("%v: not served by the SQL path", args)
+			continue
+		}
+		cmd := exec.Command("bd", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "BEADS_DIR="+beadsDir)
+		want, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("bd %v: %v", args, err)
+		}
+
+		var gotObjs, wantObjs interface{}
+		if err := json.Unmarshal(got, &gotObjs); err != nil {
+			t.Fatalf("%v: SQL output: %v", args, err)
+		}
+		if err := json.Unmarshal(want, &wantObjs); err != nil {
+			t.Fatalf("%v: bd output: %v", args, err)
+		}
+		if !reflect.DeepEqual(gotObjs, wantObjs) {
+			t.Errorf("%v: SQL output differs from bd:\nsql %s\nbd  %s", args, got, want)
+		}
+	}
+}
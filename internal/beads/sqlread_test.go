@@ -0,0 +1,434 @@
+package beads
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// fakeResult is a canned result set.
+type fakeResult struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+// fakeDolt is a database/sql connector serving canned results, standing in
+// for the Dolt server. It records every query with its arguments.
+type fakeDolt struct {
+	answer     func(query string, args []driver.Value) (*fakeResult, error)
+	connectErr error
+
+	mu       sync.Mutex
+	queries  []string
+	args     [][]driver.Value
+	connects int
+}
+
+func (f *fakeDolt) Connect(context.Context) (driver.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connects++
+	if f.connectErr != nil {
+		return nil, f.connectErr
+	}
+	return &fakeDoltConn{f: f}, nil
+}
+
+func (f *fakeDolt) Driver() driver.Driver { return nil }
+
+func (f *fakeDolt) seen() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+type fakeDoltConn struct{ f *fakeDolt }
+
+func (c *fakeDoltConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake dolt: prepared statements not supported")
+}
+func (c *fakeDoltConn) Close() error              { return nil }
+func (c *fakeDoltConn) Begin() (driver.Tx, error) { return nil, errors.New("fake dolt: read only") }
+
+func (c *fakeDoltConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+	c.f.mu.Lock()
+	c.f.queries = append(c.f.queries, query)
+	c.f.args = append(c.f.args, args)
+	c.f.mu.Unlock()
+	res, err := c.f.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{res: res}, nil
+}
+
+type fakeRows struct {
+	res  *fakeResult
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.res.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.res.rows) {
+		return io.EOF
+	}
+	copy(dest, r.res.rows[r.next])
+	r.next++
+	return nil
+}
+
+// useFakeDolt routes the SQL read path to f for the rest of the test.
+func useFakeDolt(t *testing.T, f *fakeDolt) {
+	t.Helper()
+	pool := newSQLPool()
+	pool.open = func(sqlTarget) (*sql.DB, error) { return sql.OpenDB(f), nil }
+	old := defaultSQLPool
+	defaultSQLPool = pool
+	t.Cleanup(func() { defaultSQLPool = old })
+}
+
+// writeServerMetadata makes dir/.beads a server-mode database on host:port.
+func writeServerMetadata(t *testing.T, dir, host string, port int) string {
+	t.Helper()
+	beadsDir := filepath.Join(dir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"backend":"dolt","dolt_mode":"server","dolt_database":"gastown","dolt_server_host":"` +
+		host + `","dolt_server_port":` + strconv.Itoa(port) + `}`
+	if err := os.WriteFile(filepath.Join(beadsDir, "metadata.json"), []byte(meta), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return beadsDir
+}
+
+func clearDoltEnv(t *testing.T) {
+	for _, k := range []string{"GT_DOLT_HOST", "GT_DOLT_PORT", "GT_DOLT_USER", "GT_DOLT_PASSWORD", "GT_BEADS_SQL", "BEADS_DIR"} {
+		t.Setenv(k, "")
+	}
+}
+
+var issueCols = []string{
+	"id", "title", "status", "priority", "issue_type", "assignee", "created_at", "updated_at",
+	"ephemeral", "hook_bead",
+	// Storage-only columns bd never prints.
+	"content_hash", "source_repo",
+}
+
+func fakeTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func fakeBeads(query string, args []driver.Value) (*fakeResult, error) {
+	switch {
+	case strings.Contains(query, "issue_type = ?") && len(args) > 0 && args[0] == "bogus":
+		return nil, &mysql.MySQLError{Number: 1054, Message: "Unknown column 'issue_type'"}
+	case strings.HasPrefix(query, "SELECT * FROM issues"):
+		return &fakeResult{cols: issueCols, rows: [][]driver.Value{
+			{"gt-agent-toast", "Toast", "open", int64(2), "agent", nil,
+				fakeTime("2026-10-18T09:30:00.123456Z"), fakeTime("2026-10-18T10:00:00Z"), int64(0), []byte("gt-42"), "9f2c", "."},
+			{"gt-agent-nux", "Nux", "open", int64(1), "agent", "mayor",
+				fakeTime("2026-10-17T08:00:00Z"), fakeTime("2026-10-17T08:00:00Z"), int64(1), "", "41ab", "."},
+		}}, nil
+	case strings.HasPrefix(query, "SELECT issue_id, label FROM labels"):
+		return &fakeResult{cols: []string{"issue_id", "label"}, rows: [][]driver.Value{
+			{"gt-agent-toast", "gt:agent"},
+			{"gt-agent-toast", "role:polecat"},
+			{"gt-agent-nux", "gt:agent"},
+		}}, nil
+	case strings.Contains(query, "FROM comments"):
+		return &fakeResult{cols: []string{"issue_id", "COUNT(*)"}, rows: [][]driver.Value{
+			{"gt-agent-toast", int64(1)},
+		}}, nil
+	case strings.HasPrefix(query, "SELECT depends_on_id, COUNT(*)"):
+		return &fakeResult{cols: []string{"depends_on_id", "COUNT(*)"}, rows: [][]driver.Value{
+			{"gt-agent-nux", int64(1)},
+		}}, nil
+	case strings.Contains(query, "COUNT(*)"):
+		return &fakeResult{cols: []string{"issue_id", "COUNT(*)"}, rows: [][]driver.Value{
+			{"gt-agent-toast", int64(3)},
+		}}, nil
+	case strings.HasPrefix(query, "SELECT d.issue_id, d.depends_on_id"):
+		return &fakeResult{cols: []string{"issue_id", "depends_on_id", "type", "title", "status", "priority", "issue_type"},
+			rows: [][]driver.Value{
+				{"gt-agent-toast", "gt-epic", "parent-child", "Epic", "open", int64(1), "epic"},
+			}}, nil
+	case strings.HasPrefix(query, "SELECT d.depends_on_id, d.issue_id"):
+		return &fakeResult{cols: []string{"depends_on_id", "issue_id", "type", "title", "status", "priority", "issue_type"}}, nil
+	case strings.HasPrefix(query, "SELECT i.*"):
+		cols := append(append([]string{}, issueCols...), "gt_dep_target", "gt_dep_type")
+		return &fakeResult{cols: cols, rows: [][]driver.Value{
+			{"hq-cv-1", "Convoy", "open", int64(2), "convoy", nil, fakeTime("2026-10-16T12:00:00Z"), fakeTime("2026-10-16T12:00:00Z"),
+				int64(0), nil, "77aa", ".", "hq-cv-1", "tracks"},
+			{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "external:bd:bd-7", "tracks"},
+		}}, nil
+	}
+	return nil, &mysql.MySQLError{Number: 1146, Message: "unsupported query: " + query}
+}
+
+func TestReadSQL_ListThroughBeads(t *testing.T) {
+	clearDoltEnv(t)
+	fake := &fakeDolt{answer: fakeBeads}
+	useFakeDolt(t, fake)
+	dir := t.TempDir()
+	writeServerMetadata(t, dir, "127.0.0.1", 3307)
+
+	// No bd on PATH: the answer must come from the server.
+	t.Setenv("PATH", "")
+	agents, err := New(dir).WithSQLReads().ListAgentBeads()
+	if err != nil {
+		t.Fatalf("ListAgentBeads: %v", err)
+	}
+	toast := agents["gt-agent-toast"]
+	if toast == nil || toast.Priority != 2 || toast.HookBead != "gt-42" || toast.DependencyCount != 3 ||
+		toast.CreatedAt != "2026-10-18T09:30:00.123456Z" || len(toast.Labels) != 2 || toast.Ephemeral {
+		t.Errorf("toast = %+v", toast)
+	}
+	if nux := agents["gt-agent-nux"]; nux == nil || !nux.Ephemeral || nux.Assignee != "mayor" {
+		t.Errorf("nux = %+v", nux)
+	}
+
+	// User input travels as query arguments, never in the SQL text.
+	want := "SELECT * FROM issues WHERE status NOT IN ('closed', 'tombstone') AND id IN (SELECT issue_id FROM labels WHERE label = ?) ORDER BY priority ASC, created_at DESC LIMIT ?"
+	queries := fake.seen()
+	if len(queries) == 0 || queries[0] != want {
+		t.Errorf("list query = %q\nwant %q", queries, want)
+	}
+	if got := fake.args[0]; !reflect.DeepEqual(got, []driver.Value{"gt:agent", int64(bdDefaultListLimit)}) {
+		t.Errorf("list args = %v", got)
+	}
+
+	// Connections are pooled across calls.
+	if _, err := New(dir).WithSQLReads().ListAgentBeads(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without WithSQLReads the wrapper runs bd, which isn't on PATH.
+	if _, err := New(dir).ListAgentBeads(); err == nil {
+		t.Error("plain wrapper should not read from the server")
+	}
+	fake.mu.Lock()
+	connects := fake.connects
+	fake.mu.Unlock()
+	if connects != 1 {
+		t.Errorf("connects = %d, want 1 (pooled)", connects)
+	}
+}
+
+func TestReadSQL_ShowAndDepList(t *testing.T) {
+	clearDoltEnv(t)
+	fake := &fakeDolt{answer: fakeBeads}
+	useFakeDolt(t, fake)
+	beadsDir := writeServerMetadata(t, t.TempDir(), "127.0.0.1", 3307)
+
+	out, ok := ReadSQL(beadsDir, "show", "gt-agent-toast", "gt-agent-nux", "--json")
+	if !ok {
+		t.Fatal("show not served")
+	}
+	var shown []*Issue
+	if err := json.Unmarshal(out, &shown); err != nil {
+		t.Fatal(err)
+	}
+	if len(shown) != 2 || shown[0].ID != "gt-agent-toast" || shown[0].Parent != "gt-epic" ||
+		len(shown[0].Dependencies) != 1 || shown[0].Dependencies[0].Priority != 1 ||
+		shown[0].Dependencies[0].DependencyType != "parent-child" {
+		t.Errorf("show = %+v", shown)
+	}
+
+	// An ID this database doesn't hold goes to bd for routing.
+	if _, ok := ReadSQL(beadsDir, "show", "hq-missing", "--json"); ok {
+		t.Error("unknown ID should fall back to bd")
+	}
+
+	out, ok = ReadSQL(beadsDir, "dep", "list", "gt_1", "--direction=up", "-t", "tracks", "--json")
+	if !ok {
+		t.Fatal("dep list not served")
+	}
+	var deps []IssueDep
+	if err := json.Unmarshal(out, &deps); err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 2 || deps[0].ID != "hq-cv-1" || deps[0].Type != "convoy" ||
+		deps[1].ID != "external:bd:bd-7" || deps[1].DependencyType != "tracks" {
+		t.Errorf("dep list = %s", out)
+	}
+	if strings.Contains(string(out), "0001-01-01") {
+		t.Errorf("external reference printed zero timestamps: %s", out)
+	}
+	last := len(fake.args) - 1
+	if got := fake.args[last]; !reflect.DeepEqual(got, []driver.Value{"gt_1", `external:%:gt\_1`, "tracks"}) {
+		t.Errorf("dep list args = %q", got)
+	}
+}
+
+func TestReadSQL_FallsBack(t *testing.T) {
+	clearDoltEnv(t)
+	fake := &fakeDolt{answer: fakeBeads}
+	useFakeDolt(t, fake)
+	beadsDir := writeServerMetadata(t, t.TempDir(), "127.0.0.1", 3307)
+
+	for _, args := range [][]string{
+		{"create", "--title=x", "--json"},
+		{"list", "--label=gt:agent"},         // Not JSON
+		{"list", "--json", "--sort=updated"}, // Unsupported flag
+		{"dep", "list", "gt-1", "--direction=sideways", "--json"},
+	} {
+		if _, ok := ReadSQL(beadsDir, args...); ok {
+			t.Errorf("%v should fall back to bd", args)
+		}
+	}
+
+	// SQL errors (schema drift) fall back without marking the server down.
+	if _, ok := ReadSQL(beadsDir, "list", "--json", "--type=bogus"); ok {
+		t.Error("SQL error should fall back to bd")
+	}
+	if _, ok := ReadSQL(beadsDir, "dep", "list", "gt-1", "--json"); !ok {
+		t.Error("server should still be used after a SQL error")
+	}
+
+	// Disabled by environment.
+	t.Setenv("GT_BEADS_SQL", "0")
+	if _, ok := ReadSQL(beadsDir, "list", "--json"); ok {
+		t.Error("GT_BEADS_SQL=0 should disable the SQL path")
+	}
+	t.Setenv("GT_BEADS_SQL", "")
+
+	// Not in server mode.
+	if _, ok := ReadSQL(t.TempDir(), "list", "--json"); ok {
+		t.Error("embedded database should fall back to bd")
+	}
+}
+
+func TestReadSQL_RejectedLogin(t *testing.T) {
+	clearDoltEnv(t)
+	fake := &fakeDolt{answer: fakeBeads, connectErr: &mysql.MySQLError{Number: 1045, Message: "Access denied"}}
+	useFakeDolt(t, fake)
+	beadsDir := writeServerMetadata(t, t.TempDir(), "127.0.0.1", 3307)
+	if _, ok := ReadSQL(beadsDir, "list", "--json"); ok {
+		t.Error("rejected login should fall back to bd")
+	}
+	if _, down := defaultSQLPool.downUntil["127.0.0.1:3307"]; down {
+		t.Error("a rejected login is not a down server")
+	}
+}
+
+func TestReadSQL_DownServerCooldown(t *testing.T) {
+	clearDoltEnv(t)
+	old := defaultSQLPool
+	defaultSQLPool = newSQLPool()
+	t.Cleanup(func() { defaultSQLPool = old })
+
+	// A server that refuses connections is skipped for a cooldown.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadPort := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+	deadDir := writeServerMetadata(t, t.TempDir(), "127.0.0.1", deadPort)
+	if _, ok := ReadSQL(deadDir, "list", "--json"); ok {
+		t.Fatal("down server should fall back to bd")
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(deadPort))
+	defaultSQLPool.mu.Lock()
+	_, down := defaultSQLPool.downUntil[addr]
+	defaultSQLPool.mu.Unlock()
+	if !down {
+		t.Error("refused server not marked down")
+	}
+}
+
+func TestListSQL(t *testing.T) {
+	q, ok := parseReadArgs([]string{"--allow-stale", "list", "--json", "--status=all", "--label", "gt:message",
+		"--assignee=gastown/Toast", "--priority=P1", "--parent=gt-epic", "--limit", "0"})
+	if !ok {
+		t.Fatal("list args not supported")
+	}
+	want := "SELECT * FROM issues WHERE status <> 'tombstone'" +
+		" AND id IN (SELECT issue_id FROM labels WHERE label = ?)" +
+		" AND priority = ?" +
+		" AND id IN (SELECT issue_id FROM dependencies WHERE depends_on_id = ? AND type = 'parent-child')" +
+		" AND assignee = ? ORDER BY priority ASC, created_at DESC"
+	got, args := q.listSQL()
+	if got != want {
+		t.Errorf("listSQL =\n%s\nwant\n%s", got, want)
+	}
+	if wantArgs := []interface{}{"gt:message", 1, "gt-epic", "gastown/Toast"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("listSQL args = %v, want %v", args, wantArgs)
+	}
+
+	q, _ = parseReadArgs([]string{"list", "--json", "--status=open,in_progress", "--no-assignee"})
+	got, args = q.listSQL()
+	if !strings.Contains(got, "status IN (?, ?)") || !strings.Contains(got, "(assignee IS NULL OR assignee = '')") ||
+		strings.Contains(got, "tombstone") || !reflect.DeepEqual(args[:2], []interface{}{"open", "in_progress"}) {
+		t.Errorf("listSQL = %s %v", got, args)
+	}
+
+	// Tombstones are listed only when asked for by status.
+	q, _ = parseReadArgs([]string{"list", "--json", "--status=tombstone"})
+	if got, args := q.listSQL(); !strings.Contains(got, "status IN (?)") || args[0] != "tombstone" {
+		t.Errorf("listSQL = %s %v", got, args)
+	}
+}
+
+// TestReadSQL_MatchesBdJSON compares the SQL path's list output with bd's
+// JSON shape for the same rows (testdata/bd_list_agents.json): the same
+// fields, with storage-only columns dropped, zero values omitted and the
+// per-issue counts always present. sqlread_integration_test.go checks the
+// SQL path against a live bd.
+func TestReadSQL_MatchesBdJSON(t *testing.T) {
+	clearDoltEnv(t)
+	useFakeDolt(t, &fakeDolt{answer: fakeBeads})
+	beadsDir := writeServerMetadata(t, t.TempDir(), "127.0.0.1", 3307)
+
+	out, ok := ReadSQL(beadsDir, "list", "--label=gt:agent", "--json")
+	if !ok {
+		t.Fatal("list not served")
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "bd_list_agents.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotObjs, wantObjs []map[string]interface{}
+	if err := json.Unmarshal(out, &gotObjs); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(want, &wantObjs); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotObjs, wantObjs) {
+		t.Errorf("SQL list output differs from bd:\ngot  %s\nwant %s", out, bytes.TrimSpace(want))
+	}
+}
+
+func TestSQLLikeEscape(t *testing.T) {
+	if got := sqlLikeEscape(`a_b%c\d`); got != `a\_b\%c\\d` {
+		t.Errorf("sqlLikeEscape = %s", got)
+	}
+}
@@ -0,0 +1,264 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/secrets"
+)
+
+// WIPRefPrefix is the namespace of WIP snapshot refs:
+// refs/gt/wip/<polecat>/<timestamp>. Hidden refs are never fetched by
+// default clones or shown by git branch, and keep snapshot commits (and
+// the unpushed commits under them) reachable after the worktree is gone.
+const WIPRefPrefix = "refs/gt/wip/"
+
+// ErrUninspectable is returned by TakeSnapshot when the worktree's state
+// cannot be read, so it is unknown whether it holds unsaved work.
+var ErrUninspectable = errors.New("cannot inspect worktree")
+
+// wipStampFormat is the timestamp segment of a snapshot ref (UTC).
+const wipStampFormat = "20060102T150405Z"
+
+// snapshotExclude lists worktree paths never captured in a snapshot.
+// Materialized secret files are excluded separately.
+var snapshotExclude = []string{".runtime", ".beads"}
+
+// Snapshot is a WIP snapshot: a commit holding a polecat worktree's
+// uncommitted changes on top of its HEAD at the time.
+type Snapshot struct {
+	Polecat    string    `json:"polecat"`
+	Ref        string    `json:"ref"`
+	Commit     string    `json:"commit"`
+	Head       string    `json:"head"` // Worktree HEAD the snapshot sits on
+	Branch     string    `json:"branch,omitempty"`
+	HookedBead string    `json:"hooked_bead,omitempty"`
+	Files      int       `json:"files"` // Files differing from Head
+	Time       time.Time `json:"time"`
+}
+
+// Retention decides which snapshots of a polecat are kept. The newest
+// snapshot is always kept.
+type Retention struct {
+	Keep   int           // Snapshots to keep per polecat (0 = no count limit)
+	MaxAge time.Duration // Drop older snapshots (0 = no age limit)
+}
+
+// SnapshotRef returns the ref of a polecat's snapshot taken at t.
+func SnapshotRef(polecat string, t time.Time) string {
+	return WIPRefPrefix + polecat + "/" + t.UTC().Format(wipStampFormat)
+}
+
+// TakeSnapshot commits the worktree's unsaved work to a snapshot ref in
+// repo, the rig's shared repository. Work is unsaved if the worktree has
+// uncommitted changes or commits not on any remote, and no earlier snapshot
+// holds exactly this state. Returns nil, nil when there is nothing to save,
+// and an error wrapping ErrUninspectable when the worktree cannot be read.
+// If it cannot be told whether HEAD is pushed, HEAD is snapshotted anyway.
+// The worktree, its index and its branches are left untouched.
+func TakeSnapshot(worktree string, repo *git.Git, polecat string, now time.Time) (*Snapshot, error) {
+	wt := git.NewGit(worktree)
+	head, err := wt.Rev("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading HEAD: %v", ErrUninspectable, err)
+	}
+	headTree, err := wt.Rev("HEAD^{tree}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading HEAD tree: %v", ErrUninspectable, err)
+	}
+
+	exclude := append(append([]string(nil), snapshotExclude...), secrets.MaterializedFiles(worktree)...)
+	tree, err := wt.SnapshotTree(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("%w: writing snapshot tree: %v", ErrUninspectable, err)
+	}
+
+	if tree == headTree {
+		if pushed, err := wt.RemoteContains(head); err == nil && pushed {
+			return nil, nil
+		}
+	}
+
+	// Skip if the latest snapshot already holds this state.
+	if snaps, err := ListSnapshots(repoOrWorktree(repo, wt), polecat); err == nil && len(snaps) > 0 {
+		latest := snaps[0]
+		if latest.Head == head {
+			if latestTree, err := wt.Rev(latest.Commit + "^{tree}"); err == nil && latestTree == tree {
+				return nil, nil
+			}
+		}
+	}
+
+	files, err := wt.DiffNames(head, tree)
+	if err != nil {
+		return nil, fmt.Errorf("diffing snapshot: %w", err)
+	}
+	snap := &Snapshot{
+		Polecat: polecat,
+		Ref:     SnapshotRef(polecat, now),
+		Head:    head,
+		Files:   len(files),
+		Time:    now.UTC().Truncate(time.Second),
+	}
+	if branch, err := wt.CurrentBranch(); err == nil && branch != "HEAD" {
+		snap.Branch = branch
+	}
+	if cp, err := Read(worktree); err == nil && cp != nil {
+		snap.HookedBead = cp.HookedBead
+	}
+
+	snap.Commit, err = wt.CommitTree(tree, head, snapshotMessage(snap))
+	if err != nil {
+		return nil, fmt.Errorf("committing snapshot: %w", err)
+	}
+	if err := storeRef(wt, repo, snap.Ref, snap.Commit); err != nil {
+		return nil, fmt.Errorf("storing snapshot ref: %w", err)
+	}
+	return snap, nil
+}
+
+// storeRef writes ref in repo. A linked worktree shares its refs with the
+// repository it was added from, so the ref is written directly; a worktree
+// with a repository of its own pushes the snapshot there.
+func storeRef(wt, repo *git.Git, ref, sha string) error {
+	if repo == nil {
+		return wt.UpdateRef(ref, sha)
+	}
+	wtDir, err1 := wt.CommonDir()
+	repoDir, err2 := repo.CommonDir()
+	if err1 == nil && err2 == nil && wtDir == repoDir {
+		return wt.UpdateRef(ref, sha)
+	}
+	return wt.PushRef(repo.Path(), sha, ref)
+}
+
+func repoOrWorktree(repo, wt *git.Git) *git.Git {
+	if repo != nil {
+		return repo
+	}
+	return wt
+}
+
+func snapshotMessage(s *Snapshot) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "WIP snapshot of %s\n\n", s.Polecat)
+	fmt.Fprintf(&b, "Polecat: %s\n", s.Polecat)
+	if s.Branch != "" {
+		fmt.Fprintf(&b, "Branch: %s\n", s.Branch)
+	}
+	fmt.Fprintf(&b, "Head: %s\n", s.Head)
+	if s.HookedBead != "" {
+		fmt.Fprintf(&b, "Hooked: %s\n", s.HookedBead)
+	}
+	fmt.Fprintf(&b, "Files: %d\n", s.Files)
+	return b.String()
+}
+
+// parseSnapshot builds a snapshot from its ref and commit message.
+func parseSnapshot(ref git.Ref, message string) (Snapshot, bool) {
+	rest := strings.TrimPrefix(ref.Name, WIPRefPrefix)
+	polecat, stamp, ok := strings.Cut(rest, "/")
+	if !ok || rest == ref.Name {
+		return Snapshot{}, false
+	}
+	t, err := time.Parse(wipStampFormat, stamp)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	s := Snapshot{Polecat: polecat, Ref: ref.Name, Commit: ref.SHA, Time: t}
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Branch":
+			s.Branch = value
+		case "Head":
+			s.Head = value
+		case "Hooked":
+			s.HookedBead = value
+		case "Files":
+			s.Files, _ = strconv.Atoi(value)
+		}
+	}
+	return s, true
+}
+
+// ListSnapshots returns the snapshots in repo, newest first. An empty
+// polecat lists every polecat's snapshots.
+func ListSnapshots(repo *git.Git, polecat string) ([]Snapshot, error) {
+	prefix := WIPRefPrefix
+	if polecat != "" {
+		prefix += polecat + "/"
+	}
+	refs, err := repo.ListRefs(prefix)
+	if err != nil {
+		return nil, err
+	}
+	snaps := make([]Snapshot, 0, len(refs))
+	for _, ref := range refs {
+		msg, _ := repo.CommitMessage(ref.SHA)
+		if s, ok := parseSnapshot(ref, msg); ok {
+			snaps = append(snaps, s)
+		}
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.After(snaps[j].Time) })
+	return snaps, nil
+}
+
+// Expired returns the snapshots that retention drops, given snapshots
+// newest first. Each polecat's newest snapshot is kept regardless of age.
+func (r Retention) Expired(snaps []Snapshot, now time.Time) []Snapshot {
+	var expired []Snapshot
+	seen := make(map[string]int)
+	for _, s := range snaps {
+		n := seen[s.Polecat]
+		seen[s.Polecat]++
+		if n == 0 {
+			continue
+		}
+		if (r.Keep > 0 && n >= r.Keep) || (r.MaxAge > 0 && now.Sub(s.Time) > r.MaxAge) {
+			expired = append(expired, s)
+		}
+	}
+	return expired
+}
+
+// PruneSnapshots deletes the snapshots of polecat (all polecats if empty)
+// that retention drops. Returns the number deleted.
+func PruneSnapshots(repo *git.Git, polecat string, r Retention, now time.Time) (int, error) {
+	snaps, err := ListSnapshots(repo, polecat)
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, s := range r.Expired(snaps, now) {
+		if err := repo.DeleteRef(s.Ref); err != nil {
+			return pruned, fmt.Errorf("deleting %s: %w", s.Ref, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// RestoreSnapshot adds a worktree of repo at dest on a new branch started
+// from the snapshot's HEAD, with the snapshot's changes applied as
+// uncommitted work.
+func RestoreSnapshot(repo *git.Git, s Snapshot, dest, branch string) error {
+	if s.Head == "" {
+		return fmt.Errorf("snapshot %s has no recorded HEAD", s.Ref)
+	}
+	if err := repo.WorktreeAddFromRef(dest, branch, s.Head); err != nil {
+		return fmt.Errorf("adding worktree: %w", err)
+	}
+	if err := git.NewGit(dest).ReadTreeReset(s.Commit); err != nil {
+		return fmt.Errorf("applying snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// setupRigRepo creates a bare repo with one commit on main and a linked
+// worktree on a polecat branch, like a rig's .repo.git and a polecat.
+func setupRigRepo(t *testing.T) (repo *git.Git, worktree string) {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run(src, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(src, "main.go"), "package main\n")
+	writeFile(t, filepath.Join(src, "old.go"), "package main\n")
+	writeFile(t, filepath.Join(src, ".gitignore"), "*.log\n")
+	run(src, "add", ".")
+	run(src, "commit", "-q", "-m", "initial")
+
+	bare := filepath.Join(dir, ".repo.git")
+	run(dir, "clone", "-q", "--bare", src, bare)
+	run(bare, "fetch", "-q", "origin", "+refs/heads/*:refs/remotes/origin/*")
+
+	worktree = filepath.Join(dir, "polecats", "Toast", "rig")
+	run(bare, "worktree", "add", "-q", "-b", "polecat/Toast", worktree, "main")
+	return git.NewGitWithDir(bare, ""), worktree
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTakeSnapshotAndRestore(t *testing.T) {
+	repo, worktree := setupRigRepo(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// Clean worktree on a pushed commit: nothing to save.
+	snap, err := TakeSnapshot(worktree, repo, "Toast", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap != nil {
+		t.Fatalf("clean worktree snapshotted: %+v", snap)
+	}
+
+	writeFile(t, filepath.Join(worktree, "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(worktree, "new.go"), "package main\n")
+	writeFile(t, filepath.Join(worktree, "debug.log"), "ignored\n")
+	if err := os.Remove(filepath.Join(worktree, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(worktree, ".runtime"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(worktree, ".runtime", "state"), "x\n")
+
+	snap, err = TakeSnapshot(worktree, repo, "Toast", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap == nil {
+		t.Fatal("dirty worktree not snapshotted")
+	}
+	if snap.Ref != "refs/gt/wip/Toast/20261018T120000Z" || snap.Branch != "polecat/Toast" || snap.Files != 3 {
+		t.Errorf("snapshot = %+v", snap)
+	}
+
+	// The worktree's own state is untouched.
+	status, err := git.NewGit(worktree).Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Added) != 0 {
+		t.Errorf("snapshot staged files in the real index: %+v", status)
+	}
+
+	// Unchanged since the last snapshot: nothing new to save.
+	if again, err := TakeSnapshot(worktree, repo, "Toast", now.Add(time.Minute)); err != nil || again != nil {
+		t.Errorf("repeat snapshot = %+v, %v; want nil", again, err)
+	}
+
+	snaps, err := ListSnapshots(repo, "Toast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].Commit != snap.Commit || snaps[0].Head != snap.Head || snaps[0].Files != 3 {
+		t.Fatalf("ListSnapshots = %+v", snaps)
+	}
+
+	// Rehydrate into a fresh worktree after the original is gone.
+	if err := os.RemoveAll(worktree); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(t.TempDir(), "restored")
+	if err := RestoreSnapshot(repo, snaps[0], dest, "polecat/Toast-restored"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "main.go"))
+	if err != nil || !strings.Contains(string(data), "func main") {
+		t.Errorf("main.go not restored: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "new.go")); err != nil {
+		t.Errorf("new.go not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "old.go")); !os.IsNotExist(err) {
+		t.Errorf("old.go should stay deleted: %v", err)
+	}
+	for _, f := range []string{"debug.log", ".runtime/state"} {
+		if _, err := os.Stat(filepath.Join(dest, f)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be captured: %v", f, err)
+		}
+	}
+	if dirty, _ := git.NewGit(dest).HasUncommittedChanges(); !dirty {
+		t.Error("restored changes should be uncommitted")
+	}
+}
+
+func TestTakeSnapshotUnpushedCommits(t *testing.T) {
+	repo, worktree := setupRigRepo(t)
+	writeFile(t, filepath.Join(worktree, "feature.go"), "package main\n")
+	for _, args := range [][]string{
+		{"add", "feature.go"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "wip"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktree
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	snap, err := TakeSnapshot(worktree, repo, "Toast", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap == nil || snap.Files != 0 {
+		t.Fatalf("clean worktree with unpushed commit: snapshot = %+v, want one with no file changes", snap)
+	}
+}
+
+func TestRetentionExpired(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	snaps := []Snapshot{ // newest first
+		{Polecat: "Toast", Ref: "t1", Time: now.Add(-time.Hour)},
+		{Polecat: "Nux", Ref: "n1", Time: now.Add(-30 * day)}, // newest of Nux: kept despite age
+		{Polecat: "Toast", Ref: "t2", Time: now.Add(-2 * time.Hour)},
+		{Polecat: "Toast", Ref: "t3", Time: now.Add(-3 * time.Hour)}, // over Keep
+		{Polecat: "Nux", Ref: "n2", Time: now.Add(-31 * day)},        // too old
+	}
+	r := Retention{Keep: 2, MaxAge: 7 * day}
+
+	var refs []string
+	for _, s := range r.Expired(snaps, now) {
+		refs = append(refs, s.Ref)
+	}
+	if got := strings.Join(refs, " "); got != "t3 n2" {
+		t.Errorf("expired = %q, want \"t3 n2\"", got)
+	}
+	if got := (Retention{}).Expired(snaps, now); len(got) != 0 {
+		t.Errorf("no limits expired %d snapshots", len(got))
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	repo, worktree := setupRigRepo(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		writeFile(t, filepath.Join(worktree, "main.go"), strings.Repeat("// edit\n", i+1))
+		if snap, err := TakeSnapshot(worktree, repo, "Toast", now.Add(time.Duration(i)*time.Minute)); err != nil || snap == nil {
+			t.Fatalf("snapshot %d: %+v, %v", i, snap, err)
+		}
+	}
+
+	pruned, err := PruneSnapshots(repo, "", Retention{Keep: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps, _ := ListSnapshots(repo, "Toast")
+	if pruned != 2 || len(snaps) != 1 || !snaps[0].Time.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("pruned %d, left %+v; want the newest only", pruned, snaps)
+	}
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// backupPassphraseEnv holds the backup passphrase when --passphrase-file
+// isn't given.
+const backupPassphraseEnv = "GT_BACKUP_PASSPHRASE"
+
+var (
+	backupDir            string
+	backupEncrypt        bool
+	backupPassphraseFile string
+	backupNoDolt         bool
+	backupJSON           bool
+	backupRestoreTo      string
+	backupForce          bool
+	backupSkipGit        bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:     "backup",
+	GroupID: GroupServices,
+	Short:   "Back up and restore the whole town",
+	Long: `Back up and restore a Gas Town.
+
+A backup is a single versioned archive (gt-backup-<town>-<time>.tar.gz) with
+a sha256 checksum file beside it. It holds:
+  - town config: mayor/*.json, settings, config, role overrides, plugins
+  - town beads config and formulas (.beads), and the secrets store
+  - per rig: config.json, settings, role overrides, plugins, beads config,
+    namepool state, overlays and sealed secrets
+  - every Dolt database (beads, mail, ...)
+
+Git worktrees are not archived. The archive records each rig's remote and
+crew branches, and restore re-clones them.
+
+With --encrypt the archive is sealed with AES-256-GCM under a passphrase
+read from --passphrase-file or $GT_BACKUP_PASSPHRASE. Only encrypted backups
+include the secrets master key.
+
+Scheduled backups are configured in mayor/daemon.json:
+  "patrols": {"backup": {"enabled": true, "keep": 7, "passphrase_file": "..."}}`,
+	RunE: requireSubcommand,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Write a new backup",
+	Long: `Write a backup of the current town.
+
+A running local Dolt server is backed up live with DOLT_BACKUP; a stopped
+server's data directories are copied. Databases on a remote server are
+skipped with a warning.
+
+Examples:
+  gt backup create
+  gt backup create --dir /mnt/backups
+  GT_BACKUP_PASSPHRASE=... gt backup create --encrypt`,
+	Args: cobra.NoArgs,
+	RunE: runBackupCreate,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupList,
+}
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify <archive>",
+	Short: "Check a backup's checksums and manifest",
+	Long: `Check a backup end to end: the archive against its .sha256 file, decryption,
+and every file against the checksums in its manifest.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupVerify,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Rebuild a town from a backup",
+	Long: `Rebuild a town from a backup.
+
+The archive is verified first. Config and state files are written back,
+then each rig's shared repo, mayor clone, refinery worktree and crew
+clones are re-created from its git remote. Existing checkouts are left
+alone. Dolt databases are restored while the server is stopped; existing
+databases are only replaced with --force.
+
+The target is the current town, or --to for a fresh directory. Restoring
+over an existing town requires --force.
+
+Examples:
+  gt backup restore ~/.gt/backups/hq/gt-backup-hq-20260301T020000Z.tar.gz --to ~/gt
+  gt backup restore backup.tar.gz --force --skip-git`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupRestore,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{backupCreateCmd, backupListCmd} {
+		c.Flags().StringVar(&backupDir, "dir", "", "Backup directory (default ~/.gt/backups/<town>)")
+	}
+	for _, c := range []*cobra.Command{backupCreateCmd, backupVerifyCmd, backupRestoreCmd} {
+		c.Flags().StringVar(&backupPassphraseFile, "passphrase-file", "", "Read the encryption passphrase from this file (default $"+backupPassphraseEnv+")")
+	}
+	backupCreateCmd.Flags().BoolVar(&backupEncrypt, "encrypt", false, "Encrypt the backup with a passphrase")
+	backupCreateCmd.Flags().BoolVar(&backupNoDolt, "no-dolt", false, "Don't include Dolt databases")
+	backupListCmd.Flags().BoolVar(&backupJSON, "json", false, "Output as JSON")
+	backupVerifyCmd.Flags().BoolVar(&backupJSON, "json", false, "Output the manifest as JSON")
+	backupRestoreCmd.Flags().StringVar(&backupRestoreTo, "to", "", "Town directory to restore into (default: current town)")
+	backupRestoreCmd.Flags().BoolVar(&backupForce, "force", false, "Overwrite an existing town's files and databases")
+	backupRestoreCmd.Flags().BoolVar(&backupSkipGit, "skip-git", false, "Don't re-clone rig repos, worktrees or crew")
+	backupRestoreCmd.Flags().BoolVar(&backupNoDolt, "no-dolt", false, "Don't restore Dolt databases")
+
+	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupVerifyCmd, backupRestoreCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+// backupPassphrase returns the passphrase from --passphrase-file or the
+// environment. It is empty when neither is set.
+func backupPassphrase() (string, error) {
+	if backupPassphraseFile != "" {
+		data, err := os.ReadFile(backupPassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("reading passphrase file: %w", err)
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return "", fmt.Errorf("passphrase file %s is empty", backupPassphraseFile)
+		}
+		return passphrase, nil
+	}
+	return os.Getenv(backupPassphraseEnv), nil
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var passphrase string
+	if backupEncrypt {
+		if passphrase, err = backupPassphrase(); err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("--encrypt needs a passphrase: use --passphrase-file or set %s", backupPassphraseEnv)
+		}
+	}
+
+	archive, m, err := backup.Create(townRoot, backup.CreateOptions{
+		Dir:        backupDir,
+		Passphrase: passphrase,
+		GTVersion:  Version,
+		SkipDolt:   backupNoDolt,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Backup written: %s\n", style.Success.Render("✓"), archive.Path)
+	fmt.Printf("  %d files, %d rig(s), %d database(s), %s",
+		len(m.Files), len(m.Rigs), len(m.Databases), formatBytes(archive.Size))
+	if archive.Encrypted {
+		fmt.Print(", encrypted")
+	}
+	fmt.Println()
+	for _, w := range m.Warnings {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), w)
+	}
+	return nil
+}
+
+func runBackupList(cmd *cobra.Command, args []string) error {
+	dir := backupDir
+	if dir == "" {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return fmt.Errorf("not in a Gas Town workspace (use --dir): %w", err)
+		}
+		dir = backup.DefaultDir(townRoot)
+	}
+
+	archives, err := backup.List(dir)
+	if err != nil {
+		return err
+	}
+	if backupJSON {
+		if archives == nil {
+			archives = []backup.Archive{}
+		}
+		return outputJSON(archives)
+	}
+	if len(archives) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no backups in "+dir+")"))
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "CREATED", Width: 20},
+		style.Column{Name: "AGE", Width: 6},
+		style.Column{Name: "SIZE", Width: 10},
+		style.Column{Name: "ENC", Width: 4},
+		style.Column{Name: "FILE", Width: 60},
+	)
+	for _, a := range archives {
+		enc := ""
+		if a.Encrypted {
+			enc = "yes"
+		}
+		table.AddRow(a.CreatedAt.Local().Format("2006-01-02 15:04:05"), formatAge(a.CreatedAt),
+			formatBytes(a.Size), enc, filepath.Base(a.Path))
+	}
+	fmt.Printf("Backups in %s:\n\n", dir)
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runBackupVerify(cmd *cobra.Command, args []string) error {
+	passphrase, err := backupPassphrase()
+	if err != nil {
+		return err
+	}
+	m, err := backup.Verify(args[0], passphrase)
+	if err != nil {
+		return fmt.Errorf("backup verification failed: %w", err)
+	}
+	if backupJSON {
+		return outputJSON(m)
+	}
+	fmt.Printf("%s Backup OK: %s\n", style.Success.Render("✓"), args[0])
+	fmt.Printf("  Town %s, created %s by gt %s (format v%d)\n",
+		m.Town, m.CreatedAt.Local().Format(time.RFC3339), valueOr(m.GTVersion, "?"), m.Version)
+	fmt.Printf("  %d files, %d rig(s), %d database(s)\n", len(m.Files), len(m.Rigs), len(m.Databases))
+	for _, w := range m.Warnings {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), w)
+	}
+	return nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	target := backupRestoreTo
+	if target == "" {
+		townRoot, err := workspace.FindFromCwd()
+		if err != nil || townRoot == "" {
+			return errors.New("not in a Gas Town workspace: use --to to choose where to restore")
+		}
+		target = townRoot
+	}
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	passphrase, err := backupPassphrase()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restoring %s into %s...\n", filepath.Base(args[0]), target)
+	res, err := backup.Restore(args[0], target, backup.RestoreOptions{
+		Passphrase: passphrase,
+		Force:      backupForce,
+		SkipGit:    backupSkipGit,
+		SkipDolt:   backupNoDolt,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Restored town %s from backup of %s\n", style.Success.Render("✓"),
+		res.Manifest.Town, res.Manifest.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Printf("  Files:     %d\n", res.Files)
+	if len(res.Rigs) > 0 {
+		fmt.Printf("  Rigs:      %s\n", strings.Join(res.Rigs, ", "))
+	}
+	if len(res.Crew) > 0 {
+		fmt.Printf("  Crew:      %s\n", strings.Join(res.Crew, ", "))
+	}
+	if len(res.Databases) > 0 {
+		fmt.Printf("  Databases: %s\n", strings.Join(res.Databases, ", "))
+	}
+	for _, w := range append(res.Manifest.Warnings, res.Warnings...) {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), w)
+	}
+	fmt.Println()
+	fmt.Println("Next: gt dolt start, then gt doctor --fix")
+	return nil
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
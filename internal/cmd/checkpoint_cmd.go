@@ -28,7 +28,12 @@ Checkpoint data includes:
 - Git branch and last commit
 - Timestamp
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.
+
+WIP snapshots go further and save the work itself: the daemon commits each
+dirty polecat worktree to a hidden ref (refs/gt/wip/<polecat>/<timestamp>)
+in the rig's repository, and removing a polecat snapshots it first. Use
+'gt checkpoint list' and 'gt checkpoint restore' to find and rehydrate them.`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
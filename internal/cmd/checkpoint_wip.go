@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	checkpointListJSON      bool
+	checkpointRestoreRef    string
+	checkpointRestorePath   string
+	checkpointRestoreBranch string
+)
+
+var checkpointListCmd = &cobra.Command{
+	Use:   "list <rig>[/<polecat>]",
+	Short: "List WIP snapshots of polecat worktrees",
+	Long: `List the WIP snapshots stored in a rig's repository, newest first.
+
+The daemon snapshots dirty polecat worktrees periodically, and removing a
+polecat snapshots its unsaved work first. Each snapshot is a commit on a
+hidden ref (refs/gt/wip/<polecat>/<timestamp>) holding the worktree's
+uncommitted changes on top of its HEAD, which also keeps unpushed commits
+reachable after the worktree is gone.
+
+Examples:
+  gt checkpoint list gastown
+  gt checkpoint list gastown/Toast --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointList,
+}
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore <rig>/<polecat>",
+	Short: "Rehydrate a WIP snapshot into a new worktree",
+	Long: `Restore a polecat's WIP snapshot into a new worktree.
+
+The worktree is added on a new branch started from the commit the polecat
+was on, with the snapshot's changes applied as uncommitted work. The
+polecat's original branch name is used if it is free.
+
+By default the newest snapshot is restored; --snapshot picks another by ref
+or by its number in 'gt checkpoint list' (1 = newest).
+
+Examples:
+  gt checkpoint restore gastown/Toast
+  gt checkpoint restore gastown/Toast --snapshot 3 --path /tmp/toast`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointRestore,
+}
+
+func init() {
+	checkpointCmd.AddCommand(checkpointListCmd)
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
+
+	checkpointListCmd.Flags().BoolVar(&checkpointListJSON, "json", false, "Output as JSON")
+
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestoreRef, "snapshot", "",
+		"Snapshot to restore: ref or list number (default: newest)")
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestorePath, "path", "",
+		"Worktree path (default: <rig>/restored/<polecat>-<timestamp>)")
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestoreBranch, "branch", "",
+		"Branch for the restored worktree (default: the polecat's branch if free)")
+}
+
+func runCheckpointList(cmd *cobra.Command, args []string) error {
+	rigName, polecatName, _ := strings.Cut(args[0], "/")
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+	repo, err := mgr.WIPRepo()
+	if err != nil {
+		return fmt.Errorf("finding rig repository: %w", err)
+	}
+	snaps, err := checkpoint.ListSnapshots(repo, polecatName)
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+
+	if checkpointListJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snaps)
+	}
+
+	if len(snaps) == 0 {
+		fmt.Printf("%s No WIP snapshots in %s\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("WIP snapshots (%d)", len(snaps))))
+	for i, s := range snaps {
+		fmt.Printf("  %2d. %s  %s\n", i+1, style.Bold.Render(s.Polecat), style.Dim.Render(formatAge(s.Time)))
+		fmt.Printf("      %s\n", s.Ref)
+		details := []string{fmt.Sprintf("%d file(s) changed", s.Files)}
+		if s.Branch != "" {
+			details = append(details, "branch "+s.Branch)
+		}
+		if s.Head != "" {
+			details = append(details, "on "+s.Head[:min(12, len(s.Head))])
+		}
+		if s.HookedBead != "" {
+			details = append(details, "hooked "+s.HookedBead)
+		}
+		fmt.Printf("      %s\n", style.Dim.Render(strings.Join(details, ", ")))
+	}
+	return nil
+}
+
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	rigName, polecatName, err := parseAddress(args[0])
+	if err != nil {
+		return err
+	}
+	mgr, r, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+	repo, err := mgr.WIPRepo()
+	if err != nil {
+		return fmt.Errorf("finding rig repository: %w", err)
+	}
+	snaps, err := checkpoint.ListSnapshots(repo, polecatName)
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+	snap, err := selectSnapshot(snaps, checkpointRestoreRef)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	stamp := snap.Time.UTC().Format("20060102-150405")
+	dest := checkpointRestorePath
+	if dest == "" {
+		dest = filepath.Join(r.Path, "restored", polecatName+"-"+stamp)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+
+	branch := checkpointRestoreBranch
+	if branch == "" {
+		branch = snap.Branch
+		if branch == "" {
+			branch = "restore/" + polecatName
+		}
+		if exists, err := repo.BranchExists(branch); err != nil || exists {
+			branch += "-restored-" + stamp
+		}
+	}
+
+	if err := checkpoint.RestoreSnapshot(repo, snap, dest, branch); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Restored %s\n", style.Bold.Render("✓"), snap.Ref)
+	fmt.Printf("  Worktree: %s\n", dest)
+	fmt.Printf("  Branch:   %s\n", branch)
+	fmt.Printf("  Changes:  %d file(s), uncommitted\n", snap.Files)
+	if snap.HookedBead != "" {
+		fmt.Printf("  Hooked:   %s\n", snap.HookedBead)
+	}
+	return nil
+}
+
+// selectSnapshot picks a snapshot from snaps (newest first) by ref, by
+// 1-based list number, or the newest when sel is empty.
+func selectSnapshot(snaps []checkpoint.Snapshot, sel string) (checkpoint.Snapshot, error) {
+	if len(snaps) == 0 {
+		return checkpoint.Snapshot{}, fmt.Errorf("no WIP snapshots")
+	}
+	if sel == "" {
+		return snaps[0], nil
+	}
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 || n > len(snaps) {
+			return checkpoint.Snapshot{}, fmt.Errorf("snapshot %d out of range (1-%d)", n, len(snaps))
+		}
+		return snaps[n-1], nil
+	}
+	for _, s := range snaps {
+		if s.Ref == sel || strings.HasSuffix(s.Ref, "/"+sel) || strings.HasPrefix(s.Commit, sel) {
+			return s, nil
+		}
+	}
+	return checkpoint.Snapshot{}, fmt.Errorf("snapshot %q not found", sel)
+}
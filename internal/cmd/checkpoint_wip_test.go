@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
+)
+
+func TestSelectSnapshot(t *testing.T) {
+	snaps := []checkpoint.Snapshot{
+		{Ref: "refs/gt/wip/Toast/20261018T120000Z", Commit: "abc123"},
+		{Ref: "refs/gt/wip/Toast/20261018T110000Z", Commit: "def456"},
+	}
+	tests := []struct {
+		sel     string
+		want    string
+		wantErr bool
+	}{
+		{"", "abc123", false},
+		{"2", "def456", false},
+		{"20261018T110000Z", "def456", false},
+		{"refs/gt/wip/Toast/20261018T120000Z", "abc123", false},
+		{"def4", "def456", false},
+		{"3", "", true},
+		{"nope", "", true},
+	}
+	for _, tt := range tests {
+		s, err := selectSnapshot(snaps, tt.sel)
+		if (err != nil) != tt.wantErr || s.Commit != tt.want {
+			t.Errorf("selectSnapshot(%q) = %q, %v; want %q", tt.sel, s.Commit, err, tt.want)
+		}
+	}
+	if _, err := selectSnapshot(nil, ""); err == nil {
+		t.Error("expected error with no snapshots")
+	}
+}
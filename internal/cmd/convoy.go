@@ -18,6 +18,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	convoypkg "github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -67,7 +69,11 @@ var (
 	convoyOwner        string
 	convoyOwned        bool
 	convoyMerge        string
+	convoyAfter        []string
+	convoyMaxInFlight  int
+	convoyDeadline     string
 	convoyStatusJSON   bool
+	convoyStatusGraph  bool
 	convoyListJSON     bool
 	convoyListStatus   string
 	convoyListAll      bool
@@ -166,6 +172,9 @@ COMMANDS:
   add       Add issues to an existing convoy (reopens if closed)
   close     Close a convoy (verifies all items done, or use --force)
   land      Land an owned convoy (cleanup worktrees, close convoy)
+  after     Make a convoy wait for other convoys to land
+  limit     Cap how many of a convoy's issues are in flight at once
+  deadline  Set the date a convoy should land by
   status    Show convoy progress, tracked issues, and active workers
   list      List convoys (the dashboard view)`,
 }
@@ -187,6 +196,11 @@ The --merge flag sets the merge strategy for all work in the convoy:
   mr      Create merge-request bead, refinery processes (default)
   local   Keep on feature branch (for upstream PRs, human review)
 
+The --after flag makes the convoy wait for other convoys to land before any
+of its issues are fed (see 'gt convoy after'). The --max-in-flight flag caps
+how many of its issues are dispatched at once (see 'gt convoy limit'). The
+--deadline flag sets the date it should land by (see 'gt convoy deadline').
+
 Examples:
   gt convoy create "Deploy v2.0" gt-abc bd-xyz
   gt convoy create "Release prep" gt-abc --notify           # defaults to mayor/
@@ -194,7 +208,9 @@ Examples:
   gt convoy create "Feature rollout" gt-a gt-b --owner mayor/ --notify ops/
   gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release
   gt convoy create --owned "Manual deploy" gt-abc           # caller-managed lifecycle
-  gt convoy create "Quick fix" gt-abc --merge=direct        # bypass refinery`,
+  gt convoy create "Quick fix" gt-abc --merge=direct        # bypass refinery
+  gt convoy create "API rollout" gt-a gt-b --after hq-cv-xyz --max-in-flight 2
+  gt convoy create "Launch" gt-a gt-b --deadline 2026-11-01`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvoyCreate,
 }
@@ -205,7 +221,17 @@ var convoyStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a convoy.
 
 Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Without an ID, shows status of all active convoys.
+
+With --graph, shows convoy dependencies instead: each convoy under the
+convoys it waits on, with progress, in-flight load, and what it is waiting
+for. Without an ID the graph covers all open convoys; with one, every convoy
+connected to it.
+
+Examples:
+  gt convoy status hq-cv-abc
+  gt convoy status --graph
+  gt convoy status hq-cv-abc --graph --json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConvoyStatus,
 }
@@ -269,6 +295,10 @@ A convoy is "stranded" when:
   - Has tracked issues that are ready but unassigned, OR
   - Has 0 tracked issues (empty — needs auto-close via convoy check)
 
+Convoys waiting on another convoy to land (gt convoy after) are not stranded,
+and at most a convoy's remaining in-flight capacity (gt convoy limit) is
+reported as ready.
+
 Use this to detect convoys that need feeding or cleanup. The Deacon patrol
 runs this periodically and dispatches dogs to feed stranded convoys.
 
@@ -331,10 +361,14 @@ func init() {
 	convoyCreateCmd.Flags().Lookup("notify").NoOptDefVal = "mayor/"
 	convoyCreateCmd.Flags().BoolVar(&convoyOwned, "owned", false, "Mark convoy as caller-managed lifecycle (no automatic witness/refinery registration)")
 	convoyCreateCmd.Flags().StringVar(&convoyMerge, "merge", "", "Merge strategy: direct (push to main), mr (merge queue, default), local (keep on branch)")
+	convoyCreateCmd.Flags().StringSliceVar(&convoyAfter, "after", nil, "Convoys that must land before this one is fed (repeatable)")
+	convoyCreateCmd.Flags().IntVar(&convoyMaxInFlight, "max-in-flight", 0, "Max tracked issues in flight at once (0 = unlimited)")
+	convoyCreateCmd.Flags().StringVar(&convoyDeadline, "deadline", "", "Date the convoy should land by (YYYY-MM-DD or RFC 3339)")
 
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
+	convoyStatusCmd.Flags().BoolVar(&convoyStatusGraph, "graph", false, "Show the convoy dependency graph")
 
 	// List flags
 	convoyListCmd.Flags().BoolVar(&convoyListJSON, "json", false, "Output as JSON")
@@ -397,6 +431,18 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if convoyMaxInFlight < 0 {
+		return fmt.Errorf("invalid --max-in-flight value %d: must be 0 or more", convoyMaxInFlight)
+	}
+
+	var deadline time.Time
+	if convoyDeadline != "" {
+		var err error
+		if deadline, err = convoypkg.ParseDeadlineArg(convoyDeadline); err != nil {
+			return err
+		}
+	}
+
 	// If first arg looks like an issue ID (has beads prefix), treat all args as issues
 	// and auto-generate a name from the first issue's title
 	if looksLikeIssueID(name) {
@@ -445,6 +491,12 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		description += fmt.Sprintf("\nMolecule: %s", convoyMolecule)
 	}
+	if convoyMaxInFlight > 0 {
+		description += fmt.Sprintf("\nMaxInFlight: %d", convoyMaxInFlight)
+	}
+	if !deadline.IsZero() {
+		description = convoypkg.SetDeadline(description, deadline)
+	}
 
 	// Generate convoy ID with cv- prefix
 	convoyID := fmt.Sprintf("hq-cv-%s", generateShortID())
@@ -486,7 +538,7 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	trackedCount := 0
 	for _, issueID := range trackedIssues {
 		// Use --type=tracks for non-blocking tracking relation
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		depArgs := []string{"dep", "add", convoyID, federatedTrackRef(filepath.Dir(townBeads), issueID), "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = filepath.Dir(townBeads)
 		var depStderr bytes.Buffer
@@ -503,6 +555,20 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Add 'blocks' relations from the convoys this one waits on
+	var after []string
+	for _, blockerID := range convoyAfter {
+		if _, err := showConvoyBead(townBeads, blockerID); err != nil {
+			style.PrintWarning("couldn't wait on %s: %v", blockerID, err)
+			continue
+		}
+		if err := addConvoyBlocker(townBeads, convoyID, blockerID); err != nil {
+			style.PrintWarning("couldn't wait on %s: %v", blockerID, err)
+			continue
+		}
+		after = append(after, blockerID)
+	}
+
 	// Output
 	fmt.Printf("%s Created convoy 🚚 %s\n\n", style.Bold.Render("✓"), convoyID)
 	fmt.Printf("  Name:     %s\n", name)
@@ -522,6 +588,15 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		fmt.Printf("  Molecule: %s\n", convoyMolecule)
 	}
+	if len(after) > 0 {
+		fmt.Printf("  After:    %s\n", strings.Join(after, ", "))
+	}
+	if convoyMaxInFlight > 0 {
+		fmt.Printf("  Limit:    %d in flight\n", convoyMaxInFlight)
+	}
+	if !deadline.IsZero() {
+		fmt.Printf("  Deadline: %s\n", formatConvoyTime(deadline))
+	}
 	if convoyOwned {
 		fmt.Printf("  Owned:    %s\n", style.Warning.Render("caller-managed lifecycle"))
 	}
@@ -597,7 +672,7 @@ func runConvoyAdd(cmd *cobra.Command, args []string) error {
 	// Add 'tracks' relations for each issue
 	addedCount := 0
 	for _, issueID := range issuesToAdd {
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		depArgs := []string{"dep", "add", convoyID, federatedTrackRef(filepath.Dir(townBeads), issueID), "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = filepath.Dir(townBeads)
 		var depStderr bytes.Buffer
@@ -1239,8 +1314,9 @@ func findStrandedConvoys(townBeads string) ([]strandedConvoyInfo, error) {
 	}
 
 	var convoys []struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return nil, fmt.Errorf("parsing convoy list: %w", err)
@@ -1265,14 +1341,30 @@ func findStrandedConvoys(townBeads string) ([]strandedConvoyInfo, error) {
 			continue
 		}
 
+		// Convoys waiting on another convoy to land aren't stranded; they
+		// are fed when it lands.
+		schedule := loadConvoySchedule(townBeads, convoy.ID, convoy.Description, tracked)
+		if schedule.Gated() {
+			continue
+		}
+
 		// Find ready issues (open, not blocked, no live assignee)
 		var readyIssues []string
+		inFlight := schedule.InFlight
 		for _, t := range tracked {
 			if isReadyIssue(t) {
 				readyIssues = append(readyIssues, t.ID)
+				if convoypkg.IsInFlight(t.Status, t.Assignee) {
+					inFlight-- // Dead worker: re-dispatch doesn't add load
+				}
 			}
 		}
 
+		// Report no more than the convoy's in-flight limit allows.
+		if c := convoypkg.Capacity(schedule.MaxInFlight, inFlight); c >= 0 && len(readyIssues) > c {
+			readyIssues = readyIssues[:c]
+		}
+
 		if len(readyIssues) > 0 {
 			stranded = append(stranded, strandedConvoyInfo{
 				ID:          convoy.ID,
@@ -1489,6 +1581,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	// If no ID provided, show all active convoys
 	if len(args) == 0 {
+		if convoyStatusGraph {
+			return runConvoyGraph(townBeads, "")
+		}
 		return showAllConvoyStatus(townBeads)
 	}
 
@@ -1503,6 +1598,10 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		convoyID = resolved
 	}
 
+	if convoyStatusGraph {
+		return runConvoyGraph(townBeads, convoyID)
+	}
+
 	// Get convoy details
 	showArgs := []string{"show", convoyID, "--json"}
 	showCmd := exec.Command("bd", showArgs...)
@@ -1551,21 +1650,32 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	schedule := loadConvoySchedule(townBeads, convoyID, convoy.Description, tracked)
+
+	// Forecast only open convoys; a landed convoy's ETA is moot.
+	var forecast *convoypkg.Forecast
+	if normalizeConvoyStatus(convoy.Status) == convoyStatusOpen {
+		f := forecastConvoy(townBeads, convoy.Description, tracked, loadCycleTimes(townBeads))
+		forecast = &f
+	}
+
 	if convoyStatusJSON {
 		lifecycle := "system-managed"
 		if isOwned {
 			lifecycle = "caller-managed"
 		}
 		type jsonStatus struct {
-			ID            string             `json:"id"`
-			Title         string             `json:"title"`
-			Status        string             `json:"status"`
-			Owned         bool               `json:"owned"`
-			Lifecycle     string             `json:"lifecycle"`
-			MergeStrategy string             `json:"merge_strategy,omitempty"`
-			Tracked       []trackedIssueInfo `json:"tracked"`
-			Completed     int                `json:"completed"`
-			Total         int                `json:"total"`
+			ID            string              `json:"id"`
+			Title         string              `json:"title"`
+			Status        string              `json:"status"`
+			Owned         bool                `json:"owned"`
+			Lifecycle     string              `json:"lifecycle"`
+			MergeStrategy string              `json:"merge_strategy,omitempty"`
+			Schedule      convoySchedule      `json:"schedule"`
+			Forecast      *convoypkg.Forecast `json:"forecast,omitempty"`
+			Tracked       []trackedIssueInfo  `json:"tracked"`
+			Completed     int                 `json:"completed"`
+			Total         int                 `json:"total"`
 		}
 		out := jsonStatus{
 			ID:            convoy.ID,
@@ -1574,6 +1684,8 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			Owned:         isOwned,
 			Lifecycle:     lifecycle,
 			MergeStrategy: parseConvoyMergeStrategy(convoy.Description),
+			Schedule:      schedule,
+			Forecast:      forecast,
 			Tracked:       tracked,
 			Completed:     completed,
 			Total:         len(tracked),
@@ -1596,6 +1708,12 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 	if merge != "" {
 		fmt.Printf("  Merge:     %s\n", merge)
 	}
+	printConvoySchedule(schedule)
+	if forecast != nil {
+		printConvoyForecast(*forecast)
+	} else if deadline := convoypkg.ParseDeadline(convoy.Description); !deadline.IsZero() {
+		fmt.Printf("  Deadline:  %s\n", formatConvoyTime(deadline))
+	}
 	fmt.Printf("  Progress:  %d/%d completed\n", completed, len(tracked))
 	fmt.Printf("  Created:   %s\n", convoy.CreatedAt)
 	if convoy.ClosedAt != "" {
@@ -1715,11 +1833,12 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 	}
 
 	var convoys []struct {
-		ID        string   `json:"id"`
-		Title     string   `json:"title"`
-		Status    string   `json:"status"`
-		CreatedAt string   `json:"created_at"`
-		Labels    []string `json:"labels"`
+		ID          string   `json:"id"`
+		Title       string   `json:"title"`
+		Status      string   `json:"status"`
+		Description string   `json:"description"`
+		CreatedAt   string   `json:"created_at"`
+		Labels      []string `json:"labels"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return fmt.Errorf("parsing convoy list: %w", err)
@@ -1728,14 +1847,16 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 	if convoyListJSON {
 		// Enrich each convoy with tracked issues and completion counts
 		type convoyListEntry struct {
-			ID        string             `json:"id"`
-			Title     string             `json:"title"`
-			Status    string             `json:"status"`
-			CreatedAt string             `json:"created_at"`
-			Tracked   []trackedIssueInfo `json:"tracked"`
-			Completed int                `json:"completed"`
-			Total     int                `json:"total"`
-		}
+			ID        string              `json:"id"`
+			Title     string              `json:"title"`
+			Status    string              `json:"status"`
+			CreatedAt string              `json:"created_at"`
+			Tracked   []trackedIssueInfo  `json:"tracked"`
+			Completed int                 `json:"completed"`
+			Total     int                 `json:"total"`
+			Forecast  *convoypkg.Forecast `json:"forecast,omitempty"`
+		}
+		var ct *convoypkg.CycleTimes
 		enriched := make([]convoyListEntry, 0, len(convoys))
 		for _, c := range convoys {
 			tracked, err := getTrackedIssues(townBeads, c.ID)
@@ -1752,7 +1873,7 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 					completed++
 				}
 			}
-			enriched = append(enriched, convoyListEntry{
+			entry := convoyListEntry{
 				ID:        c.ID,
 				Title:     c.Title,
 				Status:    c.Status,
@@ -1760,7 +1881,15 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 				Tracked:   tracked,
 				Completed: completed,
 				Total:     len(tracked),
-			})
+			}
+			if normalizeConvoyStatus(c.Status) == convoyStatusOpen {
+				if ct == nil {
+					ct = loadCycleTimes(townBeads)
+				}
+				f := forecastConvoy(townBeads, c.Description, tracked, ct)
+				entry.Forecast = &f
+			}
+			enriched = append(enriched, entry)
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -1779,6 +1908,7 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s\n\n", style.Bold.Render("Convoys"))
+	var ct *convoypkg.CycleTimes
 	for i, c := range convoys {
 		status := formatConvoyStatus(c.Status)
 		ownedTag := ""
@@ -1786,6 +1916,19 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 			ownedTag = " " + style.Warning.Render("[owned]")
 		}
 		fmt.Printf("  %d. 🚚 %s: %s %s%s\n", i+1, c.ID, c.Title, status, ownedTag)
+
+		// Open convoys with a deadline get a forecast line.
+		if normalizeConvoyStatus(c.Status) != convoyStatusOpen || convoypkg.ParseDeadline(c.Description).IsZero() {
+			continue
+		}
+		tracked, err := getTrackedIssues(townBeads, c.ID)
+		if err != nil {
+			continue
+		}
+		if ct == nil {
+			ct = loadCycleTimes(townBeads)
+		}
+		fmt.Printf("       %s\n", formatConvoyDue(forecastConvoy(townBeads, c.Description, tracked, ct)))
 	}
 	fmt.Printf("\nUse 'gt convoy status <id>' or 'gt convoy status <n>' for detailed view.\n")
 
@@ -1794,11 +1937,12 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 
 // printConvoyTree displays convoys with their child issues in a tree format.
 func printConvoyTree(townBeads string, convoys []struct {
-	ID        string   `json:"id"`
-	Title     string   `json:"title"`
-	Status    string   `json:"status"`
-	CreatedAt string   `json:"created_at"`
-	Labels    []string `json:"labels"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Status      string   `json:"status"`
+	Description string   `json:"description"`
+	CreatedAt   string   `json:"created_at"`
+	Labels      []string `json:"labels"`
 }) error {
 	for _, c := range convoys {
 		// Get tracked issues for this convoy
@@ -1973,23 +2117,37 @@ func getTrackedIssues(townBeads, convoyID string) ([]trackedIssueInfo, error) {
 	}
 
 	// Unwrap external:prefix:id format from dep IDs before use
+	rawIDs := make([]string, len(deps))
 	for i := range deps {
+		rawIDs[i] = deps[i].ID
 		deps[i].ID = extractIssueID(deps[i].ID)
 	}
 
+	// Beads in (or slung to) federated towns get their status from there.
+	fedCfg, _ := federation.LoadConfig(townRoot)
+	remote := federatedRefs(fedCfg, deps, rawIDs)
+
 	// Refresh status via cross-rig lookup. bd dep list returns status from
 	// the dependency record in HQ beads which is never updated when cross-rig
 	// issues (e.g., gt-* tracked by hq-* convoys) are closed in their home rig.
-	issueIDs := make([]string, len(deps))
+	issueIDs := make([]string, 0, len(deps))
 	for i, dep := range deps {
-		issueIDs[i] = dep.ID
+		if _, ok := remote[i]; !ok {
+			issueIDs = append(issueIDs, dep.ID)
+		}
 	}
 	freshDetails := getIssueDetailsBatch(issueIDs)
 	for i, dep := range deps {
+		if _, ok := remote[i]; ok {
+			continue
+		}
 		if details, ok := freshDetails[dep.ID]; ok {
 			applyFreshIssueDetails(&deps[i], details)
 		}
 	}
+	if len(remote) > 0 {
+		refreshFederatedIssues(townRoot, deps, remote)
+	}
 
 	// Collect non-closed issue IDs for worker lookup
 	openIssueIDs := make([]string, 0, len(deps))
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	convoypkg "github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var convoyDeadlineCmd = &cobra.Command{
+	Use:   "deadline <convoy-id> <date|none>",
+	Short: "Set the date a convoy should land by",
+	Long: `Set a convoy's deadline: the date its tracked work should have landed by.
+
+The date is YYYY-MM-DD (end of that day, local time) or an RFC 3339
+timestamp. 'none' removes the deadline.
+
+gt convoy status and gt convoy list forecast an ETA from historical per-rig
+cycle times (sling to merged, from the events log) and the issues left, and
+compare it to the deadline. The daemon escalates through the escalation
+routes when a convoy's forecast slips past its deadline, and again if the
+deadline passes with work remaining.
+
+Examples:
+  gt convoy deadline hq-cv-abc 2026-11-01
+  gt convoy deadline hq-cv-abc 2026-11-01T17:00:00-07:00
+  gt convoy deadline hq-cv-abc none`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConvoyDeadline,
+}
+
+func init() {
+	convoyCmd.AddCommand(convoyDeadlineCmd)
+}
+
+func runConvoyDeadline(cmd *cobra.Command, args []string) error {
+	convoyID := args[0]
+	var deadline time.Time
+	if args[1] != "none" {
+		var err error
+		if deadline, err = convoypkg.ParseDeadlineArg(args[1]); err != nil {
+			return err
+		}
+	}
+
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return err
+	}
+	c, err := showConvoyBead(townBeads, convoyID)
+	if err != nil {
+		return err
+	}
+
+	updateCmd := exec.Command("bd", "update", convoyID, "--description="+convoypkg.SetDeadline(c.Description, deadline))
+	updateCmd.Dir = filepath.Dir(townBeads)
+	var stderr bytes.Buffer
+	updateCmd.Stderr = &stderr
+	if err := updateCmd.Run(); err != nil {
+		return fmt.Errorf("updating convoy: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if deadline.IsZero() {
+		fmt.Printf("%s Removed deadline from 🚚 %s\n", style.Bold.Render("✓"), convoyID)
+	} else {
+		fmt.Printf("%s 🚚 %s: due %s\n", style.Bold.Render("✓"), convoyID, formatConvoyTime(deadline))
+	}
+	return nil
+}
+
+// loadCycleTimes learns cycle times from the town's events log. A log that
+// can't be read gives no ETAs rather than an error.
+func loadCycleTimes(townBeads string) *convoypkg.CycleTimes {
+	ct, err := convoypkg.LoadCycleTimes(filepath.Dir(townBeads), time.Now().Add(-convoypkg.CycleTimeWindow))
+	if err != nil {
+		style.PrintWarning("reading events log for ETAs: %v", err)
+		return convoypkg.NewCycleTimes()
+	}
+	return ct
+}
+
+// forecastConvoy forecasts a convoy from its description and tracked issues.
+func forecastConvoy(townBeads, description string, tracked []trackedIssueInfo, ct *convoypkg.CycleTimes) convoypkg.Forecast {
+	issues := make([]convoypkg.Issue, len(tracked))
+	for i, t := range tracked {
+		issues[i] = convoypkg.Issue{ID: t.ID, Status: t.Status, Assignee: t.Assignee}
+	}
+	return ct.Forecast(filepath.Dir(townBeads), issues,
+		convoypkg.ParseMaxInFlight(description), convoypkg.ParseDeadline(description), time.Now())
+}
+
+// printConvoyForecast prints the deadline and ETA lines of gt convoy status.
+func printConvoyForecast(f convoypkg.Forecast) {
+	if f.Deadline != nil {
+		fmt.Printf("  Deadline:  %s %s\n", formatConvoyTime(*f.Deadline), formatSLA(f.SLA))
+	}
+	if f.Remaining == 0 {
+		return
+	}
+	if f.ETA == nil {
+		fmt.Printf("  ETA:       %s\n", style.Dim.Render("unknown (no merge history)"))
+		return
+	}
+	fmt.Printf("  ETA:       %s %s\n", formatConvoyTime(*f.ETA),
+		style.Dim.Render(fmt.Sprintf("(%d left, based on %d merges)", f.Remaining, f.Samples)))
+}
+
+// formatConvoyDue is the deadline summary of a gt convoy list line, e.g.
+// "due Nov 1 17:00, ETA Nov 3 09:00 (at risk)". Empty without a deadline.
+func formatConvoyDue(f convoypkg.Forecast) string {
+	if f.Deadline == nil {
+		return ""
+	}
+	s := "due " + formatConvoyTime(*f.Deadline)
+	if f.ETA != nil && f.Remaining > 0 {
+		s += ", ETA " + formatConvoyTime(*f.ETA)
+	}
+	return s + " " + formatSLA(f.SLA)
+}
+
+// formatSLA renders an SLA status for terminal output.
+func formatSLA(s convoypkg.SLAStatus) string {
+	switch s {
+	case convoypkg.SLAOnTrack:
+		return style.Success.Render("(on track)")
+	case convoypkg.SLAAtRisk:
+		return style.Warning.Render("(at risk)")
+	case convoypkg.SLAOverdue:
+		return style.Error.Render("(overdue)")
+	case convoypkg.SLAUnknown:
+		return style.Dim.Render("(no forecast)")
+	default:
+		return ""
+	}
+}
+
+// formatConvoyTime formats a deadline or ETA in local time, with the year
+// only when it isn't this year.
+func formatConvoyTime(t time.Time) string {
+	t = t.Local()
+	if t.Year() != time.Now().Year() {
+		return t.Format("Jan 2 2006 15:04")
+	}
+	return t.Format("Jan 2 15:04")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/federation"
+)
+
+// federatedRef is a convoy-tracked bead whose status lives in another town.
+type federatedRef struct {
+	town string
+	id   string
+}
+
+// federatedTrackRef turns "<town>:<bead>" for a peer town into the external
+// reference a convoy tracks it by ("external:<town>:<bead>"). Other IDs
+// are returned unchanged.
+func federatedTrackRef(townRoot, issueID string) string {
+	town, id, ok := strings.Cut(issueID, ":")
+	if !ok || id == "" || strings.Contains(id, ":") {
+		return issueID
+	}
+	cfg, err := federation.LoadConfig(townRoot)
+	if err != nil || cfg == nil || cfg.Peers[town] == nil {
+		return issueID
+	}
+	return "external:" + town + ":" + id
+}
+
+// federatedRefs finds the tracked beads whose status lives in a peer town:
+// external references to a peer's beads, and local beads slung to a peer
+// (labeled with the bead the peer created). Keyed by index into deps;
+// rawIDs are the dependency IDs before unwrapping.
+func federatedRefs(cfg *federation.Config, deps []trackedDependency, rawIDs []string) map[int]federatedRef {
+	refs := make(map[int]federatedRef)
+	if cfg == nil || len(cfg.Peers) == 0 {
+		return refs
+	}
+	for i, dep := range deps {
+		if parts := strings.SplitN(rawIDs[i], ":", 3); len(parts) == 3 && parts[0] == "external" && cfg.Peers[parts[1]] != nil {
+			refs[i] = federatedRef{town: parts[1], id: parts[2]}
+			continue
+		}
+		if town, id, ok := federation.ParseRemoteLabel(dep.Labels); ok && cfg.Peers[town] != nil {
+			refs[i] = federatedRef{town: town, id: id}
+		}
+	}
+	return refs
+}
+
+// refreshFederatedIssues asks each peer town for the status of its beads
+// and applies it. Beads slung to a peer keep their local ID; beads tracked
+// directly are shown as <town>:<bead>. Unreachable peers leave the recorded
+// status in place.
+func refreshFederatedIssues(townRoot string, deps []trackedDependency, refs map[int]federatedRef) {
+	byTown := make(map[string][]string)
+	for _, ref := range refs {
+		byTown[ref.town] = append(byTown[ref.town], ref.id)
+	}
+	statuses := make(map[federatedRef]federation.BeadStatus)
+	for town, ids := range byTown {
+		reply, err := federation.Send(context.Background(), townRoot, town, &federation.Envelope{
+			Kind:  federation.KindStatus,
+			Beads: ids,
+		})
+		if err != nil {
+			continue
+		}
+		for _, st := range reply.Beads {
+			statuses[federatedRef{town: town, id: st.ID}] = st
+		}
+	}
+
+	for i, ref := range refs {
+		if deps[i].ID == ref.id {
+			deps[i].ID = ref.town + ":" + ref.id
+		}
+		st, ok := statuses[ref]
+		if !ok {
+			continue
+		}
+		deps[i].Status = st.Status
+		deps[i].Blocked = false
+		if deps[i].Title == "" {
+			deps[i].Title = st.Title
+		}
+		if st.Assignee != "" {
+			deps[i].Assignee = ref.town + "@" + st.Assignee
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/federation"
+)
+
+func TestFederatedTrackRef(t *testing.T) {
+	townRoot := t.TempDir()
+	if got := federatedTrackRef(townRoot, "acme:gt-abc"); got != "acme:gt-abc" {
+		t.Errorf("without federation config = %q", got)
+	}
+
+	cfg := &federation.Config{Peers: map[string]*federation.Peer{
+		"acme": {Transport: federation.TransportSSH, Host: "box", Token: "t"},
+	}}
+	if err := federation.SaveConfig(townRoot, cfg); err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]string{
+		"acme:gt-abc":          "external:acme:gt-abc",
+		"other:gt-abc":         "other:gt-abc",
+		"gt-abc":               "gt-abc",
+		"external:gt:gt-abc":   "external:gt:gt-abc",
+		"acme:external:gt-abc": "acme:external:gt-abc",
+	} {
+		if got := federatedTrackRef(townRoot, in); got != want {
+			t.Errorf("federatedTrackRef(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFederatedRefs(t *testing.T) {
+	cfg := &federation.Config{Peers: map[string]*federation.Peer{
+		"acme": {Transport: federation.TransportSSH, Host: "box", Token: "t"},
+	}}
+	rawIDs := []string{"external:acme:gt-abc", "external:gt-mol:gt-mol-1", "hq-1", "hq-2"}
+	deps := []trackedDependency{
+		{ID: "gt-abc"},
+		{ID: "gt-mol-1"},
+		{ID: "hq-1", Labels: []string{federation.RemoteLabel("acme", "gt-xyz")}},
+		{ID: "hq-2", Labels: []string{federation.RemoteLabel("ghost", "gt-xyz")}},
+	}
+
+	refs := federatedRefs(cfg, deps, rawIDs)
+	if len(refs) != 2 || refs[0] != (federatedRef{"acme", "gt-abc"}) || refs[2] != (federatedRef{"acme", "gt-xyz"}) {
+		t.Errorf("refs = %+v", refs)
+	}
+	if len(federatedRefs(nil, deps, rawIDs)) != 0 {
+		t.Error("refs found without federation config")
+	}
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	convoypkg "github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var convoyAfterRemove bool
+
+var convoyAfterCmd = &cobra.Command{
+	Use:   "after <convoy-id> <blocker-convoy-id> [blocker-convoy-id...]",
+	Short: "Make a convoy wait for other convoys to land",
+	Long: `Make a convoy wait for other convoys to land before its work is fed.
+
+Adds a 'blocks' relation from each blocker convoy to the convoy. Until every
+blocker has landed (closed), convoy observers and the Deacon's stranded-convoy
+patrol dispatch none of the convoy's issues. When the last blocker lands, the
+observer that closed it feeds the waiting convoy.
+
+Relations that would form a cycle are refused.
+
+Examples:
+  gt convoy after hq-cv-api hq-cv-schema          # api starts when schema lands
+  gt convoy after hq-cv-ui hq-cv-api hq-cv-auth   # ui waits on both
+  gt convoy after hq-cv-ui hq-cv-auth --remove    # drop a relation`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runConvoyAfter,
+}
+
+var convoyLimitCmd = &cobra.Command{
+	Use:   "limit <convoy-id> <n>",
+	Short: "Cap how many of a convoy's issues are in flight at once",
+	Long: `Set a convoy's max-in-flight limit: how many of its tracked issues may be
+dispatched (hooked, in progress, or slung but not started) at the same time.
+
+Convoy observers top the convoy up to its limit as issues close; without a
+limit they feed one issue per completion. The Deacon's stranded-convoy patrol
+reports at most the remaining capacity as ready. A limit of 0 removes it.
+
+Examples:
+  gt convoy limit hq-cv-abc 3    # at most 3 issues in flight
+  gt convoy limit hq-cv-abc 0    # no limit`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConvoyLimit,
+}
+
+func init() {
+	convoyAfterCmd.Flags().BoolVar(&convoyAfterRemove, "remove", false, "Remove the relations instead of adding them")
+
+	convoyCmd.AddCommand(convoyAfterCmd)
+	convoyCmd.AddCommand(convoyLimitCmd)
+}
+
+// convoyBead is a convoy as returned by bd show.
+type convoyBead struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Type        string `json:"issue_type"`
+	Description string `json:"description"`
+}
+
+// showConvoyBead loads a convoy and verifies it is one.
+func showConvoyBead(townBeads, convoyID string) (*convoyBead, error) {
+	showCmd := exec.Command("bd", "show", convoyID, "--json")
+	showCmd.Dir = filepath.Dir(townBeads)
+	var stdout bytes.Buffer
+	showCmd.Stdout = &stdout
+
+	if err := showCmd.Run(); err != nil {
+		return nil, fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+
+	var convoys []convoyBead
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy data: %w", err)
+	}
+	if len(convoys) == 0 {
+		return nil, fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	if convoys[0].Type != "convoy" {
+		return nil, fmt.Errorf("'%s' is not a convoy (type: %s)", convoyID, convoys[0].Type)
+	}
+	return &convoys[0], nil
+}
+
+// addConvoyBlocker makes convoyID wait on blockerID, refusing cycles.
+func addConvoyBlocker(townBeads, convoyID, blockerID string) error {
+	townRoot := filepath.Dir(townBeads)
+	blockersOf := func(id string) []string {
+		var ids []string
+		for _, b := range convoypkg.GetBlockers(townRoot, id) {
+			ids = append(ids, b.ID)
+		}
+		return ids
+	}
+	if convoypkg.WouldCycle(blockersOf, convoyID, blockerID) {
+		return fmt.Errorf("%s already waits on %s; this would create a cycle", blockerID, convoyID)
+	}
+
+	depCmd := exec.Command("bd", "dep", "add", convoyID, blockerID, "--type=blocks")
+	depCmd.Dir = townRoot
+	var stderr bytes.Buffer
+	depCmd.Stderr = &stderr
+	if err := depCmd.Run(); err != nil {
+		return fmt.Errorf("%w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func runConvoyAfter(cmd *cobra.Command, args []string) error {
+	convoyID, blockerIDs := args[0], args[1:]
+
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return err
+	}
+	if _, err := showConvoyBead(townBeads, convoyID); err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, blockerID := range blockerIDs {
+		if convoyAfterRemove {
+			rmCmd := exec.Command("bd", "dep", "remove", convoyID, blockerID)
+			rmCmd.Dir = filepath.Dir(townBeads)
+			var stderr bytes.Buffer
+			rmCmd.Stderr = &stderr
+			if err := rmCmd.Run(); err != nil {
+				style.PrintWarning("couldn't remove %s: %s", blockerID, strings.TrimSpace(stderr.String()))
+				continue
+			}
+			fmt.Printf("%s %s no longer waits on %s\n", style.Bold.Render("✓"), convoyID, blockerID)
+			changed++
+			continue
+		}
+
+		if _, err := showConvoyBead(townBeads, blockerID); err != nil {
+			style.PrintWarning("%v", err)
+			continue
+		}
+		if err := addConvoyBlocker(townBeads, convoyID, blockerID); err != nil {
+			style.PrintWarning("couldn't add %s: %v", blockerID, err)
+			continue
+		}
+		fmt.Printf("%s %s waits on %s\n", style.Bold.Render("✓"), convoyID, blockerID)
+		changed++
+	}
+
+	if changed == 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func runConvoyLimit(cmd *cobra.Command, args []string) error {
+	convoyID := args[0]
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid limit %q: must be a non-negative integer", args[1])
+	}
+
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return err
+	}
+	c, err := showConvoyBead(townBeads, convoyID)
+	if err != nil {
+		return err
+	}
+
+	updateCmd := exec.Command("bd", "update", convoyID, "--description="+convoypkg.SetMaxInFlight(c.Description, n))
+	updateCmd.Dir = filepath.Dir(townBeads)
+	var stderr bytes.Buffer
+	updateCmd.Stderr = &stderr
+	if err := updateCmd.Run(); err != nil {
+		return fmt.Errorf("updating convoy: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if n == 0 {
+		fmt.Printf("%s Removed in-flight limit from 🚚 %s\n", style.Bold.Render("✓"), convoyID)
+	} else {
+		fmt.Printf("%s 🚚 %s: at most %d issue(s) in flight\n", style.Bold.Render("✓"), convoyID, n)
+	}
+	return nil
+}
+
+// convoySchedule is a convoy's feeding constraints and current load.
+type convoySchedule struct {
+	After       []convoypkg.Blocker `json:"after,omitempty"`      // Convoys it waits on
+	WaitingOn   []string            `json:"waiting_on,omitempty"` // Those not landed yet
+	MaxInFlight int                 `json:"max_in_flight,omitempty"`
+	InFlight    int                 `json:"in_flight"`
+	Capacity    int                 `json:"capacity"` // -1 = unlimited
+}
+
+// Gated reports whether the convoy is waiting on another convoy to land.
+func (s convoySchedule) Gated() bool {
+	return len(s.WaitingOn) > 0
+}
+
+// loadConvoySchedule builds a convoy's schedule from its blockers, its
+// description and its tracked issues.
+func loadConvoySchedule(townBeads, convoyID, description string, tracked []trackedIssueInfo) convoySchedule {
+	s := convoySchedule{
+		After:       convoypkg.GetBlockers(filepath.Dir(townBeads), convoyID),
+		MaxInFlight: convoypkg.ParseMaxInFlight(description),
+	}
+	for _, b := range convoypkg.Pending(s.After) {
+		s.WaitingOn = append(s.WaitingOn, b.ID)
+	}
+	for _, t := range tracked {
+		if convoypkg.IsInFlight(t.Status, t.Assignee) {
+			s.InFlight++
+		}
+	}
+	s.Capacity = convoypkg.Capacity(s.MaxInFlight, s.InFlight)
+	return s
+}
+
+// printConvoySchedule prints the schedule lines of gt convoy status.
+func printConvoySchedule(s convoySchedule) {
+	if len(s.After) > 0 {
+		parts := make([]string, 0, len(s.After))
+		for _, b := range s.After {
+			if b.Landed() {
+				parts = append(parts, b.ID+" "+style.Success.Render("✓"))
+			} else {
+				parts = append(parts, b.ID+" "+style.Warning.Render("(waiting)"))
+			}
+		}
+		fmt.Printf("  After:     %s\n", strings.Join(parts, ", "))
+	}
+	if s.MaxInFlight > 0 {
+		fmt.Printf("  In flight: %d/%d\n", s.InFlight, s.MaxInFlight)
+	}
+}
+
+// convoyGraphNode is one convoy in gt convoy status --graph.
+type convoyGraphNode struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Status      string   `json:"status"`
+	Depth       int      `json:"depth"`
+	After       []string `json:"after,omitempty"`
+	WaitingOn   []string `json:"waiting_on,omitempty"`
+	Completed   int      `json:"completed"`
+	Total       int      `json:"total"`
+	InFlight    int      `json:"in_flight"`
+	MaxInFlight int      `json:"max_in_flight,omitempty"`
+}
+
+// runConvoyGraph shows the dependency graph of all open convoys, or of the
+// convoys connected to convoyID (in either direction, any status).
+func runConvoyGraph(townBeads, convoyID string) error {
+	townRoot := filepath.Dir(townBeads)
+
+	var ids []string
+	if convoyID == "" {
+		listCmd := exec.Command("bd", "list", "--type=convoy", "--status=open", "--json")
+		listCmd.Dir = townRoot
+		var stdout bytes.Buffer
+		listCmd.Stdout = &stdout
+		if err := listCmd.Run(); err != nil {
+			return fmt.Errorf("listing convoys: %w", err)
+		}
+		var convoys []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+			return fmt.Errorf("parsing convoy list: %w", err)
+		}
+		for _, c := range convoys {
+			ids = append(ids, c.ID)
+		}
+	} else {
+		seen := map[string]bool{convoyID: true}
+		queue := []string{convoyID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			ids = append(ids, id)
+			neighbors := append(convoypkg.GetBlockers(townRoot, id), convoypkg.GetDependents(townRoot, id)...)
+			for _, n := range neighbors {
+				if !seen[n.ID] {
+					seen[n.ID] = true
+					queue = append(queue, n.ID)
+				}
+			}
+		}
+	}
+
+	nodes := make(map[string]*convoyGraphNode, len(ids))
+	for _, id := range ids {
+		c, err := showConvoyBead(townBeads, id)
+		if err != nil {
+			style.PrintWarning("skipping %s: %v", id, err)
+			continue
+		}
+		tracked, err := getTrackedIssues(townBeads, id)
+		if err != nil {
+			style.PrintWarning("skipping convoy %s: %v", id, err)
+			continue
+		}
+		s := loadConvoySchedule(townBeads, id, c.Description, tracked)
+		node := &convoyGraphNode{
+			ID:          c.ID,
+			Title:       c.Title,
+			Status:      c.Status,
+			WaitingOn:   s.WaitingOn,
+			Total:       len(tracked),
+			InFlight:    s.InFlight,
+			MaxInFlight: s.MaxInFlight,
+		}
+		for _, b := range s.After {
+			node.After = append(node.After, b.ID)
+		}
+		for _, t := range tracked {
+			if t.Status == "closed" {
+				node.Completed++
+			}
+		}
+		nodes[id] = node
+	}
+
+	blockedBy := make(map[string][]string, len(nodes))
+	for id, n := range nodes {
+		blockedBy[id] = n.After
+	}
+	depths, err := convoypkg.Depths(blockedBy)
+	if err != nil {
+		return err
+	}
+	ordered := make([]*convoyGraphNode, 0, len(nodes))
+	for id, n := range nodes {
+		n.Depth = depths[id]
+		ordered = append(ordered, n)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Depth != ordered[j].Depth {
+			return ordered[i].Depth < ordered[j].Depth
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	if convoyStatusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ordered)
+	}
+
+	if len(ordered) == 0 {
+		fmt.Println("No active convoys.")
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render("Convoy Graph"))
+	fmt.Print(renderConvoyGraph(ordered))
+	return nil
+}
+
+// renderConvoyGraph draws convoys as a forest: each convoy under the convoys
+// it waits on. A convoy waiting on several is drawn in full once, under a
+// blocker one level up, and referenced under the rest. nodes must be sorted
+// by depth.
+func renderConvoyGraph(nodes []*convoyGraphNode) string {
+	byID := make(map[string]*convoyGraphNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	children := make(map[string][]*convoyGraphNode)
+	for _, n := range nodes {
+		for _, b := range n.After {
+			if _, ok := byID[b]; ok {
+				children[b] = append(children[b], n)
+			}
+		}
+	}
+
+	var b strings.Builder
+	drawn := make(map[string]bool)
+	var draw func(n *convoyGraphNode, prefix, connector, childPrefix string)
+	draw = func(n *convoyGraphNode, prefix, connector, childPrefix string) {
+		fmt.Fprintf(&b, "%s%s%s\n", prefix, connector, formatConvoyGraphNode(n))
+		drawn[n.ID] = true
+		kids := children[n.ID]
+		for i, kid := range kids {
+			connector, next := "├─▶ ", "│   "
+			if i == len(kids)-1 {
+				connector, next = "└─▶ ", "    "
+			}
+			if drawn[kid.ID] || kid.Depth != n.Depth+1 {
+				fmt.Fprintf(&b, "%s%s%s\n", prefix+childPrefix, connector, style.Dim.Render("↪ "+kid.ID+" (shown elsewhere)"))
+				continue
+			}
+			draw(kid, prefix+childPrefix, connector, next)
+		}
+	}
+	for _, n := range nodes {
+		if n.Depth == 0 {
+			draw(n, "  ", "", "")
+		}
+	}
+	return b.String()
+}
+
+// formatConvoyGraphNode renders one convoy line of the graph.
+func formatConvoyGraphNode(n *convoyGraphNode) string {
+	icon := style.Warning.Render("●")
+	switch {
+	case n.Status == "closed":
+		icon = style.Success.Render("✓")
+	case len(n.WaitingOn) > 0:
+		icon = style.Dim.Render("⏸")
+	}
+
+	line := fmt.Sprintf("%s %s: %s %s", icon, n.ID, n.Title, style.Dim.Render(fmt.Sprintf("(%d/%d)", n.Completed, n.Total)))
+	if n.MaxInFlight > 0 && n.Status != "closed" {
+		line += fmt.Sprintf("  %d/%d in flight", n.InFlight, n.MaxInFlight)
+	}
+	if len(n.WaitingOn) > 0 {
+		line += "  " + style.Dim.Render("waiting on "+strings.Join(n.WaitingOn, ", "))
+	}
+	return line
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderConvoyGraph(t *testing.T) {
+	// schema → api → ui, and auth → ui. ui is drawn in full under api, one
+	// level up, and referenced under auth.
+	nodes := []*convoyGraphNode{
+		{ID: "hq-cv-auth", Title: "Auth", Status: "open", Depth: 0, Completed: 1, Total: 1},
+		{ID: "hq-cv-schema", Title: "Schema", Status: "closed", Depth: 0, Completed: 2, Total: 2},
+		{ID: "hq-cv-api", Title: "API", Status: "open", Depth: 1, After: []string{"hq-cv-schema"}, Total: 3, InFlight: 2, MaxInFlight: 2},
+		{ID: "hq-cv-ui", Title: "UI", Status: "open", Depth: 2, After: []string{"hq-cv-api", "hq-cv-auth"},
+			WaitingOn: []string{"hq-cv-api", "hq-cv-auth"}, Total: 4},
+	}
+
+	out := renderConvoyGraph(nodes)
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5:\n%s", len(lines), out)
+	}
+
+	if !strings.Contains(lines[0], "hq-cv-auth: Auth") {
+		t.Errorf("line 0 = %q, want auth root", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  └─▶ ") || !strings.Contains(lines[1], "↪ hq-cv-ui (shown elsewhere)") {
+		t.Errorf("line 1 = %q, want ui reference under auth", lines[1])
+	}
+	if !strings.Contains(lines[2], "hq-cv-schema: Schema") {
+		t.Errorf("line 2 = %q, want schema root", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "  └─▶ ") || !strings.Contains(lines[3], "hq-cv-api: API") || !strings.Contains(lines[3], "2/2 in flight") {
+		t.Errorf("line 3 = %q, want api under schema", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], "      └─▶ ") || !strings.Contains(lines[4], "hq-cv-ui: UI") ||
+		!strings.Contains(lines[4], "waiting on hq-cv-api, hq-cv-auth") {
+		t.Errorf("line 4 = %q, want ui under api", lines[4])
+	}
+}
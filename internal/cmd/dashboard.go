@@ -13,6 +13,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
+	"github.com/steveyegge/gastown/internal/inbound"
 	"github.com/steveyegge/gastown/internal/web"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -99,6 +101,20 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("creating dashboard handler: %w", err)
 		}
+
+		// Mount the GitHub/GitLab webhook receiver. It answers 404 until
+		// settings/inbound.json exists.
+		mux := http.NewServeMux()
+		mux.Handle("/webhooks/", inbound.NewReceiver(townRoot, inbound.NewTown(townRoot), func(format string, args ...interface{}) {
+			fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", args...)
+		}))
+		// Mount the federation endpoint for peer towns using the http
+		// transport. It answers 404 until settings/federation.json exists.
+		mux.Handle(federation.DeliverPath, federation.NewReceiver(townRoot, newFederationTown(townRoot), func(format string, args ...interface{}) {
+			fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", args...)
+		}))
+		mux.Handle("/", handler)
+		handler = mux
 	}
 
 	// Auto-start tunnel if requested
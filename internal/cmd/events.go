@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Events query flags
+var (
+	eventsQueryTypes          []string
+	eventsQueryActors         []string
+	eventsQueryRig            string
+	eventsQuerySince          string
+	eventsQueryUntil          string
+	eventsQueryWhere          []string
+	eventsQueryIncludeArchive bool
+	eventsQueryLimit          int
+	eventsQueryCountBy        []string
+	eventsQueryPer            string
+	eventsQueryFormat         string
+	eventsQueryJSON           bool
+)
+
+var eventsCmd = &cobra.Command{
+	Use:     "events",
+	GroupID: GroupDiag,
+	Short:   "Query the town events log",
+	Long: `Query the town's raw events log (.events.jsonl).
+
+The log is searched as time-partitioned segments: the live log is indexed by
+hour (.events.idx), and events expired by KRC can be kept in compressed daily
+archive segments (.events-archive/) when 'archive' is enabled in .krc.yaml.
+Each segment's index records its time range and event types, so queries only
+read segments that can match.`,
+	RunE: requireSubcommand,
+}
+
+var eventsQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Filter, list and count events",
+	Long: `Filter events by type, actor, rig, time and payload fields, then list them
+or count them.
+
+Filters:
+  --type      Event types, comma-separated; globs allowed (merge_*)
+  --actor     Actors; globs allowed (gastown/polecats/*)
+  --rig       Rig the event concerns (payload rig, or actor/target prefix)
+  --since     Start: a duration ago (1h, 7d), RFC 3339 time or date
+  --until     End: same formats as --since
+  --where     Payload field match key=value (dotted keys, glob values); repeatable
+
+Aggregation:
+  --count-by  Count events grouped by fields: type, actor, rig, source,
+              visibility or payload.<key>
+  --per       Count per time bucket: minute, hour, day, week or a duration
+
+Examples:
+  gt events query --type merged,merge_failed --since 24h
+  gt events query --actor 'gastown/polecats/*' --rig gastown --limit 20
+  gt events query --where mr=gt-abc --include-archive
+  gt events query --count-by type --per hour --since 1d
+  gt events query --count-by payload.reason --type session_death --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runEventsQuery,
+}
+
+func init() {
+	f := eventsQueryCmd.Flags()
+	f.StringSliceVarP(&eventsQueryTypes, "type", "t", nil, "Event types (comma-separated, globs allowed)")
+	f.StringSliceVar(&eventsQueryActors, "actor", nil, "Actors (comma-separated, globs allowed)")
+	f.StringVar(&eventsQueryRig, "rig", "", "Only events concerning this rig")
+	f.StringVar(&eventsQuerySince, "since", "", "Start time: duration ago (1h, 7d), RFC 3339 time or date")
+	f.StringVar(&eventsQueryUntil, "until", "", "End time: duration ago (1h, 7d), RFC 3339 time or date")
+	f.StringArrayVar(&eventsQueryWhere, "where", nil, "Payload match key=value (repeatable)")
+	f.BoolVar(&eventsQueryIncludeArchive, "include-archive", false, "Also search the compressed archive")
+	f.IntVarP(&eventsQueryLimit, "limit", "n", 100, "Show only the most recent N events (0 = all)")
+	f.StringSliceVar(&eventsQueryCountBy, "count-by", nil, "Count events grouped by fields (comma-separated)")
+	f.StringVar(&eventsQueryPer, "per", "", "Count per time bucket: minute, hour, day, week or a duration")
+	f.StringVar(&eventsQueryFormat, "format", "table", "Output format: table, json or csv")
+	f.BoolVar(&eventsQueryJSON, "json", false, "Output as JSON (same as --format json)")
+
+	eventsCmd.AddCommand(eventsQueryCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
+
+func runEventsQuery(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	format := eventsQueryFormat
+	if eventsQueryJSON {
+		format = "json"
+	}
+	if format != "table" && format != "json" && format != "csv" {
+		return fmt.Errorf("invalid --format %q: want table, json or csv", format)
+	}
+
+	q, err := buildEventsQuery(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if len(eventsQueryCountBy) > 0 || eventsQueryPer != "" {
+		bucket, err := parseEventsBucket(eventsQueryPer)
+		if err != nil {
+			return err
+		}
+		agg := events.Aggregation{GroupBy: eventsQueryCountBy, Bucket: bucket}
+		rows, err := events.Aggregate(townRoot, q, agg)
+		if err != nil {
+			return fmt.Errorf("querying events: %w", err)
+		}
+		return outputEventCounts(rows, agg, format)
+	}
+
+	found, err := events.Find(townRoot, q)
+	if err != nil {
+		return fmt.Errorf("querying events: %w", err)
+	}
+	return outputEvents(found, format)
+}
+
+// buildEventsQuery builds the query from the command's flags.
+func buildEventsQuery(now time.Time) (*events.Query, error) {
+	q := &events.Query{
+		Types:          eventsQueryTypes,
+		Actors:         eventsQueryActors,
+		Rig:            eventsQueryRig,
+		IncludeArchive: eventsQueryIncludeArchive,
+		Limit:          eventsQueryLimit,
+	}
+	var err error
+	if eventsQuerySince != "" {
+		if q.Since, err = parseEventsTime(eventsQuerySince, now); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if eventsQueryUntil != "" {
+		if q.Until, err = parseEventsTime(eventsQueryUntil, now); err != nil {
+			return nil, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	for _, w := range eventsQueryWhere {
+		key, value, ok := strings.Cut(w, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --where %q: want key=value", w)
+		}
+		if q.Payload == nil {
+			q.Payload = make(map[string]string)
+		}
+		q.Payload[strings.TrimPrefix(key, "payload.")] = value
+	}
+	return q, nil
+}
+
+// parseEventsTime parses a duration ago (1h, 7d), an RFC 3339 time or a date.
+func parseEventsTime(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration, RFC 3339 time or date", s)
+}
+
+// parseEventsBucket parses a --per value.
+func parseEventsBucket(s string) (time.Duration, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "minute":
+		return time.Minute, nil
+	case "hour":
+		return time.Hour, nil
+	case "day":
+		return 24 * time.Hour, nil
+	case "week":
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --per %q: want minute, hour, day, week or a duration", s)
+	}
+	return d, nil
+}
+
+func outputEvents(found []events.Event, format string) error {
+	switch format {
+	case "json":
+		if found == nil {
+			found = []events.Event{}
+		}
+		return outputJSON(found)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write([]string{"ts", "type", "actor", "rig", "source", "visibility", "payload"})
+		for i := range found {
+			e := &found[i]
+			_ = w.Write([]string{e.Timestamp, e.Type, e.Actor, events.EventRig(e), e.Source, e.Visibility, eventPayloadJSON(e)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if len(found) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no matching events)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "TIME", Width: 20},
+		style.Column{Name: "TYPE", Width: 18},
+		style.Column{Name: "ACTOR", Width: 28},
+		style.Column{Name: "PAYLOAD", Width: 60},
+	)
+	for i := range found {
+		e := &found[i]
+		table.AddRow(e.Timestamp, e.Type, e.Actor, style.Dim.Render(truncateString(eventPayloadJSON(e), 60)))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func outputEventCounts(rows []events.Count, agg events.Aggregation, format string) error {
+	header := make([]string, 0, len(agg.GroupBy)+2)
+	if agg.Bucket > 0 {
+		header = append(header, "bucket")
+	}
+	header = append(header, agg.GroupBy...)
+	header = append(header, "count")
+
+	values := func(c events.Count) []string {
+		v := make([]string, 0, len(header))
+		if agg.Bucket > 0 {
+			v = append(v, c.Bucket.Format(time.RFC3339))
+		}
+		v = append(v, c.Keys...)
+		return append(v, fmt.Sprintf("%d", c.Count))
+	}
+
+	switch format {
+	case "json":
+		out := make([]map[string]interface{}, 0, len(rows))
+		for _, c := range rows {
+			m := map[string]interface{}{"count": c.Count}
+			if agg.Bucket > 0 {
+				m["bucket"] = c.Bucket.Format(time.RFC3339)
+			}
+			for i, field := range agg.GroupBy {
+				m[field] = c.Keys[i]
+			}
+			out = append(out, m)
+		}
+		return outputJSON(out)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(header)
+		for _, c := range rows {
+			_ = w.Write(values(c))
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no matching events)"))
+		return nil
+	}
+	columns := make([]style.Column, len(header))
+	for i, name := range header {
+		columns[i] = style.Column{Name: strings.ToUpper(name), Width: 20}
+	}
+	columns[len(columns)-1] = style.Column{Name: "COUNT", Width: 7, Align: style.AlignRight}
+	table := style.NewTable(columns...)
+	for _, c := range rows {
+		table.AddRow(values(c)...)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// eventPayloadJSON formats an event's payload as compact JSON.
+func eventPayloadJSON(e *events.Event) string {
+	if len(e.Payload) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(e.Payload)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Federation command flags
+var (
+	federationSSH         string
+	federationKey         string
+	federationTownPath    string
+	federationURL         string
+	federationToken       string
+	federationAllowMail   bool
+	federationAllowSling  bool
+	federationAllowStatus bool
+	federationRigs        []string
+	federationListJSON    bool
+)
+
+var federationCmd = &cobra.Command{
+	Use:     "federation",
+	GroupID: GroupComm,
+	Short:   "Route mail and work to other towns",
+	Long: `Manage the towns this town federates with.
+
+Federated addresses name an agent in another town:
+
+  town@host:rig/agent     e.g. acme@build01:gastown/nux, acme@mayor/
+
+The host is optional; when given it must match the peer's configured host.
+Mail sent to a federated address (gt mail send) and work slung to one
+(gt sling <bead> acme@gastown) are delivered through the peer's transport:
+
+  ssh   runs 'gt federation receive' in the peer's town over SSH
+  http  POSTs to the peer's dashboard at /federation/deliver
+
+Both sides list each other with the same shared token. Trust is per peer:
+--allow-mail lets it mail agents here, --allow-sling lets it sling work
+here (to the --rigs listed, or any rig). Any configured peer may ask for
+the status of beads it slung here, which is how its convoys track that
+work; --allow-status lets it query any bead.
+
+Peers are stored in settings/federation.json (mode 0600).`,
+	RunE: requireSubcommand,
+}
+
+var federationAddCmd = &cobra.Command{
+	Use:   "add <town>",
+	Short: "Add or update a peer town",
+	Long: `Add a peer town, or update one already configured (only the flags given
+change). A token is generated for a new peer unless --token is given;
+configure the same token for this town on the peer.
+
+Examples:
+  gt federation add acme --ssh deploy@build01 --town-path /srv/gt --allow-mail
+  gt federation add acme --url https://acme-gt.example.com --allow-sling --rigs gastown
+  gt federation add acme --token <token from the peer>`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFederationAdd,
+}
+
+var federationListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List peer towns and what they are trusted to do",
+	Args:  cobra.NoArgs,
+	RunE:  runFederationList,
+}
+
+var federationRemoveCmd = &cobra.Command{
+	Use:   "remove <town>",
+	Short: "Remove a peer town",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFederationRemove,
+}
+
+var federationPingCmd = &cobra.Command{
+	Use:   "ping <town>",
+	Short: "Check that a peer is reachable and accepts this town's token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFederationPing,
+}
+
+var federationReceiveCmd = &cobra.Command{
+	Use:    "receive",
+	Short:  "Handle one federation envelope from stdin (SSH transport)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runFederationReceive,
+}
+
+func init() {
+	federationAddCmd.Flags().StringVar(&federationSSH, "ssh", "", "Reach the peer over SSH at [user@]host")
+	federationAddCmd.Flags().StringVar(&federationKey, "key", "", "SSH private key")
+	federationAddCmd.Flags().StringVar(&federationTownPath, "town-path", "", "Town root on the SSH host (default ~/gt)")
+	federationAddCmd.Flags().StringVar(&federationURL, "url", "", "Reach the peer's dashboard at this URL")
+	federationAddCmd.Flags().StringVar(&federationToken, "token", "", "Shared token (default: generated for a new peer)")
+	federationAddCmd.Flags().BoolVar(&federationAllowMail, "allow-mail", false, "Let the peer mail agents in this town")
+	federationAddCmd.Flags().BoolVar(&federationAllowSling, "allow-sling", false, "Let the peer sling work to this town")
+	federationAddCmd.Flags().StringSliceVar(&federationRigs, "rigs", nil, "Rigs the peer may sling to (default all)")
+	federationAddCmd.Flags().BoolVar(&federationAllowStatus, "allow-status", false, "Let the peer query the status of any bead here")
+
+	federationListCmd.Flags().BoolVar(&federationListJSON, "json", false, "Output as JSON")
+
+	federationCmd.AddCommand(federationAddCmd)
+	federationCmd.AddCommand(federationListCmd)
+	federationCmd.AddCommand(federationRemoveCmd)
+	federationCmd.AddCommand(federationPingCmd)
+	federationCmd.AddCommand(federationReceiveCmd)
+	rootCmd.AddCommand(federationCmd)
+}
+
+// loadFederationConfig returns the town root and its federation config
+// (empty if the town has none yet).
+func loadFederationConfig() (string, *federation.Config, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := federation.LoadConfig(townRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	if cfg == nil {
+		cfg = &federation.Config{}
+	}
+	if cfg.Peers == nil {
+		cfg.Peers = make(map[string]*federation.Peer)
+	}
+	return townRoot, cfg, nil
+}
+
+func runFederationAdd(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadFederationConfig()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if federationSSH != "" && federationURL != "" {
+		return fmt.Errorf("use one of --ssh and --url")
+	}
+
+	p, existed := cfg.Peers[name]
+	if !existed {
+		p = &federation.Peer{}
+	}
+	flags := cmd.Flags()
+	switch {
+	case federationSSH != "":
+		p.Transport, p.Host, p.URL = federation.TransportSSH, federationSSH, ""
+	case federationURL != "":
+		p.Transport, p.URL, p.Host, p.KeyPath, p.TownPath = federation.TransportHTTP, federationURL, "", "", ""
+	case !existed:
+		return fmt.Errorf("a new peer needs --ssh or --url")
+	}
+	if flags.Changed("key") {
+		p.KeyPath = federationKey
+	}
+	if flags.Changed("town-path") {
+		p.TownPath = federationTownPath
+	}
+	if flags.Changed("allow-mail") {
+		p.Trust.Mail = federationAllowMail
+	}
+	if flags.Changed("allow-sling") {
+		p.Trust.Sling = federationAllowSling
+	}
+	if flags.Changed("rigs") {
+		p.Trust.Rigs = federationRigs
+	}
+	if flags.Changed("allow-status") {
+		p.Trust.Status = federationAllowStatus
+	}
+	generated := false
+	switch {
+	case federationToken != "":
+		p.Token = federationToken
+	case p.Token == "":
+		if p.Token, err = federation.NewToken(); err != nil {
+			return err
+		}
+		generated = true
+	}
+
+	cfg.Peers[name] = p
+	if err := federation.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+
+	verb := "Added"
+	if existed {
+		verb = "Updated"
+	}
+	fmt.Printf("%s %s peer %s via %s\n", style.Success.Render("✓"), verb, style.Bold.Render(name), formatPeerTransport(p))
+	fmt.Printf("  Trust: %s\n", formatPeerTrust(p))
+	if generated {
+		fmt.Printf("  Token: %s\n", p.Token)
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("On %s, run: gt federation add %s --token %s ...",
+			name, federation.LocalName(townRoot, cfg), p.Token)))
+	}
+	return nil
+}
+
+func runFederationList(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadFederationConfig()
+	if err != nil {
+		return err
+	}
+
+	if federationListJSON {
+		// Never print tokens.
+		type listed struct {
+			Town      string           `json:"town"`
+			Transport string           `json:"transport"`
+			Address   string           `json:"address"`
+			Trust     federation.Trust `json:"trust"`
+		}
+		out := make([]listed, 0, len(cfg.Peers))
+		for _, name := range cfg.PeerNames() {
+			p := cfg.Peers[name]
+			out = append(out, listed{name, p.Transport, peerAddress(p), p.Trust})
+		}
+		return outputJSON(out)
+	}
+
+	fmt.Printf("This town: %s\n\n", style.Bold.Render(federation.LocalName(townRoot, cfg)))
+	if len(cfg.Peers) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no peers; add one with 'gt federation add <town>')"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "TOWN", Width: 14},
+		style.Column{Name: "TRANSPORT", Width: 40},
+		style.Column{Name: "TRUST", Width: 30},
+	)
+	for _, name := range cfg.PeerNames() {
+		p := cfg.Peers[name]
+		table.AddRow(name, truncateString(formatPeerTransport(p), 40), formatPeerTrust(p))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runFederationRemove(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadFederationConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Peers[args[0]]; !ok {
+		return fmt.Errorf("peer %s not found", args[0])
+	}
+	delete(cfg.Peers, args[0])
+	if err := federation.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed peer %s\n", style.Success.Render("✓"), args[0])
+	return nil
+}
+
+func runFederationPing(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	reply, err := federation.Send(context.Background(), townRoot, args[0], &federation.Envelope{Kind: federation.KindPing})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s answered as %s and accepted this town's token\n",
+		style.Success.Render("✓"), args[0], style.Bold.Render(reply.Town))
+	return nil
+}
+
+func runFederationReceive(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	// The reply on stdout carries any error; the exit status only tells the
+	// sender whether to trust it.
+	cmd.SilenceUsage = true
+	return federation.Receive(townRoot, newFederationTown(townRoot), os.Stdin, os.Stdout)
+}
+
+func peerAddress(p *federation.Peer) string {
+	if p.Transport == federation.TransportHTTP {
+		return p.URL
+	}
+	if p.TownPath != "" {
+		return p.Host + ":" + p.TownPath
+	}
+	return p.Host
+}
+
+func formatPeerTransport(p *federation.Peer) string {
+	return p.Transport + " " + peerAddress(p)
+}
+
+func formatPeerTrust(p *federation.Peer) string {
+	var parts []string
+	if p.Trust.Mail {
+		parts = append(parts, "mail")
+	}
+	if p.Trust.Sling {
+		s := "sling"
+		if len(p.Trust.Rigs) > 0 {
+			s += " (" + strings.Join(p.Trust.Rigs, ",") + ")"
+		}
+		parts = append(parts, s)
+	}
+	if p.Trust.Status {
+		parts = append(parts, "status")
+	}
+	if len(parts) == 0 {
+		return "own beads' status only"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// federationTown is the federation.Town backed by mail, beads and gt sling.
+type federationTown struct {
+	townRoot string
+}
+
+func newFederationTown(townRoot string) federation.Town {
+	return &federationTown{townRoot: townRoot}
+}
+
+func (t *federationTown) SendMail(from, to string, m *federation.Mail) error {
+	msg := mail.NewMessage(from, to, m.Subject, m.Body)
+	msg.Priority = mail.ParsePriority(m.Priority)
+	msg.Type = mail.ParseMessageType(m.Type)
+	if m.ThreadID != "" {
+		msg.ThreadID = m.ThreadID
+	}
+	router := mail.NewRouterWithTownRoot(t.townRoot, t.townRoot)
+	if err := router.Send(msg); err != nil {
+		return fmt.Errorf("mailing %s: %w", to, err)
+	}
+	return nil
+}
+
+func (t *federationTown) Sling(fromTown, target string, s *federation.Sling) (string, error) {
+	rig := strings.SplitN(target, "/", 2)[0]
+	rigs, err := config.LoadRigsConfig(filepath.Join(t.townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return "", err
+	}
+	if _, ok := rigs.Rigs[rig]; !ok {
+		return "", fmt.Errorf("no rig %q in this town", rig)
+	}
+
+	// A retried delivery finds the bead made the first time.
+	b := beads.New(filepath.Join(t.townRoot, rig))
+	label := federation.OriginLabel(fromTown, s.Bead)
+	existing, err := b.List(beads.ListOptions{Label: label, Status: "all", Priority: -1, Limit: 1})
+	if err == nil && len(existing) > 0 {
+		return existing[0].ID, nil
+	}
+
+	issueType := s.Type
+	if issueType == "" {
+		issueType = "task"
+	}
+	desc := s.Description
+	if desc != "" {
+		desc += "\n\n"
+	}
+	desc += fmt.Sprintf("## Federated\n- From: %s\n- Bead: %s\n", fromTown, s.Bead)
+	issue, err := b.Create(beads.CreateOptions{
+		Title:       s.Title,
+		Type:        issueType,
+		Priority:    s.Priority,
+		Description: desc,
+		Actor:       fromTown + "@mayor/",
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating bead: %w", err)
+	}
+	if err := b.Update(issue.ID, beads.UpdateOptions{AddLabels: []string{label}}); err != nil {
+		return issue.ID, fmt.Errorf("labeling %s: %w", issue.ID, err)
+	}
+
+	args := []string{"sling", issue.ID, target}
+	if s.Args != "" {
+		args = append(args, "--args", s.Args)
+	}
+	c := exec.Command("gt", args...) //nolint:gosec // G204: target is checked against rigs.json and trust
+	c.Dir = t.townRoot
+	c.Env = os.Environ()
+	if out, err := c.CombinedOutput(); err != nil {
+		return issue.ID, fmt.Errorf("gt sling %s %s: %s", issue.ID, target, strings.TrimSpace(string(out)))
+	}
+	return issue.ID, nil
+}
+
+func (t *federationTown) BeadStatus(ids []string) ([]federation.BeadStatus, error) {
+	b := beads.New(t.townRoot)
+	out := make([]federation.BeadStatus, 0, len(ids))
+	for _, id := range ids {
+		issue, err := b.Show(id)
+		if err != nil || issue == nil {
+			continue
+		}
+		out = append(out, federation.BeadStatus{
+			ID:       issue.ID,
+			Title:    issue.Title,
+			Status:   issue.Status,
+			Assignee: issue.Assignee,
+			Origin:   federation.OriginTown(issue.Labels),
+		})
+	}
+	return out, nil
+}
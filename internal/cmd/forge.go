@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Forge command flags
+var (
+	forgeKind     string
+	forgeAPI      string
+	forgeToken    string
+	forgeListJSON bool
+)
+
+var forgeCmd = &cobra.Command{
+	Use:     "forge",
+	GroupID: GroupConfig,
+	Short:   "Configure the git forges rigs are hosted on",
+	Long: `Configure the git forges (GitHub, GitLab, Gitea) rigs are hosted on.
+
+Gas Town talks to a rig's forge for pull requests, issues, commit statuses
+and comments: the dashboard's PR list, 'gt formula run --pr', and the
+Refinery's PR landing mode (merge_queue.land_via = "pr"). The forge follows
+from the rig's git URL. github.com, gitlab.com, codeberg.org and gitea.com
+work without configuration; self-hosted forges are added by host.
+
+Tokens come from the host's configuration, else from GITHUB_TOKEN (or
+GH_TOKEN), GITLAB_TOKEN or GITEA_TOKEN, else for GitHub from the gh CLI's
+login. Hosts are stored in settings/forge.json (mode 0600).`,
+	RunE: requireSubcommand,
+}
+
+var forgeAddCmd = &cobra.Command{
+	Use:   "add <host>",
+	Short: "Add or update a forge host",
+	Long: `Add a forge host, or update one already configured (only the flags given
+change). The API URL defaults to the kind's usual path on the host:
+/api/v3 (GitHub Enterprise), /api/v4 (GitLab) or /api/v1 (Gitea).
+
+Examples:
+  gt forge add git.acme.dev --kind gitlab --token glpat-...
+  gt forge add ghe.acme.dev --kind github
+  gt forge add gitea.internal --kind gitea --api http://gitea.internal:3000/api/v1`,
+	Args: cobra.ExactArgs(1),
+	RunE: runForgeAdd,
+}
+
+var forgeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List forge hosts and the forge each rig uses",
+	Args:  cobra.NoArgs,
+	RunE:  runForgeList,
+}
+
+var forgeRemoveCmd = &cobra.Command{
+	Use:   "remove <host>",
+	Short: "Remove a forge host",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runForgeRemove,
+}
+
+var forgeCheckCmd = &cobra.Command{
+	Use:   "check <rig>",
+	Short: "Check that a rig's forge is reachable",
+	Long: `Check that a rig's forge answers, by listing the repository's open pull
+requests and issues.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runForgeCheck,
+}
+
+func init() {
+	forgeAddCmd.Flags().StringVar(&forgeKind, "kind", "", "Forge kind: github, gitlab or gitea")
+	forgeAddCmd.Flags().StringVar(&forgeAPI, "api", "", "API base URL (default derived from kind and host)")
+	forgeAddCmd.Flags().StringVar(&forgeToken, "token", "", "API token (default from the environment)")
+
+	forgeListCmd.Flags().BoolVar(&forgeListJSON, "json", false, "Output as JSON")
+
+	forgeCmd.AddCommand(forgeAddCmd)
+	forgeCmd.AddCommand(forgeListCmd)
+	forgeCmd.AddCommand(forgeRemoveCmd)
+	forgeCmd.AddCommand(forgeCheckCmd)
+	rootCmd.AddCommand(forgeCmd)
+}
+
+// loadForgeConfig returns the town root and its forge config (empty if the
+// town has none yet).
+func loadForgeConfig() (string, *forge.Config, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := forge.LoadConfig(townRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	if cfg == nil {
+		cfg = &forge.Config{}
+	}
+	if cfg.Hosts == nil {
+		cfg.Hosts = make(map[string]*forge.Host)
+	}
+	return townRoot, cfg, nil
+}
+
+func runForgeAdd(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadForgeConfig()
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(args[0])
+
+	h, existed := cfg.Hosts[host]
+	if !existed {
+		if forgeKind == "" {
+			return fmt.Errorf("a new host needs --kind (github, gitlab or gitea)")
+		}
+		h = &forge.Host{}
+	}
+	flags := cmd.Flags()
+	if flags.Changed("kind") {
+		h.Kind = forgeKind
+	}
+	if flags.Changed("api") {
+		h.API = forgeAPI
+	}
+	if flags.Changed("token") {
+		h.Token = forgeToken
+	}
+
+	cfg.Hosts[host] = h
+	if err := forge.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+	verb := "Added"
+	if existed {
+		verb = "Updated"
+	}
+	fmt.Printf("%s %s %s forge %s\n", style.Success.Render("✓"), verb, h.Kind, style.Bold.Render(host))
+	return nil
+}
+
+// rigForge is one rig's forge, as listed by gt forge list.
+type rigForge struct {
+	Rig   string `json:"rig"`
+	Kind  string `json:"kind,omitempty"`
+	Repo  string `json:"repo,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func runForgeList(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadForgeConfig()
+	if err != nil {
+		return err
+	}
+
+	var rigs []rigForge
+	if rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json")); err == nil {
+		names := make([]string, 0, len(rigsConfig.Rigs))
+		for name := range rigsConfig.Rigs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rf := rigForge{Rig: name}
+			p, err := forge.ForURL(townRoot, rigsConfig.Rigs[name].GitURL)
+			if err != nil {
+				rf.Error = err.Error()
+			} else {
+				rf.Kind, rf.Repo = p.Kind(), p.Repo().String()
+			}
+			rigs = append(rigs, rf)
+		}
+	}
+
+	if forgeListJSON {
+		// Never print tokens.
+		type listedHost struct {
+			Host string `json:"host"`
+			Kind string `json:"kind"`
+			API  string `json:"api,omitempty"`
+		}
+		hosts := make([]listedHost, 0, len(cfg.Hosts))
+		for _, name := range cfg.HostNames() {
+			hosts = append(hosts, listedHost{name, cfg.Hosts[name].Kind, cfg.Hosts[name].API})
+		}
+		return outputJSON(map[string]interface{}{"hosts": hosts, "rigs": rigs})
+	}
+
+	if len(cfg.Hosts) > 0 {
+		fmt.Printf("%s\n", style.Bold.Render("Hosts"))
+		for _, name := range cfg.HostNames() {
+			h := cfg.Hosts[name]
+			line := fmt.Sprintf("  %-24s %-7s", name, h.Kind)
+			if h.API != "" {
+				line += " " + h.API
+			}
+			if h.Token != "" {
+				line += style.Dim.Render(" (token set)")
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
+	if len(rigs) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no rigs)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "RIG", Width: 16},
+		style.Column{Name: "FORGE", Width: 8},
+		style.Column{Name: "REPOSITORY", Width: 50},
+	)
+	for _, rf := range rigs {
+		if rf.Error != "" {
+			table.AddRow(rf.Rig, "-", style.Dim.Render(truncateString(rf.Error, 50)))
+			continue
+		}
+		table.AddRow(rf.Rig, rf.Kind, truncateString(rf.Repo, 50))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runForgeRemove(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadForgeConfig()
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(args[0])
+	if _, ok := cfg.Hosts[host]; !ok {
+		return fmt.Errorf("forge host %s not found", host)
+	}
+	delete(cfg.Hosts, host)
+	if err := forge.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed forge host %s\n", style.Success.Render("✓"), host)
+	return nil
+}
+
+func runForgeCheck(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	p, err := forge.ForRig(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	prs, err := p.ListPRs(ctx)
+	if err != nil {
+		return fmt.Errorf("%s (%s): %w", p.Repo(), p.Kind(), err)
+	}
+	issues, err := p.ListIssues(ctx)
+	if err != nil {
+		return fmt.Errorf("%s (%s): %w", p.Repo(), p.Kind(), err)
+	}
+	fmt.Printf("%s %s on %s: %d open PR(s), %d open issue(s)\n",
+		style.Success.Render("✓"), style.Bold.Render(p.Repo().String()), p.Kind(), len(prs), len(issues))
+	return nil
+}
@@ -3,19 +3,21 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base32"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -107,13 +109,14 @@ This command:
   2. Pours it to create a molecule (or uses existing proto)
   3. Dispatches the molecule to available workers
 
-For PR-based workflows, use --pr to specify the GitHub PR number.
+For PR-based workflows, use --pr to specify the PR number on the rig's forge
+(GitHub, GitLab or Gitea; see 'gt forge').
 
 If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
+  --pr=N      Run formula on PR #N
   --rig=NAME  Target specific rig (default: current or gastown)
   --dry-run   Show what would happen without executing
 
@@ -156,7 +159,7 @@ func init() {
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
 
 	// Run flags
-	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
+	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "PR number on the rig's forge to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
 
@@ -309,7 +312,7 @@ func dryRunFormula(f *formula.Formula, formulaName, targetRig string) error {
 		var prTitle string
 		var changedFiles []map[string]interface{}
 		if formulaRunPR > 0 {
-			prTitle, changedFiles = fetchPRInfo(formulaRunPR)
+			prTitle, changedFiles = fetchPRInfo(targetRig, formulaRunPR)
 			if prTitle != "" {
 				fmt.Printf("  PR Title: %s\n", prTitle)
 			}
@@ -429,7 +432,7 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 	var prTitle string
 	var changedFiles []map[string]interface{}
 	if formulaRunPR > 0 {
-		prTitle, changedFiles = fetchPRInfo(formulaRunPR)
+		prTitle, changedFiles = fetchPRInfo(targetRig, formulaRunPR)
 	}
 
 	// Create output directory if configured
@@ -693,42 +696,37 @@ func renderTemplateOrDefault(tmplText string, ctx map[string]interface{}, defaul
 	return result
 }
 
-// fetchPRInfo fetches PR title and changed files from GitHub using gh CLI
-func fetchPRInfo(prNumber int) (string, []map[string]interface{}) {
+// fetchPRInfo fetches PR title and changed files from the target rig's forge.
+// Returns empty values if the rig has no reachable forge.
+func fetchPRInfo(targetRig string, prNumber int) (string, []map[string]interface{}) {
 	var prTitle string
 	var changedFiles []map[string]interface{}
 
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return prTitle, changedFiles
+	}
+	provider, err := forge.ForRig(townRoot, targetRig)
+	if err != nil {
+		return prTitle, changedFiles
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
 	// Get PR title
-	titleCmd := exec.Command("gh", "pr", "view", fmt.Sprintf("%d", prNumber), "--json", "title", "--jq", ".title")
-	titleOut, err := titleCmd.Output()
-	if err == nil {
-		prTitle = strings.TrimSpace(string(titleOut))
+	if pr, err := provider.GetPR(ctx, prNumber); err == nil {
+		prTitle = pr.Title
 	}
 
 	// Get changed files with stats
-	filesCmd := exec.Command("gh", "pr", "view", fmt.Sprintf("%d", prNumber), "--json", "files", "--jq", ".files[] | \"\\(.path) \\(.additions) \\(.deletions)\"")
-	filesOut, err := filesCmd.Output()
-	if err == nil {
-		for _, line := range strings.Split(strings.TrimSpace(string(filesOut)), "\n") {
-			if line == "" {
-				continue
-			}
-			parts := strings.Fields(line)
-			if len(parts) >= 3 {
-				additions, err := strconv.Atoi(parts[1])
-				if err != nil {
-					continue
-				}
-				deletions, err := strconv.Atoi(parts[2])
-				if err != nil {
-					continue
-				}
-				changedFiles = append(changedFiles, map[string]interface{}{
-					"path":      parts[0],
-					"additions": additions,
-					"deletions": deletions,
-				})
-			}
+	if files, err := provider.PRFiles(ctx, prNumber); err == nil {
+		for _, file := range files {
+			changedFiles = append(changedFiles, map[string]interface{}{
+				"path":      file.Path,
+				"additions": file.Additions,
+				"deletions": file.Deletions,
+			})
 		}
 	}
 
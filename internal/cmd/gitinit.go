@@ -66,13 +66,23 @@ const HQGitignore = `# Gas Town HQ .gitignore
 **/heartbeat.json
 **/activity.json
 .events.jsonl
+.events.idx
 .feed.jsonl
 
+# =============================================================================
+# Secrets (webhook signing keys, federation and forge tokens)
+# =============================================================================
+settings/webhooks.json
+settings/inbound.json
+settings/federation.json
+settings/forge.json
+
 # =============================================================================
 # Runtime state directories
 # =============================================================================
 daemon/
 logs/
+.events-archive/
 
 # =============================================================================
 # Rig git worktrees (recreate with 'gt sling' or 'gt rig add')
@@ -93,6 +93,23 @@ TTL format: 1h, 12h, 1d, 7d, 30d, etc.`,
 	RunE: runKrcConfigSet,
 }
 
+var krcConfigArchiveCmd = &cobra.Command{
+	Use:   "archive <on|off>",
+	Short: "Archive expired events instead of deleting them",
+	Long: `Turn the compressed events archive on or off.
+
+When on, pruning moves expired events from .events.jsonl into compressed daily
+segments under .events-archive/ instead of deleting them. Archived events stay
+searchable with 'gt events query --include-archive'.
+
+Examples:
+  gt krc config archive on                    # Keep archived events forever
+  gt krc config archive on --retention 180d   # Drop archive segments after 180 days
+  gt krc config archive off`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKrcConfigArchive,
+}
+
 var krcConfigResetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset TTL configuration to defaults",
@@ -129,10 +146,11 @@ var krcAutoPruneStatusCmd = &cobra.Command{
 }
 
 var (
-	krcPruneDryRun bool
-	krcPruneAuto   bool
-	krcStatsJSON   bool
-	krcDecayJSON   bool
+	krcPruneDryRun      bool
+	krcPruneAuto        bool
+	krcArchiveRetention string
+	krcStatsJSON        bool
+	krcDecayJSON        bool
 )
 
 func init() {
@@ -144,11 +162,13 @@ func init() {
 	krcCmd.AddCommand(krcAutoPruneStatusCmd)
 	krcConfigCmd.AddCommand(krcConfigSetCmd)
 	krcConfigCmd.AddCommand(krcConfigResetCmd)
+	krcConfigCmd.AddCommand(krcConfigArchiveCmd)
 
 	krcPruneCmd.Flags().BoolVar(&krcPruneDryRun, "dry-run", false, "Preview changes without modifying files")
 	krcPruneCmd.Flags().BoolVar(&krcPruneAuto, "auto", false, "Daemon mode: only prune if PruneInterval has elapsed")
 	krcStatsCmd.Flags().BoolVar(&krcStatsJSON, "json", false, "Output in JSON format")
 	krcDecayCmd.Flags().BoolVar(&krcDecayJSON, "json", false, "Output in JSON format")
+	krcConfigArchiveCmd.Flags().StringVar(&krcArchiveRetention, "retention", "", "How long to keep archive segments (e.g., 90d; empty = forever)")
 }
 
 func runKrcStats(cmd *cobra.Command, args []string) error {
@@ -307,6 +327,12 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Events processed: %d\n", result.EventsProcessed)
 	fmt.Printf("  Events pruned:    %d\n", result.EventsPruned)
 	fmt.Printf("  Events retained:  %d\n", result.EventsRetained)
+	if result.EventsArchived > 0 {
+		fmt.Printf("  Events archived:  %d\n", result.EventsArchived)
+	}
+	if result.ArchiveSegmentsRemoved > 0 {
+		fmt.Printf("  Archive segments removed: %d\n", result.ArchiveSegmentsRemoved)
+	}
 	fmt.Printf("  Space saved:      %s\n", formatBytes(result.BytesBefore-result.BytesAfter))
 	fmt.Printf("  Duration:         %s\n", result.Duration.Round(time.Millisecond))
 
@@ -349,6 +375,15 @@ func runKrcConfig(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Default TTL:     %s\n", krcFormatDuration(config.DefaultTTL))
 	fmt.Printf("Prune interval:  %s\n", krcFormatDuration(config.PruneInterval))
 	fmt.Printf("Min retain:      %d events\n", config.MinRetainCount)
+	if config.Archive {
+		retention := "forever"
+		if config.ArchiveRetention > 0 {
+			retention = krcFormatDuration(config.ArchiveRetention)
+		}
+		fmt.Printf("Archive:         on (kept %s)\n", retention)
+	} else {
+		fmt.Printf("Archive:         off\n")
+	}
 	fmt.Println()
 	fmt.Println(style.Bold.Render("TTLs by pattern:"))
 
@@ -418,6 +453,47 @@ func runKrcConfigReset(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runKrcConfigArchive(cmd *cobra.Command, args []string) error {
+	var enable bool
+	switch args[0] {
+	case "on":
+		enable = true
+	case "off":
+	default:
+		return fmt.Errorf("invalid argument %q: want on or off", args[0])
+	}
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	config, err := krc.LoadConfig(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	config.Archive = enable
+	if krcArchiveRetention != "" {
+		retention, err := krcParseDuration(krcArchiveRetention)
+		if err != nil {
+			return fmt.Errorf("invalid retention %q: %w", krcArchiveRetention, err)
+		}
+		config.ArchiveRetention = retention
+	}
+
+	if err := krc.SaveConfig(townRoot, config); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	if enable {
+		fmt.Println("Expired events will be archived to .events-archive/")
+	} else {
+		fmt.Println("Expired events will be deleted (archive off)")
+	}
+	return nil
+}
+
 // krcParseDuration parses a duration string with day support (e.g., "7d", "12h", "30m").
 func krcParseDuration(s string) (time.Duration, error) {
 	s = strings.TrimSpace(s)
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/doctor"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	exportOutput   string
+	exportEncrypt  bool
+	exportNoDolt   bool
+	importTo       string
+	importForce    bool
+	importSkipGit  bool
+	importNoDolt   bool
+	importNoDoctor bool
+)
+
+var rigExportCmd = &cobra.Command{
+	Use:   "export <rig>",
+	Short: "Package a rig for moving to another town or machine",
+	Long: `Package a rig into a single bundle: its config, settings, role overrides,
+plugins, overlays, namepool state and secrets, its beads database (with
+agent beads and hooks), and in-flight git work.
+
+In-flight work is whatever exists only on this machine: polecat branches
+and crew commits that were never pushed, and uncommitted changes (saved as
+WIP snapshots first). Everything already on the rig's remote is re-cloned
+on import instead of being packaged.
+
+Mail lives in the town database and travels with gt town export.
+
+Examples:
+  gt rig export gastown
+  gt rig export gastown -o /tmp/gastown.tar.gz --encrypt`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigExport,
+}
+
+var rigImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Add a rig from a rig export bundle",
+	Long: `Add a rig to this town from a bundle written by gt rig export.
+
+The rig is registered with its beads routes, its files are written back
+with paths rewritten for this machine, its database is restored, and the
+shared repo, mayor, refinery, crew and polecat checkouts are re-created
+from the rig's remote with the bundled unpushed and uncommitted work on
+top. The result is checked (and fixed) with gt doctor's checks.
+
+Restoring the database needs the Dolt server stopped; it is started again
+afterwards.
+
+Examples:
+  gt rig import gt-rig-gastown-20260301T120000Z.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigImport,
+}
+
+var townExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Package the whole town for moving to another machine",
+	Long: `Package the town into a single bundle: everything gt backup create
+archives (configuration, settings, role overrides, formulas, plugins, all
+Dolt databases including mail) plus every rig's in-flight git work, so
+polecats and crew can resume where they left off.
+
+Examples:
+  gt town export -o ~/town.tar.gz
+  GT_BACKUP_PASSPHRASE=... gt town export --encrypt`,
+	Args: cobra.NoArgs,
+	RunE: runTownExport,
+}
+
+var townImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Recreate a town from a town export bundle",
+	Long: `Recreate a town from a bundle written by gt town export (or gt backup
+create).
+
+Files are written with the old town root and home directory rewritten to
+the new ones, worktrees are re-created from each rig's remote with their
+in-flight work, databases are restored and the Dolt server started, and
+the result is checked (and fixed) with gt doctor's checks.
+
+Examples:
+  gt town import town.tar.gz --to ~/gt`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTownImport,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{rigExportCmd, townExportCmd} {
+		c.Flags().StringVarP(&exportOutput, "output", "o", "", "Bundle path (default: generated name in the current directory)")
+		c.Flags().BoolVar(&exportEncrypt, "encrypt", false, "Encrypt the bundle with a passphrase")
+		c.Flags().BoolVar(&exportNoDolt, "no-dolt", false, "Don't include Dolt databases")
+	}
+	for _, c := range []*cobra.Command{rigExportCmd, townExportCmd, rigImportCmd, townImportCmd} {
+		c.Flags().StringVar(&backupPassphraseFile, "passphrase-file", "", "Read the encryption passphrase from this file (default $"+backupPassphraseEnv+")")
+	}
+	for _, c := range []*cobra.Command{rigImportCmd, townImportCmd} {
+		c.Flags().BoolVar(&importSkipGit, "skip-git", false, "Don't re-create repos, worktrees or crew")
+		c.Flags().BoolVar(&importNoDolt, "no-dolt", false, "Don't restore Dolt databases")
+		c.Flags().BoolVar(&importNoDoctor, "no-doctor", false, "Don't run doctor checks afterwards")
+	}
+	townImportCmd.Flags().StringVar(&importTo, "to", "", "Town directory to create (required)")
+	townImportCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite an existing town at --to")
+
+	rigCmd.AddCommand(rigExportCmd, rigImportCmd)
+	townCmd.AddCommand(townExportCmd, townImportCmd)
+}
+
+func runRigExport(cmd *cobra.Command, args []string) error {
+	return runExport(backup.KindRig, args[0])
+}
+
+func runTownExport(cmd *cobra.Command, args []string) error {
+	return runExport(backup.KindTown, "")
+}
+
+func runExport(kind, rigName string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var passphrase string
+	if exportEncrypt {
+		if passphrase, err = backupPassphrase(); err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("--encrypt needs a passphrase: use --passphrase-file or set %s", backupPassphraseEnv)
+		}
+	}
+
+	archive, m, err := backup.Create(townRoot, backup.CreateOptions{
+		Dir:        ".",
+		Output:     exportOutput,
+		Passphrase: passphrase,
+		GTVersion:  Version,
+		SkipDolt:   exportNoDolt,
+		Kind:       kind,
+		Rig:        rigName,
+	})
+	if err != nil {
+		return err
+	}
+
+	polecats, crewBundles := 0, 0
+	for _, r := range m.Rigs {
+		polecats += len(r.Polecats)
+		for _, c := range r.Crew {
+			if c.Bundle != "" {
+				crewBundles++
+			}
+		}
+	}
+	fmt.Printf("%s Exported to %s\n", style.Success.Render("✓"), archive.Path)
+	fmt.Printf("  %d files, %d rig(s), %d database(s), %s\n",
+		len(m.Files), len(m.Rigs), len(m.Databases), formatBytes(archive.Size))
+	fmt.Printf("  In-flight work: %d polecat(s), %d crew with unpushed or uncommitted work\n", polecats, crewBundles)
+	for _, w := range m.Warnings {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), w)
+	}
+	return nil
+}
+
+func runRigImport(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	passphrase, err := backupPassphrase()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing %s...\n", filepath.Base(args[0]))
+	res, err := backup.ImportRig(args[0], townRoot, backup.RestoreOptions{
+		Passphrase: passphrase,
+		SkipGit:    importSkipGit,
+		SkipDolt:   importNoDolt,
+	})
+	if err != nil {
+		return err
+	}
+	rigName := res.Manifest.Rigs[0].Name
+	fmt.Printf("%s Imported rig %s from %s\n", style.Success.Render("✓"), rigName, res.Manifest.Town)
+	printImportResult(res)
+	return finishImport(townRoot, rigName, res)
+}
+
+func runTownImport(cmd *cobra.Command, args []string) error {
+	if importTo == "" {
+		return errors.New("--to is required: the directory to create the town in")
+	}
+	target, err := filepath.Abs(importTo)
+	if err != nil {
+		return err
+	}
+	passphrase, err := backupPassphrase()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing %s into %s...\n", filepath.Base(args[0]), target)
+	res, err := backup.Restore(args[0], target, backup.RestoreOptions{
+		Passphrase: passphrase,
+		Force:      importForce,
+		SkipGit:    importSkipGit,
+		SkipDolt:   importNoDolt,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Imported town %s (exported %s)\n", style.Success.Render("✓"),
+		res.Manifest.Town, res.Manifest.CreatedAt.Local().Format(time.RFC3339))
+	printImportResult(res)
+	return finishImport(target, "", res)
+}
+
+func printImportResult(res *backup.RestoreResult) {
+	fmt.Printf("  Files:     %d", res.Files)
+	if res.Rewritten > 0 {
+		fmt.Printf(" (%d with paths rewritten from %s)", res.Rewritten, res.Manifest.Root)
+	}
+	fmt.Println()
+	for _, row := range []struct {
+		label string
+		items []string
+	}{
+		{"Rigs", res.Rigs},
+		{"Crew", res.Crew},
+		{"Polecats", res.Polecats},
+		{"Databases", res.Databases},
+	} {
+		if len(row.items) > 0 {
+			fmt.Printf("  %-10s %s\n", row.label+":", strings.Join(row.items, ", "))
+		}
+	}
+	for _, w := range append(res.Manifest.Warnings, res.Warnings...) {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), w)
+	}
+}
+
+// finishImport starts the Dolt server for restored databases and runs the
+// doctor checks (with fixes) over the imported town or rig.
+func finishImport(townRoot, rigName string, res *backup.RestoreResult) error {
+	if len(res.Databases) > 0 && !doltserver.DefaultConfig(townRoot).IsRemote() {
+		if running, _, _ := doltserver.IsRunning(townRoot); !running {
+			if err := doltserver.Start(townRoot); err != nil {
+				fmt.Printf("  %s Could not start the Dolt server: %v\n", style.Warning.Render("⚠"), err)
+			}
+		}
+	}
+	if importNoDoctor {
+		fmt.Println("\nNext: gt doctor --fix")
+		return nil
+	}
+
+	fmt.Println("\nValidating with doctor checks (fixing what can be fixed)...")
+	ctx := &doctor.CheckContext{TownRoot: townRoot, RigName: rigName}
+	report := newDoctor(rigName).FixStreaming(ctx, os.Stdout, 0)
+	report.PrintSummaryOnly(os.Stdout, false, 0)
+	if report.HasErrors() {
+		return fmt.Errorf("import finished, but doctor found %d error(s); see above, then run gt doctor", report.Summary.Errors)
+	}
+	return nil
+}
@@ -39,6 +39,9 @@ func buildAgentBeadID(identity string, role Role, townRoot string) string {
 
 	// If role is unknown or empty, try to infer from identity
 	if role == RoleUnknown || role == Role("") {
+		if customRole, rig, ok := parseCustomRoleString(identity, townRoot); ok {
+			return customRoleBeadID(townRoot, customRole, rig)
+		}
 		switch {
 		case identity == "mayor":
 			return beads.MayorBeadIDTown()
@@ -97,6 +100,9 @@ func buildAgentBeadID(identity string, role Role, townRoot string) string {
 		// Boot is a deacon dog — uses town-level dog bead ID
 		return beads.DogBeadIDTown("boot")
 	default:
+		if customRole, rig, ok := parseCustomRoleString(identity, townRoot); ok && customRole == role {
+			return customRoleBeadID(townRoot, customRole, rig)
+		}
 		return ""
 	}
 }
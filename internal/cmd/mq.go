@@ -43,6 +43,9 @@ var (
 	// Status command flags
 	mqStatusJSON bool
 
+	// Interactive view flag
+	mqInteractive bool
+
 	// Integration land flags
 	mqIntegrationLandForce     bool
 	mqIntegrationLandSkipTests bool
@@ -62,13 +65,25 @@ var mqCmd = &cobra.Command{
 	Aliases: []string{"mr"},
 	GroupID: GroupWork,
 	Short:   "Merge queue operations",
-	RunE:    requireSubcommand,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mqInteractive {
+			return runMQTUI(args)
+		}
+		return requireSubcommand(cmd, args)
+	},
 	Long: `Manage merge requests and the merge queue for a rig.
 
 Alias: 'gt mr' is equivalent to 'gt mq' (merge request vs merge queue).
 
 The merge queue tracks work branches from polecats waiting to be merged.
-Use these commands to view, submit, retry, and manage merge requests.`,
+Use these commands to view, submit, retry, and manage merge requests.
+
+Interactive view:
+  gt mq -i [rig]   Live view of ready, blocked, in-progress and anomalous
+                   MRs, with score breakdowns and diff/log previews. Keys
+                   reject (x), bump the score (+), retry by releasing the
+                   claim (r) and open the owning polecat's session (s).
+                   Tab switches rigs when no rig is given.`,
 }
 
 var mqSubmitCmd = &cobra.Command{
@@ -305,6 +320,9 @@ func init() {
 	// Status flags
 	mqStatusCmd.Flags().BoolVar(&mqStatusJSON, "json", false, "Output as JSON")
 
+	// Interactive view
+	mqCmd.Flags().BoolVarP(&mqInteractive, "interactive", "i", false, "Interactive merge queue view")
+
 	// Add subcommands
 	mqCmd.AddCommand(mqSubmitCmd)
 	mqCmd.AddCommand(mqRetryCmd)
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ conflicts command flags
+var (
+	mqConflictsCached bool
+	mqConflictsJSON   bool
+)
+
+var mqConflictsCmd = &cobra.Command{
+	Use:   "conflicts <rig>",
+	Short: "Predict merge conflicts between in-flight branches",
+	Long: `Show which in-flight branches change the same files and lines.
+
+Compares every open MR's branch and every working polecat's branch with
+each other, and with what the default branch has changed since each forked.
+The matrix marks each pair:
+
+  H   changes overlap or touch: likely to conflict
+  f   same files, different lines
+  ·   no shared files
+
+The daemon runs the same prediction periodically (rig setting
+conflict_prediction), mails the polecats involved and the Mayor when a new
+overlap appears, and saves the result; --cached shows that saved report
+instead of computing a fresh one. With merge_queue.scoring.conflict_penalty
+set, the queue merges the least-conflicting MRs first.
+
+Examples:
+  gt mq conflicts greenplace
+  gt mq conflicts greenplace --cached
+  gt mq conflicts greenplace --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQConflicts,
+}
+
+func init() {
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsCached, "cached", false, "Show the daemon's last prediction")
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqConflictsCmd)
+}
+
+func runMQConflicts(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	var report *refinery.ConflictReport
+	if mqConflictsCached {
+		_, r, err := getRig(rigName)
+		if err != nil {
+			return err
+		}
+		report, err = refinery.LoadConflictReport(r.Path)
+		if err != nil {
+			return err
+		}
+		if report == nil {
+			return fmt.Errorf("no saved conflict prediction for rig '%s'; run without --cached", rigName)
+		}
+	} else {
+		mgr, r, err := getPolecatManager(rigName)
+		if err != nil {
+			return err
+		}
+		polecats, err := mgr.List()
+		if err != nil {
+			return fmt.Errorf("listing polecats: %w", err)
+		}
+		var branches []refinery.ConflictBranch
+		for _, p := range polecats {
+			if p.State == polecat.StateDone || p.State == polecat.StateZombie || p.Branch == "" {
+				continue
+			}
+			branches = append(branches, refinery.ConflictBranch{Branch: p.Branch, Worker: p.Name})
+		}
+
+		eng, err := getReviewEngineer(rigName)
+		if err != nil {
+			return err
+		}
+		eng.SetOutput(cmd.ErrOrStderr())
+		report, err = eng.PredictConflicts(branches)
+		if err != nil {
+			return err
+		}
+		if err := refinery.SaveConflictReport(r.Path, report); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: saving conflict report: %v\n", err)
+		}
+	}
+
+	if mqConflictsJSON {
+		return outputJSON(report)
+	}
+	printConflictReport(report)
+	return nil
+}
+
+// printConflictReport prints the overlap matrix followed by the files behind
+// each overlap.
+func printConflictReport(report *refinery.ConflictReport) {
+	fmt.Printf("%s Predicted conflicts in %s %s\n\n", style.Bold.Render("⚔"), report.Rig,
+		style.Dim.Render(fmt.Sprintf("(as of %s)", report.GeneratedAt.Format(time.RFC822))))
+	if len(report.Branches) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(no in-flight branches)"))
+		return
+	}
+
+	// Columns are numbered by row; the last column is the target.
+	target := strings.TrimPrefix(report.Target, "origin/")
+	header := fmt.Sprintf("  %3s  %-40s", "", "")
+	for i := range report.Branches {
+		header += fmt.Sprintf(" %2d", i+1)
+	}
+	header += "  " + target
+	fmt.Println(style.Dim.Render(header))
+
+	for i, b := range report.Branches {
+		label := b.Branch
+		if b.Worker != "" {
+			label += " (" + b.Worker + ")"
+		}
+		row := fmt.Sprintf("  %3d  %-40s", i+1, truncateString(label, 40))
+		for j, other := range report.Branches {
+			cell := "-"
+			if i != j {
+				cell = conflictCell(report, b.Branch, other.Branch)
+			}
+			row += " " + styleConflictCell(fmt.Sprintf("%2s", cell))
+		}
+		if _, failed := report.Errors[b.Branch]; failed {
+			row += "  " + style.Warning.Render("?")
+		} else {
+			row += "  " + styleConflictCell(conflictCell(report, b.Branch, report.Target))
+		}
+		fmt.Println(row)
+	}
+
+	var hunks, files int
+	for _, o := range report.Overlaps {
+		if o.Level == refinery.OverlapHunk {
+			hunks++
+		} else {
+			files++
+		}
+	}
+	fmt.Printf("\n  %d likely conflict(s), %d shared-file overlap(s)\n", hunks, files)
+	if len(report.Overlaps) > 0 {
+		fmt.Println()
+	}
+	for _, o := range report.Overlaps {
+		if o.Level == refinery.OverlapHunk {
+			fmt.Printf("  %s %s ↔ %s: %s\n", style.Error.Render("H"), o.A, o.B, strings.Join(o.Hunks, ", "))
+		} else {
+			fmt.Printf("  %s %s ↔ %s: %s\n", style.Dim.Render("f"), o.A, o.B, strings.Join(o.Files, ", "))
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Println()
+		for _, b := range report.Branches {
+			if msg, ok := report.Errors[b.Branch]; ok {
+				fmt.Printf("  %s %s: %s\n", style.Warning.Render("?"), b.Branch, msg)
+			}
+		}
+	}
+}
+
+// conflictCell is the matrix cell for a pair of branches.
+func conflictCell(report *refinery.ConflictReport, a, b string) string {
+	o := report.Between(a, b)
+	switch {
+	case o == nil:
+		return "·"
+	case o.Level == refinery.OverlapHunk:
+		return "H"
+	default:
+		return "f"
+	}
+}
+
+// styleConflictCell colors an already padded matrix cell.
+func styleConflictCell(cell string) string {
+	switch strings.TrimSpace(cell) {
+	case "H":
+		return style.Error.Render(cell)
+	case "·", "-":
+		return style.Dim.Render(cell)
+	}
+	return cell
+}
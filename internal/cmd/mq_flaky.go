@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/testresults"
+)
+
+// MQ flaky command flags
+var (
+	mqFlakyListJSON         bool
+	mqFlakyQuarantineReason string
+)
+
+var mqFlakyCmd = &cobra.Command{
+	Use:   "flaky",
+	Short: "Inspect flaky tests and manage the test quarantine",
+	Long: `Inspect flaky tests and manage a rig's test quarantine.
+
+The Refinery parses per-test results from the test stage (go test -json,
+JUnit XML or TAP) and records every test's outcome. A test that both fails
+and passes on the same tree is flaky. Failing tests are rerun on their own,
+and failures of quarantined tests do not block merges.
+
+The quarantine list lives in <rig>/settings/quarantine.json. Entries are test
+IDs as shown by 'gt mq flaky list' (e.g., "example.com/pkg.TestFoo"), or bare
+test names, which match the test in any package.`,
+	RunE: requireSubcommand,
+}
+
+var mqFlakyListCmd = &cobra.Command{
+	Use:   "list <rig>",
+	Short: "Show flaky and quarantined tests",
+	Long: `Show the tests that flipped between pass and fail on an unchanged tree,
+most flips first, followed by the quarantine list.
+
+Examples:
+  gt mq flaky list greenplace
+  gt mq flaky list greenplace --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFlakyList,
+}
+
+var mqFlakyQuarantineCmd = &cobra.Command{
+	Use:   "quarantine <rig> <test-id>",
+	Short: "Stop a test's failures from blocking merges",
+	Long: `Add a test to the rig's quarantine list. The Refinery still runs the test
+and records its results, but its failures no longer fail the MR.
+
+Examples:
+  gt mq flaky quarantine greenplace example.com/pkg.TestRace --reason "times out under load"
+  gt mq flaky quarantine greenplace TestRace`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQFlakyQuarantine,
+}
+
+var mqFlakyReleaseCmd = &cobra.Command{
+	Use:   "release <rig> <test-id>",
+	Short: "Remove a test from the quarantine",
+	Long: `Remove a test from the rig's quarantine list, so its failures block merges
+again.
+
+Example:
+  gt mq flaky release greenplace example.com/pkg.TestRace`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQFlakyRelease,
+}
+
+func init() {
+	mqFlakyListCmd.Flags().BoolVar(&mqFlakyListJSON, "json", false, "Output as JSON")
+	mqFlakyQuarantineCmd.Flags().StringVarP(&mqFlakyQuarantineReason, "reason", "r", "", "Why the test is quarantined")
+
+	mqFlakyCmd.AddCommand(mqFlakyListCmd)
+	mqFlakyCmd.AddCommand(mqFlakyQuarantineCmd)
+	mqFlakyCmd.AddCommand(mqFlakyReleaseCmd)
+	mqCmd.AddCommand(mqFlakyCmd)
+}
+
+// flakyTestJSON is one flaky test in 'gt mq flaky list --json' output.
+type flakyTestJSON struct {
+	ID          string    `json:"id"`
+	Runs        int       `json:"runs"`
+	Failures    int       `json:"failures"`
+	Flips       int       `json:"flips"`
+	LastFlip    time.Time `json:"last_flip"`
+	Quarantined bool      `json:"quarantined"`
+}
+
+func runMQFlakyList(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	history, err := testresults.LoadHistory(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading test history: %w", err)
+	}
+	quarantine, err := testresults.LoadQuarantine(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading quarantine: %w", err)
+	}
+	flaky := history.Flaky()
+
+	if mqFlakyListJSON {
+		out := struct {
+			Flaky       []flakyTestJSON                        `json:"flaky"`
+			Quarantined map[string]testresults.QuarantineEntry `json:"quarantined"`
+		}{Flaky: []flakyTestJSON{}, Quarantined: quarantine.Tests}
+		for _, t := range flaky {
+			out.Flaky = append(out.Flaky, flakyTestJSON{
+				ID:          t.ID,
+				Runs:        t.Runs,
+				Failures:    t.Failures,
+				Flips:       t.Flips,
+				LastFlip:    t.LastFlip,
+				Quarantined: quarantine.HasID(t.ID),
+			})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	fmt.Printf("%s Flaky tests in %s\n\n", style.Bold.Render("⚠"), r.Name)
+	if len(flaky) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none recorded)"))
+	}
+	for _, t := range flaky {
+		marker := ""
+		if quarantine.HasID(t.ID) {
+			marker = " " + style.Dim.Render("[quarantined]")
+		}
+		fmt.Printf("  %s%s\n", t.ID, marker)
+		fmt.Printf("    %d flips, %d failures in %d runs, last flip %s\n",
+			t.Flips, t.Failures, t.Runs, formatAge(t.LastFlip))
+	}
+
+	fmt.Printf("\n%s Quarantined\n\n", style.Bold.Render("⊘"))
+	if len(quarantine.Tests) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none)"))
+	}
+	for _, id := range quarantine.IDs() {
+		entry := quarantine.Tests[id]
+		fmt.Printf("  %s\n", id)
+		detail := fmt.Sprintf("since %s", entry.At.Format("2006-01-02"))
+		if entry.By != "" {
+			detail += " by " + entry.By
+		}
+		if entry.Reason != "" {
+			detail += ": " + entry.Reason
+		}
+		fmt.Printf("    %s\n", style.Dim.Render(detail))
+	}
+	return nil
+}
+
+func runMQFlakyQuarantine(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	id := args[1]
+	quarantine, err := testresults.LoadQuarantine(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading quarantine: %w", err)
+	}
+	if _, ok := quarantine.Tests[id]; ok {
+		fmt.Printf("%s %s is already quarantined\n", style.Dim.Render("○"), id)
+		return nil
+	}
+	quarantine.Tests[id] = testresults.QuarantineEntry{
+		Reason: mqFlakyQuarantineReason,
+		By:     detectSenderFallback(),
+		At:     time.Now(),
+	}
+	if err := quarantine.Save(r.Path); err != nil {
+		return fmt.Errorf("saving quarantine: %w", err)
+	}
+	fmt.Printf("%s Quarantined %s in %s\n", style.Bold.Render("✓"), id, r.Name)
+	return nil
+}
+
+func runMQFlakyRelease(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	id := args[1]
+	quarantine, err := testresults.LoadQuarantine(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading quarantine: %w", err)
+	}
+	if _, ok := quarantine.Tests[id]; !ok {
+		return fmt.Errorf("%s is not quarantined in %s", id, r.Name)
+	}
+	delete(quarantine.Tests, id)
+	if err := quarantine.Save(r.Path); err != nil {
+		return fmt.Errorf("saving quarantine: %w", err)
+	}
+	fmt.Printf("%s Released %s from quarantine in %s\n", style.Bold.Render("✓"), id, r.Name)
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -76,7 +76,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	type scoredIssue struct {
 		issue          *beads.Issue
 		fields         *beads.MRFields
-		score          float64
+		score          refinery.ScoreBreakdown
 		branchMissing  bool // true if branch doesn't exist in git (when --verify is set)
 		branchVerifyErr bool // true if git check errored (corrupt repo, permission, etc.)
 	}
@@ -128,15 +128,22 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		// Check branch existence if --verify is set (local + remote-tracking refs)
 		branchMissing, branchVerifyErr := verifyBranch(mqListVerify, gitClient, fields)
 
-		// Calculate priority score
-		score := calculateMRScore(issue, fields, now)
-		scored = append(scored, scoredIssue{issue: issue, fields: fields, score: score, branchMissing: branchMissing, branchVerifyErr: branchVerifyErr})
+		scored = append(scored, scoredIssue{issue: issue, fields: fields, branchMissing: branchMissing, branchVerifyErr: branchVerifyErr})
 	}
 
-	// Sort by score descending (highest priority first)
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
-	})
+	// Order as the Refinery will process them (highest priority first)
+	entries := make([]*refinery.QueueEntry, len(scored))
+	byID := make(map[string]scoredIssue, len(scored))
+	for i, item := range scored {
+		entries[i] = refinery.IssueQueueEntry(item.issue, item.fields, now)
+		byID[item.issue.ID] = item
+	}
+	refinery.ApplyConflictReport(r.Path, entries, now)
+	for i, e := range refinery.OrderQueue(entries, rigScoreConfig(r)) {
+		item := byID[e.ID]
+		item.score = e.Score
+		scored[i] = item
+	}
 
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
@@ -179,6 +186,22 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Show review state and latency when any MR went through review
+	showReview := false
+	for _, item := range scored {
+		if item.fields != nil && item.fields.ReviewStatus != "" {
+			showReview = true
+			break
+		}
+	}
+	var reviewTimeout time.Duration
+	if showReview {
+		eng := refinery.NewEngineer(r)
+		if err := eng.LoadConfig(); err == nil {
+			reviewTimeout = eng.Config().Review.TimeoutDuration()
+		}
+	}
+
 	// Create styled table - add GIT column when --verify is set
 	columns := []style.Column{
 		{Name: "ID", Width: 12},
@@ -191,6 +214,9 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	if mqListVerify {
 		columns = append(columns, style.Column{Name: "GIT", Width: 8})
 	}
+	if showReview {
+		columns = append(columns, style.Column{Name: "REVIEW", Width: 14})
+	}
 	columns = append(columns, style.Column{Name: "AGE", Width: 6, Align: style.AlignRight})
 
 	table := style.NewTable(columns...)
@@ -208,6 +234,12 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			} else {
 				displayStatus = "ready"
 			}
+			switch {
+			case item.score.Held:
+				displayStatus = "held"
+			case item.score.Pinned && displayStatus == "ready":
+				displayStatus = "pinned"
+			}
 		}
 
 		// Format status with styling
@@ -215,6 +247,10 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		switch displayStatus {
 		case "ready":
 			styledStatus = style.Success.Render("ready")
+		case "pinned":
+			styledStatus = style.Success.Render("pinned")
+		case "held":
+			styledStatus = style.Warning.Render("held")
 		case "in_progress":
 			styledStatus = style.Warning.Render("active")
 		case "blocked":
@@ -250,7 +286,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Format score
-		scoreStr := fmt.Sprintf("%.1f", item.score)
+		scoreStr := fmt.Sprintf("%.1f", item.score.Total)
 
 		// Format branch status when --verify is set
 		gitStatus := ""
@@ -273,12 +309,15 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			displayID = displayID[:12]
 		}
 
-		// Build row with conditional GIT column
+		// Build row with conditional GIT and REVIEW columns
+		row := []string{displayID, scoreStr, priority, convoyDisplay, branch, styledStatus}
 		if mqListVerify {
-			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, gitStatus, style.Dim.Render(age))
-		} else {
-			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, style.Dim.Render(age))
+			row = append(row, gitStatus)
+		}
+		if showReview {
+			row = append(row, formatMRReview(fields, reviewTimeout, now))
 		}
+		table.AddRow(append(row, style.Dim.Render(age))...)
 	}
 
 	fmt.Print(table.Render())
@@ -329,18 +368,53 @@ func formatMRAge(createdAt string) string {
 		}
 	}
 
-	d := time.Since(t)
+	return formatDurationShort(time.Since(t))
+}
 
-	if d < time.Minute {
-		return fmt.Sprintf("%ds", int(d.Seconds()))
+// formatMRReview formats an MR's review state with its latency: how long
+// the review took, or has been pending. Pending reviews past the policy
+// timeout are flagged as overdue.
+func formatMRReview(fields *beads.MRFields, timeout time.Duration, now time.Time) string {
+	if fields == nil || fields.ReviewStatus == "" {
+		return style.Dim.Render("-")
 	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm", int(d.Minutes()))
+
+	label := fields.ReviewStatus
+	switch fields.ReviewStatus {
+	case refinery.ReviewChangesRequested:
+		label = "changes"
+	case refinery.ReviewReworkRequested:
+		label = "rework"
 	}
-	if d < 24*time.Hour {
+	latency, pending, ok := refinery.ReviewLatency(fields, now)
+	if ok {
+		label += " " + formatDurationShort(latency)
+	}
+
+	switch {
+	case pending && timeout > 0 && latency > timeout:
+		return style.Error.Render(label + " overdue")
+	case fields.ReviewStatus == refinery.ReviewApproved:
+		return style.Success.Render(label)
+	case fields.ReviewStatus == refinery.ReviewPending:
+		return style.Warning.Render(label)
+	default:
+		return style.Error.Render(label)
+	}
+}
+
+// formatDurationShort formats a duration in its largest whole unit (5m, 3h).
+func formatDurationShort(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
 		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
 	}
-	return fmt.Sprintf("%dd", int(d.Hours()/24))
 }
 
 // outputJSON outputs data as JSON.
@@ -350,38 +424,14 @@ func outputJSON(data interface{}) error {
 	return enc.Encode(data)
 }
 
-// calculateMRScore computes the priority score for an MR using the refinery scoring function.
-// Higher scores mean higher priority (process first).
-func calculateMRScore(issue *beads.Issue, fields *beads.MRFields, now time.Time) float64 {
-	// Parse MR creation time
-	mrCreatedAt, err := time.Parse(time.RFC3339, issue.CreatedAt)
-	if err != nil {
-		mrCreatedAt, err = time.Parse("2006-01-02T15:04:05Z", issue.CreatedAt)
-		if err != nil {
-			mrCreatedAt = now // Fallback to now if parsing fails
-		}
-	}
-
-	// Build score input
-	input := refinery.ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
-	}
-
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
-
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
+// rigScoreConfig returns the rig's merge queue scoring weights, or the
+// defaults when its config can't be read.
+func rigScoreConfig(r *rig.Rig) refinery.ScoreConfig {
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return refinery.DefaultScoreConfig()
 	}
-
-	return refinery.ScoreMRWithDefaults(input)
+	return eng.Config().Scoring
 }
 
 // branchVerifier abstracts git branch existence checks for testability.
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -27,6 +28,13 @@ The priority scoring function considers:
   - Issue priority: P0 > P1 > P2 > P3 > P4
   - Retry count: MRs that fail repeatedly get deprioritized
   - MR age: FIFO tiebreaker for same priority/convoy
+  - Boost: points added with 'gt mq bump'
+  - Conflicts: MRs predicted to conflict with other in-flight branches
+    sink (merge_queue.scoring.conflict_penalty, off by default)
+
+Pinned MRs ('gt mq pin') come first and held MRs ('gt mq hold') are
+skipped. Weights and fairness caps come from the rig's merge_queue.scoring
+config; 'gt mq explain' breaks a score down.
 
 Use --strategy=fifo for first-in-first-out ordering instead.
 
@@ -77,9 +85,14 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 		if issue.Status != "open" {
 			continue
 		}
-		if len(issue.BlockedBy) == 0 && issue.BlockedByCount == 0 {
-			ready = append(ready, issue)
+		if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
+			continue
 		}
+		// Held MRs are not processed (gt mq hold)
+		if fields := beads.ParseMRFields(issue); fields != nil && fields.Hold != "" {
+			continue
+		}
+		ready = append(ready, issue)
 	}
 
 	if len(ready) == 0 {
@@ -91,6 +104,7 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 	}
 
 	now := time.Now()
+	scores := make(map[string]refinery.ScoreBreakdown, len(ready))
 
 	// Sort based on strategy
 	if mqNextStrategy == "fifo" {
@@ -101,25 +115,17 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 			return ti.Before(tj)
 		})
 	} else {
-		// Priority: highest score first
-		type scoredIssue struct {
-			issue *beads.Issue
-			score float64
-		}
-		scored := make([]scoredIssue, len(ready))
+		// Priority: pins first, then highest score, as the Refinery orders
+		byID := make(map[string]*beads.Issue, len(ready))
+		entries := make([]*refinery.QueueEntry, len(ready))
 		for i, issue := range ready {
-			fields := beads.ParseMRFields(issue)
-			score := calculateMRScore(issue, fields, now)
-			scored[i] = scoredIssue{issue: issue, score: score}
+			byID[issue.ID] = issue
+			entries[i] = refinery.IssueQueueEntry(issue, beads.ParseMRFields(issue), now)
 		}
-
-		sort.Slice(scored, func(i, j int) bool {
-			return scored[i].score > scored[j].score
-		})
-
-		// Rebuild ready slice in sorted order
-		for i, s := range scored {
-			ready[i] = s.issue
+		refinery.ApplyConflictReport(r.Path, entries, now)
+		for i, e := range refinery.OrderQueue(entries, rigScoreConfig(r)) {
+			ready[i] = byID[e.ID]
+			scores[e.ID] = e.Score
 		}
 	}
 
@@ -140,10 +146,14 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 	// Human-readable output
 	fmt.Printf("%s Next MR to process:\n\n", style.Bold.Render("🎯"))
 
-	score := calculateMRScore(next, fields, now)
+	score, ok := scores[next.ID]
+	if !ok {
+		// FIFO doesn't score; show what the priority strategy would
+		score = refinery.ExplainScore(refinery.IssueQueueEntry(next, fields, now).Input, rigScoreConfig(r))
+	}
 
 	fmt.Printf("  ID:       %s\n", next.ID)
-	fmt.Printf("  Score:    %.1f\n", score)
+	fmt.Printf("  Score:    %s\n", score)
 	fmt.Printf("  Priority: P%d\n", next.Priority)
 
 	if fields != nil {
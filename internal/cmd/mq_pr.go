@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqPRJSON bool
+
+var mqPRCmd = &cobra.Command{
+	Use:   "pr <rig> [mr-id]",
+	Short: "Land MRs through forge pull requests",
+	Long: `Land MRs through forge pull requests, for rigs whose branch protection
+forbids pushing to the default branch.
+
+With merge_queue.land_via = "pr", the Refinery runs this instead of merging and
+pushing itself. Each pass pushes the MR branch, opens (or updates) one PR per
+MR, and merges it through the forge once the required checks and approvals
+pass. The forge's checks replace the Refinery's local verification; the review
+policy, if any, still gates the MR first.
+
+PR state maps back onto the MR:
+  waiting on checks/approvals   MR stays claimed (in_progress)
+  merged                        MR closed as merged, MERGED sent to the Witness
+  conflict, failed check,       MR released (open), MERGE_FAILED sent once
+    changes requested             per PR head
+  closed without merging        MR closed as rejected, MERGE_FAILED sent
+
+Without an MR ID, advances every open MR that already has a PR. With one,
+exits 0 when the MR landed and 1 otherwise.
+
+Config (<rig>/settings/config.json):
+  "merge_queue": {
+    "land_via": "pr",
+    "pr": {
+      "required_checks": ["ci/test"],   // default: every reported check
+      "required_approvals": 1,
+      "merge_method": "squash"          // squash, merge or rebase
+    }
+  }
+
+PRs are opened on the rig's forge (GitHub, GitLab or Gitea), found from its
+git URL; see 'gt forge' for self-hosted forges and tokens.
+
+Examples:
+  gt mq pr greenplace gp-mr-abc123
+  gt mq pr greenplace              # Advance all open PRs`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMQPR,
+}
+
+func init() {
+	mqPRCmd.Flags().BoolVar(&mqPRJSON, "json", false, "Output as JSON")
+	mqCmd.AddCommand(mqPRCmd)
+}
+
+// mqPRResult is one MR's landing pass, for --json.
+type mqPRResult struct {
+	MR          string `json:"mr"`
+	Status      string `json:"status"` // merged, waiting, failed
+	PR          string `json:"pr,omitempty"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+func runMQPR(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	if eng.Config().LandVia != config.LandViaPR {
+		return fmt.Errorf("rig '%s' lands by push; set merge_queue.land_via to \"pr\" to land through pull requests", rigName)
+	}
+	eng.SetOutput(cmd.ErrOrStderr())
+
+	var mrs []*refinery.MRInfo
+	if len(args) == 2 {
+		mr, err := eng.GetMRInfo(args[1])
+		if err != nil {
+			if err == refinery.ErrMRNotFound {
+				return fmt.Errorf("merge request '%s' not found in rig '%s'", args[1], rigName)
+			}
+			return err
+		}
+		mrs = append(mrs, mr)
+	} else {
+		all, err := eng.ListAllOpenMRs()
+		if err != nil {
+			return err
+		}
+		for _, mr := range all {
+			if mr.PRNumber > 0 {
+				mrs = append(mrs, mr)
+			}
+		}
+	}
+
+	results := make([]mqPRResult, 0, len(mrs))
+	for _, mr := range mrs {
+		result := eng.ProcessMRInfo(context.Background(), mr)
+		if result.Success {
+			eng.HandleMRInfoSuccess(mr, result)
+		} else {
+			eng.HandleMRInfoFailure(mr, result)
+		}
+
+		r := mqPRResult{MR: mr.ID, Status: "failed", Message: result.Error}
+		if result.PR != nil {
+			r.PR = result.PR.URL
+		}
+		switch {
+		case result.Success:
+			r.Status, r.MergeCommit = "merged", result.MergeCommit
+		case result.PRPending || result.ReviewPending:
+			r.Status = "waiting"
+		}
+		results = append(results, r)
+	}
+
+	if mqPRJSON {
+		if err := outputJSON(results); err != nil {
+			return err
+		}
+	} else if len(results) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no open pull requests)"))
+	} else {
+		for _, r := range results {
+			switch r.Status {
+			case "merged":
+				fmt.Printf("%s %s merged (%.8s) %s\n", style.Success.Render("✓"), r.MR, r.MergeCommit, style.Dim.Render(r.PR))
+			case "waiting":
+				fmt.Printf("%s %s %s\n", style.Warning.Render("⏸"), r.MR, r.Message)
+			default:
+				fmt.Printf("%s %s %s\n", style.Error.Render("✗"), r.MR, r.Message)
+			}
+		}
+	}
+
+	if len(args) == 2 && results[0].Status != "merged" {
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ priority override flags
+var (
+	mqBumpBy      int
+	mqBumpClear   bool
+	mqPinClear    bool
+	mqHoldReason  string
+	mqHoldClear   bool
+	mqExplainJSON bool
+)
+
+var mqBumpCmd = &cobra.Command{
+	Use:   "bump <rig> <mr-id>",
+	Short: "Boost a merge request's priority score",
+	Long: `Add points to a merge request's priority score.
+
+Boosts add up: bumping twice with the default --by adds 200 points. A
+negative --by demotes the MR. At the default weights 100 points is one
+priority level and 10 points is an hour of convoy age.
+
+Examples:
+  gt mq bump greenplace gp-mr-abc123            # +100
+  gt mq bump greenplace gp-mr-abc123 --by 500
+  gt mq bump greenplace gp-mr-abc123 --by -100  # Demote
+  gt mq bump greenplace gp-mr-abc123 --clear    # Remove the boost`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQBump,
+}
+
+var mqPinCmd = &cobra.Command{
+	Use:   "pin <rig> <mr-id>",
+	Short: "Land a merge request next",
+	Long: `Pin a merge request ahead of the queue.
+
+Pinned MRs are processed before every unpinned one, whatever their score;
+among themselves they go by score. The pin stays until the MR lands or is
+unpinned.
+
+Examples:
+  gt mq pin greenplace gp-mr-abc123
+  gt mq pin greenplace gp-mr-abc123 --clear   # Unpin`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQPin,
+}
+
+var mqHoldCmd = &cobra.Command{
+	Use:   "hold <rig> <mr-id>",
+	Short: "Keep a merge request out of the queue",
+	Long: `Hold a merge request so the Refinery doesn't process it.
+
+The MR stays open and listed (as held) until released. Holding doesn't stop
+an MR the Refinery has already claimed.
+
+Examples:
+  gt mq hold greenplace gp-mr-abc123 --reason "wait for the release branch"
+  gt mq hold greenplace gp-mr-abc123 --clear   # Release`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQHold,
+}
+
+var mqExplainCmd = &cobra.Command{
+	Use:   "explain <rig> <mr-id>",
+	Short: "Show how a merge request's priority score adds up",
+	Long: `Break a merge request's priority score into its components and show
+where it sits among the ready MRs.
+
+Components:
+  base          merge_queue.scoring.base_score
+  convoy age    convoy_age_weight per hour since the convoy was created
+  priority      priority_weight × (4 - priority)
+  retries       retry_penalty per conflict retry, up to max_retry_penalty
+  MR age        mr_age_weight per hour since the MR was submitted
+  boost         points added with 'gt mq bump'
+  conflicts     conflict_penalty per in-flight branch changing the same lines
+                (from the daemon's latest 'gt mq conflicts' prediction)
+  fairness      fairness_penalty per MR from the same convoy (worker) ahead
+                beyond convoy_cap (author_cap)
+
+Pinned MRs ('gt mq pin') go ahead of the rest and held MRs ('gt mq hold')
+are not processed.
+
+Config, all optional:
+  "merge_queue": {
+    "scoring": {
+      "priority_weight": 100,
+      "convoy_age_weight": 10,
+      "convoy_cap": 3,          // 0 = no cap
+      "author_cap": 2,
+      "fairness_penalty": 200,
+      "conflict_penalty": 0     // 0 = don't reorder by conflicts
+    }
+  }
+
+Examples:
+  gt mq explain greenplace gp-mr-abc123
+  gt mq explain greenplace gp-mr-abc123 --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQExplain,
+}
+
+func init() {
+	mqBumpCmd.Flags().IntVar(&mqBumpBy, "by", refinery.DefaultBoost, "Points to add (negative to demote)")
+	mqBumpCmd.Flags().BoolVar(&mqBumpClear, "clear", false, "Remove the boost")
+	mqPinCmd.Flags().BoolVar(&mqPinClear, "clear", false, "Unpin")
+	mqHoldCmd.Flags().StringVar(&mqHoldReason, "reason", "", "Why the MR is held")
+	mqHoldCmd.Flags().BoolVar(&mqHoldClear, "clear", false, "Release the hold")
+	mqExplainCmd.Flags().BoolVar(&mqExplainJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqBumpCmd)
+	mqCmd.AddCommand(mqPinCmd)
+	mqCmd.AddCommand(mqHoldCmd)
+	mqCmd.AddCommand(mqExplainCmd)
+}
+
+func runMQBump(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+
+	if mqBumpClear {
+		if err := eng.SetBoost(mrID, 0); err != nil {
+			return err
+		}
+		fmt.Printf("%s Cleared boost on %s\n", style.Success.Render("✓"), mrID)
+		return nil
+	}
+	boost, err := eng.BoostMR(mrID, mqBumpBy)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Bumped %s by %+d (boost now %+d)\n", style.Success.Render("✓"), mrID, mqBumpBy, boost)
+	return nil
+}
+
+func runMQPin(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+
+	if err := eng.PinMR(mrID, !mqPinClear); err != nil {
+		return err
+	}
+	if mqPinClear {
+		fmt.Printf("%s Unpinned %s\n", style.Success.Render("✓"), mrID)
+	} else {
+		fmt.Printf("%s Pinned %s ahead of the queue\n", style.Success.Render("✓"), mrID)
+	}
+	return nil
+}
+
+func runMQHold(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+
+	reason := ""
+	if !mqHoldClear {
+		reason = strings.TrimSpace(mqHoldReason)
+		if reason == "" {
+			reason = "held"
+		}
+	}
+	if err := eng.HoldMR(mrID, reason); err != nil {
+		return err
+	}
+	if mqHoldClear {
+		fmt.Printf("%s Released %s back into the queue\n", style.Success.Render("✓"), mrID)
+	} else {
+		fmt.Printf("%s Holding %s: %s\n", style.Success.Render("✓"), mrID, reason)
+	}
+	return nil
+}
+
+func runMQExplain(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	eng.SetOutput(cmd.ErrOrStderr())
+
+	x, err := eng.ExplainMR(mrID)
+	if err != nil {
+		if err == refinery.ErrMRNotFound {
+			return fmt.Errorf("merge request '%s' not found in rig '%s'", mrID, rigName)
+		}
+		return err
+	}
+	if mqExplainJSON {
+		return outputJSON(x)
+	}
+
+	mr, s, cfg := x.MR, x.Score, x.Config
+	fmt.Printf("%s %s  P%d  %s\n", style.Bold.Render(mr.ID), mr.Branch, mr.Priority, style.Dim.Render(mr.Worker))
+
+	switch {
+	case mr.Hold != "":
+		fmt.Printf("  Held: %s (not processed until 'gt mq hold --clear')\n", mr.Hold)
+	case mr.Assignee != "":
+		fmt.Printf("  Claimed by %s\n", mr.Assignee)
+	case mr.BlockedBy != "":
+		fmt.Printf("  Blocked by %s; would be #%d of %d\n", mr.BlockedBy, x.Position, x.QueueLength)
+	case x.Ready:
+		fmt.Printf("  #%d of %d ready\n", x.Position, x.QueueLength)
+	}
+	if s.Pinned {
+		fmt.Printf("  Pinned ahead of unpinned MRs\n")
+	}
+	fmt.Println()
+
+	printTerm := func(name string, value float64, detail string) {
+		line := fmt.Sprintf("  %-12s %+9.1f", name, value)
+		if detail != "" {
+			line += "  " + style.Dim.Render(detail)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("  %-12s %9.1f\n", "base", s.Base)
+	convoy := ""
+	if mr.ConvoyID != "" {
+		convoy = fmt.Sprintf("convoy %s, %.0f/hour", mr.ConvoyID, cfg.ConvoyAgeWeight)
+	}
+	printTerm("convoy age", s.ConvoyAge, convoy)
+	printTerm("priority", s.Priority, fmt.Sprintf("%.0f per level above P4", cfg.PriorityWeight))
+	printTerm("retries", 0-s.RetryPenalty, fmt.Sprintf("%d × %.0f, max %.0f", mr.RetryCount, cfg.RetryPenalty, cfg.MaxRetryPenalty))
+	printTerm("MR age", s.MRAge, fmt.Sprintf("%.0f/hour", cfg.MRAgeWeight))
+	printTerm("boost", s.Boost, "gt mq bump")
+	printTerm("conflicts", 0-s.Conflicts, fmt.Sprintf("%d overlapping branch(es) × %.0f", mr.Overlaps, cfg.ConflictPenalty))
+	fairness := "no caps"
+	if cfg.ConvoyCap > 0 || cfg.AuthorCap > 0 {
+		fairness = fmt.Sprintf("convoy cap %d, author cap %d, %.0f per MR over", cfg.ConvoyCap, cfg.AuthorCap, cfg.FairnessPenalty)
+	}
+	printTerm("fairness", 0-s.Fairness, fairness)
+	fmt.Printf("  %-12s %9.1f\n", "total", s.Total)
+	return nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ review command flags
+var (
+	mqReviewGateJSON       bool
+	mqReviewApprove        bool
+	mqReviewRequestChanges bool
+	mqReviewFindings       []string
+	mqReviewSummary        string
+	mqReviewReviewer       string
+	mqApproveReason        string
+)
+
+var mqReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Code review stage between gt done and merge",
+	Long: `Code review stage between gt done and merge.
+
+When a rig's merge_queue.review policy is enabled, the Refinery has every MR
+reviewed before merging it. It creates a review task, blocks the MR on it and
+slings the review formula (default mol-mr-review) to the policy's reviewer,
+or to a fresh polecat. The reviewer records a verdict with structured
+findings on the MR bead. Requested changes are sent back to the polecat with
+REWORK_REQUEST; a push after that starts a new review.
+
+Humans can override with 'gt mq approve'.
+
+Config (<rig>/settings/config.json):
+  "merge_queue": {
+    "review": {
+      "enabled": true,
+      "reviewer": "",            // sling target; "" = fresh polecat, "manual" = don't dispatch
+      "formula": "mol-mr-review",
+      "agent": "",               // agent/runtime override for the reviewer
+      "timeout": "2h"            // flag pending reviews as overdue in gt mq list
+    }
+  }`,
+	RunE: requireSubcommand,
+}
+
+var mqReviewGateCmd = &cobra.Command{
+	Use:   "gate <rig> <mr-id>",
+	Short: "Check an MR against the review policy before merging",
+	Long: `Check an MR against the rig's review policy. Run by the Refinery before
+merging each MR.
+
+Requests a review of the branch head when there is none, and sends the polecat
+back with REWORK_REQUEST when the review requested changes. Exits 0 when the
+MR may merge (approved, or review not required) and 1 otherwise.
+
+Examples:
+  gt mq review gate greenplace gp-mr-abc123
+  gt mq review gate greenplace gp-mr-abc123 --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQReviewGate,
+}
+
+var mqReviewRecordCmd = &cobra.Command{
+	Use:   "record <rig> <mr-id>",
+	Short: "Record a review verdict on an MR",
+	Long: `Record a reviewer's verdict and findings on an MR and close its review task.
+
+Findings are given as severity:[file:[line:]]message, where severity is one of
+blocking, major, minor or nit.
+
+Examples:
+  gt mq review record greenplace gp-mr-abc123 --approve
+  gt mq review record greenplace gp-mr-abc123 --request-changes \
+    --finding "blocking:internal/api/server.go:42:nil map write on first request" \
+    --finding "nit:missing doc comment on Serve"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQReviewRecord,
+}
+
+var mqApproveCmd = &cobra.Command{
+	Use:   "approve <rig> <mr-id>",
+	Short: "Approve an MR, overriding its review",
+	Long: `Approve an MR's current branch head, overriding a pending review or requested
+changes. The MR's review task is closed so the MR can merge.
+
+Examples:
+  gt mq approve greenplace gp-mr-abc123
+  gt mq approve greenplace gp-mr-abc123 --reason "findings are false positives"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQApprove,
+}
+
+func init() {
+	mqReviewGateCmd.Flags().BoolVar(&mqReviewGateJSON, "json", false, "Output as JSON")
+
+	mqReviewRecordCmd.Flags().BoolVar(&mqReviewApprove, "approve", false, "Approve the MR")
+	mqReviewRecordCmd.Flags().BoolVar(&mqReviewRequestChanges, "request-changes", false, "Request changes (sends findings back to the worker)")
+	mqReviewRecordCmd.Flags().StringArrayVar(&mqReviewFindings, "finding", nil, "Finding as severity:[file:[line:]]message (repeatable)")
+	mqReviewRecordCmd.Flags().StringVar(&mqReviewSummary, "summary", "", "One-line review summary")
+	mqReviewRecordCmd.Flags().StringVar(&mqReviewReviewer, "reviewer", "", "Reviewer identity (default: current agent)")
+
+	mqApproveCmd.Flags().StringVarP(&mqApproveReason, "reason", "r", "", "Why the MR is approved")
+
+	mqReviewCmd.AddCommand(mqReviewGateCmd)
+	mqReviewCmd.AddCommand(mqReviewRecordCmd)
+	mqCmd.AddCommand(mqReviewCmd)
+	mqCmd.AddCommand(mqApproveCmd)
+}
+
+// getReviewEngineer returns an Engineer for the rig with its merge queue
+// config (and review policy) loaded.
+func getReviewEngineer(rigName string) (*refinery.Engineer, error) {
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return nil, err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	return eng, nil
+}
+
+func runMQReviewGate(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	mr, err := eng.GetMRInfo(mrID)
+	if err != nil {
+		if err == refinery.ErrMRNotFound {
+			return fmt.Errorf("merge request '%s' not found in rig '%s'", mrID, rigName)
+		}
+		return err
+	}
+
+	eng.SetOutput(cmd.ErrOrStderr())
+	result, err := eng.ReviewGate(mr)
+	if err != nil {
+		return fmt.Errorf("review gate: %w", err)
+	}
+
+	if mqReviewGateJSON {
+		if err := outputJSON(result); err != nil {
+			return err
+		}
+	} else if result.Approved {
+		fmt.Printf("%s %s may merge: %s\n", style.Success.Render("✓"), mrID, result.Message)
+	} else {
+		fmt.Printf("%s %s awaiting review: %s\n", style.Warning.Render("⏸"), mrID, result.Message)
+	}
+
+	if !result.Approved {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func runMQReviewRecord(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	if mqReviewApprove == mqReviewRequestChanges {
+		return fmt.Errorf("specify exactly one of --approve or --request-changes")
+	}
+
+	var findings []refinery.ReviewFinding
+	for _, s := range mqReviewFindings {
+		f, err := refinery.ParseReviewFinding(s)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, f)
+	}
+	if mqReviewRequestChanges && len(findings) == 0 && mqReviewSummary == "" {
+		return fmt.Errorf("--request-changes needs at least one --finding or a --summary")
+	}
+	if mqReviewRequestChanges && len(findings) == 0 {
+		findings = append(findings, refinery.ReviewFinding{Severity: "blocking", Message: mqReviewSummary})
+	}
+
+	reviewer := mqReviewReviewer
+	if reviewer == "" {
+		reviewer = detectSender()
+	}
+
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	if err := eng.RecordReview(mrID, reviewer, mqReviewApprove, findings, mqReviewSummary); err != nil {
+		return fmt.Errorf("recording review: %w", err)
+	}
+
+	if mqReviewApprove {
+		fmt.Printf("%s Approved %s\n", style.Bold.Render("✓"), mrID)
+	} else {
+		fmt.Printf("%s Requested changes on %s (%d finding(s))\n", style.Bold.Render("✗"), mrID, len(findings))
+		for _, f := range findings {
+			fmt.Printf("  %s\n", f)
+		}
+		fmt.Printf("  %s\n", style.Dim.Render("The Refinery sends the findings back to the worker"))
+	}
+	return nil
+}
+
+func runMQApprove(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	eng, err := getReviewEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	approver := detectSender()
+	if err := eng.ApproveMR(mrID, approver, mqApproveReason); err != nil {
+		return fmt.Errorf("approving MR: %w", err)
+	}
+
+	fmt.Printf("%s Approved %s (by %s)\n", style.Bold.Render("✓"), mrID, approver)
+	if mqApproveReason != "" {
+		fmt.Printf("  Reason: %s\n", mqApproveReason)
+	}
+	return nil
+}
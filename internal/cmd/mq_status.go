@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -34,6 +35,13 @@ type MRStatusOutput struct {
 	MergeCommit string `json:"merge_commit,omitempty"`
 	CloseReason string `json:"close_reason,omitempty"`
 
+	// Code review
+	ReviewStatus   string                   `json:"review_status,omitempty"`
+	Reviewer       string                   `json:"reviewer,omitempty"`
+	ReviewSHA      string                   `json:"review_sha,omitempty"`
+	ReviewTask     string                   `json:"review_task,omitempty"`
+	ReviewFindings []refinery.ReviewFinding `json:"review_findings,omitempty"`
+
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
@@ -95,6 +103,11 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.Rig = mrFields.Rig
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
+		output.ReviewStatus = mrFields.ReviewStatus
+		output.Reviewer = mrFields.Reviewer
+		output.ReviewSHA = mrFields.ReviewSHA
+		output.ReviewTask = mrFields.ReviewTask
+		output.ReviewFindings = refinery.DecodeReviewFindings(mrFields.ReviewFindings)
 	}
 
 	// Add dependency info from the issue's Dependencies field
@@ -186,6 +199,31 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		}
 	}
 
+	// Code review
+	if mrFields != nil && mrFields.ReviewStatus != "" {
+		fmt.Printf("\n%s\n", style.Bold.Render("Review"))
+		fmt.Printf("   Status:   %s\n", mrFields.ReviewStatus)
+		if mrFields.Reviewer != "" {
+			fmt.Printf("   Reviewer: %s\n", mrFields.Reviewer)
+		}
+		if mrFields.ReviewSHA != "" {
+			fmt.Printf("   Head:     %s\n", mrFields.ReviewSHA)
+		}
+		if mrFields.ReviewTask != "" {
+			fmt.Printf("   Task:     %s\n", mrFields.ReviewTask)
+		}
+		if latency, pending, ok := refinery.ReviewLatency(mrFields, time.Now()); ok {
+			label := "took"
+			if pending {
+				label = "pending for"
+			}
+			fmt.Printf("   Latency:  %s %s\n", label, formatDurationShort(latency))
+		}
+		for _, f := range refinery.DecodeReviewFindings(mrFields.ReviewFindings) {
+			fmt.Printf("   %s %s\n", style.Dim.Render("•"), f)
+		}
+	}
+
 	// Dependencies (what this MR is waiting on)
 	if len(issue.Dependencies) > 0 {
 		fmt.Printf("\n%s\n", style.Bold.Render("Waiting On"))
@@ -328,6 +366,14 @@ func getDescriptionWithoutMRFields(description string) string {
 		"close-reason": true,
 		"closereason":  true,
 		"type":         true,
+
+		"review_status":       true,
+		"review_sha":          true,
+		"reviewer":            true,
+		"review_task":         true,
+		"review_requested_at": true,
+		"reviewed_at":         true,
+		"review_findings":     true,
 	}
 
 	var lines []string
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -110,6 +111,31 @@ func TestFormatMRAge(t *testing.T) {
 	}
 }
 
+func TestFormatMRReview(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		fields  *beads.MRFields
+		timeout time.Duration
+		want    string
+	}{
+		{"no review", &beads.MRFields{}, 0, "-"},
+		{"pending", &beads.MRFields{ReviewStatus: "pending", ReviewRequestedAt: "2026-01-02T11:45:00Z"}, time.Hour, "pending 15m"},
+		{"overdue", &beads.MRFields{ReviewStatus: "pending", ReviewRequestedAt: "2026-01-02T09:00:00Z"}, time.Hour, "pending 3h overdue"},
+		{"approved", &beads.MRFields{ReviewStatus: "approved", ReviewRequestedAt: "2026-01-02T09:00:00Z", ReviewedAt: "2026-01-02T09:20:00Z"}, time.Hour, "approved 20m"},
+		{"changes", &beads.MRFields{ReviewStatus: "changes_requested", ReviewRequestedAt: "2026-01-02T09:00:00Z", ReviewedAt: "2026-01-02T09:05:00Z"}, 0, "changes 5m"},
+		{"override without request", &beads.MRFields{ReviewStatus: "approved"}, 0, "approved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatMRReview(tt.fields, tt.timeout, now)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("formatMRReview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetDescriptionWithoutMRFields(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	mqtui "github.com/steveyegge/gastown/internal/tui/mq"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// runMQTUI launches the interactive merge queue TUI over the given rig, or
+// over every rig starting with the current one.
+func runMQTUI(args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var rigs []string
+	if len(args) > 0 {
+		if _, _, err := getRig(args[0]); err != nil {
+			return err
+		}
+		rigs = []string{args[0]}
+	} else {
+		rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+		if err != nil {
+			return fmt.Errorf("loading rigs config: %w", err)
+		}
+		for name := range rigsConfig.Rigs {
+			rigs = append(rigs, name)
+		}
+		sort.Strings(rigs)
+		// Start on the rig we're in, if any
+		if current, err := inferRigFromCwd(townRoot); err == nil {
+			for i, name := range rigs {
+				if name == current {
+					rigs[0], rigs[i] = rigs[i], rigs[0]
+					sort.Strings(rigs[1:])
+					break
+				}
+			}
+		}
+	}
+
+	m := mqtui.New(&mqTUISource{engineers: make(map[string]*refinery.Engineer)}, rigs)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// mqTUISource backs the merge queue TUI with each rig's Refinery engineer.
+type mqTUISource struct {
+	mu        sync.Mutex
+	engineers map[string]*refinery.Engineer
+	rigs      map[string]*rig.Rig
+}
+
+// engineer returns the rig's engineer, creating it on first use. Engineer
+// output is discarded so it cannot draw over the TUI.
+func (s *mqTUISource) engineer(rigName string) (*refinery.Engineer, *rig.Rig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if eng, ok := s.engineers[rigName]; ok {
+		return eng, s.rigs[rigName], nil
+	}
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return nil, nil, err
+	}
+	eng := refinery.NewEngineer(r)
+	eng.SetOutput(io.Discard)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if s.rigs == nil {
+		s.rigs = make(map[string]*rig.Rig)
+	}
+	s.engineers[rigName], s.rigs[rigName] = eng, r
+	return eng, r, nil
+}
+
+func (s *mqTUISource) Load(rigName string) (*mqtui.Queue, error) {
+	eng, _, err := s.engineer(rigName)
+	if err != nil {
+		return nil, err
+	}
+	mrs, err := eng.ListAllOpenMRs()
+	if err != nil {
+		return nil, err
+	}
+	anomalies, err := eng.ListQueueAnomalies(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &mqtui.Queue{MRs: mrs, Anomalies: anomalies, Scoring: eng.Config().Scoring}, nil
+}
+
+func (s *mqTUISource) Preview(rigName string, mr *refinery.MRInfo) (string, error) {
+	eng, _, err := s.engineer(rigName)
+	if err != nil {
+		return "", err
+	}
+	return eng.PreviewMR(mr)
+}
+
+func (s *mqTUISource) Reject(rigName, id, reason string) error {
+	_, r, err := s.engineer(rigName)
+	if err != nil {
+		return err
+	}
+	mgr := refinery.NewManager(r)
+	mgr.SetOutput(io.Discard)
+	_, err = mgr.RejectMR(id, reason, false)
+	return err
+}
+
+func (s *mqTUISource) Bump(rigName, id string) error {
+	eng, _, err := s.engineer(rigName)
+	if err != nil {
+		return err
+	}
+	_, err = eng.BoostMR(id, refinery.DefaultBoost)
+	return err
+}
+
+func (s *mqTUISource) Retry(rigName, id string) error {
+	eng, _, err := s.engineer(rigName)
+	if err != nil {
+		return err
+	}
+	return eng.ReleaseMR(id)
+}
+
+func (s *mqTUISource) SessionCmd(rigName string, mr *refinery.MRInfo) *exec.Cmd {
+	return exec.Command("gt", "session", "at", rigName+"/"+mr.Worker)
+}
@@ -71,7 +71,11 @@ func runPatrolNew(cmd *cobra.Command, args []string) error {
 			ExtraVars:     buildRefineryPatrolVars(roleInfo),
 		}
 	default:
-		return fmt.Errorf("unsupported role for patrol: %q (expected deacon, witness, or refinery)", roleName)
+		def := customRoleDef(roleInfo.TownRoot, Role(roleName), roleInfo.Rig)
+		if def == nil || def.PatrolFormula == "" {
+			return fmt.Errorf("unsupported role for patrol: %q (expected deacon, witness, refinery, or a custom role with a patrol_formula)", roleName)
+		}
+		cfg = customRolePatrolConfig(roleInfo, def)
 	}
 
 	// Create and hook the wisp
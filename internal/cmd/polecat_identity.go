@@ -22,9 +22,10 @@ import (
 
 // Polecat identity command flags
 var (
-	polecatIdentityListJSON    bool
-	polecatIdentityShowJSON    bool
-	polecatIdentityRemoveForce bool
+	polecatIdentityListJSON     bool
+	polecatIdentityShowJSON     bool
+	polecatIdentityRemoveForce  bool
+	polecatIdentityCapabilities []string
 )
 
 var polecatIdentityCmd = &cobra.Command{
@@ -52,9 +53,13 @@ The identity bead tracks:
   - Hook bead (current work)
   - Cleanup status
 
+Use --capabilities to declare skills that gt sling --auto matches against
+bead labels (lang:, tool:, cap:).
+
 Example:
   gt polecat identity add gastown Toast
-  gt polecat identity add gastown  # auto-generate name`,
+  gt polecat identity add gastown  # auto-generate name
+  gt polecat identity add gastown Toast --capabilities go,docker`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runPolecatIdentityAdd,
 }
@@ -118,6 +123,22 @@ Example:
 	RunE: runPolecatIdentityRename,
 }
 
+var polecatIdentityCapabilitiesCmd = &cobra.Command{
+	Use:     "capabilities <rig> <name> [capability...]",
+	Aliases: []string{"caps"},
+	Short:   "Set the capabilities a polecat identity declares",
+	Long: `Set the capabilities a polecat identity declares for capability routing.
+
+gt sling --auto prefers free identities whose capabilities match the bead's
+lang:, tool: and cap: labels. Pass no capabilities to clear them.
+
+Example:
+  gt polecat identity capabilities gastown Toast go docker
+  gt polecat identity capabilities gastown Toast   # clear`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runPolecatIdentityCapabilities,
+}
+
 var polecatIdentityRemoveCmd = &cobra.Command{
 	Use:   "remove <rig> <name>",
 	Short: "Remove a polecat identity",
@@ -138,6 +159,9 @@ Example:
 }
 
 func init() {
+	// Add flags
+	polecatIdentityAddCmd.Flags().StringSliceVar(&polecatIdentityCapabilities, "capabilities", nil, "Capabilities for routing (e.g., go,docker)")
+
 	// List flags
 	polecatIdentityListCmd.Flags().BoolVar(&polecatIdentityListJSON, "json", false, "Output as JSON")
 
@@ -152,6 +176,7 @@ func init() {
 	polecatIdentityCmd.AddCommand(polecatIdentityListCmd)
 	polecatIdentityCmd.AddCommand(polecatIdentityShowCmd)
 	polecatIdentityCmd.AddCommand(polecatIdentityRenameCmd)
+	polecatIdentityCmd.AddCommand(polecatIdentityCapabilitiesCmd)
 	polecatIdentityCmd.AddCommand(polecatIdentityRemoveCmd)
 
 	// Add identity to polecat command
@@ -160,14 +185,15 @@ func init() {
 
 // IdentityInfo holds identity bead information for display.
 type IdentityInfo struct {
-	Rig            string `json:"rig"`
-	Name           string `json:"name"`
-	BeadID         string `json:"bead_id"`
-	AgentState     string `json:"agent_state,omitempty"`
-	HookBead       string `json:"hook_bead,omitempty"`
-	CleanupStatus  string `json:"cleanup_status,omitempty"`
-	WorktreeExists bool   `json:"worktree_exists"`
-	SessionRunning bool   `json:"session_running"`
+	Rig            string   `json:"rig"`
+	Name           string   `json:"name"`
+	BeadID         string   `json:"bead_id"`
+	AgentState     string   `json:"agent_state,omitempty"`
+	HookBead       string   `json:"hook_bead,omitempty"`
+	CleanupStatus  string   `json:"cleanup_status,omitempty"`
+	Capabilities   []string `json:"capabilities,omitempty"`
+	WorktreeExists bool     `json:"worktree_exists"`
+	SessionRunning bool     `json:"session_running"`
 }
 
 // IdentityDetails holds detailed identity information for show command.
@@ -240,9 +266,10 @@ func runPolecatIdentityAdd(cmd *cobra.Command, args []string) error {
 
 	// Create identity bead
 	fields := &beads.AgentFields{
-		RoleType:   "polecat",
-		Rig:        rigName,
-		AgentState: "idle",
+		RoleType:     "polecat",
+		Rig:          rigName,
+		AgentState:   "idle",
+		Capabilities: polecatIdentityCapabilities,
 	}
 
 	title := fmt.Sprintf("Polecat %s in %s", polecatName, rigName)
@@ -254,10 +281,36 @@ func runPolecatIdentityAdd(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s Created identity bead: %s\n", style.SuccessPrefix, issue.ID)
 	fmt.Printf("  Polecat: %s\n", polecatName)
 	fmt.Printf("  Rig:     %s\n", rigName)
+	if len(polecatIdentityCapabilities) > 0 {
+		fmt.Printf("  Capabilities: %s\n", strings.Join(polecatIdentityCapabilities, ", "))
+	}
 
 	return nil
 }
 
+func runPolecatIdentityCapabilities(cmd *cobra.Command, args []string) error {
+	rigName, polecatName := args[0], args[1]
+	capabilities := args[2:]
+
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	bd := beads.New(r.Path)
+	beadID := polecatBeadIDForRig(r, rigName, polecatName)
+	if err := bd.UpdateAgentDescriptionFields(beadID, beads.AgentFieldUpdates{Capabilities: &capabilities}); err != nil {
+		return fmt.Errorf("updating identity %s: %w", beadID, err)
+	}
+
+	if len(capabilities) == 0 {
+		fmt.Printf("%s Cleared capabilities of %s/%s\n", style.SuccessPrefix, rigName, polecatName)
+	} else {
+		fmt.Printf("%s %s/%s capabilities: %s\n", style.SuccessPrefix, rigName, polecatName, strings.Join(capabilities, ", "))
+	}
+	return nil
+}
+
 func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 	rigName := args[0]
 
@@ -310,6 +363,7 @@ func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 			AgentState:     fields.AgentState,
 			HookBead:       issue.HookBead,
 			CleanupStatus:  fields.CleanupStatus,
+			Capabilities:   fields.Capabilities,
 			WorktreeExists: worktreeExists,
 			SessionRunning: sessionRunning,
 		}
@@ -426,6 +480,7 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 				AgentState:     fields.AgentState,
 				HookBead:       issue.HookBead,
 				CleanupStatus:  fields.CleanupStatus,
+				Capabilities:   fields.Capabilities,
 				WorktreeExists: worktreeExists,
 				SessionRunning: sessionRunning,
 			},
@@ -493,6 +548,11 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Cleanup:       %s\n", fields.CleanupStatus)
 	}
 
+	// Capabilities
+	if len(fields.Capabilities) > 0 {
+		fmt.Printf("  Capabilities:  %s\n", strings.Join(fields.Capabilities, ", "))
+	}
+
 	// Timestamps
 	if issue.CreatedAt != "" {
 		fmt.Printf("  Created:       %s\n", style.Dim.Render(issue.CreatedAt))
@@ -598,6 +658,7 @@ func runPolecatIdentityRename(cmd *cobra.Command, args []string) error {
 		CleanupStatus:     oldFields.CleanupStatus,
 		ActiveMR:          oldFields.ActiveMR,
 		NotificationLevel: oldFields.NotificationLevel,
+		Capabilities:      oldFields.Capabilities,
 	}
 
 	newTitle := fmt.Sprintf("Polecat %s in %s", newName, rigName)
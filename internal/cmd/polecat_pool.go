@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var polecatPoolStatusJSON bool
+
+var polecatPoolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Manage the rig's warm worktree pool",
+	Long: `Manage the warm pool of pre-created polecat worktrees.
+
+A rig with warm_pool.size > 0 in settings/config.json keeps that many
+worktrees checked out at the default branch (with setup hooks and,
+optionally, merge_queue.setup_command already run). New polecats claim a
+warm worktree instead of creating one, cutting spawn latency. The daemon
+tops the pool up and re-syncs entries when the default branch advances.
+
+Example settings:
+  "warm_pool": {"size": 2, "run_setup_command": true, "max_age": "24h"}`,
+	RunE: requireSubcommand,
+}
+
+var polecatPoolStatusCmd = &cobra.Command{
+	Use:   "status <rig>",
+	Short: "Show warm pool entries and hit rate",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolecatPoolStatus,
+}
+
+var polecatPoolFillCmd = &cobra.Command{
+	Use:   "fill <rig>",
+	Short: "Top up the warm pool now",
+	Long: `Top up the warm pool now instead of waiting for the daemon.
+
+Creates missing entries, re-syncs entries behind the default branch and
+replaces entries older than max_age.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatPoolFill,
+}
+
+var polecatPoolDrainCmd = &cobra.Command{
+	Use:   "drain <rig>",
+	Short: "Remove all warm worktrees",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolecatPoolDrain,
+}
+
+func init() {
+	polecatPoolStatusCmd.Flags().BoolVar(&polecatPoolStatusJSON, "json", false, "Output as JSON")
+
+	polecatPoolCmd.AddCommand(polecatPoolStatusCmd)
+	polecatPoolCmd.AddCommand(polecatPoolFillCmd)
+	polecatPoolCmd.AddCommand(polecatPoolDrainCmd)
+
+	polecatCmd.AddCommand(polecatPoolCmd)
+}
+
+func runPolecatPoolStatus(cmd *cobra.Command, args []string) error {
+	mgr, _, err := getPolecatManager(args[0])
+	if err != nil {
+		return err
+	}
+	status, err := mgr.WarmPoolStatus()
+	if err != nil {
+		return fmt.Errorf("reading warm pool: %w", err)
+	}
+
+	if polecatPoolStatusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Warm pool: %s", status.Rig)))
+	if status.Config == nil {
+		fmt.Printf("  %s\n", style.Dim.Render("disabled (set warm_pool.size in settings/config.json)"))
+	} else {
+		fmt.Printf("  Size:          %d\n", status.Size)
+	}
+	fmt.Printf("  Ready:         %d\n", status.Ready)
+	if status.Stale > 0 {
+		fmt.Printf("  Stale:         %s\n", style.Warning.Render(fmt.Sprintf("%d (behind %s)", status.Stale, status.BaseRef)))
+	}
+	if status.BaseSHA != "" {
+		fmt.Printf("  Base:          %s %s\n", status.BaseRef, style.Dim.Render(shortSHA(status.BaseSHA)))
+	}
+
+	s := status.Stats
+	fmt.Println()
+	fmt.Printf("%s\n", style.Bold.Render("Metrics"))
+	fmt.Printf("  Hit rate:      %.0f%% (%d hits, %d misses)\n", s.HitRate()*100, s.Hits, s.Misses)
+	fmt.Printf("  Created:       %d\n", s.Created)
+	fmt.Printf("  Refreshed:     %d\n", s.Refreshed)
+	fmt.Printf("  Discarded:     %d\n", s.Discarded)
+	if !s.LastFill.IsZero() {
+		fmt.Printf("  Last fill:     %s\n", style.Dim.Render(s.LastFill.Format(time.RFC3339)))
+	}
+
+	if len(status.Entries) > 0 {
+		fmt.Println()
+		fmt.Printf("%s\n", style.Bold.Render("Entries"))
+		for _, e := range status.Entries {
+			fmt.Printf("  %s  %s  %s\n", e.ID, shortSHA(e.BaseSHA),
+				style.Dim.Render("synced "+e.SyncedAt.Format(time.RFC3339)))
+		}
+	}
+	return nil
+}
+
+func runPolecatPoolFill(cmd *cobra.Command, args []string) error {
+	mgr, _, err := getPolecatManager(args[0])
+	if err != nil {
+		return err
+	}
+	result, err := mgr.FillWarmPool()
+	if err != nil {
+		return fmt.Errorf("filling warm pool: %w", err)
+	}
+	if result.Skipped {
+		fmt.Printf("%s Warm pool fill already in progress\n", style.Dim.Render("○"))
+		return nil
+	}
+	fmt.Printf("%s Warm pool filled: created %d, refreshed %d, discarded %d\n",
+		style.Success.Render("✓"), result.Created, result.Refreshed, result.Discarded)
+	return nil
+}
+
+func runPolecatPoolDrain(cmd *cobra.Command, args []string) error {
+	mgr, _, err := getPolecatManager(args[0])
+	if err != nil {
+		return err
+	}
+	n, err := mgr.DrainWarmPool()
+	if err != nil {
+		return fmt.Errorf("draining warm pool: %w", err)
+	}
+	fmt.Printf("%s Removed %d warm worktree(s)\n", style.Success.Render("✓"), n)
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
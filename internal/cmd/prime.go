@@ -556,6 +556,9 @@ func buildRoleAnnouncement(ctx RoleContext) string {
 	case RoleCrew:
 		return fmt.Sprintf("%s Crew %s, checking in.", ctx.Rig, ctx.Polecat)
 	default:
+		if customRoleDef(ctx.TownRoot, ctx.Role, ctx.Rig) != nil {
+			return fmt.Sprintf("%s, checking in.", ctx.ActorString())
+		}
 		return "Agent, checking in."
 	}
 }
@@ -588,6 +591,9 @@ func getAgentIdentity(ctx RoleContext) string {
 	case RoleRefinery:
 		return fmt.Sprintf("%s/refinery", ctx.Rig)
 	default:
+		if customRoleDef(ctx.TownRoot, ctx.Role, ctx.Rig) != nil {
+			return ctx.ActorString()
+		}
 		return ""
 	}
 }
@@ -680,6 +686,14 @@ func getAgentBeadID(ctx RoleContext) string {
 		}
 		return ""
 	default:
+		if def := customRoleDef(ctx.TownRoot, ctx.Role, ctx.Rig); def != nil {
+			if !def.IsRigScoped() {
+				return customRoleBeadID(ctx.TownRoot, ctx.Role, "")
+			}
+			if ctx.Rig != "" {
+				return customRoleBeadID(ctx.TownRoot, ctx.Role, ctx.Rig)
+			}
+		}
 		return ""
 	}
 }
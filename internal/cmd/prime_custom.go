@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/cli"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/templates"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// outputCustomRoleContext outputs the context of a custom role: its prompt
+// template (resolved relative to <town>/roles/) when it has one, otherwise a
+// generic description of the role.
+func outputCustomRoleContext(ctx RoleContext, def *config.RoleDefinition) error {
+	if def.PromptTemplate != "" {
+		path := def.PromptTemplate
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(ctx.TownRoot, "roles", path)
+		}
+		townName, _ := workspace.GetTownName(ctx.TownRoot)
+		defaultBranch := "main"
+		if ctx.Rig != "" {
+			if rigCfg, err := rig.LoadRigConfig(filepath.Join(ctx.TownRoot, ctx.Rig)); err == nil && rigCfg.DefaultBranch != "" {
+				defaultBranch = rigCfg.DefaultBranch
+			}
+		}
+		output, err := templates.RenderRoleFile(path, templates.RoleData{
+			Role:          def.Role,
+			RigName:       ctx.Rig,
+			TownRoot:      ctx.TownRoot,
+			TownName:      townName,
+			WorkDir:       ctx.WorkDir,
+			DefaultBranch: defaultBranch,
+			MayorSession:  session.MayorSessionName(),
+			DeaconSession: session.DeaconSessionName(),
+			Address:       def.MailAddress(ctx.Rig),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(output)
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("# %s Context", def.Role)))
+	if ctx.Rig != "" {
+		fmt.Printf("You are the **%s** agent for rig %s.\n", def.Role, style.Bold.Render(ctx.Rig))
+	} else {
+		fmt.Printf("You are the town's **%s** agent.\n", def.Role)
+	}
+	if def.Description != "" {
+		fmt.Printf("\n%s\n", def.Description)
+	}
+	fmt.Println()
+	fmt.Printf("Your mail address is `%s`. Check it with `%s mail inbox`.\n", def.MailAddress(ctx.Rig), cli.Name())
+	fmt.Printf("Work slung to you lands on your hook: `%s hook`.\n", cli.Name())
+	fmt.Println()
+	fmt.Printf("Town root: %s\n", style.Dim.Render(ctx.TownRoot))
+	return nil
+}
+
+// outputCustomRolePatrolContext shows the status of a custom role's patrol
+// molecule, auto-bonding one on startup like the witness.
+func outputCustomRolePatrolContext(ctx RoleContext, def *config.RoleDefinition) {
+	outputPatrolContext(customRolePatrolConfig(ctx, def))
+}
+
+// customRolePatrolConfig returns the patrol config of a custom role with a
+// patrol formula.
+func customRolePatrolConfig(ctx RoleContext, def *config.RoleDefinition) PatrolConfig {
+	beadsDir := ctx.WorkDir
+	if !def.IsRigScoped() {
+		beadsDir = ctx.TownRoot
+	}
+	return PatrolConfig{
+		RoleName:        def.Role,
+		PatrolMolName:   def.PatrolFormula,
+		BeadsDir:        beadsDir,
+		Assignee:        ctx.ActorString(),
+		HeaderEmoji:     "🔄",
+		HeaderTitle:     fmt.Sprintf("%s Patrol Status", def.Role),
+		CheckInProgress: true,
+		WorkLoopSteps: []string{
+			"Check inbox: `" + cli.Name() + " mail inbox`",
+			"Check next step: `bd mol current`",
+			"Execute the step",
+			"Close step: `bd close <step-id>`",
+			"Check next: `bd mol current`",
+			"At cycle end (loop-or-exit step):\n   - If context LOW:\n     * Squash: `bd mol squash <mol-id> --summary \"<summary>\"`\n     * Create new patrol: `" + cli.Name() + " patrol new`\n     * Continue executing from inbox-check step\n   - If context HIGH:\n     * Send handoff: `" + cli.Name() + " handoff -s \"" + def.Role + " patrol\" -m \"<observations>\"`\n     * Exit cleanly (daemon respawns fresh session)",
+		},
+	}
+}
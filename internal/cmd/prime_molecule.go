@@ -107,6 +107,14 @@ func showMoleculeExecutionPrompt(workDir, moleculeID string) {
 
 // outputMoleculeContext checks if the agent is working on a molecule step and shows progress.
 func outputMoleculeContext(ctx RoleContext) {
+	// Custom roles with a patrol formula run it like the witness
+	if def := customRoleDef(ctx.TownRoot, ctx.Role, ctx.Rig); def != nil {
+		if def.PatrolFormula != "" {
+			outputCustomRolePatrolContext(ctx, def)
+		}
+		return
+	}
+
 	// Applies to polecats, crew workers, deacon, witness, and refinery
 	if ctx.Role != RolePolecat && ctx.Role != RoleCrew && ctx.Role != RoleDeacon && ctx.Role != RoleWitness && ctx.Role != RoleRefinery {
 		return
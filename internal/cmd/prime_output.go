@@ -20,6 +20,11 @@ import (
 
 // outputPrimeContext outputs the role-specific context using templates or fallback.
 func outputPrimeContext(ctx RoleContext) error {
+	// Custom roles bring their own prompt template
+	if def := customRoleDef(ctx.TownRoot, ctx.Role, ctx.Rig); def != nil {
+		return outputCustomRoleContext(ctx, def)
+	}
+
 	// Try to use templates first
 	tmpl, err := templates.New()
 	if err != nil {
@@ -183,8 +183,13 @@ func GetRoleWithContext(cwd, townRoot string) (RoleInfo, error) {
 
 	// Determine authoritative role
 	if envRole != "" {
-		// Parse env role - it might be simple ("mayor") or compound ("gastown/witness")
+		// Parse env role - it might be simple ("mayor") or compound ("gastown/witness").
+		// Custom roles are checked first: parseRoleString would take
+		// "gastown/reviewer" for a polecat.
 		parsedRole, rig, polecat := parseRoleString(envRole)
+		if customRole, customRig, ok := parseCustomRoleString(envRole, townRoot); ok {
+			parsedRole, rig, polecat = customRole, customRig, ""
+		}
 		info.Role = parsedRole
 		info.Rig = rig
 		info.Polecat = polecat
@@ -282,6 +287,13 @@ func detectRole(cwd, townRoot string) RoleInfo {
 		return ctx
 	}
 
+	// Check for custom roles in their default homes: <role>/ or <rig>/<role>/
+	if role, rig, ok := detectCustomRole(parts, townRoot); ok {
+		ctx.Role = role
+		ctx.Rig = rig
+		return ctx
+	}
+
 	// At this point, first part should be a rig name
 	if len(parts) < 1 {
 		return ctx
@@ -410,6 +422,10 @@ func (info RoleInfo) ActorString() string {
 	case RoleBoot:
 		return "deacon-boot"
 	default:
+		// Rig-scoped custom roles: "gastown/reviewer"
+		if info.Rig != "" && customRoleDef(info.TownRoot, info.Role, info.Rig) != nil {
+			return fmt.Sprintf("%s/%s", info.Rig, info.Role)
+		}
 		return string(info.Role)
 	}
 }
@@ -444,6 +460,12 @@ func getRoleHome(role Role, rig, polecat, townRoot string) string {
 	case RoleBoot:
 		return filepath.Join(townRoot, "deacon", "dogs", "boot")
 	default:
+		if def := customRoleDef(townRoot, role, rig); def != nil {
+			if def.IsRigScoped() && rig == "" {
+				return ""
+			}
+			return def.WorkDir(townRoot, rig)
+		}
 		return ""
 	}
 }
@@ -596,6 +618,24 @@ func runRoleList(cmd *cobra.Command, args []string) error {
 	for _, r := range roles {
 		fmt.Printf("  %-10s  %s\n", style.Bold.Render(string(r.name)), r.desc)
 	}
+
+	townRoot, _ := workspace.FindFromCwd()
+	defs, errs := config.LoadCustomRoles(townRoot)
+	if len(defs) > 0 || len(errs) > 0 {
+		fmt.Println()
+		fmt.Println("Custom roles:")
+		fmt.Println()
+	}
+	for _, def := range defs {
+		desc := def.Description
+		if desc == "" {
+			desc = "(no description)"
+		}
+		fmt.Printf("  %-10s  %s %s\n", style.Bold.Render(def.Role), desc, style.Dim.Render("["+def.Scope+"]"))
+	}
+	for _, name := range sortedErrorKeys(errs) {
+		fmt.Printf("  %-10s  %s\n", style.Bold.Render(name), style.Dim.Render("invalid: "+errs[name].Error()))
+	}
 	return nil
 }
 
@@ -659,8 +699,11 @@ func runRoleEnv(cmd *cobra.Command, args []string) error {
 func runRoleDef(cmd *cobra.Command, args []string) error {
 	roleName := args[0]
 
+	// Determine town root and rig path
+	townRoot, _ := workspace.FindFromCwd()
+
 	// Validate role name
-	validRoles := config.AllRoles()
+	validRoles := append(config.AllRoles(), config.CustomRoleNames(townRoot)...)
 	isValid := false
 	for _, r := range validRoles {
 		if r == roleName {
@@ -672,8 +715,6 @@ func runRoleDef(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown role %q - valid roles: %s", roleName, strings.Join(validRoles, ", "))
 	}
 
-	// Determine town root and rig path
-	townRoot, _ := workspace.FindFromCwd()
 	rigPath := ""
 	if townRoot != "" {
 		// Try to get rig path if we're in a rig directory
@@ -691,6 +732,15 @@ func runRoleDef(cmd *cobra.Command, args []string) error {
 	// Display role info
 	fmt.Printf("%s %s\n", style.Bold.Render("Role:"), def.Role)
 	fmt.Printf("%s %s\n", style.Bold.Render("Scope:"), def.Scope)
+	if def.Custom {
+		if def.Description != "" {
+			fmt.Printf("%s %s\n", style.Bold.Render("Description:"), def.Description)
+		}
+		if def.PatrolFormula != "" {
+			fmt.Printf("%s %s\n", style.Bold.Render("Patrol:"), def.PatrolFormula)
+		}
+		fmt.Printf("%s %v\n", style.Bold.Render("Keep alive:"), def.KeepAlive)
+	}
 	fmt.Println()
 
 	// Session config
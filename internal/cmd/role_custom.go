@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/customrole"
+)
+
+// customRoleEmoji is shown for custom role agents, which have no emoji of
+// their own.
+const customRoleEmoji = "🔧"
+
+// customRoleDef loads the definition of a custom role as seen from rig, or
+// returns nil if role is not a custom role of the town.
+func customRoleDef(townRoot string, role Role, rig string) *config.RoleDefinition {
+	if !config.IsCustomRole(townRoot, string(role)) {
+		return nil
+	}
+	rigPath := ""
+	if rig != "" {
+		rigPath = filepath.Join(townRoot, rig)
+	}
+	def, err := config.LoadRoleDefinition(townRoot, rigPath, string(role))
+	if err != nil {
+		return nil
+	}
+	return def
+}
+
+// parseCustomRoleString recognizes custom role identities: "<rig>/<role>"
+// for rig-scoped roles and "<role>" (or "<role>/") for town-scoped ones.
+func parseCustomRoleString(s, townRoot string) (Role, string, bool) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "/")
+	rig, role, ok := strings.Cut(s, "/")
+	if !ok {
+		rig, role = "", rig
+	}
+	if strings.Contains(role, "/") {
+		return "", "", false
+	}
+	def := customRoleDef(townRoot, Role(role), rig)
+	if def == nil || def.IsRigScoped() != (rig != "") {
+		return "", "", false
+	}
+	return Role(role), rig, true
+}
+
+// detectCustomRole detects a custom role from cwd path parts relative to
+// the town root, matching the role's default work dirs: <role>/ for town
+// roles and <rig>/<role>/ for rig roles.
+func detectCustomRole(parts []string, townRoot string) (Role, string, bool) {
+	if len(parts) >= 1 {
+		if def := customRoleDef(townRoot, Role(parts[0]), ""); def != nil && !def.IsRigScoped() {
+			return Role(parts[0]), "", true
+		}
+	}
+	if len(parts) >= 2 {
+		if def := customRoleDef(townRoot, Role(parts[1]), parts[0]); def != nil && def.IsRigScoped() {
+			return Role(parts[1]), parts[0], true
+		}
+	}
+	return "", "", false
+}
+
+// customRoleBeadID returns the agent bead ID of a custom role agent:
+// hq-<role> for town-scoped roles, <prefix>-<rig>-<role> for rig-scoped ones.
+func customRoleBeadID(townRoot string, role Role, rig string) string {
+	if rig == "" {
+		return beads.AgentBeadIDWithPrefix(beads.TownBeadsPrefix, "", string(role), "")
+	}
+	return beads.AgentBeadIDWithPrefix(beads.GetPrefixForRig(townRoot, rig), rig, string(role), "")
+}
+
+// customRoleAgentDefs returns the status agent definitions of the custom
+// role agents in rig, or of the town-scoped ones when rig is empty. Role
+// files that fail to load are skipped; gt doctor reports them.
+func customRoleAgentDefs(townRoot, rig string) []agentDef {
+	var rigs []string
+	if rig != "" {
+		rigs = []string{rig}
+	}
+	managers, _ := customrole.ForTown(townRoot, rigs)
+	var defs []agentDef
+	for _, m := range managers {
+		if m.RigName() != rig {
+			continue
+		}
+		defs = append(defs, agentDef{
+			name:    m.Role().Role,
+			address: m.Address(),
+			session: m.SessionName(),
+			role:    m.Role().Role,
+			beadID:  m.AgentBeadID(),
+		})
+	}
+	return defs
+}
+
+// sortedErrorKeys returns the keys of a per-name error map, sorted.
+func sortedErrorKeys(errs map[string]error) []string {
+	keys := make([]string, 0, len(errs))
+	for k := range errs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
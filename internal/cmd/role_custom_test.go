@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupCustomRoleTown(t *testing.T) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	rolesDir := filepath.Join(townRoot, "roles")
+	if err := os.MkdirAll(rolesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"reviewer.toml": `scope = "rig"`,
+		"triage.toml":   `scope = "town"`,
+	} {
+		if err := os.WriteFile(filepath.Join(rolesDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return townRoot
+}
+
+func TestParseCustomRoleString(t *testing.T) {
+	townRoot := setupCustomRoleTown(t)
+
+	tests := []struct {
+		input    string
+		wantRole Role
+		wantRig  string
+		wantOK   bool
+	}{
+		{"gastown/reviewer", "reviewer", "gastown", true},
+		{"triage", "triage", "", true},
+		{"triage/", "triage", "", true},
+		// Scope must match: rig roles need a rig, town roles must not have one
+		{"reviewer", "", "", false},
+		{"gastown/triage", "", "", false},
+		// Built-in and undefined roles are not custom
+		{"gastown/witness", "", "", false},
+		{"gastown/unknown", "", "", false},
+		{"gastown/crew/reviewer", "", "", false},
+	}
+
+	for _, tt := range tests {
+		role, rig, ok := parseCustomRoleString(tt.input, townRoot)
+		if role != tt.wantRole || rig != tt.wantRig || ok != tt.wantOK {
+			t.Errorf("parseCustomRoleString(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.input, role, rig, ok, tt.wantRole, tt.wantRig, tt.wantOK)
+		}
+	}
+}
+
+func TestBuildAgentBeadIDCustomRole(t *testing.T) {
+	townRoot := setupCustomRoleTown(t)
+
+	tests := []struct {
+		identity string
+		role     Role
+		want     string
+	}{
+		{"gastown/reviewer", Role("reviewer"), "gt-gastown-reviewer"},
+		{"gastown/reviewer", RoleUnknown, "gt-gastown-reviewer"},
+		{"triage", Role("triage"), "hq-triage"},
+		{"triage", RoleUnknown, "hq-triage"},
+	}
+
+	for _, tt := range tests {
+		got := buildAgentBeadID(tt.identity, tt.role, townRoot)
+		if got != tt.want {
+			t.Errorf("buildAgentBeadID(%q, %q) = %q, want %q", tt.identity, tt.role, got, tt.want)
+		}
+	}
+}
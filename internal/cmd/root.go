@@ -66,6 +66,7 @@ var beadsExemptCommands = map[string]bool{
 	"krc":           true, // KRC doesn't require beads
 	"run-migration": true, // Migration orchestrator handles its own beads checks
 	"mcp-server":    true, // MCP server handles beads internally
+	"secrets":       true, // Secrets store is independent of beads
 }
 
 // Commands exempt from the town root branch warning.
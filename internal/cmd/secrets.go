@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/secrets"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	GroupID: GroupConfig,
+	Short:   "Manage encrypted secrets for agents",
+	Long: `Manage encrypted secrets delivered to agent worktrees and sessions.
+
+Secrets replace plaintext rig overlays (.runtime/overlay/.env) and
+credential-copying setup hooks. Each town and rig has one store, sealed
+with NaCl secretbox under the town master key (.runtime/secrets/master.key
+or $GT_SECRETS_KEY):
+
+  <town>/.runtime/secrets/town.enc   Town-wide secrets
+  <rig>/.runtime/secrets.enc         Rig secrets (override town by name)
+
+Delivery modes:
+  env (default)   Exported into the agent session at start (sourced from a
+                  0600 file, never placed on the command line)
+  --file <path>   Written into the worktree at spawn (mode 0600) and
+                  scrubbed when the polecat is nuked
+
+Use --role to restrict a secret to specific roles (e.g., polecat,crew).
+Known secret values are redacted from captured pane output, events and mail.
+
+Examples:
+  gt secrets set GITHUB_TOKEN --rig gastown --role polecat < token.txt
+  gt secrets set dotenv --rig gastown --file .env --from-file ./prod.env
+  gt secrets list --rig gastown
+  gt secrets rm GITHUB_TOKEN --rig gastown
+  gt secrets migrate-overlay gastown`,
+	RunE: requireSubcommand,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Create or update a secret",
+	Long: `Create or update a secret.
+
+The value is taken from the argument, --from-file, or stdin (in that order).
+Prefer stdin or --from-file so the value does not land in shell history.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretsSet,
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a secret value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretsGet,
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secrets (names and scopes only)",
+	RunE:  runSecretsList,
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretsRm,
+}
+
+var secretsMigrateOverlayCmd = &cobra.Command{
+	Use:   "migrate-overlay <rig>",
+	Short: "Move plaintext overlay files into the rig's secrets store",
+	Long: `Move files from <rig>/.runtime/overlay/ into the rig's encrypted store.
+
+Each overlay file becomes a file-mode secret materialized at the same
+name in new worktrees. The plaintext overlay file is removed after the
+store is saved, unless --keep is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsMigrateOverlay,
+}
+
+var (
+	secretsRig      string
+	secretsRoles    []string
+	secretsFile     string
+	secretsFromFile string
+	secretsJSON     bool
+	secretsKeep     bool
+)
+
+func init() {
+	for _, c := range []*cobra.Command{secretsSetCmd, secretsGetCmd, secretsListCmd, secretsRmCmd} {
+		c.Flags().StringVar(&secretsRig, "rig", "", "Rig store to use (default: town store)")
+	}
+	secretsSetCmd.Flags().StringSliceVar(&secretsRoles, "role", nil, "Restrict to roles (comma-separated, default: all)")
+	secretsSetCmd.Flags().StringVar(&secretsFile, "file", "", "Materialize as this worktree-relative file instead of an env var")
+	secretsSetCmd.Flags().StringVar(&secretsFromFile, "from-file", "", "Read the value from a local file")
+	secretsListCmd.Flags().BoolVar(&secretsJSON, "json", false, "Output as JSON")
+	secretsMigrateOverlayCmd.Flags().BoolVar(&secretsKeep, "keep", false, "Keep plaintext overlay files after migrating")
+
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+	secretsCmd.AddCommand(secretsMigrateOverlayCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// openSecretsStore opens the town store, or the rig store when rigName is set.
+func openSecretsStore(rigName string, create bool) (*secrets.Store, error) {
+	if rigName == "" {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		return secrets.OpenTown(townRoot, create)
+	}
+	townRoot, r, err := getRig(rigName)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.OpenRig(townRoot, r.Path, create)
+}
+
+// validateSecretRoles rejects role names the town does not know about:
+// anything other than a built-in role or one of the town's custom roles.
+func validateSecretRoles(townRoot string, roles []string) error {
+	known := append(config.AllRoles(), config.CustomRoleNames(townRoot)...)
+	for _, role := range roles {
+		found := false
+		for _, k := range known {
+			if role == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown role %q - valid roles: %v", role, known)
+		}
+	}
+	return nil
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := secrets.ValidateName(name); err != nil {
+		return err
+	}
+	if len(secretsRoles) > 0 {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		if err := validateSecretRoles(townRoot, secretsRoles); err != nil {
+			return err
+		}
+	}
+
+	var value string
+	switch {
+	case len(args) == 2:
+		value = args[1]
+	case secretsFromFile != "":
+		data, err := os.ReadFile(secretsFromFile)
+		if err != nil {
+			return fmt.Errorf("reading value file: %w", err)
+		}
+		value = string(data)
+	default:
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading value from stdin: %w", err)
+		}
+		value = string(data)
+		// Single-line stdin values lose their trailing newline; file-mode
+		// values (e.g., a whole .env) are kept verbatim.
+		if secretsFile == "" {
+			value = strings.TrimRight(value, "\r\n")
+		}
+	}
+	if value == "" {
+		return fmt.Errorf("empty secret value")
+	}
+
+	store, err := openSecretsStore(secretsRig, true)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(secrets.Secret{
+		Name:  name,
+		Value: value,
+		Roles: secretsRoles,
+		File:  secretsFile,
+	}); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	secrets.ResetRedactorCache()
+
+	fmt.Printf("%s Set secret %s in %s\n", style.SuccessPrefix, name, secretsScopeLabel(secretsRig))
+	return nil
+}
+
+func runSecretsGet(cmd *cobra.Command, args []string) error {
+	store, err := openSecretsStore(secretsRig, false)
+	if err != nil {
+		return err
+	}
+	sec, ok := store.Get(args[0])
+	if !ok {
+		return fmt.Errorf("%w: %s", secrets.ErrNotFound, args[0])
+	}
+	fmt.Print(sec.Value)
+	if !strings.HasSuffix(sec.Value, "\n") {
+		fmt.Println()
+	}
+	return nil
+}
+
+// secretListEntry is the JSON shape for gt secrets list. Values are omitted.
+type secretListEntry struct {
+	Name      string   `json:"name"`
+	Mode      string   `json:"mode"`
+	File      string   `json:"file,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	store, err := openSecretsStore(secretsRig, false)
+	if err != nil {
+		return err
+	}
+
+	var entries []secretListEntry
+	for _, sec := range store.List() {
+		mode := "env"
+		if sec.IsFile() {
+			mode = "file"
+		}
+		entries = append(entries, secretListEntry{
+			Name:      sec.Name,
+			Mode:      mode,
+			File:      sec.File,
+			Roles:     sec.Roles,
+			UpdatedAt: sec.UpdatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	if secretsJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No secrets in %s\n", secretsScopeLabel(secretsRig))
+		return nil
+	}
+
+	fmt.Println(style.Bold.Render("Secrets in " + secretsScopeLabel(secretsRig)))
+	table := style.NewTable(
+		style.Column{Name: "NAME", Width: 24},
+		style.Column{Name: "MODE", Width: 14},
+		style.Column{Name: "ROLES", Width: 20},
+		style.Column{Name: "UPDATED", Width: 16},
+	)
+	for _, e := range entries {
+		mode := e.Mode
+		if e.File != "" {
+			mode = "file:" + e.File
+		}
+		roles := "all"
+		if len(e.Roles) > 0 {
+			roles = strings.Join(e.Roles, ",")
+		}
+		table.AddRow(e.Name, mode, roles, e.UpdatedAt)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runSecretsRm(cmd *cobra.Command, args []string) error {
+	store, err := openSecretsStore(secretsRig, false)
+	if err != nil {
+		return err
+	}
+	if !store.Delete(args[0]) {
+		return fmt.Errorf("%w: %s", secrets.ErrNotFound, args[0])
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	secrets.ResetRedactorCache()
+
+	fmt.Printf("%s Removed secret %s from %s\n", style.SuccessPrefix, args[0], secretsScopeLabel(secretsRig))
+	return nil
+}
+
+func runSecretsMigrateOverlay(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	townRoot, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	overlayDir := filepath.Join(r.Path, ".runtime", "overlay")
+	entries, err := os.ReadDir(overlayDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("No overlay directory for rig %s - nothing to migrate\n", rigName)
+			return nil
+		}
+		return fmt.Errorf("reading overlay dir: %w", err)
+	}
+
+	store, err := secrets.OpenRig(townRoot, r.Path, true)
+	if err != nil {
+		return err
+	}
+
+	migrated, err := stageOverlaySecrets(store, overlayDir, entries)
+	if err != nil {
+		return err
+	}
+	if len(migrated) == 0 {
+		fmt.Printf("Overlay for rig %s is empty - nothing to migrate\n", rigName)
+		return nil
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	secrets.ResetRedactorCache()
+
+	for _, name := range migrated {
+		if !secretsKeep {
+			if err := os.Remove(filepath.Join(overlayDir, name)); err != nil {
+				style.PrintWarning("could not remove plaintext %s: %v", name, err)
+			}
+		}
+		fmt.Printf("%s Migrated %s → secret %s\n", style.SuccessPrefix, name, overlaySecretName(name))
+	}
+	return nil
+}
+
+// stageOverlaySecrets adds each overlay file to store as a file-mode secret
+// and returns the migrated file names. It does not save the store. It fails
+// if two overlay files, or an overlay file and an existing secret for a
+// different target, would share a secret name.
+func stageOverlaySecrets(store *secrets.Store, overlayDir string, entries []os.DirEntry) ([]string, error) {
+	var migrated []string
+	fileFor := make(map[string]string) // secret name -> overlay file
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := overlaySecretName(entry.Name())
+		if other, ok := fileFor[name]; ok {
+			return nil, fmt.Errorf("overlay files %s and %s both map to secret %s - rename one and retry", other, entry.Name(), name)
+		}
+		if existing, ok := store.Get(name); ok && existing.File != entry.Name() {
+			return nil, fmt.Errorf("overlay file %s maps to secret %s, which already exists for %s", entry.Name(), name, secretTarget(existing))
+		}
+		fileFor[name] = entry.Name()
+
+		data, err := os.ReadFile(filepath.Join(overlayDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading overlay file %s: %w", entry.Name(), err)
+		}
+		if err := store.Set(secrets.Secret{
+			Name:  name,
+			Value: string(data),
+			File:  entry.Name(),
+		}); err != nil {
+			return nil, err
+		}
+		migrated = append(migrated, entry.Name())
+	}
+	return migrated, nil
+}
+
+// overlaySecretName derives a valid secret name from an overlay file name
+// (".env" → "overlay_env", "config.json" → "overlay_config_json").
+func overlaySecretName(fileName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(fileName, "."))
+	return "overlay_" + name
+}
+
+// secretTarget describes where a secret is delivered, for error messages.
+func secretTarget(sec *secrets.Secret) string {
+	if sec.IsFile() {
+		return "file " + sec.File
+	}
+	return "an env var"
+}
+
+func secretsScopeLabel(rigName string) string {
+	if rigName == "" {
+		return "town store"
+	}
+	return "rig " + rigName
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/secrets"
+)
+
+func TestOverlaySecretName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{".env", "overlay_env"},
+		{"config.json", "overlay_config_json"},
+		{".env.local", "overlay_env_local"},
+	}
+	for _, tt := range tests {
+		if got := overlaySecretName(tt.file); got != tt.want {
+			t.Errorf("overlaySecretName(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSecretRoles(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := validateSecretRoles(townRoot, []string{"polecat", "crew"}); err != nil {
+		t.Errorf("validateSecretRoles(polecat,crew) error = %v", err)
+	}
+	if err := validateSecretRoles(townRoot, nil); err != nil {
+		t.Errorf("validateSecretRoles(nil) error = %v", err)
+	}
+	if err := validateSecretRoles(townRoot, []string{"janitor"}); err == nil {
+		t.Error("validateSecretRoles(janitor) should fail without a janitor role")
+	}
+
+	// Custom roles defined by the town can be given scoped secrets.
+	rolesDir := filepath.Join(townRoot, "roles")
+	if err := os.MkdirAll(rolesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rolesDir, "janitor.toml"), []byte("role = \"janitor\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSecretRoles(townRoot, []string{"janitor", "polecat"}); err != nil {
+		t.Errorf("validateSecretRoles(janitor) with custom role error = %v", err)
+	}
+}
+
+func TestStageOverlaySecrets_RejectsNameCollision(t *testing.T) {
+	overlayDir := t.TempDir()
+	for _, name := range []string{".env-local", ".env.local"} {
+		if err := os.WriteFile(filepath.Join(overlayDir, name), []byte("K=v\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(overlayDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := secrets.Open(filepath.Join(t.TempDir(), "secrets.enc"), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stageOverlaySecrets(store, overlayDir, entries)
+	if err == nil || !strings.Contains(err.Error(), "overlay_env_local") {
+		t.Fatalf("stageOverlaySecrets() error = %v, want collision on overlay_env_local", err)
+	}
+}
+
+func TestStageOverlaySecrets_RejectsExistingSecretForOtherTarget(t *testing.T) {
+	overlayDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(overlayDir, ".env"), []byte("K=v\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(overlayDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := secrets.Open(filepath.Join(t.TempDir(), "secrets.enc"), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(secrets.Secret{Name: "overlay_env", Value: "token"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stageOverlaySecrets(store, overlayDir, entries); err == nil {
+		t.Fatal("stageOverlaySecrets() should refuse to overwrite an env secret")
+	}
+	if sec, _ := store.Get("overlay_env"); sec.Value != "token" {
+		t.Errorf("existing secret overwritten: %q", sec.Value)
+	}
+
+	// Re-migrating the same file is an update, not a collision.
+	if err := store.Set(secrets.Secret{Name: "overlay_env", Value: "old", File: ".env"}); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := stageOverlaySecrets(store, overlayDir, entries)
+	if err != nil || len(migrated) != 1 {
+		t.Fatalf("stageOverlaySecrets() = %v, %v", migrated, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -52,6 +53,17 @@ Target Resolution:
   gt sling gt-abc mayor                 # Mayor
   gt sling gt-abc deacon/dogs           # Auto-dispatch to idle dog
   gt sling gt-abc deacon/dogs/alpha     # Specific dog
+  gt sling gt-abc acme@host:gastown     # Rig in another town (see gt federation)
+
+Capability Routing (--auto):
+  gt sling gt-abc --auto                # Pick rig, agent and account
+  gt sling gt-abc --auto --dry-run      # Show the choice and why
+
+  Beads declare requirements as labels: lang:go, tool:docker, cap:frontend,
+  tier:medium (max cost tier), context:200k (min context window). Rigs,
+  agents, accounts and polecat identities declare capabilities; the router
+  rejects candidates that miss a requirement and ranks the rest by skill
+  overlap, cost and past success rates from the events log.
 
 Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --create               # Create polecat if missing
@@ -124,6 +136,7 @@ var (
 	slingNoBoot        bool   // --no-boot: skip wakeRigAgents (avoid witness/refinery boot and lock contention)
 	slingMaxConcurrent int    // --max-concurrent: limit concurrent spawns in batch mode
 	slingBaseBranch    string // --base-branch: override base branch for polecat worktree
+	slingAuto          bool   // --auto: pick target, agent and account by capabilities
 )
 
 func init() {
@@ -148,6 +161,7 @@ func init() {
 	slingCmd.Flags().BoolVar(&slingNoBoot, "no-boot", false, "Skip rig boot after polecat spawn (avoids witness/refinery lock contention)")
 	slingCmd.Flags().IntVar(&slingMaxConcurrent, "max-concurrent", 0, "Limit concurrent polecat spawns in batch mode (0 = no limit)")
 	slingCmd.Flags().StringVar(&slingBaseBranch, "base-branch", "", "Override base branch for polecat worktree (e.g., 'develop', 'release/v2')")
+	slingCmd.Flags().BoolVar(&slingAuto, "auto", false, "Pick rig, agent and account from the bead's capability labels")
 
 	rootCmd.AddCommand(slingCmd)
 }
@@ -168,6 +182,10 @@ func runSling(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if slingAuto && len(args) > 1 {
+		return fmt.Errorf("--auto picks the target itself: sling one bead without a target")
+	}
+
 	// Disable Dolt auto-commit for all bd commands run during sling (gt-u6n6a).
 	// Under concurrent load (batch slinging), auto-commits from individual bd writes
 	// cause manifest contention and 'database is read only' errors. The Dolt server
@@ -217,6 +235,11 @@ func runSling(cmd *cobra.Command, args []string) error {
 		args[i] = strings.TrimRight(args[i], "/")
 	}
 
+	// Federated target (town@host:rig/agent): the other town does the work.
+	if len(args) == 2 && federation.IsAddress(args[1]) {
+		return runFederatedSling(townRoot, args[0], args[1])
+	}
+
 	// Validate target format early, before any dispatch path (bead, formula, batch)
 	// can trigger resolveTarget side-effects like polecat spawning.
 	if len(args) > 1 {
@@ -263,6 +286,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 			// Not a verified bead - try as standalone formula
 			if err := verifyFormulaExists(firstArg); err == nil {
 				// Standalone formula mode: gt sling <formula> [target]
+				if slingAuto {
+					return fmt.Errorf("--auto routes beads by their labels; use --on <bead> to route a formula")
+				}
 				return runSlingFormula(args)
 			}
 			// Not a formula either - check if it looks like a bead ID (routing issue workaround).
@@ -308,12 +334,33 @@ func runSling(cmd *cobra.Command, args []string) error {
 	if len(args) > 1 {
 		target = args[1]
 	}
+	agentOverride, account, create := slingAgent, slingAccount, slingCreate
+	if slingAuto {
+		result, err := routeBead(townRoot, beadID, info.Labels)
+		if err != nil {
+			return fmt.Errorf("routing %s: %w", beadID, err)
+		}
+		printRouteDecision(beadID, result)
+		target = result.Best.Target()
+		if agentOverride == "" {
+			agentOverride = result.Best.Agent
+		}
+		if account == "" {
+			account = result.Best.Account
+		}
+		if result.Best.Polecat != "" {
+			create = true // identity exists; its worktree is created on demand
+		}
+		if !slingDryRun {
+			logRouteDecision(detectActor(), beadID, result.Best)
+		}
+	}
 	resolved, err := resolveTarget(target, ResolveTargetOptions{
 		DryRun:     slingDryRun,
 		Force:      force,
-		Create:     slingCreate,
-		Account:    slingAccount,
-		Agent:      slingAgent,
+		Create:     create,
+		Account:    account,
+		Agent:      agentOverride,
 		NoBoot:     slingNoBoot,
 		HookBead:   beadID,
 		BeadID:     beadID,
@@ -520,7 +567,11 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	// Log sling event to activity feed
 	actor := detectActor()
-	_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadID, targetAgent))
+	slingPayload := events.SlingPayload(beadID, targetAgent)
+	if agentOverride != "" {
+		slingPayload["agent"] = agentOverride
+	}
+	_ = events.LogFeed(events.TypeSling, actor, slingPayload)
 
 	// Update agent bead's hook_bead field (ZFC: agents track their current work)
 	// Skip if hook was already set atomically during polecat spawn - avoids "agent bead not found"
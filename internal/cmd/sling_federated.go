@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/federation"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// runFederatedSling hands a bead to a rig or agent in another town.
+//
+// The other town creates its own bead for the work and slings it there. The
+// local bead is hooked to the federated address and labeled with the remote
+// bead, so convoys tracking it follow the remote bead's status.
+func runFederatedSling(townRoot, beadID, target string) error {
+	if slingOnTarget != "" || slingAuto {
+		return fmt.Errorf("--on and --auto can't be used with a federated target")
+	}
+	b := beads.New(resolveBeadDir(beadID))
+	issue, err := b.Show(beadID)
+	if err != nil {
+		return fmt.Errorf("bead '%s' not found", beadID)
+	}
+	if (issue.Status == "pinned" || issue.Status == "hooked") && !slingForce {
+		return fmt.Errorf("bead %s is already %s to %s\nUse --force to re-sling", beadID, issue.Status, issue.Assignee)
+	}
+
+	if slingDryRun {
+		fmt.Printf("Would sling %s to %s\n", beadID, target)
+		return nil
+	}
+
+	env := &federation.Envelope{
+		Kind:   federation.KindSling,
+		Sender: detectSender(),
+		Sling: &federation.Sling{
+			Bead:        beadID,
+			Title:       issue.Title,
+			Description: issue.Description,
+			Type:        issue.Type,
+			Priority:    issue.Priority,
+			Args:        slingArgs,
+		},
+	}
+	reply, err := federation.SendTo(context.Background(), townRoot, target, env)
+	if err != nil {
+		return err
+	}
+	addr, _ := federation.ParseAddress(target)
+
+	status := "hooked"
+	if err := b.Update(beadID, beads.UpdateOptions{
+		Status:    &status,
+		Assignee:  &target,
+		AddLabels: []string{federation.RemoteLabel(addr.Town, reply.Bead)},
+	}); err != nil {
+		style.PrintWarning("slung, but couldn't record it on %s: %v", beadID, err)
+	}
+
+	fmt.Printf("%s Slung %s to %s\n", style.Bold.Render("🎯"), beadID, target)
+	fmt.Printf("  %s created %s for it\n", reply.Town, style.Bold.Render(reply.Bead))
+	fmt.Printf("  %s\n", style.Dim.Render("Convoys tracking "+beadID+" follow "+addr.Town+":"+reply.Bead))
+	return nil
+}
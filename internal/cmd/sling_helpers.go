@@ -69,11 +69,12 @@ func resolveBeadDirFromRigsJSON(townRoot, prefix string) string {
 	return ""
 }
 
-// beadInfo holds status and assignee for a bead.
+// beadInfo holds status, assignee and labels for a bead.
 type beadInfo struct {
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Assignee string   `json:"assignee"`
+	Labels   []string `json:"labels,omitempty"`
 }
 
 // verifyBeadExists checks that the bead exists using bd show.
@@ -361,6 +362,11 @@ func agentIDToBeadID(agentID, townRoot string) string {
 		return beads.DeaconBeadIDTown()
 	}
 
+	// Custom roles: "<rig>/<role>" or "<role>"
+	if role, rig, ok := parseCustomRoleString(agentID, townRoot); ok {
+		return customRoleBeadID(townRoot, role, rig)
+	}
+
 	// Parse path-style agent IDs
 	parts := strings.Split(agentID, "/")
 	if len(parts) < 2 {
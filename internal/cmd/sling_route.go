@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/routing"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// routingHistoryWindow bounds how far back gt sling --auto learns from.
+const routingHistoryWindow = 30 * 24 * time.Hour
+
+// routeBead picks a rig, agent and account for beadID from its routing labels
+// (lang:, tool:, cap:, tier:, context:) and the capabilities declared in rig
+// settings, agent presets, accounts and polecat identities.
+func routeBead(townRoot, beadID string, labels []string) (*routing.Result, error) {
+	req, err := routing.RequirementsFromLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	rigs, _, err := getAllRigs()
+	if err != nil {
+		return nil, fmt.Errorf("listing rigs: %w", err)
+	}
+	townSettings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		townSettings = config.NewTownSettings()
+	}
+	owner := beads.GetRigNameForPrefix(townRoot, beads.ExtractPrefix(beadID))
+
+	in := routing.Input{Bead: beadID, Requirements: req}
+	for _, r := range rigs {
+		if IsRigParked(townRoot, r.Name) {
+			continue
+		}
+		rigSettings, _ := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+		candidate := routing.Rig{
+			Name:     r.Name,
+			OwnsBead: r.Name == owner,
+			Agents:   routingAgents(townRoot, r.Path, townSettings, rigSettings),
+		}
+		if rigSettings != nil {
+			candidate.Capabilities = rigSettings.Capabilities
+		}
+		if len(req.Capabilities) > 0 {
+			candidate.Polecats = freePolecatIdentities(r.Path, r.Name)
+		}
+		in.Rigs = append(in.Rigs, candidate)
+	}
+
+	if accts, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot)); err == nil {
+		for handle, a := range accts.Accounts {
+			in.Accounts = append(in.Accounts, routing.Account{
+				Handle:       handle,
+				Capabilities: a.Capabilities,
+				Default:      handle == accts.Default,
+			})
+		}
+		sort.Slice(in.Accounts, func(i, j int) bool { return in.Accounts[i].Handle < in.Accounts[j].Handle })
+	}
+
+	if h, err := routing.LoadHistory(townRoot, routingHistoryWindow); err == nil {
+		in.History = h
+	}
+
+	return routing.Route(in)
+}
+
+// routingAgents lists the agents a rig can run polecats with: the rig's
+// default polecat agent plus every installed agent that declares capabilities.
+func routingAgents(townRoot, rigPath string, townSettings *config.TownSettings, rigSettings *config.RigSettings) []routing.Agent {
+	defaultName, _ := config.ResolveRoleAgentName("polecat", townRoot, rigPath)
+
+	names := map[string]bool{defaultName: true}
+	if rigSettings != nil {
+		for name := range rigSettings.Agents {
+			names[name] = true
+		}
+	}
+	for name := range townSettings.Agents {
+		names[name] = true
+	}
+	for _, name := range config.ListAgentPresets() {
+		names[name] = true
+	}
+
+	var agents []routing.Agent
+	for name := range names {
+		caps := config.ResolveAgentCapabilities(name, townSettings, rigSettings)
+		isDefault := name == defaultName
+		if caps == nil && !isDefault {
+			continue
+		}
+		rc, _, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, name)
+		if err != nil {
+			continue
+		}
+		if !isDefault {
+			if _, err := exec.LookPath(rc.Command); err != nil {
+				continue
+			}
+		}
+		agents = append(agents, routing.Agent{
+			Name:         name,
+			Capabilities: caps,
+			Default:      isDefault,
+			UsesAccounts: rc.Provider == string(config.AgentClaude) || filepath.Base(rc.Command) == "claude",
+		})
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return agents
+}
+
+// freePolecatIdentities returns polecat identities in a rig that declare
+// capabilities and have neither hooked work nor a live worktree.
+func freePolecatIdentities(rigPath, rigName string) []routing.Polecat {
+	agentBeads, err := beads.New(rigPath).ListAgentBeads()
+	if err != nil {
+		return nil
+	}
+	var polecats []routing.Polecat
+	for id, issue := range agentBeads {
+		beadRig, role, name, ok := beads.ParseAgentBeadID(id)
+		if !ok || role != "polecat" || beadRig != rigName || issue.Status == "closed" {
+			continue
+		}
+		fields := beads.ParseAgentFields(issue.Description)
+		if len(fields.Capabilities) == 0 || fields.HookBead != "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(rigPath, "polecats", name)); err == nil {
+			continue
+		}
+		polecats = append(polecats, routing.Polecat{Name: name, Capabilities: fields.Capabilities})
+	}
+	sort.Slice(polecats, func(i, j int) bool { return polecats[i].Name < polecats[j].Name })
+	return polecats
+}
+
+// printRouteDecision explains the routing choice and the runners-up.
+func printRouteDecision(beadID string, result *routing.Result) {
+	best := result.Best
+	fmt.Printf("%s Routed %s to %s (agent %s", style.Bold.Render("🧭"), beadID, best.Target(), best.Agent)
+	if best.Account != "" {
+		fmt.Printf(", account %s", best.Account)
+	}
+	fmt.Printf(", score %.2f)\n", best.Score)
+	for _, reason := range best.Reasons {
+		fmt.Printf("  %s %s\n", style.Dim.Render("•"), reason)
+	}
+	for i, alt := range result.Alternatives {
+		if i == 2 {
+			fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("(+%d more candidates)", len(result.Alternatives)-i)))
+			break
+		}
+		label := alt.Target() + " agent " + alt.Agent
+		if alt.Account != "" {
+			label += " account " + alt.Account
+		}
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("runner-up: %s (score %.2f)", label, alt.Score)))
+	}
+	if len(result.Rejected) > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("rejected: "+strings.Join(result.Rejected, "; ")))
+	}
+}
+
+// logRouteDecision records the decision so later routing can learn from it.
+func logRouteDecision(actor, beadID string, d *routing.Decision) {
+	_ = events.LogAudit(events.TypeRouted, actor,
+		events.RoutedPayload(beadID, d.Target(), d.Agent, d.Account, d.Score, d.Reasons))
+}
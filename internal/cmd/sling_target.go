@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/customrole"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -118,8 +119,8 @@ type ResolvedTarget struct {
 }
 
 // resolveTarget resolves a target specification to agent, pane, and working directory.
-// Handles: "." or empty (self), dog targets, rig targets (auto-spawn polecat),
-// existing agents (with dead polecat fallback).
+// Handles: "." or empty (self), dog targets, custom role agents, rig targets
+// (auto-spawn polecat), existing agents (with dead polecat fallback).
 func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, error) {
 	result := &ResolvedTarget{}
 
@@ -171,6 +172,11 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 		return result, nil
 	}
 
+	// Custom role target: "<rig>/<role>" or "<role>" (starts the agent if needed)
+	if role, rigName, ok := parseCustomRoleString(target, opts.TownRoot); ok {
+		return resolveCustomRoleTarget(string(role), rigName, opts)
+	}
+
 	// Rig target (auto-spawn polecat)
 	if rigName, isRig := IsRigName(target); isRig {
 		if opts.BeadID != "" && !opts.Force {
@@ -249,3 +255,28 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 	result.WorkDir = workDir
 	return result, nil
 }
+
+// resolveCustomRoleTarget resolves a custom role agent as a sling target,
+// starting its session first if it is not running.
+func resolveCustomRoleTarget(role, rigName string, opts ResolveTargetOptions) (*ResolvedTarget, error) {
+	mgr, err := customrole.Load(opts.TownRoot, role, rigName)
+	if err != nil {
+		return nil, err
+	}
+	agentID := strings.TrimSuffix(mgr.Address(), "/")
+	if opts.DryRun {
+		fmt.Printf("Would sling to %s (session %s)\n", agentID, mgr.SessionName())
+		return &ResolvedTarget{Agent: agentID, Pane: "<" + role + "-pane>", WorkDir: mgr.WorkDir()}, nil
+	}
+	if running, _ := mgr.IsRunning(); !running {
+		fmt.Printf("Starting %s...\n", agentID)
+		if err := mgr.Start(opts.Agent); err != nil && err != customrole.ErrAlreadyRunning {
+			return nil, fmt.Errorf("starting %s: %w", agentID, err)
+		}
+	}
+	pane, err := getSessionPane(mgr.SessionName())
+	if err != nil {
+		return nil, fmt.Errorf("getting pane for %s: %w", mgr.SessionName(), err)
+	}
+	return &ResolvedTarget{Agent: agentID, Pane: pane, WorkDir: mgr.WorkDir()}, nil
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/stats"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	statsBy     string
+	statsRig    string
+	statsSince  string
+	statsFormat string
+	statsJSON   bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	GroupID: GroupDiag,
+	Short:   "Agent performance analytics",
+	Long: `Report how agents perform, from the events log, merge-request beads and
+session costs.
+
+Each sling of a bead to a worker is an attempt. Metrics per group:
+  slung         Attempts started
+  completed     Attempts that reached gt done
+  merged        Completed work that landed on the default branch
+  merge %       Merged / (merged + rejected or failed merges)
+  rework        Work sent back: re-slung after done, or review rework
+  conflicts     Merge conflict cycles
+  escalations   Escalations raised by the worker (and the rate per attempt)
+  time to done  Median time from sling to gt done
+  cost          Session costs (gt costs record), and cost per merged bead
+
+Costs already rolled into daily digests carry no per-worker detail and are
+not counted.`,
+	RunE: requireSubcommand,
+}
+
+var statsAgentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Metrics per worker identity or agent preset",
+	Long: `Show metrics per worker identity (rig/polecats/name), or per agent preset
+with --by agent to compare models for a role.
+
+Attempts that didn't record an agent preset use the rig's current polecat
+agent.
+
+Examples:
+  gt stats agents
+  gt stats agents --by agent --since 7d
+  gt stats agents --rig gastown --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runStatsAgents,
+}
+
+var statsRigsCmd = &cobra.Command{
+	Use:   "rigs",
+	Short: "Metrics per rig",
+	Long: `Show metrics per rig, across all workers and agent presets.
+
+Examples:
+  gt stats rigs
+  gt stats rigs --since 90d --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsRigs,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{statsAgentsCmd, statsRigsCmd} {
+		c.Flags().StringVar(&statsRig, "rig", "", "Only this rig's work")
+		c.Flags().StringVar(&statsSince, "since", "30d", "Start: a duration ago (7d, 24h), RFC 3339 time or date; \"all\" for everything")
+		c.Flags().StringVar(&statsFormat, "format", "table", "Output format: table, json or csv")
+		c.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON (same as --format json)")
+		statsCmd.AddCommand(c)
+	}
+	statsAgentsCmd.Flags().StringVar(&statsBy, "by", "identity", "Group by: identity or agent")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStatsAgents(cmd *cobra.Command, args []string) error {
+	switch stats.Grouping(statsBy) {
+	case stats.ByIdentity, stats.ByAgent:
+	default:
+		return fmt.Errorf("invalid --by %q: want identity or agent", statsBy)
+	}
+	return runStats(stats.Grouping(statsBy))
+}
+
+func runStatsRigs(cmd *cobra.Command, args []string) error {
+	return runStats(stats.ByRig)
+}
+
+func runStats(by stats.Grouping) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	format := statsFormat
+	if statsJSON {
+		format = "json"
+	}
+	if format != "table" && format != "json" && format != "csv" {
+		return fmt.Errorf("invalid --format %q: want table, json or csv", format)
+	}
+
+	var since time.Time
+	if statsSince != "all" && statsSince != "" {
+		if since, err = parseEventsTime(statsSince, time.Now()); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+
+	c, err := stats.Load(townRoot, since)
+	if err != nil {
+		return fmt.Errorf("loading stats: %w", err)
+	}
+	return outputStats(c.Metrics(by, statsRig), by, format)
+}
+
+func outputStats(rows []stats.Metrics, by stats.Grouping, format string) error {
+	header := []string{string(by), "slung", "completed", "merged", "merge_failed", "merge_success_rate",
+		"rework", "conflicts", "escalations", "escalation_rate", "median_time_to_done_seconds",
+		"cost_usd", "cost_per_merged_usd"}
+
+	switch format {
+	case "json":
+		if rows == nil {
+			rows = []stats.Metrics{}
+		}
+		return outputJSON(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(header)
+		for _, m := range rows {
+			_ = w.Write([]string{m.Key,
+				strconv.Itoa(m.Slung), strconv.Itoa(m.Completed), strconv.Itoa(m.Merged), strconv.Itoa(m.MergeFailed),
+				strconv.FormatFloat(m.MergeSuccessRate, 'f', 3, 64),
+				strconv.Itoa(m.Rework), strconv.Itoa(m.Conflicts), strconv.Itoa(m.Escalations),
+				strconv.FormatFloat(m.EscalationRate, 'f', 3, 64),
+				strconv.FormatFloat(m.MedianDoneSecs, 'f', 0, 64),
+				strconv.FormatFloat(m.CostUSD, 'f', 2, 64),
+				strconv.FormatFloat(m.CostPerMerged, 'f', 2, 64)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no work recorded in this period)"))
+		return nil
+	}
+	keyName := map[stats.Grouping]string{stats.ByIdentity: "IDENTITY", stats.ByAgent: "AGENT", stats.ByRig: "RIG"}[by]
+	table := style.NewTable(
+		style.Column{Name: keyName, Width: 28},
+		style.Column{Name: "SLUNG", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "DONE", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "MERGED", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "MERGE%", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "REWORK", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "CONFL", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "ESC%", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "TO DONE", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "COST", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "$/MERGE", Width: 8, Align: style.AlignRight},
+	)
+	for _, m := range rows {
+		table.AddRow(m.Key,
+			strconv.Itoa(m.Slung), strconv.Itoa(m.Completed), strconv.Itoa(m.Merged),
+			formatStatsRate(m.MergeSuccessRate, m.Merged+m.MergeFailed),
+			strconv.Itoa(m.Rework), strconv.Itoa(m.Conflicts),
+			formatStatsRate(m.EscalationRate, m.Slung),
+			formatStatsDuration(m.MedianTimeToDone),
+			fmt.Sprintf("$%.2f", m.CostUSD),
+			formatStatsCost(m.CostPerMerged, m.Merged))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// formatStatsRate formats a rate as a percentage, or "-" with no samples.
+func formatStatsRate(rate float64, samples int) string {
+	if samples == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+func formatStatsDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return formatDurationShort(d)
+}
+
+func formatStatsCost(cost float64, merged int) string {
+	if merged == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -274,7 +274,7 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 	beadsWg.Add(1)
 	go func() {
 		defer beadsWg.Done()
-		townBeadsClient := beads.New(townBeadsPath)
+		townBeadsClient := beads.New(townBeadsPath).WithSQLReads()
 		townAgentBeads, _ := townBeadsClient.ListAgentBeads()
 		mergeAgentBeads(townAgentBeads)
 
@@ -304,7 +304,7 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 		go func(r *rig.Rig) {
 			defer beadsWg.Done()
 			rigBeadsPath := filepath.Join(r.Path, "mayor", "rig")
-			rigBeads := beads.New(rigBeadsPath)
+			rigBeads := beads.New(rigBeadsPath).WithSQLReads()
 			rigAgentBeads, _ := rigBeads.ListAgentBeads()
 			if rigAgentBeads == nil {
 				return
@@ -371,7 +371,7 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		status.Agents = discoverGlobalAgents(allSessions, allAgentBeads, allHookBeads, mailRouter, statusFast)
+		status.Agents = discoverGlobalAgents(townRoot, allSessions, allAgentBeads, allHookBeads, mailRouter, statusFast)
 	}()
 
 	// Process all rigs in parallel
@@ -494,6 +494,9 @@ func outputStatusText(status TownStatus) error {
 		if icon == "" {
 			icon = roleIcons[agent.Name]
 		}
+		if icon == "" {
+			icon = customRoleEmoji
+		}
 		if statusVerbose {
 			fmt.Printf("%s %s\n", icon, style.Bold.Render(capitalizeFirst(agent.Name)))
 			renderAgentDetails(agent, "   ", nil, status.Location)
@@ -518,7 +521,7 @@ func outputStatusText(status TownStatus) error {
 		fmt.Printf("─── %s ───────────────────────────────────────────\n\n", style.Bold.Render(r.Name+"/"))
 
 		// Group agents by role
-		var witnesses, refineries, crews, polecats []AgentRuntime
+		var witnesses, refineries, crews, polecats, customs []AgentRuntime
 		for _, agent := range r.Agents {
 			switch agent.Role {
 			case "witness":
@@ -529,6 +532,8 @@ func outputStatusText(status TownStatus) error {
 				crews = append(crews, agent)
 			case "polecat":
 				polecats = append(polecats, agent)
+			default:
+				customs = append(customs, agent)
 			}
 		}
 
@@ -609,8 +614,19 @@ func outputStatusText(status TownStatus) error {
 			}
 		}
 
+		// Custom roles
+		for _, agent := range customs {
+			if statusVerbose {
+				fmt.Printf("%s %s\n", customRoleEmoji, style.Bold.Render(capitalizeFirst(agent.Name)))
+				renderAgentDetails(agent, "   ", r.Hooks, status.Location)
+				fmt.Println()
+			} else {
+				renderAgentCompact(agent, customRoleEmoji+" ", r.Hooks, status.Location)
+			}
+		}
+
 		// No agents
-		if len(witnesses) == 0 && len(refineries) == 0 && len(crews) == 0 && len(polecats) == 0 {
+		if len(r.Agents) == 0 {
 			fmt.Printf("   %s\n", style.Dim.Render("(no agents)"))
 		}
 		fmt.Println()
@@ -915,7 +931,7 @@ func discoverRigHooks(r *rig.Rig, crews []string) []AgentHookInfo {
 	var hooks []AgentHookInfo
 
 	// Create beads instance for the rig
-	b := beads.New(r.Path)
+	b := beads.New(r.Path).WithSQLReads()
 
 	// Check polecats
 	for _, name := range r.Polecats {
@@ -944,41 +960,30 @@ func discoverRigHooks(r *rig.Rig, crews []string) []AgentHookInfo {
 	return hooks
 }
 
-// discoverGlobalAgents checks runtime state for town-level agents (Mayor, Deacon).
-// Uses parallel fetching for performance. If skipMail is true, mail lookups are skipped.
+// discoverGlobalAgents checks runtime state for town-level agents (Mayor, Deacon,
+// and town-scoped custom roles). Uses parallel fetching for performance. If skipMail is true, mail lookups are skipped.
 // allSessions is a preloaded map of tmux sessions for O(1) lookup.
 // allAgentBeads is a preloaded map of agent beads for O(1) lookup.
 // allHookBeads is a preloaded map of hook beads for O(1) lookup.
-func discoverGlobalAgents(allSessions map[string]bool, allAgentBeads map[string]*beads.Issue, allHookBeads map[string]*beads.Issue, mailRouter *mail.Router, skipMail bool) []AgentRuntime {
+func discoverGlobalAgents(townRoot string, allSessions map[string]bool, allAgentBeads map[string]*beads.Issue, allHookBeads map[string]*beads.Issue, mailRouter *mail.Router, skipMail bool) []AgentRuntime {
 	// Get session names dynamically
 	mayorSession := getMayorSessionName()
 	deaconSession := getDeaconSessionName()
 
 	// Define agents to discover
 	// Note: Mayor and Deacon are town-level agents with hq- prefix bead IDs
-	agentDefs := []struct {
-		name    string
-		address string
-		session string
-		role    string
-		beadID  string
-	}{
+	agentDefs := []agentDef{
 		{"mayor", "mayor/", mayorSession, "coordinator", beads.MayorBeadIDTown()},
 		{"deacon", "deacon/", deaconSession, "health-check", beads.DeaconBeadIDTown()},
 	}
+	agentDefs = append(agentDefs, customRoleAgentDefs(townRoot, "")...)
 
 	agents := make([]AgentRuntime, len(agentDefs))
 	var wg sync.WaitGroup
 
 	for i, def := range agentDefs {
 		wg.Add(1)
-		go func(idx int, d struct {
-			name    string
-			address string
-			session string
-			role    string
-			beadID  string
-		}) {
+		go func(idx int, d agentDef) {
 			defer wg.Done()
 
 			agent := AgentRuntime{
@@ -1108,6 +1113,9 @@ func discoverRigAgents(allSessions map[string]bool, r *rig.Rig, crews []string,
 		})
 	}
 
+	// Rig-scoped custom roles
+	defs = append(defs, customRoleAgentDefs(townRoot, r.Name)...)
+
 	if len(defs) == 0 {
 		return nil
 	}
@@ -1174,7 +1182,7 @@ func getMQSummary(r *rig.Rig) *MQSummary {
 	}
 
 	// Create beads instance for the rig
-	b := beads.New(r.BeadsPath())
+	b := beads.New(r.BeadsPath()).WithSQLReads()
 
 	// Query for all open merge-request issues
 	opts := beads.ListOptions{
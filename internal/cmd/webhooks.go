@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/webhook"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Webhooks command flags
+var (
+	webhooksAddEvents []string
+	webhooksAddRig    string
+	webhooksAddSecret string
+	webhooksListJSON  bool
+	webhooksTestEvent string
+	webhooksDeadLimit int
+	webhooksDeadJSON  bool
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	GroupID: GroupComm,
+	Short:   "Send town events to external HTTP endpoints",
+	Long: `Manage outbound webhook subscriptions.
+
+The daemon tails the events log (.events.jsonl) and POSTs each event matching a
+subscription to its URL as JSON:
+
+  {"id": "<delivery id>", "webhook": "<subscription id>", "event": {...}}
+
+Each request is signed with the subscription's secret:
+
+  X-Gastown-Timestamp: <unix seconds>
+  X-Gastown-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+
+Non-2xx responses and network errors are retried with exponential backoff
+(6 attempts by default). Deliveries that still fail, or are rejected with a
+4xx, go to the dead-letter log (daemon/webhooks-dead.jsonl). Delivery is
+at-least-once: dedupe on the delivery ID, which is stable across retries.
+
+Subscriptions are stored in settings/webhooks.json (mode 0600).`,
+	RunE: requireSubcommand,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Subscribe a URL to town events",
+	Long: `Subscribe a URL to town events.
+
+--events takes event types (globs allowed); without it every event is sent.
+--rig limits deliveries to events concerning one rig. A signing secret is
+generated unless --secret is given.
+
+Examples:
+  gt webhooks add https://example.com/hooks/gt --events merged,escalation_sent
+  gt webhooks add https://example.com/hooks/gt --events 'merge_*' --rig gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksAdd,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhook subscriptions",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a webhook subscription",
+	Long:  `Remove a webhook subscription. Its pending retries are dropped.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWebhooksRemove,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <id>",
+	Short: "Send a test delivery to a subscription",
+	Long: `Send one signed test delivery to a subscription's URL and report the response.
+
+The test event is not written to the events log and is not retried. It is
+sent regardless of the subscription's event and rig filters.
+
+Examples:
+  gt webhooks test wh-1a2b3c4d
+  gt webhooks test wh-1a2b3c4d --event merged`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksTest,
+}
+
+var webhooksDeadCmd = &cobra.Command{
+	Use:   "dead",
+	Short: "Show deliveries that failed permanently",
+	Long: `Show the dead-letter log: deliveries that exhausted their retries or were
+rejected by the receiver.`,
+	Args: cobra.NoArgs,
+	RunE: runWebhooksDead,
+}
+
+func init() {
+	webhooksAddCmd.Flags().StringSliceVar(&webhooksAddEvents, "events", nil, "Event types to send (comma-separated, globs allowed; default all)")
+	webhooksAddCmd.Flags().StringVar(&webhooksAddRig, "rig", "", "Only send events concerning this rig")
+	webhooksAddCmd.Flags().StringVar(&webhooksAddSecret, "secret", "", "Signing secret (default: generated)")
+
+	webhooksListCmd.Flags().BoolVar(&webhooksListJSON, "json", false, "Output as JSON")
+
+	webhooksTestCmd.Flags().StringVar(&webhooksTestEvent, "event", "webhook_test", "Event type of the test delivery")
+
+	webhooksDeadCmd.Flags().IntVarP(&webhooksDeadLimit, "limit", "n", 20, "Show only the most recent N entries (0 = all)")
+	webhooksDeadCmd.Flags().BoolVar(&webhooksDeadJSON, "json", false, "Output as JSON")
+
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksRemoveCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	webhooksCmd.AddCommand(webhooksDeadCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+func runWebhooksAdd(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := webhook.LoadConfig(townRoot)
+	if err != nil {
+		return err
+	}
+
+	sub := &webhook.Subscription{
+		URL:    args[0],
+		Events: webhooksAddEvents,
+		Rig:    webhooksAddRig,
+		Secret: webhooksAddSecret,
+	}
+	if err := cfg.Add(sub); err != nil {
+		return err
+	}
+	if err := webhook.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Added webhook %s → %s\n", style.Success.Render("✓"), style.Bold.Render(sub.ID), sub.URL)
+	fmt.Printf("  Events: %s\n", formatWebhookEvents(sub))
+	if sub.Rig != "" {
+		fmt.Printf("  Rig:    %s\n", sub.Rig)
+	}
+	if webhooksAddSecret == "" {
+		fmt.Printf("  Secret: %s\n", sub.Secret)
+		fmt.Printf("  %s\n", style.Dim.Render("Verify X-Gastown-Signature with this secret; it is not shown again"))
+	}
+	fmt.Printf("  %s\n", style.Dim.Render("Deliveries start with the next event (daemon must be running)"))
+	return nil
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := webhook.LoadConfig(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if webhooksListJSON {
+		// Never print secrets.
+		type listed struct {
+			ID        string    `json:"id"`
+			URL       string    `json:"url"`
+			Events    []string  `json:"events,omitempty"`
+			Rig       string    `json:"rig,omitempty"`
+			Disabled  bool      `json:"disabled,omitempty"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+		out := make([]listed, 0, len(cfg.Subscriptions))
+		for _, s := range cfg.Subscriptions {
+			out = append(out, listed{s.ID, s.URL, s.Events, s.Rig, s.Disabled, s.CreatedAt})
+		}
+		return outputJSON(out)
+	}
+
+	if len(cfg.Subscriptions) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no webhooks; add one with 'gt webhooks add <url>')"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 12},
+		style.Column{Name: "URL", Width: 44},
+		style.Column{Name: "EVENTS", Width: 28},
+		style.Column{Name: "RIG", Width: 12},
+	)
+	for _, s := range cfg.Subscriptions {
+		id := s.ID
+		if s.Disabled {
+			id = style.Dim.Render(id + " (off)")
+		}
+		table.AddRow(id, truncateString(s.URL, 44), truncateString(formatWebhookEvents(s), 28), s.Rig)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runWebhooksRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := webhook.LoadConfig(townRoot)
+	if err != nil {
+		return err
+	}
+	if !cfg.Remove(args[0]) {
+		return fmt.Errorf("webhook %s not found", args[0])
+	}
+	if err := webhook.SaveConfig(townRoot, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed webhook %s\n", style.Success.Render("✓"), args[0])
+	return nil
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := webhook.LoadConfig(townRoot)
+	if err != nil {
+		return err
+	}
+	sub := cfg.Get(args[0])
+	if sub == nil {
+		return fmt.Errorf("webhook %s not found", args[0])
+	}
+
+	payload := map[string]interface{}{"message": "test delivery from gt webhooks test"}
+	if sub.Rig != "" {
+		payload["rig"] = sub.Rig
+	}
+	e := &events.Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       webhooksTestEvent,
+		Actor:      detectSender(),
+		Payload:    payload,
+		Visibility: events.VisibilityAudit,
+	}
+	env, err := webhook.NewEnvelope(sub, e)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	result := webhook.Send(context.Background(), client, sub, env, 1)
+	if !result.OK() {
+		fmt.Printf("%s %s → %s: %s (%s)\n", style.Error.Render("✗"), sub.ID, sub.URL,
+			result.Error, result.Duration.Round(time.Millisecond))
+		return NewSilentExit(1)
+	}
+	fmt.Printf("%s %s → %s: HTTP %d (%s)\n", style.Success.Render("✓"), sub.ID, sub.URL,
+		result.StatusCode, result.Duration.Round(time.Millisecond))
+	fmt.Printf("  Delivery: %s\n", env.ID)
+	return nil
+}
+
+func runWebhooksDead(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	dead, err := webhook.ReadDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	if webhooksDeadLimit > 0 && len(dead) > webhooksDeadLimit {
+		dead = dead[len(dead)-webhooksDeadLimit:]
+	}
+
+	if webhooksDeadJSON {
+		if dead == nil {
+			dead = []webhook.DeadLetter{}
+		}
+		return outputJSON(dead)
+	}
+	if len(dead) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no failed deliveries)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "FAILED", Width: 20},
+		style.Column{Name: "WEBHOOK", Width: 12},
+		style.Column{Name: "EVENT", Width: 18},
+		style.Column{Name: "TRIES", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "ERROR", Width: 40},
+	)
+	for _, dl := range dead {
+		table.AddRow(dl.FailedAt.Local().Format("2006-01-02 15:04:05"), dl.Webhook, dl.Event.Type,
+			fmt.Sprintf("%d", dl.Attempts), truncateString(dl.LastError, 40))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// formatWebhookEvents describes a subscription's event filter.
+func formatWebhookEvents(s *webhook.Subscription) string {
+	if len(s.Events) == 0 {
+		return "(all)"
+	}
+	return strings.Join(s.Events, ",")
+}
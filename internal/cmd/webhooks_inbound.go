@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/inbound"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Inbound webhook command flags
+var (
+	inboundShowJSON       bool
+	inboundReplayProvider string
+	inboundReplayEvent    string
+	inboundReplayDryRun   bool
+	inboundReplayJSON     bool
+)
+
+var webhooksInboundCmd = &cobra.Command{
+	Use:   "inbound",
+	Short: "Turn GitHub/GitLab repo activity into town work",
+	Long: `Show the inbound webhook receiver's configuration.
+
+gt dashboard serves GitHub and GitLab webhooks at:
+
+  POST /webhooks/github   (verified with X-Hub-Signature-256)
+  POST /webhooks/gitlab   (verified with X-Gitlab-Token)
+
+Deliveries are matched against the rules in settings/inbound.json (mode 0600):
+
+  {
+    "github_secret": "<webhook secret>",
+    "gitlab_token": "<secret token>",
+    "rules": [
+      {"on": "issue.labeled", "label": "gt", "action": "create_bead"},
+      {"on": "pull_request.opened", "action": "sling", "formula": "mol-polecat-review-pr"},
+      {"on": "ci.failed", "branch": "polecat/*", "action": "mail"}
+    ]
+  }
+
+"on" is a <kind>.<action> glob. Kinds: issue, pull_request, comment, ci.
+Actions: create_bead files a bead in the rig wrapping the repo; sling creates a
+tracking bead and slings a formula on it (PRs get var pr_url); mail notifies
+"to", or the polecat whose branch the event concerns. Rules may narrow matches
+with provider, repo, label and branch, and name a rig when the repo isn't a
+rig's git_url (or map it under "repos").
+
+Beads are labeled upstream:<repo>#<n>, so redeliveries don't duplicate work.
+The receiver answers 404 while settings/inbound.json doesn't exist.`,
+	Args: cobra.NoArgs,
+	RunE: runWebhooksInboundShow,
+}
+
+var webhooksInboundReplayCmd = &cobra.Command{
+	Use:   "replay <payload.json>",
+	Short: "Run a recorded webhook payload through the rules",
+	Long: `Run a recorded GitHub or GitLab webhook payload through the inbound rules.
+
+The payload is parsed as if delivered with the given event header
+(X-GitHub-Event / X-Gitlab-Event) and acted on; signatures are not checked.
+Use --dry-run to see which rules match without creating beads, slinging or
+sending mail.
+
+Examples:
+  gt webhooks inbound replay issue.json --event issues --dry-run
+  gt webhooks inbound replay pipeline.json --provider gitlab --event "Pipeline Hook"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksInboundReplay,
+}
+
+func init() {
+	webhooksInboundCmd.Flags().BoolVar(&inboundShowJSON, "json", false, "Output as JSON (secrets omitted)")
+
+	webhooksInboundReplayCmd.Flags().StringVar(&inboundReplayProvider, "provider", inbound.ProviderGitHub, "Payload provider: github or gitlab")
+	webhooksInboundReplayCmd.Flags().StringVar(&inboundReplayEvent, "event", "", "Provider event type (e.g. issues, pull_request, \"Merge Request Hook\")")
+	webhooksInboundReplayCmd.Flags().BoolVar(&inboundReplayDryRun, "dry-run", false, "Show matching rules without acting")
+	webhooksInboundReplayCmd.Flags().BoolVar(&inboundReplayJSON, "json", false, "Output as JSON")
+	_ = webhooksInboundReplayCmd.MarkFlagRequired("event")
+
+	webhooksInboundCmd.AddCommand(webhooksInboundReplayCmd)
+	webhooksCmd.AddCommand(webhooksInboundCmd)
+}
+
+func loadInboundConfig() (string, *inbound.Config, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := inbound.LoadConfig(townRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	return townRoot, cfg, nil
+}
+
+func runWebhooksInboundShow(cmd *cobra.Command, args []string) error {
+	_, cfg, err := loadInboundConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		if inboundShowJSON {
+			return outputJSON(map[string]interface{}{"enabled": false})
+		}
+		fmt.Printf("%s\n", style.Dim.Render("(inbound receiver disabled; create "+inbound.ConfigFile+" to enable it)"))
+		return nil
+	}
+
+	if inboundShowJSON {
+		// Never print secrets.
+		return outputJSON(map[string]interface{}{
+			"enabled": true,
+			"github":  cfg.GitHubSecret != "",
+			"gitlab":  cfg.GitLabToken != "",
+			"repos":   cfg.Repos,
+			"rules":   cfg.Rules,
+		})
+	}
+
+	enabled := func(ok bool) string {
+		if ok {
+			return style.Success.Render("enabled")
+		}
+		return style.Dim.Render("no secret configured")
+	}
+	fmt.Printf("%s /webhooks/github: %s\n", style.Bold.Render("GitHub"), enabled(cfg.GitHubSecret != ""))
+	fmt.Printf("%s /webhooks/gitlab: %s\n", style.Bold.Render("GitLab"), enabled(cfg.GitLabToken != ""))
+	for repo, rig := range cfg.Repos {
+		fmt.Printf("  %s → %s\n", repo, rig)
+	}
+	fmt.Println()
+
+	if len(cfg.Rules) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no rules)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "ON", Width: 22},
+		style.Column{Name: "MATCH", Width: 30},
+		style.Column{Name: "ACTION", Width: 12},
+		style.Column{Name: "DETAIL", Width: 30},
+	)
+	for _, r := range cfg.Rules {
+		table.AddRow(r.On, truncateString(formatInboundMatch(r), 30), r.Action, truncateString(formatInboundDetail(r), 30))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runWebhooksInboundReplay(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadInboundConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("inbound receiver disabled: %s not found", inbound.ConfigFile)
+	}
+	body, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading payload: %w", err)
+	}
+
+	header := http.Header{}
+	var e *inbound.RepoEvent
+	switch inboundReplayProvider {
+	case inbound.ProviderGitHub:
+		header.Set("X-GitHub-Event", inboundReplayEvent)
+		e, err = inbound.ParseGitHub(header, body)
+	case inbound.ProviderGitLab:
+		header.Set("X-Gitlab-Event", inboundReplayEvent)
+		e, err = inbound.ParseGitLab(header, body)
+	default:
+		return fmt.Errorf("unknown provider %q (want github or gitlab)", inboundReplayProvider)
+	}
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if inboundReplayJSON {
+			return outputJSON(map[string]interface{}{"status": "ignored"})
+		}
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("(%s %q events are not handled)", inboundReplayProvider, inboundReplayEvent)))
+		return nil
+	}
+
+	proc := inbound.NewProcessor(townRoot, cfg, inbound.NewTown(townRoot))
+	proc.DryRun = inboundReplayDryRun
+	results := proc.Process(e)
+
+	if inboundReplayJSON {
+		return outputJSON(map[string]interface{}{"event": e, "results": results})
+	}
+	fmt.Printf("%s %s.%s by %s\n", style.Bold.Render(e.Ref()), e.Kind, e.Action, e.Author)
+	if len(results) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("no rules matched"))
+		return nil
+	}
+	for _, res := range results {
+		switch {
+		case res.Error != "":
+			fmt.Printf("  %s %s: %s\n", style.Error.Render("✗"), res.Rule, res.Error)
+		case res.Skipped != "":
+			fmt.Printf("  %s %s: %s\n", style.Dim.Render("-"), res.Rule, res.Skipped)
+		default:
+			detail := res.Bead
+			if res.To != "" {
+				detail = fmt.Sprintf("%s → %s", detail, res.To)
+			}
+			fmt.Printf("  %s %s: %s %s\n", style.Success.Render("✓"), res.Rule, res.Action, detail)
+		}
+	}
+	return nil
+}
+
+func formatInboundMatch(r *inbound.Rule) string {
+	var parts []string
+	for _, p := range []struct{ k, v string }{
+		{"provider", r.Provider}, {"repo", r.Repo}, {"label", r.Label}, {"branch", r.Branch},
+	} {
+		if p.v != "" {
+			parts = append(parts, p.k+"="+p.v)
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatInboundDetail(r *inbound.Rule) string {
+	detail := r.Rig
+	switch r.Action {
+	case inbound.ActionSling:
+		formula := r.Formula
+		if formula == "" {
+			formula = inbound.DefaultReviewFormula
+		}
+		detail = formula + " " + detail
+	case inbound.ActionMail:
+		if r.To != "" {
+			detail = r.To
+		} else {
+			detail = "branch owner"
+		}
+	}
+	return detail
+}
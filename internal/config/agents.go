@@ -122,6 +122,9 @@ type AgentPresetInfo struct {
 	// EmitsPermissionWarning indicates the agent shows a bypass-permissions warning on startup
 	// that needs to be acknowledged via tmux.
 	EmitsPermissionWarning bool `json:"emits_permission_warning,omitempty"`
+
+	// Capabilities declares what this agent is good at, for gt sling --auto.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
 }
 
 // NonInteractiveConfig contains settings for running agents non-interactively.
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cost tiers for agents and accounts, cheapest first.
+const (
+	CostTierLow    = "low"
+	CostTierMedium = "medium"
+	CostTierHigh   = "high"
+)
+
+// Capabilities describes what an agent, account, rig or polecat identity can
+// do. Capability-aware routing (gt sling --auto) matches these against the
+// requirements a bead carries in its labels.
+type Capabilities struct {
+	// Languages are programming languages (e.g., "go", "typescript").
+	Languages []string `json:"languages,omitempty"`
+
+	// Tools are tools or environments available (e.g., "docker", "terraform").
+	Tools []string `json:"tools,omitempty"`
+
+	// Tags are free-form capabilities (e.g., "frontend", "security-review").
+	Tags []string `json:"tags,omitempty"`
+
+	// CostTier is "low", "medium" or "high". Empty means unknown.
+	CostTier string `json:"cost_tier,omitempty"`
+
+	// ContextTokens is the model's context window. Zero means unknown.
+	ContextTokens int `json:"context_tokens,omitempty"`
+}
+
+// Has reports whether c lists name as a language, tool or tag.
+// Comparison is case-insensitive. A nil receiver has nothing.
+func (c *Capabilities) Has(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, list := range [][]string{c.Languages, c.Tools, c.Tags} {
+		for _, v := range list {
+			if strings.EqualFold(v, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CostTierRank orders cost tiers: 1 (low) to 3 (high), 0 for unknown.
+func CostTierRank(tier string) int {
+	switch strings.ToLower(tier) {
+	case CostTierLow:
+		return 1
+	case CostTierMedium:
+		return 2
+	case CostTierHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ParseContextTokens parses a context size such as "200000", "200k" or "1m".
+func ParseContextTokens(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	mult := 1
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1000, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		mult, s = 1000000, strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid context size %q", s)
+	}
+	return n * mult, nil
+}
+
+// validateCapabilities checks the cost tier and context size.
+func validateCapabilities(field string, c *Capabilities) error {
+	if c == nil {
+		return nil
+	}
+	if c.CostTier != "" && CostTierRank(c.CostTier) == 0 {
+		return fmt.Errorf("%s.cost_tier must be low, medium or high, got %q", field, c.CostTier)
+	}
+	if c.ContextTokens < 0 {
+		return fmt.Errorf("%s.context_tokens must not be negative", field)
+	}
+	return nil
+}
+
+// ResolveAgentCapabilities returns the capabilities declared for an agent
+// name, looked up like the agent itself: rig custom agents, town custom
+// agents, then presets. A custom agent without its own capabilities inherits
+// those of the preset it is based on (its provider).
+func ResolveAgentCapabilities(name string, townSettings *TownSettings, rigSettings *RigSettings) *Capabilities {
+	var custom *RuntimeConfig
+	if rigSettings != nil && rigSettings.Agents[name] != nil {
+		custom = rigSettings.Agents[name]
+	} else if townSettings != nil && townSettings.Agents[name] != nil {
+		custom = townSettings.Agents[name]
+	}
+	if custom != nil {
+		if custom.Capabilities != nil {
+			return custom.Capabilities
+		}
+		if custom.Provider != "" {
+			name = custom.Provider
+		}
+	}
+	if info := GetAgentPresetByName(name); info != nil {
+		return info.Capabilities
+	}
+	return nil
+}
@@ -11,7 +11,8 @@ import (
 // AgentEnvConfig specifies the configuration for generating agent environment variables.
 // This is the single source of truth for all agent environment configuration.
 type AgentEnvConfig struct {
-	// Role is the agent role: mayor, deacon, witness, refinery, crew, polecat, boot
+	// Role is the agent role: mayor, deacon, witness, refinery, crew, polecat, boot,
+	// or the name of a custom role
 	Role string
 
 	// Rig is the rig name (empty for town-level agents like mayor/deacon)
@@ -87,6 +88,20 @@ func AgentEnv(cfg AgentEnvConfig) map[string]string {
 		env["GT_CREW"] = cfg.AgentName
 		env["BD_ACTOR"] = fmt.Sprintf("%s/crew/%s", cfg.Rig, cfg.AgentName)
 		env["GIT_AUTHOR_NAME"] = cfg.AgentName
+
+	case "", "dog":
+		// Dogs get their identity from their kennel, not the environment.
+
+	default:
+		// Custom roles: "<rig>/<role>" when rig-scoped, "<role>" in town.
+		identity := cfg.Role
+		if cfg.Rig != "" {
+			identity = fmt.Sprintf("%s/%s", cfg.Rig, cfg.Role)
+			env["GT_RIG"] = cfg.Rig
+		}
+		env["GT_ROLE"] = identity
+		env["BD_ACTOR"] = identity
+		env["GIT_AUTHOR_NAME"] = identity
 	}
 
 	// Only set GT_ROOT if provided
@@ -35,6 +35,22 @@ func TestAgentEnv_Witness(t *testing.T) {
 	assertEnv(t, env, "GT_ROOT", "/town")
 }
 
+func TestAgentEnv_CustomRole(t *testing.T) {
+	t.Parallel()
+	env := AgentEnv(AgentEnvConfig{
+		Role:     "reviewer",
+		Rig:      "myrig",
+		TownRoot: "/town",
+	})
+	assertEnv(t, env, "GT_ROLE", "myrig/reviewer")
+	assertEnv(t, env, "GT_RIG", "myrig")
+	assertEnv(t, env, "BD_ACTOR", "myrig/reviewer")
+
+	env = AgentEnv(AgentEnvConfig{Role: "auditor", TownRoot: "/town"})
+	assertEnv(t, env, "GT_ROLE", "auditor")
+	assertNotSet(t, env, "GT_RIG")
+}
+
 func TestAgentEnv_Polecat(t *testing.T) {
 	t.Parallel()
 	env := AgentEnv(AgentEnvConfig{
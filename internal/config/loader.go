@@ -219,6 +219,98 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Sandbox != nil {
+		if err := validateSandboxConfig(c.Sandbox); err != nil {
+			return err
+		}
+	}
+	if c.Execution != nil {
+		if err := validateExecutionConfig(c.Execution); err != nil {
+			return err
+		}
+	}
+	if err := validateCapabilities("capabilities", c.Capabilities); err != nil {
+		return err
+	}
+	if c.WarmPool != nil {
+		if c.WarmPool.Size < 0 {
+			return fmt.Errorf("warm_pool.size must not be negative")
+		}
+		if c.WarmPool.MaxAge != "" {
+			if d, err := time.ParseDuration(c.WarmPool.MaxAge); err != nil || d <= 0 {
+				return fmt.Errorf("invalid warm_pool.max_age: %q", c.WarmPool.MaxAge)
+			}
+		}
+	}
+	if c.WIPSnapshots != nil {
+		if c.WIPSnapshots.Keep < 0 {
+			return fmt.Errorf("wip_snapshots.keep must not be negative")
+		}
+		for field, v := range map[string]string{"interval": c.WIPSnapshots.Interval, "max_age": c.WIPSnapshots.MaxAge} {
+			if v == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				return fmt.Errorf("invalid wip_snapshots.%s: %q", field, v)
+			}
+		}
+	}
+	if c.ConflictPrediction != nil && c.ConflictPrediction.Interval != "" {
+		if d, err := time.ParseDuration(c.ConflictPrediction.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid conflict_prediction.interval: %q", c.ConflictPrediction.Interval)
+		}
+	}
+	return nil
+}
+
+// validateExecutionConfig validates the polecat execution mode settings.
+func validateExecutionConfig(c *ExecutionConfig) error {
+	switch c.Mode {
+	case "", ExecutionModeHost:
+		return nil
+	case ExecutionModeContainer:
+	default:
+		return fmt.Errorf("execution.mode must be 'host' or 'container', got '%s'", c.Mode)
+	}
+	cc := c.Container
+	if cc == nil || (cc.Image == "" && cc.Dockerfile == "") {
+		return fmt.Errorf("%w: execution.container.image or execution.container.dockerfile", ErrMissingField)
+	}
+	switch cc.Driver {
+	case "", "docker", "podman":
+	default:
+		return fmt.Errorf("execution.container.driver must be 'docker' or 'podman', got '%s'", cc.Driver)
+	}
+	for _, m := range cc.Mounts {
+		if parts := strings.Split(m, ":"); len(parts) < 2 || !filepath.IsAbs(parts[0]) {
+			return fmt.Errorf("execution.container.mounts: %q must be host:container[:ro] with an absolute host path", m)
+		}
+	}
+	return nil
+}
+
+// validateSandboxConfig validates every profile in a SandboxConfig.
+func validateSandboxConfig(c *SandboxConfig) error {
+	profiles := map[string]*SandboxProfile{"default": c.Default}
+	for role, p := range c.Roles {
+		profiles[role] = p
+	}
+	for name, p := range profiles {
+		if p == nil {
+			continue
+		}
+		if _, err := p.MemoryBytes(); err != nil {
+			return fmt.Errorf("sandbox profile %s: %w", name, err)
+		}
+		if p.CPUs < 0 || p.MaxPids < 0 {
+			return fmt.Errorf("sandbox profile %s: limits must not be negative", name)
+		}
+		for _, wp := range p.WritablePaths {
+			if !filepath.IsAbs(wp) {
+				return fmt.Errorf("sandbox profile %s: writable path %q must be absolute", name, wp)
+			}
+		}
+	}
 	return nil
 }
 
@@ -259,6 +351,85 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 		return fmt.Errorf("%w: max_concurrent must be non-negative", ErrMissingField)
 	}
 
+	if err := ValidateVerifyStages(c.VerifyStages); err != nil {
+		return err
+	}
+	if err := ValidateReviewPolicy(c.Review); err != nil {
+		return err
+	}
+	if err := ValidateLanding(c.LandVia, c.PR); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateLanding checks the landing mode and pull-request policy.
+func ValidateLanding(landVia string, pr *PRLandingPolicy) error {
+	switch landVia {
+	case "", LandViaPush, LandViaPR:
+	default:
+		return fmt.Errorf("invalid land_via %q: want %q or %q", landVia, LandViaPush, LandViaPR)
+	}
+	if pr == nil {
+		return nil
+	}
+	switch pr.MergeMethod {
+	case "", PRMergeSquash, PRMergeMerge, PRMergeRebase:
+	default:
+		return fmt.Errorf("invalid pr.merge_method %q: want squash, merge or rebase", pr.MergeMethod)
+	}
+	if pr.RequiredApprovals < 0 {
+		return fmt.Errorf("pr.required_approvals must be non-negative, got %d", pr.RequiredApprovals)
+	}
+	return nil
+}
+
+// ValidateReviewPolicy checks a review policy's timeout. Nil is valid.
+func ValidateReviewPolicy(p *ReviewPolicy) error {
+	if p == nil || p.Timeout == "" {
+		return nil
+	}
+	dur, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid review.timeout: %w", err)
+	}
+	if dur <= 0 {
+		return fmt.Errorf("review.timeout must be positive, got %v", dur)
+	}
+	return nil
+}
+
+// ValidateVerifyStages checks a verification pipeline: every stage needs a
+// unique name and a command, and timeouts must be positive durations.
+func ValidateVerifyStages(stages []VerifyStage) error {
+	seen := make(map[string]bool, len(stages))
+	for i, st := range stages {
+		if st.Name == "" {
+			return fmt.Errorf("%w: verify_stages[%d].name", ErrMissingField, i)
+		}
+		if strings.TrimSpace(st.Command) == "" {
+			return fmt.Errorf("%w: verify_stages[%d].command", ErrMissingField, i)
+		}
+		if seen[st.Name] {
+			return fmt.Errorf("duplicate verify stage %q", st.Name)
+		}
+		seen[st.Name] = true
+		switch st.Results {
+		case "", "go-json", "junit", "tap", "none":
+		default:
+			return fmt.Errorf("verify_stages[%d].results: unknown format %q (want go-json, junit, tap or none)", i, st.Results)
+		}
+		if st.Timeout != "" {
+			dur, err := time.ParseDuration(st.Timeout)
+			if err != nil {
+				return fmt.Errorf("invalid verify_stages[%d].timeout: %w", i, err)
+			}
+			if dur <= 0 {
+				return fmt.Errorf("verify_stages[%d].timeout must be positive, got %v", i, dur)
+			}
+		}
+	}
 	return nil
 }
 
@@ -646,6 +817,9 @@ func validateAccountsConfig(c *AccountsConfig) error {
 		if acct.ConfigDir == "" {
 			return fmt.Errorf("%w: config_dir for account '%s'", ErrMissingField, handle)
 		}
+		if err := validateCapabilities("accounts."+handle+".capabilities", acct.Capabilities); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -372,6 +372,221 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid sandbox",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{
+					Enabled: true,
+					Default: &SandboxProfile{CPUs: 2, Memory: "4G", MaxPids: 1024},
+					Roles: map[string]*SandboxProfile{
+						"polecat": {ReadOnlyFS: true, WritablePaths: []string{"/var/cache/npm"}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sandbox memory",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{
+					Enabled: true,
+					Default: &SandboxProfile{Memory: "lots"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative sandbox writable path",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{
+					Enabled: true,
+					Roles: map[string]*SandboxProfile{
+						"polecat": {ReadOnlyFS: true, WritablePaths: []string{"cache"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "container execution with image",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Execution: &ExecutionConfig{
+					Mode:      ExecutionModeContainer,
+					Container: &ContainerConfig{Driver: "podman", Image: "alpine:3", Mounts: []string{"/cache:/cache:ro"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "container execution without image spec",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Execution: &ExecutionConfig{Mode: ExecutionModeContainer, Container: &ContainerConfig{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown execution mode",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Execution: &ExecutionConfig{Mode: "vm"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown container driver",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Execution: &ExecutionConfig{
+					Mode:      ExecutionModeContainer,
+					Container: &ContainerConfig{Driver: "lxc", Image: "alpine:3"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "verify stages",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				MergeQueue: &MergeQueueConfig{VerifyStages: []VerifyStage{
+					{Name: "lint", Command: "golangci-lint run", Parallel: true},
+					{Name: "test", Command: "go test ./...", Timeout: "20m"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate verify stage",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				MergeQueue: &MergeQueueConfig{VerifyStages: []VerifyStage{
+					{Name: "test", Command: "go test ./..."},
+					{Name: "test", Command: "make test"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "verify stage without command",
+			settings: &RigSettings{
+				Type:       "rig-settings",
+				Version:    1,
+				MergeQueue: &MergeQueueConfig{VerifyStages: []VerifyStage{{Name: "lint"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid verify stage timeout",
+			settings: &RigSettings{
+				Type:       "rig-settings",
+				Version:    1,
+				MergeQueue: &MergeQueueConfig{VerifyStages: []VerifyStage{{Name: "test", Command: "make", Timeout: "soon"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid verify stage results format",
+			settings: &RigSettings{
+				Type:       "rig-settings",
+				Version:    1,
+				MergeQueue: &MergeQueueConfig{VerifyStages: []VerifyStage{{Name: "test", Command: "make", Results: "xml"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "review policy",
+			settings: &RigSettings{
+				Type:       "rig-settings",
+				Version:    1,
+				MergeQueue: &MergeQueueConfig{Review: &ReviewPolicy{Enabled: true, Reviewer: "gastown/reviewer", Timeout: "2h"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid review timeout",
+			settings: &RigSettings{
+				Type:       "rig-settings",
+				Version:    1,
+				MergeQueue: &MergeQueueConfig{Review: &ReviewPolicy{Enabled: true, Timeout: "-1h"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "warm pool",
+			settings: &RigSettings{
+				Type:     "rig-settings",
+				Version:  1,
+				WarmPool: &WarmPoolConfig{Size: 2, MaxAge: "12h"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid capability cost tier",
+			settings: &RigSettings{
+				Type:         "rig-settings",
+				Version:      1,
+				Capabilities: &Capabilities{CostTier: "cheap"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative warm pool size",
+			settings: &RigSettings{
+				Type:     "rig-settings",
+				Version:  1,
+				WarmPool: &WarmPoolConfig{Size: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid warm pool max age",
+			settings: &RigSettings{
+				Type:     "rig-settings",
+				Version:  1,
+				WarmPool: &WarmPoolConfig{Size: 1, MaxAge: "soon"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "wip snapshots",
+			settings: &RigSettings{
+				Type:         "rig-settings",
+				Version:      1,
+				WIPSnapshots: &WIPSnapshotConfig{Interval: "5m", Keep: 3, MaxAge: "72h"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid wip snapshot interval",
+			settings: &RigSettings{
+				Type:         "rig-settings",
+				Version:      1,
+				WIPSnapshots: &WIPSnapshotConfig{Interval: "often"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid conflict prediction interval",
+			settings: &RigSettings{
+				Type:               "rig-settings",
+				Version:            1,
+				ConflictPrediction: &ConflictPredictionConfig{Interval: "-5m"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
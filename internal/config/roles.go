@@ -38,12 +38,27 @@ type RoleDefinition struct {
 
 	// PromptTemplate is the name of the role's prompt template file.
 	PromptTemplate string `toml:"prompt_template,omitempty"`
+
+	// Description is a one-line summary of what the role does.
+	Description string `toml:"description,omitempty"`
+
+	// PatrolFormula is the formula whose wisp drives the agent's patrol loop
+	// (e.g., "mol-reviewer-patrol"). Empty means the role has no patrol.
+	PatrolFormula string `toml:"patrol_formula,omitempty"`
+
+	// KeepAlive makes the daemon restart the agent's session when it dies,
+	// as it does for witnesses and refineries.
+	KeepAlive bool `toml:"keep_alive,omitempty"`
+
+	// Custom is set for user-defined roles loaded from <town>/roles/.
+	Custom bool `toml:"-"`
 }
 
 // RoleSessionConfig contains session-related configuration.
 type RoleSessionConfig struct {
 	// Pattern is the tmux session name pattern.
-	// Supports placeholders: {rig}, {name}, {role}
+	// Supports placeholders: {rig}, {name}, {role}, and {prefix} (the rig's
+	// beads prefix) for custom roles
 	// Examples: "hq-mayor", "gt-{rig}-witness", "gt-{rig}-{name}"
 	Pattern string `toml:"pattern"`
 
@@ -133,10 +148,23 @@ func isValidRoleName(name string) bool {
 //
 // Each layer merges with (not replaces) the previous. Users only specify
 // fields they want to change.
+//
+// A name that is not a built-in role loads the custom role defined by
+// <town>/roles/<role>.toml, with rig-level overrides applied on top.
 func LoadRoleDefinition(townRoot, rigPath, roleName string) (*RoleDefinition, error) {
 	// Validate role name
 	if !isValidRoleName(roleName) {
-		return nil, fmt.Errorf("unknown role %q - valid roles: %v", roleName, AllRoles())
+		if !IsCustomRole(townRoot, roleName) {
+			return nil, fmt.Errorf("unknown role %q - valid roles: %v", roleName, append(AllRoles(), CustomRoleNames(townRoot)...))
+		}
+		def, err := loadCustomRoleDefinition(townRoot, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyRigRoleOverride(def, rigPath, roleName); err != nil {
+			return nil, err
+		}
+		return def, nil
 	}
 
 	// 1. Load built-in defaults
@@ -156,20 +184,29 @@ func LoadRoleDefinition(townRoot, rigPath, roleName string) (*RoleDefinition, er
 	}
 
 	// 3. Apply rig-level overrides if present (only for rig-scoped roles)
-	if rigPath != "" {
-		rigOverridePath := filepath.Join(rigPath, "roles", roleName+".toml")
-		if override, err := loadRoleOverride(rigOverridePath); err != nil {
-			if !os.IsNotExist(err) {
-				return nil, fmt.Errorf("rig-level role override %s: %w", rigOverridePath, err)
-			}
-		} else {
-			mergeRoleDefinition(def, override)
-		}
+	if err := applyRigRoleOverride(def, rigPath, roleName); err != nil {
+		return nil, err
 	}
 
 	return def, nil
 }
 
+// applyRigRoleOverride merges <rig>/roles/<role>.toml into def, if present.
+func applyRigRoleOverride(def *RoleDefinition, rigPath, roleName string) error {
+	if rigPath == "" {
+		return nil
+	}
+	rigOverridePath := filepath.Join(rigPath, "roles", roleName+".toml")
+	if override, err := loadRoleOverride(rigOverridePath); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("rig-level role override %s: %w", rigOverridePath, err)
+		}
+	} else {
+		mergeRoleDefinition(def, override)
+	}
+	return nil
+}
+
 // loadBuiltinRoleDefinition loads a role definition from embedded defaults.
 func loadBuiltinRoleDefinition(roleName string) (*RoleDefinition, error) {
 	data, err := defaultRolesFS.ReadFile("roles/" + roleName + ".toml")
@@ -262,6 +299,16 @@ func mergeRoleDefinition(base, override *RoleDefinition) {
 	if override.PromptTemplate != "" {
 		base.PromptTemplate = override.PromptTemplate
 	}
+
+	if override.Description != "" {
+		base.Description = override.Description
+	}
+	if override.PatrolFormula != "" {
+		base.PatrolFormula = override.PatrolFormula
+	}
+	if override.KeepAlive {
+		base.KeepAlive = true
+	}
 }
 
 // ExpandPattern expands placeholders in a pattern string.
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// customRoleName is the shape of a custom role name. Hyphens are excluded so
+// that role names stay unambiguous inside session names and agent bead IDs.
+var customRoleName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// reservedRoleNames cannot be used for custom roles even though they are not
+// role definitions: they already mean something in addresses and sessions.
+var reservedRoleNames = []string{"boot", "overseer", "polecats", "dogs", "rig", "hq", "town"}
+
+// Default health thresholds for custom roles, matching the witness.
+var defaultCustomRoleHealth = RoleHealthConfig{
+	PingTimeout:         Duration{30 * time.Second},
+	ConsecutiveFailures: 3,
+	KillCooldown:        Duration{5 * time.Minute},
+	StuckThreshold:      Duration{time.Hour},
+}
+
+// IsBuiltinRole reports whether name is one of the built-in roles.
+func IsBuiltinRole(name string) bool {
+	return isValidRoleName(name)
+}
+
+// IsCustomRole reports whether name is a user-defined role, i.e. not a
+// built-in role and defined by <town>/roles/<name>.toml.
+func IsCustomRole(townRoot, name string) bool {
+	if townRoot == "" || IsBuiltinRole(name) || validateCustomRoleName(name) != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(townRoot, "roles", name+".toml"))
+	return err == nil && !info.IsDir()
+}
+
+// CustomRoleNames returns the names of the town's custom roles, sorted.
+// Files in <town>/roles/ named after built-in roles are overrides, not
+// custom roles, and are skipped.
+func CustomRoleNames(townRoot string) []string {
+	if townRoot == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(townRoot, "roles"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".toml")
+		if !ok || e.IsDir() || IsBuiltinRole(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadCustomRoles loads every custom role defined in the town. Roles that
+// fail to load are reported in the returned error map, keyed by role name.
+func LoadCustomRoles(townRoot string) ([]*RoleDefinition, map[string]error) {
+	var defs []*RoleDefinition
+	var errs map[string]error
+	for _, name := range CustomRoleNames(townRoot) {
+		def, err := loadCustomRoleDefinition(townRoot, name)
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[name] = err
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs, errs
+}
+
+// loadCustomRoleDefinition loads and validates <town>/roles/<name>.toml as a
+// custom role, filling in defaults for everything it leaves out.
+func loadCustomRoleDefinition(townRoot, name string) (*RoleDefinition, error) {
+	path := filepath.Join(townRoot, "roles", name+".toml")
+	if err := validateCustomRoleName(name); err != nil {
+		return nil, fmt.Errorf("custom role %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is within the town's roles directory
+	if err != nil {
+		return nil, fmt.Errorf("reading custom role %s: %w", path, err)
+	}
+	var def RoleDefinition
+	if err := toml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("parsing custom role %s: %w", path, err)
+	}
+
+	if def.Role == "" {
+		def.Role = name
+	}
+	if def.Role != name {
+		return nil, fmt.Errorf("custom role %s: role %q does not match file name", path, def.Role)
+	}
+	switch def.Scope {
+	case "rig", "town":
+	case "":
+		return nil, fmt.Errorf("custom role %s: scope is required (town or rig)", path)
+	default:
+		return nil, fmt.Errorf("custom role %s: invalid scope %q (want town or rig)", path, def.Scope)
+	}
+	def.Custom = true
+
+	if def.Session.Pattern == "" {
+		if def.Scope == "rig" {
+			def.Session.Pattern = "{prefix}-{role}"
+		} else {
+			def.Session.Pattern = "hq-{role}"
+		}
+	}
+	if def.Session.WorkDir == "" {
+		if def.Scope == "rig" {
+			def.Session.WorkDir = "{town}/{rig}/{role}"
+		} else {
+			def.Session.WorkDir = "{town}/{role}"
+		}
+	}
+	// GT_ROLE, GT_RIG and BD_ACTOR come from AgentEnv, like other roles.
+	if def.Env == nil {
+		def.Env = make(map[string]string)
+	}
+	if _, ok := def.Env["GT_SCOPE"]; !ok {
+		def.Env["GT_SCOPE"] = def.Scope
+	}
+	mergeRoleDefinition(&def, &RoleDefinition{Health: defaultHealthFor(def.Health)})
+	return &def, nil
+}
+
+// defaultHealthFor returns the default thresholds for those left unset in h.
+func defaultHealthFor(h RoleHealthConfig) RoleHealthConfig {
+	var out RoleHealthConfig
+	if h.PingTimeout.Duration == 0 {
+		out.PingTimeout = defaultCustomRoleHealth.PingTimeout
+	}
+	if h.ConsecutiveFailures == 0 {
+		out.ConsecutiveFailures = defaultCustomRoleHealth.ConsecutiveFailures
+	}
+	if h.KillCooldown.Duration == 0 {
+		out.KillCooldown = defaultCustomRoleHealth.KillCooldown
+	}
+	if h.StuckThreshold.Duration == 0 {
+		out.StuckThreshold = defaultCustomRoleHealth.StuckThreshold
+	}
+	return out
+}
+
+func validateCustomRoleName(name string) error {
+	if !customRoleName.MatchString(name) {
+		return fmt.Errorf("invalid role name %q (use lowercase letters, digits and underscores)", name)
+	}
+	for _, r := range reservedRoleNames {
+		if name == r {
+			return fmt.Errorf("role name %q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// IsRigScoped reports whether the role runs once per rig.
+func (rd *RoleDefinition) IsRigScoped() bool {
+	return rd.Scope == "rig"
+}
+
+// SessionName returns the role's tmux session name in the given rig (empty
+// for town-scoped roles). prefix is the rig's beads prefix.
+func (rd *RoleDefinition) SessionName(rig, prefix string) string {
+	return strings.ReplaceAll(ExpandPattern(rd.Session.Pattern, "", rig, "", rd.Role), "{prefix}", prefix)
+}
+
+// WorkDir returns the role's working directory in the given rig (empty for
+// town-scoped roles).
+func (rd *RoleDefinition) WorkDir(townRoot, rig string) string {
+	return filepath.Clean(ExpandPattern(rd.Session.WorkDir, townRoot, rig, "", rd.Role))
+}
+
+// MailAddress returns the mail address of the role's agent: "<rig>/<role>"
+// for rig-scoped roles and "<role>/" for town-scoped ones.
+func (rd *RoleDefinition) MailAddress(rig string) string {
+	if rd.IsRigScoped() {
+		return rig + "/" + rd.Role
+	}
+	return rd.Role + "/"
+}
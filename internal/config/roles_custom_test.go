@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeRoleFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	rolesDir := filepath.Join(dir, "roles")
+	if err := os.MkdirAll(rolesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rolesDir, name+".toml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRoleDefinition_CustomRole(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRoleFile(t, townRoot, "reviewer", `
+scope = "rig"
+description = "Reviews open MRs"
+patrol_formula = "mol-reviewer-patrol"
+keep_alive = true
+
+[health]
+kill_cooldown = "10m"
+`)
+	// A file named after a built-in role is an override, not a custom role.
+	writeRoleFile(t, townRoot, "mayor", `nudge = "hi"`)
+
+	def, err := LoadRoleDefinition(townRoot, "", "reviewer")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition: %v", err)
+	}
+	if !def.Custom || def.Role != "reviewer" || !def.KeepAlive || def.PatrolFormula != "mol-reviewer-patrol" {
+		t.Errorf("def = %+v", def)
+	}
+	if got := def.SessionName("greenplace", "gp"); got != "gp-reviewer" {
+		t.Errorf("SessionName = %q, want gp-reviewer", got)
+	}
+	if got := def.WorkDir("/town", "greenplace"); got != "/town/greenplace/reviewer" {
+		t.Errorf("WorkDir = %q", got)
+	}
+	if got := def.MailAddress("greenplace"); got != "greenplace/reviewer" {
+		t.Errorf("MailAddress = %q", got)
+	}
+	if def.Health.KillCooldown.Duration != 10*time.Minute || def.Health.ConsecutiveFailures != 3 {
+		t.Errorf("Health = %+v, want the override merged over defaults", def.Health)
+	}
+	if def.Env["GT_SCOPE"] != "rig" {
+		t.Errorf("Env = %v", def.Env)
+	}
+
+	if got := CustomRoleNames(townRoot); len(got) != 1 || got[0] != "reviewer" {
+		t.Errorf("CustomRoleNames = %v, want [reviewer]", got)
+	}
+	if !IsCustomRole(townRoot, "reviewer") || IsCustomRole(townRoot, "mayor") || IsCustomRole(townRoot, "nope") {
+		t.Error("IsCustomRole misclassified a role")
+	}
+
+	// Rig-level overrides apply to custom roles too.
+	rigPath := t.TempDir()
+	writeRoleFile(t, rigPath, "reviewer", `nudge = "rig nudge"`)
+	def, err = LoadRoleDefinition(townRoot, rigPath, "reviewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Nudge != "rig nudge" || def.Scope != "rig" {
+		t.Errorf("rig override not applied: %+v", def)
+	}
+}
+
+func TestLoadRoleDefinition_CustomTownRole(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRoleFile(t, townRoot, "auditor", `scope = "town"`)
+
+	def, err := LoadRoleDefinition(townRoot, "", "auditor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := def.SessionName("", ""); got != "hq-auditor" {
+		t.Errorf("SessionName = %q, want hq-auditor", got)
+	}
+	if got := def.MailAddress(""); got != "auditor/" {
+		t.Errorf("MailAddress = %q, want auditor/", got)
+	}
+	if got := def.WorkDir("/town", ""); got != "/town/auditor" {
+		t.Errorf("WorkDir = %q", got)
+	}
+}
+
+func TestLoadCustomRoles_Invalid(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRoleFile(t, townRoot, "noscope", `description = "x"`)
+	writeRoleFile(t, townRoot, "badscope", `scope = "galaxy"`)
+	writeRoleFile(t, townRoot, "mismatch", "role = \"other\"\nscope = \"rig\"")
+	writeRoleFile(t, townRoot, "overseer", `scope = "town"`)
+	writeRoleFile(t, townRoot, "good", `scope = "rig"`)
+
+	defs, errs := LoadCustomRoles(townRoot)
+	if len(defs) != 1 || defs[0].Role != "good" {
+		t.Errorf("defs = %+v, want only good", defs)
+	}
+	want := map[string]string{
+		"noscope":  "scope is required",
+		"badscope": "invalid scope",
+		"mismatch": "does not match",
+		"overseer": "reserved",
+	}
+	for name, msg := range want {
+		if err := errs[name]; err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("errs[%s] = %v, want %q", name, err, msg)
+		}
+	}
+}
@@ -345,6 +345,290 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// Sandbox configures optional Linux resource sandboxing (cgroup v2 limits,
+	// read-only filesystem, network denial) for agent sessions in this rig.
+	Sandbox *SandboxConfig `json:"sandbox,omitempty"`
+
+	// Execution selects where polecat sessions run: on the host (default)
+	// or inside a container built from a rig-provided image spec.
+	Execution *ExecutionConfig `json:"execution,omitempty"`
+
+	// WarmPool keeps pre-created worktrees ready so new polecats skip
+	// worktree creation and setup on spawn.
+	WarmPool *WarmPoolConfig `json:"warm_pool,omitempty"`
+
+	// WIPSnapshots configures the daemon's periodic snapshots of dirty
+	// polecat worktrees to hidden refs. On by default.
+	WIPSnapshots *WIPSnapshotConfig `json:"wip_snapshots,omitempty"`
+
+	// ConflictPrediction configures the daemon's periodic check for
+	// in-flight branches that change the same lines. On by default.
+	ConflictPrediction *ConflictPredictionConfig `json:"conflict_prediction,omitempty"`
+
+	// Capabilities describes the rig's codebase and environment (languages,
+	// installed tools), for gt sling --auto.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+}
+
+// WarmPoolConfig configures a rig's warm pool of pre-created, pre-synced
+// worktrees. The daemon keeps the pool topped up and re-syncs entries when
+// the default branch advances; gt sling claims an entry instead of creating
+// a worktree from scratch.
+type WarmPoolConfig struct {
+	// Size is the number of ready worktrees to keep. Zero disables the pool.
+	Size int `json:"size"`
+
+	// RunSetupCommand runs merge_queue.setup_command (e.g., "pnpm install")
+	// in each warm worktree after creation and after every re-sync.
+	RunSetupCommand bool `json:"run_setup_command,omitempty"`
+
+	// MaxAge recreates entries older than this duration (default "24h").
+	MaxAge string `json:"max_age,omitempty"`
+}
+
+// DefaultWarmPoolMaxAge is the default maximum age of a warm pool entry.
+const DefaultWarmPoolMaxAge = 24 * time.Hour
+
+// MaxAgeDuration returns MaxAge parsed, or DefaultWarmPoolMaxAge.
+func (c *WarmPoolConfig) MaxAgeDuration() time.Duration {
+	return ParseDurationOrDefault(c.MaxAge, DefaultWarmPoolMaxAge)
+}
+
+// WIPSnapshotConfig configures WIP snapshots: the daemon commits each
+// polecat's uncommitted changes and unpushed commits to a hidden ref
+// (refs/gt/wip/<polecat>/<timestamp>) in the rig's shared repository, so
+// work survives a crash or nuke. A nil config uses the defaults.
+type WIPSnapshotConfig struct {
+	// Disabled turns periodic snapshots off. Removing a polecat still
+	// snapshots its unsaved work first.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Interval is the minimum time between snapshots of a polecat
+	// (default "10m"). Unchanged worktrees are never re-snapshotted.
+	Interval string `json:"interval,omitempty"`
+
+	// Keep is how many snapshots to keep per polecat (default 10).
+	Keep int `json:"keep,omitempty"`
+
+	// MaxAge drops snapshots older than this duration (default "168h").
+	// A polecat's newest snapshot is always kept.
+	MaxAge string `json:"max_age,omitempty"`
+}
+
+// Defaults for WIP snapshots.
+const (
+	DefaultWIPSnapshotInterval = 10 * time.Minute
+	DefaultWIPSnapshotKeep     = 10
+	DefaultWIPSnapshotMaxAge   = 7 * 24 * time.Hour
+)
+
+// Enabled reports whether periodic snapshots run.
+func (c *WIPSnapshotConfig) Enabled() bool {
+	return c == nil || !c.Disabled
+}
+
+// IntervalDuration returns Interval parsed, or DefaultWIPSnapshotInterval.
+func (c *WIPSnapshotConfig) IntervalDuration() time.Duration {
+	if c == nil {
+		return DefaultWIPSnapshotInterval
+	}
+	return ParseDurationOrDefault(c.Interval, DefaultWIPSnapshotInterval)
+}
+
+// KeepCount returns Keep, or DefaultWIPSnapshotKeep.
+func (c *WIPSnapshotConfig) KeepCount() int {
+	if c == nil || c.Keep <= 0 {
+		return DefaultWIPSnapshotKeep
+	}
+	return c.Keep
+}
+
+// MaxAgeDuration returns MaxAge parsed, or DefaultWIPSnapshotMaxAge.
+func (c *WIPSnapshotConfig) MaxAgeDuration() time.Duration {
+	if c == nil {
+		return DefaultWIPSnapshotMaxAge
+	}
+	return ParseDurationOrDefault(c.MaxAge, DefaultWIPSnapshotMaxAge)
+}
+
+// ConflictPredictionConfig configures conflict prediction: the daemon diffs
+// the rig's open MRs and working polecat branches against each other and
+// the default branch, saves the overlaps for gt mq conflicts and queue
+// ordering, and warns the polecats involved and the Mayor when two branches
+// start changing the same lines. A nil config uses the defaults.
+type ConflictPredictionConfig struct {
+	// Disabled turns periodic prediction off. gt mq conflicts still works.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Interval is the minimum time between predictions (default "15m").
+	Interval string `json:"interval,omitempty"`
+
+	// Quiet saves predictions without mailing anyone.
+	Quiet bool `json:"quiet,omitempty"`
+}
+
+// DefaultConflictPredictionInterval is the default time between conflict
+// predictions.
+const DefaultConflictPredictionInterval = 15 * time.Minute
+
+// Enabled reports whether periodic prediction runs.
+func (c *ConflictPredictionConfig) Enabled() bool {
+	return c == nil || !c.Disabled
+}
+
+// IntervalDuration returns Interval parsed, or
+// DefaultConflictPredictionInterval.
+func (c *ConflictPredictionConfig) IntervalDuration() time.Duration {
+	if c == nil {
+		return DefaultConflictPredictionInterval
+	}
+	return ParseDurationOrDefault(c.Interval, DefaultConflictPredictionInterval)
+}
+
+// Execution modes for polecat sessions.
+const (
+	ExecutionModeHost      = "host"
+	ExecutionModeContainer = "container"
+)
+
+// ExecutionConfig selects the polecat execution mode for a rig.
+type ExecutionConfig struct {
+	// Mode is "host" (default) or "container".
+	Mode string `json:"mode,omitempty"`
+
+	// Container configures container mode. Required when Mode is "container".
+	Container *ContainerConfig `json:"container,omitempty"`
+}
+
+// UsesContainer reports whether polecats should run in a container. Nil-safe.
+func (c *ExecutionConfig) UsesContainer() bool {
+	return c != nil && c.Mode == ExecutionModeContainer && c.Container != nil
+}
+
+// ContainerConfig describes the container polecats run in.
+// The tmux session stays on the host; the container is attached to the pane
+// with a TTY, so capture, nudge and kill work exactly as in host mode.
+type ContainerConfig struct {
+	// Driver is the container CLI: "docker" (default) or "podman".
+	Driver string `json:"driver,omitempty"`
+
+	// Image is a prebuilt image reference (e.g., "ghcr.io/org/toolchain:1.4").
+	// Ignored when Dockerfile is set.
+	Image string `json:"image,omitempty"`
+
+	// Dockerfile is the image spec, relative to the rig root (e.g.,
+	// "mayor/rig/.gastown/Dockerfile"). The image is built on first use and
+	// rebuilt whenever the file changes.
+	Dockerfile string `json:"dockerfile,omitempty"`
+
+	// BuildContext is the build context dir, relative to the rig root.
+	// Defaults to the Dockerfile's directory.
+	BuildContext string `json:"build_context,omitempty"`
+
+	// Network is the container network. Defaults to "host" so agents can
+	// reach the town's Dolt server on localhost.
+	Network string `json:"network,omitempty"`
+
+	// Mounts lists extra bind mounts as "host:container[:ro]".
+	Mounts []string `json:"mounts,omitempty"`
+
+	// Env sets extra environment variables inside the container.
+	Env map[string]string `json:"env,omitempty"`
+
+	// RunArgs are extra arguments passed to "<driver> run" verbatim.
+	RunArgs []string `json:"run_args,omitempty"`
+}
+
+// SandboxConfig configures per-role sandbox profiles for a rig.
+// Sandboxing is Linux-only; see internal/sandbox.
+type SandboxConfig struct {
+	// Enabled turns sandboxing on for this rig.
+	Enabled bool `json:"enabled"`
+
+	// Required makes session start fail when the sandbox cannot be applied
+	// (missing kernel support, no bwrap). When false, the session starts
+	// with whatever limits could be applied and a sandbox_unavailable event
+	// is recorded.
+	Required bool `json:"required,omitempty"`
+
+	// Default is the profile used for roles without an entry in Roles.
+	Default *SandboxProfile `json:"default,omitempty"`
+
+	// Roles maps role names ("polecat", "crew", ...) to profiles.
+	Roles map[string]*SandboxProfile `json:"roles,omitempty"`
+}
+
+// SandboxProfile describes the limits applied to one agent session.
+type SandboxProfile struct {
+	// CPUs caps CPU bandwidth in cores (e.g., 2 = two full cores, 0.5 = half).
+	// Zero means unlimited.
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// Memory caps memory usage (e.g., "4G", "512M"). Empty means unlimited.
+	Memory string `json:"memory,omitempty"`
+
+	// MaxPids caps the number of processes/threads. Zero means unlimited.
+	MaxPids int `json:"max_pids,omitempty"`
+
+	// ReadOnlyFS mounts everything read-only except the worktree, the agent's
+	// home directory, town/rig runtime dirs, beads and WritablePaths.
+	ReadOnlyFS bool `json:"read_only_fs,omitempty"`
+
+	// DenyNetwork runs the session in a private network namespace.
+	// Note: this also cuts loopback access to a host Dolt sql-server.
+	DenyNetwork bool `json:"deny_network,omitempty"`
+
+	// WritablePaths lists extra absolute paths kept writable under ReadOnlyFS
+	// (e.g., package manager caches).
+	WritablePaths []string `json:"writable_paths,omitempty"`
+}
+
+// ProfileFor returns the sandbox profile for role, or nil if sandboxing is
+// disabled or no profile applies. Nil-safe.
+func (c *SandboxConfig) ProfileFor(role string) *SandboxProfile {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+	if p, ok := c.Roles[role]; ok && p != nil {
+		return p
+	}
+	return c.Default
+}
+
+// NeedsNamespaces reports whether the profile requires mount/network
+// namespaces (bwrap) in addition to cgroup limits.
+func (p *SandboxProfile) NeedsNamespaces() bool {
+	return p.ReadOnlyFS || p.DenyNetwork
+}
+
+// MemoryBytes parses Memory into bytes. Accepts a plain byte count or a
+// K/M/G/T suffix (binary multiples). Returns 0 when Memory is empty.
+func (p *SandboxProfile) MemoryBytes() (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(p.Memory))
+	if s == "" {
+		return 0, nil
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		mult = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		mult = 1 << 30
+	case strings.HasSuffix(s, "T"):
+		mult = 1 << 40
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+	var n float64
+	if _, err := fmt.Sscanf(s, "%g", &n); err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid sandbox memory limit %q", p.Memory)
+	}
+	return int64(n * float64(mult)), nil
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -404,6 +688,10 @@ type RuntimeConfig struct {
 
 	// Instructions controls the per-workspace instruction file name.
 	Instructions *RuntimeInstructionsConfig `json:"instructions,omitempty"`
+
+	// Capabilities declares what this agent is good at, for gt sling --auto.
+	// Overrides the capabilities of the preset it is based on.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
 }
 
 // RuntimeSessionConfig configures how Gas Town discovers runtime session IDs.
@@ -854,6 +1142,29 @@ type MergeQueueConfig struct {
 	// TypecheckCommand is the command to run for type checking (e.g., tsc --noEmit).
 	TypecheckCommand string `json:"typecheck_command,omitempty"`
 
+	// VerifyStages is the ordered pre-merge verification pipeline the
+	// Refinery runs on the merged tree. When empty, the pipeline is derived
+	// from the setup, build, typecheck, lint and test commands, in that order.
+	VerifyStages []VerifyStage `json:"verify_stages,omitempty"`
+
+	// VerifyCache skips verification of a tree that already passed the same
+	// pipeline (e.g., an MR retried after a push race).
+	// Nil defaults to true.
+	VerifyCache *bool `json:"verify_cache,omitempty"`
+
+	// Review is the rig's code-review policy. When enabled, the Refinery
+	// has a reviewer agent review each MR before merging it.
+	Review *ReviewPolicy `json:"review,omitempty"`
+
+	// LandVia is how the Refinery lands MRs: "push" (default) merges and
+	// pushes to the target branch itself; "pr" opens a pull request per MR
+	// and merges it through the forge once required checks and approvals
+	// pass, for repos whose branch protection forbids direct pushes.
+	LandVia string `json:"land_via,omitempty"`
+
+	// PR configures pull-request landing (land_via = "pr").
+	PR *PRLandingPolicy `json:"pr,omitempty"`
+
 	// DeleteMergedBranches controls whether to delete branches after merging.
 	// Nil defaults to true (merged branches are deleted).
 	DeleteMergedBranches *bool `json:"delete_merged_branches,omitempty"`
@@ -878,6 +1189,137 @@ const (
 	OnConflictAutoRebase = "auto_rebase"
 )
 
+// Verification stage names. Stages with any other name are custom stages.
+const (
+	VerifyStageSetup     = "setup"
+	VerifyStageBuild     = "build"
+	VerifyStageTypecheck = "typecheck"
+	VerifyStageLint      = "lint"
+	VerifyStageTest      = "test"
+)
+
+// VerifyStage is one step of the Refinery's pre-merge verification pipeline.
+type VerifyStage struct {
+	// Name identifies the stage: setup, build, typecheck, lint, test, or any
+	// custom name. Test stages are retried per retry_flaky_tests.
+	Name string `json:"name"`
+
+	// Command is run with sh -c in the Refinery worktree.
+	Command string `json:"command"`
+
+	// Timeout bounds the stage (e.g., "10m"). Empty means no limit.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Parallel lets the stage run concurrently with the adjacent stages that
+	// are also marked parallel.
+	Parallel bool `json:"parallel,omitempty"`
+
+	// Results is the format of the per-test results of a test stage:
+	// "go-json", "junit", "tap", or "none" to treat the stage as opaque.
+	// Empty detects the format from the stage's stdout.
+	Results string `json:"results,omitempty"`
+
+	// ResultsFile is where the stage writes its results (e.g., a JUnit
+	// report), relative to the worktree. Empty means stdout.
+	ResultsFile string `json:"results_file,omitempty"`
+
+	// RerunCommand reruns only the failing tests. {tests} expands to the
+	// failing test names, {pattern} to a go test -run pattern and {packages}
+	// to their packages. Go test stages default to
+	// "go test -json -run {pattern} {packages}"; other formats rerun the
+	// whole command.
+	RerunCommand string `json:"rerun_command,omitempty"`
+}
+
+// Landing modes for MergeQueueConfig.LandVia.
+const (
+	LandViaPush = "push"
+	LandViaPR   = "pr"
+)
+
+// PR merge methods.
+const (
+	PRMergeSquash = "squash"
+	PRMergeMerge  = "merge"
+	PRMergeRebase = "rebase"
+)
+
+// PRLandingPolicy configures how the Refinery lands MRs through pull
+// requests.
+type PRLandingPolicy struct {
+	// RequiredChecks are the check names that must pass before merging.
+	// Empty means every check reported on the PR must pass, and a PR with
+	// no reported checks waits until CI reports at least one.
+	RequiredChecks []string `json:"required_checks,omitempty"`
+
+	// RequiredApprovals is the number of approving reviews needed.
+	RequiredApprovals int `json:"required_approvals,omitempty"`
+
+	// MergeMethod is squash (default), merge or rebase.
+	MergeMethod string `json:"merge_method,omitempty"`
+}
+
+// Method returns the PR merge method. Nil-safe.
+func (p *PRLandingPolicy) Method() string {
+	if p == nil || p.MergeMethod == "" {
+		return PRMergeSquash
+	}
+	return p.MergeMethod
+}
+
+// DefaultReviewFormula is the formula reviewers run on an MR.
+const DefaultReviewFormula = "mol-mr-review"
+
+// ReviewPolicy configures the review stage between gt done and merge.
+type ReviewPolicy struct {
+	// Enabled gates every MR on a review verdict.
+	Enabled bool `json:"enabled"`
+
+	// Reviewer is the sling target reviews are dispatched to: empty for a
+	// fresh polecat in the rig, or an agent such as "<rig>/reviewer".
+	// "manual" creates the review task without dispatching it.
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Formula is the formula the reviewer runs (default mol-mr-review).
+	Formula string `json:"formula,omitempty"`
+
+	// Agent overrides the reviewer's agent/runtime alias.
+	Agent string `json:"agent,omitempty"`
+
+	// Timeout is how long a review may stay pending before gt mq list
+	// flags it as overdue (e.g., "2h"). Empty means no limit.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ReviewFormula returns the formula reviewers run. Nil-safe.
+func (p *ReviewPolicy) ReviewFormula() string {
+	if p == nil || p.Formula == "" {
+		return DefaultReviewFormula
+	}
+	return p.Formula
+}
+
+// TimeoutDuration returns the parsed review timeout, or 0 for no limit.
+func (p *ReviewPolicy) TimeoutDuration() time.Duration {
+	if p == nil {
+		return 0
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// TimeoutDuration returns the parsed stage timeout, or 0 for no limit.
+func (s VerifyStage) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
 // IsPolecatIntegrationEnabled returns whether polecat integration branch
 // sourcing is enabled. Nil-safe, defaults to true.
 func (c *MergeQueueConfig) IsPolecatIntegrationEnabled() bool {
@@ -915,6 +1357,15 @@ func (c *MergeQueueConfig) IsRunTestsEnabled() bool {
 	return *c.RunTests
 }
 
+// IsVerifyCacheEnabled returns whether already-verified trees skip
+// verification. Nil-safe, defaults to true.
+func (c *MergeQueueConfig) IsVerifyCacheEnabled() bool {
+	if c.VerifyCache == nil {
+		return true
+	}
+	return *c.VerifyCache
+}
+
 // IsDeleteMergedBranchesEnabled returns whether merged branches should be deleted.
 // Nil-safe, defaults to true.
 func (c *MergeQueueConfig) IsDeleteMergedBranchesEnabled() bool {
@@ -982,6 +1433,9 @@ type Account struct {
 	Email       string `json:"email"`                 // account email
 	Description string `json:"description,omitempty"` // human description
 	ConfigDir   string `json:"config_dir"`            // path to CLAUDE_CONFIG_DIR
+
+	// Capabilities of this account (e.g., cost tier), for gt sling --auto.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
 }
 
 // CurrentAccountsVersion is the current schema version for AccountsConfig.
@@ -581,3 +581,106 @@ func TestParseDurationOrDefault_AllWebTimeoutDefaults(t *testing.T) {
 	}
 }
 
+
+func TestSandboxProfile_MemoryBytes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1048576", 1048576, false},
+		{"512M", 512 << 20, false},
+		{"4G", 4 << 30, false},
+		{"4Gi", 4 << 30, false},
+		{"2gb", 2 << 30, false},
+		{"1.5G", 3 << 29, false},
+		{"lots", 0, true},
+		{"-1G", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := (&SandboxProfile{Memory: tt.in}).MemoryBytes()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MemoryBytes(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MemoryBytes(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSandboxConfig_ProfileFor(t *testing.T) {
+	t.Parallel()
+	polecat := &SandboxProfile{MaxPids: 512}
+	dflt := &SandboxProfile{CPUs: 1}
+	c := &SandboxConfig{
+		Enabled: true,
+		Default: dflt,
+		Roles:   map[string]*SandboxProfile{"polecat": polecat},
+	}
+
+	if got := c.ProfileFor("polecat"); got != polecat {
+		t.Errorf("ProfileFor(polecat) = %+v, want role profile", got)
+	}
+	if got := c.ProfileFor("crew"); got != dflt {
+		t.Errorf("ProfileFor(crew) = %+v, want default", got)
+	}
+	c.Enabled = false
+	if got := c.ProfileFor("polecat"); got != nil {
+		t.Errorf("ProfileFor() with sandbox disabled = %+v, want nil", got)
+	}
+	var nilCfg *SandboxConfig
+	if got := nilCfg.ProfileFor("polecat"); got != nil {
+		t.Errorf("nil ProfileFor() = %+v, want nil", got)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	c := &Capabilities{Languages: []string{"Go"}, Tools: []string{"docker"}, Tags: []string{"frontend"}}
+	for _, name := range []string{"go", "DOCKER", "frontend"} {
+		if !c.Has(name) {
+			t.Errorf("Has(%q) = false", name)
+		}
+	}
+	if c.Has("rust") {
+		t.Error("Has(rust) = true")
+	}
+	var nilCaps *Capabilities
+	if nilCaps.Has("go") {
+		t.Error("nil Capabilities has go")
+	}
+
+	tests := map[string]int{"200000": 200000, "200k": 200000, "1M": 1000000}
+	for in, want := range tests {
+		if got, err := ParseContextTokens(in); err != nil || got != want {
+			t.Errorf("ParseContextTokens(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseContextTokens("big"); err == nil {
+		t.Error("ParseContextTokens(big) should fail")
+	}
+}
+
+func TestResolveAgentCapabilities(t *testing.T) {
+	haiku := &Capabilities{CostTier: CostTierLow}
+	town := &TownSettings{Agents: map[string]*RuntimeConfig{
+		"claude-haiku": {Provider: "claude", Capabilities: haiku},
+		"claude-plain": {Provider: "claude"},
+	}}
+	rig := &RigSettings{Agents: map[string]*RuntimeConfig{
+		"claude-haiku": {Provider: "claude", Capabilities: &Capabilities{CostTier: CostTierMedium}},
+	}}
+
+	if got := ResolveAgentCapabilities("claude-haiku", town, nil); got != haiku {
+		t.Errorf("town agent capabilities = %+v", got)
+	}
+	if got := ResolveAgentCapabilities("claude-haiku", town, rig); got == nil || got.CostTier != CostTierMedium {
+		t.Errorf("rig agent should override town agent, got %+v", got)
+	}
+	// Without its own capabilities a custom agent inherits its provider's.
+	if got, want := ResolveAgentCapabilities("claude-plain", town, nil), GetAgentPresetByName("claude").Capabilities; got != want {
+		t.Errorf("inherited capabilities = %+v, want %+v", got, want)
+	}
+}
@@ -6,14 +6,16 @@ import (
 )
 
 // Address represents a parsed agent or rig address.
-// Format: [machine:]rig[/polecat]
+// Format: [town@][machine:]rig[/polecat]
 //
 // Examples:
 //   - "gastown/rictus"        -> local machine, gastown rig, rictus polecat
 //   - "vm:gastown/rictus"     -> vm machine, gastown rig, rictus polecat
 //   - "gastown/"              -> local machine, gastown rig, broadcast
 //   - "vm:gastown/"           -> vm machine, gastown rig, broadcast
+//   - "acme@vm:gastown/nux"   -> nux in the gastown rig of the acme town on vm
 type Address struct {
+	Town    string // Federated town name (empty = this town)
 	Machine string // Machine name (empty = local)
 	Rig     string // Rig name (required)
 	Polecat string // Polecat name (empty = broadcast to rig)
@@ -25,6 +27,7 @@ type Address struct {
 //   - rig/
 //   - machine:rig/polecat
 //   - machine:rig/
+//   - town@[machine:]rig[/polecat]
 func ParseAddress(s string) (*Address, error) {
 	if s == "" {
 		return nil, fmt.Errorf("empty address")
@@ -32,6 +35,15 @@ func ParseAddress(s string) (*Address, error) {
 
 	addr := &Address{}
 
+	// Check for town prefix (town@), which must come before any path
+	if idx := strings.Index(s, "@"); idx >= 0 && !strings.Contains(s[:idx], "/") {
+		addr.Town = s[:idx]
+		s = s[idx+1:]
+		if addr.Town == "" {
+			return nil, fmt.Errorf("empty town name before '@'")
+		}
+	}
+
 	// Check for machine prefix (machine:)
 	if idx := strings.Index(s, ":"); idx >= 0 {
 		addr.Machine = s[:idx]
@@ -60,6 +72,10 @@ func ParseAddress(s string) (*Address, error) {
 func (a *Address) String() string {
 	var sb strings.Builder
 
+	if a.Town != "" {
+		sb.WriteString(a.Town)
+		sb.WriteString("@")
+	}
 	if a.Machine != "" {
 		sb.WriteString(a.Machine)
 		sb.WriteString(":")
@@ -77,7 +93,12 @@ func (a *Address) String() string {
 
 // IsLocal returns true if the address targets the local machine.
 func (a *Address) IsLocal() bool {
-	return a.Machine == "" || a.Machine == "local"
+	return a.Town == "" && (a.Machine == "" || a.Machine == "local")
+}
+
+// IsFederated returns true if the address targets another town.
+func (a *Address) IsFederated() bool {
+	return a.Town != ""
 }
 
 // IsBroadcast returns true if the address targets a rig (no specific polecat).
@@ -124,7 +145,7 @@ func (a *Address) Equal(other *Address) bool {
 		m2 = "local"
 	}
 
-	return m1 == m2 && a.Rig == other.Rig && a.Polecat == other.Polecat
+	return a.Town == other.Town && m1 == m2 && a.Rig == other.Rig && a.Polecat == other.Polecat
 }
 
 // MustParseAddress parses an address and panics on error.
@@ -51,6 +51,21 @@ func TestParseAddress(t *testing.T) {
 			input:   "vm:/rictus",
 			wantErr: true,
 		},
+		{
+			name:  "town@machine:rig/polecat",
+			input: "acme@vm:gastown/rictus",
+			want:  &Address{Town: "acme", Machine: "vm", Rig: "gastown", Polecat: "rictus"},
+		},
+		{
+			name:  "town@rig/crew path",
+			input: "acme@gastown/crew/max",
+			want:  &Address{Town: "acme", Rig: "gastown", Polecat: "crew/max"},
+		},
+		{
+			name:    "empty town",
+			input:   "@vm:gastown/rictus",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -66,6 +81,9 @@ func TestParseAddress(t *testing.T) {
 				t.Errorf("ParseAddress(%q) unexpected error: %v", tt.input, err)
 				return
 			}
+			if got.Town != tt.want.Town {
+				t.Errorf("Town = %q, want %q", got.Town, tt.want.Town)
+			}
 			if got.Machine != tt.want.Machine {
 				t.Errorf("Machine = %q, want %q", got.Machine, tt.want.Machine)
 			}
@@ -100,6 +118,10 @@ func TestAddressString(t *testing.T) {
 			addr: &Address{Machine: "vm", Rig: "gastown"},
 			want: "vm:gastown/",
 		},
+		{
+			addr: &Address{Town: "acme", Machine: "vm", Rig: "gastown", Polecat: "rictus"},
+			want: "acme@vm:gastown/rictus",
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,6 +143,7 @@ func TestAddressIsLocal(t *testing.T) {
 		{&Address{Machine: "", Rig: "gastown"}, true},
 		{&Address{Machine: "local", Rig: "gastown"}, true},
 		{&Address{Machine: "vm", Rig: "gastown"}, false},
+		{&Address{Town: "acme", Rig: "gastown"}, false},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,131 @@
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// CLIDriver drives a Docker-compatible CLI (docker or podman).
+type CLIDriver struct {
+	Binary string
+}
+
+// Name returns the CLI binary name.
+func (d *CLIDriver) Name() string {
+	return d.Binary
+}
+
+// ImageExists reports whether image is present locally.
+func (d *CLIDriver) ImageExists(image string) (bool, error) {
+	cmd := exec.Command(d.Binary, "image", "inspect", image) //nolint:gosec // G204: binary is docker/podman
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s image inspect: %w", d.Binary, err)
+	}
+	return true, nil
+}
+
+// Build builds and tags image, streaming build output to stdout.
+func (d *CLIDriver) Build(image, dockerfile, contextDir string) error {
+	cmd := exec.Command(d.Binary, "build", "-t", image, "-f", dockerfile, contextDir) //nolint:gosec // G204: args come from rig settings
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RunCommand returns "<binary> run --rm -it ..." for spec.
+func (d *CLIDriver) RunCommand(spec RunSpec) string {
+	args := []string{"run", "--rm", "-it", "--init", "--name", spec.Name}
+
+	network := spec.Network
+	switch {
+	case spec.Limits != nil && spec.Limits.DenyNetwork:
+		network = "none"
+	case network == "":
+		network = "host"
+	}
+	args = append(args, "--network", network)
+	if spec.User != "" {
+		args = append(args, "--user", spec.User)
+	}
+	if spec.WorkDir != "" {
+		args = append(args, "--workdir", spec.WorkDir)
+	}
+	for _, m := range spec.Mounts {
+		v := m.Source + ":" + m.Target
+		if m.ReadOnly {
+			v += ":ro"
+		}
+		args = append(args, "--volume", v)
+	}
+	for _, p := range spec.Masks {
+		args = append(args, "--tmpfs", p)
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for k := range spec.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--env", k+"="+spec.Env[k])
+	}
+
+	args = append(args, limitArgs(spec.Limits)...)
+	args = append(args, spec.Args...)
+	args = append(args, spec.Image, "/bin/sh", "-c", spec.Command)
+
+	parts := []string{"exec", d.Binary}
+	for _, a := range args {
+		parts = append(parts, config.ShellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// limitArgs maps a sandbox profile onto container run flags, so rigs get the
+// same limits in container mode as under the host sandbox. DenyNetwork is
+// handled by RunCommand's --network choice.
+func limitArgs(p *config.SandboxProfile) []string {
+	if p == nil {
+		return nil
+	}
+	var args []string
+	if p.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(p.CPUs, 'f', -1, 64))
+	}
+	if bytes, err := p.MemoryBytes(); err == nil && bytes > 0 {
+		args = append(args, "--memory", strconv.FormatInt(bytes, 10))
+	}
+	if p.MaxPids > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(p.MaxPids))
+	}
+	if p.ReadOnlyFS {
+		args = append(args, "--read-only", "--tmpfs", "/tmp")
+	}
+	return args
+}
+
+// Stop force-removes the container.
+func (d *CLIDriver) Stop(name string) error {
+	cmd := exec.Command(d.Binary, "rm", "-f", name) //nolint:gosec // G204: binary is docker/podman
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.ToLower(string(out))
+		if strings.Contains(msg, "no such container") || strings.Contains(msg, "no container with name") {
+			return nil
+		}
+		return fmt.Errorf("%s rm -f %s: %w: %s", d.Binary, name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
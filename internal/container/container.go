@@ -0,0 +1,196 @@
+// Package container runs agent sessions inside containers.
+//
+// Container mode keeps the tmux session on the host and runs the container
+// attached to the pane ("<driver> run -it ..."), so capture-pane, send-keys
+// and kill-session keep working unchanged. The worktree, agent dir, shared
+// beads dirs and the rig's git object store are bind-mounted at their host
+// paths, so absolute paths (and git worktree links) resolve identically
+// inside and outside the container.
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Driver is a container engine.
+type Driver interface {
+	// Name returns the driver name (e.g., "docker").
+	Name() string
+
+	// ImageExists reports whether image is available locally.
+	ImageExists(image string) (bool, error)
+
+	// Build builds image from dockerfile using contextDir as build context.
+	Build(image, dockerfile, contextDir string) error
+
+	// RunCommand returns the shell command that runs spec in the foreground,
+	// attached to the calling terminal. It is used as the tmux pane command.
+	RunCommand(spec RunSpec) string
+
+	// Stop force-removes the named container. A missing container is not
+	// an error.
+	Stop(name string) error
+}
+
+// Mount is a bind mount.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// RunSpec describes one container run.
+type RunSpec struct {
+	Name    string            // container name (the tmux session name)
+	Image   string            // image reference
+	WorkDir string            // working directory inside the container
+	User    string            // "uid:gid"; empty keeps the image default
+	Network string            // network mode; empty means "host"
+	Mounts  []Mount           // bind mounts
+	Masks   []string          // paths hidden behind an empty tmpfs
+	Env     map[string]string // extra environment
+	Limits  *config.SandboxProfile
+	Args    []string // extra run arguments
+	Command string   // shell command run with /bin/sh -c
+}
+
+// NewDriver returns the driver for name ("docker" if empty).
+func NewDriver(name string) (Driver, error) {
+	switch name {
+	case "", "docker":
+		return &CLIDriver{Binary: "docker"}, nil
+	case "podman":
+		return &CLIDriver{Binary: "podman"}, nil
+	default:
+		return nil, fmt.Errorf("unknown container driver %q", name)
+	}
+}
+
+// EnsureImage returns the image to run for cfg, building it from the rig's
+// Dockerfile when needed. Built images are tagged
+// "gastown-<rig>:<hash of the Dockerfile>" so edits trigger a rebuild.
+func EnsureImage(d Driver, cfg *config.ContainerConfig, rigName, rigPath string) (string, error) {
+	if cfg.Dockerfile == "" {
+		if cfg.Image == "" {
+			return "", fmt.Errorf("container config has neither image nor dockerfile")
+		}
+		return cfg.Image, nil
+	}
+
+	dockerfile := filepath.Join(rigPath, cfg.Dockerfile)
+	data, err := os.ReadFile(dockerfile) //nolint:gosec // G304: path comes from rig settings
+	if err != nil {
+		return "", fmt.Errorf("reading image spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	image := fmt.Sprintf("gastown-%s:%s", strings.ToLower(rigName), hex.EncodeToString(sum[:])[:12])
+
+	exists, err := d.ImageExists(image)
+	if err != nil {
+		return "", fmt.Errorf("checking image %s: %w", image, err)
+	}
+	if exists {
+		return image, nil
+	}
+
+	contextDir := filepath.Dir(dockerfile)
+	if cfg.BuildContext != "" {
+		contextDir = filepath.Join(rigPath, cfg.BuildContext)
+	}
+	if err := d.Build(image, dockerfile, contextDir); err != nil {
+		return "", fmt.Errorf("building image %s: %w", image, err)
+	}
+	return image, nil
+}
+
+// Paths locates the host directories a containerized session needs.
+type Paths struct {
+	TownRoot    string
+	RigPath     string
+	AgentDir    string
+	WorkDir     string
+	SettingsDir string // role settings dir (e.g., <rig>/polecats), holds .claude/settings.json
+}
+
+// Spec builds the RunSpec for an agent session from the rig's container
+// config. command is the agent startup command built for host mode.
+func Spec(cfg *config.ContainerConfig, image, name string, paths Paths, command string) RunSpec {
+	spec := RunSpec{
+		Name:    name,
+		Image:   image,
+		WorkDir: paths.WorkDir,
+		User:    fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		Network: cfg.Network,
+		Env:     make(map[string]string, len(cfg.Env)),
+		Args:    cfg.RunArgs,
+		Command: command,
+	}
+
+	for k, v := range cfg.Env {
+		spec.Env[k] = v
+	}
+
+	seen := make(map[string]bool)
+	add := func(p string, ro bool) {
+		if p == "" || seen[p] {
+			return
+		}
+		if _, err := os.Stat(p); err != nil {
+			return
+		}
+		seen[p] = true
+		spec.Mounts = append(spec.Mounts, Mount{Source: p, Target: p, ReadOnly: ro})
+	}
+
+	add(paths.WorkDir, false)
+	add(paths.AgentDir, false)
+	if paths.RigPath != "" {
+		// Shared beads and the git object store behind the worktree.
+		add(filepath.Join(paths.RigPath, ".beads"), false)
+		add(filepath.Join(paths.RigPath, "mayor", "rig", ".beads"), false)
+		add(filepath.Join(paths.RigPath, ".repo.git"), false)
+		add(filepath.Join(paths.RigPath, "mayor", "rig", ".git"), false)
+		add(filepath.Join(paths.RigPath, ".runtime"), false)
+	}
+	if paths.TownRoot != "" {
+		add(filepath.Join(paths.TownRoot, ".beads"), false)
+		add(filepath.Join(paths.TownRoot, ".runtime"), false)
+		// The master key decrypts every rig's secrets; agents get theirs
+		// materialized instead.
+		if seen[filepath.Join(paths.TownRoot, ".runtime")] {
+			spec.Masks = append(spec.Masks, filepath.Join(paths.TownRoot, ".runtime", "secrets"))
+		}
+		add(filepath.Join(paths.TownRoot, "mayor"), true)
+	}
+	if paths.SettingsDir != "" {
+		add(filepath.Join(paths.SettingsDir, ".claude"), true)
+	}
+	// Agent credentials and config.
+	if home, err := os.UserHomeDir(); err == nil {
+		add(filepath.Join(home, ".claude"), false)
+		add(filepath.Join(home, ".claude.json"), false)
+	}
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		add(dir, false)
+	}
+
+	for _, m := range cfg.Mounts {
+		parts := strings.Split(m, ":")
+		if len(parts) < 2 {
+			continue
+		}
+		spec.Mounts = append(spec.Mounts, Mount{
+			Source:   parts[0],
+			Target:   parts[1],
+			ReadOnly: len(parts) > 2 && parts[2] == "ro",
+		})
+	}
+	return spec
+}
@@ -0,0 +1,196 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestNewDriver(t *testing.T) {
+	for _, name := range []string{"", "docker", "podman"} {
+		d, err := NewDriver(name)
+		if err != nil {
+			t.Fatalf("NewDriver(%q) error = %v", name, err)
+		}
+		want := name
+		if want == "" {
+			want = "docker"
+		}
+		if d.Name() != want {
+			t.Errorf("NewDriver(%q).Name() = %q", name, d.Name())
+		}
+	}
+	if _, err := NewDriver("lxc"); err == nil {
+		t.Error("NewDriver(lxc) should fail")
+	}
+}
+
+func TestEnsureImage(t *testing.T) {
+	rigPath := t.TempDir()
+	fake := NewFakeDriver()
+
+	img, err := EnsureImage(fake, &config.ContainerConfig{Image: "alpine:3"}, "MyRig", rigPath)
+	if err != nil || img != "alpine:3" {
+		t.Errorf("EnsureImage(prebuilt) = %q, %v", img, err)
+	}
+	if len(fake.Built) != 0 {
+		t.Error("prebuilt image should not be built")
+	}
+
+	if err := os.WriteFile(filepath.Join(rigPath, "Dockerfile"), []byte("FROM alpine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.ContainerConfig{Dockerfile: "Dockerfile"}
+	first, err := EnsureImage(fake, cfg, "MyRig", rigPath)
+	if err != nil {
+		t.Fatalf("EnsureImage(dockerfile) error = %v", err)
+	}
+	if !strings.HasPrefix(first, "gastown-myrig:") || len(fake.Built) != 1 {
+		t.Errorf("EnsureImage() = %q, built %v", first, fake.Built)
+	}
+
+	// Unchanged spec: cached. Changed spec: new tag, rebuilt.
+	if again, _ := EnsureImage(fake, cfg, "MyRig", rigPath); again != first || len(fake.Built) != 1 {
+		t.Errorf("unchanged spec rebuilt: %q, %v", again, fake.Built)
+	}
+	_ = os.WriteFile(filepath.Join(rigPath, "Dockerfile"), []byte("FROM alpine\nRUN apk add git\n"), 0644)
+	changed, _ := EnsureImage(fake, cfg, "MyRig", rigPath)
+	if changed == first || len(fake.Built) != 2 {
+		t.Errorf("changed spec not rebuilt: %q, %v", changed, fake.Built)
+	}
+}
+
+func TestSpec_MountsAtHostPaths(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "myrig")
+	workDir := filepath.Join(rigPath, "polecats", "Toast", "myrig")
+	for _, d := range []string{workDir, filepath.Join(rigPath, ".beads"), filepath.Join(rigPath, ".repo.git")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.ContainerConfig{
+		Mounts: []string{"/var/cache/go:/go/pkg:ro"},
+		Env:    map[string]string{"GOFLAGS": "-mod=mod"},
+	}
+
+	spec := Spec(cfg, "img", "gt-Toast", Paths{
+		TownRoot: townRoot,
+		RigPath:  rigPath,
+		AgentDir: filepath.Dir(workDir),
+		WorkDir:  workDir,
+	}, "claude")
+
+	mounts := make(map[string]Mount)
+	for _, m := range spec.Mounts {
+		mounts[m.Target] = m
+	}
+	for _, p := range []string{workDir, filepath.Dir(workDir), filepath.Join(rigPath, ".beads"), filepath.Join(rigPath, ".repo.git")} {
+		if m, ok := mounts[p]; !ok || m.Source != p || m.ReadOnly {
+			t.Errorf("missing rw mount for %s: %+v", p, m)
+		}
+	}
+	if m := mounts["/go/pkg"]; m.Source != "/var/cache/go" || !m.ReadOnly {
+		t.Errorf("extra mount = %+v", m)
+	}
+	if _, ok := mounts[filepath.Join(rigPath, "mayor", "rig", ".beads")]; ok {
+		t.Error("nonexistent paths should not be mounted")
+	}
+	if spec.Env["GOFLAGS"] != "-mod=mod" {
+		t.Errorf("Env = %v", spec.Env)
+	}
+	spec.Env["X"] = "y"
+	if _, ok := cfg.Env["X"]; ok {
+		t.Error("Spec must not alias the config's Env map")
+	}
+}
+
+// reachable reports whether path is visible inside a container run from
+// spec: under some mount and not under a mask.
+func reachable(spec RunSpec, path string) bool {
+	under := func(p, dir string) bool {
+		return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+	}
+	for _, m := range spec.Masks {
+		if under(path, m) {
+			return false
+		}
+	}
+	for _, m := range spec.Mounts {
+		if under(path, m.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSpec_HidesSecretsKey(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "myrig")
+	workDir := filepath.Join(rigPath, "polecats", "Toast", "myrig")
+	keyPath := filepath.Join(townRoot, ".runtime", "secrets", "master.key")
+	for _, d := range []string{workDir, filepath.Dir(keyPath), filepath.Join(townRoot, ".runtime", "nudge_queue")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(keyPath, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := Spec(&config.ContainerConfig{}, "img", "gt-Toast", Paths{
+		TownRoot: townRoot,
+		RigPath:  rigPath,
+		AgentDir: filepath.Dir(workDir),
+		WorkDir:  workDir,
+	}, "claude")
+
+	if reachable(spec, keyPath) {
+		t.Errorf("master key reachable in container: mounts %+v, masks %v", spec.Mounts, spec.Masks)
+	}
+	if !reachable(spec, filepath.Join(townRoot, ".runtime", "nudge_queue")) {
+		t.Error("town runtime state should stay reachable")
+	}
+	got := (&CLIDriver{Binary: "docker"}).RunCommand(spec)
+	if !strings.Contains(got, "--tmpfs "+filepath.Dir(keyPath)) {
+		t.Errorf("RunCommand() does not mask the secrets dir: %s", got)
+	}
+}
+
+func TestCLIDriver_RunCommand(t *testing.T) {
+	d := &CLIDriver{Binary: "podman"}
+	spec := RunSpec{
+		Name:    "gt-Toast",
+		Image:   "img:1",
+		WorkDir: "/w",
+		User:    "1000:1000",
+		Mounts:  []Mount{{Source: "/w", Target: "/w"}, {Source: "/m", Target: "/m", ReadOnly: true}},
+		Env:     map[string]string{"B": "2", "A": "1"},
+		Limits:  &config.SandboxProfile{CPUs: 2, Memory: "1G", MaxPids: 100},
+		Command: "exec claude --x 'y'",
+	}
+
+	got := d.RunCommand(spec)
+	for _, want := range []string{
+		"exec podman run --rm -it --init --name gt-Toast --network host",
+		"--user 1000:1000 --workdir /w --volume /w:/w --volume /m:/m:ro --env A=1 --env B=2",
+		"--cpus 2 --memory 1073741824 --pids-limit 100",
+		"img:1 /bin/sh -c 'exec claude --x '\\''y'\\'''",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RunCommand() missing %q\n got: %s", want, got)
+		}
+	}
+
+	spec.Limits = &config.SandboxProfile{DenyNetwork: true, ReadOnlyFS: true}
+	got = d.RunCommand(spec)
+	if !strings.Contains(got, "--network none") || strings.Contains(got, "--network host") {
+		t.Errorf("DenyNetwork should select --network none: %s", got)
+	}
+	if !strings.Contains(got, "--read-only --tmpfs /tmp") {
+		t.Errorf("ReadOnlyFS should add --read-only: %s", got)
+	}
+}
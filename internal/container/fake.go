@@ -0,0 +1,57 @@
+package container
+
+import "sync"
+
+// FakeDriver is an in-memory Driver for tests. RunCommand returns the spec's
+// command unchanged, so sessions run directly on the host.
+type FakeDriver struct {
+	mu      sync.Mutex
+	Images  map[string]bool // locally available images
+	Built   []string        // images built, in order
+	Runs    []RunSpec       // specs passed to RunCommand
+	Stopped []string        // containers stopped
+}
+
+// NewFakeDriver returns a FakeDriver with the given images available.
+func NewFakeDriver(images ...string) *FakeDriver {
+	f := &FakeDriver{Images: make(map[string]bool)}
+	for _, img := range images {
+		f.Images[img] = true
+	}
+	return f
+}
+
+// Name returns "fake".
+func (f *FakeDriver) Name() string { return "fake" }
+
+// ImageExists reports whether image was registered or built.
+func (f *FakeDriver) ImageExists(image string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Images[image], nil
+}
+
+// Build records the build and marks image available.
+func (f *FakeDriver) Build(image, dockerfile, contextDir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Built = append(f.Built, image)
+	f.Images[image] = true
+	return nil
+}
+
+// RunCommand records spec and returns its command unchanged.
+func (f *FakeDriver) RunCommand(spec RunSpec) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Runs = append(f.Runs, spec)
+	return spec.Command
+}
+
+// Stop records the stop.
+func (f *FakeDriver) Stop(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Stopped = append(f.Stopped, name)
+	return nil
+}
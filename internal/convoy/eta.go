@@ -0,0 +1,339 @@
+package convoy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// deadlineKey is the convoy description line holding its target date.
+const deadlineKey = "Deadline: "
+
+// CycleTimeWindow is how far back the events log is replayed to learn
+// cycle times.
+const CycleTimeWindow = 30 * 24 * time.Hour
+
+// SLAStatus is a convoy's standing against its deadline.
+type SLAStatus string
+
+const (
+	SLANone    SLAStatus = ""         // No deadline set
+	SLAOnTrack SLAStatus = "on_track" // Forecast lands before the deadline
+	SLAAtRisk  SLAStatus = "at_risk"  // Forecast lands after the deadline
+	SLAOverdue SLAStatus = "overdue"  // Deadline passed with work remaining
+	SLAUnknown SLAStatus = "unknown"  // No history to forecast from
+)
+
+// Slipping reports whether the status warrants an alert.
+func (s SLAStatus) Slipping() bool {
+	return s == SLAAtRisk || s == SLAOverdue
+}
+
+// ParseDeadline extracts a convoy's deadline from its description. Returns
+// the zero time if not set or malformed.
+func ParseDeadline(description string) time.Time {
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, deadlineKey) {
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(strings.TrimPrefix(line, deadlineKey)))
+			if err != nil {
+				return time.Time{}
+			}
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// SetDeadline returns description with its deadline set to t, replacing any
+// existing deadline. The zero time removes the deadline.
+func SetDeadline(description string, t time.Time) string {
+	value := ""
+	if !t.IsZero() {
+		value = t.Format(time.RFC3339)
+	}
+	return setDescriptionField(description, deadlineKey, value)
+}
+
+// ParseDeadlineArg parses a user-supplied deadline: an RFC 3339 timestamp,
+// or a date (YYYY-MM-DD), which means the end of that day in local time.
+func ParseDeadlineArg(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return d.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid deadline %q: want YYYY-MM-DD or RFC 3339", s)
+}
+
+// CycleTimes is the record of how long issues took from sling to merge,
+// learned from the events log. A merge is matched to its sling by bead, or
+// by the branch the bead's worker reported at gt done.
+type CycleTimes struct {
+	rigs     map[string][]time.Duration
+	all      []time.Duration
+	started  map[string]slingStart // bead -> first sling not yet merged
+	branches map[string]string     // branch -> bead
+}
+
+type slingStart struct {
+	rig string
+	at  time.Time
+}
+
+// NewCycleTimes returns an empty record.
+func NewCycleTimes() *CycleTimes {
+	return &CycleTimes{
+		rigs:     make(map[string][]time.Duration),
+		started:  make(map[string]slingStart),
+		branches: make(map[string]string),
+	}
+}
+
+// LoadCycleTimes replays the town's sling, done and merged events since the
+// given time, including archived segments. A missing log is an empty record.
+func LoadCycleTimes(townRoot string, since time.Time) (*CycleTimes, error) {
+	c := NewCycleTimes()
+	q := &events.Query{
+		Types:          []string{events.TypeSling, events.TypeDone, events.TypeMerged},
+		Since:          since,
+		IncludeArchive: true,
+	}
+	err := events.Scan(townRoot, q, func(e *events.Event) error {
+		c.learn(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// learn records one event. Events must arrive oldest first.
+func (c *CycleTimes) learn(e *events.Event) {
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return
+	}
+	bead, _ := e.Payload["bead"].(string)
+	branch, _ := e.Payload["branch"].(string)
+
+	switch e.Type {
+	case events.TypeSling:
+		target, _ := e.Payload["target"].(string)
+		rig := events.RigOfTarget(target)
+		if bead == "" || rig == "" {
+			return
+		}
+		// A re-sling (the first worker died) keeps the original start:
+		// the convoy waited the whole time.
+		if _, ok := c.started[bead]; !ok {
+			c.started[bead] = slingStart{rig: rig, at: ts}
+		}
+	case events.TypeDone:
+		if bead != "" && branch != "" {
+			c.branches[branch] = bead
+		}
+	case events.TypeMerged:
+		if bead == "" {
+			bead = c.branches[branch]
+		}
+		s, ok := c.started[bead]
+		if !ok || !ts.After(s.at) {
+			return
+		}
+		d := ts.Sub(s.at)
+		c.rigs[s.rig] = append(c.rigs[s.rig], d)
+		c.all = append(c.all, d)
+		delete(c.started, bead)
+		delete(c.branches, branch)
+	}
+}
+
+// Estimate returns the median cycle time on a rig and the number of merges
+// behind it, falling back to the town-wide median for rigs with no record.
+// Returns 0, 0 with no record at all.
+func (c *CycleTimes) Estimate(rig string) (time.Duration, int) {
+	samples := c.rigs[rig]
+	if len(samples) == 0 {
+		samples = c.all
+	}
+	return median(samples), len(samples)
+}
+
+// Samples returns the number of merges learned across the town.
+func (c *CycleTimes) Samples() int {
+	return len(c.all)
+}
+
+// SlungAt returns when a bead still awaiting merge was first slung, and to
+// which rig. ok is false if no such sling was seen.
+func (c *CycleTimes) SlungAt(bead string) (at time.Time, rig string, ok bool) {
+	s, ok := c.started[bead]
+	return s.at, s.rig, ok
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Issue is the state of a tracked issue that a forecast needs.
+type Issue struct {
+	ID       string
+	Status   string
+	Assignee string
+}
+
+// Forecast is when a convoy is expected to land and how that compares to
+// its deadline.
+type Forecast struct {
+	Deadline  *time.Time `json:"deadline,omitempty"`
+	ETA       *time.Time `json:"eta,omitempty"`
+	Remaining int        `json:"remaining"`
+	InFlight  int        `json:"in_flight"`
+	Samples   int        `json:"samples"` // Merges the estimate is based on
+	SLA       SLAStatus  `json:"sla,omitempty"`
+}
+
+// workItem is a remaining issue in the forecast simulation. Started is zero
+// for issues not yet dispatched.
+type workItem struct {
+	est     time.Duration
+	started time.Time
+}
+
+// Forecast estimates when the given tracked issues will all have landed.
+// Each remaining issue takes its rig's median cycle time. In-flight issues
+// finish their cycle from when they were slung; queued issues start as
+// lanes free up, with as many lanes as the convoy's in-flight limit (or,
+// without one, as many as are in flight now, at least one). The ETA is
+// unknown (nil) when the town has no merge history.
+func (c *CycleTimes) Forecast(townRoot string, issues []Issue, maxInFlight int, deadline, now time.Time) Forecast {
+	f := Forecast{Samples: c.Samples()}
+	if !deadline.IsZero() {
+		f.Deadline = &deadline
+	}
+
+	var items []workItem
+	for _, is := range issues {
+		if is.Status == "closed" || is.Status == "tombstone" {
+			continue
+		}
+		f.Remaining++
+		item := workItem{}
+		at, rig, slung := c.SlungAt(is.ID)
+		if IsInFlight(is.Status, is.Assignee) {
+			f.InFlight++
+			item.started = now
+			if slung {
+				item.started = at
+			}
+		}
+		if !slung {
+			rig = rigForIssue(townRoot, is.ID)
+		}
+		item.est, _ = c.Estimate(rig)
+		items = append(items, item)
+	}
+
+	if f.Remaining == 0 {
+		f.ETA = &now
+	} else if f.Samples > 0 {
+		eta := simulate(now, items, maxInFlight)
+		f.ETA = &eta
+	}
+	f.SLA = AssessSLA(deadline, f.ETA, f.Remaining, now)
+	return f
+}
+
+// simulate plays the remaining work through the convoy's lanes and returns
+// when the last issue lands.
+func simulate(now time.Time, items []workItem, maxInFlight int) time.Time {
+	var lanes []time.Time
+	var queued []workItem
+	for _, it := range items {
+		if it.started.IsZero() {
+			queued = append(queued, it)
+			continue
+		}
+		end := it.started.Add(it.est)
+		if end.Before(now) {
+			// Running late; assume it lands any moment.
+			end = now
+		}
+		lanes = append(lanes, end)
+	}
+
+	width := maxInFlight
+	if width <= 0 {
+		width = len(lanes)
+	}
+	if width < 1 {
+		width = 1
+	}
+	for len(lanes) < width {
+		lanes = append(lanes, now)
+	}
+
+	for _, it := range queued {
+		next := 0
+		for i := range lanes {
+			if lanes[i].Before(lanes[next]) {
+				next = i
+			}
+		}
+		lanes[next] = lanes[next].Add(it.est)
+	}
+
+	eta := now
+	for _, end := range lanes {
+		if end.After(eta) {
+			eta = end
+		}
+	}
+	return eta
+}
+
+// AssessSLA compares a forecast to its deadline. A nil ETA means no
+// forecast could be made.
+func AssessSLA(deadline time.Time, eta *time.Time, remaining int, now time.Time) SLAStatus {
+	switch {
+	case deadline.IsZero():
+		return SLANone
+	case remaining == 0:
+		return SLAOnTrack
+	case now.After(deadline):
+		return SLAOverdue
+	case eta == nil:
+		return SLAUnknown
+	case eta.After(deadline):
+		return SLAAtRisk
+	default:
+		return SLAOnTrack
+	}
+}
+
+// ForecastConvoy forecasts an open convoy from its description and tracked
+// issues, as the daemon and dashboard see it.
+func ForecastConvoy(townRoot, convoyID, description string, c *CycleTimes, now time.Time) Forecast {
+	tracked := getConvoyTrackedIssues(townRoot, convoyID)
+	issues := make([]Issue, len(tracked))
+	for i, t := range tracked {
+		issues[i] = Issue{ID: t.ID, Status: t.Status, Assignee: t.Assignee}
+	}
+	return c.Forecast(townRoot, issues, ParseMaxInFlight(description), ParseDeadline(description), now)
+}
@@ -0,0 +1,171 @@
+package convoy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestDeadlineRoundTrip(t *testing.T) {
+	desc := "Convoy tracking 2 issues\nOwner: mayor/"
+	due := time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)
+
+	set := SetDeadline(desc, due)
+	if got := ParseDeadline(set); !got.Equal(due) {
+		t.Errorf("ParseDeadline = %v, want %v", got, due)
+	}
+	if strings.Count(SetDeadline(set, due.Add(time.Hour)), deadlineKey) != 1 {
+		t.Errorf("replacing deadline duplicated the line")
+	}
+	if cleared := SetDeadline(set, time.Time{}); cleared != desc {
+		t.Errorf("cleared = %q, want %q", cleared, desc)
+	}
+	if got := ParseDeadline("Deadline: soon"); !got.IsZero() {
+		t.Errorf("malformed deadline parsed as %v", got)
+	}
+}
+
+func TestParseDeadlineArg(t *testing.T) {
+	got, err := ParseDeadlineArg("2026-11-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 11, 1, 23, 59, 59, 0, time.Local)
+	if !got.Equal(want) {
+		t.Errorf("date = %v, want end of day %v", got, want)
+	}
+	if _, err := ParseDeadlineArg("2026-11-01T12:00:00Z"); err != nil {
+		t.Errorf("RFC 3339: %v", err)
+	}
+	if _, err := ParseDeadlineArg("next week"); err == nil {
+		t.Error("expected error for free-form date")
+	}
+}
+
+func ev(ts time.Time, typ string, payload map[string]interface{}) *events.Event {
+	return &events.Event{Timestamp: ts.Format(time.RFC3339), Type: typ, Payload: payload}
+}
+
+func TestCycleTimesLearn(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	c := NewCycleTimes()
+	for _, e := range []*events.Event{
+		// gt-1: merged 2h after sling, matched by bead.
+		ev(t0, events.TypeSling, events.SlingPayload("gt-1", "gastown/polecats/alpha")),
+		ev(t0.Add(2*time.Hour), events.TypeMerged, map[string]interface{}{"bead": "gt-1", "branch": "polecat/alpha"}),
+		// gt-2: re-slung, merged 4h after the first sling, matched by branch.
+		ev(t0, events.TypeSling, events.SlingPayload("gt-2", "gastown")),
+		ev(t0.Add(time.Hour), events.TypeSling, events.SlingPayload("gt-2", "gastown/polecats/beta")),
+		ev(t0.Add(3*time.Hour), events.TypeDone, events.DonePayload("gt-2", "polecat/beta-x")),
+		ev(t0.Add(4*time.Hour), events.TypeMerged, map[string]interface{}{"branch": "polecat/beta-x"}),
+		// bd-1 on beads: 6h.
+		ev(t0, events.TypeSling, events.SlingPayload("bd-1", "beads")),
+		ev(t0.Add(6*time.Hour), events.TypeMerged, map[string]interface{}{"bead": "bd-1"}),
+		// gt-3: still in flight.
+		ev(t0.Add(5*time.Hour), events.TypeSling, events.SlingPayload("gt-3", "gastown")),
+		// Town-level sling: no rig.
+		ev(t0, events.TypeSling, events.SlingPayload("hq-1", "mayor/")),
+	} {
+		c.learn(e)
+	}
+
+	if d, n := c.Estimate("gastown"); d != 3*time.Hour || n != 2 {
+		t.Errorf("Estimate(gastown) = %v, %d; want 3h, 2", d, n)
+	}
+	if d, n := c.Estimate("unknown-rig"); d != 4*time.Hour || n != 3 {
+		t.Errorf("Estimate(unknown-rig) = %v, %d; want town median 4h, 3", d, n)
+	}
+	if at, rig, ok := c.SlungAt("gt-3"); !ok || rig != "gastown" || !at.Equal(t0.Add(5*time.Hour)) {
+		t.Errorf("SlungAt(gt-3) = %v, %q, %v", at, rig, ok)
+	}
+	if _, _, ok := c.SlungAt("gt-1"); ok {
+		t.Error("merged bead should no longer be in flight")
+	}
+	if _, _, ok := c.SlungAt("hq-1"); ok {
+		t.Error("town-level sling should be ignored")
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	h := time.Hour
+	tests := []struct {
+		name  string
+		items []workItem
+		max   int
+		want  time.Duration
+	}{
+		{"nothing left", nil, 0, 0},
+		{"one queued, no limit", []workItem{{est: 2 * h}}, 0, 2 * h},
+		{"queued run serially without a limit", []workItem{{est: 2 * h}, {est: 2 * h}, {est: 2 * h}}, 0, 6 * h},
+		{"limit runs queued work in parallel", []workItem{{est: 2 * h}, {est: 2 * h}, {est: 2 * h}}, 2, 4 * h},
+		{"in flight finishes its cycle", []workItem{{est: 3 * h, started: now.Add(-h)}}, 0, 2 * h},
+		{"late in flight lands now", []workItem{{est: h, started: now.Add(-5 * h)}, {est: h}}, 0, h},
+		{"queued takes the earliest lane", []workItem{
+			{est: 4 * h, started: now}, {est: h, started: now}, {est: 2 * h},
+		}, 2, 4 * h},
+	}
+	for _, tt := range tests {
+		got := simulate(now, tt.items, tt.max).Sub(now)
+		if got != tt.want {
+			t.Errorf("%s: ETA in %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssessSLA(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	deadline := now.Add(24 * time.Hour)
+	early, late := now.Add(time.Hour), now.Add(48*time.Hour)
+	tests := []struct {
+		name      string
+		deadline  time.Time
+		eta       *time.Time
+		remaining int
+		now       time.Time
+		want      SLAStatus
+	}{
+		{"no deadline", time.Time{}, &late, 1, now, SLANone},
+		{"on track", deadline, &early, 1, now, SLAOnTrack},
+		{"at risk", deadline, &late, 1, now, SLAAtRisk},
+		{"no history", deadline, nil, 1, now, SLAUnknown},
+		{"overdue", deadline, &late, 1, deadline.Add(time.Minute), SLAOverdue},
+		{"done after deadline", deadline, &early, 0, deadline.Add(time.Minute), SLAOnTrack},
+	}
+	for _, tt := range tests {
+		if got := AssessSLA(tt.deadline, tt.eta, tt.remaining, tt.now); got != tt.want {
+			t.Errorf("%s: AssessSLA = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestForecast(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCycleTimes()
+	c.learn(ev(now.Add(-10*time.Hour), events.TypeSling, events.SlingPayload("gt-old", "gastown")))
+	c.learn(ev(now.Add(-8*time.Hour), events.TypeMerged, map[string]interface{}{"bead": "gt-old"}))
+	c.learn(ev(now.Add(-time.Hour), events.TypeSling, events.SlingPayload("gt-2", "gastown/polecats/alpha")))
+
+	issues := []Issue{
+		{ID: "gt-1", Status: "closed"},
+		{ID: "gt-2", Status: "in_progress", Assignee: "gastown/polecats/alpha"},
+		{ID: "gt-3", Status: "open"},
+	}
+	f := c.Forecast(t.TempDir(), issues, 0, now.Add(2*time.Hour), now)
+	if f.Remaining != 2 || f.InFlight != 1 || f.Samples != 1 {
+		t.Errorf("forecast = %+v", f)
+	}
+	// gt-2 lands in 1h, then gt-3 takes 2h.
+	if f.ETA == nil || !f.ETA.Equal(now.Add(3*time.Hour)) {
+		t.Errorf("ETA = %v, want now+3h", f.ETA)
+	}
+	if f.SLA != SLAAtRisk {
+		t.Errorf("SLA = %q, want at_risk", f.SLA)
+	}
+
+	if f := NewCycleTimes().Forecast(t.TempDir(), issues, 0, time.Time{}, now); f.ETA != nil || f.SLA != SLANone {
+		t.Errorf("forecast without history = %+v, want no ETA", f)
+	}
+}
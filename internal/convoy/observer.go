@@ -60,6 +60,9 @@ func CheckConvoysForIssue(townRoot, issueID, observer string, logger func(format
 		// event-driven instead of relying on polling-based patrol cycles.
 		if !isConvoyClosed(townRoot, convoyID) {
 			feedNextReadyIssue(townRoot, convoyID, observer, logger)
+		} else {
+			// The convoy just landed: start the convoys that were waiting on it.
+			feedDependentConvoys(townRoot, convoyID, observer, logger)
 		}
 	}
 
@@ -70,19 +73,15 @@ func CheckConvoysForIssue(townRoot, issueID, observer string, logger func(format
 // Uses bd dep list to query the dependency graph.
 func getTrackingConvoys(townRoot, issueID string) []string {
 	// Query for convoys that track this issue (direction=up finds dependents)
-	cmd := exec.Command("bd", "dep", "list", issueID, "--direction=up", "-t", "tracks", "--json")
-	cmd.Dir = townRoot
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
+	out, err := beads.RunRead(townRoot, "dep", "list", issueID, "--direction=up", "-t", "tracks", "--json")
+	if err != nil {
 		return nil
 	}
 
 	var results []struct {
 		ID string `json:"id"`
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+	if err := json.Unmarshal(out, &results); err != nil {
 		return nil
 	}
 
@@ -142,21 +141,43 @@ type trackedIssue struct {
 // provides reactive (event-driven) convoy feeding instead of waiting for
 // polling-based patrol cycles.
 //
-// Only one issue is dispatched per call. When that issue completes, the
-// observer fires again and feeds the next one.
+// The convoy's schedule is honored: nothing is fed while a convoy it waits
+// on (a 'blocks' dependency) is still open, and a convoy with a MaxInFlight
+// limit is topped up to that limit. Without a limit, only one issue is
+// dispatched per call. When that issue completes, the observer fires again
+// and feeds the next one.
 func feedNextReadyIssue(townRoot, convoyID, observer string, logger func(format string, args ...interface{})) {
 	tracked := getConvoyTrackedIssues(townRoot, convoyID)
 	if len(tracked) == 0 {
 		return
 	}
 
-	// Find the first ready issue (open, no assignee).
+	if pending := Pending(GetBlockers(townRoot, convoyID)); len(pending) > 0 {
+		logger("%s: convoy %s: waiting on %s to land, not feeding", observer, convoyID, pending[0].ID)
+		return
+	}
+
+	budget := feedBudget(tracked, getMaxInFlight(townRoot, convoyID))
+	if budget == 0 {
+		logger("%s: convoy %s: at its in-flight limit, not feeding", observer, convoyID)
+		return
+	}
+
+	feedReadyIssues(townRoot, convoyID, observer, tracked, budget, logger)
+}
+
+// feedReadyIssues dispatches up to budget ready issues (open, no assignee)
+// and returns how many were dispatched. A failed dispatch doesn't use up
+// the budget; the next ready issue is tried instead.
+func feedReadyIssues(townRoot, convoyID, observer string, tracked []trackedIssue, budget int, logger func(format string, args ...interface{})) int {
 	// Issues are returned by bd dep list in dependency order, so we pick
-	// the first match which is typically the highest priority.
+	// the first matches which are typically the highest priority.
+	fed, ready := 0, 0
 	for _, issue := range tracked {
 		if issue.Status != "open" || issue.Assignee != "" {
 			continue
 		}
+		ready++
 
 		// Determine target rig from issue prefix
 		rig := rigForIssue(townRoot, issue.ID)
@@ -166,25 +187,40 @@ func feedNextReadyIssue(townRoot, convoyID, observer string, logger func(format
 		}
 
 		logger("%s: convoy %s: feeding next ready issue %s to %s", observer, convoyID, issue.ID, rig)
-		if err := dispatchIssue(townRoot, issue.ID, rig); err != nil {
+		if err := dispatchIssueFn(townRoot, issue.ID, rig); err != nil {
 			logger("%s: convoy %s: failed to dispatch %s: %v", observer, convoyID, issue.ID, err)
+			continue
+		}
+		if fed++; fed == budget {
+			return fed
 		}
-		return // Feed one at a time
 	}
 
-	logger("%s: convoy %s: no ready issues to feed", observer, convoyID)
+	if ready == 0 {
+		logger("%s: convoy %s: no ready issues to feed", observer, convoyID)
+	}
+	return fed
+}
+
+// feedDependentConvoys feeds the open convoys that were waiting on a convoy
+// that just landed. Convoys still waiting on another open convoy are left
+// alone; feedNextReadyIssue checks that.
+func feedDependentConvoys(townRoot, convoyID, observer string, logger func(format string, args ...interface{})) {
+	for _, dep := range GetDependents(townRoot, convoyID) {
+		if dep.Landed() {
+			continue
+		}
+		logger("%s: convoy %s landed, feeding dependent convoy %s", observer, convoyID, dep.ID)
+		feedNextReadyIssue(townRoot, dep.ID, observer, logger)
+	}
 }
 
 // getConvoyTrackedIssues returns issues tracked by a convoy with fresh status.
 // Uses bd dep list for the tracking relations, then bd show for current status.
 func getConvoyTrackedIssues(townRoot, convoyID string) []trackedIssue {
 	// Get tracked issue IDs from dependency graph
-	depCmd := exec.Command("bd", "dep", "list", convoyID, "--direction=down", "--type=tracks", "--json")
-	depCmd.Dir = townRoot
-	var stdout bytes.Buffer
-	depCmd.Stdout = &stdout
-
-	if err := depCmd.Run(); err != nil {
+	out, err := beads.RunRead(townRoot, "dep", "list", convoyID, "--direction=down", "--type=tracks", "--json")
+	if err != nil {
 		return nil
 	}
 
@@ -194,7 +230,7 @@ func getConvoyTrackedIssues(townRoot, convoyID string) []trackedIssue {
 		Assignee string `json:"assignee"`
 		Priority int    `json:"priority"`
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
+	if err := json.Unmarshal(out, &deps); err != nil {
 		return nil
 	}
 
@@ -294,6 +330,9 @@ func rigForIssue(townRoot, issueID string) string {
 	return beads.GetRigNameForPrefix(townRoot, prefix)
 }
 
+// dispatchIssueFn is dispatchIssue, replaceable in tests.
+var dispatchIssueFn = dispatchIssue
+
 // dispatchIssue dispatches an issue to a rig via gt sling.
 func dispatchIssue(townRoot, issueID, rig string) error {
 	cmd := exec.Command("gt", "sling", issueID, rig, "--no-boot")
@@ -1,6 +1,9 @@
 package convoy
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -136,3 +139,40 @@ func TestFeedNextReadyIssue_EmptyTracked(t *testing.T) {
 		t.Errorf("expected no logs for empty tracked issues, got %d", len(logged))
 	}
 }
+
+func TestFeedReadyIssues_FailedDispatchDoesNotCount(t *testing.T) {
+	townRoot := t.TempDir()
+	beadsDir := filepath.Join(townRoot, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "routes.jsonl"), []byte(`{"prefix": "gt-", "path": "gastown/mayor/rig"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var dispatched []string
+	orig := dispatchIssueFn
+	dispatchIssueFn = func(townRoot, issueID, rig string) error {
+		if issueID == "gt-first" {
+			return errors.New("sling failed")
+		}
+		dispatched = append(dispatched, issueID)
+		return nil
+	}
+	t.Cleanup(func() { dispatchIssueFn = orig })
+
+	tracked := []trackedIssue{
+		{ID: "gt-first", Status: "open"},
+		{ID: "gt-second", Status: "open"},
+		{ID: "gt-third", Status: "open"},
+	}
+	logger := func(format string, args ...interface{}) {}
+
+	// With a budget of one, the failed first dispatch must not use it up.
+	if fed := feedReadyIssues(townRoot, "hq-cv-1", "test", tracked, 1, logger); fed != 1 {
+		t.Errorf("fed = %d, want 1", fed)
+	}
+	if len(dispatched) != 1 || dispatched[0] != "gt-second" {
+		t.Errorf("dispatched = %v, want [gt-second]", dispatched)
+	}
+}
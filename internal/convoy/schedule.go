@@ -0,0 +1,270 @@
+package convoy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxInFlightKey is the convoy description line holding its concurrency
+// limit, alongside Owner:, Notify: and Merge:.
+const maxInFlightKey = "MaxInFlight: "
+
+// Blocker is a convoy (or issue) that must land before a convoy starts.
+type Blocker struct {
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+}
+
+// Landed reports whether the blocker no longer holds its convoy back.
+func (b Blocker) Landed() bool {
+	return b.Status == "closed" || b.Status == "tombstone"
+}
+
+// ParseMaxInFlight extracts the per-convoy concurrency limit from a convoy
+// description. Returns 0 (unlimited) if not set or malformed.
+func ParseMaxInFlight(description string) int {
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, maxInFlightKey) {
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, maxInFlightKey)))
+			if err != nil || n < 0 {
+				return 0
+			}
+			return n
+		}
+	}
+	return 0
+}
+
+// SetMaxInFlight returns description with its concurrency limit set to n,
+// replacing any existing limit. n <= 0 removes the limit.
+func SetMaxInFlight(description string, n int) string {
+	value := ""
+	if n > 0 {
+		value = strconv.Itoa(n)
+	}
+	return setDescriptionField(description, maxInFlightKey, value)
+}
+
+// setDescriptionField returns description with its key line set to value,
+// replacing any existing line. An empty value removes the line.
+func setDescriptionField(description, key, value string) string {
+	var lines []string
+	for _, line := range strings.Split(description, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), key) {
+			lines = append(lines, line)
+		}
+	}
+	out := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if value != "" {
+		if out != "" {
+			out += "\n"
+		}
+		out += key + value
+	}
+	return out
+}
+
+// IsInFlight reports whether a tracked issue counts against its convoy's
+// concurrency limit: dispatched to a worker and not yet closed.
+func IsInFlight(status, assignee string) bool {
+	switch status {
+	case "in_progress", "hooked":
+		return true
+	case "open":
+		return assignee != ""
+	default:
+		return false
+	}
+}
+
+// Capacity returns how many more issues a convoy may dispatch given its
+// limit and the number already in flight. Returns -1 when unlimited.
+func Capacity(maxInFlight, inFlight int) int {
+	if maxInFlight <= 0 {
+		return -1
+	}
+	if inFlight >= maxInFlight {
+		return 0
+	}
+	return maxInFlight - inFlight
+}
+
+// feedBudget returns how many ready issues one feed may dispatch. Without a
+// limit the observer feeds one at a time, as each completion feeds the next;
+// with one it tops the convoy up to its limit.
+func feedBudget(tracked []trackedIssue, maxInFlight int) int {
+	inFlight := 0
+	for _, t := range tracked {
+		if IsInFlight(t.Status, t.Assignee) {
+			inFlight++
+		}
+	}
+	if c := Capacity(maxInFlight, inFlight); c >= 0 {
+		return c
+	}
+	return 1
+}
+
+// Pending returns the blockers that have not landed yet.
+func Pending(blockers []Blocker) []Blocker {
+	var pending []Blocker
+	for _, b := range blockers {
+		if !b.Landed() {
+			pending = append(pending, b)
+		}
+	}
+	return pending
+}
+
+// GetBlockers returns the convoys that must land before convoyID starts
+// (its outgoing 'blocks' dependencies). Returns nil if bd fails.
+func GetBlockers(townRoot, convoyID string) []Blocker {
+	return listBlocksDeps(townRoot, convoyID, "down")
+}
+
+// GetDependents returns the convoys waiting for convoyID to land.
+func GetDependents(townRoot, convoyID string) []Blocker {
+	return listBlocksDeps(townRoot, convoyID, "up")
+}
+
+func listBlocksDeps(townRoot, convoyID, direction string) []Blocker {
+	cmd := exec.Command("bd", "dep", "list", convoyID, "--direction="+direction, "--type=blocks", "--json")
+	cmd.Dir = townRoot
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+
+	var deps []struct {
+		Blocker
+		DependencyType string `json:"dependency_type"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
+		return nil
+	}
+	var blockers []Blocker
+	for _, d := range deps {
+		// bd filters by --type; double-check so a tracks relation can
+		// never gate a convoy.
+		if d.DependencyType != "" && d.DependencyType != "blocks" {
+			continue
+		}
+		d.ID = extractIssueID(d.ID)
+		blockers = append(blockers, d.Blocker)
+	}
+	return blockers
+}
+
+// getMaxInFlight reads a convoy's concurrency limit from its description.
+func getMaxInFlight(townRoot, convoyID string) int {
+	cmd := exec.Command("bd", "show", convoyID, "--json")
+	cmd.Dir = townRoot
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return 0
+	}
+
+	var results []struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) == 0 {
+		return 0
+	}
+	return ParseMaxInFlight(results[0].Description)
+}
+
+// Depths assigns each convoy in the graph its depth: 0 for convoys waiting
+// on nothing in the graph, otherwise one more than their deepest blocker.
+// blockedBy maps a convoy to the IDs it waits on; IDs that are not keys of
+// blockedBy (e.g., closed convoys left out of the graph) are ignored.
+// Returns an error naming the convoys on a cycle, if any.
+func Depths(blockedBy map[string][]string) (map[string]int, error) {
+	const (
+		visiting = -1
+		unset    = -2
+	)
+	depth := make(map[string]int, len(blockedBy))
+	for id := range blockedBy {
+		depth[id] = unset
+	}
+
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch depth[id] {
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if p == id {
+					start = i
+				}
+			}
+			return fmt.Errorf("dependency cycle: %s → %s", strings.Join(path[start:], " → "), id)
+		case unset:
+		default:
+			return nil
+		}
+		depth[id] = visiting
+		path = append(path, id)
+		d := 0
+		for _, b := range blockedBy[id] {
+			if _, ok := blockedBy[b]; !ok {
+				continue
+			}
+			if err := visit(b); err != nil {
+				return err
+			}
+			if depth[b]+1 > d {
+				d = depth[b] + 1
+			}
+		}
+		path = path[:len(path)-1]
+		depth[id] = d
+		return nil
+	}
+
+	// Visit in sorted order so the reported cycle is deterministic.
+	ids := make([]string, 0, len(blockedBy))
+	for id := range blockedBy {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return depth, nil
+}
+
+// WouldCycle reports whether making convoy wait on blocker would close a
+// dependency cycle, i.e., blocker already (transitively) waits on convoy.
+// blockersOf returns the IDs a convoy waits on.
+func WouldCycle(blockersOf func(id string) []string, convoy, blocker string) bool {
+	seen := make(map[string]bool)
+	stack := []string{blocker}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == convoy {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, blockersOf(id)...)
+	}
+	return false
+}
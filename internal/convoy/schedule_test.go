@@ -0,0 +1,122 @@
+package convoy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMaxInFlight(t *testing.T) {
+	tests := []struct {
+		desc string
+		want int
+	}{
+		{"Convoy tracking 3 issues\nOwner: mayor/\nMaxInFlight: 2", 2},
+		{"Convoy tracking 3 issues\n  MaxInFlight: 5  ", 5},
+		{"Convoy tracking 3 issues", 0},
+		{"MaxInFlight: many", 0},
+		{"MaxInFlight: -1", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseMaxInFlight(tt.desc); got != tt.want {
+			t.Errorf("ParseMaxInFlight(%q) = %d, want %d", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestSetMaxInFlight(t *testing.T) {
+	desc := "Convoy tracking 3 issues\nOwner: mayor/"
+
+	set := SetMaxInFlight(desc, 2)
+	if set != desc+"\nMaxInFlight: 2" {
+		t.Errorf("set = %q", set)
+	}
+	replaced := SetMaxInFlight(set, 4)
+	if ParseMaxInFlight(replaced) != 4 || strings.Count(replaced, "MaxInFlight") != 1 {
+		t.Errorf("replaced = %q", replaced)
+	}
+	if cleared := SetMaxInFlight(replaced, 0); cleared != desc {
+		t.Errorf("cleared = %q, want %q", cleared, desc)
+	}
+	if got := SetMaxInFlight("", 3); got != "MaxInFlight: 3" {
+		t.Errorf("empty description = %q", got)
+	}
+}
+
+func TestFeedBudget(t *testing.T) {
+	tracked := []trackedIssue{
+		{ID: "gt-1", Status: "closed"},
+		{ID: "gt-2", Status: "in_progress", Assignee: "gastown/polecats/alpha"},
+		{ID: "gt-3", Status: "open", Assignee: "gastown/polecats/beta"}, // slung, not started
+		{ID: "gt-4", Status: "open"},
+		{ID: "gt-5", Status: "open"},
+	}
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, 1}, // unlimited: one at a time
+		{1, 0}, // over the limit
+		{2, 0}, // at the limit
+		{4, 2},
+	}
+	for _, tt := range tests {
+		if got := feedBudget(tracked, tt.limit); got != tt.want {
+			t.Errorf("feedBudget(limit=%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	blockers := []Blocker{
+		{ID: "hq-cv-a", Status: "closed"},
+		{ID: "hq-cv-b", Status: "open"},
+		{ID: "hq-cv-c", Status: "tombstone"},
+	}
+	pending := Pending(blockers)
+	if len(pending) != 1 || pending[0].ID != "hq-cv-b" {
+		t.Errorf("Pending = %+v, want [hq-cv-b]", pending)
+	}
+}
+
+func TestDepths(t *testing.T) {
+	graph := map[string][]string{
+		"hq-cv-schema":   nil,
+		"hq-cv-api":      {"hq-cv-schema"},
+		"hq-cv-frontend": {"hq-cv-api", "hq-cv-schema"},
+		"hq-cv-docs":     {"hq-cv-landed"}, // blocker outside the graph
+	}
+	depths, err := Depths(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"hq-cv-schema": 0, "hq-cv-api": 1, "hq-cv-frontend": 2, "hq-cv-docs": 0}
+	for id, d := range want {
+		if depths[id] != d {
+			t.Errorf("depth(%s) = %d, want %d", id, depths[id], d)
+		}
+	}
+
+	graph["hq-cv-schema"] = []string{"hq-cv-frontend"}
+	_, err = Depths(graph)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Depths with cycle: err = %v, want cycle", err)
+	}
+}
+
+func TestWouldCycle(t *testing.T) {
+	graph := map[string][]string{
+		"b": {"a"},
+		"c": {"b"},
+	}
+	blockersOf := func(id string) []string { return graph[id] }
+	if !WouldCycle(blockersOf, "a", "c") {
+		t.Error("a after c should cycle (c waits on b waits on a)")
+	}
+	if !WouldCycle(blockersOf, "a", "a") {
+		t.Error("a convoy waiting on itself should cycle")
+	}
+	if WouldCycle(blockersOf, "c", "a") {
+		t.Error("c after a should not cycle")
+	}
+}
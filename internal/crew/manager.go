@@ -16,6 +16,8 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
@@ -222,6 +224,12 @@ func (m *Manager) addLocked(name string, createBranch bool) (*CrewWorker, error)
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file-mode secrets scoped to crew (e.g., .env).
+	if _, err := secrets.Materialize(filepath.Dir(m.rig.Path), m.rig.Path, "crew", crewPath); err != nil {
+		// Non-fatal - log warning but continue
+		fmt.Printf("Warning: could not materialize secrets: %v\n", err)
+	}
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(crewPath); err != nil {
 		// Non-fatal - log warning but continue
@@ -335,6 +343,13 @@ func (m *Manager) Remove(name string, force bool) error {
 		}
 	}
 
+	// Scrub materialized secrets first so a failed removal leaves none behind
+	if err := secrets.Scrub(crewPath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	_ = secrets.ScrubEnvFile(secrets.EnvFilePath(crewPath))
+	_ = sandbox.Release(crewPath)
+
 	// Remove directory
 	if err := os.RemoveAll(crewPath); err != nil {
 		return fmt.Errorf("removing crew dir: %w", err)
@@ -656,6 +671,30 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		claudeCmd = strings.Replace(claudeCmd, " --dangerously-skip-permissions", "", 1)
 	}
 
+	// Source env-mode secrets scoped to crew from a 0600 file so values stay
+	// out of the command line.
+	if secs, err := secrets.Resolve(townRoot, m.rig.Path, "crew"); err != nil {
+		fmt.Printf("Warning: could not resolve secrets: %v\n", err)
+	} else {
+		envFile := secrets.EnvFilePath(worker.ClonePath)
+		if wrote, err := secrets.WriteEnvFile(envFile, secs); err != nil {
+			fmt.Printf("Warning: could not write secrets env file: %v\n", err)
+		} else if wrote {
+			claudeCmd = secrets.SourceEnvFile(claudeCmd, envFile)
+		}
+	}
+
+	// Apply the rig's sandbox profile for crew, if configured.
+	claudeCmd, err = session.ApplySandbox(sandbox.Paths{
+		TownRoot: townRoot,
+		RigPath:  m.rig.Path,
+		AgentDir: worker.ClonePath,
+		WorkDir:  worker.ClonePath,
+	}, "crew", sessionID, claudeCmd)
+	if err != nil {
+		return err
+	}
+
 	// Create session with command and env vars via -e flags.
 	// The -e flags set session-level env BEFORE the shell starts, ensuring the
 	// initial shell inherits the correct GT_ROLE (not the parent's).
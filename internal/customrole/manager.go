@@ -0,0 +1,243 @@
+// Package customrole manages the sessions of user-defined agent roles.
+//
+// A custom role is defined by <town>/roles/<role>.toml (see
+// config.LoadRoleDefinition). Rig-scoped roles run one agent per rig, like
+// the witness; town-scoped roles run a single agent for the town, like the
+// deacon.
+package customrole
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Common errors
+var (
+	ErrNotRunning     = errors.New("custom role agent not running")
+	ErrAlreadyRunning = errors.New("custom role agent already running")
+)
+
+// Manager handles the session lifecycle of one custom role agent.
+// ZFC-compliant: tmux session is the source of truth for running state.
+type Manager struct {
+	townRoot string
+	def      *config.RoleDefinition
+	rigName  string // Empty for town-scoped roles
+}
+
+// NewManager creates a manager for a custom role's agent. rigName is ignored
+// for town-scoped roles.
+func NewManager(townRoot string, def *config.RoleDefinition, rigName string) *Manager {
+	if !def.IsRigScoped() {
+		rigName = ""
+	}
+	return &Manager{townRoot: townRoot, def: def, rigName: rigName}
+}
+
+// Load loads the named custom role and returns a manager for its agent.
+func Load(townRoot, role, rigName string) (*Manager, error) {
+	if !config.IsCustomRole(townRoot, role) {
+		return nil, fmt.Errorf("%q is not a custom role", role)
+	}
+	rigPath := ""
+	if rigName != "" {
+		rigPath = filepath.Join(townRoot, rigName)
+	}
+	def, err := config.LoadRoleDefinition(townRoot, rigPath, role)
+	if err != nil {
+		return nil, err
+	}
+	if def.IsRigScoped() && rigName == "" {
+		return nil, fmt.Errorf("role %s is rig-scoped: specify a rig (<rig>/%s)", role, role)
+	}
+	return NewManager(townRoot, def, rigName), nil
+}
+
+// Role returns the role definition.
+func (m *Manager) Role() *config.RoleDefinition {
+	return m.def
+}
+
+// RigName returns the agent's rig, or "" for town-scoped roles.
+func (m *Manager) RigName() string {
+	return m.rigName
+}
+
+// SessionName returns the tmux session name of the agent.
+func (m *Manager) SessionName() string {
+	return m.def.SessionName(m.rigName, session.PrefixFor(m.rigName))
+}
+
+// Address returns the agent's mail address.
+func (m *Manager) Address() string {
+	return m.def.MailAddress(m.rigName)
+}
+
+// WorkDir returns the agent's working directory.
+func (m *Manager) WorkDir() string {
+	return m.def.WorkDir(m.townRoot, m.rigName)
+}
+
+// AgentBeadID returns the ID of the agent's bead: <prefix>-<rig>-<role> in
+// rig beads, or hq-<role> in town beads.
+func (m *Manager) AgentBeadID() string {
+	if m.rigName == "" {
+		return beads.AgentBeadIDWithPrefix(beads.TownBeadsPrefix, "", m.def.Role, "")
+	}
+	return beads.AgentBeadIDWithPrefix(beads.GetPrefixForRig(m.townRoot, m.rigName), m.rigName, m.def.Role, "")
+}
+
+// IsRunning checks if the agent's session is active.
+func (m *Manager) IsRunning() (bool, error) {
+	return tmux.NewTmux().HasSession(m.SessionName())
+}
+
+// Start starts the agent in a tmux session, replacing a zombie session
+// (tmux alive, agent dead). agentOverride optionally selects a different
+// agent alias.
+func (m *Manager) Start(agentOverride string) error {
+	t := tmux.NewTmux()
+	sessionID := m.SessionName()
+
+	running, _ := t.HasSession(sessionID)
+	if running {
+		if t.IsAgentAlive(sessionID) {
+			return ErrAlreadyRunning
+		}
+		if err := t.KillSessionWithProcesses(sessionID); err != nil {
+			return fmt.Errorf("killing zombie session: %w", err)
+		}
+	}
+
+	workDir := m.WorkDir()
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return fmt.Errorf("creating %s work dir: %w", m.def.Role, err)
+	}
+
+	// Agent bead is best-effort: the session works without it, but mail
+	// routing and sling need it to address the agent.
+	if err := m.EnsureAgentBead(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s agent bead: %v\n", m.def.Role, err)
+	}
+
+	rigPath := ""
+	theme := tmux.DeaconTheme()
+	if m.rigName != "" {
+		rigPath = filepath.Join(m.townRoot, m.rigName)
+		theme = tmux.AssignTheme(m.rigName)
+	}
+	extraEnv := make(map[string]string, len(m.def.Env))
+	for k, v := range m.def.Env {
+		extraEnv[k] = config.ExpandPattern(v, m.townRoot, m.rigName, "", m.def.Role)
+	}
+
+	cfg := session.SessionConfig{
+		SessionID: sessionID,
+		WorkDir:   workDir,
+		Role:      m.def.Role,
+		TownRoot:  m.townRoot,
+		RigPath:   rigPath,
+		RigName:   m.rigName,
+		Beacon: session.BeaconConfig{
+			Recipient: m.Address(),
+			Sender:    "deacon",
+			Topic:     "patrol",
+		},
+		Instructions:   m.instructions(),
+		AgentOverride:  agentOverride,
+		ExtraEnv:       extraEnv,
+		Theme:          &theme,
+		WaitForAgent:   true,
+		WaitFatal:      true,
+		AcceptBypass:   true,
+		VerifySurvived: true,
+		TrackPID:       true,
+	}
+	if m.def.Session.StartCommand != "" && agentOverride == "" {
+		cfg.Command = config.ExpandPattern(m.def.Session.StartCommand, m.townRoot, m.rigName, "", m.def.Role)
+	}
+	_, err := session.StartSession(t, cfg)
+	return err
+}
+
+// instructions returns the startup instructions for the agent.
+func (m *Manager) instructions() string {
+	if m.def.Nudge != "" {
+		return m.def.Nudge
+	}
+	if m.def.PatrolFormula != "" {
+		return "Run `gt prime --hook` and begin patrol."
+	}
+	return "Run `gt prime --hook` and check your mail."
+}
+
+// Stop stops the agent's session.
+func (m *Manager) Stop() error {
+	t := tmux.NewTmux()
+	sessionID := m.SessionName()
+	running, _ := t.HasSession(sessionID)
+	if !running {
+		return ErrNotRunning
+	}
+	return t.KillSessionWithProcesses(sessionID)
+}
+
+// EnsureAgentBead creates the agent's bead if it does not exist.
+func (m *Manager) EnsureAgentBead() error {
+	var bd *beads.Beads
+	if m.rigName == "" {
+		bd = beads.New(beads.GetTownBeadsPath(m.townRoot))
+	} else {
+		rigPath := filepath.Join(m.townRoot, m.rigName)
+		bd = beads.NewWithBeadsDir(rigPath, beads.ResolveBeadsDir(rigPath))
+	}
+	id := m.AgentBeadID()
+	if _, err := bd.Show(id); err == nil {
+		return nil
+	}
+	desc := m.def.Description
+	if desc == "" {
+		desc = fmt.Sprintf("Custom %s agent", m.def.Role)
+	}
+	if m.rigName != "" {
+		desc = fmt.Sprintf("%s for %s", desc, m.rigName)
+	}
+	_, err := bd.CreateAgentBead(id, desc, &beads.AgentFields{
+		RoleType:   m.def.Role,
+		Rig:        m.rigName,
+		AgentState: "idle",
+	})
+	return err
+}
+
+// ForTown returns managers for every instance of the town's custom roles:
+// one per town-scoped role, and one per rig in rigs for each rig-scoped role.
+// Role files that fail to load are returned in errs, keyed by role name.
+func ForTown(townRoot string, rigs []string) (managers []*Manager, errs map[string]error) {
+	defs, errs := config.LoadCustomRoles(townRoot)
+	for _, def := range defs {
+		if !def.IsRigScoped() {
+			managers = append(managers, NewManager(townRoot, def, ""))
+			continue
+		}
+		for _, rigName := range rigs {
+			rigDef, err := config.LoadRoleDefinition(townRoot, filepath.Join(townRoot, rigName), def.Role)
+			if err != nil {
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[rigName+"/"+def.Role] = err
+				continue
+			}
+			managers = append(managers, NewManager(townRoot, rigDef, rigName))
+		}
+	}
+	return managers, errs
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/backup"
+)
+
+const (
+	defaultBackupInterval = 24 * time.Hour
+	defaultBackupKeep     = 7
+)
+
+// backupInterval returns the configured backup interval, or the default (24h).
+func backupInterval(config *DaemonPatrolConfig) time.Duration {
+	if config != nil && config.Patrols != nil && config.Patrols.Backup != nil {
+		if config.Patrols.Backup.Interval > 0 {
+			return config.Patrols.Backup.Interval
+		}
+	}
+	return defaultBackupInterval
+}
+
+// runScheduledBackup writes a town backup and prunes old ones.
+// Non-fatal: errors are logged but don't stop the patrol.
+func (d *Daemon) runScheduledBackup() {
+	if !IsPatrolEnabled(d.patrolConfig, "backup") {
+		return
+	}
+	config := d.patrolConfig.Patrols.Backup
+
+	passphrase, err := readBackupPassphrase(config.PassphraseFile)
+	if err != nil {
+		d.logger.Printf("backup: %v", err)
+		return
+	}
+	dir := config.Dir
+	if dir == "" {
+		dir = backup.DefaultDir(d.config.TownRoot)
+	}
+
+	archive, manifest, err := backup.Create(d.config.TownRoot, backup.CreateOptions{
+		Dir:        dir,
+		Passphrase: passphrase,
+	})
+	if err != nil {
+		d.logger.Printf("backup: failed: %v", err)
+		return
+	}
+	d.logger.Printf("backup: wrote %s (%d files, %d database(s))",
+		archive.Path, len(manifest.Files), len(manifest.Databases))
+	for _, w := range manifest.Warnings {
+		d.logger.Printf("backup: warning: %s", w)
+	}
+
+	keep := config.Keep
+	if keep <= 0 {
+		keep = defaultBackupKeep
+	}
+	removed, err := backup.Prune(dir, backup.Retention{Keep: keep, MaxAge: config.MaxAge}, time.Now())
+	if err != nil {
+		d.logger.Printf("backup: pruning: %v", err)
+	}
+	if len(removed) > 0 {
+		d.logger.Printf("backup: pruned %d old backup(s)", len(removed))
+	}
+}
+
+// readBackupPassphrase reads a passphrase file; an empty path means no
+// encryption.
+func readBackupPassphrase(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from daemon config
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase file: %w", err)
+	}
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase file %s is empty", path)
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	gitpkg "github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// predictConflicts re-runs conflict prediction for every rig whose saved
+// report is older than its interval, and mails the polecats on both sides
+// of each newly overlapping pair of branches, plus a summary to the Mayor.
+// state.ConflictAlerts remembers what was announced so each overlap is
+// announced once.
+func (d *Daemon) predictConflicts(state *State) {
+	now := time.Now()
+	for _, rigName := range d.getKnownRigs() {
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+		var cfg *config.ConflictPredictionConfig
+		if err == nil {
+			cfg = settings.ConflictPrediction
+		}
+		if !cfg.Enabled() {
+			continue
+		}
+		if prev, err := refinery.LoadConflictReport(rigPath); err == nil && prev != nil &&
+			now.Sub(prev.GeneratedAt) < cfg.IntervalDuration() {
+			continue
+		}
+
+		r := &rig.Rig{Name: rigName, Path: rigPath}
+		polecats, err := polecat.NewManager(r, gitpkg.NewGit(rigPath), nil).List()
+		if err != nil {
+			d.logger.Printf("Conflict prediction %s: listing polecats failed: %v", rigName, err)
+			continue
+		}
+		live := make(map[string]bool)
+		var branches []refinery.ConflictBranch
+		for _, p := range polecats {
+			if p.State == polecat.StateDone || p.State == polecat.StateZombie || p.Branch == "" {
+				continue
+			}
+			live[p.Name] = true
+			branches = append(branches, refinery.ConflictBranch{Branch: p.Branch, Worker: p.Name})
+		}
+
+		eng := refinery.NewEngineer(r)
+		if err := eng.LoadConfig(); err != nil {
+			d.logger.Printf("Conflict prediction %s: loading merge queue config failed: %v", rigName, err)
+			continue
+		}
+		eng.SetOutput(io.Discard)
+		report, err := eng.PredictConflicts(branches)
+		if err != nil {
+			d.logger.Printf("Conflict prediction %s: %v", rigName, err)
+			continue
+		}
+		if err := refinery.SaveConflictReport(rigPath, report); err != nil {
+			d.logger.Printf("Conflict prediction %s: saving report failed: %v", rigName, err)
+		}
+
+		alerts, next := conflictAlertsDue(state.ConflictAlerts, rigName, report.Overlaps)
+		state.ConflictAlerts = next
+		if len(alerts) == 0 || (cfg != nil && cfg.Quiet) {
+			continue
+		}
+		d.logger.Printf("Conflict prediction %s: %d new overlap(s)", rigName, len(alerts))
+		if !d.announceConflicts(rigName, report, alerts, live) {
+			// Retry on the next prediction.
+			for _, o := range alerts {
+				delete(state.ConflictAlerts, conflictAlertKey(rigName, o))
+			}
+		}
+	}
+}
+
+// conflictAlertKey identifies an overlapping pair of branches in
+// state.ConflictAlerts.
+func conflictAlertKey(rigName string, o refinery.Overlap) string {
+	return rigName + "/" + o.A + " " + o.B
+}
+
+// conflictAlertsDue returns the rig's hunk-level overlaps to announce, given
+// the alert record for all rigs, along with the record to keep. An overlap is
+// announced when it appears and again if the files it covers change; one
+// that goes away is forgotten, so a later overlap announces afresh. Other
+// rigs' records are kept as they are.
+func conflictAlertsDue(prev map[string]string, rigName string, overlaps []refinery.Overlap) ([]refinery.Overlap, map[string]string) {
+	next := make(map[string]string)
+	for key, files := range prev {
+		if !strings.HasPrefix(key, rigName+"/") {
+			next[key] = files
+		}
+	}
+	var alerts []refinery.Overlap
+	for _, o := range overlaps {
+		if o.Level != refinery.OverlapHunk {
+			continue
+		}
+		key := conflictAlertKey(rigName, o)
+		files := strings.Join(o.Hunks, ",")
+		if prev[key] != files {
+			alerts = append(alerts, o)
+		}
+		next[key] = files
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return conflictAlertKey(rigName, alerts[i]) < conflictAlertKey(rigName, alerts[j])
+	})
+	if len(next) == 0 {
+		next = nil
+	}
+	return alerts, next
+}
+
+// announceConflicts mails each live polecat involved in the new overlaps
+// and the Mayor. Reports whether the Mayor's summary was sent.
+func (d *Daemon) announceConflicts(rigName string, report *refinery.ConflictReport, alerts []refinery.Overlap, live map[string]bool) bool {
+	workers := make(map[string]string)
+	for _, b := range report.Branches {
+		if live[b.Worker] {
+			workers[b.Branch] = b.Worker
+		}
+	}
+
+	// One message per polecat, listing every branch it now collides with.
+	notes := make(map[string][]string)
+	var summary []string
+	for _, o := range alerts {
+		files := strings.Join(o.Hunks, ", ")
+		summary = append(summary, fmt.Sprintf("- %s ↔ %s: %s", o.A, o.B, files))
+		if w := workers[o.A]; w != "" {
+			notes[w] = append(notes[w], conflictNote(report.Target, o.A, o.B, files))
+		}
+		if w := workers[o.B]; w != "" {
+			notes[w] = append(notes[w], conflictNote(report.Target, o.B, o.A, files))
+		}
+	}
+
+	names := make([]string, 0, len(notes))
+	for name := range notes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		body := strings.Join(notes[name], "\n") +
+			fmt.Sprintf("\n\nRebase early or coordinate to keep the merge clean.\nInspect with: gt mq conflicts %s", rigName)
+		d.sendConflictMail(rigName+"/"+name, "Predicted merge conflict on your branch", body)
+	}
+
+	body := fmt.Sprintf("In-flight branches in %s now change the same lines:\n\n%s\n\nInspect with: gt mq conflicts %s",
+		rigName, strings.Join(summary, "\n"), rigName)
+	return d.sendConflictMail("mayor/", fmt.Sprintf("Predicted merge conflicts in %s", rigName), body)
+}
+
+// conflictNote describes an overlap from branch's side.
+func conflictNote(target, branch, other, files string) string {
+	if other == target {
+		return fmt.Sprintf("- %s and %s (changed since you forked) both change: %s", branch, target, files)
+	}
+	return fmt.Sprintf("- %s and %s both change: %s", branch, other, files)
+}
+
+// sendConflictMail sends a conflict warning with gt mail send. Reports
+// whether it was sent.
+func (d *Daemon) sendConflictMail(to, subject, body string) bool {
+	cmd := exec.Command(d.gtPath, "mail", "send", to, "-s", subject, "-m", body) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		d.logger.Printf("Conflict prediction: mailing %s failed: %v (%s)", to, err, string(out))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func TestConflictAlertsDue(t *testing.T) {
+	prev := map[string]string{
+		"gp/polecat/nux polecat/toast":  "a.go",     // already announced
+		"gp/polecat/nux polecat/slit":   "a.go",     // now covers more files
+		"gp/polecat/ace polecat/toast":  "b.go",     // resolved: forget
+		"gp2/polecat/nux polecat/toast": "other.go", // another rig: keep
+	}
+	overlaps := []refinery.Overlap{
+		{A: "polecat/nux", B: "polecat/toast", Level: refinery.OverlapHunk, Hunks: []string{"a.go"}},
+		{A: "polecat/nux", B: "polecat/slit", Level: refinery.OverlapHunk, Hunks: []string{"a.go", "c.go"}},
+		{A: "polecat/nux", B: "origin/main", Level: refinery.OverlapHunk, Hunks: []string{"d.go"}},
+		{A: "polecat/ace", B: "polecat/slit", Level: refinery.OverlapFile, Files: []string{"e.go"}},
+	}
+
+	alerts, next := conflictAlertsDue(prev, "gp", overlaps)
+	var got []string
+	for _, o := range alerts {
+		got = append(got, o.A+" "+o.B)
+	}
+	if want := []string{"polecat/nux origin/main", "polecat/nux polecat/slit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+	wantNext := map[string]string{
+		"gp/polecat/nux polecat/toast":  "a.go",
+		"gp/polecat/nux polecat/slit":   "a.go,c.go",
+		"gp/polecat/nux origin/main":    "d.go",
+		"gp2/polecat/nux polecat/toast": "other.go",
+	}
+	if !reflect.DeepEqual(next, wantNext) {
+		t.Errorf("next = %v, want %v", next, wantNext)
+	}
+
+	// No overlaps anywhere leaves no record behind in state.json.
+	if alerts, next := conflictAlertsDue(map[string]string{"gp/a b": "x"}, "gp", nil); alerts != nil || next != nil {
+		t.Errorf("empty: alerts = %v, next = %v", alerts, next)
+	}
+}
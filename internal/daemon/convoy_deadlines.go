@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/convoy"
+)
+
+// checkConvoyDeadlines forecasts every open convoy that has a deadline and
+// escalates, through the town's escalation routes, each convoy whose
+// forecast has slipped past its deadline (at risk) or whose deadline has
+// passed with work remaining (overdue). state.ConvoyAlerts remembers what
+// was escalated so each slip alerts once.
+func (d *Daemon) checkConvoyDeadlines(state *State) {
+	cmd := exec.Command(d.bdPath, "list", "--type=convoy", "--status=open", "--json") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ()
+	output, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Convoy deadlines: listing convoys failed: %v", err)
+		return
+	}
+	var convoys []struct {
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(output, &convoys); err != nil {
+		d.logger.Printf("Convoy deadlines: parsing convoy list failed: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var ct *convoy.CycleTimes
+	statuses := make(map[string]convoy.SLAStatus)
+	forecasts := make(map[string]convoy.Forecast)
+	titles := make(map[string]string)
+	for _, c := range convoys {
+		if convoy.ParseDeadline(c.Description).IsZero() {
+			continue
+		}
+		if ct == nil {
+			ct, err = convoy.LoadCycleTimes(d.config.TownRoot, now.Add(-convoy.CycleTimeWindow))
+			if err != nil {
+				d.logger.Printf("Convoy deadlines: reading events log failed: %v", err)
+				return
+			}
+		}
+		f := convoy.ForecastConvoy(d.config.TownRoot, c.ID, c.Description, ct, now)
+		statuses[c.ID] = f.SLA
+		forecasts[c.ID] = f
+		titles[c.ID] = c.Title
+	}
+
+	alerts, next := convoyAlertsDue(state.ConvoyAlerts, statuses)
+	state.ConvoyAlerts = next
+	for _, id := range alerts {
+		if !d.escalateConvoyDeadline(id, titles[id], forecasts[id]) {
+			// Retry on the next heartbeat.
+			delete(state.ConvoyAlerts, id)
+		}
+	}
+}
+
+// convoyAlertsDue returns the convoys to escalate, given the SLA status each
+// was last escalated for and their current statuses, along with the alert
+// record to keep. A convoy alerts when it starts slipping and again if it
+// goes from at risk to overdue; one that recovers, lands or loses its
+// deadline is forgotten, so a later slip alerts afresh.
+func convoyAlertsDue(prev map[string]string, current map[string]convoy.SLAStatus) ([]string, map[string]string) {
+	var alerts []string
+	next := make(map[string]string)
+	for id, status := range current {
+		if !status.Slipping() {
+			continue
+		}
+		if prev[id] != string(status) {
+			alerts = append(alerts, id)
+		}
+		next[id] = string(status)
+	}
+	sort.Strings(alerts)
+	if len(next) == 0 {
+		next = nil
+	}
+	return alerts, next
+}
+
+// escalateConvoyDeadline raises a convoy's slip with gt escalate, which
+// routes it by severity per the town's escalation config. Reports whether
+// the escalation was raised.
+func (d *Daemon) escalateConvoyDeadline(convoyID, title string, f convoy.Forecast) bool {
+	severity := config.SeverityMedium
+	summary := fmt.Sprintf("Convoy %s (%s) is forecast to miss its deadline", convoyID, title)
+	if f.SLA == convoy.SLAOverdue {
+		severity = config.SeverityHigh
+		summary = fmt.Sprintf("Convoy %s (%s) is past its deadline", convoyID, title)
+	}
+
+	reason := fmt.Sprintf("Deadline: %s\nRemaining: %d issue(s), %d in flight",
+		f.Deadline.Format(time.RFC3339), f.Remaining, f.InFlight)
+	if f.ETA != nil {
+		reason += fmt.Sprintf("\nETA: %s (based on %d merges)", f.ETA.Format(time.RFC3339), f.Samples)
+	}
+	reason += fmt.Sprintf("\n\nInspect with: gt convoy status %s", convoyID)
+
+	d.logger.Printf("Convoy deadlines: %s %s, escalating (%s)", convoyID, f.SLA, severity)
+	cmd := exec.Command(d.gtPath, "escalate", summary, //nolint:gosec // G204: args are constructed internally
+		"--severity", severity,
+		"--reason", reason,
+		"--source", "convoy:"+convoyID,
+		"--related", convoyID)
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		d.logger.Printf("Convoy deadlines: escalating %s failed: %v (%s)", convoyID, err, string(out))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/convoy"
+)
+
+func TestConvoyAlertsDue(t *testing.T) {
+	prev := map[string]string{
+		"hq-cv-known":     "at_risk", // already escalated, still at risk
+		"hq-cv-worse":     "at_risk", // now overdue: escalate again
+		"hq-cv-recovered": "at_risk", // back on track: forget
+		"hq-cv-landed":    "overdue", // no longer open: forget
+	}
+	current := map[string]convoy.SLAStatus{
+		"hq-cv-known":     convoy.SLAAtRisk,
+		"hq-cv-worse":     convoy.SLAOverdue,
+		"hq-cv-recovered": convoy.SLAOnTrack,
+		"hq-cv-new":       convoy.SLAAtRisk,
+		"hq-cv-unknown":   convoy.SLAUnknown,
+	}
+
+	alerts, next := convoyAlertsDue(prev, current)
+	if want := []string{"hq-cv-new", "hq-cv-worse"}; !reflect.DeepEqual(alerts, want) {
+		t.Errorf("alerts = %v, want %v", alerts, want)
+	}
+	wantNext := map[string]string{
+		"hq-cv-known": "at_risk",
+		"hq-cv-worse": "overdue",
+		"hq-cv-new":   "at_risk",
+	}
+	if !reflect.DeepEqual(next, wantNext) {
+		t.Errorf("next = %v, want %v", next, wantNext)
+	}
+
+	// Nothing slipping leaves no record behind in state.json.
+	if alerts, next := convoyAlertsDue(wantNext, nil); alerts != nil || next != nil {
+		t.Errorf("empty: alerts = %v, next = %v", alerts, next)
+	}
+}
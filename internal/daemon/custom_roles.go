@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/customrole"
+)
+
+// ensureCustomRolesRunning keeps the agents of keep_alive custom roles
+// running, like witnesses and refineries. Each role can be disabled or limited
+// to some rigs under patrols.roles.<role> in daemon.json.
+func (d *Daemon) ensureCustomRolesRunning() {
+	managers, errs := customrole.ForTown(d.config.TownRoot, d.getKnownRigs())
+	for role, err := range errs {
+		d.logger.Printf("Skipping custom role %s: %v", role, err)
+	}
+
+	rigsByRole := make(map[string]map[string]bool)
+	for _, mgr := range managers {
+		def := mgr.Role()
+		if !def.KeepAlive || !IsPatrolEnabled(d.patrolConfig, def.Role) {
+			continue
+		}
+		if rigName := mgr.RigName(); rigName != "" {
+			allowed, ok := rigsByRole[def.Role]
+			if !ok {
+				allowed = make(map[string]bool)
+				for _, r := range d.getPatrolRigs(def.Role) {
+					allowed[r] = true
+				}
+				rigsByRole[def.Role] = allowed
+			}
+			if !allowed[rigName] {
+				continue
+			}
+			if operational, reason := d.isRigOperational(rigName); !operational {
+				d.logger.Printf("Skipping %s auto-start for %s: %s", def.Role, rigName, reason)
+				continue
+			}
+		}
+		d.ensureCustomRoleRunning(mgr)
+	}
+}
+
+// ensureCustomRoleRunning starts one custom role agent if it is not running,
+// with the same restart backoff as the Deacon.
+func (d *Daemon) ensureCustomRoleRunning(mgr *customrole.Manager) {
+	agentID := mgr.Address()
+	if d.restartTracker != nil {
+		if d.restartTracker.IsInCrashLoop(agentID) {
+			d.logger.Printf("%s is in crash loop, skipping restart (use 'gt daemon clear-backoff %s' to reset)", agentID, agentID)
+			return
+		}
+		if !d.restartTracker.CanRestart(agentID) {
+			remaining := d.restartTracker.GetBackoffRemaining(agentID)
+			d.logger.Printf("%s restart in backoff, %s remaining", agentID, remaining.Round(time.Second))
+			return
+		}
+	}
+
+	if err := mgr.Start(""); err != nil {
+		if err == customrole.ErrAlreadyRunning {
+			if d.restartTracker != nil {
+				d.restartTracker.RecordSuccess(agentID)
+			}
+			return
+		}
+		d.logger.Printf("Error starting %s: %v", agentID, err)
+		return
+	}
+
+	if d.restartTracker != nil {
+		d.restartTracker.RecordRestart(agentID)
+		if err := d.restartTracker.Save(); err != nil {
+			d.logger.Printf("Warning: failed to save restart state: %v", err)
+		}
+	}
+	d.logger.Printf("%s session started successfully", agentID)
+}
@@ -29,9 +29,11 @@ import (
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/webhook"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
 )
@@ -51,6 +53,7 @@ type Daemon struct {
 	convoyWatcher *ConvoyWatcher
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
+	webhooks      *webhook.Dispatcher
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -245,6 +248,14 @@ func (d *Daemon) Run() error {
 		}
 	}
 
+	// Start webhook dispatcher to deliver events to outbound subscriptions
+	d.webhooks = webhook.NewDispatcher(d.config.TownRoot, d.logger.Printf)
+	if err := d.webhooks.Start(); err != nil {
+		d.logger.Printf("Warning: failed to start webhook dispatcher: %v", err)
+	} else {
+		d.logger.Println("Webhook dispatcher started")
+	}
+
 	// Start dedicated Dolt health check ticker if Dolt server is configured.
 	// This runs at a much higher frequency (default 30s) than the general
 	// heartbeat (3 min) so Dolt crashes are detected quickly.
@@ -271,6 +282,17 @@ func (d *Daemon) Run() error {
 		d.logger.Printf("Dolt remotes push ticker started (interval %v)", interval)
 	}
 
+	// Start scheduled backup ticker if configured (opt-in, default daily).
+	var backupTicker *time.Ticker
+	var backupChan <-chan time.Time
+	if IsPatrolEnabled(d.patrolConfig, "backup") {
+		interval := backupInterval(d.patrolConfig)
+		backupTicker = time.NewTicker(interval)
+		backupChan = backupTicker.C
+		defer backupTicker.Stop()
+		d.logger.Printf("Backup ticker started (interval %v)", interval)
+	}
+
 	// Note: PATCH-010 uses per-session hooks in deacon/manager.go (SetAutoRespawnHook).
 	// Global pane-died hooks don't fire reliably in tmux 3.2a, so we rely on the
 	// per-session approach which has been tested to work for continuous recovery.
@@ -308,6 +330,12 @@ func (d *Daemon) Run() error {
 				d.pushDoltRemotes()
 			}
 
+		case <-backupChan:
+			// Scheduled town backup with retention pruning.
+			if !d.isShutdownInProgress() {
+				d.runScheduledBackup()
+			}
+
 		case <-timer.C:
 			d.heartbeat(state)
 
@@ -394,6 +422,9 @@ func (d *Daemon) heartbeat(state *State) {
 	// 6. Ensure Mayor is running (restart if dead)
 	d.ensureMayorRunning()
 
+	// 6b. Ensure keep_alive custom role agents are running (restart if dead)
+	d.ensureCustomRolesRunning()
+
 	// 7. Trigger pending polecat spawns (bootstrap mode - ZFC violation acceptable)
 	// This ensures polecats get nudged even when Deacon isn't in a patrol cycle.
 	// Uses regex-based WaitForRuntimeReady, which is acceptable for daemon bootstrap.
@@ -425,6 +456,21 @@ func (d *Daemon) heartbeat(state *State) {
 	// branches persist indefinitely. This cleans them up periodically.
 	d.pruneStaleBranches()
 
+	// 14. Record sandbox limit hits and clean up cgroups of ended sessions.
+	d.checkSandboxViolations()
+
+	// 15. Top up polecat warm pools and re-sync entries behind the default branch.
+	d.fillWarmPools()
+
+	// 16. Forecast convoys with deadlines and escalate those slipping past them.
+	d.checkConvoyDeadlines(state)
+
+	// 17. Snapshot dirty polecat worktrees to hidden WIP refs and prune old ones.
+	d.snapshotWIP()
+
+	// 18. Predict merge conflicts between in-flight branches and warn those involved.
+	d.predictConflicts(state)
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -435,6 +481,44 @@ func (d *Daemon) heartbeat(state *State) {
 	d.logger.Printf("Heartbeat complete (#%d)", state.HeartbeatCount)
 }
 
+// checkSandboxViolations records cgroup limit hits (OOM kills, memory.max
+// and pids.max rejections) of sandboxed agent sessions as events.
+func (d *Daemon) checkSandboxViolations() {
+	for _, report := range sandbox.Scan(d.config.TownRoot) {
+		for _, v := range report.Violations {
+			d.logger.Printf("Sandbox violation: %s hit %s (%d)", report.Session, v.Kind, v.Count)
+			_ = events.LogFeed(events.TypeSandboxViolation, "daemon",
+				events.SandboxViolationPayload(report.Session, report.Role, v.Kind, v.Count))
+		}
+	}
+}
+
+// fillWarmPools tops up the warm worktree pool of every rig that configures
+// one. Fills run in the background since the rig's setup command can be slow;
+// a fill still running from an earlier heartbeat makes the next one a no-op.
+func (d *Daemon) fillWarmPools() {
+	for _, rigName := range d.getKnownRigs() {
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+		if err != nil || settings.WarmPool == nil || settings.WarmPool.Size <= 0 {
+			continue
+		}
+		r := &rig.Rig{Name: rigName, Path: rigPath}
+		mgr := polecat.NewManager(r, gitpkg.NewGit(rigPath), nil)
+		go func(rigName string) {
+			result, err := mgr.FillWarmPool()
+			if err != nil {
+				d.logger.Printf("Warm pool %s: fill failed: %v", rigName, err)
+				return
+			}
+			if result.Created+result.Refreshed+result.Discarded > 0 {
+				d.logger.Printf("Warm pool %s: created %d, refreshed %d, discarded %d",
+					rigName, result.Created, result.Refreshed, result.Discarded)
+			}
+		}(rigName)
+	}
+}
+
 // ensureDoltServerRunning ensures the Dolt SQL server is running if configured.
 // This provides the backend for beads database access in server mode.
 func (d *Daemon) ensureDoltServerRunning() {
@@ -1039,6 +1123,12 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop webhook dispatcher (pending retries persist in its state file)
+	if d.webhooks != nil {
+		d.webhooks.Stop()
+		d.logger.Println("Webhook dispatcher stopped")
+	}
+
 	// Stop Dolt server if we're managing it
 	if d.doltServer != nil && d.doltServer.IsEnabled() && !d.doltServer.IsExternal() {
 		if err := d.doltServer.Stop(); err != nil {
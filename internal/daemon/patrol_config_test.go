@@ -79,6 +79,54 @@ func TestIsPatrolEnabled_DoltRemotes(t *testing.T) {
 	}
 }
 
+func TestIsPatrolEnabled_Backup(t *testing.T) {
+	// backup is opt-in like dolt_remotes
+	if IsPatrolEnabled(nil, "backup") {
+		t.Error("expected backup to be disabled with nil config")
+	}
+	config := &DaemonPatrolConfig{
+		Patrols: &PatrolsConfig{},
+	}
+	if IsPatrolEnabled(config, "backup") {
+		t.Error("expected backup to be disabled by default")
+	}
+	config.Patrols.Backup = &BackupConfig{Enabled: true}
+	if !IsPatrolEnabled(config, "backup") {
+		t.Error("expected backup to be enabled when configured")
+	}
+	if got := backupInterval(config); got != defaultBackupInterval {
+		t.Errorf("backupInterval = %v, want %v", got, defaultBackupInterval)
+	}
+}
+
+func TestIsPatrolEnabled_CustomRole(t *testing.T) {
+	config := &DaemonPatrolConfig{
+		Patrols: &PatrolsConfig{
+			Roles: map[string]*PatrolConfig{
+				"reviewer": {Enabled: true, Rigs: []string{"gastown"}},
+				"triage":   {Enabled: false},
+			},
+		},
+	}
+
+	if !IsPatrolEnabled(config, "reviewer") {
+		t.Error("expected reviewer to be enabled")
+	}
+	if IsPatrolEnabled(config, "triage") {
+		t.Error("expected triage to be disabled")
+	}
+	if !IsPatrolEnabled(config, "auditor") {
+		t.Error("expected unconfigured custom role to be enabled (default)")
+	}
+
+	if rigs := GetPatrolRigs(config, "reviewer"); len(rigs) != 1 || rigs[0] != "gastown" {
+		t.Errorf("GetPatrolRigs(reviewer) = %v, want [gastown]", rigs)
+	}
+	if rigs := GetPatrolRigs(config, "auditor"); rigs != nil {
+		t.Errorf("GetPatrolRigs(auditor) = %v, want nil (all rigs)", rigs)
+	}
+}
+
 func TestDoltRemotesInterval(t *testing.T) {
 	// Default interval
 	if got := doltRemotesInterval(nil); got != defaultDoltRemotesInterval {
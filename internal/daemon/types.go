@@ -59,6 +59,16 @@ type State struct {
 
 	// HeartbeatCount is how many heartbeats have completed.
 	HeartbeatCount int64 `json:"heartbeat_count"`
+
+	// ConvoyAlerts records the SLA status each slipping convoy was last
+	// escalated for, so an alert fires once per slip rather than every
+	// heartbeat.
+	ConvoyAlerts map[string]string `json:"convoy_alerts,omitempty"`
+
+	// ConflictAlerts records, per rig and pair of branches, the files whose
+	// predicted conflict was last announced, so each overlap is announced
+	// once rather than every prediction.
+	ConflictAlerts map[string]string `json:"conflict_alerts,omitempty"`
 }
 
 // StateFile returns the path to the state file.
@@ -118,6 +128,10 @@ type PatrolsConfig struct {
 	Deacon      *PatrolConfig      `json:"deacon,omitempty"`
 	DoltServer  *DoltServerConfig  `json:"dolt_server,omitempty"`
 	DoltRemotes *DoltRemotesConfig `json:"dolt_remotes,omitempty"`
+	Backup      *BackupConfig      `json:"backup,omitempty"`
+
+	// Roles configures the keep-alive patrols of custom roles, keyed by role name.
+	Roles map[string]*PatrolConfig `json:"roles,omitempty"`
 }
 
 // DoltRemotesConfig holds configuration for the dolt_remotes patrol.
@@ -140,6 +154,29 @@ type DoltRemotesConfig struct {
 	Branch string `json:"branch,omitempty"`
 }
 
+// BackupConfig holds configuration for the backup patrol.
+// This patrol periodically writes a town backup (see gt backup) and prunes
+// old ones.
+type BackupConfig struct {
+	// Enabled controls whether scheduled backups run.
+	Enabled bool `json:"enabled"`
+
+	// Interval is how often to back up (default 24h).
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Dir is where backups are written (default ~/.gt/backups/<town>).
+	Dir string `json:"dir,omitempty"`
+
+	// Keep is how many backups to retain (default 7).
+	Keep int `json:"keep,omitempty"`
+
+	// MaxAge removes backups older than this, if set.
+	MaxAge time.Duration `json:"max_age,omitempty"`
+
+	// PassphraseFile, if set, encrypts backups with the passphrase it holds.
+	PassphraseFile string `json:"passphrase_file,omitempty"`
+}
+
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
 type DaemonPatrolConfig struct {
 	Type      string         `json:"type"`
@@ -171,7 +208,7 @@ func LoadPatrolConfig(townRoot string) *DaemonPatrolConfig {
 
 // IsPatrolEnabled checks if a patrol is enabled in the config.
 // Returns true if the config doesn't exist (default enabled for backwards compatibility).
-// Exception: opt-in patrols (dolt_remotes) default to disabled.
+// Exception: opt-in patrols (dolt_remotes, backup) default to disabled.
 func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 	// Opt-in patrols: disabled unless explicitly enabled in config.
 	// Must check before the nil-config fallback, otherwise nil config
@@ -182,6 +219,12 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		}
 		return config.Patrols.DoltRemotes.Enabled
 	}
+	if patrol == "backup" {
+		if config == nil || config.Patrols == nil || config.Patrols.Backup == nil {
+			return false
+		}
+		return config.Patrols.Backup.Enabled
+	}
 
 	if config == nil || config.Patrols == nil {
 		return true // Default: enabled
@@ -200,6 +243,10 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	default:
+		if pc := config.Patrols.Roles[patrol]; pc != nil {
+			return pc.Enabled
+		}
 	}
 	return true // Default: enabled
 }
@@ -219,6 +266,10 @@ func GetPatrolRigs(config *DaemonPatrolConfig, patrol string) []string {
 		if config.Patrols.Witness != nil {
 			return config.Patrols.Witness.Rigs
 		}
+	default:
+		if pc := config.Patrols.Roles[patrol]; pc != nil {
+			return pc.Rigs
+		}
 	}
 	return nil // All rigs
 }
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/config"
+	gitpkg "github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// snapshotWIP saves the unsaved work of every polecat whose latest WIP
+// snapshot is older than its rig's snapshot interval, then prunes snapshots
+// past the rig's retention. Polecats with nothing new to save are skipped by
+// TakeSnapshot itself, so quiet worktrees cost one status check.
+func (d *Daemon) snapshotWIP() {
+	now := time.Now()
+	for _, rigName := range d.getKnownRigs() {
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+		var cfg *config.WIPSnapshotConfig
+		if err == nil {
+			cfg = settings.WIPSnapshots
+		}
+		if !cfg.Enabled() {
+			continue
+		}
+
+		r := &rig.Rig{Name: rigName, Path: rigPath}
+		mgr := polecat.NewManager(r, gitpkg.NewGit(rigPath), nil)
+		polecats, err := mgr.List()
+		if err != nil || len(polecats) == 0 {
+			continue
+		}
+		repo, err := mgr.WIPRepo()
+		if err != nil {
+			continue
+		}
+		snaps, err := checkpoint.ListSnapshots(repo, "")
+		if err != nil {
+			d.logger.Printf("WIP snapshots %s: listing snapshots failed: %v", rigName, err)
+			continue
+		}
+
+		names := make([]string, 0, len(polecats))
+		for _, p := range polecats {
+			names = append(names, p.Name)
+		}
+		for _, name := range wipSnapshotsDue(names, snaps, cfg.IntervalDuration(), now) {
+			snap, err := mgr.SnapshotWIP(name)
+			if err != nil {
+				d.logger.Printf("WIP snapshots %s: snapshot of %s failed: %v", rigName, name, err)
+				continue
+			}
+			if snap != nil {
+				d.logger.Printf("WIP snapshots %s: saved %s (%d files)", rigName, snap.Ref, snap.Files)
+			}
+		}
+
+		retention := checkpoint.Retention{Keep: cfg.KeepCount(), MaxAge: cfg.MaxAgeDuration()}
+		if pruned, err := checkpoint.PruneSnapshots(repo, "", retention, now); err != nil {
+			d.logger.Printf("WIP snapshots %s: pruning failed: %v", rigName, err)
+		} else if pruned > 0 {
+			d.logger.Printf("WIP snapshots %s: pruned %d expired snapshot(s)", rigName, pruned)
+		}
+	}
+}
+
+// wipSnapshotsDue returns the polecats, sorted, whose newest snapshot in
+// snaps is at least interval old, or who have none.
+func wipSnapshotsDue(polecats []string, snaps []checkpoint.Snapshot, interval time.Duration, now time.Time) []string {
+	latest := make(map[string]time.Time)
+	for _, s := range snaps {
+		if s.Time.After(latest[s.Polecat]) {
+			latest[s.Polecat] = s.Time
+		}
+	}
+	var due []string
+	for _, name := range polecats {
+		if t, ok := latest[name]; ok && now.Sub(t) < interval {
+			continue
+		}
+		due = append(due, name)
+	}
+	sort.Strings(due)
+	return due
+}
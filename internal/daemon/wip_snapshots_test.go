@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
+)
+
+func TestWIPSnapshotsDue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	snaps := []checkpoint.Snapshot{
+		{Polecat: "Toast", Time: now.Add(-2 * time.Minute)},
+		{Polecat: "Nux", Time: now.Add(-15 * time.Minute)},
+		{Polecat: "Toast", Time: now.Add(-time.Hour)},
+		{Polecat: "Gone", Time: now.Add(-time.Hour)},
+	}
+
+	got := wipSnapshotsDue([]string{"Toast", "Nux", "Furiosa"}, snaps, 10*time.Minute, now)
+	if want := []string{"Furiosa", "Nux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("due = %v, want %v", got, want)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/steveyegge/gastown/internal/config"
@@ -15,6 +17,9 @@ import (
 // This check validates any user-provided overrides at:
 //   - <town>/roles/<role>.toml (town-level overrides)
 //   - <rig>/roles/<role>.toml (rig-level overrides)
+//
+// and any custom roles defined by <town>/roles/<name>.toml files whose name
+// is not a built-in role.
 type RoleConfigCheck struct {
 	BaseCheck
 }
@@ -35,13 +40,17 @@ func NewRoleBeadsCheck() *RoleConfigCheck {
 // Run checks if role config is valid.
 func (c *RoleConfigCheck) Run(ctx *CheckContext) *CheckResult {
 	var warnings []string
-	var overrideCount int
+	var overrideCount, customCount int
 
 	// Check town-level overrides
 	townRolesDir := filepath.Join(ctx.TownRoot, "roles")
 	if entries, err := os.ReadDir(townRolesDir); err == nil {
 		for _, entry := range entries {
 			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".toml" {
+				if !config.IsBuiltinRole(strings.TrimSuffix(entry.Name(), ".toml")) {
+					customCount++ // Validated below
+					continue
+				}
 				overrideCount++
 				path := filepath.Join(townRolesDir, entry.Name())
 				if err := validateRoleOverride(path); err != nil {
@@ -51,6 +60,13 @@ func (c *RoleConfigCheck) Run(ctx *CheckContext) *CheckResult {
 		}
 	}
 
+	// Check custom role definitions
+	_, customErrs := config.LoadCustomRoles(ctx.TownRoot)
+	for name, err := range customErrs {
+		warnings = append(warnings, fmt.Sprintf("custom role %s: %v", name, err))
+	}
+	sort.Strings(warnings)
+
 	// Check rig-level overrides for each rig
 	// Discover rigs by looking for directories with rig.json
 	if entries, err := os.ReadDir(ctx.TownRoot); err == nil {
@@ -84,7 +100,7 @@ func (c *RoleConfigCheck) Run(ctx *CheckContext) *CheckResult {
 			Status:   StatusWarning,
 			Message:  fmt.Sprintf("%d role config override(s) have issues", len(warnings)),
 			Details:  warnings,
-			FixHint:  "Check TOML syntax in role override files",
+			FixHint:  "Check TOML syntax in role override files; custom roles also need a valid name and scope (town or rig)",
 			Category: c.Category(),
 		}
 	}
@@ -93,6 +109,9 @@ func (c *RoleConfigCheck) Run(ctx *CheckContext) *CheckResult {
 	if overrideCount > 0 {
 		msg = fmt.Sprintf("Role config valid (%d override file(s))", overrideCount)
 	}
+	if customCount > 0 {
+		msg += fmt.Sprintf(", %d custom role(s)", customCount)
+	}
 
 	return &CheckResult{
 		Name:     c.Name(),
@@ -3,6 +3,7 @@ package doctor
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -112,6 +113,36 @@ needs_pre_sync = true
 		}
 	})
 
+	t.Run("custom roles are validated", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		rolesDir := filepath.Join(tmpDir, "roles")
+		if err := os.MkdirAll(rolesDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		// Valid TOML, but a custom role needs a scope
+		if err := os.WriteFile(filepath.Join(rolesDir, "reviewer.toml"), []byte(`description = "Reviews code"`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(rolesDir, "triage.toml"), []byte(`scope = "town"`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		check := NewRoleBeadsCheck()
+		ctx := &CheckContext{TownRoot: tmpDir}
+		result := check.Run(ctx)
+
+		if result.Status != StatusWarning {
+			t.Errorf("expected StatusWarning, got %v: %s", result.Status, result.Message)
+		}
+		if len(result.Details) != 1 {
+			t.Fatalf("expected 1 warning detail, got %d: %v", len(result.Details), result.Details)
+		}
+		if !strings.Contains(result.Details[0], "reviewer") {
+			t.Errorf("expected warning about reviewer, got %q", result.Details[0])
+		}
+	})
+
 	t.Run("check is not fixable", func(t *testing.T) {
 		check := NewRoleBeadsCheck()
 		if check.CanFix() {
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+// SandboxCheck verifies the host supports the sandbox profiles rigs ask for:
+// cgroup v2 with delegated cpu/memory/pids controllers for resource limits,
+// and bubblewrap plus user namespaces for filesystem and network isolation.
+type SandboxCheck struct {
+	BaseCheck
+	probe func() sandbox.Support // overridable for tests
+}
+
+// NewSandboxCheck creates a new sandbox support check.
+func NewSandboxCheck() *SandboxCheck {
+	return &SandboxCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "sandbox-support",
+			CheckDescription: "Check kernel support for rig sandbox profiles",
+			CheckCategory:    CategoryInfrastructure,
+		},
+		probe: sandbox.Probe,
+	}
+}
+
+// sandboxNeeds summarizes what the town's sandbox settings require.
+type sandboxNeeds struct {
+	rigs        []string
+	limits      bool
+	controllers map[string]bool
+	isolation   bool
+	required    bool
+}
+
+// Run checks host support against the sandbox settings of every rig.
+func (c *SandboxCheck) Run(ctx *CheckContext) *CheckResult {
+	needs := collectSandboxNeeds(ctx.TownRoot)
+	if len(needs.rigs) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No rigs use sandboxing",
+		}
+	}
+
+	support := c.probe()
+	var missing []string
+	if needs.limits {
+		if !support.CanLimit() {
+			missing = append(missing, "cgroup v2 limits unavailable")
+		}
+		for _, ctrl := range []string{"cpu", "memory", "pids"} {
+			if needs.controllers[ctrl] && support.CgroupParent != "" && !support.HasController(ctrl) {
+				missing = append(missing, "cgroup controller "+ctrl+" not delegated")
+			}
+		}
+	}
+	if needs.isolation && !support.CanIsolate() {
+		missing = append(missing, "filesystem/network isolation unavailable")
+	}
+
+	rigList := strings.Join(needs.rigs, ", ")
+	if len(missing) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: fmt.Sprintf("Sandbox supported (rigs: %s)", rigList),
+		}
+	}
+
+	status := StatusWarning
+	if needs.required {
+		status = StatusError
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  status,
+		Message: fmt.Sprintf("Sandbox profiles cannot be fully applied (rigs: %s)", rigList),
+		Details: append(missing, support.Problems...),
+		FixHint: "Run under a systemd user session (or set GT_CGROUP_ROOT to a delegated cgroup) and install bubblewrap",
+	}
+}
+
+// collectSandboxNeeds reads the sandbox settings of every registered rig.
+func collectSandboxNeeds(townRoot string) sandboxNeeds {
+	needs := sandboxNeeds{controllers: make(map[string]bool)}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return needs
+	}
+
+	for rigName := range rigsConfig.Rigs {
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(townRoot, rigName)))
+		if err != nil || settings.Sandbox == nil || !settings.Sandbox.Enabled {
+			continue
+		}
+		needs.rigs = append(needs.rigs, rigName)
+		if settings.Sandbox.Required {
+			needs.required = true
+		}
+
+		profiles := []*config.SandboxProfile{settings.Sandbox.Default}
+		for _, p := range settings.Sandbox.Roles {
+			profiles = append(profiles, p)
+		}
+		for _, p := range profiles {
+			if p == nil {
+				continue
+			}
+			if p.CPUs > 0 {
+				needs.limits, needs.controllers["cpu"] = true, true
+			}
+			if p.Memory != "" {
+				needs.limits, needs.controllers["memory"] = true, true
+			}
+			if p.MaxPids > 0 {
+				needs.limits, needs.controllers["pids"] = true, true
+			}
+			if p.NeedsNamespaces() {
+				needs.isolation = true
+			}
+		}
+	}
+	sort.Strings(needs.rigs)
+	return needs
+}
@@ -0,0 +1,76 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+func writeSandboxTown(t *testing.T, sb *config.SandboxConfig) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigs := &config.RigsConfig{Version: 1, Rigs: map[string]config.RigEntry{"myrig": {}}}
+	if err := config.SaveRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"), rigs); err != nil {
+		t.Fatalf("SaveRigsConfig() error = %v", err)
+	}
+	settings := config.NewRigSettings()
+	settings.Sandbox = sb
+	if err := config.SaveRigSettings(config.RigSettingsPath(filepath.Join(townRoot, "myrig")), settings); err != nil {
+		t.Fatalf("SaveRigSettings() error = %v", err)
+	}
+	return townRoot
+}
+
+func TestSandboxCheck_NoRigsUseSandbox(t *testing.T) {
+	townRoot := writeSandboxTown(t, nil)
+	check := NewSandboxCheck()
+	check.probe = func() sandbox.Support { t.Fatal("probe should not run"); return sandbox.Support{} }
+
+	result := check.Run(&CheckContext{TownRoot: townRoot})
+	if result.Status != StatusOK {
+		t.Errorf("Status = %v, want OK: %s", result.Status, result.Message)
+	}
+}
+
+func TestSandboxCheck_MissingSupport(t *testing.T) {
+	sb := &config.SandboxConfig{
+		Enabled: true,
+		Roles: map[string]*config.SandboxProfile{
+			"polecat": {Memory: "2G", DenyNetwork: true},
+		},
+	}
+	unsupported := func() sandbox.Support {
+		return sandbox.Support{Problems: []string{"cgroup v2 not mounted"}}
+	}
+
+	check := NewSandboxCheck()
+	check.probe = unsupported
+	if result := check.Run(&CheckContext{TownRoot: writeSandboxTown(t, sb)}); result.Status != StatusWarning {
+		t.Errorf("Status = %v, want warning", result.Status)
+	}
+
+	sb.Required = true
+	if result := check.Run(&CheckContext{TownRoot: writeSandboxTown(t, sb)}); result.Status != StatusError {
+		t.Errorf("required: Status = %v, want error", result.Status)
+	}
+
+	check.probe = func() sandbox.Support {
+		return sandbox.Support{
+			CgroupV2:     true,
+			CgroupParent: "/sys/fs/cgroup/gastown",
+			Delegated:    true,
+			Controllers:  []string{"cpu", "memory", "pids"},
+			Bwrap:        "/usr/bin/bwrap",
+			UserNS:       true,
+		}
+	}
+	if result := check.Run(&CheckContext{TownRoot: writeSandboxTown(t, sb)}); result.Status != StatusOK {
+		t.Errorf("supported: Status = %v, want OK: %v", result.Status, result.Details)
+	}
+}
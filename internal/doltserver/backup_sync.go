@@ -0,0 +1,67 @@
+package doltserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupTimeout bounds a single database backup or restore.
+const backupTimeout = 10 * time.Minute
+
+// SyncDatabaseBackup writes a consistent copy of a database served by the
+// running Dolt server into dir, as a Dolt backup (DOLT_BACKUP sync-url).
+// Unlike copying the data directory, this is safe while the server is
+// writing. Restore it with RestoreDatabaseBackup.
+func SyncDatabaseBackup(townRoot, db, dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return fmt.Errorf("creating backup dir: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+	url := strings.ReplaceAll("file://"+filepath.ToSlash(abs), "'", "''")
+	query := fmt.Sprintf("USE `%s`; CALL DOLT_BACKUP('sync-url', '%s')", db, url)
+	cmd := buildDoltSQLCmd(ctx, DefaultConfig(townRoot), "-q", query)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("backing up %s: %w (output: %s)", db, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RestoreDatabaseBackup recreates database db in the town's data directory
+// from a Dolt backup in dir (see SyncDatabaseBackup). The server must be
+// stopped. With force, an existing database of that name is replaced.
+func RestoreDatabaseBackup(townRoot, db, dir string, force bool) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	config := DefaultConfig(townRoot)
+	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
+		return fmt.Errorf("creating data dir: %w", err)
+	}
+
+	args := []string{"backup", "restore"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, "file://"+filepath.ToSlash(abs), db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "dolt", args...)
+	cmd.Dir = config.DataDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("restoring %s: %w (output: %s)", db, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
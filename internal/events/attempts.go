@@ -0,0 +1,111 @@
+package events
+
+import (
+	"strings"
+	"time"
+)
+
+// Attempt is one run of a bead by one worker, replayed from the sling,
+// routed, done and merge events.
+type Attempt struct {
+	Bead, Rig       string
+	Target          string // Sling target: the worker identity (rig/polecats/name)
+	Agent           string // Agent preset, if the event recorded one
+	SlungAt, DoneAt time.Time
+	Done            bool
+	Branch          string // Branch reported by done
+	Merged          bool
+	MergeFailed     bool // The merge of Branch failed
+	Superseded      bool // Slung again before done (the worker died or was replaced)
+	Reslung         int  // Times the bead was slung again after this attempt was done
+
+	routed bool // Started by a routed event, awaiting its sling
+}
+
+// Attempts replays work attempts from the events log.
+type Attempts struct {
+	All []*Attempt // In start order
+
+	latest   map[string]*Attempt // bead -> latest attempt
+	byBranch map[string]*Attempt // branch -> completed attempt
+}
+
+// NewAttempts returns an empty replay.
+func NewAttempts() *Attempts {
+	return &Attempts{latest: make(map[string]*Attempt), byBranch: make(map[string]*Attempt)}
+}
+
+// Latest returns the bead's latest attempt, or nil.
+func (r *Attempts) Latest(bead string) *Attempt {
+	return r.latest[bead]
+}
+
+// Add replays one event. Events must arrive oldest first; other event
+// types are ignored.
+func (r *Attempts) Add(e *Event) {
+	ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+	bead, _ := e.Payload["bead"].(string)
+	switch e.Type {
+	case TypeSling, TypeRouted:
+		target, _ := e.Payload["target"].(string)
+		rig := RigOfTarget(target)
+		if bead == "" || rig == "" {
+			return
+		}
+		agent, _ := e.Payload["agent"].(string)
+		if prev := r.latest[bead]; prev != nil {
+			switch {
+			case prev.routed && !prev.Done && e.Type == TypeSling && prev.Rig == rig:
+				// A routed event and the sling that follows it describe
+				// the same attempt.
+				prev.routed = false
+				prev.Target = target
+				if prev.Agent == "" {
+					prev.Agent = agent
+				}
+				return
+			case prev.Done:
+				// Completed work came back.
+				prev.Reslung++
+			default:
+				prev.Superseded = true
+			}
+		}
+		a := &Attempt{Bead: bead, Rig: rig, Target: target, Agent: agent, SlungAt: ts,
+			routed: e.Type == TypeRouted}
+		r.latest[bead] = a
+		r.All = append(r.All, a)
+	case TypeDone:
+		a := r.latest[bead]
+		if a == nil || a.Done {
+			return
+		}
+		a.Done = true
+		a.DoneAt = ts
+		a.Branch, _ = e.Payload["branch"].(string)
+		if a.Branch != "" {
+			r.byBranch[a.Branch] = a
+		}
+	case TypeMerged:
+		if a := r.latest[bead]; a != nil && a.Done {
+			a.Merged = true
+		}
+	case TypeMergeFailed:
+		branch, _ := e.Payload["branch"].(string)
+		if a := r.byBranch[branch]; a != nil {
+			a.MergeFailed = true
+		}
+	}
+}
+
+// RigOfTarget extracts the rig from a sling target ("rig",
+// "rig/polecats/name", "rig/crew/name"). Town-level targets (mayor,
+// deacon) have no rig.
+func RigOfTarget(target string) string {
+	rig, _, _ := strings.Cut(strings.TrimSuffix(target, "/"), "/")
+	switch rig {
+	case "", "mayor", "deacon":
+		return ""
+	}
+	return rig
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttempts_Replay(t *testing.T) {
+	ev := func(min int, eventType string, payload map[string]interface{}) *Event {
+		return &Event{Timestamp: queryBase.Add(time.Duration(min) * time.Minute).Format(time.RFC3339),
+			Type: eventType, Payload: payload}
+	}
+	r := NewAttempts()
+	for _, e := range []*Event{
+		// Routed then slung: one attempt, agent from the routed event.
+		ev(0, TypeRouted, map[string]interface{}{"bead": "gt-1", "target": "gastown", "agent": "codex"}),
+		ev(1, TypeSling, map[string]interface{}{"bead": "gt-1", "target": "gastown/polecats/Toast"}),
+		ev(30, TypeDone, map[string]interface{}{"bead": "gt-1", "branch": "polecat/Toast/gt-1"}),
+		ev(40, TypeMergeFailed, map[string]interface{}{"branch": "polecat/Toast/gt-1"}),
+		// Completed work came back, then merged.
+		ev(50, TypeSling, map[string]interface{}{"bead": "gt-1", "target": "gastown/polecats/Nux"}),
+		ev(70, TypeDone, map[string]interface{}{"bead": "gt-1", "branch": "polecat/Nux/gt-1"}),
+		ev(80, TypeMerged, map[string]interface{}{"bead": "gt-1"}),
+		// Slung twice before done: the first worker was replaced.
+		ev(0, TypeSling, map[string]interface{}{"bead": "gt-2", "target": "beads/polecats/Ace"}),
+		ev(5, TypeSling, map[string]interface{}{"bead": "gt-2", "target": "beads/polecats/Max"}),
+		// Town-level targets have no rig.
+		ev(0, TypeSling, map[string]interface{}{"bead": "gt-3", "target": "mayor/"}),
+	} {
+		r.Add(e)
+	}
+
+	if len(r.All) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(r.All))
+	}
+	first, second, replaced, last := r.All[0], r.All[1], r.All[2], r.All[3]
+	if first.Target != "gastown/polecats/Toast" || first.Agent != "codex" || !first.Done ||
+		!first.MergeFailed || first.Merged || first.Reslung != 1 || first.DoneAt.Sub(first.SlungAt) != 30*time.Minute {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if !second.Merged || second.MergeFailed || r.Latest("gt-1") != second {
+		t.Errorf("second attempt = %+v", second)
+	}
+	if !replaced.Superseded || replaced.Done || last.Superseded || last.Rig != "beads" {
+		t.Errorf("replaced = %+v, last = %+v", replaced, last)
+	}
+	if r.Latest("gt-3") != nil {
+		t.Error("town-level sling should not start an attempt")
+	}
+}
+
+func TestRigOfTarget(t *testing.T) {
+	for target, want := range map[string]string{
+		"gastown":                "gastown",
+		"gastown/":               "gastown",
+		"gastown/polecats/Toast": "gastown",
+		"beads/crew/max":         "beads",
+		"mayor/":                 "",
+		"deacon":                 "",
+		"":                       "",
+	} {
+		if got := RigOfTarget(target); got != want {
+			t.Errorf("RigOfTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -68,6 +69,16 @@ const (
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+
+	// Sandbox events (resource limits on agent sessions)
+	TypeSandboxViolation   = "sandbox_violation"   // Session hit a cgroup limit
+	TypeSandboxUnavailable = "sandbox_unavailable" // Sandbox configured but not applied
+
+	// Routing events (gt sling --auto)
+	TypeRouted = "routed"
+
+	// Inbound webhook events (GitHub/GitLab activity that matched a rule)
+	TypeRepoActivity = "repo_activity"
 )
 
 // EventsFile is the name of the raw events log.
@@ -111,6 +122,10 @@ func write(event Event) error {
 
 	eventsPath := filepath.Join(townRoot, EventsFile)
 
+	// Never persist secret values: payloads often carry command output,
+	// failure reasons and subjects that may echo credentials.
+	event.Payload = secrets.LoadRedactor(townRoot).RedactPayload(event.Payload)
+
 	// Marshal event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -148,6 +163,22 @@ func SlingPayload(beadID, target string) map[string]interface{} {
 	}
 }
 
+// RoutedPayload creates a payload for routing decisions made by gt sling --auto.
+// The agent is recorded so routing can learn per rig/agent success rates.
+func RoutedPayload(beadID, target, agent, account string, score float64, reasons []string) map[string]interface{} {
+	p := map[string]interface{}{
+		"bead":    beadID,
+		"target":  target,
+		"agent":   agent,
+		"score":   score,
+		"reasons": reasons,
+	}
+	if account != "" {
+		p["account"] = account
+	}
+	return p
+}
+
 // HookPayload creates a payload for hook events.
 func HookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -215,6 +246,17 @@ func MergePayload(mrID, worker, branch, reason string) map[string]interface{} {
 	return p
 }
 
+// MergedPayload creates a payload for a merged event, recorded once the
+// Witness has confirmed a polecat's work is on the default branch.
+func MergedPayload(beadID, rig, worker, branch string) map[string]interface{} {
+	return map[string]interface{}{
+		"bead":   beadID,
+		"rig":    rig,
+		"worker": worker,
+		"branch": branch,
+	}
+}
+
 // PatrolPayload creates a payload for patrol start/complete events.
 func PatrolPayload(rig string, polecatCount int, message string) map[string]interface{} {
 	p := map[string]interface{}{
@@ -332,3 +374,25 @@ func SessionPayload(sessionID, role, topic, cwd string) map[string]interface{} {
 	}
 	return p
 }
+
+// SandboxViolationPayload creates a payload for sandbox violation events.
+// session: tmux session that hit the limit
+// kind: which limit ("oom_kill", "memory_max", "pids_max")
+// count: number of new hits since the last check
+func SandboxViolationPayload(session, role, kind string, count int64) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"role":    role,
+		"kind":    kind,
+		"count":   count,
+	}
+}
+
+// SandboxUnavailablePayload creates a payload for sandbox unavailable events.
+func SandboxUnavailablePayload(session, role, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"role":    role,
+		"reason":  reason,
+	}
+}
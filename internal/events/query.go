@@ -0,0 +1,291 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Query selects events from the events log. Zero-valued fields don't filter.
+type Query struct {
+	Types   []string          // Event type patterns (glob, e.g. "merge_*")
+	Actors  []string          // Actor patterns (glob, e.g. "gastown/polecats/*")
+	Rig     string            // Rig the event concerns (payload rig, or actor/target prefix)
+	Since   time.Time         // Events at or after
+	Until   time.Time         // Events at or before
+	Payload map[string]string // Payload field (dotted path) → value pattern (glob)
+
+	// IncludeArchive also searches the compressed archive segments.
+	IncludeArchive bool
+
+	// Limit keeps only the most recent N matching events (0 = all).
+	Limit int
+}
+
+// Match reports whether an event satisfies the query's filters.
+func (q *Query) Match(e *Event) bool {
+	if len(q.Types) > 0 && !matchAny(q.Types, e.Type) {
+		return false
+	}
+	if len(q.Actors) > 0 && !matchAny(q.Actors, e.Actor) {
+		return false
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !q.Since.IsZero() && ts.Before(q.Since) {
+			return false
+		}
+		if !q.Until.IsZero() && ts.After(q.Until) {
+			return false
+		}
+	}
+	if q.Rig != "" && EventRig(e) != q.Rig {
+		return false
+	}
+	for key, want := range q.Payload {
+		got, ok := payloadValue(e.Payload, key)
+		if !ok || !matchGlob(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan calls fn for each event matching the query, oldest segment first:
+// archive segments (with IncludeArchive), then the live log. Segments whose
+// time range or event types cannot match are skipped. Limit is not applied.
+func Scan(townRoot string, q *Query, fn func(*Event) error) error {
+	var segments []*Segment
+	if q.IncludeArchive {
+		archived, err := ArchiveSegments(townRoot)
+		if err != nil {
+			return err
+		}
+		segments = append(segments, archived...)
+	}
+	live, err := LiveSegments(townRoot)
+	if err != nil {
+		return err
+	}
+	segments = append(segments, live...)
+
+	for _, seg := range segments {
+		if !seg.overlaps(q.Since, q.Until) || !segmentHasType(seg, q.Types) {
+			continue
+		}
+		if err := scanSegment(townRoot, seg, q, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanSegment(townRoot string, seg *Segment, q *Query, fn func(*Event) error) error {
+	rc, err := openSegment(townRoot, seg)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 64*1024), maxEventLine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue // Skip malformed lines
+		}
+		if !q.Match(&e) {
+			continue
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scanning events: %w", err)
+	}
+	return nil
+}
+
+// Find returns the events matching the query in log order, keeping only the
+// most recent Limit events when a limit is set.
+func Find(townRoot string, q *Query) ([]Event, error) {
+	var found []Event
+	err := Scan(townRoot, q, func(e *Event) error {
+		found = append(found, *e)
+		if q.Limit > 0 && len(found) > 2*q.Limit {
+			found = append(found[:0], found[len(found)-q.Limit:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[len(found)-q.Limit:]
+	}
+	return found, nil
+}
+
+// Count is one row of an aggregation: the number of events sharing a time
+// bucket and the values of the group-by fields.
+type Count struct {
+	Bucket time.Time // Start of the time bucket (zero without bucketing)
+	Keys   []string  // Group-by field values, in Aggregation.GroupBy order
+	Count  int
+}
+
+// Aggregation groups matching events for counting.
+type Aggregation struct {
+	GroupBy []string      // Fields (see FieldValue)
+	Bucket  time.Duration // Time bucket width (0 = no bucketing)
+}
+
+// Aggregate counts the events matching the query by bucket and group,
+// sorted by bucket, then by group values.
+func Aggregate(townRoot string, q *Query, agg Aggregation) ([]Count, error) {
+	counts := make(map[string]*Count)
+	err := Scan(townRoot, q, func(e *Event) error {
+		var bucket time.Time
+		if agg.Bucket > 0 {
+			ts, err := time.Parse(time.RFC3339, e.Timestamp)
+			if err != nil {
+				return nil // Can't bucket undated events
+			}
+			bucket = ts.UTC().Truncate(agg.Bucket)
+		}
+		keys := make([]string, len(agg.GroupBy))
+		for i, field := range agg.GroupBy {
+			keys[i] = FieldValue(e, field)
+		}
+		id := bucket.Format(time.RFC3339) + "\x00" + strings.Join(keys, "\x00")
+		c := counts[id]
+		if c == nil {
+			c = &Count{Bucket: bucket, Keys: keys}
+			counts[id] = c
+		}
+		c.Count++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Count, 0, len(counts))
+	for _, c := range counts {
+		rows = append(rows, *c)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Bucket.Equal(rows[j].Bucket) {
+			return rows[i].Bucket.Before(rows[j].Bucket)
+		}
+		return strings.Join(rows[i].Keys, "\x00") < strings.Join(rows[j].Keys, "\x00")
+	})
+	return rows, nil
+}
+
+// FieldValue returns an event field as a string for grouping and output:
+// ts, type, actor, source, visibility, rig, or a payload field given as
+// "payload.<path>" (or a bare payload key).
+func FieldValue(e *Event, field string) string {
+	switch field {
+	case "ts", "time", "timestamp":
+		return e.Timestamp
+	case "type":
+		return e.Type
+	case "actor":
+		return e.Actor
+	case "source":
+		return e.Source
+	case "visibility":
+		return e.Visibility
+	case "rig":
+		return EventRig(e)
+	}
+	v, _ := payloadValue(e.Payload, strings.TrimPrefix(field, "payload."))
+	return v
+}
+
+// EventRig returns the rig an event concerns: its payload's rig, or the rig
+// prefix of its actor or target address.
+func EventRig(e *Event) string {
+	if rig, ok := e.Payload["rig"].(string); ok && rig != "" {
+		return rig
+	}
+	for _, addr := range []string{e.Actor, payloadString(e.Payload, "target")} {
+		if rig, _, ok := strings.Cut(addr, "/"); ok && rig != "" {
+			return rig
+		}
+	}
+	return ""
+}
+
+// payloadValue looks up a dotted path in a payload and formats the value.
+func payloadValue(payload map[string]interface{}, key string) (string, bool) {
+	var cur interface{} = payload
+	for _, part := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if cur, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "", true
+	case float64, bool:
+		return fmt.Sprint(v), true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+func payloadString(payload map[string]interface{}, key string) string {
+	s, _ := payload[key].(string)
+	return s
+}
+
+// matchAny reports whether s matches any of the glob patterns.
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches s against a glob pattern where * also matches "/".
+func matchGlob(pattern, s string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == s
+	}
+	ok, err := path.Match(pattern, s)
+	if err == nil && ok {
+		return true
+	}
+	// path.Match's * stops at "/"; let a trailing * span address segments.
+	if prefix, found := strings.CutSuffix(pattern, "*"); found && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(s, prefix)
+	}
+	return false
+}
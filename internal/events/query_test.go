@@ -0,0 +1,229 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var queryBase = time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+
+func eventLine(t *testing.T, at time.Time, eventType, actor string, payload map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(Event{
+		Timestamp:  at.Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: VisibilityFeed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeEventLines(t *testing.T, townRoot string, lines []string, appendTo bool) {
+	t.Helper()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(filepath.Join(townRoot, EventsFile), flags, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, line := range lines {
+		if _, err := f.WriteString(line + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sampleEvents(t *testing.T) []string {
+	return []string{
+		eventLine(t, queryBase, TypeSling, "mayor", map[string]interface{}{"bead": "gt-1", "target": "gastown/polecats/Toast"}),
+		eventLine(t, queryBase.Add(10*time.Minute), TypeMergeStarted, "gastown/refinery", map[string]interface{}{"mr": "gt-mr-1"}),
+		eventLine(t, queryBase.Add(20*time.Minute), TypeMerged, "gastown/refinery", map[string]interface{}{"mr": "gt-mr-1"}),
+		eventLine(t, queryBase.Add(90*time.Minute), TypeMergeFailed, "beads/refinery", map[string]interface{}{"mr": "bd-mr-2", "reason": "conflict"}),
+		eventLine(t, queryBase.Add(3*time.Hour), TypeSessionDeath, "gastown/polecats/Toast", map[string]interface{}{"reason": "crash"}),
+	}
+}
+
+func TestFind_Filters(t *testing.T) {
+	townRoot := t.TempDir()
+	writeEventLines(t, townRoot, sampleEvents(t), false)
+
+	tests := []struct {
+		name  string
+		q     Query
+		types []string
+	}{
+		{"all", Query{}, []string{TypeSling, TypeMergeStarted, TypeMerged, TypeMergeFailed, TypeSessionDeath}},
+		{"type glob", Query{Types: []string{"merge*"}}, []string{TypeMergeStarted, TypeMerged, TypeMergeFailed}},
+		{"actor glob", Query{Actors: []string{"gastown/*"}}, []string{TypeMergeStarted, TypeMerged, TypeSessionDeath}},
+		{"rig from target", Query{Rig: "gastown", Types: []string{TypeSling}}, []string{TypeSling}},
+		{"rig", Query{Rig: "beads"}, []string{TypeMergeFailed}},
+		{"payload", Query{Payload: map[string]string{"mr": "gt-mr-*"}}, []string{TypeMergeStarted, TypeMerged}},
+		{"since", Query{Since: queryBase.Add(time.Hour)}, []string{TypeMergeFailed, TypeSessionDeath}},
+		{"until", Query{Until: queryBase.Add(10 * time.Minute)}, []string{TypeSling, TypeMergeStarted}},
+		{"limit keeps newest", Query{Limit: 2}, []string{TypeMergeFailed, TypeSessionDeath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := Find(townRoot, &tt.q)
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if len(found) != len(tt.types) {
+				t.Fatalf("got %d events, want %d: %+v", len(found), len(tt.types), found)
+			}
+			for i, e := range found {
+				if e.Type != tt.types[i] {
+					t.Errorf("event %d type = %q, want %q", i, e.Type, tt.types[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAggregate_ByTypePerHour(t *testing.T) {
+	townRoot := t.TempDir()
+	writeEventLines(t, townRoot, sampleEvents(t), false)
+
+	rows, err := Aggregate(townRoot, &Query{Types: []string{"merge*"}}, Aggregation{GroupBy: []string{"rig"}, Bucket: time.Hour})
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	if !rows[0].Bucket.Equal(queryBase) || rows[0].Keys[0] != "gastown" || rows[0].Count != 2 {
+		t.Errorf("row 0 = %+v, want gastown x2 at %v", rows[0], queryBase)
+	}
+	if !rows[1].Bucket.Equal(queryBase.Add(time.Hour)) || rows[1].Keys[0] != "beads" || rows[1].Count != 1 {
+		t.Errorf("row 1 = %+v, want beads x1 at %v", rows[1], queryBase.Add(time.Hour))
+	}
+}
+
+func TestLiveSegments_IndexTracksLog(t *testing.T) {
+	townRoot := t.TempDir()
+	lines := sampleEvents(t)
+	writeEventLines(t, townRoot, lines[:3], false)
+
+	segs, err := LiveSegments(townRoot)
+	if err != nil {
+		t.Fatalf("LiveSegments: %v", err)
+	}
+	if len(segs) != 1 || segs[0].Count != 3 {
+		t.Fatalf("want one partition of 3 events, got %+v", segs)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, LiveIndexFile)); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	// Appends extend the index into new hourly partitions.
+	writeEventLines(t, townRoot, lines[3:], true)
+	segs, err = LiveSegments(townRoot)
+	if err != nil {
+		t.Fatalf("LiveSegments: %v", err)
+	}
+	if len(segs) != 3 {
+		t.Fatalf("want 3 partitions after append, got %d", len(segs))
+	}
+	if segs[2].Types[TypeSessionDeath] != 1 {
+		t.Errorf("last partition types = %v", segs[2].Types)
+	}
+
+	// A rewrite that keeps the first line (as KRC pruning can) rebuilds the index.
+	writeEventLines(t, townRoot, []string{lines[0], lines[4]}, false)
+	found, err := Find(townRoot, &Query{})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(found) != 2 || found[1].Type != TypeSessionDeath {
+		t.Errorf("after rewrite got %+v", found)
+	}
+}
+
+func TestArchive_QueryAndPrune(t *testing.T) {
+	townRoot := t.TempDir()
+	lines := sampleEvents(t)
+	old := eventLine(t, queryBase.AddDate(0, 0, -40), TypeMerged, "gastown/refinery", map[string]interface{}{"mr": "gt-mr-0"})
+
+	if err := Archive(townRoot, []string{old, lines[0]}); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if err := Archive(townRoot, []string{lines[1]}); err != nil {
+		t.Fatalf("Archive (append): %v", err)
+	}
+	writeEventLines(t, townRoot, lines[2:], false)
+
+	segs, err := ArchiveSegments(townRoot)
+	if err != nil {
+		t.Fatalf("ArchiveSegments: %v", err)
+	}
+	if len(segs) != 2 {
+		t.Fatalf("want 2 daily archive segments, got %+v", segs)
+	}
+
+	live, err := Find(townRoot, &Query{Types: []string{TypeMerged}})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(live) != 1 {
+		t.Errorf("without archive got %d merged events, want 1", len(live))
+	}
+	all, err := Find(townRoot, &Query{Types: []string{TypeMerged}, IncludeArchive: true})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(all) != 2 || all[0].Payload["mr"] != "gt-mr-0" {
+		t.Errorf("with archive got %+v", all)
+	}
+	sameDay, err := Find(townRoot, &Query{Since: queryBase.Add(-time.Hour), Until: queryBase.Add(15 * time.Minute), IncludeArchive: true})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(sameDay) != 2 {
+		t.Errorf("appended archive segment: got %d events, want 2", len(sameDay))
+	}
+
+	removed, err := PruneArchive(townRoot, queryBase.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("PruneArchive: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("PruneArchive removed %d segments, want 1", removed)
+	}
+	all, err = Find(townRoot, &Query{Types: []string{TypeMerged}, IncludeArchive: true})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("after prune got %d merged events, want 1", len(all))
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"merged", "merged", true},
+		{"merge*", "merge_failed", true},
+		{"gastown/*", "gastown/polecats/Toast", true},
+		{"gastown/polecats/*", "beads/polecats/Toast", false},
+		{"session_?eath", "session_death", true},
+		{"merged", "merge_failed", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
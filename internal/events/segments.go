@@ -0,0 +1,423 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// The events log is searched as time-partitioned segments. The live log
+// (.events.jsonl) is split into hourly partitions recorded in LiveIndexFile;
+// events expired by KRC can be moved into compressed daily archive segments
+// under ArchiveDir, recorded in its index. Each segment records its time
+// range and event counts by type, so queries skip segments that cannot match.
+const (
+	// LiveIndexFile indexes the live events log by hour.
+	LiveIndexFile = ".events.idx"
+
+	// ArchiveDir holds the compressed archive segments.
+	ArchiveDir = ".events-archive"
+
+	// archiveIndexFile indexes the archive segments (inside ArchiveDir).
+	archiveIndexFile = "index.json"
+
+	// livePartition is the time span of a live log partition.
+	livePartition = time.Hour
+
+	// maxEventLine bounds a single event line when scanning.
+	maxEventLine = 1024 * 1024
+)
+
+// Segment describes a contiguous run of events: an hourly partition of the
+// live log (a byte range) or a compressed archive file.
+type Segment struct {
+	File   string         `json:"file,omitempty"` // Archive segment file name
+	Offset int64          `json:"offset"`         // Live partitions: start of the byte range
+	Length int64          `json:"length"`         // Live partitions: length of the byte range
+	First  time.Time      `json:"first"`          // Oldest event timestamp
+	Last   time.Time      `json:"last"`           // Newest event timestamp
+	Count  int            `json:"count"`
+	Types  map[string]int `json:"types"`
+}
+
+// overlaps reports whether the segment may hold events in [since, until].
+// Zero bounds are open.
+func (s *Segment) overlaps(since, until time.Time) bool {
+	if s.First.IsZero() {
+		return true // Undated events: can't rule out
+	}
+	if !since.IsZero() && s.Last.Before(since) {
+		return false
+	}
+	if !until.IsZero() && s.First.After(until) {
+		return false
+	}
+	return true
+}
+
+// add records an event in the segment's summary.
+func (s *Segment) add(ts time.Time, eventType string) {
+	s.Count++
+	if s.Types == nil {
+		s.Types = make(map[string]int)
+	}
+	s.Types[eventType]++
+	if ts.IsZero() {
+		return
+	}
+	if s.First.IsZero() || ts.Before(s.First) {
+		s.First = ts
+	}
+	if ts.After(s.Last) {
+		s.Last = ts
+	}
+}
+
+// liveIndex is the on-disk index of the live events log.
+type liveIndex struct {
+	Size       int64      `json:"size"` // Bytes of the log indexed so far
+	Head       string     `json:"head"` // Hash of the first line
+	Tail       string     `json:"tail"` // Hash of the bytes just before Size
+	Partitions []*Segment `json:"partitions"`
+}
+
+// archiveIndex is the on-disk index of the archive segments.
+type archiveIndex struct {
+	Segments []*Segment `json:"segments"`
+}
+
+// eventHeader is the part of an event needed to route and index it.
+type eventHeader struct {
+	Timestamp string `json:"ts"`
+	Type      string `json:"type"`
+}
+
+// parseHeader extracts an event line's timestamp and type. The timestamp is
+// zero when it is missing or malformed.
+func parseHeader(line []byte) (time.Time, string, bool) {
+	var h eventHeader
+	if err := json.Unmarshal(line, &h); err != nil {
+		return time.Time{}, "", false
+	}
+	ts, _ := time.Parse(time.RFC3339, h.Timestamp)
+	return ts, h.Type, true
+}
+
+// LiveSegments returns the hourly partitions of the live events log,
+// updating the on-disk index with events appended since it was written.
+// The index is rebuilt when the log was rewritten (e.g., pruned by KRC).
+func LiveSegments(townRoot string) ([]*Segment, error) {
+	eventsPath := filepath.Join(townRoot, EventsFile)
+	f, err := os.Open(eventsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	head, err := headHash(f)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	indexPath := filepath.Join(townRoot, LiveIndexFile)
+	idx := &liveIndex{}
+	if data, err := os.ReadFile(indexPath); err == nil {
+		_ = json.Unmarshal(data, idx)
+	}
+	// A rewritten log (KRC prune) no longer matches the indexed bytes.
+	if idx.Head != head || idx.Size > info.Size() || tailHash(f, idx.Size) != idx.Tail {
+		idx = &liveIndex{Head: head}
+	}
+	if idx.Size == info.Size() {
+		return idx.Partitions, nil
+	}
+
+	if err := extendLiveIndex(f, idx); err != nil {
+		return nil, err
+	}
+	idx.Tail = tailHash(f, idx.Size)
+	// Best-effort: an unwritable index only costs a rescan next time.
+	_ = util.AtomicWriteJSON(indexPath, idx)
+	return idx.Partitions, nil
+}
+
+// headHash hashes the first line of the log.
+func headHash(f *os.File) (string, error) {
+	r := bufio.NewReader(f)
+	line, err := r.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading events log: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// tailHash hashes the bytes of the log just before size.
+func tailHash(f *os.File, size int64) string {
+	const n = 256
+	start := size - n
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, size-start)
+	if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+		return ""
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8])
+}
+
+// extendLiveIndex indexes complete lines from idx.Size to the end of the log.
+// Consecutive events in the same hour share a partition.
+func extendLiveIndex(f *os.File, idx *liveIndex) error {
+	if _, err := f.Seek(idx.Size, io.SeekStart); err != nil {
+		return err
+	}
+	var cur *Segment
+	if n := len(idx.Partitions); n > 0 {
+		cur = idx.Partitions[n-1]
+	}
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	offset := idx.Size
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break // Ignore a trailing partial line: a writer is mid-append
+		}
+		if err != nil {
+			return fmt.Errorf("reading events log: %w", err)
+		}
+
+		ts, eventType, _ := parseHeader(bytes.TrimSpace(line))
+		hour := ts.Truncate(livePartition)
+		if cur == nil || cur.First.Truncate(livePartition) != hour || cur.Offset+cur.Length != offset {
+			cur = &Segment{Offset: offset}
+			idx.Partitions = append(idx.Partitions, cur)
+		}
+		cur.Length += int64(len(line))
+		if len(bytes.TrimSpace(line)) > 0 {
+			cur.add(ts, eventType)
+		}
+		offset += int64(len(line))
+	}
+	idx.Size = offset
+	return nil
+}
+
+// ArchiveSegments returns the compressed archive segments, oldest first.
+func ArchiveSegments(townRoot string) ([]*Segment, error) {
+	idx, err := loadArchiveIndex(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Segments, nil
+}
+
+func loadArchiveIndex(townRoot string) (*archiveIndex, error) {
+	idx := &archiveIndex{}
+	data, err := os.ReadFile(filepath.Join(townRoot, ArchiveDir, archiveIndexFile))
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive index: %w", err)
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("parsing archive index: %w", err)
+	}
+	return idx, nil
+}
+
+// archiveSegmentName returns the daily archive segment an event belongs to.
+func archiveSegmentName(ts time.Time) string {
+	if ts.IsZero() {
+		return "events-undated.jsonl.gz"
+	}
+	return "events-" + ts.UTC().Format("2006-01-02") + ".jsonl.gz"
+}
+
+// Archive appends raw event lines to the compressed archive, one segment per
+// UTC day. Each call appends a gzip member to the day's segment, so existing
+// data is never rewritten.
+func Archive(townRoot string, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	dir := filepath.Join(townRoot, ArchiveDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating archive dir: %w", err)
+	}
+
+	fl := flock.New(filepath.Join(dir, "index.lock"))
+	if err := fl.Lock(); err != nil {
+		return fmt.Errorf("acquiring archive lock: %w", err)
+	}
+	defer fl.Unlock() //nolint:errcheck // best-effort unlock
+
+	idx, err := loadArchiveIndex(townRoot)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*Segment, len(idx.Segments))
+	for _, seg := range idx.Segments {
+		byName[seg.File] = seg
+	}
+
+	// Group lines by segment, keeping their order.
+	groups := make(map[string][]string)
+	var names []string
+	for _, line := range lines {
+		ts, eventType, _ := parseHeader([]byte(line))
+		name := archiveSegmentName(ts)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], line)
+
+		seg := byName[name]
+		if seg == nil {
+			seg = &Segment{File: name}
+			byName[name] = seg
+			idx.Segments = append(idx.Segments, seg)
+		}
+		seg.add(ts, eventType)
+	}
+
+	for _, name := range names {
+		if err := appendGzip(filepath.Join(dir, name), groups[name]); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(idx.Segments, func(i, j int) bool {
+		return idx.Segments[i].File < idx.Segments[j].File
+	})
+	if err := util.AtomicWriteJSON(filepath.Join(dir, archiveIndexFile), idx); err != nil {
+		return fmt.Errorf("writing archive index: %w", err)
+	}
+	return nil
+}
+
+// appendGzip appends lines to a gzip file as a new member.
+func appendGzip(path string, lines []string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events archive is non-sensitive operational data
+	if err != nil {
+		return fmt.Errorf("opening archive segment: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	for _, line := range lines {
+		if _, err := io.WriteString(zw, line+"\n"); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("writing archive segment: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing archive segment: %w", err)
+	}
+	return f.Close()
+}
+
+// PruneArchive removes archive segments whose newest event is before cutoff.
+// Returns the number of segments removed.
+func PruneArchive(townRoot string, cutoff time.Time) (int, error) {
+	dir := filepath.Join(townRoot, ArchiveDir)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return 0, nil
+	}
+	fl := flock.New(filepath.Join(dir, "index.lock"))
+	if err := fl.Lock(); err != nil {
+		return 0, fmt.Errorf("acquiring archive lock: %w", err)
+	}
+	defer fl.Unlock() //nolint:errcheck // best-effort unlock
+
+	idx, err := loadArchiveIndex(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	var kept []*Segment
+	removed := 0
+	for _, seg := range idx.Segments {
+		if !seg.Last.IsZero() && seg.Last.Before(cutoff) {
+			if err := os.Remove(filepath.Join(dir, seg.File)); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("removing archive segment %s: %w", seg.File, err)
+			}
+			removed++
+			continue
+		}
+		kept = append(kept, seg)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	idx.Segments = kept
+	if err := util.AtomicWriteJSON(filepath.Join(dir, archiveIndexFile), idx); err != nil {
+		return removed, fmt.Errorf("writing archive index: %w", err)
+	}
+	return removed, nil
+}
+
+// openSegment returns a reader over a segment's event lines.
+func openSegment(townRoot string, seg *Segment) (io.ReadCloser, error) {
+	if seg.File == "" {
+		f, err := os.Open(filepath.Join(townRoot, EventsFile))
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.NewSectionReader(f, seg.Offset, seg.Length), f}, nil
+	}
+
+	f, err := os.Open(filepath.Join(townRoot, ArchiveDir, seg.File))
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(f) // Reads all appended members
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("opening archive segment %s: %w", seg.File, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, f}, nil
+}
+
+// segmentHasType reports whether a segment holds any event whose type
+// matches one of the patterns. No patterns match everything.
+func segmentHasType(seg *Segment, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for t := range seg.Types {
+		if matchAny(patterns, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+// Package federation routes mail and work between towns.
+//
+// A federated address names an agent in another town:
+// town@host:rig/agent (see connection.Address); the host is optional and,
+// when given, must match the peer's configured host. Each peer town is
+// listed in settings/federation.json with the transport that reaches it (SSH
+// to the remote gt, or an HTTP POST to its dashboard), a shared token, and
+// what the peer is trusted to do here: send mail, sling work, and to which
+// rigs.
+//
+// Both transports carry the same JSON Envelope. The receiving town
+// authenticates the token, checks trust, and hands the envelope to a Town,
+// which delivers mail, creates and slings a bead, or reports bead status.
+package federation
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ConfigFile is the federation configuration, relative to the town root. It
+// holds peer tokens, so it is written 0600 and ignored by the HQ .gitignore.
+const ConfigFile = "settings/federation.json"
+
+// Transports.
+const (
+	TransportSSH  = "ssh"
+	TransportHTTP = "http"
+)
+
+// Errors returned when routing or receiving.
+var (
+	ErrUnknownTown  = errors.New("unknown town")
+	ErrUnauthorized = errors.New("invalid federation token")
+	ErrNotTrusted   = errors.New("not trusted")
+)
+
+// Trust lists what a peer town may do in this town. Every configured peer
+// may ask for the status of beads it slung here.
+type Trust struct {
+	Mail   bool     `json:"mail,omitempty"`   // Send mail to agents here
+	Sling  bool     `json:"sling,omitempty"`  // Sling work to rigs here
+	Rigs   []string `json:"rigs,omitempty"`   // Rigs work may be slung to (empty = all)
+	Status bool     `json:"status,omitempty"` // Query the status of any bead here
+}
+
+// Peer is a remote town.
+type Peer struct {
+	Transport string `json:"transport"`           // ssh or http
+	Host      string `json:"host,omitempty"`      // ssh: [user@]host
+	KeyPath   string `json:"key_path,omitempty"`  // ssh: private key (default: ssh's own)
+	TownPath  string `json:"town_path,omitempty"` // ssh: town root on the host (default ~/gt)
+	URL       string `json:"url,omitempty"`       // http: the peer's dashboard URL
+	Token     string `json:"token"`               // Shared secret, sent both ways
+	Trust     Trust  `json:"trust"`
+}
+
+// Hostname returns the host the peer is reached at, for matching addresses.
+func (p *Peer) Hostname() string {
+	if p.Transport == TransportHTTP {
+		if u, err := url.Parse(p.URL); err == nil {
+			return u.Hostname()
+		}
+		return ""
+	}
+	host := p.Host
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return host
+}
+
+// Config is the federation configuration.
+type Config struct {
+	// Town is this town's name as peers know it (default: mayor/town.json name).
+	Town  string           `json:"town,omitempty"`
+	Peers map[string]*Peer `json:"peers"`
+}
+
+// ConfigPath returns the path of the federation configuration.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, ConfigFile)
+}
+
+// LoadConfig reads the federation configuration. Returns nil, nil when the
+// town has none (federation is disabled).
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading federation config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing federation config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveConfig writes the federation configuration.
+func SaveConfig(townRoot string, cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := util.EnsureDirAndWriteJSONWithPerm(ConfigPath(townRoot), cfg, 0600); err != nil {
+		return fmt.Errorf("writing federation config: %w", err)
+	}
+	return nil
+}
+
+// Validate checks every peer's transport settings.
+func (c *Config) Validate() error {
+	for name, p := range c.Peers {
+		if name == "" || strings.ContainsAny(name, "@:/ ") {
+			return fmt.Errorf("federation: invalid town name %q", name)
+		}
+		switch p.Transport {
+		case TransportSSH:
+			if p.Host == "" {
+				return fmt.Errorf("federation: town %s: ssh transport needs a host", name)
+			}
+		case TransportHTTP:
+			u, err := url.Parse(p.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("federation: town %s: http transport needs an http(s) url", name)
+			}
+		default:
+			return fmt.Errorf("federation: town %s: unknown transport %q (want ssh or http)", name, p.Transport)
+		}
+		if p.Token == "" {
+			return fmt.Errorf("federation: town %s: missing token", name)
+		}
+	}
+	return nil
+}
+
+// PeerNames returns the configured towns, sorted.
+func (c *Config) PeerNames() []string {
+	names := make([]string, 0, len(c.Peers))
+	for name := range c.Peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Peer returns the named town.
+func (c *Config) Peer(town string) (*Peer, error) {
+	if c != nil {
+		if p, ok := c.Peers[town]; ok {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q (add it with gt federation add)", ErrUnknownTown, town)
+}
+
+// Authenticate returns the peer sending as town if token is its token.
+func (c *Config) Authenticate(town, token string) (*Peer, error) {
+	p, err := c.Peer(town)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.Token)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	return p, nil
+}
+
+// LocalName returns this town's federated name.
+func LocalName(townRoot string, cfg *Config) string {
+	if cfg != nil && cfg.Town != "" {
+		return cfg.Town
+	}
+	if tc, err := config.LoadTownConfig(filepath.Join(townRoot, "mayor", "town.json")); err == nil {
+		return tc.Name
+	}
+	return filepath.Base(townRoot)
+}
+
+// NewToken returns a random token for a new peer.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// IsAddress reports whether s is a federated address (town@...).
+func IsAddress(s string) bool {
+	addr, err := connection.ParseAddress(s)
+	return err == nil && addr.IsFederated()
+}
+
+// ParseAddress parses a federated address.
+func ParseAddress(s string) (*connection.Address, error) {
+	addr, err := connection.ParseAddress(s)
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IsFederated() {
+		return nil, fmt.Errorf("%q is not a federated address (want town@host:rig/agent)", s)
+	}
+	return addr, nil
+}
+
+// Route returns the peer an address is delivered through, checking that
+// the address's host (if any) is where that town lives.
+func (c *Config) Route(addr *connection.Address) (*Peer, error) {
+	p, err := c.Peer(addr.Town)
+	if err != nil {
+		return nil, err
+	}
+	if addr.Machine != "" && !strings.EqualFold(addr.Machine, p.Hostname()) {
+		return nil, fmt.Errorf("town %s is configured at %s, not %s", addr.Town, p.Hostname(), addr.Machine)
+	}
+	return p, nil
+}
+
+// LocalAddress returns the part of addr inside its town: "rig/agent", or
+// "mayor/" for town-level agents.
+func LocalAddress(addr *connection.Address) string {
+	return addr.RigPath()
+}
+
+// RemoteLabel is the label on a bead slung to another town, naming the bead
+// that town created for it.
+func RemoteLabel(town, beadID string) string {
+	return "remote:" + town + ":" + beadID
+}
+
+// ParseRemoteLabel finds a RemoteLabel among labels.
+func ParseRemoteLabel(labels []string) (town, beadID string, ok bool) {
+	for _, l := range labels {
+		parts := strings.SplitN(l, ":", 3)
+		if len(parts) == 3 && parts[0] == "remote" && parts[1] != "" && parts[2] != "" {
+			return parts[1], parts[2], true
+		}
+	}
+	return "", "", false
+}
+
+// OriginLabel is the label on a bead created for work slung from another
+// town, naming the sending town's bead.
+func OriginLabel(town, beadID string) string {
+	return "federated:" + town + ":" + beadID
+}
+
+// OriginTown returns the town that slung a bead here, from its OriginLabel,
+// or "" for a bead made in this town.
+func OriginTown(labels []string) string {
+	for _, l := range labels {
+		if rest, ok := strings.CutPrefix(l, "federated:"); ok {
+			if town, _, ok := strings.Cut(rest, ":"); ok {
+				return town
+			}
+		}
+	}
+	return ""
+}
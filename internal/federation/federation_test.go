@@ -0,0 +1,260 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder is a Town that records what it was asked to do.
+type recorder struct {
+	mail   []string // "from -> to: subject"
+	slings []string // "fromTown -> target: title"
+}
+
+func (r *recorder) SendMail(from, to string, m *Mail) error {
+	r.mail = append(r.mail, from+" -> "+to+": "+m.Subject)
+	return nil
+}
+
+func (r *recorder) Sling(fromTown, target string, s *Sling) (string, error) {
+	r.slings = append(r.slings, fromTown+" -> "+target+": "+s.Title)
+	return "gt-remote1", nil
+}
+
+func (r *recorder) BeadStatus(ids []string) ([]BeadStatus, error) {
+	var out []BeadStatus
+	for _, id := range ids {
+		switch id {
+		case "gt-remote1":
+			out = append(out, BeadStatus{ID: id, Status: "closed", Origin: "home"})
+		case "gt-local":
+			out = append(out, BeadStatus{ID: id, Status: "open"})
+		}
+	}
+	return out, nil
+}
+
+// writeTown creates a town root named name with the given federation config.
+func writeTown(t *testing.T, name string, cfg *Config) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	town := `{"type":"town","version":2,"name":"` + name + `"}`
+	if err := os.WriteFile(filepath.Join(root, "mayor", "town.json"), []byte(town), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg != nil {
+		if err := SaveConfig(root, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestHandleTrust(t *testing.T) {
+	cfg := &Config{Peers: map[string]*Peer{
+		"home": {Transport: TransportSSH, Host: "box", Token: "secret", Trust: Trust{Mail: true}},
+		"lab":  {Transport: TransportSSH, Host: "lab", Token: "other", Trust: Trust{Sling: true, Rigs: []string{"gastown"}, Status: true}},
+	}}
+	town := &recorder{}
+	mail := &Mail{Subject: "hi"}
+
+	tests := []struct {
+		name    string
+		env     Envelope
+		wantErr error
+	}{
+		{"unknown town", Envelope{Kind: KindPing, From: "nobody", Token: "secret"}, ErrUnknownTown},
+		{"bad token", Envelope{Kind: KindPing, From: "home", Token: "nope"}, ErrUnauthorized},
+		{"ping", Envelope{Kind: KindPing, From: "home", Token: "secret"}, nil},
+		{"mail", Envelope{Kind: KindMail, From: "home", Token: "secret", Sender: "gastown/nux", To: "gastown/max", Mail: mail}, nil},
+		{"mail not trusted", Envelope{Kind: KindMail, From: "lab", Token: "other", To: "gastown/max", Mail: mail}, ErrNotTrusted},
+		{"relay", Envelope{Kind: KindMail, From: "home", Token: "secret", To: "lab@gastown/max", Mail: mail}, ErrNotTrusted},
+		{"sling not trusted", Envelope{Kind: KindSling, From: "home", Token: "secret", To: "gastown", Sling: &Sling{Title: "x"}}, ErrNotTrusted},
+		{"sling other rig", Envelope{Kind: KindSling, From: "lab", Token: "other", To: "beads/", Sling: &Sling{Title: "x"}}, ErrNotTrusted},
+		{"sling", Envelope{Kind: KindSling, From: "lab", Token: "other", To: "gastown/", Sling: &Sling{Bead: "hq-1", Title: "Fix it"}}, nil},
+		// home may only see the bead it slung; lab is trusted with any.
+		{"status", Envelope{Kind: KindStatus, From: "home", Token: "secret", Beads: []string{"gt-remote1", "gt-local", "gt-gone"}}, nil},
+		{"status trusted", Envelope{Kind: KindStatus, From: "lab", Token: "other", Beads: []string{"gt-local"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, err := Handle("", cfg, town, &tt.env)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || reply.OK || reply.Error == "" {
+					t.Errorf("Handle = %+v, %v; want %v", reply, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reply.OK {
+				t.Fatalf("Handle = %+v, %v", reply, err)
+			}
+			switch tt.env.Kind {
+			case KindSling:
+				if reply.Bead != "gt-remote1" {
+					t.Errorf("Bead = %q", reply.Bead)
+				}
+			case KindStatus:
+				want := "gt-remote1"
+				if tt.env.From == "lab" {
+					want = "gt-local"
+				}
+				if len(reply.Beads) != 1 || reply.Beads[0].ID != want {
+					t.Errorf("Beads = %+v, want only %s", reply.Beads, want)
+				}
+			}
+		})
+	}
+
+	if len(town.mail) != 1 || town.mail[0] != "home@gastown/nux -> gastown/max: hi" {
+		t.Errorf("mail = %v", town.mail)
+	}
+	if len(town.slings) != 1 || town.slings[0] != "lab -> gastown: Fix it" {
+		t.Errorf("slings = %v", town.slings)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	cfg := &Config{Peers: map[string]*Peer{
+		"acme": {Transport: TransportSSH, Host: "deploy@build01", Token: "t"},
+		"web":  {Transport: TransportHTTP, URL: "https://gt.example.com:8443", Token: "t"},
+	}}
+
+	for _, tt := range []struct {
+		addr      string
+		wantLocal string
+		wantErr   bool
+	}{
+		{"acme@gastown/nux", "gastown/nux", false},
+		{"acme@build01:gastown/crew/max", "gastown/crew/max", false},
+		{"acme@mayor/", "mayor/", false},
+		{"web@gt.example.com:gastown", "gastown/", false},
+		{"acme@elsewhere:gastown/nux", "", true},
+		{"ghost@gastown/nux", "", true},
+	} {
+		addr, err := ParseAddress(tt.addr)
+		if err != nil {
+			t.Fatalf("ParseAddress(%q): %v", tt.addr, err)
+		}
+		_, err = cfg.Route(addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Route(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+		}
+		if !tt.wantErr && LocalAddress(addr) != tt.wantLocal {
+			t.Errorf("LocalAddress(%q) = %q, want %q", tt.addr, LocalAddress(addr), tt.wantLocal)
+		}
+	}
+
+	for _, s := range []string{"gastown/nux", "@town", "@rig/gastown", "list:oncall", "mayor/"} {
+		if IsAddress(s) {
+			t.Errorf("IsAddress(%q) = true", s)
+		}
+	}
+}
+
+func TestSendOverHTTP(t *testing.T) {
+	town := &recorder{}
+	remote := writeTown(t, "acme", &Config{Peers: map[string]*Peer{
+		"home": {Transport: TransportSSH, Host: "home-box", Token: "shared", Trust: Trust{Mail: true}},
+	}})
+	srv := httptest.NewServer(NewReceiver(remote, town, t.Logf))
+	defer srv.Close()
+
+	home := writeTown(t, "home", &Config{Peers: map[string]*Peer{
+		"acme": {Transport: TransportHTTP, URL: srv.URL, Token: "shared"},
+	}})
+
+	env := &Envelope{Kind: KindMail, Sender: "mayor/", Mail: &Mail{Subject: "status?"}}
+	reply, err := SendTo(context.Background(), home, "acme@gastown/nux", env)
+	if err != nil {
+		t.Fatalf("SendTo: %v", err)
+	}
+	if reply.Town != "acme" || len(town.mail) != 1 || town.mail[0] != "home@mayor/ -> gastown/nux: status?" {
+		t.Errorf("reply %+v, mail %v", reply, town.mail)
+	}
+
+	// Sling isn't trusted: the refusal comes back as an error.
+	_, err = SendTo(context.Background(), home, "acme@gastown", &Envelope{Kind: KindSling, Sling: &Sling{Title: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "may not sling") {
+		t.Errorf("untrusted sling = %v", err)
+	}
+
+	// A wrong token is turned away.
+	cfg, _ := LoadConfig(home)
+	cfg.Peers["acme"].Token = "stolen"
+	if err := SaveConfig(home, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Send(context.Background(), home, "acme", &Envelope{Kind: KindPing}); err == nil || !strings.Contains(err.Error(), ErrUnauthorized.Error()) {
+		t.Errorf("ping with wrong token = %v", err)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	root := writeTown(t, "acme", &Config{Peers: map[string]*Peer{
+		"home": {Transport: TransportSSH, Host: "box", Token: "shared"},
+	}})
+
+	var out bytes.Buffer
+	in := strings.NewReader(`{"kind":"ping","from":"home","token":"shared"}`)
+	if err := Receive(root, &recorder{}, in, &out); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	var reply Reply
+	if err := json.Unmarshal(out.Bytes(), &reply); err != nil || !reply.OK || reply.Town != "acme" {
+		t.Errorf("reply = %s (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := Receive(root, &recorder{}, strings.NewReader("not json"), &out); err == nil {
+		t.Error("Receive accepted a malformed envelope")
+	}
+	if !strings.Contains(out.String(), `"error"`) {
+		t.Errorf("no error reply: %s", out.String())
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for name, p := range map[string]*Peer{
+		"no host":   {Transport: TransportSSH, Token: "t"},
+		"bad url":   {Transport: TransportHTTP, URL: "ftp://x", Token: "t"},
+		"no token":  {Transport: TransportSSH, Host: "h"},
+		"transport": {Transport: "carrier-pigeon", Token: "t"},
+	} {
+		cfg := &Config{Peers: map[string]*Peer{"acme": p}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: Validate accepted %+v", name, p)
+		}
+	}
+	if err := (&Config{Peers: map[string]*Peer{"a@b": {Transport: TransportSSH, Host: "h", Token: "t"}}}).Validate(); err == nil {
+		t.Error("Validate accepted a town name with '@'")
+	}
+}
+
+func TestRemoteLabel(t *testing.T) {
+	town, id, ok := ParseRemoteLabel([]string{"gt:task", RemoteLabel("acme", "gt-abc")})
+	if !ok || town != "acme" || id != "gt-abc" {
+		t.Errorf("ParseRemoteLabel = %q, %q, %v", town, id, ok)
+	}
+	if _, _, ok := ParseRemoteLabel([]string{"remote:", "federated:acme:gt-abc"}); ok {
+		t.Error("ParseRemoteLabel matched a non-remote label")
+	}
+}
+
+func TestOriginTown(t *testing.T) {
+	if got := OriginTown([]string{"gt:task", OriginLabel("acme", "hq-1")}); got != "acme" {
+		t.Errorf("OriginTown = %q, want acme", got)
+	}
+	if got := OriginTown([]string{"gt:task"}); got != "" {
+		t.Errorf("OriginTown = %q, want empty", got)
+	}
+}
@@ -0,0 +1,217 @@
+package federation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxEnvelopeSize bounds a delivery body.
+	maxEnvelopeSize = 1 << 20
+
+	// maxStatusBeads bounds one status query.
+	maxStatusBeads = 200
+)
+
+// Town carries out deliveries in the receiving town. The real
+// implementation lives with the gt commands (it needs mail and sling);
+// tests substitute a recorder.
+type Town interface {
+	// SendMail delivers a message from a federated address to a local one.
+	SendMail(from, to string, m *Mail) error
+	// Sling creates a bead for the work and slings it to target (a rig or
+	// rig/agent), returning the bead ID. Repeat deliveries of the same
+	// sending bead return the existing bead.
+	Sling(fromTown, target string, s *Sling) (string, error)
+	// BeadStatus reports on local beads, with the town each was slung from;
+	// unknown IDs are left out.
+	BeadStatus(ids []string) ([]BeadStatus, error)
+}
+
+// Handle authenticates, authorizes and carries out one envelope. The reply
+// is always non-nil; on failure it carries the error message, and the
+// error wraps ErrUnknownTown, ErrUnauthorized or ErrNotTrusted when the
+// sender was turned away.
+func Handle(townRoot string, cfg *Config, town Town, env *Envelope) (*Reply, error) {
+	reply := &Reply{Town: LocalName(townRoot, cfg)}
+	err := handle(cfg, town, env, reply)
+	if err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.OK = true
+	}
+	return reply, err
+}
+
+func handle(cfg *Config, town Town, env *Envelope, reply *Reply) error {
+	if cfg == nil {
+		return fmt.Errorf("%w: federation is not enabled here", ErrUnknownTown)
+	}
+	peer, err := cfg.Authenticate(env.From, env.Token)
+	if err != nil {
+		return err
+	}
+	if IsAddress(env.To) {
+		return fmt.Errorf("%w: %s may not relay to %s", ErrNotTrusted, env.From, env.To)
+	}
+
+	switch env.Kind {
+	case KindPing:
+		return nil
+
+	case KindMail:
+		if !peer.Trust.Mail {
+			return fmt.Errorf("%w: %s may not send mail here", ErrNotTrusted, env.From)
+		}
+		if env.Mail == nil || env.Mail.Subject == "" || env.To == "" {
+			return errors.New("mail needs a recipient and a subject")
+		}
+		sender := env.Sender
+		if sender == "" {
+			sender = "mayor/"
+		}
+		return town.SendMail(env.From+"@"+sender, env.To, env.Mail)
+
+	case KindSling:
+		if !peer.Trust.Sling {
+			return fmt.Errorf("%w: %s may not sling work here", ErrNotTrusted, env.From)
+		}
+		target := strings.TrimRight(env.To, "/")
+		if env.Sling == nil || env.Sling.Title == "" || target == "" {
+			return errors.New("sling needs a target and a titled bead")
+		}
+		rig := strings.SplitN(target, "/", 2)[0]
+		if !peer.Trust.allowsRig(rig) {
+			return fmt.Errorf("%w: %s may not sling work to rig %s", ErrNotTrusted, env.From, rig)
+		}
+		id, err := town.Sling(env.From, target, env.Sling)
+		reply.Bead = id
+		return err
+
+	case KindStatus:
+		if len(env.Beads) > maxStatusBeads {
+			return fmt.Errorf("status query for %d beads exceeds the limit of %d", len(env.Beads), maxStatusBeads)
+		}
+		beads, err := town.BeadStatus(env.Beads)
+		if err != nil {
+			return err
+		}
+		// Without status trust a peer only sees the beads it slung here;
+		// others are left out as if unknown.
+		for _, b := range beads {
+			if peer.Trust.Status || b.Origin == env.From {
+				reply.Beads = append(reply.Beads, b)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown envelope kind %q", env.Kind)
+	}
+}
+
+func (t Trust) allowsRig(rig string) bool {
+	if len(t.Rigs) == 0 {
+		return true
+	}
+	for _, r := range t.Rigs {
+		if r == rig {
+			return true
+		}
+	}
+	return false
+}
+
+// Receive reads an envelope from r, handles it and writes the reply to w.
+// It is the far end of the SSH transport.
+func Receive(townRoot string, town Town, r io.Reader, w io.Writer) error {
+	var env Envelope
+	var reply *Reply
+	var err error
+	if decErr := json.NewDecoder(io.LimitReader(r, maxEnvelopeSize)).Decode(&env); decErr != nil {
+		err = fmt.Errorf("reading envelope: %w", decErr)
+		reply = &Reply{Error: err.Error()}
+	} else {
+		cfg, cfgErr := LoadConfig(townRoot)
+		if cfgErr != nil {
+			err = cfgErr
+			reply = &Reply{Error: err.Error()}
+		} else {
+			reply, err = Handle(townRoot, cfg, town, &env)
+		}
+	}
+	if encErr := json.NewEncoder(w).Encode(reply); encErr != nil {
+		return encErr
+	}
+	return err
+}
+
+// Receiver is the HTTP end of federation, mounted at DeliverPath on the
+// dashboard. The configuration is reloaded per delivery, so peer and trust
+// edits apply without a restart.
+type Receiver struct {
+	townRoot string
+	town     Town
+	logf     func(format string, args ...interface{})
+}
+
+// NewReceiver creates a receiver acting on the town.
+func NewReceiver(townRoot string, town Town, logf func(format string, args ...interface{})) *Receiver {
+	return &Receiver{townRoot: townRoot, town: town, logf: logf}
+}
+
+// ServeHTTP authenticates and handles a delivery.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != DeliverPath {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cfg, err := LoadConfig(r.townRoot)
+	if err != nil {
+		r.logf("federation: %v", err)
+		http.Error(w, "federation misconfigured", http.StatusInternalServerError)
+		return
+	}
+	if cfg == nil {
+		http.NotFound(w, req) // Federation not enabled
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxEnvelopeSize+1))
+	if err != nil || len(body) > maxEnvelopeSize {
+		http.Error(w, "envelope too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		http.Error(w, "invalid envelope", http.StatusBadRequest)
+		return
+	}
+
+	reply, err := Handle(r.townRoot, cfg, r.town, &env)
+	status := http.StatusOK
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrUnknownTown), errors.Is(err, ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, ErrNotTrusted):
+		status = http.StatusForbidden
+	default:
+		status = http.StatusUnprocessableEntity
+	}
+	if err != nil {
+		r.logf("federation: %s from %s (%s): %v", env.Kind, env.From, req.RemoteAddr, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(reply)
+}
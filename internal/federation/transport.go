@@ -0,0 +1,231 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Envelope kinds.
+const (
+	KindPing   = "ping"
+	KindMail   = "mail"
+	KindSling  = "sling"
+	KindStatus = "status"
+)
+
+// DeliverPath is where the dashboard serves federation deliveries.
+const DeliverPath = "/federation/deliver"
+
+// sendTimeout bounds one delivery, including the remote sling.
+const sendTimeout = 2 * time.Minute
+
+// Envelope is one delivery between towns.
+type Envelope struct {
+	ID     string    `json:"id"`
+	Kind   string    `json:"kind"`
+	From   string    `json:"from"`             // Sending town
+	Token  string    `json:"token"`            // The sending town's token for this peer
+	Sender string    `json:"sender,omitempty"` // Sending agent, as addressed in its own town
+	To     string    `json:"to,omitempty"`     // Recipient or sling target, local to the receiving town
+	SentAt time.Time `json:"sent_at"`
+
+	Mail  *Mail    `json:"mail,omitempty"`
+	Sling *Sling   `json:"sling,omitempty"`
+	Beads []string `json:"beads,omitempty"` // status: beads to report on
+}
+
+// Mail is a message for an agent in the receiving town.
+type Mail struct {
+	Subject  string `json:"subject"`
+	Body     string `json:"body,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Type     string `json:"type,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+}
+
+// Sling is work for a rig in the receiving town. The receiving town creates
+// its own bead for it and slings that.
+type Sling struct {
+	Bead        string `json:"bead"` // The bead in the sending town
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Priority    int    `json:"priority"`
+	Args        string `json:"args,omitempty"`
+}
+
+// BeadStatus is a bead's state as reported by its town.
+type BeadStatus struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+
+	// Origin is the town that slung the bead here ("" if made here), for
+	// checking who may see it. It isn't sent.
+	Origin string `json:"-"`
+}
+
+// Reply is the receiving town's answer.
+type Reply struct {
+	OK    bool         `json:"ok"`
+	Error string       `json:"error,omitempty"`
+	Town  string       `json:"town,omitempty"` // Receiving town
+	Bead  string       `json:"bead,omitempty"` // sling: bead created in the receiving town
+	Beads []BeadStatus `json:"beads,omitempty"`
+}
+
+// Transport delivers envelopes to one peer.
+type Transport interface {
+	Deliver(ctx context.Context, env *Envelope) (*Reply, error)
+}
+
+// NewTransport returns the transport for a peer.
+func NewTransport(p *Peer) Transport {
+	if p.Transport == TransportHTTP {
+		return &httpTransport{url: strings.TrimRight(p.URL, "/") + DeliverPath, client: http.DefaultClient}
+	}
+	return &sshTransport{peer: p}
+}
+
+// transportFor is replaced in tests.
+var transportFor = NewTransport
+
+// Send delivers env to town through its configured transport, filling in
+// the sender fields. A reply carrying an error is returned as an error.
+func Send(ctx context.Context, townRoot, town string, env *Envelope) (*Reply, error) {
+	cfg, err := LoadConfig(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	p, err := cfg.Peer(town)
+	if err != nil {
+		return nil, err
+	}
+	return sendTo(ctx, townRoot, cfg, town, p, env)
+}
+
+// SendTo delivers env to the town of a federated address.
+func SendTo(ctx context.Context, townRoot, address string, env *Envelope) (*Reply, error) {
+	addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadConfig(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	p, err := cfg.Route(addr)
+	if err != nil {
+		return nil, err
+	}
+	env.To = LocalAddress(addr)
+	return sendTo(ctx, townRoot, cfg, addr.Town, p, env)
+}
+
+func sendTo(ctx context.Context, townRoot string, cfg *Config, town string, p *Peer, env *Envelope) (*Reply, error) {
+	env.From = LocalName(townRoot, cfg)
+	env.Token = p.Token
+	if env.ID == "" {
+		id, err := NewToken()
+		if err != nil {
+			return nil, err
+		}
+		env.ID = id[:16]
+	}
+	if env.SentAt.IsZero() {
+		env.SentAt = time.Now().UTC()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	reply, err := transportFor(p).Deliver(ctx, env)
+	if err != nil {
+		return nil, fmt.Errorf("delivering to %s: %w", town, err)
+	}
+	if !reply.OK {
+		return reply, fmt.Errorf("%s refused %s: %s", town, env.Kind, reply.Error)
+	}
+	return reply, nil
+}
+
+// sshTransport runs gt federation receive on the peer's host.
+type sshTransport struct {
+	peer *Peer
+}
+
+func (t *sshTransport) Deliver(ctx context.Context, env *Envelope) (*Reply, error) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	townPath := t.peer.TownPath
+	if townPath == "" {
+		townPath = "~/gt"
+	}
+	args := []string{"-o", "BatchMode=yes"}
+	if t.peer.KeyPath != "" {
+		args = append(args, "-i", t.peer.KeyPath)
+	}
+	args = append(args, t.peer.Host, "cd "+config.ShellQuote(townPath)+" && gt federation receive")
+
+	cmd := exec.CommandContext(ctx, "ssh", args...) //nolint:gosec // G204: host and path come from town config
+	cmd.Stdin = bytes.NewReader(body)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var reply Reply
+	if jsonErr := json.Unmarshal(out, &reply); jsonErr == nil && (reply.OK || reply.Error != "") {
+		return &reply, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %v: %s", t.peer.Host, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil, fmt.Errorf("ssh %s: unreadable reply %q", t.peer.Host, strings.TrimSpace(string(out)))
+}
+
+// httpTransport posts to the peer's dashboard.
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (t *httpTransport) Deliver(ctx context.Context, env *Envelope) (*Reply, error) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxEnvelopeSize))
+	if err != nil {
+		return nil, err
+	}
+	var reply Reply
+	if err := json.Unmarshal(data, &reply); err != nil || (!reply.OK && reply.Error == "") {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.New("federation is not enabled on the peer's dashboard")
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return &reply, nil
+}
@@ -0,0 +1,149 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTimeout bounds each API request.
+const requestTimeout = 60 * time.Second
+
+// perPage is the page size for list requests. Lists return the first page
+// only: the most recent open pull requests and issues.
+const perPage = 50
+
+// APIError is an error response from a forge API.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// client is the JSON REST client the providers share.
+type client struct {
+	base   string // API base URL, no trailing slash
+	header http.Header
+	http   *http.Client
+}
+
+func newClient(base string, header http.Header) *client {
+	return &client{base: base, header: header, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// do sends a request with in (if non-nil) as its JSON body and decodes the
+// response into out (if non-nil).
+func (c *client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Method: method, Path: path}
+		var e struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil {
+			apiErr.Message = e.Message
+			if apiErr.Message == "" {
+				apiErr.Message = e.Error
+			}
+		}
+		return apiErr
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: parsing response: %w", method, path, err)
+	}
+	return nil
+}
+
+// tokenEnv lists the environment variables holding each kind's token.
+var tokenEnv = map[string][]string{
+	KindGitHub: {"GITHUB_TOKEN", "GH_TOKEN"},
+	KindGitLab: {"GITLAB_TOKEN"},
+	KindGitea:  {"GITEA_TOKEN"},
+}
+
+// envToken finds a token for a forge host in the environment, or for
+// GitHub, from the gh CLI's login.
+func envToken(kind, host string) string {
+	for _, name := range tokenEnv[kind] {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	if kind == KindGitHub {
+		return ghToken(host)
+	}
+	return ""
+}
+
+// ghTokens caches gh auth token by host; gh is asked once per process.
+var ghTokens sync.Map
+
+// ghToken returns the gh CLI's token for host, or "" if gh isn't installed
+// or logged in. A variable so tests never run gh.
+var ghToken = func(host string) string {
+	if v, ok := ghTokens.Load(host); ok {
+		return v.(string)
+	}
+	token := ""
+	if _, err := exec.LookPath("gh"); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host).Output() //nolint:gosec // G204: host comes from a rig's git URL
+		if err == nil {
+			token = strings.TrimSpace(string(out))
+		}
+	}
+	ghTokens.Store(host, token)
+	return token
+}
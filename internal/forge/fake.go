@@ -0,0 +1,272 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is an in-memory Provider for tests. Pull requests and issues share
+// one numbering. Tests may edit PRs, Issues and CommitStatuses directly to
+// play the forge's part (a check finishing, a reviewer approving).
+type Fake struct {
+	mu   sync.Mutex
+	repo Repo
+	next int
+
+	PRs            map[int]*PullRequest
+	Issues         map[int]*Issue
+	Files          map[int][]File
+	CommitStatuses map[string][]Check   // By commit SHA
+	Notes          map[string][]Comment // By "pr#N" or "issue#N"
+
+	Merged   []string // "number:method:sha" per successful MergePR
+	Updates  int      // UpdatePR calls
+	MergeErr error    // Returned by MergePR when set
+}
+
+var _ Provider = (*Fake)(nil)
+
+// NewFake returns an empty fake forge holding repo.
+func NewFake(repo Repo) *Fake {
+	return &Fake{
+		repo:           repo,
+		next:           1,
+		PRs:            make(map[int]*PullRequest),
+		Issues:         make(map[int]*Issue),
+		Files:          make(map[int][]File),
+		CommitStatuses: make(map[string][]Check),
+		Notes:          make(map[string][]Comment),
+	}
+}
+
+// Kind returns "fake".
+func (f *Fake) Kind() string { return "fake" }
+
+// Repo returns the fake's repository.
+func (f *Fake) Repo() Repo { return f.repo }
+
+func (f *Fake) url(kind string, number int) string {
+	return fmt.Sprintf("https://%s/%s/%s/%d", f.repo.Host, f.repo.FullName(), kind, number)
+}
+
+// getPR returns a copy of a PR, with its head's statuses when it has no
+// checks of its own. Callers hold f.mu.
+func (f *Fake) getPR(number int) (*PullRequest, error) {
+	pr, ok := f.PRs[number]
+	if !ok {
+		return nil, &APIError{StatusCode: 404, Method: "GET", Path: fmt.Sprintf("pulls/%d", number)}
+	}
+	cp := *pr
+	if len(cp.Checks) == 0 {
+		cp.Checks = append([]Check(nil), f.CommitStatuses[cp.HeadSHA]...)
+	}
+	return &cp, nil
+}
+
+// sortedPRs returns the numbers of PRs in the given state, in order.
+func (f *Fake) sortedPRs(state string) []int {
+	var numbers []int
+	for n, pr := range f.PRs {
+		if pr.State == state {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// ListPRs returns the open pull requests.
+func (f *Fake) ListPRs(_ context.Context) ([]*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var prs []*PullRequest
+	for _, n := range f.sortedPRs(PROpen) {
+		pr, _ := f.getPR(n)
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// GetPR returns a pull request.
+func (f *Fake) GetPR(_ context.Context, number int) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.getPR(number)
+}
+
+// FindPR returns the open pull request from head into base, or nil.
+func (f *Fake) FindPR(_ context.Context, head, base string) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, n := range f.sortedPRs(PROpen) {
+		if pr := f.PRs[n]; pr.HeadRef == head && pr.BaseRef == base {
+			return f.getPR(n)
+		}
+	}
+	return nil, nil
+}
+
+// CreatePR opens a pull request.
+func (f *Fake) CreatePR(_ context.Context, req PRRequest) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	pr := &PullRequest{
+		Number:    f.next,
+		URL:       f.url("pull", f.next),
+		Title:     req.Title,
+		Body:      req.Body,
+		State:     PROpen,
+		HeadRef:   req.Head,
+		BaseRef:   req.Base,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	f.PRs[pr.Number] = pr
+	f.next++
+	return f.getPR(pr.Number)
+}
+
+// UpdatePR rewrites a pull request's title and body.
+func (f *Fake) UpdatePR(_ context.Context, number int, req PRRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, ok := f.PRs[number]
+	if !ok {
+		return fmt.Errorf("no PR #%d", number)
+	}
+	f.Updates++
+	pr.Title, pr.Body, pr.UpdatedAt = req.Title, req.Body, time.Now()
+	return nil
+}
+
+// MergePR merges a pull request if its head is still headSHA.
+func (f *Fake) MergePR(_ context.Context, number int, method, headSHA string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.MergeErr != nil {
+		return f.MergeErr
+	}
+	pr, ok := f.PRs[number]
+	if !ok {
+		return fmt.Errorf("no PR #%d", number)
+	}
+	if pr.State != PROpen {
+		return fmt.Errorf("PR #%d is %s", number, pr.State)
+	}
+	if headSHA != "" && pr.HeadSHA != headSHA {
+		return errors.New("head moved")
+	}
+	f.Merged = append(f.Merged, fmt.Sprintf("%d:%s:%s", number, method, headSHA))
+	pr.State, pr.MergeCommit = PRMerged, "c0ffee"
+	return nil
+}
+
+// PRFiles returns the files set for a pull request.
+func (f *Fake) PRFiles(_ context.Context, number int) ([]File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.PRs[number]; !ok {
+		return nil, fmt.Errorf("no PR #%d", number)
+	}
+	return append([]File(nil), f.Files[number]...), nil
+}
+
+// ListIssues returns the open issues.
+func (f *Fake) ListIssues(_ context.Context) ([]*Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var numbers []int
+	for n, i := range f.Issues {
+		if i.State == IssueOpen {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	issues := make([]*Issue, 0, len(numbers))
+	for _, n := range numbers {
+		cp := *f.Issues[n]
+		issues = append(issues, &cp)
+	}
+	return issues, nil
+}
+
+// GetIssue returns an issue.
+func (f *Fake) GetIssue(_ context.Context, number int) (*Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.Issues[number]
+	if !ok {
+		return nil, &APIError{StatusCode: 404, Method: "GET", Path: fmt.Sprintf("issues/%d", number)}
+	}
+	cp := *i
+	return &cp, nil
+}
+
+// CreateIssue opens an issue.
+func (f *Fake) CreateIssue(_ context.Context, req IssueRequest) (*Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	i := &Issue{
+		Number:    f.next,
+		URL:       f.url("issues", f.next),
+		Title:     req.Title,
+		Body:      req.Body,
+		State:     IssueOpen,
+		Labels:    req.Labels,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	f.Issues[i.Number] = i
+	f.next++
+	cp := *i
+	return &cp, nil
+}
+
+// Statuses returns the statuses set on sha.
+func (f *Fake) Statuses(_ context.Context, sha string) ([]Check, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Check(nil), f.CommitStatuses[sha]...), nil
+}
+
+// SetStatus sets a status on sha, replacing one with the same name.
+func (f *Fake) SetStatus(_ context.Context, sha string, check Check) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checks := f.CommitStatuses[sha]
+	for i := range checks {
+		if checks[i].Name == check.Name {
+			checks[i] = check
+			return nil
+		}
+	}
+	f.CommitStatuses[sha] = append(checks, check)
+	return nil
+}
+
+func noteKey(kind ItemKind, number int) string {
+	return fmt.Sprintf("%s#%d", kind, number)
+}
+
+// Comments returns the comments on a pull request or issue.
+func (f *Fake) Comments(_ context.Context, kind ItemKind, number int) ([]Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Comment(nil), f.Notes[noteKey(kind, number)]...), nil
+}
+
+// AddComment comments on a pull request or issue.
+func (f *Fake) AddComment(_ context.Context, kind ItemKind, number int, body string) (*Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := noteKey(kind, number)
+	c := Comment{ID: int64(len(f.Notes[key]) + 1), Author: "gastown", Body: body, CreatedAt: time.Now()}
+	f.Notes[key] = append(f.Notes[key], c)
+	return &c, nil
+}
@@ -0,0 +1,465 @@
+// Package forge talks to the git forge hosting a rig's repository — GitHub,
+// GitLab or Gitea — about its pull requests, issues, commit statuses and
+// comments, over each forge's REST API.
+//
+// A rig's forge follows from its git URL (RigEntry.GitURL): github.com,
+// gitlab.com, codeberg.org and gitea.com are known, and self-hosted forges
+// are listed by host in settings/forge.json with their kind, API URL and
+// token. Without a configured token, providers read GITHUB_TOKEN (or
+// GH_TOKEN), GITLAB_TOKEN or GITEA_TOKEN, and GitHub falls back to the gh
+// CLI's login when gh is installed.
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Forge kinds.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindGitea  = "gitea"
+)
+
+// Pull request states.
+const (
+	PROpen   = "open"
+	PRClosed = "closed" // Closed without merging
+	PRMerged = "merged"
+)
+
+// Issue states.
+const (
+	IssueOpen   = "open"
+	IssueClosed = "closed"
+)
+
+// Check states.
+const (
+	CheckPending = "pending"
+	CheckSuccess = "success"
+	CheckFailure = "failure"
+)
+
+// Mergeability of a pull request; empty while the forge hasn't decided.
+const (
+	MergeClean    = "clean"
+	MergeConflict = "conflict"
+)
+
+// ItemKind says whether a number names a pull request or an issue.
+type ItemKind string
+
+// Item kinds.
+const (
+	ItemPR    ItemKind = "pr"
+	ItemIssue ItemKind = "issue"
+)
+
+// ErrNoForge is returned for repositories on a host with no known forge.
+var ErrNoForge = errors.New("no forge configured")
+
+// PullRequest is a pull request (GitLab: merge request).
+type PullRequest struct {
+	Number           int       `json:"number"`
+	URL              string    `json:"url"`
+	Title            string    `json:"title"`
+	Body             string    `json:"body"`
+	State            string    `json:"state"` // open, closed, merged
+	Author           string    `json:"author,omitempty"`
+	HeadRef          string    `json:"head_ref,omitempty"`
+	BaseRef          string    `json:"base_ref,omitempty"`
+	HeadSHA          string    `json:"head_sha"`
+	Mergeable        string    `json:"mergeable,omitempty"` // clean, conflict, or "" (unknown)
+	Labels           []string  `json:"labels,omitempty"`
+	Checks           []Check   `json:"checks,omitempty"`
+	Approvals        int       `json:"approvals"`
+	ChangesRequested bool      `json:"changes_requested,omitempty"`
+	MergeCommit      string    `json:"merge_commit,omitempty"`
+	Additions        int       `json:"additions,omitempty"`
+	Deletions        int       `json:"deletions,omitempty"`
+	ChangedFiles     int       `json:"changed_files,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Conflicting reports whether the forge found conflicts with the base branch.
+func (pr *PullRequest) Conflicting() bool {
+	return pr.Mergeable == MergeConflict
+}
+
+// PRRequest describes a pull request to open or update.
+type PRRequest struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+}
+
+// Check is a commit status or CI check reported on a commit.
+type Check struct {
+	Name        string `json:"name"`
+	State       string `json:"state"` // pending, success, failure
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// File is a file changed by a pull request.
+type File struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// Issue is a forge issue.
+type Issue struct {
+	Number    int       `json:"number"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"` // open, closed
+	Author    string    `json:"author,omitempty"`
+	Labels    []string  `json:"labels,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IssueRequest describes an issue to open.
+type IssueRequest struct {
+	Title  string
+	Body   string
+	Labels []string // Ignored by Gitea, which takes label IDs
+}
+
+// Comment is a comment on a pull request or issue.
+type Comment struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Provider is a repository on a forge. GitHub, GitLab and Gitea providers
+// talk to the forge's REST API; Fake keeps everything in memory for tests.
+type Provider interface {
+	// Kind is the forge kind (github, gitlab or gitea).
+	Kind() string
+	Repo() Repo
+
+	// ListPRs returns the open pull requests with their checks and
+	// mergeability. Reviews (Approvals, ChangesRequested) are only filled
+	// in by GetPR.
+	ListPRs(ctx context.Context) ([]*PullRequest, error)
+	GetPR(ctx context.Context, number int) (*PullRequest, error)
+	// FindPR returns the open pull request from head into base, or nil.
+	FindPR(ctx context.Context, head, base string) (*PullRequest, error)
+	CreatePR(ctx context.Context, req PRRequest) (*PullRequest, error)
+	// UpdatePR rewrites a pull request's title and body.
+	UpdatePR(ctx context.Context, number int, req PRRequest) error
+	// MergePR merges a pull request with the given method (squash, merge or
+	// rebase), failing if its head is no longer headSHA.
+	MergePR(ctx context.Context, number int, method, headSHA string) error
+	PRFiles(ctx context.Context, number int) ([]File, error)
+
+	// ListIssues returns the open issues, without pull requests.
+	ListIssues(ctx context.Context) ([]*Issue, error)
+	GetIssue(ctx context.Context, number int) (*Issue, error)
+	CreateIssue(ctx context.Context, req IssueRequest) (*Issue, error)
+
+	// Statuses returns the commit statuses and CI checks reported on sha.
+	Statuses(ctx context.Context, sha string) ([]Check, error)
+	// SetStatus reports a commit status on sha.
+	SetStatus(ctx context.Context, sha string, check Check) error
+
+	Comments(ctx context.Context, kind ItemKind, number int) ([]Comment, error)
+	AddComment(ctx context.Context, kind ItemKind, number int, body string) (*Comment, error)
+}
+
+// Repo identifies a repository on a forge host.
+type Repo struct {
+	Host  string `json:"host"`
+	Owner string `json:"owner"` // User, org, or (GitLab) group path
+	Name  string `json:"name"`
+}
+
+// FullName returns owner/name.
+func (r Repo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// String returns host/owner/name.
+func (r Repo) String() string {
+	return r.Host + "/" + r.FullName()
+}
+
+// ParseRepoURL parses a git remote URL — https://host/owner/repo.git,
+// ssh://git@host/owner/repo.git or git@host:owner/repo.git — into a Repo.
+// GitLab subgroups stay in the owner (group/subgroup).
+func ParseRepoURL(gitURL string) (Repo, error) {
+	var host, path string
+	if strings.Contains(gitURL, "://") {
+		u, err := url.Parse(gitURL)
+		if err != nil {
+			return Repo{}, fmt.Errorf("parsing git URL %q: %w", gitURL, err)
+		}
+		if u.Scheme == "file" {
+			return Repo{}, fmt.Errorf("%q is a local repository", gitURL)
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like: [user@]host:owner/repo
+		h, p, ok := strings.Cut(gitURL, ":")
+		if !ok || strings.Contains(h, "/") {
+			return Repo{}, fmt.Errorf("%q is not a remote git URL", gitURL)
+		}
+		if i := strings.LastIndex(h, "@"); i >= 0 {
+			h = h[i+1:]
+		}
+		host, path = h, p
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if host == "" || i <= 0 || i == len(path)-1 {
+		return Repo{}, fmt.Errorf("%q does not name a forge repository (want host/owner/repo)", gitURL)
+	}
+	return Repo{Host: strings.ToLower(host), Owner: path[:i], Name: path[i+1:]}, nil
+}
+
+// ParsePRURL parses a pull request's web URL (GitHub .../pull/N, GitLab
+// .../-/merge_requests/N, Gitea .../pulls/N) into its repo and number.
+func ParsePRURL(prURL string) (Repo, int, error) {
+	u, err := url.Parse(prURL)
+	if err != nil || u.Hostname() == "" {
+		return Repo{}, 0, fmt.Errorf("%q is not a pull request URL", prURL)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(parts) - 2; i >= 2; i-- {
+		switch parts[i] {
+		case "pull", "pulls", "merge_requests":
+		default:
+			continue
+		}
+		number, err := strconv.Atoi(parts[i+1])
+		if err != nil || number <= 0 {
+			break
+		}
+		repoParts := parts[:i]
+		if repoParts[len(repoParts)-1] == "-" { // GitLab
+			repoParts = repoParts[:len(repoParts)-1]
+		}
+		if len(repoParts) < 2 {
+			break
+		}
+		return Repo{
+			Host:  strings.ToLower(u.Hostname()),
+			Owner: strings.Join(repoParts[:len(repoParts)-1], "/"),
+			Name:  repoParts[len(repoParts)-1],
+		}, number, nil
+	}
+	return Repo{}, 0, fmt.Errorf("%q is not a pull request URL", prURL)
+}
+
+// ConfigFile is the forge configuration, relative to the town root. It may
+// hold API tokens, so it is written 0600 and ignored by the HQ .gitignore.
+const ConfigFile = "settings/forge.json"
+
+// Host configures a self-hosted forge, or overrides a known one.
+type Host struct {
+	Kind  string `json:"kind"`            // github, gitlab or gitea
+	API   string `json:"api,omitempty"`   // API base URL (default derived from kind and host)
+	Token string `json:"token,omitempty"` // API token (default from the environment)
+}
+
+// Config is the forge configuration: forges by git host.
+type Config struct {
+	Hosts map[string]*Host `json:"hosts"`
+}
+
+// ConfigPath returns the path of the forge configuration.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, ConfigFile)
+}
+
+// LoadConfig reads the forge configuration. Returns nil, nil when the town
+// has none (only the known public forges are available).
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading forge config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing forge config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveConfig writes the forge configuration.
+func SaveConfig(townRoot string, cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := util.EnsureDirAndWriteJSONWithPerm(ConfigPath(townRoot), cfg, 0600); err != nil {
+		return fmt.Errorf("writing forge config: %w", err)
+	}
+	return nil
+}
+
+// Validate checks every host's kind and API URL.
+func (c *Config) Validate() error {
+	for name, h := range c.Hosts {
+		if name == "" || strings.ContainsAny(name, "/: ") {
+			return fmt.Errorf("forge: invalid host %q", name)
+		}
+		switch h.Kind {
+		case KindGitHub, KindGitLab, KindGitea:
+		default:
+			return fmt.Errorf("forge: host %s: unknown kind %q (want github, gitlab or gitea)", name, h.Kind)
+		}
+		if h.API != "" {
+			u, err := url.Parse(h.API)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("forge: host %s: api must be an http(s) URL", name)
+			}
+		}
+	}
+	return nil
+}
+
+// HostNames returns the configured hosts, sorted.
+func (c *Config) HostNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Hosts))
+	for name := range c.Hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knownHosts are public forges that need no configuration.
+var knownHosts = map[string]string{
+	"github.com":   KindGitHub,
+	"gitlab.com":   KindGitLab,
+	"codeberg.org": KindGitea,
+	"gitea.com":    KindGitea,
+}
+
+// Lookup returns the forge settings for a git host: the configured ones,
+// or the defaults for a known public forge.
+func (c *Config) Lookup(host string) (*Host, error) {
+	host = strings.ToLower(host)
+	if c != nil {
+		if h, ok := c.Hosts[host]; ok {
+			return h, nil
+		}
+	}
+	if kind, ok := knownHosts[host]; ok {
+		return &Host{Kind: kind}, nil
+	}
+	return nil, fmt.Errorf("%w for %s (add it with gt forge add %s --kind github|gitlab|gitea)", ErrNoForge, host, host)
+}
+
+// New returns the provider for repo on the forge described by h.
+func New(repo Repo, h *Host) (Provider, error) {
+	api := h.API
+	if api == "" {
+		api = defaultAPI(h.Kind, repo.Host)
+	}
+	api = strings.TrimSuffix(api, "/")
+	token := h.Token
+	if token == "" {
+		token = envToken(h.Kind, repo.Host)
+	}
+
+	switch h.Kind {
+	case KindGitHub:
+		return newGitHub(repo, api, token), nil
+	case KindGitLab:
+		return newGitLab(repo, api, token), nil
+	case KindGitea:
+		return newGitea(repo, api, token), nil
+	}
+	return nil, fmt.Errorf("unknown forge kind %q", h.Kind)
+}
+
+// defaultAPI returns the API base URL for a forge kind on host.
+func defaultAPI(kind, host string) string {
+	switch kind {
+	case KindGitHub:
+		if host == "github.com" {
+			return "https://api.github.com"
+		}
+		return "https://" + host + "/api/v3" // GitHub Enterprise Server
+	case KindGitLab:
+		return "https://" + host + "/api/v4"
+	default:
+		return "https://" + host + "/api/v1"
+	}
+}
+
+// ForRepo returns the provider for repo, using the town's forge
+// configuration when townRoot has one.
+func ForRepo(townRoot string, repo Repo) (Provider, error) {
+	var cfg *Config
+	if townRoot != "" {
+		var err error
+		if cfg, err = LoadConfig(townRoot); err != nil {
+			return nil, err
+		}
+	}
+	h, err := cfg.Lookup(repo.Host)
+	if err != nil {
+		return nil, err
+	}
+	return New(repo, h)
+}
+
+// ForURL returns the provider for the repository at a git remote URL.
+func ForURL(townRoot, gitURL string) (Provider, error) {
+	repo, err := ParseRepoURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return ForRepo(townRoot, repo)
+}
+
+// ForRig returns the provider for a rig's repository, from its git URL in
+// mayor/rigs.json.
+func ForRig(townRoot, rigName string) (Provider, error) {
+	rigs, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+	entry, ok := rigs.Rigs[rigName]
+	if !ok {
+		return nil, fmt.Errorf("rig '%s' not found", rigName)
+	}
+	if entry.GitURL == "" {
+		return nil, fmt.Errorf("rig '%s' has no git URL", rigName)
+	}
+	return ForURL(townRoot, entry.GitURL)
+}
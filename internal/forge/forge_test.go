@@ -0,0 +1,418 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Repo
+	}{
+		{"https://github.com/acme/widgets.git", Repo{"github.com", "acme", "widgets"}},
+		{"https://github.com/acme/widgets", Repo{"github.com", "acme", "widgets"}},
+		{"git@github.com:acme/widgets.git", Repo{"github.com", "acme", "widgets"}},
+		{"ssh://git@git.example.com:2222/acme/widgets.git", Repo{"git.example.com", "acme", "widgets"}},
+		{"https://gitlab.com/acme/platform/widgets.git", Repo{"gitlab.com", "acme/platform", "widgets"}},
+		{"https://user:pw@Codeberg.org/acme/widgets/", Repo{"codeberg.org", "acme", "widgets"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRepoURL(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRepoURL(%q) = %+v, %v; want %+v", tt.url, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"/srv/git/widgets.git", "file:///srv/git/widgets.git", "https://github.com/widgets", "widgets"} {
+		if got, err := ParseRepoURL(bad); err == nil {
+			t.Errorf("ParseRepoURL(%q) = %+v, want error", bad, got)
+		}
+	}
+}
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		repo   Repo
+		number int
+	}{
+		{"https://github.com/acme/widgets/pull/42", Repo{"github.com", "acme", "widgets"}, 42},
+		{"https://gitlab.com/acme/platform/widgets/-/merge_requests/7", Repo{"gitlab.com", "acme/platform", "widgets"}, 7},
+		{"https://codeberg.org/acme/widgets/pulls/3#issuecomment-1", Repo{"codeberg.org", "acme", "widgets"}, 3},
+	}
+	for _, tt := range tests {
+		repo, number, err := ParsePRURL(tt.url)
+		if err != nil || repo != tt.repo || number != tt.number {
+			t.Errorf("ParsePRURL(%q) = %+v #%d, %v", tt.url, repo, number, err)
+		}
+	}
+	for _, bad := range []string{"https://github.com/acme/widgets", "https://github.com/pull/1", "https://github.com/acme/widgets/pull/x"} {
+		if _, _, err := ParsePRURL(bad); err == nil {
+			t.Errorf("ParsePRURL(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestConfigLookup(t *testing.T) {
+	root := t.TempDir()
+	if cfg, err := LoadConfig(root); cfg != nil || err != nil {
+		t.Fatalf("LoadConfig without a file = %v, %v", cfg, err)
+	}
+	if p, err := ForRepo(root, Repo{"gitlab.com", "acme", "widgets"}); err != nil || p.Kind() != KindGitLab {
+		t.Errorf("gitlab.com = %v, %v", p, err)
+	}
+	if _, err := ForRepo(root, Repo{"git.acme.dev", "acme", "widgets"}); !errors.Is(err, ErrNoForge) {
+		t.Errorf("unknown host = %v, want ErrNoForge", err)
+	}
+
+	cfg := &Config{Hosts: map[string]*Host{"git.acme.dev": {Kind: KindGitea, Token: "t"}}}
+	if err := SaveConfig(root, cfg); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ForURL(root, "git@git.acme.dev:acme/widgets.git")
+	if err != nil || p.Kind() != KindGitea || p.Repo().FullName() != "acme/widgets" {
+		t.Fatalf("configured host = %v, %v", p, err)
+	}
+	if g := p.(*gitea); g.c.base != "https://git.acme.dev/api/v1" {
+		t.Errorf("API = %s", g.c.base)
+	}
+
+	for name, h := range map[string]*Host{
+		"kind": {Kind: "svn"},
+		"api":  {Kind: KindGitLab, API: "git.acme.dev"},
+	} {
+		if err := (&Config{Hosts: map[string]*Host{"h": h}}).Validate(); err == nil {
+			t.Errorf("%s: Validate accepted %+v", name, h)
+		}
+	}
+}
+
+// fakeAPI serves canned JSON responses keyed by "METHOD /path" (path with
+// its raw query, if any) and records request bodies.
+type fakeAPI struct {
+	t         *testing.T
+	responses map[string]string
+	bodies    map[string]map[string]any
+	headers   http.Header
+}
+
+func newFakeAPI(t *testing.T, responses map[string]string) (*fakeAPI, *httptest.Server) {
+	api := &fakeAPI{t: t, responses: responses, bodies: make(map[string]map[string]any)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return api, srv
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.EscapedPath()
+	if r.URL.RawQuery != "" {
+		key += "?" + r.URL.RawQuery
+	}
+	a.headers = r.Header
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		a.bodies[key] = body
+	}
+	resp, ok := a.responses[key]
+	if !ok {
+		a.t.Logf("unexpected request: %s", key)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		return
+	}
+	_, _ = w.Write([]byte(resp))
+}
+
+func TestGitHub(t *testing.T) {
+	api, srv := newFakeAPI(t, map[string]string{
+		"GET /repos/acme/widgets/pulls/42": `{
+			"number": 42, "html_url": "https://github.com/acme/widgets/pull/42", "title": "feat: x", "body": null,
+			"state": "open", "user": {"login": "nux"}, "head": {"ref": "polecat/nux", "sha": "abc123"}, "base": {"ref": "main"},
+			"mergeable": false, "mergeable_state": "dirty", "merged_at": null, "labels": [{"name": "gt"}],
+			"additions": 10, "deletions": 2, "changed_files": 3}`,
+		"GET /repos/acme/widgets/pulls/42/reviews?per_page=100": `[
+			{"user": {"login": "ann"}, "state": "CHANGES_REQUESTED"},
+			{"user": {"login": "ann"}, "state": "APPROVED"},
+			{"user": {"login": "ann"}, "state": "COMMENTED"},
+			{"user": {"login": "bob"}, "state": "CHANGES_REQUESTED"}]`,
+		"GET /repos/acme/widgets/commits/abc123/status": `{"statuses": [{"context": "ci/legacy", "state": "pending"}]}`,
+		"GET /repos/acme/widgets/commits/abc123/check-runs?per_page=100": `{"check_runs": [
+			{"name": "test", "status": "completed", "conclusion": "success"},
+			{"name": "lint", "status": "in_progress", "conclusion": null},
+			{"name": "e2e", "status": "completed", "conclusion": "timed_out"}]}`,
+		"GET /repos/acme/widgets/pulls?base=main&head=acme%3Apolecat%2Fnux&state=open": `[{"number": 42}]`,
+		"PUT /repos/acme/widgets/pulls/42/merge":                                       `{"merged": true}`,
+		"GET /repos/acme/widgets/issues?state=open&per_page=50": `[
+			{"number": 1, "title": "bug", "state": "open", "labels": [{"name": "p1"}]},
+			{"number": 42, "title": "feat: x", "state": "open", "pull_request": {}}]`,
+		"POST /repos/acme/widgets/statuses/abc123": `{}`,
+	})
+	p, err := New(Repo{"github.com", "acme", "widgets"}, &Host{Kind: KindGitHub, API: srv.URL, Token: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pr, err := p.FindPR(ctx, "polecat/nux", "main")
+	if err != nil {
+		t.Fatalf("FindPR: %v", err)
+	}
+	if api.headers.Get("Authorization") != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", api.headers.Get("Authorization"))
+	}
+	if pr.State != PROpen || pr.HeadSHA != "abc123" || !pr.Conflicting() || pr.Author != "nux" || pr.BaseRef != "main" {
+		t.Errorf("pr = %+v", pr)
+	}
+	if pr.Approvals != 1 || !pr.ChangesRequested {
+		t.Errorf("reviews: approvals=%d changes=%v, want 1 true", pr.Approvals, pr.ChangesRequested)
+	}
+	want := []Check{{Name: "ci/legacy", State: CheckPending}, {Name: "test", State: CheckSuccess}, {Name: "lint", State: CheckPending}, {Name: "e2e", State: CheckFailure}}
+	if len(pr.Checks) != len(want) {
+		t.Fatalf("checks = %+v, want %+v", pr.Checks, want)
+	}
+	for i := range want {
+		if pr.Checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, pr.Checks[i], want[i])
+		}
+	}
+
+	if err := p.MergePR(ctx, 42, "squash", "abc123"); err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if body := api.bodies["PUT /repos/acme/widgets/pulls/42/merge"]; body["merge_method"] != "squash" || body["sha"] != "abc123" {
+		t.Errorf("merge body = %v", body)
+	}
+
+	issues, err := p.ListIssues(ctx)
+	if err != nil || len(issues) != 1 || issues[0].Number != 1 || issues[0].Labels[0] != "p1" {
+		t.Errorf("ListIssues = %+v, %v", issues, err)
+	}
+
+	if err := p.SetStatus(ctx, "abc123", Check{Name: "gastown/refinery", State: CheckFailure}); err != nil {
+		t.Fatal(err)
+	}
+	if body := api.bodies["POST /repos/acme/widgets/statuses/abc123"]; body["state"] != "failure" || body["context"] != "gastown/refinery" {
+		t.Errorf("status body = %v", body)
+	}
+
+	_, err = p.GetPR(ctx, 7)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetPR(7) = %v, want a 404 APIError", err)
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	const project = "/projects/acme%2Fplatform%2Fwidgets"
+	api, srv := newFakeAPI(t, map[string]string{
+		"GET " + project + "/merge_requests/7": `{
+			"iid": 7, "web_url": "https://gitlab.com/acme/platform/widgets/-/merge_requests/7", "title": "feat: y",
+			"description": "body", "state": "merged", "author": {"username": "max"}, "source_branch": "feature",
+			"target_branch": "main", "sha": "def456", "merge_commit_sha": null, "squash_commit_sha": "5a5a5a",
+			"labels": ["gt"], "changes_count": "4"}`,
+		"GET " + project + "/merge_requests/7/approvals":                                           `{"approved_by": [{"user": {"username": "ann"}}, {"user": {"username": "bob"}}]}`,
+		"GET " + project + "/repository/commits/def456/statuses?per_page=100":                      `[{"name": "build", "status": "success"}, {"name": "test", "status": "failed"}, {"name": "deploy", "status": "manual"}]`,
+		"GET " + project + "/merge_requests/7/diffs?per_page=100":                                  `[{"new_path": "a.go", "diff": "@@ -1,2 +1,3 @@\n ctx\n-old\n+new\n+more\n"}]`,
+		"POST " + project + "/statuses/def456":                                                     `{}`,
+		"GET " + project + "/merge_requests/7/notes?sort=asc&per_page=100":                         `[{"id": 1, "body": "assigned to @max", "system": true}, {"id": 2, "body": "LGTM", "author": {"username": "ann"}}]`,
+		"POST " + project + "/issues":                                                              `{"iid": 3, "title": "bug", "state": "opened", "labels": ["p1", "gt"]}`,
+		"GET " + project + "/merge_requests?source_branch=feature&state=opened&target_branch=main": `[]`,
+	})
+	p, err := New(Repo{"gitlab.com", "acme/platform", "widgets"}, &Host{Kind: KindGitLab, API: srv.URL, Token: "glpat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pr, err := p.GetPR(ctx, 7)
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if api.headers.Get("PRIVATE-TOKEN") != "glpat" {
+		t.Errorf("PRIVATE-TOKEN = %q", api.headers.Get("PRIVATE-TOKEN"))
+	}
+	if pr.State != PRMerged || pr.MergeCommit != "5a5a5a" || pr.HeadRef != "feature" || pr.Approvals != 2 || pr.ChangedFiles != 4 {
+		t.Errorf("pr = %+v", pr)
+	}
+	if len(pr.Checks) != 3 || pr.Checks[0].State != CheckSuccess || pr.Checks[1].State != CheckFailure || pr.Checks[2].State != CheckPending {
+		t.Errorf("checks = %+v", pr.Checks)
+	}
+
+	if found, err := p.FindPR(ctx, "feature", "main"); found != nil || err != nil {
+		t.Errorf("FindPR = %+v, %v; want nil", found, err)
+	}
+
+	files, err := p.PRFiles(ctx, 7)
+	if err != nil || len(files) != 1 || files[0] != (File{Path: "a.go", Additions: 2, Deletions: 1}) {
+		t.Errorf("PRFiles = %+v, %v", files, err)
+	}
+
+	if err := p.SetStatus(ctx, "def456", Check{Name: "gastown", State: CheckFailure}); err != nil {
+		t.Fatal(err)
+	}
+	if body := api.bodies["POST "+project+"/statuses/def456"]; body["state"] != "failed" || body["name"] != "gastown" {
+		t.Errorf("status body = %v", body)
+	}
+
+	comments, err := p.Comments(ctx, ItemPR, 7)
+	if err != nil || len(comments) != 1 || comments[0].Author != "ann" {
+		t.Errorf("Comments = %+v, %v", comments, err)
+	}
+
+	issue, err := p.CreateIssue(ctx, IssueRequest{Title: "bug", Labels: []string{"p1", "gt"}})
+	if err != nil || issue.Number != 3 || issue.State != IssueOpen {
+		t.Errorf("CreateIssue = %+v, %v", issue, err)
+	}
+	if body := api.bodies["POST "+project+"/issues"]; body["labels"] != "p1,gt" {
+		t.Errorf("issue body = %v", body)
+	}
+}
+
+func TestGitea(t *testing.T) {
+	api, srv := newFakeAPI(t, map[string]string{
+		"GET /repos/acme/widgets/pulls?state=open&limit=50": `[
+			{"number": 2, "state": "open", "head": {"ref": "other", "sha": "111"}, "base": {"ref": "main"}},
+			{"number": 3, "state": "open", "head": {"ref": "feature", "sha": "222"}, "base": {"ref": "main"}, "mergeable": true}]`,
+		"GET /repos/acme/widgets/pulls/3": `{"number": 3, "state": "open", "title": "feat: z", "head": {"ref": "feature", "sha": "222"}, "base": {"ref": "main"}, "mergeable": true}`,
+		"GET /repos/acme/widgets/pulls/3/reviews": `[
+			{"user": {"login": "ann"}, "state": "APPROVED", "stale": true},
+			{"user": {"login": "bob"}, "state": "APPROVED"},
+			{"user": {"login": "cy"}, "state": "REQUEST_CHANGES", "dismissed": true}]`,
+		"GET /repos/acme/widgets/commits/111/status": `{"statuses": []}`,
+		"GET /repos/acme/widgets/commits/222/status": `{"statuses": [{"context": "ci", "status": "warning"}, {"context": "lint", "status": "error"}]}`,
+		"POST /repos/acme/widgets/pulls/3/merge":     ``,
+	})
+	p, err := New(Repo{"codeberg.org", "acme", "widgets"}, &Host{Kind: KindGitea, API: srv.URL, Token: "tea"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pr, err := p.FindPR(ctx, "feature", "main")
+	if err != nil || pr == nil {
+		t.Fatalf("FindPR = %+v, %v", pr, err)
+	}
+	if api.headers.Get("Authorization") != "token tea" {
+		t.Errorf("Authorization = %q", api.headers.Get("Authorization"))
+	}
+	if pr.Number != 3 || pr.Mergeable != MergeClean || pr.Approvals != 1 || pr.ChangesRequested {
+		t.Errorf("pr = %+v", pr)
+	}
+	if len(pr.Checks) != 2 || pr.Checks[0].State != CheckSuccess || pr.Checks[1].State != CheckFailure {
+		t.Errorf("checks = %+v", pr.Checks)
+	}
+
+	prs, err := p.ListPRs(ctx)
+	if err != nil || len(prs) != 2 {
+		t.Fatalf("ListPRs = %d, %v", len(prs), err)
+	}
+	if prs[0].Conflicting() != true || prs[1].Conflicting() {
+		t.Errorf("mergeable: #2 %q, #3 %q", prs[0].Mergeable, prs[1].Mergeable)
+	}
+
+	if err := p.MergePR(ctx, 3, "rebase", "222"); err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if body := api.bodies["POST /repos/acme/widgets/pulls/3/merge"]; body["Do"] != "rebase" || body["head_commit_id"] != "222" {
+		t.Errorf("merge body = %v", body)
+	}
+}
+
+func TestFake(t *testing.T) {
+	f := NewFake(Repo{"forge.example", "acme", "widgets"})
+	ctx := context.Background()
+
+	pr, _ := f.CreatePR(ctx, PRRequest{Head: "feature", Base: "main", Title: "feat"})
+	issue, _ := f.CreateIssue(ctx, IssueRequest{Title: "bug"})
+	if pr.Number != 1 || issue.Number != 2 {
+		t.Errorf("numbers = %d, %d; want 1, 2", pr.Number, issue.Number)
+	}
+
+	f.PRs[1].HeadSHA = "aaa"
+	_ = f.SetStatus(ctx, "aaa", Check{Name: "ci", State: CheckPending})
+	_ = f.SetStatus(ctx, "aaa", Check{Name: "ci", State: CheckSuccess})
+	if got, _ := f.FindPR(ctx, "feature", "main"); got == nil || len(got.Checks) != 1 || got.Checks[0].State != CheckSuccess {
+		t.Errorf("FindPR = %+v", got)
+	}
+
+	if err := f.MergePR(ctx, 1, "squash", "bbb"); err == nil {
+		t.Error("MergePR with a stale head succeeded")
+	}
+	if err := f.MergePR(ctx, 1, "squash", "aaa"); err != nil {
+		t.Fatal(err)
+	}
+	if prs, _ := f.ListPRs(ctx); len(prs) != 0 {
+		t.Errorf("ListPRs after merge = %d PRs", len(prs))
+	}
+
+	_, _ = f.AddComment(ctx, ItemIssue, 2, "on it")
+	if c, _ := f.Comments(ctx, ItemIssue, 2); len(c) != 1 || !strings.Contains(c[0].Body, "on it") {
+		t.Errorf("Comments = %+v", c)
+	}
+}
+
+func TestCheckStates(t *testing.T) {
+	t.Run("github check runs", func(t *testing.T) {
+		for _, tt := range []struct {
+			status, conclusion, want string
+		}{
+			{"completed", "success", CheckSuccess},
+			{"completed", "neutral", CheckSuccess},
+			{"completed", "skipped", CheckSuccess},
+			{"completed", "failure", CheckFailure},
+			{"completed", "cancelled", CheckFailure}, //nolint:misspell // GitHub's spelling
+			{"completed", "timed_out", CheckFailure},
+			{"completed", "action_required", CheckFailure},
+			{"queued", "", CheckPending},
+			{"in_progress", "", CheckPending},
+		} {
+			checks := githubChecks(nil, []ghCheckRun{{Name: "ci", Status: tt.status, Conclusion: tt.conclusion}})
+			if got := checks[0].State; got != tt.want {
+				t.Errorf("%s/%s = %s, want %s", tt.status, tt.conclusion, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("github statuses", func(t *testing.T) {
+		for state, want := range map[string]string{
+			"success": CheckSuccess, "failure": CheckFailure, "error": CheckFailure, "pending": CheckPending,
+		} {
+			checks := githubChecks([]ghStatus{{Context: "ci", State: state}}, nil)
+			if got := checks[0].State; got != want {
+				t.Errorf("%s = %s, want %s", state, got, want)
+			}
+		}
+	})
+
+	t.Run("gitlab", func(t *testing.T) {
+		for status, want := range map[string]string{
+			"success": CheckSuccess, "skipped": CheckSuccess,
+			"failed": CheckFailure, "canceled": CheckFailure,
+			"pending": CheckPending, "running": CheckPending, "created": CheckPending, "manual": CheckPending,
+		} {
+			s := glStatus{Name: "ci", Status: status}
+			if got := s.check().State; got != want {
+				t.Errorf("%s = %s, want %s", status, got, want)
+			}
+		}
+	})
+
+	t.Run("gitea", func(t *testing.T) {
+		for status, want := range map[string]string{
+			"success": CheckSuccess, "warning": CheckSuccess,
+			"failure": CheckFailure, "error": CheckFailure,
+			"pending": CheckPending,
+		} {
+			if got := giteaCheckState(status); got != want {
+				t.Errorf("%s = %s, want %s", status, got, want)
+			}
+		}
+	})
+}
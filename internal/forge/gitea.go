@@ -0,0 +1,345 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// gitea is a repository on Gitea or Forgejo (Codeberg). Pull requests and
+// issues share one numbering, as on GitHub.
+type gitea struct {
+	repo Repo
+	c    *client
+	path string // /repos/{owner}/{name}
+}
+
+var _ Provider = (*gitea)(nil)
+
+func newGitea(repo Repo, api, token string) *gitea {
+	h := http.Header{}
+	if token != "" {
+		h.Set("Authorization", "token "+token)
+	}
+	return &gitea{
+		repo: repo,
+		c:    newClient(api, h),
+		path: "/repos/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name),
+	}
+}
+
+func (g *gitea) Kind() string { return KindGitea }
+func (g *gitea) Repo() Repo   { return g.repo }
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaLabel struct {
+	Name string `json:"name"`
+}
+
+type giteaPull struct {
+	Number int       `json:"number"`
+	URL    string    `json:"html_url"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	State  string    `json:"state"` // open, closed
+	Merged bool      `json:"merged"`
+	User   giteaUser `json:"user"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Mergeable      bool         `json:"mergeable"`
+	MergeCommitSHA string       `json:"merge_commit_sha"`
+	Labels         []giteaLabel `json:"labels"`
+	Additions      int          `json:"additions"`
+	Deletions      int          `json:"deletions"`
+	ChangedFiles   int          `json:"changed_files"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// pullRequest normalizes a Gitea pull request.
+func (p *giteaPull) pullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:       p.Number,
+		URL:          p.URL,
+		Title:        p.Title,
+		Body:         p.Body,
+		State:        p.State,
+		Author:       p.User.Login,
+		HeadRef:      p.Head.Ref,
+		BaseRef:      p.Base.Ref,
+		HeadSHA:      p.Head.SHA,
+		Additions:    p.Additions,
+		Deletions:    p.Deletions,
+		ChangedFiles: p.ChangedFiles,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+	if p.Merged {
+		pr.State = PRMerged
+		pr.MergeCommit = p.MergeCommitSHA
+	}
+	if pr.State == PROpen {
+		pr.Mergeable = MergeConflict
+		if p.Mergeable {
+			pr.Mergeable = MergeClean
+		}
+	}
+	for _, l := range p.Labels {
+		pr.Labels = append(pr.Labels, l.Name)
+	}
+	return pr
+}
+
+func (g *gitea) pullPath(number int) string {
+	return fmt.Sprintf("%s/pulls/%d", g.path, number)
+}
+
+func (g *gitea) ListPRs(ctx context.Context) ([]*PullRequest, error) {
+	pulls, err := g.openPulls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]*PullRequest, 0, len(pulls))
+	for i := range pulls {
+		pr := pulls[i].pullRequest()
+		if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (g *gitea) openPulls(ctx context.Context) ([]giteaPull, error) {
+	var pulls []giteaPull
+	err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls?state=open&limit=%d", g.path, perPage), nil, &pulls)
+	return pulls, err
+}
+
+func (g *gitea) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var p giteaPull
+	if err := g.c.do(ctx, http.MethodGet, g.pullPath(number), nil, &p); err != nil {
+		return nil, err
+	}
+	pr := p.pullRequest()
+
+	var reviews []struct {
+		User      giteaUser `json:"user"`
+		State     string    `json:"state"` // APPROVED, REQUEST_CHANGES, COMMENT, PENDING, REQUEST_REVIEW
+		Stale     bool      `json:"stale"`
+		Dismissed bool      `json:"dismissed"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, g.pullPath(number)+"/reviews", nil, &reviews); err != nil {
+		return nil, err
+	}
+	var latest []ghReview // Same counting rules as GitHub
+	for _, r := range reviews {
+		state := r.State
+		switch {
+		case r.Dismissed:
+			state = "DISMISSED"
+		case r.State == "REQUEST_CHANGES":
+			state = "CHANGES_REQUESTED"
+		case r.State == "APPROVED" && r.Stale:
+			continue
+		}
+		latest = append(latest, ghReview{User: ghUser{Login: r.User.Login}, State: state})
+	}
+	applyGitHubReviews(pr, latest)
+
+	var err error
+	if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (g *gitea) FindPR(ctx context.Context, head, base string) (*PullRequest, error) {
+	pulls, err := g.openPulls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pulls {
+		if p.Head.Ref == head && p.Base.Ref == base {
+			return g.GetPR(ctx, p.Number)
+		}
+	}
+	return nil, nil
+}
+
+func (g *gitea) CreatePR(ctx context.Context, req PRRequest) (*PullRequest, error) {
+	in := map[string]string{"head": req.Head, "base": req.Base, "title": req.Title, "body": req.Body}
+	var p giteaPull
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/pulls", in, &p); err != nil {
+		return nil, err
+	}
+	return g.GetPR(ctx, p.Number)
+}
+
+func (g *gitea) UpdatePR(ctx context.Context, number int, req PRRequest) error {
+	in := map[string]string{"title": req.Title, "body": req.Body}
+	return g.c.do(ctx, http.MethodPatch, g.pullPath(number), in, nil)
+}
+
+func (g *gitea) MergePR(ctx context.Context, number int, method, headSHA string) error {
+	in := map[string]string{"Do": method}
+	if headSHA != "" {
+		in["head_commit_id"] = headSHA
+	}
+	return g.c.do(ctx, http.MethodPost, g.pullPath(number)+"/merge", in, nil)
+}
+
+func (g *gitea) PRFiles(ctx context.Context, number int) ([]File, error) {
+	var files []struct {
+		Filename  string `json:"filename"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, g.pullPath(number)+"/files?limit=100", nil, &files); err != nil {
+		return nil, err
+	}
+	out := make([]File, 0, len(files))
+	for _, f := range files {
+		out = append(out, File{Path: f.Filename, Additions: f.Additions, Deletions: f.Deletions})
+	}
+	return out, nil
+}
+
+type giteaIssue struct {
+	Number    int          `json:"number"`
+	URL       string       `json:"html_url"`
+	Title     string       `json:"title"`
+	Body      string       `json:"body"`
+	State     string       `json:"state"`
+	User      giteaUser    `json:"user"`
+	Labels    []giteaLabel `json:"labels"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func (i *giteaIssue) issue() *Issue {
+	out := &Issue{
+		Number:    i.Number,
+		URL:       i.URL,
+		Title:     i.Title,
+		Body:      i.Body,
+		State:     i.State,
+		Author:    i.User.Login,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+	for _, l := range i.Labels {
+		out.Labels = append(out.Labels, l.Name)
+	}
+	return out
+}
+
+func (g *gitea) ListIssues(ctx context.Context) ([]*Issue, error) {
+	var raw []giteaIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues?state=open&type=issues&limit=%d", g.path, perPage), nil, &raw); err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(raw))
+	for i := range raw {
+		issues = append(issues, raw[i].issue())
+	}
+	return issues, nil
+}
+
+func (g *gitea) GetIssue(ctx context.Context, number int) (*Issue, error) {
+	var i giteaIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", g.path, number), nil, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitea) CreateIssue(ctx context.Context, req IssueRequest) (*Issue, error) {
+	in := map[string]string{"title": req.Title, "body": req.Body}
+	var i giteaIssue
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/issues", in, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitea) Statuses(ctx context.Context, sha string) ([]Check, error) {
+	if sha == "" {
+		return nil, nil
+	}
+	var combined struct {
+		Statuses []struct {
+			Context     string `json:"context"`
+			Status      string `json:"status"` // pending, success, error, failure, warning
+			Description string `json:"description"`
+			TargetURL   string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/status", g.path, url.PathEscape(sha)), nil, &combined); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(combined.Statuses))
+	for _, s := range combined.Statuses {
+		checks = append(checks, Check{Name: s.Context, State: giteaCheckState(s.Status), Description: s.Description, URL: s.TargetURL})
+	}
+	return checks, nil
+}
+
+// giteaCheckState maps a Gitea commit status onto a check state.
+func giteaCheckState(status string) string {
+	switch status {
+	case "success", "warning":
+		return CheckSuccess
+	case "failure", "error":
+		return CheckFailure
+	}
+	return CheckPending
+}
+
+func (g *gitea) SetStatus(ctx context.Context, sha string, check Check) error {
+	in := map[string]string{
+		"state":       check.State, // Gitea's states match ours
+		"context":     check.Name,
+		"description": check.Description,
+		"target_url":  check.URL,
+	}
+	return g.c.do(ctx, http.MethodPost, g.path+"/statuses/"+url.PathEscape(sha), in, nil)
+}
+
+type giteaComment struct {
+	ID        int64     `json:"id"`
+	User      giteaUser `json:"user"`
+	Body      string    `json:"body"`
+	URL       string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (g *gitea) Comments(ctx context.Context, _ ItemKind, number int) ([]Comment, error) {
+	var raw []giteaComment
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d/comments", g.path, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Comment, 0, len(raw))
+	for _, c := range raw {
+		out = append(out, Comment{ID: c.ID, Author: c.User.Login, Body: c.Body, URL: c.URL, CreatedAt: c.CreatedAt})
+	}
+	return out, nil
+}
+
+func (g *gitea) AddComment(ctx context.Context, _ ItemKind, number int, body string) (*Comment, error) {
+	var c giteaComment
+	if err := g.c.do(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/comments", g.path, number), map[string]string{"body": body}, &c); err != nil {
+		return nil, err
+	}
+	return &Comment{ID: c.ID, Author: c.User.Login, Body: c.Body, URL: c.URL, CreatedAt: c.CreatedAt}, nil
+}
@@ -0,0 +1,405 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// gitHub is a repository on GitHub or GitHub Enterprise Server.
+type gitHub struct {
+	repo Repo
+	c    *client
+	path string // /repos/{owner}/{name}
+}
+
+var _ Provider = (*gitHub)(nil)
+
+func newGitHub(repo Repo, api, token string) *gitHub {
+	h := http.Header{}
+	h.Set("Accept", "application/vnd.github+json")
+	h.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token != "" {
+		h.Set("Authorization", "Bearer "+token)
+	}
+	return &gitHub{
+		repo: repo,
+		c:    newClient(api, h),
+		path: "/repos/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name),
+	}
+}
+
+func (g *gitHub) Kind() string { return KindGitHub }
+func (g *gitHub) Repo() Repo   { return g.repo }
+
+type ghUser struct {
+	Login string `json:"login"`
+}
+
+type ghLabel struct {
+	Name string `json:"name"`
+}
+
+type ghPull struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"` // open, closed
+	User   ghUser `json:"user"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Mergeable      *bool      `json:"mergeable"`       // Only on single-PR responses; null while computing
+	MergeableState string     `json:"mergeable_state"` // dirty = conflicts
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	Labels         []ghLabel  `json:"labels"`
+	Additions      int        `json:"additions"`
+	Deletions      int        `json:"deletions"`
+	ChangedFiles   int        `json:"changed_files"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// pullRequest normalizes GitHub's view of a pull request.
+func (p *ghPull) pullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:       p.Number,
+		URL:          p.URL,
+		Title:        p.Title,
+		Body:         p.Body,
+		State:        p.State,
+		Author:       p.User.Login,
+		HeadRef:      p.Head.Ref,
+		BaseRef:      p.Base.Ref,
+		HeadSHA:      p.Head.SHA,
+		Additions:    p.Additions,
+		Deletions:    p.Deletions,
+		ChangedFiles: p.ChangedFiles,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+	if p.MergedAt != nil {
+		pr.State = PRMerged
+		pr.MergeCommit = p.MergeCommitSHA
+	}
+	switch {
+	case p.MergeableState == "dirty":
+		pr.Mergeable = MergeConflict
+	case p.Mergeable != nil && *p.Mergeable:
+		pr.Mergeable = MergeClean
+	case p.Mergeable != nil:
+		pr.Mergeable = MergeConflict
+	}
+	for _, l := range p.Labels {
+		pr.Labels = append(pr.Labels, l.Name)
+	}
+	return pr
+}
+
+type ghReview struct {
+	User  ghUser `json:"user"`
+	State string `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED, PENDING
+}
+
+// applyGitHubReviews counts each reviewer's latest approving or blocking
+// review; comments don't change where a reviewer stands.
+func applyGitHubReviews(pr *PullRequest, reviews []ghReview) {
+	latest := make(map[string]string)
+	var order []string
+	for _, r := range reviews {
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			if _, seen := latest[r.User.Login]; !seen {
+				order = append(order, r.User.Login)
+			}
+			latest[r.User.Login] = r.State
+		}
+	}
+	for _, who := range order {
+		switch latest[who] {
+		case "APPROVED":
+			pr.Approvals++
+		case "CHANGES_REQUESTED":
+			pr.ChangesRequested = true
+		}
+	}
+}
+
+type ghStatus struct {
+	Context     string `json:"context"`
+	State       string `json:"state"` // success, pending, failure, error
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+type ghCheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`     // queued, in_progress, completed
+	Conclusion string `json:"conclusion"` // success, failure, neutral, skipped, cancelled, timed_out, ...
+	URL        string `json:"html_url"`
+	Output     struct {
+		Title string `json:"title"`
+	} `json:"output"`
+}
+
+// githubChecks merges commit statuses and check runs into checks.
+func githubChecks(statuses []ghStatus, runs []ghCheckRun) []Check {
+	var checks []Check
+	for _, s := range statuses {
+		c := Check{Name: s.Context, State: CheckPending, Description: s.Description, URL: s.TargetURL}
+		switch s.State {
+		case "success":
+			c.State = CheckSuccess
+		case "failure", "error":
+			c.State = CheckFailure
+		}
+		checks = append(checks, c)
+	}
+	for _, r := range runs {
+		c := Check{Name: r.Name, State: CheckPending, Description: r.Output.Title, URL: r.URL}
+		if r.Status == "completed" {
+			switch r.Conclusion {
+			case "success", "neutral", "skipped":
+				c.State = CheckSuccess
+			default:
+				c.State = CheckFailure
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func (g *gitHub) ListPRs(ctx context.Context) ([]*PullRequest, error) {
+	var pulls []ghPull
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls?state=open&per_page=%d", g.path, perPage), nil, &pulls); err != nil {
+		return nil, err
+	}
+	prs := make([]*PullRequest, 0, len(pulls))
+	for _, p := range pulls {
+		// The list omits mergeability; the single-PR view has it.
+		pr, err := g.pull(ctx, p.Number)
+		if err != nil {
+			return nil, err
+		}
+		if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// pull fetches a pull request without its reviews and checks.
+func (g *gitHub) pull(ctx context.Context, number int) (*PullRequest, error) {
+	var p ghPull
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", g.path, number), nil, &p); err != nil {
+		return nil, err
+	}
+	return p.pullRequest(), nil
+}
+
+func (g *gitHub) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	pr, err := g.pull(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []ghReview
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d/reviews?per_page=100", g.path, number), nil, &reviews); err != nil {
+		return nil, err
+	}
+	applyGitHubReviews(pr, reviews)
+	if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (g *gitHub) FindPR(ctx context.Context, head, base string) (*PullRequest, error) {
+	q := url.Values{}
+	q.Set("state", "open")
+	q.Set("head", g.repo.Owner+":"+head)
+	q.Set("base", base)
+	var pulls []ghPull
+	if err := g.c.do(ctx, http.MethodGet, g.path+"/pulls?"+q.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return g.GetPR(ctx, pulls[0].Number)
+}
+
+func (g *gitHub) CreatePR(ctx context.Context, req PRRequest) (*PullRequest, error) {
+	in := map[string]string{"head": req.Head, "base": req.Base, "title": req.Title, "body": req.Body}
+	var p ghPull
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/pulls", in, &p); err != nil {
+		return nil, err
+	}
+	return g.GetPR(ctx, p.Number)
+}
+
+func (g *gitHub) UpdatePR(ctx context.Context, number int, req PRRequest) error {
+	in := map[string]string{"title": req.Title, "body": req.Body}
+	return g.c.do(ctx, http.MethodPatch, fmt.Sprintf("%s/pulls/%d", g.path, number), in, nil)
+}
+
+func (g *gitHub) MergePR(ctx context.Context, number int, method, headSHA string) error {
+	in := map[string]string{"merge_method": method}
+	if headSHA != "" {
+		in["sha"] = headSHA
+	}
+	return g.c.do(ctx, http.MethodPut, fmt.Sprintf("%s/pulls/%d/merge", g.path, number), in, nil)
+}
+
+func (g *gitHub) PRFiles(ctx context.Context, number int) ([]File, error) {
+	var files []struct {
+		Filename  string `json:"filename"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d/files?per_page=100", g.path, number), nil, &files); err != nil {
+		return nil, err
+	}
+	out := make([]File, 0, len(files))
+	for _, f := range files {
+		out = append(out, File{Path: f.Filename, Additions: f.Additions, Deletions: f.Deletions})
+	}
+	return out, nil
+}
+
+type ghIssue struct {
+	Number      int       `json:"number"`
+	URL         string    `json:"html_url"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	User        ghUser    `json:"user"`
+	Labels      []ghLabel `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"` // Set when the issue is a PR
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (i *ghIssue) issue() *Issue {
+	out := &Issue{
+		Number:    i.Number,
+		URL:       i.URL,
+		Title:     i.Title,
+		Body:      i.Body,
+		State:     i.State,
+		Author:    i.User.Login,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+	for _, l := range i.Labels {
+		out.Labels = append(out.Labels, l.Name)
+	}
+	return out
+}
+
+func (g *gitHub) ListIssues(ctx context.Context) ([]*Issue, error) {
+	var items []ghIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues?state=open&per_page=%d", g.path, perPage), nil, &items); err != nil {
+		return nil, err
+	}
+	var issues []*Issue
+	for i := range items {
+		if items[i].PullRequest == nil {
+			issues = append(issues, items[i].issue())
+		}
+	}
+	return issues, nil
+}
+
+func (g *gitHub) GetIssue(ctx context.Context, number int) (*Issue, error) {
+	var i ghIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", g.path, number), nil, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitHub) CreateIssue(ctx context.Context, req IssueRequest) (*Issue, error) {
+	in := map[string]any{"title": req.Title, "body": req.Body}
+	if len(req.Labels) > 0 {
+		in["labels"] = req.Labels
+	}
+	var i ghIssue
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/issues", in, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitHub) Statuses(ctx context.Context, sha string) ([]Check, error) {
+	if sha == "" {
+		return nil, nil
+	}
+	var combined struct {
+		Statuses []ghStatus `json:"statuses"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/status", g.path, url.PathEscape(sha)), nil, &combined); err != nil {
+		return nil, err
+	}
+	var runs struct {
+		CheckRuns []ghCheckRun `json:"check_runs"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/check-runs?per_page=100", g.path, url.PathEscape(sha)), nil, &runs); err != nil {
+		return nil, err
+	}
+	return githubChecks(combined.Statuses, runs.CheckRuns), nil
+}
+
+func (g *gitHub) SetStatus(ctx context.Context, sha string, check Check) error {
+	in := map[string]string{
+		"state":       check.State, // GitHub's states match ours
+		"context":     check.Name,
+		"description": check.Description,
+		"target_url":  check.URL,
+	}
+	return g.c.do(ctx, http.MethodPost, g.path+"/statuses/"+url.PathEscape(sha), in, nil)
+}
+
+type ghComment struct {
+	ID        int64     `json:"id"`
+	User      ghUser    `json:"user"`
+	Body      string    `json:"body"`
+	URL       string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *ghComment) comment() Comment {
+	return Comment{ID: c.ID, Author: c.User.Login, Body: c.Body, URL: c.URL, CreatedAt: c.CreatedAt}
+}
+
+// Comments returns the conversation on a PR or issue; GitHub keeps both on
+// the issue (PR review comments on lines of code are not included).
+func (g *gitHub) Comments(ctx context.Context, _ ItemKind, number int) ([]Comment, error) {
+	var raw []ghComment
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d/comments?per_page=100", g.path, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Comment, 0, len(raw))
+	for i := range raw {
+		out = append(out, raw[i].comment())
+	}
+	return out, nil
+}
+
+func (g *gitHub) AddComment(ctx context.Context, _ ItemKind, number int, body string) (*Comment, error) {
+	var c ghComment
+	if err := g.c.do(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/comments", g.path, number), map[string]string{"body": body}, &c); err != nil {
+		return nil, err
+	}
+	out := c.comment()
+	return &out, nil
+}
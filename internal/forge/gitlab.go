@@ -0,0 +1,361 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// gitLab is a project on GitLab. Pull requests are merge requests, and PR
+// numbers are merge request IIDs.
+type gitLab struct {
+	repo Repo
+	c    *client
+	path string // /projects/{url-encoded owner/name}
+}
+
+var _ Provider = (*gitLab)(nil)
+
+func newGitLab(repo Repo, api, token string) *gitLab {
+	h := http.Header{}
+	if token != "" {
+		h.Set("PRIVATE-TOKEN", token)
+	}
+	return &gitLab{
+		repo: repo,
+		c:    newClient(api, h),
+		path: "/projects/" + strings.ReplaceAll(url.PathEscape(repo.FullName()), "/", "%2F"),
+	}
+}
+
+func (g *gitLab) Kind() string { return KindGitLab }
+func (g *gitLab) Repo() Repo   { return g.repo }
+
+type glUser struct {
+	Username string `json:"username"`
+}
+
+type glMergeRequest struct {
+	IID                 int       `json:"iid"`
+	URL                 string    `json:"web_url"`
+	Title               string    `json:"title"`
+	Description         string    `json:"description"`
+	State               string    `json:"state"` // opened, closed, locked, merged
+	Author              glUser    `json:"author"`
+	SourceBranch        string    `json:"source_branch"`
+	TargetBranch        string    `json:"target_branch"`
+	SHA                 string    `json:"sha"`
+	HasConflicts        bool      `json:"has_conflicts"`
+	MergeStatus         string    `json:"merge_status"`          // can_be_merged, cannot_be_merged, checking, ...
+	DetailedMergeStatus string    `json:"detailed_merge_status"` // mergeable, requested_changes, ...
+	Labels              []string  `json:"labels"`
+	MergeCommitSHA      string    `json:"merge_commit_sha"`
+	SquashCommitSHA     string    `json:"squash_commit_sha"`
+	ChangesCount        string    `json:"changes_count"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// pullRequest normalizes a GitLab merge request.
+func (m *glMergeRequest) pullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:           m.IID,
+		URL:              m.URL,
+		Title:            m.Title,
+		Body:             m.Description,
+		State:            PRClosed,
+		Author:           m.Author.Username,
+		HeadRef:          m.SourceBranch,
+		BaseRef:          m.TargetBranch,
+		HeadSHA:          m.SHA,
+		Labels:           m.Labels,
+		ChangesRequested: m.DetailedMergeStatus == "requested_changes",
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+	switch m.State {
+	case "opened", "locked":
+		pr.State = PROpen
+	case "merged":
+		pr.State = PRMerged
+		pr.MergeCommit = m.MergeCommitSHA
+		if pr.MergeCommit == "" {
+			pr.MergeCommit = m.SquashCommitSHA
+		}
+	}
+	switch {
+	case m.HasConflicts:
+		pr.Mergeable = MergeConflict
+	case m.DetailedMergeStatus == "mergeable" || m.MergeStatus == "can_be_merged":
+		pr.Mergeable = MergeClean
+	}
+	_, _ = fmt.Sscanf(m.ChangesCount, "%d", &pr.ChangedFiles) // "12", or "1000+"
+	return pr
+}
+
+type glStatus struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"` // pending, running, success, failed, canceled, skipped, created, manual
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+func (s *glStatus) check() Check {
+	c := Check{Name: s.Name, State: CheckPending, Description: s.Description, URL: s.TargetURL}
+	switch s.Status {
+	case "success", "skipped":
+		c.State = CheckSuccess
+	case "failed", "canceled":
+		c.State = CheckFailure
+	}
+	return c
+}
+
+func (g *gitLab) mrPath(number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", g.path, number)
+}
+
+func (g *gitLab) ListPRs(ctx context.Context) ([]*PullRequest, error) {
+	var mrs []glMergeRequest
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/merge_requests?state=opened&per_page=%d", g.path, perPage), nil, &mrs); err != nil {
+		return nil, err
+	}
+	prs := make([]*PullRequest, 0, len(mrs))
+	for i := range mrs {
+		pr := mrs[i].pullRequest()
+		var err error
+		if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (g *gitLab) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var mr glMergeRequest
+	if err := g.c.do(ctx, http.MethodGet, g.mrPath(number), nil, &mr); err != nil {
+		return nil, err
+	}
+	pr := mr.pullRequest()
+	var approvals struct {
+		ApprovedBy []struct {
+			User glUser `json:"user"`
+		} `json:"approved_by"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, g.mrPath(number)+"/approvals", nil, &approvals); err != nil {
+		return nil, err
+	}
+	pr.Approvals = len(approvals.ApprovedBy)
+	var err error
+	if pr.Checks, err = g.Statuses(ctx, pr.HeadSHA); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (g *gitLab) FindPR(ctx context.Context, head, base string) (*PullRequest, error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", head)
+	q.Set("target_branch", base)
+	var mrs []glMergeRequest
+	if err := g.c.do(ctx, http.MethodGet, g.path+"/merge_requests?"+q.Encode(), nil, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return g.GetPR(ctx, mrs[0].IID)
+}
+
+func (g *gitLab) CreatePR(ctx context.Context, req PRRequest) (*PullRequest, error) {
+	in := map[string]string{
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+		"title":         req.Title,
+		"description":   req.Body,
+	}
+	var mr glMergeRequest
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/merge_requests", in, &mr); err != nil {
+		return nil, err
+	}
+	return g.GetPR(ctx, mr.IID)
+}
+
+func (g *gitLab) UpdatePR(ctx context.Context, number int, req PRRequest) error {
+	in := map[string]string{"title": req.Title, "description": req.Body}
+	return g.c.do(ctx, http.MethodPut, g.mrPath(number), in, nil)
+}
+
+// MergePR merges a merge request. Squash is requested per merge; merge and
+// rebase both follow the project's merge method setting.
+func (g *gitLab) MergePR(ctx context.Context, number int, method, headSHA string) error {
+	in := map[string]any{"squash": method == "squash"}
+	if headSHA != "" {
+		in["sha"] = headSHA
+	}
+	return g.c.do(ctx, http.MethodPut, g.mrPath(number)+"/merge", in, nil)
+}
+
+func (g *gitLab) PRFiles(ctx context.Context, number int) ([]File, error) {
+	var diffs []struct {
+		NewPath string `json:"new_path"`
+		Diff    string `json:"diff"`
+	}
+	if err := g.c.do(ctx, http.MethodGet, g.mrPath(number)+"/diffs?per_page=100", nil, &diffs); err != nil {
+		return nil, err
+	}
+	files := make([]File, 0, len(diffs))
+	for _, d := range diffs {
+		f := File{Path: d.NewPath}
+		f.Additions, f.Deletions = countDiffLines(d.Diff)
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// countDiffLines counts added and removed lines in a diff's hunks (GitLab
+// sends them without ---/+++ file headers).
+func countDiffLines(diff string) (additions, deletions int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+type glIssue struct {
+	IID         int       `json:"iid"`
+	URL         string    `json:"web_url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       string    `json:"state"` // opened, closed
+	Author      glUser    `json:"author"`
+	Labels      []string  `json:"labels"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (i *glIssue) issue() *Issue {
+	state := IssueClosed
+	if i.State == "opened" {
+		state = IssueOpen
+	}
+	return &Issue{
+		Number:    i.IID,
+		URL:       i.URL,
+		Title:     i.Title,
+		Body:      i.Description,
+		State:     state,
+		Author:    i.Author.Username,
+		Labels:    i.Labels,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func (g *gitLab) ListIssues(ctx context.Context) ([]*Issue, error) {
+	var raw []glIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues?state=opened&per_page=%d", g.path, perPage), nil, &raw); err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(raw))
+	for i := range raw {
+		issues = append(issues, raw[i].issue())
+	}
+	return issues, nil
+}
+
+func (g *gitLab) GetIssue(ctx context.Context, number int) (*Issue, error) {
+	var i glIssue
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", g.path, number), nil, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitLab) CreateIssue(ctx context.Context, req IssueRequest) (*Issue, error) {
+	in := map[string]string{"title": req.Title, "description": req.Body}
+	if len(req.Labels) > 0 {
+		in["labels"] = strings.Join(req.Labels, ",")
+	}
+	var i glIssue
+	if err := g.c.do(ctx, http.MethodPost, g.path+"/issues", in, &i); err != nil {
+		return nil, err
+	}
+	return i.issue(), nil
+}
+
+func (g *gitLab) Statuses(ctx context.Context, sha string) ([]Check, error) {
+	if sha == "" {
+		return nil, nil
+	}
+	var raw []glStatus
+	if err := g.c.do(ctx, http.MethodGet, fmt.Sprintf("%s/repository/commits/%s/statuses?per_page=100", g.path, url.PathEscape(sha)), nil, &raw); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(raw))
+	for i := range raw {
+		checks = append(checks, raw[i].check())
+	}
+	return checks, nil
+}
+
+func (g *gitLab) SetStatus(ctx context.Context, sha string, check Check) error {
+	state := check.State
+	if state == CheckFailure {
+		state = "failed"
+	}
+	in := map[string]string{
+		"state":       state,
+		"name":        check.Name,
+		"description": check.Description,
+		"target_url":  check.URL,
+	}
+	return g.c.do(ctx, http.MethodPost, g.path+"/statuses/"+url.PathEscape(sha), in, nil)
+}
+
+func (g *gitLab) notesPath(kind ItemKind, number int) string {
+	if kind == ItemPR {
+		return g.mrPath(number) + "/notes"
+	}
+	return fmt.Sprintf("%s/issues/%d/notes", g.path, number)
+}
+
+type glNote struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    glUser    `json:"author"`
+	System    bool      `json:"system"` // GitLab's own activity notes
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (g *gitLab) Comments(ctx context.Context, kind ItemKind, number int) ([]Comment, error) {
+	var notes []glNote
+	if err := g.c.do(ctx, http.MethodGet, g.notesPath(kind, number)+"?sort=asc&per_page=100", nil, &notes); err != nil {
+		return nil, err
+	}
+	var out []Comment
+	for _, n := range notes {
+		if !n.System {
+			out = append(out, Comment{ID: n.ID, Author: n.Author.Username, Body: n.Body, CreatedAt: n.CreatedAt})
+		}
+	}
+	return out, nil
+}
+
+func (g *gitLab) AddComment(ctx context.Context, kind ItemKind, number int, body string) (*Comment, error) {
+	var n glNote
+	if err := g.c.do(ctx, http.MethodPost, g.notesPath(kind, number), map[string]string{"body": body}, &n); err != nil {
+		return nil, err
+	}
+	return &Comment{ID: n.ID, Author: n.Author.Username, Body: n.Body, CreatedAt: n.CreatedAt}, nil
+}
@@ -0,0 +1,22 @@
+package git
+
+// CreateBundle writes a bundle of refs to path, leaving out history already
+// on a remote so the bundle only carries unpushed work. Its prerequisites
+// are fetched from the remote when the bundle is applied.
+func (g *Git) CreateBundle(path string, refs ...string) error {
+	args := append([]string{"bundle", "create", "--quiet", path}, refs...)
+	args = append(args, "--not", "--remotes")
+	_, err := g.run(args...)
+	return err
+}
+
+// FetchBundle fetches refspecs (e.g., "refs/heads/*:refs/heads/*") from a
+// bundle file, overwriting local refs.
+func (g *Git) FetchBundle(path string, refspecs ...string) error {
+	args := []string{"fetch", "--quiet", "--update-head-ok", path}
+	for _, spec := range refspecs {
+		args = append(args, "+"+spec)
+	}
+	_, err := g.run(args...)
+	return err
+}
@@ -369,6 +369,12 @@ func (g *Git) Checkout(ref string) error {
 	return err
 }
 
+// CheckoutNewBranch creates (or resets) branch at startPoint and checks it out.
+func (g *Git) CheckoutNewBranch(branch, startPoint string) error {
+	_, err := g.run("checkout", "-B", branch, startPoint)
+	return err
+}
+
 // Fetch fetches from the remote.
 func (g *Git) Fetch(remote string) error {
 	_, err := g.run("fetch", remote)
@@ -927,6 +933,12 @@ func (g *Git) WorktreeRemove(path string, force bool) error {
 	return err
 }
 
+// WorktreeMove moves a worktree to a new path, updating git's bookkeeping.
+func (g *Git) WorktreeMove(from, to string) error {
+	_, err := g.run("worktree", "move", from, to)
+	return err
+}
+
 // WorktreePrune removes worktree entries for deleted paths.
 func (g *Git) WorktreePrune() error {
 	_, err := g.run("worktree", "prune")
@@ -1033,6 +1045,77 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// LogOneline returns the one-line log of commits on branch that are not on
+// base, newest first, at most max entries (0 for all).
+func (g *Git) LogOneline(base, branch string, max int) (string, error) {
+	args := []string{"log", "--oneline", "--no-decorate"}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+	return g.run(append(args, base+".."+branch)...)
+}
+
+// DiffStat returns the diffstat of branch against its merge base with base:
+// what merging branch into base would change.
+func (g *Git) DiffStat(base, branch string) (string, error) {
+	return g.run("diff", "--stat", base+"..."+branch)
+}
+
+// Hunk is a changed line range on the old side of a diff: Lines lines from
+// Start, or a pure insertion after line Start when Lines is 0.
+type Hunk struct {
+	Start int `json:"start"`
+	Lines int `json:"lines"`
+}
+
+// ChangedHunks returns the line ranges branch changes in each file, relative
+// to its merge base with base (the old side of "git diff base...branch").
+// Files changed without line hunks (binary files, mode changes) map to an
+// empty slice.
+func (g *Git) ChangedHunks(base, branch string) (map[string][]Hunk, error) {
+	out, err := g.run("diff", "-U0", "--no-color", "--no-ext-diff", "--no-renames", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseHunks(out), nil
+}
+
+// parseHunks parses unified diff output with zero context into per-file
+// hunks.
+func parseHunks(diff string) map[string][]Hunk {
+	files := make(map[string][]Hunk)
+	var current string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			// "diff --git a/<path> b/<path>"; without renames both match
+			current = ""
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				current = line[i+3:]
+				files[current] = []Hunk{}
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			// "@@ -<start>[,<lines>] +<start>[,<lines>] @@"
+			fields := strings.Fields(line)
+			if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+				continue
+			}
+			h := Hunk{Lines: 1}
+			start, count, hasCount := strings.Cut(fields[1][1:], ",")
+			if _, err := fmt.Sscanf(start, "%d", &h.Start); err != nil {
+				continue
+			}
+			if hasCount {
+				if _, err := fmt.Sscanf(count, "%d", &h.Lines); err != nil {
+					continue
+				}
+			}
+			files[current] = append(files[current], h)
+		}
+	}
+	return files
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -332,6 +333,104 @@ func TestFetchBranch(t *testing.T) {
 	}
 }
 
+func TestLogOnelineAndDiffStat(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout feature: %v", err)
+	}
+	for _, name := range []string{"one.txt", "two.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("line\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	log, err := g.LogOneline(mainBranch, "feature", 0)
+	if err != nil {
+		t.Fatalf("LogOneline: %v", err)
+	}
+	lines := strings.Split(log, "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "add two.txt") {
+		t.Errorf("LogOneline = %q, want two commits newest first", log)
+	}
+	if log, _ := g.LogOneline(mainBranch, "feature", 1); strings.Contains(log, "\n") {
+		t.Errorf("LogOneline max 1 = %q, want one line", log)
+	}
+
+	stat, err := g.DiffStat(mainBranch, "feature")
+	if err != nil {
+		t.Fatalf("DiffStat: %v", err)
+	}
+	if !strings.Contains(stat, "one.txt") || !strings.Contains(stat, "2 files changed") {
+		t.Errorf("DiffStat = %q", stat)
+	}
+}
+
+func TestChangedHunks(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	lines := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	write := func(name string, content []string, msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(content, "\n")+"\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit(msg); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+	write("code.txt", lines, "add code")
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout feature: %v", err)
+	}
+	changed := append([]string{}, lines...)
+	changed[1] = "B"                              // line 2 replaced
+	changed = append(changed[:5], changed[6:]...) // line 6 deleted
+	changed = append(changed, "i")                // appended after line 8
+	write("code.txt", changed, "edit code")
+	write("new.txt", []string{"x"}, "add new")
+
+	hunks, err := g.ChangedHunks(mainBranch, "feature")
+	if err != nil {
+		t.Fatalf("ChangedHunks: %v", err)
+	}
+	want := map[string][]Hunk{
+		"code.txt": {{Start: 2, Lines: 1}, {Start: 6, Lines: 1}, {Start: 8, Lines: 0}},
+		"new.txt":  {{Start: 0, Lines: 0}},
+	}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Errorf("ChangedHunks = %v, want %v", hunks, want)
+	}
+}
+
+func TestParseHunksBinary(t *testing.T) {
+	diff := "diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files a/logo.png and b/logo.png differ"
+	got := parseHunks(diff)
+	if h, ok := got["logo.png"]; !ok || len(h) != 0 {
+		t.Errorf("parseHunks = %v, want logo.png with no hunks", got)
+	}
+}
+
 func TestCheckConflicts_NoConflict(t *testing.T) {
 	dir := initTestRepo(t)
 	g := NewGit(dir)
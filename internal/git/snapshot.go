@@ -0,0 +1,178 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotIdentity commits snapshots under a fixed identity so they work in
+// worktrees without user.name/user.email configured.
+var snapshotIdentity = []string{
+	"GIT_AUTHOR_NAME=Gas Town", "GIT_AUTHOR_EMAIL=gastown@localhost",
+	"GIT_COMMITTER_NAME=Gas Town", "GIT_COMMITTER_EMAIL=gastown@localhost",
+}
+
+// Ref is a ref and the commit it points to.
+type Ref struct {
+	Name string
+	SHA  string
+	Time time.Time // Committer date of the commit
+}
+
+// Path returns the repository path: the git dir of a bare repo, otherwise
+// the working directory.
+func (g *Git) Path() string {
+	if g.gitDir != "" {
+		return g.gitDir
+	}
+	return g.workDir
+}
+
+// CommonDir returns the absolute path of the repository's common git dir,
+// which linked worktrees share with their main repository.
+func (g *Git) CommonDir() (string, error) {
+	dir, err := g.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workDir, dir)
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return dir, nil
+}
+
+// SnapshotTree writes a tree object of the working tree as 'git add -A'
+// would stage it, without touching the real index. Paths in exclude keep
+// their HEAD version. Untracked files are included; ignored files are not.
+func (g *Git) SnapshotTree(exclude []string) (string, error) {
+	tmp, err := os.CreateTemp("", "gt-snapshot-index-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	// Start from a copy of the real index so unchanged files aren't rehashed.
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	if indexPath, err := g.run("rev-parse", "--git-path", "index"); err == nil {
+		if !filepath.IsAbs(indexPath) {
+			indexPath = filepath.Join(g.workDir, indexPath)
+		}
+		if data, err := os.ReadFile(indexPath); err == nil { //nolint:gosec // G304: path comes from git
+			if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+				return "", fmt.Errorf("copying index: %w", err)
+			}
+		}
+	}
+	if info, err := os.Stat(tmpPath); err != nil || info.Size() == 0 {
+		if _, err := g.runWithEnv([]string{"read-tree", "HEAD"}, env); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := g.runWithEnv([]string{"add", "-A"}, env); err != nil {
+		return "", err
+	}
+	if len(exclude) > 0 {
+		args := append([]string{"reset", "-q", "HEAD", "--"}, exclude...)
+		if _, err := g.runWithEnv(args, env); err != nil {
+			return "", err
+		}
+	}
+	return g.runWithEnv([]string{"write-tree"}, env)
+}
+
+// CommitTree creates a commit of tree with the given parent (if any) and
+// returns its SHA. No branch or ref is updated.
+func (g *Git) CommitTree(tree, parent, message string) (string, error) {
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	return g.runWithEnv(args, snapshotIdentity)
+}
+
+// UpdateRef points ref at sha, creating it if needed.
+func (g *Git) UpdateRef(ref, sha string) error {
+	_, err := g.run("update-ref", ref, sha)
+	return err
+}
+
+// DeleteRef deletes ref.
+func (g *Git) DeleteRef(ref string) error {
+	_, err := g.run("update-ref", "-d", ref)
+	return err
+}
+
+// PushRef pushes sha to ref in the repository at repoPath (a path or URL),
+// bypassing hooks.
+func (g *Git) PushRef(repoPath, sha, ref string) error {
+	_, err := g.run("push", "--quiet", "--no-verify", repoPath, sha+":"+ref)
+	return err
+}
+
+// ListRefs returns the refs under prefix (e.g., "refs/gt/wip/"), sorted by
+// name.
+func (g *Git) ListRefs(prefix string) ([]Ref, error) {
+	out, err := g.run("for-each-ref", "--sort=refname",
+		"--format=%(refname)%09%(objectname)%09%(committerdate:unix)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		ts, _ := strconv.ParseInt(parts[2], 10, 64)
+		refs = append(refs, Ref{Name: parts[0], SHA: parts[1], Time: time.Unix(ts, 0)})
+	}
+	return refs, nil
+}
+
+// CommitMessage returns the full message of a commit.
+func (g *Git) CommitMessage(sha string) (string, error) {
+	return g.run("log", "-1", "--format=%B", sha)
+}
+
+// ReadTreeReset makes the working tree match tree, including deletions,
+// then resets the index to HEAD so the differences show as uncommitted
+// changes.
+func (g *Git) ReadTreeReset(tree string) error {
+	if _, err := g.run("read-tree", "-u", "--reset", tree); err != nil {
+		return err
+	}
+	_, err := g.run("reset", "-q")
+	return err
+}
+
+// RemoteContains reports whether sha is reachable from any remote-tracking
+// ref, i.e., whether it has been pushed.
+func (g *Git) RemoteContains(sha string) (bool, error) {
+	out, err := g.run("for-each-ref", "--count=1", "--contains", sha, "--format=%(refname)", "refs/remotes/")
+	if err != nil {
+		return false, err
+	}
+	return out != "", nil
+}
+
+// DiffNames returns the paths that differ between two tree-ish objects.
+func (g *Git) DiffNames(from, to string) ([]string, error) {
+	out, err := g.run("diff-tree", "-r", "--name-only", "--no-commit-id", from, to)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
@@ -0,0 +1,198 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GitHub delivery headers.
+const (
+	githubEventHeader     = "X-GitHub-Event"
+	githubDeliveryHeader  = "X-GitHub-Delivery"
+	githubSignatureHeader = "X-Hub-Signature-256"
+)
+
+// VerifyGitHub checks a delivery's X-Hub-Signature-256 against the secret.
+func VerifyGitHub(secret string, header http.Header, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("no GitHub webhook secret configured")
+	}
+	sig, ok := strings.CutPrefix(header.Get(githubSignatureHeader), "sha256=")
+	if !ok {
+		return fmt.Errorf("missing %s header", githubSignatureHeader)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(sig)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// githubUser, githubLabel, ... are the parts of GitHub payloads we read.
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubLabel struct {
+	Name string `json:"name"`
+}
+
+type githubPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Sender githubUser   `json:"sender"`
+	Label  *githubLabel `json:"label"`
+	Issue  *struct {
+		Number      int             `json:"number"`
+		Title       string          `json:"title"`
+		Body        string          `json:"body"`
+		HTMLURL     string          `json:"html_url"`
+		User        githubUser      `json:"user"`
+		Labels      []githubLabel   `json:"labels"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	PullRequest *struct {
+		Number  int           `json:"number"`
+		Title   string        `json:"title"`
+		Body    string        `json:"body"`
+		HTMLURL string        `json:"html_url"`
+		User    githubUser    `json:"user"`
+		Labels  []githubLabel `json:"labels"`
+		Merged  bool          `json:"merged"`
+		Head    struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Comment *struct {
+		Body    string     `json:"body"`
+		HTMLURL string     `json:"html_url"`
+		Path    string     `json:"path"`
+		User    githubUser `json:"user"`
+	} `json:"comment"`
+	CheckSuite *struct {
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_suite"`
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	} `json:"workflow_run"`
+}
+
+// ParseGitHub normalizes a GitHub delivery. Returns nil, nil for event types
+// the receiver doesn't handle (including ping).
+func ParseGitHub(header http.Header, body []byte) (*RepoEvent, error) {
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parsing GitHub payload: %w", err)
+	}
+	e := &RepoEvent{
+		Provider: ProviderGitHub,
+		Delivery: header.Get(githubDeliveryHeader),
+		Action:   p.Action,
+		Repo:     p.Repository.FullName,
+		Author:   p.Sender.Login,
+	}
+	if p.Label != nil {
+		e.Label = p.Label.Name
+	}
+
+	switch header.Get(githubEventHeader) {
+	case "issues":
+		if p.Issue == nil {
+			return nil, fmt.Errorf("issues event without issue")
+		}
+		e.Kind = KindIssue
+		e.Number, e.Title, e.Body, e.URL = p.Issue.Number, p.Issue.Title, p.Issue.Body, p.Issue.HTMLURL
+		e.Author = p.Issue.User.Login
+		e.Labels = githubLabelNames(p.Issue.Labels)
+
+	case "pull_request":
+		if p.PullRequest == nil {
+			return nil, fmt.Errorf("pull_request event without pull_request")
+		}
+		pr := p.PullRequest
+		e.Kind = KindPullRequest
+		e.Number, e.Title, e.Body, e.URL = pr.Number, pr.Title, pr.Body, pr.HTMLURL
+		e.Author = pr.User.Login
+		e.Labels = githubLabelNames(pr.Labels)
+		e.Branch, e.Commit = pr.Head.Ref, pr.Head.SHA
+		if e.Action == "closed" && pr.Merged {
+			e.Action = "merged"
+		}
+
+	case "pull_request_review_comment":
+		if p.PullRequest == nil || p.Comment == nil {
+			return nil, fmt.Errorf("review comment event without pull_request or comment")
+		}
+		e.Kind = KindComment
+		e.Number, e.Title = p.PullRequest.Number, p.PullRequest.Title
+		e.Branch, e.Commit = p.PullRequest.Head.Ref, p.PullRequest.Head.SHA
+		e.Body, e.URL, e.Author = p.Comment.Body, p.Comment.HTMLURL, p.Comment.User.Login
+		if p.Comment.Path != "" {
+			e.Body = p.Comment.Path + ": " + e.Body
+		}
+
+	case "check_suite":
+		if p.CheckSuite == nil || p.Action != "completed" {
+			return nil, nil
+		}
+		e.Kind = KindCI
+		e.Branch, e.Commit = p.CheckSuite.HeadBranch, p.CheckSuite.HeadSHA
+		e.Conclusion = p.CheckSuite.Conclusion
+		e.Action = ciAction(e.Conclusion)
+		e.Title = "Checks"
+		e.URL = fmt.Sprintf("%s/commit/%s/checks", p.Repository.HTMLURL, e.Commit)
+
+	case "workflow_run":
+		if p.WorkflowRun == nil || p.Action != "completed" {
+			return nil, nil
+		}
+		e.Kind = KindCI
+		e.Branch, e.Commit = p.WorkflowRun.HeadBranch, p.WorkflowRun.HeadSHA
+		e.Conclusion = p.WorkflowRun.Conclusion
+		e.Action = ciAction(e.Conclusion)
+		e.Title, e.URL = p.WorkflowRun.Name, p.WorkflowRun.HTMLURL
+
+	default:
+		return nil, nil
+	}
+	return e, nil
+}
+
+func githubLabelNames(labels []githubLabel) []string {
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// ciAction maps a CI conclusion or status to a RepoEvent action.
+func ciAction(conclusion string) string {
+	switch conclusion {
+	case "failure", "failed", "timed_out", "startup_failure":
+		return "failed"
+	case "success":
+		return "succeeded"
+	case "cancelled", "canceled":
+		return "canceled"
+	default:
+		return conclusion
+	}
+}
@@ -0,0 +1,178 @@
+package inbound
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GitLab delivery headers.
+const (
+	gitlabEventHeader    = "X-Gitlab-Event"
+	gitlabDeliveryHeader = "X-Gitlab-Event-UUID"
+	gitlabTokenHeader    = "X-Gitlab-Token"
+)
+
+// VerifyGitLab checks a delivery's X-Gitlab-Token against the configured
+// secret token.
+func VerifyGitLab(token string, header http.Header) error {
+	if token == "" {
+		return fmt.Errorf("no GitLab webhook token configured")
+	}
+	got := header.Get(gitlabTokenHeader)
+	if got == "" {
+		return fmt.Errorf("missing %s header", gitlabTokenHeader)
+	}
+	if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		return fmt.Errorf("token mismatch")
+	}
+	return nil
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabLabel struct {
+	Title string `json:"title"`
+}
+
+type gitlabPayload struct {
+	User    gitlabUser `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+		SourceBranch string `json:"source_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+		// Pipelines
+		Ref    string `json:"ref"`
+		SHA    string `json:"sha"`
+		Status string `json:"status"`
+		// Notes
+		Note         string `json:"note"`
+		NoteableType string `json:"noteable_type"`
+	} `json:"object_attributes"`
+	Labels  []gitlabLabel `json:"labels"`
+	Changes struct {
+		Labels *struct {
+			Previous []gitlabLabel `json:"previous"`
+			Current  []gitlabLabel `json:"current"`
+		} `json:"labels"`
+	} `json:"changes"`
+	MergeRequest *struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		SourceBranch string `json:"source_branch"`
+	} `json:"merge_request"`
+}
+
+// gitlabActions maps GitLab object actions to RepoEvent actions.
+var gitlabActions = map[string]string{
+	"open":   "opened",
+	"reopen": "reopened",
+	"close":  "closed",
+	"merge":  "merged",
+	"update": "updated",
+}
+
+// ParseGitLab normalizes a GitLab delivery. Returns nil, nil for event
+// types the receiver doesn't handle.
+func ParseGitLab(header http.Header, body []byte) (*RepoEvent, error) {
+	var p gitlabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parsing GitLab payload: %w", err)
+	}
+	attrs := &p.ObjectAttributes
+	e := &RepoEvent{
+		Provider: ProviderGitLab,
+		Delivery: header.Get(gitlabDeliveryHeader),
+		Repo:     p.Project.PathWithNamespace,
+		Author:   p.User.Username,
+		Action:   attrs.Action,
+	}
+	if action, ok := gitlabActions[attrs.Action]; ok {
+		e.Action = action
+	}
+
+	switch header.Get(gitlabEventHeader) {
+	case "Issue Hook":
+		e.Kind = KindIssue
+		e.Number, e.Title, e.Body, e.URL = attrs.IID, attrs.Title, attrs.Description, attrs.URL
+		e.Labels = gitlabLabelTitles(p.Labels)
+		// An update that adds a label is GitHub's "labeled".
+		if added := addedLabel(p); added != "" && e.Action == "updated" {
+			e.Action, e.Label = "labeled", added
+		}
+
+	case "Merge Request Hook":
+		e.Kind = KindPullRequest
+		e.Number, e.Title, e.Body, e.URL = attrs.IID, attrs.Title, attrs.Description, attrs.URL
+		e.Labels = gitlabLabelTitles(p.Labels)
+		e.Branch, e.Commit = attrs.SourceBranch, attrs.LastCommit.ID
+		if added := addedLabel(p); added != "" && e.Action == "updated" {
+			e.Action, e.Label = "labeled", added
+		}
+
+	case "Note Hook":
+		if attrs.NoteableType != "MergeRequest" || p.MergeRequest == nil {
+			return nil, nil
+		}
+		e.Kind, e.Action = KindComment, "created"
+		e.Number, e.Title = p.MergeRequest.IID, p.MergeRequest.Title
+		e.Branch = p.MergeRequest.SourceBranch
+		e.Body, e.URL = attrs.Note, attrs.URL
+
+	case "Pipeline Hook":
+		switch attrs.Status {
+		case "failed", "success", "canceled":
+		default:
+			return nil, nil // Still running
+		}
+		e.Kind = KindCI
+		e.Branch, e.Commit = attrs.Ref, attrs.SHA
+		e.Conclusion = attrs.Status
+		e.Action = ciAction(attrs.Status)
+		e.Title = "Pipeline"
+		e.URL = fmt.Sprintf("%s/-/pipelines/%d", p.Project.WebURL, attrs.ID)
+
+	default:
+		return nil, nil
+	}
+	return e, nil
+}
+
+func gitlabLabelTitles(labels []gitlabLabel) []string {
+	titles := make([]string, 0, len(labels))
+	for _, l := range labels {
+		titles = append(titles, l.Title)
+	}
+	return titles
+}
+
+// addedLabel returns the first label an update added, if any.
+func addedLabel(p gitlabPayload) string {
+	if p.Changes.Labels == nil {
+		return ""
+	}
+	previous := make(map[string]bool)
+	for _, l := range p.Changes.Labels.Previous {
+		previous[l.Title] = true
+	}
+	for _, l := range p.Changes.Labels.Current {
+		if !previous[l.Title] {
+			return l.Title
+		}
+	}
+	return ""
+}
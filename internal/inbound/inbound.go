@@ -0,0 +1,260 @@
+// Package inbound receives GitHub and GitLab webhooks for the repos rigs wrap
+// and turns repo activity into town work.
+//
+// Deliveries are verified (GitHub HMAC signature, GitLab secret token),
+// normalized into a RepoEvent, and matched against the rules in
+// settings/inbound.json. A matching rule creates a bead, slings a formula on
+// a tracking bead, or mails the polecat that owns a branch.
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ConfigFile is the receiver configuration, relative to the town root. It
+// holds webhook secrets, so it is written 0600 and ignored by the HQ
+// .gitignore.
+const ConfigFile = "settings/inbound.json"
+
+// Providers.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Event kinds.
+const (
+	KindIssue       = "issue"
+	KindPullRequest = "pull_request" // GitHub pull requests and GitLab merge requests
+	KindComment     = "comment"      // Review comments on a pull request
+	KindCI          = "ci"           // Check suites, workflow runs and pipelines
+)
+
+// Rule actions.
+const (
+	ActionCreateBead = "create_bead"
+	ActionSling      = "sling"
+	ActionMail       = "mail"
+)
+
+// DefaultReviewFormula is slung on new pull requests by sling rules that
+// don't name a formula.
+const DefaultReviewFormula = "mol-polecat-review-pr"
+
+// RepoEvent is a provider-neutral description of repo activity.
+type RepoEvent struct {
+	Provider   string   `json:"provider"`
+	Delivery   string   `json:"delivery,omitempty"` // Provider's delivery ID
+	Kind       string   `json:"kind"`
+	Action     string   `json:"action"` // opened, labeled, closed, created, failed, succeeded, ...
+	Repo       string   `json:"repo"`   // owner/name (GitLab: full project path)
+	Number     int      `json:"number,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	Label      string   `json:"label,omitempty"`  // Label just added (labeled events)
+	Branch     string   `json:"branch,omitempty"` // PR head branch or CI ref
+	Commit     string   `json:"commit,omitempty"`
+	Conclusion string   `json:"conclusion,omitempty"` // CI: provider's raw conclusion
+}
+
+// Ref returns the event's upstream reference, e.g. "org/repo#12".
+func (e *RepoEvent) Ref() string {
+	if e.Number == 0 {
+		return e.Repo
+	}
+	return fmt.Sprintf("%s#%d", e.Repo, e.Number)
+}
+
+// Rule maps matching repo activity to an action.
+type Rule struct {
+	Name string `json:"name,omitempty"`
+
+	// Match conditions. Empty conditions match everything.
+	On       string `json:"on"`                 // "<kind>.<action>" glob, e.g. "issue.labeled", "ci.failed", "pull_request.*"
+	Provider string `json:"provider,omitempty"` // github or gitlab
+	Repo     string `json:"repo,omitempty"`     // Repo glob, e.g. "acme/*"
+	Label    string `json:"label,omitempty"`    // Required label (the label added, for labeled events)
+	Branch   string `json:"branch,omitempty"`   // Branch glob, e.g. "polecat/*"
+
+	// Action and its settings.
+	Action   string `json:"action"`             // create_bead, sling or mail
+	Rig      string `json:"rig,omitempty"`      // Rig to act in (default: rig wrapping the repo)
+	Type     string `json:"type,omitempty"`     // create_bead/sling: bead type (default task)
+	Priority *int   `json:"priority,omitempty"` // create_bead/sling: bead priority (default 2)
+	Formula  string `json:"formula,omitempty"`  // sling: formula (default mol-polecat-review-pr)
+	Target   string `json:"target,omitempty"`   // sling: target (default: the rig, i.e. a fresh polecat)
+	To       string `json:"to,omitempty"`       // mail: recipient (default: polecat owning the branch)
+}
+
+// Config is the receiver configuration.
+type Config struct {
+	GitHubSecret string            `json:"github_secret,omitempty"` // Webhook secret for X-Hub-Signature-256
+	GitLabToken  string            `json:"gitlab_token,omitempty"`  // Secret token sent as X-Gitlab-Token
+	Repos        map[string]string `json:"repos,omitempty"`         // Repo → rig, overriding git_url matching
+	Rules        []*Rule           `json:"rules"`
+}
+
+// ConfigPath returns the path of the receiver configuration.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, ConfigFile)
+}
+
+// LoadConfig reads the receiver configuration. Returns nil, nil when the
+// town has none (the receiver is disabled).
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading inbound config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing inbound config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveConfig writes the receiver configuration.
+func SaveConfig(townRoot string, cfg *Config) error {
+	if err := util.EnsureDirAndWriteJSONWithPerm(ConfigPath(townRoot), cfg, 0600); err != nil {
+		return fmt.Errorf("writing inbound config: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the rules.
+func (c *Config) Validate() error {
+	for i, r := range c.Rules {
+		switch r.Action {
+		case ActionCreateBead, ActionSling, ActionMail:
+		default:
+			return fmt.Errorf("inbound rule %d (%s): unknown action %q (want create_bead, sling or mail)", i+1, r.Name, r.Action)
+		}
+		if r.On == "" {
+			return fmt.Errorf("inbound rule %d (%s): missing \"on\"", i+1, r.Name)
+		}
+		if _, err := path.Match(r.On, ""); err != nil {
+			return fmt.Errorf("inbound rule %d (%s): bad \"on\" pattern: %w", i+1, r.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to an event.
+func (r *Rule) Matches(e *RepoEvent) bool {
+	if !globMatch(r.On, e.Kind+"."+e.Action) {
+		return false
+	}
+	if r.Provider != "" && r.Provider != e.Provider {
+		return false
+	}
+	if r.Repo != "" && !globMatch(r.Repo, e.Repo) {
+		return false
+	}
+	if r.Branch != "" && !globMatch(r.Branch, e.Branch) {
+		return false
+	}
+	if r.Label != "" {
+		if e.Label != "" {
+			return strings.EqualFold(e.Label, r.Label)
+		}
+		for _, l := range e.Labels {
+			if strings.EqualFold(l, r.Label) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// label returns the name used in logs and results.
+func (r *Rule) label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.On + "→" + r.Action
+}
+
+// ResolveRig returns the rig a rule acts in for an event: the rule's rig,
+// the configured repo mapping, or the rig whose git_url points at the repo.
+func (c *Config) ResolveRig(townRoot string, r *Rule, e *RepoEvent) (string, error) {
+	if r.Rig != "" {
+		return r.Rig, nil
+	}
+	if rig, ok := c.Repos[e.Repo]; ok {
+		return rig, nil
+	}
+	rigs, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return "", fmt.Errorf("loading rigs: %w", err)
+	}
+	for name, entry := range rigs.Rigs {
+		if repoFromGitURL(entry.GitURL) == strings.ToLower(e.Repo) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no rig wraps %s (set \"rig\" on the rule or add it to \"repos\")", e.Repo)
+}
+
+// repoFromGitURL extracts the lower-cased owner/name path from a git URL
+// (https://host/owner/name.git, git@host:owner/name.git, ssh://...).
+func repoFromGitURL(gitURL string) string {
+	s := strings.TrimSuffix(strings.TrimSpace(gitURL), "/")
+	s = strings.TrimSuffix(s, ".git")
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+		if j := strings.Index(s, "/"); j >= 0 {
+			s = s[j+1:]
+		}
+	} else if i := strings.Index(s, ":"); i >= 0 {
+		s = s[i+1:] // scp-like git@host:owner/name
+	}
+	return strings.ToLower(s)
+}
+
+// PolecatFromBranch returns the polecat that owns a branch created by
+// gt sling (polecat/<name>/<issue>@<ts> or polecat/<name>-<ts>).
+func PolecatFromBranch(branch string) (string, bool) {
+	rest, ok := strings.CutPrefix(branch, "polecat/")
+	if !ok || rest == "" {
+		return "", false
+	}
+	if name, _, found := strings.Cut(rest, "/"); found {
+		return name, name != ""
+	}
+	if i := strings.LastIndex(rest, "-"); i > 0 {
+		return rest[:i], true
+	}
+	return rest, true
+}
+
+// globMatch matches s against a glob pattern where * also matches "/".
+func globMatch(pattern, s string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == s
+	}
+	if ok, err := path.Match(pattern, s); err == nil && ok {
+		return true
+	}
+	if prefix, found := strings.CutSuffix(pattern, "*"); found && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(s, prefix)
+	}
+	return false
+}
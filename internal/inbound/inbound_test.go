@@ -0,0 +1,409 @@
+package inbound
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return data
+}
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		event   string
+		want    *RepoEvent
+	}{
+		{
+			fixture: "github_issues_labeled.json",
+			event:   "issues",
+			want: &RepoEvent{
+				Provider: ProviderGitHub, Kind: KindIssue, Action: "labeled", Repo: "acme/widgets", Number: 42,
+				Title: "Widget export drops the last row", Label: "gt", Labels: []string{"bug", "gt"},
+				URL: "https://github.com/acme/widgets/issues/42", Author: "octocat",
+			},
+		},
+		{
+			fixture: "github_pull_request_opened.json",
+			event:   "pull_request",
+			want: &RepoEvent{
+				Provider: ProviderGitHub, Kind: KindPullRequest, Action: "opened", Repo: "acme/widgets", Number: 57,
+				Title: "Add YAML export", Branch: "yaml-export", URL: "https://github.com/acme/widgets/pull/57",
+				Author: "contributor-sam", Labels: []string{},
+			},
+		},
+		{
+			fixture: "github_workflow_run_failure.json",
+			event:   "workflow_run",
+			want: &RepoEvent{
+				Provider: ProviderGitHub, Kind: KindCI, Action: "failed", Repo: "acme/widgets", Title: "CI",
+				Branch: "polecat/Toast/wd-abc@m3k9x2", Conclusion: "failure", Author: "gastown-bot",
+				URL: "https://github.com/acme/widgets/actions/runs/11873345021",
+			},
+		},
+		{
+			fixture: "github_ping.json",
+			event:   "ping",
+			want:    nil,
+		},
+		{
+			fixture: "gitlab_issue_labeled.json",
+			event:   "Issue Hook",
+			want: &RepoEvent{
+				Provider: ProviderGitLab, Kind: KindIssue, Action: "labeled", Repo: "acme/widgets", Number: 17,
+				Title: "Import fails on empty sheets", Label: "gt", Labels: []string{"bug", "gt"},
+				URL: "https://gitlab.example.com/acme/widgets/-/issues/17", Author: "jo",
+			},
+		},
+		{
+			fixture: "gitlab_merge_request_open.json",
+			event:   "Merge Request Hook",
+			want: &RepoEvent{
+				Provider: ProviderGitLab, Kind: KindPullRequest, Action: "opened", Repo: "acme/widgets", Number: 9,
+				Title: "Speed up sheet parsing", Branch: "fast-parse", Author: "sam", Labels: []string{},
+				URL: "https://gitlab.example.com/acme/widgets/-/merge_requests/9",
+			},
+		},
+		{
+			fixture: "gitlab_pipeline_failed.json",
+			event:   "Pipeline Hook",
+			want: &RepoEvent{
+				Provider: ProviderGitLab, Kind: KindCI, Action: "failed", Repo: "acme/widgets", Title: "Pipeline",
+				Branch: "polecat/Nux-m3k9zz", Conclusion: "failed", Author: "gastown-bot",
+				URL: "https://gitlab.example.com/acme/widgets/-/pipelines/388120",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			header := http.Header{}
+			var got *RepoEvent
+			var err error
+			if tt.want == nil || tt.want.Provider == ProviderGitHub {
+				header.Set(githubEventHeader, tt.event)
+				got, err = ParseGitHub(header, loadFixture(t, tt.fixture))
+			} else {
+				header.Set(gitlabEventHeader, tt.event)
+				got, err = ParseGitLab(header, loadFixture(t, tt.fixture))
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got == nil || tt.want == nil {
+				if got != tt.want {
+					t.Fatalf("got %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+			// Compare the fields rules act on; bodies and commits are long.
+			got.Body, got.Commit = "", ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := loadFixture(t, "github_issues_labeled.json")
+	header := http.Header{}
+	header.Set(githubSignatureHeader, githubSignature("s3cret", body))
+	if err := VerifyGitHub("s3cret", header, body); err != nil {
+		t.Errorf("VerifyGitHub: %v", err)
+	}
+	if err := VerifyGitHub("other", header, body); err == nil {
+		t.Error("VerifyGitHub accepted the wrong secret")
+	}
+	if err := VerifyGitHub("s3cret", header, append(body, ' ')); err == nil {
+		t.Error("VerifyGitHub accepted a tampered body")
+	}
+	if err := VerifyGitHub("", header, body); err == nil {
+		t.Error("VerifyGitHub accepted a delivery with no secret configured")
+	}
+
+	header = http.Header{}
+	header.Set(gitlabTokenHeader, "tok")
+	if err := VerifyGitLab("tok", header); err != nil {
+		t.Errorf("VerifyGitLab: %v", err)
+	}
+	if err := VerifyGitLab("other", header); err == nil {
+		t.Error("VerifyGitLab accepted the wrong token")
+	}
+}
+
+func TestPolecatFromBranch(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+		ok     bool
+	}{
+		{"polecat/Toast/gt-abc@m3k9x2", "Toast", true},
+		{"polecat/Nux-m3k9zz", "Nux", true},
+		{"polecat/Furiosa", "Furiosa", true},
+		{"feature/thing", "", false},
+		{"main", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := PolecatFromBranch(tt.branch)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("PolecatFromBranch(%q) = %q, %v; want %q, %v", tt.branch, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRepoFromGitURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://github.com/acme/widgets.git":        "acme/widgets",
+		"git@github.com:Acme/Widgets.git":            "acme/widgets",
+		"ssh://git@gitlab.example.com/acme/widgets":  "acme/widgets",
+		"https://gitlab.example.com/group/sub/proj/": "group/sub/proj",
+	} {
+		if got := repoFromGitURL(url); got != want {
+			t.Errorf("repoFromGitURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// fakeTown records actions instead of touching beads, gt and mail.
+type fakeTown struct {
+	labels  map[string]string // label → bead ID
+	created []string          // "rig:title"
+	slung   []string          // "formula bead target vars"
+	mailed  []string          // "to: subject"
+	logged  []string          // repo_activity actors
+	nextID  int
+}
+
+func newFakeTown() *fakeTown {
+	return &fakeTown{labels: make(map[string]string)}
+}
+
+func (f *fakeTown) FindBead(rig, label string) (string, error) {
+	return f.labels[rig+"|"+label], nil
+}
+
+func (f *fakeTown) CreateBead(rig string, opts beads.CreateOptions, labels []string) (string, error) {
+	f.nextID++
+	id := fmt.Sprintf("wd-%d", f.nextID)
+	for _, l := range labels {
+		f.labels[rig+"|"+l] = id
+	}
+	f.created = append(f.created, rig+":"+opts.Title)
+	return id, nil
+}
+
+func (f *fakeTown) Sling(formula, bead, target string, vars map[string]string) error {
+	f.slung = append(f.slung, fmt.Sprintf("%s %s %s %v", formula, bead, target, vars))
+	return nil
+}
+
+func (f *fakeTown) SendMail(from, to, subject, body string) error {
+	f.mailed = append(f.mailed, to+": "+subject)
+	return nil
+}
+
+func (f *fakeTown) LogActivity(actor string, payload map[string]interface{}) error {
+	f.logged = append(f.logged, actor)
+	return nil
+}
+
+// setupTown writes a rigs.json in which the widgets rig wraps acme/widgets,
+// and the default rules.
+func setupTown(t *testing.T) (string, *Config) {
+	t.Helper()
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigs := `{"version": 1, "rigs": {"widgets": {"git_url": "git@github.com:acme/widgets.git"}}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(rigs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		GitHubSecret: "s3cret",
+		GitLabToken:  "tok",
+		Rules: []*Rule{
+			{Name: "triage", On: "issue.labeled", Label: "gt", Action: ActionCreateBead},
+			{Name: "review", On: "pull_request.opened", Action: ActionSling},
+			{Name: "ci", On: "ci.failed", Branch: "polecat/*", Action: ActionMail},
+		},
+	}
+	if err := SaveConfig(townRoot, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return townRoot, cfg
+}
+
+func parseFixture(t *testing.T, provider, event, fixture string) *RepoEvent {
+	t.Helper()
+	header := http.Header{}
+	var e *RepoEvent
+	var err error
+	if provider == ProviderGitHub {
+		header.Set(githubEventHeader, event)
+		e, err = ParseGitHub(header, loadFixture(t, fixture))
+	} else {
+		header.Set(gitlabEventHeader, event)
+		e, err = ParseGitLab(header, loadFixture(t, fixture))
+	}
+	if err != nil || e == nil {
+		t.Fatalf("parsing %s: %v", fixture, err)
+	}
+	return e
+}
+
+func TestProcessor_Rules(t *testing.T) {
+	townRoot, cfg := setupTown(t)
+	town := newFakeTown()
+	proc := NewProcessor(townRoot, cfg, town)
+
+	// Labeled issue → bead in the rig wrapping the repo; repeats are deduped.
+	issue := parseFixture(t, ProviderGitHub, "issues", "github_issues_labeled.json")
+	results := proc.Process(issue)
+	if len(results) != 1 || results[0].Rig != "widgets" || results[0].Bead != "wd-1" || results[0].Error != "" {
+		t.Fatalf("issue results = %+v", results)
+	}
+	if want := "widgets:acme/widgets#42: Widget export drops the last row"; len(town.created) != 1 || town.created[0] != want {
+		t.Errorf("created = %v, want %q", town.created, want)
+	}
+	results = proc.Process(issue)
+	if len(town.created) != 1 || results[0].Skipped == "" {
+		t.Errorf("repeat created a second bead: %v (%+v)", town.created, results)
+	}
+
+	// New PR → tracking bead with mol-polecat-review-pr slung on it.
+	pr := parseFixture(t, ProviderGitHub, "pull_request", "github_pull_request_opened.json")
+	results = proc.Process(pr)
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("PR results = %+v", results)
+	}
+	wantSling := "mol-polecat-review-pr wd-2 widgets map[pr_url:https://github.com/acme/widgets/pull/57]"
+	if len(town.slung) != 1 || town.slung[0] != wantSling {
+		t.Errorf("slung = %v, want %q", town.slung, wantSling)
+	}
+
+	// CI failure on a polecat branch → mail to that polecat.
+	ci := parseFixture(t, ProviderGitHub, "workflow_run", "github_workflow_run_failure.json")
+	results = proc.Process(ci)
+	if len(results) != 1 || results[0].To != "widgets/Toast" {
+		t.Fatalf("CI results = %+v", results)
+	}
+	if want := "widgets/Toast: CI failed on polecat/Toast/wd-abc@m3k9x2"; len(town.mailed) != 1 || town.mailed[0] != want {
+		t.Errorf("mailed = %v, want %q", town.mailed, want)
+	}
+
+	// GitLab pipeline on a polecat branch; the repos map picks the rig.
+	cfg.Repos = map[string]string{"acme/widgets": "widgets-gl"}
+	results = proc.Process(parseFixture(t, ProviderGitLab, "Pipeline Hook", "gitlab_pipeline_failed.json"))
+	if len(results) != 1 || results[0].To != "widgets-gl/Nux" {
+		t.Errorf("pipeline results = %+v", results)
+	}
+
+	if len(town.logged) != 5 {
+		t.Errorf("logged %d repo_activity events, want 5: %v", len(town.logged), town.logged)
+	}
+}
+
+func TestProcessor_DryRunAndUnknownRig(t *testing.T) {
+	townRoot, cfg := setupTown(t)
+	town := newFakeTown()
+	proc := NewProcessor(townRoot, cfg, town)
+	proc.DryRun = true
+
+	results := proc.Process(parseFixture(t, ProviderGitLab, "Issue Hook", "gitlab_issue_labeled.json"))
+	if len(results) != 1 || results[0].Skipped == "" || len(town.created) != 0 || len(town.logged) != 0 {
+		t.Errorf("dry run acted: %+v, created %v, logged %v", results, town.created, town.logged)
+	}
+
+	e := parseFixture(t, ProviderGitHub, "issues", "github_issues_labeled.json")
+	e.Repo = "other/repo"
+	results = proc.Process(e)
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("unknown repo should fail to resolve a rig: %+v", results)
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	townRoot, _ := setupTown(t)
+	town := newFakeTown()
+	recv := NewReceiver(townRoot, town, t.Logf)
+	recv.async = false
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	post := func(provider, event, delivery string, body []byte, auth func(http.Header)) (int, receiveResponse) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/webhooks/"+provider, bytes.NewReader(body))
+		if provider == ProviderGitHub {
+			req.Header.Set(githubEventHeader, event)
+			req.Header.Set(githubDeliveryHeader, delivery)
+		} else {
+			req.Header.Set(gitlabEventHeader, event)
+			req.Header.Set(gitlabDeliveryHeader, delivery)
+		}
+		auth(req.Header)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		defer resp.Body.Close()
+		var out receiveResponse
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+	signed := func(body []byte) func(http.Header) {
+		return func(h http.Header) { h.Set(githubSignatureHeader, githubSignature("s3cret", body)) }
+	}
+
+	body := loadFixture(t, "github_issues_labeled.json")
+	if status, _ := post(ProviderGitHub, "issues", "d-1", body, func(h http.Header) {
+		h.Set(githubSignatureHeader, githubSignature("wrong", body))
+	}); status != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d, want 401", status)
+	}
+
+	status, out := post(ProviderGitHub, "issues", "d-1", body, signed(body))
+	if status != http.StatusAccepted || out.Status != "accepted" || out.Matched != 1 {
+		t.Errorf("issue delivery: status %d, %+v", status, out)
+	}
+	if len(town.created) != 1 {
+		t.Errorf("created = %v", town.created)
+	}
+	if _, out := post(ProviderGitHub, "issues", "d-1", body, signed(body)); out.Status != "duplicate" {
+		t.Errorf("redelivery status = %q, want duplicate", out.Status)
+	}
+
+	ping := loadFixture(t, "github_ping.json")
+	if _, out := post(ProviderGitHub, "ping", "d-2", ping, signed(ping)); out.Status != "ignored" {
+		t.Errorf("ping status = %q, want ignored", out.Status)
+	}
+
+	mr := loadFixture(t, "gitlab_merge_request_open.json")
+	status, out = post(ProviderGitLab, "Merge Request Hook", "d-3", mr, func(h http.Header) { h.Set(gitlabTokenHeader, "tok") })
+	if status != http.StatusAccepted || out.Matched != 1 || len(town.slung) != 1 {
+		t.Errorf("GitLab MR: status %d, %+v, slung %v", status, out, town.slung)
+	}
+}
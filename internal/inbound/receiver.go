@@ -0,0 +1,496 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+const (
+	// maxPayloadSize bounds a delivery body (GitHub caps payloads at 25 MB,
+	// but the events we handle are far smaller).
+	maxPayloadSize = 5 << 20
+
+	// dedupeWindow is how long delivery IDs are remembered to drop redeliveries.
+	dedupeWindow = time.Hour
+)
+
+// Town carries out rule actions. NewTown returns the real implementation;
+// tests substitute a recorder.
+type Town interface {
+	// FindBead returns the ID of a bead in the rig carrying label, or "".
+	FindBead(rig, label string) (string, error)
+	CreateBead(rig string, opts beads.CreateOptions, labels []string) (string, error)
+	Sling(formula, bead, target string, vars map[string]string) error
+	SendMail(from, to, subject, body string) error
+	// LogActivity records a repo_activity event in the town feed.
+	LogActivity(actor string, payload map[string]interface{}) error
+}
+
+// Result records what one matching rule did for an event.
+type Result struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Rig     string `json:"rig,omitempty"`
+	Bead    string `json:"bead,omitempty"`
+	To      string `json:"to,omitempty"`
+	Skipped string `json:"skipped,omitempty"` // Why nothing was done
+	Error   string `json:"error,omitempty"`
+}
+
+// Processor applies the configured rules to repo events.
+type Processor struct {
+	townRoot string
+	cfg      *Config
+	town     Town
+
+	// DryRun reports what rules would do without acting.
+	DryRun bool
+}
+
+// NewProcessor creates a processor for a town's rules.
+func NewProcessor(townRoot string, cfg *Config, town Town) *Processor {
+	return &Processor{townRoot: townRoot, cfg: cfg, town: town}
+}
+
+// Process runs every rule matching the event and logs a repo_activity event
+// when any matched.
+func (p *Processor) Process(e *RepoEvent) []Result {
+	var results []Result
+	for _, rule := range p.cfg.Rules {
+		if !rule.Matches(e) {
+			continue
+		}
+		res := Result{Rule: rule.label(), Action: rule.Action}
+		rig, err := p.cfg.ResolveRig(p.townRoot, rule, e)
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		res.Rig = rig
+		switch rule.Action {
+		case ActionCreateBead:
+			p.createBead(rule, e, &res)
+		case ActionSling:
+			p.sling(rule, e, &res)
+		case ActionMail:
+			p.mail(rule, e, &res)
+		}
+		results = append(results, res)
+	}
+
+	if len(results) > 0 && !p.DryRun {
+		_ = p.town.LogActivity(e.Provider+"/"+e.Author, activityPayload(e, results))
+	}
+	return results
+}
+
+func (p *Processor) createBead(rule *Rule, e *RepoEvent, res *Result) {
+	label := "upstream:" + e.Ref()
+	if p.existing(res, label) {
+		return
+	}
+	opts := beads.CreateOptions{
+		Title:       fmt.Sprintf("%s: %s", e.Ref(), e.Title),
+		Type:        ruleType(rule),
+		Priority:    rulePriority(rule),
+		Description: upstreamDescription(e),
+		Actor:       e.Provider,
+	}
+	if p.DryRun {
+		res.Skipped = "dry run: would create " + opts.Title
+		return
+	}
+	id, err := p.town.CreateBead(res.Rig, opts, []string{label, "source:" + e.Provider})
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	res.Bead = id
+}
+
+func (p *Processor) sling(rule *Rule, e *RepoEvent, res *Result) {
+	formula := rule.Formula
+	if formula == "" {
+		formula = DefaultReviewFormula
+	}
+	label := "upstream:" + e.Ref() + ":" + formula
+	if p.existing(res, label) {
+		return
+	}
+	title := fmt.Sprintf("%s %s: %s", formula, e.Ref(), e.Title)
+	vars := map[string]string{}
+	if e.Kind == KindPullRequest {
+		title = fmt.Sprintf("Review PR %s: %s", e.Ref(), e.Title)
+		vars["pr_url"] = e.URL
+	}
+	target := rule.Target
+	if target == "" {
+		target = res.Rig
+	}
+	res.To = target
+	if p.DryRun {
+		res.Skipped = fmt.Sprintf("dry run: would sling %s to %s", formula, target)
+		return
+	}
+
+	id, err := p.town.CreateBead(res.Rig, beads.CreateOptions{
+		Title:       title,
+		Type:        ruleType(rule),
+		Priority:    rulePriority(rule),
+		Description: upstreamDescription(e),
+		Actor:       e.Provider,
+	}, []string{label, "source:" + e.Provider})
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	res.Bead = id
+	if err := p.town.Sling(formula, id, target, vars); err != nil {
+		res.Error = err.Error()
+	}
+}
+
+func (p *Processor) mail(rule *Rule, e *RepoEvent, res *Result) {
+	to := rule.To
+	if to == "" {
+		name, ok := PolecatFromBranch(e.Branch)
+		if !ok {
+			res.Skipped = fmt.Sprintf("branch %q is not a polecat branch", e.Branch)
+			return
+		}
+		to = res.Rig + "/" + name
+	}
+	res.To = to
+	subject, body := mailContent(e)
+	if p.DryRun {
+		res.Skipped = "dry run: would mail " + subject
+		return
+	}
+	if err := p.town.SendMail(e.Provider, to, subject, body); err != nil {
+		res.Error = err.Error()
+	}
+}
+
+// existing records an already-created bead for label on res, reporting
+// whether there was one (so redelivered or repeated events don't duplicate
+// work).
+func (p *Processor) existing(res *Result, label string) bool {
+	id, err := p.town.FindBead(res.Rig, label)
+	if err != nil || id == "" {
+		return false
+	}
+	res.Bead = id
+	res.Skipped = "already tracked by " + id
+	return true
+}
+
+func ruleType(rule *Rule) string {
+	if rule.Type != "" {
+		return rule.Type
+	}
+	return "task"
+}
+
+func rulePriority(rule *Rule) int {
+	if rule.Priority != nil {
+		return *rule.Priority
+	}
+	return 2
+}
+
+func upstreamDescription(e *RepoEvent) string {
+	var sb strings.Builder
+	if e.Body != "" {
+		sb.WriteString(e.Body)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("## Upstream\n")
+	fmt.Fprintf(&sb, "- %s: %s\n", e.Provider, e.Ref())
+	if e.URL != "" {
+		fmt.Fprintf(&sb, "- URL: %s\n", e.URL)
+	}
+	if e.Author != "" {
+		fmt.Fprintf(&sb, "- Author: %s\n", e.Author)
+	}
+	if e.Branch != "" {
+		fmt.Fprintf(&sb, "- Branch: %s\n", e.Branch)
+	}
+	return sb.String()
+}
+
+func mailContent(e *RepoEvent) (subject, body string) {
+	switch e.Kind {
+	case KindCI:
+		subject = fmt.Sprintf("CI %s on %s", e.Action, e.Branch)
+		body = fmt.Sprintf("%s %s for %s on branch %s", e.Title, e.Conclusion, e.Repo, e.Branch)
+		if e.Commit != "" {
+			body += fmt.Sprintf(" at %.12s", e.Commit)
+		}
+		body += ".\n\n" + e.URL
+		if e.Action == "failed" {
+			body += "\n\nInvestigate the failure, fix it and push again."
+		}
+	case KindComment:
+		subject = fmt.Sprintf("Review comment on %s", e.Ref())
+		body = fmt.Sprintf("%s commented on %s (%s):\n\n%s\n\n%s", e.Author, e.Ref(), e.Branch, e.Body, e.URL)
+	default:
+		subject = fmt.Sprintf("%s %s: %s", strings.ReplaceAll(e.Kind, "_", " "), e.Action, e.Ref())
+		body = fmt.Sprintf("%s\n\n%s", e.Title, e.URL)
+	}
+	return subject, body
+}
+
+func activityPayload(e *RepoEvent, results []Result) map[string]interface{} {
+	payload := map[string]interface{}{
+		"provider": e.Provider,
+		"kind":     e.Kind,
+		"action":   e.Action,
+		"repo":     e.Repo,
+		"url":      e.URL,
+	}
+	if e.Number != 0 {
+		payload["number"] = e.Number
+	}
+	if e.Branch != "" {
+		payload["branch"] = e.Branch
+	}
+	var rules []string
+	for _, r := range results {
+		rules = append(rules, r.Rule)
+		if r.Rig != "" {
+			payload["rig"] = r.Rig
+		}
+	}
+	payload["rules"] = rules
+	return payload
+}
+
+// Receiver is the HTTP endpoint for GitHub and GitLab webhooks, mounted at
+// /webhooks/github and /webhooks/gitlab. The configuration is reloaded per
+// delivery, so rule edits apply without a restart.
+type Receiver struct {
+	townRoot string
+	town     Town
+	logf     func(format string, args ...interface{})
+
+	// async processes matched events after responding, so slow actions
+	// don't exceed the providers' delivery timeouts.
+	async bool
+
+	mu   sync.Mutex
+	seen map[string]time.Time // Delivery ID → when received
+}
+
+// NewReceiver creates a receiver acting on the town.
+func NewReceiver(townRoot string, town Town, logf func(format string, args ...interface{})) *Receiver {
+	return &Receiver{townRoot: townRoot, town: town, logf: logf, async: true, seen: make(map[string]time.Time)}
+}
+
+// receiveResponse is the receiver's reply to a delivery.
+type receiveResponse struct {
+	Status  string     `json:"status"` // accepted, ignored, duplicate
+	Event   *RepoEvent `json:"event,omitempty"`
+	Matched int        `json:"matched"`
+}
+
+// ServeHTTP verifies, parses and processes a delivery.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	provider := path.Base(strings.TrimSuffix(req.URL.Path, "/"))
+	if provider != ProviderGitHub && provider != ProviderGitLab {
+		http.NotFound(w, req)
+		return
+	}
+
+	cfg, err := LoadConfig(r.townRoot)
+	if err != nil {
+		r.logf("inbound: %v", err)
+		http.Error(w, "receiver misconfigured", http.StatusInternalServerError)
+		return
+	}
+	if cfg == nil {
+		http.NotFound(w, req) // Receiver not enabled
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize+1))
+	if err != nil || len(body) > maxPayloadSize {
+		http.Error(w, "payload too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var e *RepoEvent
+	if provider == ProviderGitHub {
+		if err = VerifyGitHub(cfg.GitHubSecret, req.Header, body); err == nil {
+			e, err = ParseGitHub(req.Header, body)
+		} else {
+			err = &authError{err}
+		}
+	} else {
+		if err = VerifyGitLab(cfg.GitLabToken, req.Header); err == nil {
+			e, err = ParseGitLab(req.Header, body)
+		} else {
+			err = &authError{err}
+		}
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if _, ok := err.(*authError); ok {
+			status = http.StatusUnauthorized
+		}
+		r.logf("inbound: rejected %s delivery: %v", provider, err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	resp := receiveResponse{Status: "ignored", Event: e}
+	switch {
+	case e == nil:
+	case r.duplicate(e.Delivery):
+		resp.Status = "duplicate"
+	default:
+		resp.Status = "accepted"
+		for _, rule := range cfg.Rules {
+			if rule.Matches(e) {
+				resp.Matched++
+			}
+		}
+		if resp.Matched > 0 {
+			proc := NewProcessor(r.townRoot, cfg, r.town)
+			if r.async {
+				go r.process(proc, e)
+			} else {
+				r.process(proc, e)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (r *Receiver) process(proc *Processor, e *RepoEvent) {
+	for _, res := range proc.Process(e) {
+		switch {
+		case res.Error != "":
+			r.logf("inbound: %s %s.%s rule %s: %s", e.Ref(), e.Kind, e.Action, res.Rule, res.Error)
+		case res.Skipped != "":
+			r.logf("inbound: %s %s.%s rule %s: skipped: %s", e.Ref(), e.Kind, e.Action, res.Rule, res.Skipped)
+		default:
+			r.logf("inbound: %s %s.%s rule %s: %s done (bead %s, to %s)", e.Ref(), e.Kind, e.Action, res.Rule, res.Action, res.Bead, res.To)
+		}
+	}
+}
+
+// duplicate reports whether a delivery ID was seen within the dedupe
+// window, recording it otherwise.
+func (r *Receiver) duplicate(delivery string) bool {
+	if delivery == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, at := range r.seen {
+		if now.Sub(at) > dedupeWindow {
+			delete(r.seen, id)
+		}
+	}
+	if _, ok := r.seen[delivery]; ok {
+		return true
+	}
+	r.seen[delivery] = now
+	return false
+}
+
+// authError marks a verification failure.
+type authError struct{ err error }
+
+func (e *authError) Error() string { return e.err.Error() }
+
+// townActions is the Town backed by beads, gt sling and mail.
+type townActions struct {
+	townRoot string
+}
+
+// NewTown returns a Town that acts on the town at townRoot.
+func NewTown(townRoot string) Town {
+	return &townActions{townRoot: townRoot}
+}
+
+func (t *townActions) FindBead(rig, label string) (string, error) {
+	issues, err := beads.New(filepath.Join(t.townRoot, rig)).List(beads.ListOptions{
+		Label:    label,
+		Status:   "all",
+		Priority: -1,
+		Limit:    1,
+	})
+	if err != nil || len(issues) == 0 {
+		return "", err
+	}
+	return issues[0].ID, nil
+}
+
+func (t *townActions) CreateBead(rig string, opts beads.CreateOptions, labels []string) (string, error) {
+	b := beads.New(filepath.Join(t.townRoot, rig))
+	issue, err := b.Create(opts)
+	if err != nil {
+		return "", fmt.Errorf("creating bead: %w", err)
+	}
+	if len(labels) > 0 {
+		if err := b.Update(issue.ID, beads.UpdateOptions{AddLabels: labels}); err != nil {
+			return issue.ID, fmt.Errorf("labeling %s: %w", issue.ID, err)
+		}
+	}
+	return issue.ID, nil
+}
+
+func (t *townActions) Sling(formula, bead, target string, vars map[string]string) error {
+	args := []string{"sling", formula, "--on", bead, target}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--var", k+"="+vars[k])
+	}
+	cmd := exec.Command("gt", args...) //nolint:gosec // G204: formula and target come from town config
+	cmd.Dir = t.townRoot
+	cmd.Env = os.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gt sling %s: %s", formula, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (t *townActions) SendMail(from, to, subject, body string) error {
+	router := mail.NewRouterWithTownRoot(t.townRoot, t.townRoot)
+	if err := router.Send(mail.NewMessage(from, to, subject, body)); err != nil {
+		return fmt.Errorf("mailing %s: %w", to, err)
+	}
+	return nil
+}
+
+func (t *townActions) LogActivity(actor string, payload map[string]interface{}) error {
+	return events.LogFeed(events.TypeRepoActivity, actor, payload)
+}
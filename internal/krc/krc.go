@@ -45,6 +45,15 @@ type Config struct {
 	// MinRetainCount keeps at least N events even if expired (for debugging).
 	// Default: 100
 	MinRetainCount int `json:"min_retain_count"`
+
+	// Archive moves expired events into compressed archive segments
+	// (searchable with gt events query --include-archive) instead of
+	// deleting them. Applies to the events log, not the curated feed.
+	Archive bool `json:"archive,omitempty"`
+
+	// ArchiveRetention is how long archive segments are kept after their
+	// newest event. Zero keeps them forever.
+	ArchiveRetention time.Duration `json:"archive_retention,omitempty"`
 }
 
 // DefaultConfig returns the default KRC configuration.
@@ -171,6 +180,11 @@ type PruneResult struct {
 	BytesAfter      int64          `json:"bytes_after"`
 	PrunedByType    map[string]int `json:"pruned_by_type"`
 	Duration        time.Duration  `json:"duration"`
+
+	// EventsArchived counts pruned events moved to the archive.
+	EventsArchived int `json:"events_archived,omitempty"`
+	// ArchiveSegmentsRemoved counts archive segments past ArchiveRetention.
+	ArchiveSegmentsRemoved int `json:"archive_segments_removed,omitempty"`
 }
 
 // Pruner handles the pruning of expired events.
@@ -195,8 +209,8 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 		PrunedByType: make(map[string]int),
 	}
 
-	// Prune events file
-	eventsResult, err := p.pruneFile(filepath.Join(p.townRoot, events.EventsFile))
+	// Prune events file (archiving expired events when configured)
+	eventsResult, err := p.pruneFile(filepath.Join(p.townRoot, events.EventsFile), p.config.Archive)
 	if err != nil {
 		return nil, fmt.Errorf("pruning events: %w", err)
 	}
@@ -205,12 +219,13 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 	result.EventsRetained += eventsResult.EventsRetained
 	result.BytesBefore += eventsResult.BytesBefore
 	result.BytesAfter += eventsResult.BytesAfter
+	result.EventsArchived += eventsResult.EventsArchived
 	for k, v := range eventsResult.PrunedByType {
 		result.PrunedByType[k] += v
 	}
 
 	// Prune feed file
-	feedResult, err := p.pruneFile(filepath.Join(p.townRoot, ".feed.jsonl"))
+	feedResult, err := p.pruneFile(filepath.Join(p.townRoot, ".feed.jsonl"), false)
 	if err != nil {
 		return nil, fmt.Errorf("pruning feed: %w", err)
 	}
@@ -223,12 +238,22 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 		result.PrunedByType[k] += v
 	}
 
+	// Drop archive segments past retention
+	if p.config.ArchiveRetention > 0 {
+		removed, err := events.PruneArchive(p.townRoot, time.Now().Add(-p.config.ArchiveRetention))
+		if err != nil {
+			return nil, fmt.Errorf("pruning archive: %w", err)
+		}
+		result.ArchiveSegmentsRemoved = removed
+	}
+
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
-// pruneFile prunes a single JSONL file.
-func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
+// pruneFile prunes a single JSONL file. With archive set, expired events are
+// appended to the events archive before the file is replaced.
+func (p *Pruner) pruneFile(filePath string, archive bool) (result *PruneResult, err error) {
 	result = &PruneResult{
 		PrunedByType: make(map[string]int),
 	}
@@ -284,7 +309,7 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 	// Increase buffer size for potentially long lines
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
-	var retained []string
+	var retained, expired []string
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -317,6 +342,9 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 		if now.Sub(ts) > ttl {
 			result.EventsPruned++
 			result.PrunedByType[event.Type]++
+			if archive {
+				expired = append(expired, line)
+			}
 		} else {
 			retained = append(retained, line)
 		}
@@ -360,6 +388,14 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 	}
 	srcClosed = true
 
+	// Archive before replacing, so a failure loses nothing
+	if len(expired) > 0 {
+		if err := events.Archive(p.townRoot, expired); err != nil {
+			return nil, fmt.Errorf("archiving expired events: %w", err)
+		}
+		result.EventsArchived = len(expired)
+	}
+
 	// Atomic replace
 	if err := os.Rename(tmpPath, filePath); err != nil {
 		return nil, fmt.Errorf("replacing file: %w", err)
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -215,6 +217,55 @@ func TestPruner_Prune(t *testing.T) {
 	}
 }
 
+func TestPruner_PruneArchivesExpiredEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsPath := filepath.Join(tmpDir, events.EventsFile)
+	now := time.Now().UTC()
+
+	f, err := os.Create(eventsPath)
+	if err != nil {
+		t.Fatalf("failed to create events file: %v", err)
+	}
+	for _, ts := range []time.Time{now.Add(-10 * 24 * time.Hour), now.Add(-8 * 24 * time.Hour), now.Add(-time.Hour)} {
+		data, _ := json.Marshal(map[string]interface{}{
+			"ts":    ts.Format(time.RFC3339),
+			"type":  "test_event",
+			"actor": "actor1",
+		})
+		f.Write(data)
+		f.WriteString("\n")
+	}
+	f.Close()
+
+	config := DefaultConfig()
+	config.Archive = true
+	result, err := NewPruner(tmpDir, config).Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.EventsPruned != 2 || result.EventsArchived != 2 {
+		t.Errorf("expected 2 events pruned and archived, got %d pruned, %d archived", result.EventsPruned, result.EventsArchived)
+	}
+
+	archived, err := events.Find(tmpDir, &events.Query{IncludeArchive: true})
+	if err != nil {
+		t.Fatalf("querying events: %v", err)
+	}
+	if len(archived) != 3 {
+		t.Errorf("expected 3 events across archive and live log, got %d", len(archived))
+	}
+
+	// Retention drops the archive segments
+	config.ArchiveRetention = 24 * time.Hour
+	result, err = NewPruner(tmpDir, config).Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.ArchiveSegmentsRemoved != 2 {
+		t.Errorf("expected 2 archive segments removed, got %d", result.ArchiveSegmentsRemoved)
+	}
+}
+
 func TestGetStats(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "krc-test-*")
 	if err != nil {
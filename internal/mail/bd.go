@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 const (
@@ -43,6 +45,16 @@ func (e *bdError) ContainsError(substr string) bool {
 	return strings.Contains(e.Stderr, substr)
 }
 
+// runBdRead runs a read-only bd query for the inbox, answering it from the
+// Dolt SQL server when that serves beadsDir (see beads.ReadSQL) and
+// otherwise running bd like runBdCommand.
+func runBdRead(ctx context.Context, args []string, workDir, beadsDir string) ([]byte, error) {
+	if out, ok := beads.ReadSQL(beadsDir, args...); ok {
+		return out, nil
+	}
+	return runBdCommand(ctx, args, workDir, beadsDir)
+}
+
 // runBdCommand executes a bd command with a context timeout and proper environment setup.
 // ctx controls the deadline/timeout for the subprocess.
 // workDir is the directory to run the command in.
@@ -143,7 +143,7 @@ func (m *Mailbox) listFromDir(beadsDir string) ([]*Message, error) {
 
 	ctx, cancel := bdReadCtx()
 	defer cancel()
-	stdout, err := runBdCommand(ctx, args, m.workDir, beadsDir)
+	stdout, err := runBdRead(ctx, args, m.workDir, beadsDir)
 	if err != nil {
 		return nil, err
 	}
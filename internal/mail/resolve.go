@@ -1,5 +1,6 @@
 // Package mail provides address resolution for beads-native messaging.
-// This module implements the resolution order:
+// This module implements the resolution order (federated town@... addresses
+// pass through to the router first):
 // 1. Contains '/' → agent address or pattern
 // 2. Starts with '@' → special pattern (@town, @crew, @rig/X, @role/X)
 // 3. Otherwise → lookup by name: group → queue → channel
@@ -13,6 +14,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
 )
 
 // RecipientType indicates the type of resolved recipient.
@@ -77,6 +79,11 @@ func (r *Resolver) resolveWithVisited(address string, visited map[string]bool) (
 		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
 	}
 
+	// Federated address (town@host:rig/agent) - delivered by the router
+	if federation.IsAddress(address) {
+		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
+	}
+
 	// 2. Contains '/' → agent address or pattern
 	if strings.Contains(address, "/") {
 		return r.resolveAgentAddress(address)
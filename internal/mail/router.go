@@ -1,6 +1,7 @@
 package mail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,7 +10,9 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/nudge"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -747,10 +750,22 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports fan-out for:
 // - Mailing lists (list:name) - fans out to all list members
 // - @group addresses - resolves and fans out to matching agents
+// Supports federated delivery for town@host:rig/agent addresses.
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
 func (r *Router) Send(msg *Message) error {
+	// Redact secret values before the message reaches beads or any recipient.
+	if red := secrets.LoadRedactor(r.townRoot); red != nil {
+		msg.Subject = red.Redact(msg.Subject)
+		msg.Body = red.Redact(msg.Body)
+	}
+
+	// Check for federated address - deliver through the peer town
+	if federation.IsAddress(msg.To) {
+		return r.sendToFederated(msg)
+	}
+
 	// Check for mailing list address
 	if isListAddress(msg.To) {
 		return r.sendToList(msg)
@@ -780,6 +795,30 @@ func (r *Router) Send(msg *Message) error {
 	return r.sendToSingle(msg)
 }
 
+// sendToFederated delivers a message to an agent in another town. The
+// receiving town records it as from <this town>@<sender>, so replies route
+// back the same way.
+func (r *Router) sendToFederated(msg *Message) error {
+	if r.townRoot == "" {
+		return fmt.Errorf("sending to %s: not in a Gas Town workspace", msg.To)
+	}
+	env := &federation.Envelope{
+		Kind:   federation.KindMail,
+		Sender: msg.From,
+		Mail: &federation.Mail{
+			Subject:  msg.Subject,
+			Body:     msg.Body,
+			Priority: string(msg.Priority),
+			Type:     string(msg.Type),
+			ThreadID: msg.ThreadID,
+		},
+	}
+	if _, err := federation.SendTo(context.Background(), r.townRoot, msg.To, env); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return nil
+}
+
 // sendToGroup resolves a @group address and sends individual messages to each member.
 func (r *Router) sendToGroup(msg *Message) error {
 	group := parseGroupAddress(msg.To)
@@ -1332,6 +1371,9 @@ func (r *Router) notifyRecipient(msg *Message) error {
 	}
 
 	sessionIDs := addressToSessionIDs(msg.To)
+	if id := r.customRoleSessionID(msg.To); id != "" {
+		sessionIDs = []string{id}
+	}
 	if len(sessionIDs) == 0 {
 		return nil // Unable to determine session ID
 	}
@@ -1420,6 +1462,30 @@ func addressToAgentBeadID(address string) string {
 	}
 }
 
+// customRoleSessionID returns the tmux session of the custom role agent at
+// address ("<rig>/<role>" or "<role>/"), or "" if address is not one.
+func (r *Router) customRoleSessionID(address string) string {
+	if r.townRoot == "" {
+		return ""
+	}
+	rig, role, ok := strings.Cut(strings.TrimSuffix(address, "/"), "/")
+	if !ok {
+		rig, role = "", rig
+	}
+	if !config.IsCustomRole(r.townRoot, role) {
+		return ""
+	}
+	rigPath := ""
+	if rig != "" {
+		rigPath = filepath.Join(r.townRoot, rig)
+	}
+	def, err := config.LoadRoleDefinition(r.townRoot, rigPath, role)
+	if err != nil || def.IsRigScoped() != (rig != "") {
+		return ""
+	}
+	return def.SessionName(rig, session.PrefixFor(rig))
+}
+
 // addressToSessionIDs converts a mail address to possible tmux session IDs.
 // Returns multiple candidates since the canonical address format (rig/name)
 // doesn't distinguish between crew workers (gt-rig-crew-name) and polecats
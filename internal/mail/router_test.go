@@ -146,6 +146,42 @@ func TestAddressToSessionIDs(t *testing.T) {
 	}
 }
 
+func TestRouter_CustomRoleSessionID(t *testing.T) {
+	reg := session.NewPrefixRegistry()
+	reg.Register("gt", "gastown")
+	old := session.DefaultRegistry()
+	session.SetDefaultRegistry(reg)
+	defer session.SetDefaultRegistry(old)
+
+	townRoot := t.TempDir()
+	rolesDir := filepath.Join(townRoot, "roles")
+	if err := os.MkdirAll(rolesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rolesDir, "reviewer.toml"), []byte(`scope = "rig"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rolesDir, "auditor.toml"), []byte(`scope = "town"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	tests := map[string]string{
+		"gastown/reviewer": "gt-reviewer",
+		"auditor/":         "hq-auditor",
+		"auditor":          "hq-auditor",
+		"reviewer/":        "", // Rig-scoped role needs a rig
+		"gastown/auditor":  "", // Town-scoped role has no rig
+		"gastown/witness":  "",
+		"gastown/Toast":    "",
+	}
+	for address, want := range tests {
+		if got := r.customRoleSessionID(address); got != want {
+			t.Errorf("customRoleSessionID(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
 func TestAddressToSessionID(t *testing.T) {
 	// Set up prefix registry for test
 	reg := session.NewPrefixRegistry()
@@ -17,11 +17,14 @@ import (
 	"github.com/gofrs/flock"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -95,6 +98,7 @@ var (
 	ErrHasChanges         = errors.New("polecat has uncommitted changes")
 	ErrHasUncommittedWork = errors.New("polecat has uncommitted work")
 	ErrShellInWorktree    = errors.New("shell working directory is inside polecat worktree")
+	ErrUnsnapshottedWork  = errors.New("polecat has work that could not be snapshotted")
 	ErrDoltUnhealthy      = errors.New("dolt health check failed")
 	ErrDoltAtCapacity     = errors.New("dolt server at connection capacity")
 )
@@ -665,18 +669,75 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 			startPoint, m.rig.Path, filepath.Join(m.rig.Path, ".repo.git"))
 	}
 
+	// Claim a pre-created worktree from the rig's warm pool when one is
+	// configured. Warm entries track the default branch, so only spawns
+	// without an explicit base branch use them.
+	warm := false
+	if opts.BaseBranch == "" && m.warmPoolConfig() != nil {
+		if err := m.claimWarmWorktree(repoGit, clonePath, branchName, startPoint); err == nil {
+			warm = true
+		} else if !errors.Is(err, ErrWarmPoolEmpty) {
+			fmt.Printf("Warning: could not use warm worktree: %v\n", err)
+			m.recordWarmMiss()
+		}
+	}
+
 	// Always create fresh branch - unique name guarantees no collision
 	// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
 	// Worktree goes in polecats/<name>/<rigname>/ for LLM ergonomics
-	if err := repoGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
-		cleanupOnError()
-		return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
+	if !warm {
+		if err := repoGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
+			cleanupOnError()
+			return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
+		}
 	}
 
 	// NOTE: No per-directory CLAUDE.md or AGENTS.md is created here.
 	// Only ~/gt/CLAUDE.md (town-root identity anchor) exists on disk.
 	// Full context is injected ephemerally via SessionStart hook (gt prime).
 
+	m.provisionWorktree(clonePath, !warm)
+
+	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
+	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
+
+	// Create or reopen agent bead for ZFC compliance (self-report state).
+	// State starts as "spawning" - will be updated to "working" when Claude starts.
+	// HookBead is set atomically at creation time if provided (avoids cross-beads routing issues).
+	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
+	// Retries with backoff — a polecat without an agent bead is untrackable (gt-94llt7).
+	agentID := m.agentBeadID(name)
+	if err = m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
+		RoleType:   "polecat",
+		Rig:        m.rig.Name,
+		AgentState: "spawning",
+		HookBead:   opts.HookBead, // Set atomically at spawn time
+	}); err != nil {
+		// Hard fail — an untrackable polecat is worse than no polecat
+		cleanupOnError()
+		return nil, fmt.Errorf("agent bead required for polecat tracking: %w", err)
+	}
+
+	// Return polecat with working state (transient model: polecats are spawned with work)
+	// State is derived from beads, not stored in state.json
+	now := time.Now()
+	polecat := &Polecat{
+		Name:      name,
+		Rig:       m.rig.Name,
+		State:     StateWorking, // Transient model: polecat spawns with work
+		ClonePath: clonePath,
+		Branch:    branchName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return polecat, nil
+}
+
+// provisionWorktree sets up a freshly added polecat worktree: shared beads,
+// PRIME.md, overlay files, secrets, .gitignore patterns, runtime settings
+// and (optionally) the rig's setup hooks. Failures are non-fatal.
+func (m *Manager) provisionWorktree(clonePath string, runSetupHooks bool) {
 	// Set up shared beads: polecat uses rig's .beads via redirect file.
 	// This eliminates git sync overhead - all polecats share one database.
 	if err := m.setupSharedBeads(clonePath); err != nil {
@@ -700,6 +761,13 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file-mode secrets scoped to polecats (e.g., .env).
+	// Recorded in the worktree manifest so nuke can scrub them.
+	if _, err := secrets.Materialize(filepath.Dir(m.rig.Path), m.rig.Path, "polecat", clonePath); err != nil {
+		// Non-fatal - log warning but continue
+		fmt.Printf("Warning: could not materialize secrets: %v\n", err)
+	}
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(clonePath); err != nil {
 		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
@@ -717,45 +785,45 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 	// Run setup hooks from .runtime/setup-hooks/.
 	// These hooks can inject local git config, copy secrets, or perform other setup tasks.
-	if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
-		// Non-fatal - log warning but continue
-		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+	// Warm worktrees already ran them when the pool created them.
+	if runSetupHooks {
+		if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
+			// Non-fatal - log warning but continue
+			fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+		}
 	}
+}
 
-	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
-	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
-
-	// Create or reopen agent bead for ZFC compliance (self-report state).
-	// State starts as "spawning" - will be updated to "working" when Claude starts.
-	// HookBead is set atomically at creation time if provided (avoids cross-beads routing issues).
-	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
-	// Retries with backoff — a polecat without an agent bead is untrackable (gt-94llt7).
-	agentID := m.agentBeadID(name)
-	if err = m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
-		RoleType:   "polecat",
-		Rig:        m.rig.Name,
-		AgentState: "spawning",
-		HookBead:   opts.HookBead, // Set atomically at spawn time
-	}); err != nil {
-		// Hard fail — an untrackable polecat is worse than no polecat
-		cleanupOnError()
-		return nil, fmt.Errorf("agent bead required for polecat tracking: %w", err)
+// Reattach recreates a polecat's worktree on an existing branch of the rig
+// repository, e.g. after the rig has moved to another machine. Unlike Add,
+// it neither allocates a branch nor touches the agent bead, which travels
+// with the rig's database. Returns the worktree path.
+func (m *Manager) Reattach(name, branch string) (string, error) {
+	fl, err := m.lockPolecat(name)
+	if err != nil {
+		return "", err
 	}
+	defer func() { _ = fl.Unlock() }()
 
-	// Return polecat with working state (transient model: polecats are spawned with work)
-	// State is derived from beads, not stored in state.json
-	now := time.Now()
-	polecat := &Polecat{
-		Name:      name,
-		Rig:       m.rig.Name,
-		State:     StateWorking, // Transient model: polecat spawns with work
-		ClonePath: clonePath,
-		Branch:    branchName,
-		CreatedAt: now,
-		UpdatedAt: now,
+	if m.exists(name) {
+		return "", ErrPolecatExists
+	}
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return "", fmt.Errorf("finding repo base: %w", err)
 	}
 
-	return polecat, nil
+	polecatDir := m.polecatDir(name)
+	clonePath := filepath.Join(polecatDir, m.rig.Name)
+	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+		return "", fmt.Errorf("creating polecat dir: %w", err)
+	}
+	if err := repoGit.WorktreeAddExisting(clonePath, branch); err != nil {
+		_ = os.RemoveAll(polecatDir)
+		return "", fmt.Errorf("creating worktree on %s: %w", branch, err)
+	}
+	m.provisionWorktree(clonePath, true)
+	return clonePath, nil
 }
 
 // Remove deletes a polecat worktree.
@@ -770,6 +838,11 @@ func (m *Manager) Remove(name string, force bool) error {
 // nuclear=true: bypass ALL safety checks including stashes and unpushed commits
 // selfNuke=true: bypass cwd-in-worktree check (for polecat deleting its own worktree)
 //
+// No mode deletes unsaved work: uncommitted changes and unpushed commits are
+// first saved to a WIP snapshot ref (see SnapshotWIP), and removal is refused
+// if that fails. A worktree too broken to inspect is refused too, except in
+// nuclear mode, which warns and removes it.
+//
 // ZFC #10: Uses cleanup_status from agent bead if available (polecat self-report),
 // falls back to git check for backward compatibility.
 func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool) error {
@@ -832,6 +905,20 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 		}
 	}
 
+	// Whatever the checks allowed through, save it before it's deleted.
+	if _, err := m.SnapshotWIP(name); err != nil {
+		switch {
+		case errors.Is(err, checkpoint.ErrUninspectable) && nuclear:
+			// Nuclear removal exists for corrupted worktrees; there is
+			// no readable work to save.
+			fmt.Printf("Warning: could not check %s for unsaved work, removing anyway: %v\n", name, err)
+		case errors.Is(err, checkpoint.ErrUninspectable):
+			return fmt.Errorf("cannot check polecat %s for unsaved work: %v\nUse nuclear removal (gt polecat nuke) if the worktree is corrupted", name, err)
+		default:
+			return fmt.Errorf("%w: %s: %v\nResolve the snapshot failure, or save the work by hand, before removing", ErrUnsnapshottedWork, name, err)
+		}
+	}
+
 	// Reset agent bead FIRST, before any filesystem operations.
 	// This prevents a race where a concurrent sling allocates the same name,
 	// sets hook_bead, and then has it cleared by this cleanup. By resetting
@@ -872,6 +959,16 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 		}
 	}
 
+	// Scrub materialized secrets before touching the worktree so a partial
+	// removal cannot leave credentials on disk.
+	if err := secrets.Scrub(clonePath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if err := secrets.ScrubEnvFile(secrets.EnvFilePath(polecatDir)); err != nil {
+		fmt.Printf("Warning: could not scrub secrets env file: %v\n", err)
+	}
+	_ = sandbox.Release(polecatDir)
+
 	// Get repo base to remove the worktree properly
 	repoGit, err := m.repoBase()
 	if err != nil {
@@ -1172,6 +1269,11 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file-mode secrets scoped to polecats
+	if _, err := secrets.Materialize(filepath.Dir(m.rig.Path), m.rig.Path, "polecat", newClonePath); err != nil {
+		fmt.Printf("Warning: could not materialize secrets: %v\n", err)
+	}
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(newClonePath); err != nil {
 		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
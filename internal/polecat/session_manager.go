@@ -15,9 +15,12 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/container"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -42,6 +45,10 @@ var (
 type SessionManager struct {
 	tmux *tmux.Tmux
 	rig  *rig.Rig
+
+	// containers overrides the container driver selected by rig settings.
+	// Used by tests to inject a fake driver.
+	containers container.Driver
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
@@ -292,6 +299,39 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	}
 	command = config.PrependEnv(command, envVarsToInject)
 
+	// Inject env-mode secrets scoped to polecats. Values are written to a
+	// 0600 file outside the worktree and sourced, so they never appear in
+	// the tmux command line or process listing.
+	if secs, err := secrets.Resolve(townRoot, m.rig.Path, "polecat"); err != nil {
+		fmt.Printf("Warning: could not resolve secrets: %v\n", err)
+	} else {
+		envFile := secrets.EnvFilePath(m.polecatDir(polecat))
+		if wrote, err := secrets.WriteEnvFile(envFile, secs); err != nil {
+			fmt.Printf("Warning: could not write secrets env file: %v\n", err)
+		} else if wrote {
+			command = secrets.SourceEnvFile(command, envFile)
+		}
+	}
+
+	// In container mode the agent runs inside the rig's container, attached
+	// to the tmux pane. Otherwise apply the rig's host sandbox, if any.
+	if execCfg := m.executionConfig(); execCfg.UsesContainer() {
+		command, err = m.containerCommand(execCfg.Container, polecat, sessionID, workDir, townRoot, opts.RuntimeConfigDir, command)
+		if err != nil {
+			return err
+		}
+	} else {
+		command, err = session.ApplySandbox(sandbox.Paths{
+			TownRoot: townRoot,
+			RigPath:  m.rig.Path,
+			AgentDir: m.polecatDir(polecat),
+			WorkDir:  workDir,
+		}, "polecat", sessionID, command)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
@@ -404,6 +444,63 @@ func (m *SessionManager) isSessionStale(sessionID string) bool {
 	return isSessionProcessDead(m.tmux, sessionID)
 }
 
+// executionConfig returns the rig's execution settings (nil means host mode).
+func (m *SessionManager) executionConfig() *config.ExecutionConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil {
+		return nil
+	}
+	return settings.Execution
+}
+
+// containerDriver returns the injected driver or the one the config selects.
+func (m *SessionManager) containerDriver(cfg *config.ContainerConfig) (container.Driver, error) {
+	if m.containers != nil {
+		return m.containers, nil
+	}
+	return container.NewDriver(cfg.Driver)
+}
+
+// containerCommand rewrites command to run inside the rig's container,
+// building the image first if the rig provides a Dockerfile. The rig's
+// polecat sandbox profile, if any, is applied as container limits.
+func (m *SessionManager) containerCommand(cfg *config.ContainerConfig, polecat, sessionID, workDir, townRoot, runtimeConfigDir, command string) (string, error) {
+	d, err := m.containerDriver(cfg)
+	if err != nil {
+		return "", err
+	}
+	image, err := container.EnsureImage(d, cfg, m.rig.Name, m.rig.Path)
+	if err != nil {
+		return "", fmt.Errorf("preparing container image: %w", err)
+	}
+
+	spec := container.Spec(cfg, image, sessionID, container.Paths{
+		TownRoot:    townRoot,
+		RigPath:     m.rig.Path,
+		AgentDir:    m.polecatDir(polecat),
+		WorkDir:     workDir,
+		SettingsDir: config.RoleSettingsDir("polecat", m.rig.Path),
+	}, command)
+	for k, v := range config.AgentEnv(config.AgentEnvConfig{
+		Role:             "polecat",
+		Rig:              m.rig.Name,
+		AgentName:        polecat,
+		TownRoot:         townRoot,
+		RuntimeConfigDir: runtimeConfigDir,
+	}) {
+		if _, ok := spec.Env[k]; !ok {
+			spec.Env[k] = v
+		}
+	}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path)); err == nil {
+		spec.Limits = settings.Sandbox.ProfileFor("polecat")
+	}
+
+	// A container left over from a crashed session would block the name.
+	_ = d.Stop(sessionID)
+	return d.RunCommand(spec), nil
+}
+
 // Stop terminates a polecat session.
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
@@ -428,6 +525,20 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
+	// Remove the session's container in container mode. Killing the pane
+	// only detaches the client; the container itself must be removed.
+	if execCfg := m.executionConfig(); execCfg.UsesContainer() {
+		if d, err := m.containerDriver(execCfg.Container); err == nil {
+			if err := d.Stop(sessionID); err != nil {
+				fmt.Printf("Warning: could not remove container %s: %v\n", sessionID, err)
+			}
+		}
+	}
+
+	// Remove the session's cgroup, if sandboxed. A group that still has
+	// exiting processes is left for the daemon's sandbox scan.
+	_ = sandbox.Release(m.polecatDir(polecat))
+
 	return nil
 }
 
@@ -567,7 +678,7 @@ func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.capturePane(sessionID, lines)
 }
 
 // CaptureSession returns the recent output from a session by raw session ID.
@@ -580,7 +691,16 @@ func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, er
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.capturePane(sessionID, lines)
+}
+
+// capturePane captures pane output with known secret values redacted.
+func (m *SessionManager) capturePane(sessionID string, lines int) (string, error) {
+	out, err := m.tmux.CapturePane(sessionID, lines)
+	if err != nil {
+		return "", err
+	}
+	return secrets.LoadRedactor(filepath.Dir(m.rig.Path)).Redact(out), nil
 }
 
 // Inject sends a message to a polecat session.
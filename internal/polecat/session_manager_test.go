@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/container"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -420,3 +422,65 @@ func TestValidateSessionName(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerCommand_FakeDriver(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	workDir := filepath.Join(rigPath, "polecats", "Toast", "gastown")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rigPath, "Dockerfile"), []byte("FROM golang:1.24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewRigSettings()
+	settings.Sandbox = &config.SandboxConfig{
+		Enabled: true,
+		Roles:   map[string]*config.SandboxProfile{"polecat": {MaxPids: 256}},
+	}
+	settings.Execution = &config.ExecutionConfig{
+		Mode:      config.ExecutionModeContainer,
+		Container: &config.ContainerConfig{Dockerfile: "Dockerfile"},
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	fake := container.NewFakeDriver()
+	m := NewSessionManager(tmux.NewTmux(), &rig.Rig{Name: "gastown", Path: rigPath, Polecats: []string{"Toast"}})
+	m.containers = fake
+
+	execCfg := m.executionConfig()
+	if !execCfg.UsesContainer() {
+		t.Fatal("executionConfig() should select container mode")
+	}
+	cmd, err := m.containerCommand(execCfg.Container, "Toast", "gt-Toast", workDir, townRoot, "", "exec claude")
+	if err != nil {
+		t.Fatalf("containerCommand() error = %v", err)
+	}
+	if cmd != "exec claude" {
+		t.Errorf("fake RunCommand should pass the command through, got %q", cmd)
+	}
+
+	if len(fake.Built) != 1 || !strings.HasPrefix(fake.Built[0], "gastown-gastown:") {
+		t.Errorf("Built = %v, want one gastown-gastown:<hash> image", fake.Built)
+	}
+	if len(fake.Stopped) != 1 || fake.Stopped[0] != "gt-Toast" {
+		t.Errorf("Stopped = %v, want stale container removed before run", fake.Stopped)
+	}
+	spec := fake.Runs[0]
+	if spec.WorkDir != workDir || spec.Env["GT_ROLE"] == "" {
+		t.Errorf("spec = %+v", spec)
+	}
+	if spec.Limits == nil || spec.Limits.MaxPids != 256 {
+		t.Errorf("spec.Limits = %+v, want polecat sandbox profile", spec.Limits)
+	}
+
+	// Second start reuses the built image.
+	if _, err := m.containerCommand(execCfg.Container, "Toast", "gt-Toast", workDir, townRoot, "", "exec claude"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Built) != 1 {
+		t.Errorf("image rebuilt unexpectedly: %v", fake.Built)
+	}
+}
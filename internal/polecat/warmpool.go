@@ -0,0 +1,459 @@
+package polecat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Warm pool: pre-created worktrees that new polecats claim instead of running
+// "git worktree add" plus setup on the spawn path.
+//
+// Entries are anonymous detached worktrees, not polecats: a polecat only
+// exists once it has work (see types.go), so the pool holds disk state only.
+// Each entry lives at <rig>/.runtime/warm/<id>/<rigname>/, the same depth as
+// polecats/<name>/<rigname>/, so relative redirects (shared beads) resolve the
+// same way after the entry is moved into place.
+
+// ErrWarmPoolEmpty is returned when no warm worktree is available.
+var ErrWarmPoolEmpty = errors.New("warm pool empty")
+
+// WarmEntry is one ready worktree in the pool.
+type WarmEntry struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	BaseRef   string    `json:"base_ref"`
+	BaseSHA   string    `json:"base_sha"`
+	CreatedAt time.Time `json:"created_at"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+// WarmPoolStats counts pool activity since the pool was first filled.
+type WarmPoolStats struct {
+	Hits      int       `json:"hits"`
+	Misses    int       `json:"misses"`
+	Created   int       `json:"created"`
+	Refreshed int       `json:"refreshed"`
+	Discarded int       `json:"discarded"`
+	LastFill  time.Time `json:"last_fill,omitempty"`
+}
+
+// HitRate returns hits / (hits + misses), or 0 with no claims yet.
+func (s WarmPoolStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// WarmPoolState is persisted to <rig>/.runtime/warm/pool.json.
+type WarmPoolState struct {
+	Entries []*WarmEntry  `json:"entries"`
+	Stats   WarmPoolStats `json:"stats"`
+}
+
+// WarmPoolStatus is the pool summary reported by gt polecat pool status.
+type WarmPoolStatus struct {
+	Rig     string                 `json:"rig"`
+	Size    int                    `json:"size"`
+	Ready   int                    `json:"ready"`
+	Stale   int                    `json:"stale"`
+	BaseRef string                 `json:"base_ref"`
+	BaseSHA string                 `json:"base_sha,omitempty"`
+	Entries []*WarmEntry           `json:"entries"`
+	Stats   WarmPoolStats          `json:"stats"`
+	Config  *config.WarmPoolConfig `json:"config,omitempty"`
+}
+
+// FillResult summarizes one FillWarmPool pass.
+type FillResult struct {
+	Created   int
+	Refreshed int
+	Discarded int
+	Skipped   bool // another fill was already running
+}
+
+func (m *Manager) warmDir() string {
+	return filepath.Join(m.rig.Path, ".runtime", "warm")
+}
+
+func (m *Manager) warmStatePath() string {
+	return filepath.Join(m.warmDir(), "pool.json")
+}
+
+// warmPoolConfig returns the rig's warm pool settings, or nil when disabled.
+func (m *Manager) warmPoolConfig() *config.WarmPoolConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil || settings.WarmPool == nil || settings.WarmPool.Size <= 0 {
+		return nil
+	}
+	return settings.WarmPool
+}
+
+// warmSetupCommand returns merge_queue.setup_command when the pool should run it.
+func (m *Manager) warmSetupCommand(cfg *config.WarmPoolConfig) string {
+	if cfg == nil || !cfg.RunSetupCommand {
+		return ""
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil || settings.MergeQueue == nil {
+		return ""
+	}
+	return settings.MergeQueue.SetupCommand
+}
+
+// defaultStartPoint returns origin/<default_branch> for the rig.
+func (m *Manager) defaultStartPoint() string {
+	defaultBranch := "main"
+	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
+		defaultBranch = rigCfg.DefaultBranch
+	}
+	return fmt.Sprintf("origin/%s", defaultBranch)
+}
+
+// lockWarmState acquires the lock guarding pool.json.
+// Caller must defer fl.Unlock().
+func (m *Manager) lockWarmState() (*flock.Flock, error) {
+	lockDir := filepath.Join(m.rig.Path, ".runtime", "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating lock dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(lockDir, "warm-pool.lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("acquiring warm pool lock: %w", err)
+	}
+	return fl, nil
+}
+
+func (m *Manager) loadWarmState() (*WarmPoolState, error) {
+	data, err := os.ReadFile(m.warmStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &WarmPoolState{}, nil
+		}
+		return nil, err
+	}
+	var state WarmPoolState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", m.warmStatePath(), err)
+	}
+	return &state, nil
+}
+
+func (m *Manager) saveWarmState(state *WarmPoolState) error {
+	if err := os.MkdirAll(m.warmDir(), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(m.warmStatePath(), state)
+}
+
+// updateWarmState runs fn on the pool state under the state lock and saves it.
+func (m *Manager) updateWarmState(fn func(*WarmPoolState)) error {
+	fl, err := m.lockWarmState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	state, err := m.loadWarmState()
+	if err != nil {
+		return err
+	}
+	fn(state)
+	return m.saveWarmState(state)
+}
+
+// claimWarmWorktree moves a ready warm worktree to clonePath and puts it on a
+// fresh branch from startPoint. It prefers an entry already synced to
+// startPoint; any other entry is still usable since the checkout moves it.
+// Returns ErrWarmPoolEmpty when the pool has nothing to hand out.
+func (m *Manager) claimWarmWorktree(repoGit *git.Git, clonePath, branchName, startPoint string) error {
+	wantSHA, _ := repoGit.Rev(startPoint)
+
+	var entry *WarmEntry
+	err := m.updateWarmState(func(state *WarmPoolState) {
+		pick := -1
+		for i, e := range state.Entries {
+			if _, err := os.Stat(e.Path); err != nil {
+				continue
+			}
+			if pick < 0 || (wantSHA != "" && e.BaseSHA == wantSHA) {
+				pick = i
+			}
+			if wantSHA != "" && e.BaseSHA == wantSHA {
+				break
+			}
+		}
+		if pick < 0 {
+			state.Stats.Misses++
+			return
+		}
+		entry = state.Entries[pick]
+		state.Entries = append(state.Entries[:pick], state.Entries[pick+1:]...)
+	})
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return ErrWarmPoolEmpty
+	}
+
+	if err := repoGit.WorktreeMove(entry.Path, clonePath); err != nil {
+		m.discardWarmEntry(repoGit, entry)
+		return fmt.Errorf("moving warm worktree: %w", err)
+	}
+	_ = os.Remove(filepath.Dir(entry.Path))
+
+	if err := git.NewGit(clonePath).CheckoutNewBranch(branchName, startPoint); err != nil {
+		_ = repoGit.WorktreeRemove(clonePath, true)
+		_ = os.RemoveAll(clonePath)
+		return fmt.Errorf("checking out %s in warm worktree: %w", branchName, err)
+	}
+	return m.updateWarmState(func(state *WarmPoolState) {
+		state.Stats.Hits++
+	})
+}
+
+// discardWarmEntry removes a warm worktree and its directory.
+func (m *Manager) discardWarmEntry(repoGit *git.Git, e *WarmEntry) {
+	_ = repoGit.WorktreeRemove(e.Path, true)
+	_ = os.RemoveAll(filepath.Dir(e.Path))
+}
+
+// recordWarmMiss counts a spawn that claimed an entry but could not use it.
+func (m *Manager) recordWarmMiss() {
+	_ = m.updateWarmState(func(state *WarmPoolState) {
+		state.Stats.Misses++
+	})
+}
+
+// FillWarmPool tops the rig's warm pool up to its configured size, re-syncs
+// entries whose base has fallen behind the default branch, and replaces
+// entries older than max_age. It is a no-op when the pool is disabled and
+// skips when another fill is already running.
+func (m *Manager) FillWarmPool() (*FillResult, error) {
+	result := &FillResult{}
+	cfg := m.warmPoolConfig()
+	if cfg == nil {
+		return result, nil
+	}
+
+	lockDir := filepath.Join(m.rig.Path, ".runtime", "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating lock dir: %w", err)
+	}
+	fillLock := flock.New(filepath.Join(lockDir, "warm-pool-fill.lock"))
+	locked, err := fillLock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("acquiring warm pool fill lock: %w", err)
+	}
+	if !locked {
+		result.Skipped = true
+		return result, nil
+	}
+	defer func() { _ = fillLock.Unlock() }()
+
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, fmt.Errorf("finding repo base: %w", err)
+	}
+	if err := repoGit.Fetch("origin"); err != nil {
+		fmt.Printf("Warning: could not fetch origin: %v\n", err)
+	}
+	startPoint := m.defaultStartPoint()
+	sha, err := repoGit.Rev(startPoint)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", startPoint, err)
+	}
+	setupCmd := m.warmSetupCommand(cfg)
+	maxAge := cfg.MaxAgeDuration()
+
+	// Take out entries that need work so concurrent claims don't get them
+	// mid-refresh. Fresh entries stay claimable.
+	var stale []*WarmEntry
+	ready := 0
+	if err := m.updateWarmState(func(state *WarmPoolState) {
+		var keep []*WarmEntry
+		for _, e := range state.Entries {
+			if _, err := os.Stat(e.Path); err != nil {
+				result.Discarded++
+				continue
+			}
+			if e.BaseSHA != sha || time.Since(e.CreatedAt) > maxAge {
+				stale = append(stale, e)
+				continue
+			}
+			keep = append(keep, e)
+		}
+		state.Entries = keep
+		ready = len(keep)
+	}); err != nil {
+		return nil, err
+	}
+
+	var prepared []*WarmEntry
+	for _, e := range stale {
+		if time.Since(e.CreatedAt) > maxAge || ready+len(prepared) >= cfg.Size {
+			m.discardWarmEntry(repoGit, e)
+			result.Discarded++
+			continue
+		}
+		if err := m.syncWarmEntry(e, startPoint, sha, setupCmd); err != nil {
+			fmt.Printf("Warning: could not refresh warm worktree %s: %v\n", e.ID, err)
+			m.discardWarmEntry(repoGit, e)
+			result.Discarded++
+			continue
+		}
+		prepared = append(prepared, e)
+		result.Refreshed++
+	}
+
+	for ready+len(prepared) < cfg.Size {
+		e, err := m.createWarmEntry(repoGit, startPoint, sha, setupCmd)
+		if err != nil {
+			if len(prepared) > 0 {
+				break
+			}
+			return result, err
+		}
+		prepared = append(prepared, e)
+		result.Created++
+	}
+
+	var surplus []*WarmEntry
+	err = m.updateWarmState(func(state *WarmPoolState) {
+		state.Entries = append(state.Entries, prepared...)
+		if len(state.Entries) > cfg.Size {
+			surplus = state.Entries[cfg.Size:]
+			state.Entries = state.Entries[:cfg.Size]
+		}
+		state.Stats.Created += result.Created
+		state.Stats.Refreshed += result.Refreshed
+		state.Stats.Discarded += result.Discarded + len(surplus)
+		state.Stats.LastFill = time.Now()
+	})
+	for _, e := range surplus {
+		m.discardWarmEntry(repoGit, e)
+		result.Discarded++
+	}
+	return result, err
+}
+
+// createWarmEntry adds a detached worktree at sha and prepares it.
+func (m *Manager) createWarmEntry(repoGit *git.Git, startPoint, sha, setupCmd string) (*WarmEntry, error) {
+	id := fmt.Sprintf("%d", time.Now().UnixNano())
+	path := filepath.Join(m.warmDir(), id, m.rig.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating warm dir: %w", err)
+	}
+	if err := repoGit.WorktreeAddDetached(path, sha); err != nil {
+		_ = os.RemoveAll(filepath.Dir(path))
+		return nil, fmt.Errorf("creating warm worktree: %w", err)
+	}
+
+	e := &WarmEntry{
+		ID:        id,
+		Path:      path,
+		BaseRef:   startPoint,
+		BaseSHA:   sha,
+		CreatedAt: time.Now(),
+		SyncedAt:  time.Now(),
+	}
+	if err := rig.RunSetupHooks(m.rig.Path, path); err != nil {
+		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+	}
+	if err := runWarmSetupCommand(path, setupCmd); err != nil {
+		m.discardWarmEntry(repoGit, e)
+		return nil, err
+	}
+	return e, nil
+}
+
+// syncWarmEntry moves an existing warm worktree to sha, keeping ignored
+// build artifacts (node_modules, target/) so the setup command is incremental.
+func (m *Manager) syncWarmEntry(e *WarmEntry, startPoint, sha, setupCmd string) error {
+	if err := git.NewGit(e.Path).ResetHard(sha); err != nil {
+		return err
+	}
+	if err := runWarmSetupCommand(e.Path, setupCmd); err != nil {
+		return err
+	}
+	e.BaseRef = startPoint
+	e.BaseSHA = sha
+	e.SyncedAt = time.Now()
+	return nil
+}
+
+// runWarmSetupCommand runs the rig's setup command in dir, if any.
+func runWarmSetupCommand(dir, command string) error {
+	if command == "" {
+		return nil
+	}
+	cmd := exec.Command("sh", "-c", command) //nolint:gosec // G204: command comes from rig settings
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("setup command %q failed: %w\n%s", command, err, out)
+	}
+	return nil
+}
+
+// WarmPoolStatus reports the rig's warm pool contents and hit-rate metrics.
+func (m *Manager) WarmPoolStatus() (*WarmPoolStatus, error) {
+	state, err := m.loadWarmState()
+	if err != nil {
+		return nil, err
+	}
+	status := &WarmPoolStatus{
+		Rig:     m.rig.Name,
+		BaseRef: m.defaultStartPoint(),
+		Entries: state.Entries,
+		Stats:   state.Stats,
+		Config:  m.warmPoolConfig(),
+	}
+	if status.Config != nil {
+		status.Size = status.Config.Size
+	}
+	if repoGit, err := m.repoBase(); err == nil {
+		status.BaseSHA, _ = repoGit.Rev(status.BaseRef)
+	}
+	for _, e := range state.Entries {
+		if status.BaseSHA != "" && e.BaseSHA != status.BaseSHA {
+			status.Stale++
+		} else {
+			status.Ready++
+		}
+	}
+	return status, nil
+}
+
+// DrainWarmPool removes every warm worktree (e.g., after disabling the pool).
+func (m *Manager) DrainWarmPool() (int, error) {
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+	var drained []*WarmEntry
+	if err := m.updateWarmState(func(state *WarmPoolState) {
+		drained = state.Entries
+		state.Entries = nil
+		state.Stats.Discarded += len(drained)
+	}); err != nil {
+		return 0, err
+	}
+	for _, e := range drained {
+		m.discardWarmEntry(repoGit, e)
+	}
+	return len(drained), nil
+}
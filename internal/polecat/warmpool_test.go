@@ -0,0 +1,177 @@
+package polecat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupWarmPoolRig creates a rig whose mayor/rig repo has origin/main and a
+// warm pool of the given size. The setup command drops a marker file so tests
+// can tell warm worktrees apart from freshly created ones.
+func setupWarmPoolRig(t *testing.T, size int) (*Manager, string) {
+	t.Helper()
+	root := t.TempDir()
+	mayorRig := filepath.Join(root, "mayor", "rig")
+	if err := os.MkdirAll(mayorRig, 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = mayorRig
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("init")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(mayorRig, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "README.md")
+	run("commit", "-m", "init")
+	run("remote", "add", "origin", mayorRig)
+	run("update-ref", "refs/remotes/origin/main", "HEAD")
+
+	settings := config.NewRigSettings()
+	settings.MergeQueue.SetupCommand = "touch .warm-setup"
+	settings.WarmPool = &config.WarmPoolConfig{Size: size, RunSetupCommand: true}
+	if err := config.SaveRigSettings(config.RigSettingsPath(root), settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	r := &rig.Rig{Name: "rig", Path: root}
+	return NewManager(r, git.NewGit(root), nil), mayorRig
+}
+
+func TestFillWarmPool(t *testing.T) {
+	m, mayorRig := setupWarmPoolRig(t, 2)
+
+	result, err := m.FillWarmPool()
+	if err != nil {
+		t.Fatalf("FillWarmPool: %v", err)
+	}
+	if result.Created != 2 {
+		t.Errorf("Created = %d, want 2", result.Created)
+	}
+
+	status, err := m.WarmPoolStatus()
+	if err != nil {
+		t.Fatalf("WarmPoolStatus: %v", err)
+	}
+	if status.Ready != 2 || len(status.Entries) != 2 {
+		t.Fatalf("Ready = %d, entries = %d, want 2", status.Ready, len(status.Entries))
+	}
+	for _, e := range status.Entries {
+		if _, err := os.Stat(filepath.Join(e.Path, ".warm-setup")); err != nil {
+			t.Errorf("setup command did not run in %s", e.Path)
+		}
+	}
+
+	// A full, current pool needs no work.
+	result, err = m.FillWarmPool()
+	if err != nil {
+		t.Fatalf("second FillWarmPool: %v", err)
+	}
+	if result.Created+result.Refreshed+result.Discarded != 0 {
+		t.Errorf("second fill did work: %+v", result)
+	}
+
+	// Advancing the default branch marks entries stale; the next fill re-syncs them.
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", "advance")
+	cmd.Dir = mayorRig
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	cmd = exec.Command("git", "update-ref", "refs/remotes/origin/main", "HEAD")
+	cmd.Dir = mayorRig
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git update-ref: %v\n%s", err, out)
+	}
+	if status, _ := m.WarmPoolStatus(); status.Stale != 2 {
+		t.Errorf("Stale = %d after advancing main, want 2", status.Stale)
+	}
+	result, err = m.FillWarmPool()
+	if err != nil {
+		t.Fatalf("refresh FillWarmPool: %v", err)
+	}
+	if result.Refreshed != 2 {
+		t.Errorf("Refreshed = %d, want 2", result.Refreshed)
+	}
+	status, _ = m.WarmPoolStatus()
+	head, _ := git.NewGit(mayorRig).Rev("HEAD")
+	for _, e := range status.Entries {
+		if e.BaseSHA != head {
+			t.Errorf("entry %s at %s, want %s", e.ID, e.BaseSHA, head)
+		}
+		if got, _ := git.NewGit(e.Path).Rev("HEAD"); got != head {
+			t.Errorf("worktree %s HEAD = %s, want %s", e.Path, got, head)
+		}
+	}
+}
+
+func TestAddWithOptions_ClaimsWarmWorktree(t *testing.T) {
+	installMockBd(t)
+	m, _ := setupWarmPoolRig(t, 1)
+	if _, err := m.FillWarmPool(); err != nil {
+		t.Fatalf("FillWarmPool: %v", err)
+	}
+
+	p, err := m.AddWithOptions("Toast", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.ClonePath, ".warm-setup")); err != nil {
+		t.Errorf("polecat worktree was not claimed from the warm pool")
+	}
+	branch, err := git.NewGit(p.ClonePath).CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if branch != p.Branch {
+		t.Errorf("branch = %q, want %q", branch, p.Branch)
+	}
+
+	// Pool is now empty: the next spawn misses and creates a worktree.
+	p2, err := m.AddWithOptions("Nux", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions (miss): %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p2.ClonePath, ".warm-setup")); err == nil {
+		t.Errorf("second polecat unexpectedly used a warm worktree")
+	}
+
+	status, err := m.WarmPoolStatus()
+	if err != nil {
+		t.Fatalf("WarmPoolStatus: %v", err)
+	}
+	if status.Stats.Hits != 1 || status.Stats.Misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 1/1", status.Stats.Hits, status.Stats.Misses)
+	}
+	if got := status.Stats.HitRate(); got != 0.5 {
+		t.Errorf("HitRate = %v, want 0.5", got)
+	}
+}
+
+func TestWarmPoolDisabled(t *testing.T) {
+	m, _ := setupWarmPoolRig(t, 0)
+	result, err := m.FillWarmPool()
+	if err != nil {
+		t.Fatalf("FillWarmPool: %v", err)
+	}
+	if result.Created != 0 {
+		t.Errorf("disabled pool created %d entries", result.Created)
+	}
+	if _, err := os.Stat(m.warmStatePath()); !os.IsNotExist(err) {
+		t.Errorf("disabled pool wrote state file")
+	}
+}
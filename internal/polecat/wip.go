@@ -0,0 +1,34 @@
+package polecat
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// SnapshotWIP saves a polecat's unsaved work (uncommitted changes and
+// unpushed commits) to a WIP snapshot ref in the rig's shared repository.
+// Returns nil, nil when there is nothing to save or the polecat has no
+// git worktree.
+func (m *Manager) SnapshotWIP(name string) (*checkpoint.Snapshot, error) {
+	clonePath := m.clonePath(name)
+	// Only a worktree root: a plain directory inside some other repository
+	// must not snapshot that repository.
+	if _, err := os.Stat(filepath.Join(clonePath, ".git")); err != nil {
+		return nil, nil
+	}
+	repo, err := m.repoBase()
+	if err != nil {
+		// No shared repo: keep the ref in the worktree's own repository.
+		repo = nil
+	}
+	return checkpoint.TakeSnapshot(clonePath, repo, name, time.Now())
+}
+
+// WIPRepo returns the rig repository that holds WIP snapshot refs.
+func (m *Manager) WIPRepo() (*git.Git, error) {
+	return m.repoBase()
+}
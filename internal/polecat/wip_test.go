@@ -0,0 +1,83 @@
+package polecat
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
+)
+
+// breakHEAD overwrites a worktree's HEAD with garbage so git can no longer
+// resolve it.
+func breakHEAD(t *testing.T, clonePath string) {
+	t.Helper()
+	out, err := exec.Command("git", "-C", clonePath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		t.Fatalf("rev-parse --git-dir: %v", err)
+	}
+	head := filepath.Join(strings.TrimSpace(string(out)), "HEAD")
+	if err := os.WriteFile(head, []byte("garbage\n"), 0644); err != nil {
+		t.Fatalf("corrupting HEAD: %v", err)
+	}
+}
+
+func TestRemoveWithOptions_NuclearRemovesBrokenHEAD(t *testing.T) {
+	installMockBd(t)
+	m, _ := setupWarmPoolRig(t, 0)
+	p, err := m.AddWithOptions("Toast", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+	breakHEAD(t, p.ClonePath)
+
+	if _, err := m.SnapshotWIP("Toast"); !errors.Is(err, checkpoint.ErrUninspectable) {
+		t.Fatalf("SnapshotWIP error = %v, want ErrUninspectable", err)
+	}
+
+	// Without nuclear the unreadable worktree is kept.
+	err = m.RemoveWithOptions("Toast", true, false, false)
+	if err == nil {
+		t.Fatal("force removal of an uninspectable worktree should fail")
+	}
+	if errors.Is(err, ErrUnsnapshottedWork) {
+		t.Errorf("error = %v, should not claim unsnapshotted work", err)
+	}
+	if _, statErr := os.Stat(p.ClonePath); statErr != nil {
+		t.Fatalf("worktree removed despite refusal: %v", statErr)
+	}
+
+	if err := m.RemoveWithOptions("Toast", true, true, false); err != nil {
+		t.Fatalf("nuclear RemoveWithOptions: %v", err)
+	}
+	if _, err := os.Stat(p.ClonePath); !os.IsNotExist(err) {
+		t.Errorf("worktree still present after nuclear removal: %v", err)
+	}
+}
+
+func TestRemoveWithOptions_NuclearSnapshotsDirtyWork(t *testing.T) {
+	installMockBd(t)
+	m, _ := setupWarmPoolRig(t, 0)
+	p, err := m.AddWithOptions("Toast", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ClonePath, "work.txt"), []byte("unsaved\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.RemoveWithOptions("Toast", true, true, false); err != nil {
+		t.Fatalf("nuclear RemoveWithOptions: %v", err)
+	}
+	repo, err := m.WIPRepo()
+	if err != nil {
+		t.Fatalf("WIPRepo: %v", err)
+	}
+	snaps, err := checkpoint.ListSnapshots(repo, "Toast")
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("snapshots = %v, %v; want one", snaps, err)
+	}
+}
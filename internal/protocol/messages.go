@@ -92,7 +92,7 @@ func formatMergedBody(p MergedPayload) string {
 // NewMergeFailedMessage creates a MERGE_FAILED protocol message.
 // Sent by Refinery to Witness when merge fails (tests, build, etc.).
 func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureType, errorMsg string) *mail.Message {
-	payload := MergeFailedPayload{
+	return NewMergeFailedMessageFromPayload(MergeFailedPayload{
 		Branch:       branch,
 		Issue:        issue,
 		Polecat:      polecat,
@@ -101,14 +101,21 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		FailureType:  failureType,
 		Error:        errorMsg,
 		TargetBranch: targetBranch,
-	}
+	})
+}
 
-	body := formatMergeFailedBody(payload)
+// NewMergeFailedMessageFromPayload creates a MERGE_FAILED protocol message
+// from a filled-in payload, including the failing stage and its output.
+func NewMergeFailedMessageFromPayload(p MergeFailedPayload) *mail.Message {
+	if p.FailedAt.IsZero() {
+		p.FailedAt = time.Now()
+	}
+	body := formatMergeFailedBody(p)
 
 	msg := mail.NewMessage(
-		fmt.Sprintf("%s/refinery", rig),
-		fmt.Sprintf("%s/witness", rig),
-		fmt.Sprintf("MERGE_FAILED %s", polecat),
+		fmt.Sprintf("%s/refinery", p.Rig),
+		fmt.Sprintf("%s/witness", p.Rig),
+		fmt.Sprintf("MERGE_FAILED %s", p.Polecat),
 		body,
 	)
 	msg.Priority = mail.PriorityHigh
@@ -128,9 +135,34 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
 	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	if p.Stage != "" {
+		sb.WriteString(fmt.Sprintf("Stage: %s\n", p.Stage))
+	}
+	if p.LogPath != "" {
+		sb.WriteString(fmt.Sprintf("Log: %s\n", p.LogPath))
+	}
+	if p.Output != "" {
+		// Output goes last: it spans lines and is read to the end of the body.
+		sb.WriteString(mergeFailedOutputHeader)
+		sb.WriteString(strings.TrimRight(p.Output, "\n"))
+		sb.WriteString("\n")
+	}
 	return sb.String()
 }
 
+// mergeFailedOutputHeader introduces the stage output in a MERGE_FAILED body.
+const mergeFailedOutputHeader = "Output:\n"
+
+// splitMergeFailedBody separates a MERGE_FAILED body into its header fields
+// and the stage output block, if any.
+func splitMergeFailedBody(body string) (header, output string) {
+	i := strings.Index(body, "\n"+mergeFailedOutputHeader)
+	if i < 0 {
+		return body, ""
+	}
+	return body[:i+1], strings.TrimRight(body[i+1+len(mergeFailedOutputHeader):], "\n")
+}
+
 // NewReworkRequestMessage creates a REWORK_REQUEST protocol message.
 // Sent by Refinery to Witness when a branch needs rebasing due to conflicts.
 func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, conflictFiles []string) *mail.Message {
@@ -159,6 +191,32 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 	return msg
 }
 
+// NewReviewReworkRequestMessage creates a REWORK_REQUEST protocol message
+// for an MR whose review requested changes. Sent by Refinery to Witness.
+func NewReviewReworkRequestMessage(p ReworkRequestPayload) *mail.Message {
+	p.Reason = ReworkReasonReview
+	if p.RequestedAt.IsZero() {
+		p.RequestedAt = time.Now()
+	}
+	if p.Instructions == "" {
+		p.Instructions = formatReviewInstructions()
+	}
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/refinery", p.Rig),
+		fmt.Sprintf("%s/witness", p.Rig),
+		fmt.Sprintf("REWORK_REQUEST %s", p.Polecat),
+		formatReworkRequestBody(p),
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return msg
+}
+
+// reworkFindingsHeader introduces the review findings in a REWORK_REQUEST body.
+const reworkFindingsHeader = "Findings:"
+
 // formatReworkRequestBody formats the body of a REWORK_REQUEST message.
 func formatReworkRequestBody(p ReworkRequestPayload) string {
 	var sb strings.Builder
@@ -168,10 +226,25 @@ func formatReworkRequestBody(p ReworkRequestPayload) string {
 	sb.WriteString(fmt.Sprintf("Rig: %s\n", p.Rig))
 	sb.WriteString(fmt.Sprintf("Target: %s\n", p.TargetBranch))
 	sb.WriteString(fmt.Sprintf("Requested-At: %s\n", p.RequestedAt.Format(time.RFC3339)))
+	if p.Reason != "" {
+		sb.WriteString(fmt.Sprintf("Reason: %s\n", p.Reason))
+	}
+	if p.MR != "" {
+		sb.WriteString(fmt.Sprintf("MR: %s\n", p.MR))
+	}
+	if p.Reviewer != "" {
+		sb.WriteString(fmt.Sprintf("Reviewer: %s\n", p.Reviewer))
+	}
 
 	if len(p.ConflictFiles) > 0 {
 		sb.WriteString(fmt.Sprintf("Conflict-Files: %s\n", strings.Join(p.ConflictFiles, ", ")))
 	}
+	if len(p.Findings) > 0 {
+		sb.WriteString(reworkFindingsHeader + "\n")
+		for _, f := range p.Findings {
+			sb.WriteString("  - " + f + "\n")
+		}
+	}
 
 	sb.WriteString("\n")
 	sb.WriteString(p.Instructions)
@@ -191,6 +264,38 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// formatReviewInstructions returns standard instructions for addressing
+// review findings.
+func formatReviewInstructions() string {
+	return `Code review requested changes. Address the findings above on your branch,
+commit and push:
+
+  git push
+
+The Refinery will have the new head reviewed again before merging.`
+}
+
+// parseReworkFindings extracts the findings block of a REWORK_REQUEST body.
+func parseReworkFindings(body string) []string {
+	var findings []string
+	inBlock := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == reworkFindingsHeader {
+			inBlock = true
+			continue
+		}
+		if !inBlock {
+			continue
+		}
+		f, ok := strings.CutPrefix(line, "  - ")
+		if !ok {
+			break
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergeReadyPayload(body string) (*MergeReadyPayload, error) {
@@ -259,18 +364,23 @@ func ParseMergedPayload(body string) (*MergedPayload, error) {
 // ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
+	// Header fields end where the free-form stage output begins.
+	header, output := splitMergeFailedBody(body)
 	payload := &MergeFailedPayload{
-		Branch:       parseField(body, "Branch"),
-		Issue:        parseField(body, "Issue"),
-		Polecat:      parseField(body, "Polecat"),
-		Rig:          parseField(body, "Rig"),
-		TargetBranch: parseField(body, "Target"),
-		FailureType:  parseField(body, "Failure-Type"),
-		Error:        parseField(body, "Error"),
+		Branch:       parseField(header, "Branch"),
+		Issue:        parseField(header, "Issue"),
+		Polecat:      parseField(header, "Polecat"),
+		Rig:          parseField(header, "Rig"),
+		TargetBranch: parseField(header, "Target"),
+		FailureType:  parseField(header, "Failure-Type"),
+		Error:        parseField(header, "Error"),
+		Stage:        parseField(header, "Stage"),
+		LogPath:      parseField(header, "Log"),
+		Output:       output,
 	}
 
 	// Parse timestamp
-	if ts := parseField(body, "Failed-At"); ts != "" {
+	if ts := parseField(header, "Failed-At"); ts != "" {
 		if t, err := time.Parse(time.RFC3339, ts); err == nil {
 			payload.FailedAt = t
 		}
@@ -302,6 +412,10 @@ func ParseReworkRequestPayload(body string) (*ReworkRequestPayload, error) {
 		Polecat:      parseField(body, "Polecat"),
 		Rig:          parseField(body, "Rig"),
 		TargetBranch: parseField(body, "Target"),
+		Reason:       parseField(body, "Reason"),
+		MR:           parseField(body, "MR"),
+		Reviewer:     parseField(body, "Reviewer"),
+		Findings:     parseReworkFindings(body),
 	}
 
 	// Parse timestamp
@@ -281,6 +281,35 @@ Error: Test failed`
 	}
 }
 
+func TestMergeFailedPayload_StageOutputRoundTrip(t *testing.T) {
+	msg := NewMergeFailedMessageFromPayload(MergeFailedPayload{
+		Branch:       "polecat/nux/gt-abc",
+		Issue:        "gt-abc",
+		Polecat:      "nux",
+		Rig:          "gastown",
+		TargetBranch: "main",
+		FailureType:  "lint",
+		Error:        "lint failed: exit status 1",
+		Stage:        "lint",
+		LogPath:      "/town/gastown/.runtime/refinery/verify/gt-mr1/02-lint.log",
+		Output:       "main.go:3: x declared and not used\nBranch: not-a-header",
+	})
+
+	payload, err := ParseMergeFailedPayload(msg.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Branch != "polecat/nux/gt-abc" {
+		t.Errorf("Branch = %q; output lines must not be parsed as headers", payload.Branch)
+	}
+	if payload.Stage != "lint" || payload.LogPath == "" {
+		t.Errorf("Stage = %q, LogPath = %q", payload.Stage, payload.LogPath)
+	}
+	if payload.Output != "main.go:3: x declared and not used\nBranch: not-a-header" {
+		t.Errorf("Output = %q", payload.Output)
+	}
+}
+
 func TestParseMergeFailedPayload_InvalidInput(t *testing.T) {
 	payload, err := ParseMergeFailedPayload("")
 	if err == nil {
@@ -315,6 +344,43 @@ Conflict-Files: file1.go, file2.go`
 	}
 }
 
+func TestReviewReworkRequestRoundTrip(t *testing.T) {
+	msg := NewReviewReworkRequestMessage(ReworkRequestPayload{
+		Branch:       "polecat/nux/gt-abc",
+		Issue:        "gt-abc",
+		Polecat:      "nux",
+		Rig:          "gastown",
+		TargetBranch: "main",
+		MR:           "gt-mr1",
+		Reviewer:     "gastown/polecats/rictus",
+		Findings:     []string{"[blocking] api.go:12 nil map write", "[nit] missing doc comment"},
+	})
+
+	if msg.Subject != "REWORK_REQUEST nux" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "REWORK_REQUEST nux")
+	}
+
+	payload, err := ParseReworkRequestPayload(msg.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !payload.IsReview() {
+		t.Errorf("Reason = %q, want %q", payload.Reason, ReworkReasonReview)
+	}
+	if payload.MR != "gt-mr1" {
+		t.Errorf("MR = %q, want %q", payload.MR, "gt-mr1")
+	}
+	if payload.Reviewer != "gastown/polecats/rictus" {
+		t.Errorf("Reviewer = %q", payload.Reviewer)
+	}
+	if len(payload.Findings) != 2 || payload.Findings[1] != "[nit] missing doc comment" {
+		t.Errorf("Findings = %q", payload.Findings)
+	}
+	if len(payload.ConflictFiles) != 0 {
+		t.Errorf("ConflictFiles = %q, want none", payload.ConflictFiles)
+	}
+}
+
 func TestParseReworkRequestPayload_InvalidInput(t *testing.T) {
 	payload, err := ParseReworkRequestPayload("")
 	if err == nil {
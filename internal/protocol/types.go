@@ -7,7 +7,7 @@
 //   - MERGE_READY: Witness → Refinery (branch ready for merge)
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
-//   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//   - REWORK_REQUEST: Refinery → Witness (rebase needed, or review requested changes)
 package protocol
 
 import (
@@ -35,7 +35,8 @@ const (
 	TypeMergeFailed MessageType = "MERGE_FAILED"
 
 	// TypeReworkRequest is sent from Refinery to Witness when a polecat's
-	// branch needs rebasing due to conflicts with the target branch.
+	// branch needs rebasing due to conflicts with the target branch, or when
+	// code review requested changes.
 	// Subject format: "REWORK_REQUEST <polecat-name>"
 	TypeReworkRequest MessageType = "REWORK_REQUEST"
 )
@@ -136,10 +137,26 @@ type MergeFailedPayload struct {
 
 	// TargetBranch is the branch we tried to merge into.
 	TargetBranch string `json:"target_branch"`
+
+	// Stage is the verification stage that failed (build, lint, test, ...).
+	Stage string `json:"stage,omitempty"`
+
+	// LogPath is where the Refinery stored the full stage logs.
+	LogPath string `json:"log_path,omitempty"`
+
+	// Output is the tail of the failing stage's output.
+	Output string `json:"output,omitempty"`
 }
 
+// Rework reasons carried by REWORK_REQUEST.
+const (
+	ReworkReasonConflict = "conflict"
+	ReworkReasonReview   = "review"
+)
+
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
-// Sent by Refinery when a polecat's branch has conflicts requiring rebase.
+// Sent by Refinery when a polecat's branch has conflicts requiring rebase,
+// or when its review requested changes.
 type ReworkRequestPayload struct {
 	// Branch is the source branch that needs rebasing.
 	Branch string `json:"branch"`
@@ -164,6 +181,23 @@ type ReworkRequestPayload struct {
 
 	// Instructions provides specific rebase instructions.
 	Instructions string `json:"instructions,omitempty"`
+
+	// Reason is why rework is needed: conflict (default) or review.
+	Reason string `json:"reason,omitempty"`
+
+	// MR is the merge-request bead ID.
+	MR string `json:"mr,omitempty"`
+
+	// Reviewer is who requested changes (review rework only).
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Findings are the review findings to address, one per line.
+	Findings []string `json:"findings,omitempty"`
+}
+
+// IsReview reports whether the rework was requested by code review.
+func (p *ReworkRequestPayload) IsReview() bool {
+	return p.Reason == ReworkReasonReview
 }
 
 // PolecatDonePayload contains the data from a POLECAT_DONE notification.
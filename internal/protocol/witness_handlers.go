@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/witness"
@@ -148,7 +149,22 @@ func (h *DefaultWitnessHandler) HandlePolecatDone(payload *PolecatDonePayload) e
 // 1. Logs the conflict
 // 2. Notifies the polecat with rebase instructions
 // 3. Updates the polecat's state to indicate rebase needed
+//
+// When code review requested changes, the Witness forwards the findings to
+// the polecat instead.
 func (h *DefaultWitnessHandler) HandleReworkRequest(payload *ReworkRequestPayload) error {
+	if payload.IsReview() {
+		fmt.Fprintf(h.Output, "[Witness] REWORK_REQUEST (review) received for polecat %s\n", payload.Polecat)
+		fmt.Fprintf(h.Output, "  Branch: %s\n", payload.Branch)
+		fmt.Fprintf(h.Output, "  MR: %s\n", payload.MR)
+		fmt.Fprintf(h.Output, "  Findings: %d\n", len(payload.Findings))
+		if err := h.notifyPolecatReview(payload); err != nil {
+			fmt.Fprintf(h.Output, "[Witness] Warning: failed to notify polecat: %v\n", err)
+		}
+		fmt.Fprintf(h.Output, "[Witness] ⚠ Polecat %s needs to address review findings\n", payload.Polecat)
+		return nil
+	}
+
 	fmt.Fprintf(h.Output, "[Witness] REWORK_REQUEST received for polecat %s\n", payload.Polecat)
 	fmt.Fprintf(h.Output, "  Branch: %s\n", payload.Branch)
 	fmt.Fprintf(h.Output, "  Issue: %s\n", payload.Issue)
@@ -204,12 +220,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit your work with 'gt done'.`,
 			payload.Branch,
 			payload.Issue,
 			payload.FailureType,
 			payload.Error,
+			formatFailureOutput(payload),
 		),
 	)
 	msg.Priority = mail.PriorityHigh
@@ -218,6 +235,19 @@ Please fix the issue and resubmit your work with 'gt done'.`,
 	return h.Router.Send(msg)
 }
 
+// formatFailureOutput renders the failing stage's log location and output
+// for a polecat notification. Returns an empty string when there is none.
+func formatFailureOutput(payload *MergeFailedPayload) string {
+	var sb strings.Builder
+	if payload.LogPath != "" {
+		sb.WriteString(fmt.Sprintf("Full log: %s\n", payload.LogPath))
+	}
+	if payload.Output != "" {
+		sb.WriteString(fmt.Sprintf("\nOutput of the %s stage:\n%s\n", payload.Stage, payload.Output))
+	}
+	return sb.String()
+}
+
 // notifyPolecatRebase sends a rebase request notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatRebase(payload *ReworkRequestPayload) error {
 	conflictInfo := ""
@@ -258,5 +288,43 @@ Then run 'gt done' to resubmit for merge.`,
 	return h.Router.Send(msg)
 }
 
+// notifyPolecatReview forwards review findings to a polecat.
+func (h *DefaultWitnessHandler) notifyPolecatReview(payload *ReworkRequestPayload) error {
+	var findings strings.Builder
+	for _, f := range payload.Findings {
+		findings.WriteString("  - " + f + "\n")
+	}
+	if findings.Len() == 0 {
+		findings.WriteString("  (no findings recorded - see the MR bead)\n")
+	}
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/witness", h.Rig),
+		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
+		"Changes requested in review",
+		fmt.Sprintf(`Code review of your merge request requested changes.
+
+Branch: %s
+Issue: %s
+MR: %s
+Reviewer: %s
+
+Findings:
+%s
+%s`,
+			payload.Branch,
+			payload.Issue,
+			payload.MR,
+			payload.Reviewer,
+			findings.String(),
+			payload.Instructions,
+		),
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return h.Router.Send(msg)
+}
+
 // Ensure DefaultWitnessHandler implements WitnessHandler.
 var _ WitnessHandler = (*DefaultWitnessHandler)(nil)
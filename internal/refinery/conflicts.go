@@ -0,0 +1,251 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// How much two branches' changes overlap.
+const (
+	OverlapFile = "file" // Same files, different lines
+	OverlapHunk = "hunk" // Overlapping or adjacent lines: likely to conflict
+)
+
+// ConflictReportMaxAge is how old a saved conflict report may be before
+// queue ordering ignores it.
+const ConflictReportMaxAge = time.Hour
+
+// ConflictBranch is an in-flight branch checked for overlaps.
+type ConflictBranch struct {
+	Branch string `json:"branch"`
+	Worker string `json:"worker,omitempty"`
+	MR     string `json:"mr,omitempty"` // Open MR for the branch, if submitted
+}
+
+// Overlap is where two branches, or a branch and the target, change the
+// same files since the branch forked.
+type Overlap struct {
+	A     string   `json:"a"` // Branch
+	B     string   `json:"b"` // Branch, or the target
+	Level string   `json:"level"`
+	Files []string `json:"files"`           // Files both change
+	Hunks []string `json:"hunks,omitempty"` // Files where the changed lines overlap
+}
+
+// ConflictReport is a rig's predicted conflicts between in-flight branches
+// and its default branch.
+type ConflictReport struct {
+	Rig         string           `json:"rig"`
+	Target      string           `json:"target"` // e.g. origin/main
+	GeneratedAt time.Time        `json:"generated_at"`
+	Branches    []ConflictBranch `json:"branches"`
+	Overlaps    []Overlap        `json:"overlaps"`
+	// Errors holds the branches that couldn't be diffed.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Between returns the overlap between two branches (or a branch and the
+// target), or nil.
+func (r *ConflictReport) Between(a, b string) *Overlap {
+	for i, o := range r.Overlaps {
+		if (o.A == a && o.B == b) || (o.A == b && o.B == a) {
+			return &r.Overlaps[i]
+		}
+	}
+	return nil
+}
+
+// HunkOverlaps returns how many other in-flight branches change the same
+// lines as branch.
+func (r *ConflictReport) HunkOverlaps(branch string) int {
+	n := 0
+	for _, o := range r.Overlaps {
+		if o.Level == OverlapHunk && o.B != r.Target && (o.A == branch || o.B == branch) {
+			n++
+		}
+	}
+	return n
+}
+
+// CompareChanges returns the files two sets of changes both touch, and
+// those where the changed lines overlap or touch. Hunks are in merge-base
+// coordinates, so branches forked from different commits compare
+// approximately. A file changed without line hunks overlaps any change to
+// it.
+func CompareChanges(a, b map[string][]git.Hunk) (files, hunks []string) {
+	for file, ha := range a {
+		hb, ok := b[file]
+		if !ok {
+			continue
+		}
+		files = append(files, file)
+		if len(ha) == 0 || len(hb) == 0 || hunksOverlap(ha, hb) {
+			hunks = append(hunks, file)
+		}
+	}
+	sort.Strings(files)
+	sort.Strings(hunks)
+	return files, hunks
+}
+
+// hunksOverlap reports whether any two hunks overlap or are adjacent, which
+// git also treats as a conflict.
+func hunksOverlap(a, b []git.Hunk) bool {
+	for _, x := range a {
+		xs, xe := hunkRange(x)
+		for _, y := range b {
+			ys, ye := hunkRange(y)
+			if xs <= ye+1 && ys <= xe+1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hunkRange returns the lines a hunk covers; an insertion covers the line
+// it follows.
+func hunkRange(h git.Hunk) (int, int) {
+	if h.Lines == 0 {
+		return h.Start, h.Start
+	}
+	return h.Start, h.Start + h.Lines - 1
+}
+
+// buildConflictReport compares each pair of branches' changes, and each
+// branch's changes with what the target changed since the branch forked.
+func buildConflictReport(target string, branches []ConflictBranch, changes, targetChanges map[string]map[string][]git.Hunk) []Overlap {
+	var overlaps []Overlap
+	add := func(a, b string, files, hunks []string) {
+		if len(files) == 0 {
+			return
+		}
+		level := OverlapFile
+		if len(hunks) > 0 {
+			level = OverlapHunk
+		}
+		overlaps = append(overlaps, Overlap{A: a, B: b, Level: level, Files: files, Hunks: hunks})
+	}
+	for i, a := range branches {
+		ca, ok := changes[a.Branch]
+		if !ok {
+			continue
+		}
+		if tc, ok := targetChanges[a.Branch]; ok {
+			files, hunks := CompareChanges(ca, tc)
+			add(a.Branch, target, files, hunks)
+		}
+		for _, b := range branches[i+1:] {
+			if cb, ok := changes[b.Branch]; ok {
+				files, hunks := CompareChanges(ca, cb)
+				add(a.Branch, b.Branch, files, hunks)
+			}
+		}
+	}
+	return overlaps
+}
+
+// PredictConflicts reports where the rig's open MRs and the given polecat
+// branches change the same files and lines as each other, and as the
+// default branch since they forked. It fetches origin first so the default
+// branch is current.
+func (e *Engineer) PredictConflicts(polecats []ConflictBranch) (*ConflictReport, error) {
+	if err := e.git.Fetch("origin"); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetching origin: %v\n", err)
+	}
+	mrs, err := e.ListAllOpenMRs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConflictReport{
+		Rig:         e.rig.Name,
+		Target:      "origin/" + e.rig.DefaultBranch(),
+		GeneratedAt: time.Now(),
+	}
+	seen := make(map[string]bool)
+	for _, mr := range mrs {
+		if mr.Branch != "" && !seen[mr.Branch] {
+			seen[mr.Branch] = true
+			report.Branches = append(report.Branches, ConflictBranch{Branch: mr.Branch, Worker: mr.Worker, MR: mr.ID})
+		}
+	}
+	for _, p := range polecats {
+		if p.Branch != "" && p.Branch != e.rig.DefaultBranch() && !seen[p.Branch] {
+			seen[p.Branch] = true
+			report.Branches = append(report.Branches, p)
+		}
+	}
+	sort.Slice(report.Branches, func(i, j int) bool {
+		return report.Branches[i].Branch < report.Branches[j].Branch
+	})
+
+	changes := make(map[string]map[string][]git.Hunk)
+	targetChanges := make(map[string]map[string][]git.Hunk)
+	for _, b := range report.Branches {
+		// Prefer the local branch (shared with polecats) over origin's copy
+		ref := b.Branch
+		if exists, _ := e.git.BranchExists(ref); !exists {
+			ref = "origin/" + ref
+		}
+		c, err := e.git.ChangedHunks(report.Target, ref)
+		if err == nil {
+			targetChanges[b.Branch], err = e.git.ChangedHunks(ref, report.Target)
+		}
+		if err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[b.Branch] = err.Error()
+			continue
+		}
+		changes[b.Branch] = c
+	}
+	report.Overlaps = buildConflictReport(report.Target, report.Branches, changes, targetChanges)
+	return report, nil
+}
+
+// conflictReportPath is where the latest conflict report is kept.
+func conflictReportPath(rigPath string) string {
+	return filepath.Join(constants.RigRuntimePath(rigPath), "refinery", "conflicts.json")
+}
+
+// SaveConflictReport saves a rig's latest conflict report.
+func SaveConflictReport(rigPath string, report *ConflictReport) error {
+	return util.EnsureDirAndWriteJSON(conflictReportPath(rigPath), report)
+}
+
+// LoadConflictReport reads a rig's latest conflict report. It returns nil,
+// without error, when there is none.
+func LoadConflictReport(rigPath string) (*ConflictReport, error) {
+	data, err := os.ReadFile(conflictReportPath(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var report ConflictReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing conflict report: %w", err)
+	}
+	return &report, nil
+}
+
+// recentConflictReport returns the rig's saved conflict report if it is
+// recent enough to order the queue by, else nil.
+func recentConflictReport(rigPath string, now time.Time) *ConflictReport {
+	report, err := LoadConflictReport(rigPath)
+	if err != nil || report == nil || now.Sub(report.GeneratedAt) > ConflictReportMaxAge {
+		return nil
+	}
+	return report
+}
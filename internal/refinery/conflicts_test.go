@@ -0,0 +1,121 @@
+package refinery
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestCompareChanges(t *testing.T) {
+	a := map[string][]git.Hunk{
+		"shared.go":   {{Start: 10, Lines: 5}}, // 10-14
+		"adjacent.go": {{Start: 20, Lines: 2}}, // 20-21
+		"apart.go":    {{Start: 1, Lines: 3}},  // 1-3
+		"binary.png":  nil,                     // no line hunks
+		"only-a.go":   {{Start: 1, Lines: 1}},
+	}
+	b := map[string][]git.Hunk{
+		"shared.go":   {{Start: 12, Lines: 0}},  // insertion after 12
+		"adjacent.go": {{Start: 22, Lines: 1}},  // 22
+		"apart.go":    {{Start: 40, Lines: 10}}, // 40-49
+		"binary.png":  {{Start: 1, Lines: 1}},
+		"only-b.go":   {{Start: 1, Lines: 1}},
+	}
+
+	files, hunks := CompareChanges(a, b)
+	if want := []string{"adjacent.go", "apart.go", "binary.png", "shared.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	if want := []string{"adjacent.go", "binary.png", "shared.go"}; !reflect.DeepEqual(hunks, want) {
+		t.Errorf("hunks = %v, want %v", hunks, want)
+	}
+}
+
+func TestBuildConflictReport(t *testing.T) {
+	branches := []ConflictBranch{
+		{Branch: "polecat/ace", Worker: "ace"},
+		{Branch: "polecat/nux", Worker: "nux", MR: "gp-mr-1"},
+		{Branch: "polecat/toast", Worker: "toast"},
+		{Branch: "polecat/broken", Worker: "broken"}, // couldn't be diffed
+	}
+	changes := map[string]map[string][]git.Hunk{
+		"polecat/ace":   {"a.go": {{Start: 1, Lines: 5}}},
+		"polecat/nux":   {"a.go": {{Start: 3, Lines: 1}}, "b.go": {{Start: 1, Lines: 1}}},
+		"polecat/toast": {"b.go": {{Start: 50, Lines: 1}}},
+	}
+	targetChanges := map[string]map[string][]git.Hunk{
+		"polecat/ace":   {"a.go": {{Start: 100, Lines: 1}}},
+		"polecat/nux":   {},
+		"polecat/toast": {"b.go": {{Start: 51, Lines: 1}}},
+	}
+
+	r := &ConflictReport{Target: "origin/main", Branches: branches}
+	r.Overlaps = buildConflictReport(r.Target, branches, changes, targetChanges)
+	want := []Overlap{
+		{A: "polecat/ace", B: "origin/main", Level: OverlapFile, Files: []string{"a.go"}},
+		{A: "polecat/ace", B: "polecat/nux", Level: OverlapHunk, Files: []string{"a.go"}, Hunks: []string{"a.go"}},
+		{A: "polecat/nux", B: "polecat/toast", Level: OverlapFile, Files: []string{"b.go"}},
+		{A: "polecat/toast", B: "origin/main", Level: OverlapHunk, Files: []string{"b.go"}, Hunks: []string{"b.go"}},
+	}
+	if !reflect.DeepEqual(r.Overlaps, want) {
+		t.Fatalf("overlaps = %+v\nwant %+v", r.Overlaps, want)
+	}
+
+	if o := r.Between("polecat/nux", "polecat/ace"); o == nil || o.Level != OverlapHunk {
+		t.Errorf("Between(nux, ace) = %+v, want the hunk overlap", o)
+	}
+	if o := r.Between("polecat/ace", "polecat/toast"); o != nil {
+		t.Errorf("Between(ace, toast) = %+v, want nil", o)
+	}
+	// Overlaps with the target and file-only overlaps don't count.
+	for branch, want := range map[string]int{"polecat/ace": 1, "polecat/nux": 1, "polecat/toast": 0} {
+		if got := r.HunkOverlaps(branch); got != want {
+			t.Errorf("HunkOverlaps(%s) = %d, want %d", branch, got, want)
+		}
+	}
+}
+
+func TestApplyConflictReport(t *testing.T) {
+	rigPath := t.TempDir()
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	report := &ConflictReport{
+		Target:      "origin/main",
+		GeneratedAt: now.Add(-10 * time.Minute),
+		Overlaps: []Overlap{
+			{A: "polecat/ace", B: "polecat/nux", Level: OverlapHunk, Hunks: []string{"a.go"}},
+			{A: "polecat/ace", B: "polecat/toast", Level: OverlapHunk, Hunks: []string{"a.go"}},
+		},
+	}
+	if err := SaveConflictReport(rigPath, report); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []*QueueEntry{
+		{ID: "ace", Branch: "polecat/ace", Input: ScoreInput{Priority: 2, MRCreatedAt: now, Now: now}},
+		{ID: "nux", Branch: "polecat/nux", Input: ScoreInput{Priority: 2, MRCreatedAt: now, Now: now}},
+	}
+	ApplyConflictReport(rigPath, entries, now)
+	if entries[0].Input.Overlaps != 2 || entries[1].Input.Overlaps != 1 {
+		t.Fatalf("overlaps = %d, %d, want 2, 1", entries[0].Input.Overlaps, entries[1].Input.Overlaps)
+	}
+
+	// With a conflict penalty the less-conflicting MR lands first.
+	cfg := DefaultScoreConfig()
+	cfg.ConflictPenalty = 50
+	ordered := OrderQueue(entries, cfg)
+	if ordered[0].ID != "nux" {
+		t.Errorf("first = %s, want nux", ordered[0].ID)
+	}
+	if got := ordered[1].Score.Conflicts; got != 100 {
+		t.Errorf("ace conflicts = %v, want 100", got)
+	}
+
+	// A stale report is ignored.
+	stale := []*QueueEntry{{ID: "ace", Branch: "polecat/ace"}}
+	ApplyConflictReport(rigPath, stale, now.Add(ConflictReportMaxAge))
+	if stale[0].Input.Overlaps != 0 {
+		t.Errorf("stale report applied: overlaps = %d", stale[0].Input.Overlaps)
+	}
+}
@@ -2,22 +2,22 @@
 package refinery
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/convoy"
 	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
@@ -64,6 +64,33 @@ type MergeQueueConfig struct {
 	// TestCommand is the command to run for testing.
 	TestCommand string `json:"test_command"`
 
+	// SetupCommand, BuildCommand, TypecheckCommand and LintCommand form the
+	// default verification pipeline together with TestCommand.
+	SetupCommand     string `json:"setup_command"`
+	BuildCommand     string `json:"build_command"`
+	TypecheckCommand string `json:"typecheck_command"`
+	LintCommand      string `json:"lint_command"`
+
+	// VerifyStages overrides the default pipeline with an explicit ordered
+	// list of stages (see Pipeline).
+	VerifyStages []config.VerifyStage `json:"verify_stages"`
+
+	// VerifyCache skips verification of trees that already passed.
+	VerifyCache bool `json:"verify_cache"`
+
+	// Review is the code-review policy (nil or disabled: no review stage).
+	Review *config.ReviewPolicy `json:"review"`
+
+	// LandVia is "push" (merge and push to the target) or "pr" (land
+	// through a forge pull request).
+	LandVia string `json:"land_via"`
+
+	// PR is the pull-request landing policy (land_via = "pr").
+	PR *config.PRLandingPolicy `json:"pr"`
+
+	// Scoring holds the weights and fairness caps that order the queue.
+	Scoring ScoreConfig `json:"scoring"`
+
 	// DeleteMergedBranches controls whether to delete branches after merge.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
@@ -92,6 +119,9 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		OnConflict: "assign_back",
 		RunTests:                         true,
 		TestCommand:                      "",
+		VerifyCache:                      true,
+		LandVia:                          config.LandViaPush,
+		Scoring:                          DefaultScoreConfig(),
 		DeleteMergedBranches:             true,
 		RetryFlakyTests:                  1,
 		PollInterval:                     30 * time.Second,
@@ -117,6 +147,12 @@ type MRInfo struct {
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	PRNumber        int        // Forge pull request (land_via = "pr")
+	PRURL           string     // Forge pull request URL
+	Boost           int        // Score points added by gt mq bump
+	Pinned          bool       // Pinned ahead of the queue by gt mq pin
+	Hold            string     // Why gt mq hold keeps it out of the queue
+	Overlaps        int        // In-flight branches changing the same lines (gt mq conflicts)
 
 	// Raw data for agent-side queue health analysis (ZFC: agent decides, Go transports)
 	UpdatedAt          time.Time // When the MR was last updated
@@ -160,8 +196,9 @@ type Engineer struct {
 	git                   *git.Git
 	config                *MergeQueueConfig
 	workDir               string
-	output                io.Writer    // Output destination for user-facing messages
-	router                *mail.Router // Mail router for sending protocol messages
+	output                io.Writer      // Output destination for user-facing messages
+	router                *mail.Router   // Mail router for sending protocol messages
+	forge                 forge.Provider // Forge for land_via = "pr", resolved on first use
 	mergeSlotEnsureExists func() (string, error)
 	mergeSlotAcquire      func(holder string, addWaiter bool) (*beads.MergeSlotStatus, error)
 	mergeSlotRelease      func(holder string) error
@@ -242,6 +279,16 @@ func (e *Engineer) LoadConfig() error {
 		OnConflict *string `json:"on_conflict"`
 		RunTests                         *bool   `json:"run_tests"`
 		TestCommand                      *string `json:"test_command"`
+		SetupCommand                     *string `json:"setup_command"`
+		BuildCommand                     *string `json:"build_command"`
+		TypecheckCommand                 *string `json:"typecheck_command"`
+		LintCommand                      *string `json:"lint_command"`
+		VerifyStages                     []config.VerifyStage `json:"verify_stages"`
+		VerifyCache                      *bool   `json:"verify_cache"`
+		Review                           *config.ReviewPolicy `json:"review"`
+		LandVia                          *string `json:"land_via"`
+		PR                               *config.PRLandingPolicy `json:"pr"`
+		Scoring                          json.RawMessage `json:"scoring"`
 		DeleteMergedBranches             *bool   `json:"delete_merged_branches"`
 		RetryFlakyTests                  *int    `json:"retry_flaky_tests"`
 		PollInterval                     *string `json:"poll_interval"`
@@ -266,6 +313,57 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.TestCommand != nil {
 		e.config.TestCommand = *mqRaw.TestCommand
 	}
+	if mqRaw.SetupCommand != nil {
+		e.config.SetupCommand = *mqRaw.SetupCommand
+	}
+	if mqRaw.BuildCommand != nil {
+		e.config.BuildCommand = *mqRaw.BuildCommand
+	}
+	if mqRaw.TypecheckCommand != nil {
+		e.config.TypecheckCommand = *mqRaw.TypecheckCommand
+	}
+	if mqRaw.LintCommand != nil {
+		e.config.LintCommand = *mqRaw.LintCommand
+	}
+	if mqRaw.VerifyStages != nil {
+		if err := config.ValidateVerifyStages(mqRaw.VerifyStages); err != nil {
+			return err
+		}
+		e.config.VerifyStages = mqRaw.VerifyStages
+	}
+	if mqRaw.VerifyCache != nil {
+		e.config.VerifyCache = *mqRaw.VerifyCache
+	}
+	if mqRaw.Review != nil {
+		if err := config.ValidateReviewPolicy(mqRaw.Review); err != nil {
+			return err
+		}
+		e.config.Review = mqRaw.Review
+	}
+	if mqRaw.LandVia != nil || mqRaw.PR != nil {
+		landVia := e.config.LandVia
+		if mqRaw.LandVia != nil {
+			landVia = *mqRaw.LandVia
+		}
+		if err := config.ValidateLanding(landVia, mqRaw.PR); err != nil {
+			return err
+		}
+		e.config.LandVia = landVia
+		if mqRaw.PR != nil {
+			e.config.PR = mqRaw.PR
+		}
+	}
+	if mqRaw.Scoring != nil {
+		// Weights left out keep their defaults
+		scoring := e.config.Scoring
+		if err := json.Unmarshal(mqRaw.Scoring, &scoring); err != nil {
+			return fmt.Errorf("parsing merge_queue.scoring: %w", err)
+		}
+		if err := scoring.Validate(); err != nil {
+			return err
+		}
+		e.config.Scoring = scoring
+	}
 	if mqRaw.DeleteMergedBranches != nil {
 		e.config.DeleteMergedBranches = *mqRaw.DeleteMergedBranches
 	}
@@ -308,11 +406,24 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
-	SlotTimeout bool // Merge slot contention timeout (distinct from build/test failure)
+	SlotTimeout bool          // Merge slot contention timeout (distinct from build/test failure)
+	Verify      *VerifyResult // Pre-merge verification outcome (nil if not run)
+	// ReviewPending means the review policy has not approved the branch head
+	// yet (review requested, in progress, or sent back for rework).
+	ReviewPending bool
+	// PR is the MR's pull request when the rig lands via PR.
+	PR *forge.PullRequest
+	// PRPending means the PR is waiting on checks, approvals, the worker or
+	// the forge; the MR stays in the queue without notifications.
+	PRPending bool
+	// PRFailure is the MERGE_FAILED failure type of a PR that can't land
+	// (checks, review, closed).
+	PRFailure string
 }
 
-// doMerge performs the actual git merge operation.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+// doMerge performs the actual git merge operation. mrID names the
+// verification run (its stage logs are stored under that name).
+func (e *Engineer) doMerge(ctx context.Context, mrID, branch, target, sourceIssue string) ProcessResult {
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -392,21 +503,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Pushed %d submodule(s)\n", len(subChanges))
 	}
 
-	// Step 4: Run tests if configured
-	if e.config.RunTests && e.config.TestCommand != "" {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
-		result := e.runTests(ctx)
-		if !result.Success {
-			return ProcessResult{
-				Success:     false,
-				TestsFailed: true,
-				Error:       result.Error,
-			}
-		}
-		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
-	}
-
-	// Step 5: Perform the actual merge using squash merge
+	// Step 4: Perform the actual merge using squash merge
 	// Get the original commit message from the polecat branch to preserve the
 	// conventional commit format (feat:/fix:) instead of creating redundant merge commits
 	originalMsg, err := e.git.GetBranchCommitMessage(branch)
@@ -437,6 +534,25 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
+	// Step 5: Run the verification pipeline against the merged tree, so what
+	// is verified is exactly what gets pushed.
+	verifyResult := e.verify(ctx, mrID)
+	if !verifyResult.Success {
+		if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reset %s after verification failure: %v\n", target, resetErr)
+		}
+		result := ProcessResult{
+			Success: false,
+			Error:   "verification failed",
+			Verify:  verifyResult,
+		}
+		if f := verifyResult.Failed; f != nil {
+			result.Error = f.Error
+			result.TestsFailed = f.Name == config.VerifyStageTest
+		}
+		return result
+	}
+
 	// Step 6: Get the merge commit SHA
 	mergeCommit, err := e.git.Rev("HEAD")
 	if err != nil {
@@ -497,6 +613,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	return ProcessResult{
 		Success:     true,
 		MergeCommit: mergeCommit,
+		Verify:      verifyResult,
 	}
 }
 
@@ -562,7 +679,9 @@ func ValidateTestCommand(cmd string) error {
 	return nil
 }
 
-// runTests runs the configured test command and returns the result.
+// runTests runs only the configured test command, as a test stage with
+// flaky-test retries, and returns the result. doMerge runs the full pipeline
+// via verify.
 func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 	if err := ValidateTestCommand(e.config.TestCommand); err != nil {
 		return ProcessResult{
@@ -571,48 +690,18 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 		}
 	}
 
-	// Run the test command with retries for flaky tests
-	maxRetries := e.config.RetryFlakyTests
-	if maxRetries < 1 {
-		maxRetries = 1
-	}
-
-	var lastErr error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
-		}
-
-		// Trust boundary: TestCommand comes from rig's config.json (operator-controlled
-		// infrastructure config), not from PR branches or user input. Shell execution
-		// is intentional for flexibility (pipes, env vars, etc).
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Executing test command: %s\n", e.config.TestCommand)
-		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		err := cmd.Run()
-		if err == nil {
-			return ProcessResult{Success: true}
-		}
-		lastErr = err
-
-		// Check if context was canceled
-		if ctx.Err() != nil {
-			return ProcessResult{
-				Success: false,
-				Error:   "test run canceled",
-			}
+	stage := e.runStage(ctx, config.VerifyStage{Name: config.VerifyStageTest, Command: e.config.TestCommand}, "", "")
+	result := &VerifyResult{Success: stage.Success, Stages: []StageResult{stage}}
+	if !stage.Success {
+		result.Failed = &result.Stages[0]
+		return ProcessResult{
+			Success:     false,
+			TestsFailed: true,
+			Error:       stage.Error,
+			Verify:      result,
 		}
 	}
-
-	return ProcessResult{
-		Success:     false,
-		TestsFailed: true,
-		Error:       fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr),
-	}
+	return ProcessResult{Success: true, Verify: result}
 }
 
 // syncCrewWorkspaces pulls latest changes to all crew workspaces.
@@ -656,8 +745,24 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mr.Worker)
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
+	// Review gate: only approved branch heads merge when the rig has a
+	// review policy.
+	gate, err := e.ReviewGate(mr)
+	if err != nil {
+		return ProcessResult{ReviewPending: true, Error: fmt.Sprintf("review gate: %v", err)}
+	}
+	if !gate.Approved {
+		return ProcessResult{ReviewPending: true, Error: gate.Message}
+	}
+
+	// Rigs whose branch protection forbids direct pushes land through the
+	// forge instead; its required checks stand in for local verification.
+	if e.config.LandVia == config.LandViaPR {
+		return e.landViaPR(ctx, mr)
+	}
+
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.doMerge(ctx, mr.ID, mr.Branch, mr.Target, mr.SourceIssue)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -687,6 +792,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 			}
 			mrFields.MergeCommit = result.MergeCommit
 			mrFields.CloseReason = "merged"
+			setVerifyFields(mrFields, result.Verify)
 			newDesc := beads.SetMRFields(mrBead, mrFields)
 			if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
@@ -724,13 +830,24 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
-	// 2. Delete source branch if configured (local only)
+	// 2. Delete source branch if configured (local only, plus the copy
+	// pushed for the PR)
 	if e.config.DeleteMergedBranches && mr.Branch != "" {
 		if err := e.git.DeleteBranch(mr.Branch, true); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to delete branch %s: %v\n", mr.Branch, err)
 		} else {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Deleted local branch: %s\n", mr.Branch)
 		}
+		if result.PR != nil {
+			if err := e.git.DeleteRemoteBranch("origin", mr.Branch); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to delete origin/%s: %v\n", mr.Branch, err)
+			}
+		}
+	}
+
+	// 2.5. PR landing happens inside gt, so notify the Witness here
+	if result.PR != nil {
+		e.sendMerged(mr, result.MergeCommit)
 	}
 
 	// 3. Log success
@@ -740,8 +857,18 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 // HandleMRInfoFailure handles a failed merge from MRInfo.
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // For slot timeouts, the MR stays in queue for automatic retry without notifying polecats.
+// MRs waiting on review stay in queue too; the review gate does its own notifying.
 // This enables non-blocking delegation: the queue continues to the next MR.
 func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
+	if result.ReviewPending {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ⏸ Awaiting review: %s - %s\n", mr.ID, result.Error)
+		return
+	}
+	if result.PRPending {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ⏸ Awaiting PR: %s - %s\n", mr.ID, result.Error)
+		return
+	}
+
 	// Slot timeout is transient infrastructure contention — not a build/test/conflict failure.
 	// The MR stays in queue and will be retried on the next poll cycle.
 	// No polecat notification needed since there's nothing for a worker to fix.
@@ -758,14 +885,51 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		failureType = "conflict"
 	} else if result.TestsFailed {
 		failureType = "tests"
+	} else if result.PRFailure != "" {
+		failureType = result.PRFailure
+	} else if result.Verify != nil && result.Verify.Failed != nil {
+		failureType = result.Verify.Failed.Name
+	}
+	payload := protocol.MergeFailedPayload{
+		Branch:       mr.Branch,
+		Issue:        mr.SourceIssue,
+		Polecat:      mr.Worker,
+		Rig:          e.rig.Name,
+		FailureType:  failureType,
+		Error:        result.Error,
+		TargetBranch: mr.Target,
+	}
+	if result.Verify != nil && result.Verify.Failed != nil {
+		payload.Stage = result.Verify.Failed.Name
+		payload.LogPath = result.Verify.Failed.LogPath
+		payload.Output = result.Verify.Failed.Output
+	}
+	if result.PR != nil {
+		payload.Error += "\n" + result.PR.URL
+	}
+
+	// Record the verification outcome on the MR bead so the stage logs can
+	// be found from it.
+	if mr.ID != "" && result.Verify != nil {
+		e.recordVerifyResult(mr.ID, result.Verify)
 	}
-	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
+
+	msg := protocol.NewMergeFailedMessageFromPayload(payload)
 	if err := e.router.Send(msg); err != nil {
 		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
 	} else {
 		fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
 	}
 
+	// A PR closed without merging rejects the MR.
+	if result.PRFailure == PRFailureClosed {
+		if err := e.beads.CloseWithReason(string(CloseReasonRejected)+": "+result.Error, mr.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close MR %s: %v\n", mr.ID, err)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Rejected: %s - %s\n", mr.ID, result.Error)
+		return
+	}
+
 	// If this was a conflict, create a conflict-resolution task for dispatch
 	// and block the MR until the task is resolved (non-blocking delegation)
 	if result.Conflict {
@@ -792,6 +956,36 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// recordVerifyResult stores the verification outcome on the MR bead.
+func (e *Engineer) recordVerifyResult(mrID string, v *VerifyResult) {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mrID, err)
+		return
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	setVerifyFields(mrFields, v)
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record verification on MR %s: %v\n", mrID, err)
+	}
+}
+
+// setVerifyFields copies a verification outcome into MR fields. A cached
+// pass keeps the log of the run that verified the tree.
+func setVerifyFields(fields *beads.MRFields, v *VerifyResult) {
+	if v == nil || (v.Success && !v.Cached && len(v.Stages) == 0) {
+		return // Nothing was verified
+	}
+	fields.VerifyResult = v.Summary()
+	if v.LogDir != "" {
+		fields.VerifyLog = v.LogDir
+	}
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
@@ -972,7 +1166,25 @@ func issueToMRInfo(issue *beads.Issue, fields *beads.MRFields) *MRInfo {
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Assignee:        issue.Assignee,
+		PRNumber:        fields.PRNumber,
+		PRURL:           fields.PRURL,
+		Boost:           fields.Boost,
+		Pinned:          fields.Pinned,
+		Hold:            fields.Hold,
+	}
+}
+
+// GetMRInfo fetches a single merge request by bead ID.
+func (e *Engineer) GetMRInfo(mrID string) (*MRInfo, error) {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil || !beads.HasLabel(issue, "gt:merge-request") {
+		return nil, ErrMRNotFound
 	}
+	return issueToMRInfo(issue, fields), nil
 }
 
 // firstOpenBlocker returns the ID of the first open blocker for an issue,
@@ -990,7 +1202,8 @@ func (e *Engineer) firstOpenBlocker(issue *beads.Issue) string {
 // ListReadyMRs returns MRs that are ready for processing:
 // - Not claimed by another worker (checked via assignee field)
 // - Not blocked by an open task (handled by bd ready)
-// Sorted by priority (highest first).
+// - Not held (gt mq hold)
+// In processing order (see OrderQueue).
 //
 // This queries beads for merge-request wisps.
 func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
@@ -1037,12 +1250,31 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 				issue.ID, issue.Assignee, issue.UpdatedAt)
 		}
 
+		if fields.Hold != "" {
+			continue
+		}
+
 		mrs = append(mrs, issueToMRInfo(issue, fields))
 	}
 
+	now := time.Now()
+	e.applyConflictReport(mrs, now)
+	mrs, _ = OrderMRs(mrs, e.config.Scoring, now)
 	return mrs, nil
 }
 
+// applyConflictReport sets each MR's overlap count from the rig's latest
+// conflict report, if it is recent enough.
+func (e *Engineer) applyConflictReport(mrs []*MRInfo, now time.Time) {
+	report := recentConflictReport(e.rig.Path, now)
+	if report == nil {
+		return
+	}
+	for _, mr := range mrs {
+		mr.Overlaps = report.HunkOverlaps(mr.Branch)
+	}
+}
+
 // ListBlockedMRs returns MRs that are blocked by open tasks.
 // Useful for monitoring/reporting.
 //
@@ -1121,6 +1353,7 @@ func (e *Engineer) ListAllOpenMRs() ([]*MRInfo, error) {
 		mrs = append(mrs, mr)
 	}
 
+	e.applyConflictReport(mrs, time.Now())
 	return mrs, nil
 }
 
@@ -1204,6 +1437,34 @@ func detectQueueAnomalies(
 	return anomalies
 }
 
+// PreviewMR returns what merging an MR would land on its target: the
+// branch's commits and a diffstat. The branch is read from origin when it
+// is not present locally.
+func (e *Engineer) PreviewMR(mr *MRInfo) (string, error) {
+	branch := mr.Branch
+	if exists, _ := e.git.BranchExists(branch); !exists {
+		branch = "origin/" + branch
+	}
+	target := mr.Target
+	if target == "" {
+		target = e.rig.DefaultBranch()
+	}
+	target = "origin/" + target
+
+	log, err := e.git.LogOneline(target, branch, 20)
+	if err != nil {
+		return "", fmt.Errorf("reading log of %s: %w", branch, err)
+	}
+	stat, err := e.git.DiffStat(target, branch)
+	if err != nil {
+		return "", fmt.Errorf("reading diff of %s: %w", branch, err)
+	}
+	if log == "" {
+		log = "(no commits ahead of " + target + ")"
+	}
+	return log + "\n\n" + stat, nil
+}
+
 // ClaimMR claims an MR for processing by setting the assignee field.
 // This replaces mrqueue.Claim() for beads-based MRs.
 // The workerID is typically the refinery's identifier (e.g., "gastown/refinery").
@@ -230,6 +230,36 @@ func TestEngineer_LoadConfig_InvalidStaleClaimTimeout(t *testing.T) {
 	}
 }
 
+func TestEngineer_LoadConfig_Scoring(t *testing.T) {
+	tmpDir := t.TempDir()
+	r := &rig.Rig{Name: "test-rig", Path: tmpDir}
+	write := func(scoring map[string]interface{}) {
+		data, _ := json.Marshal(map[string]interface{}{
+			"merge_queue": map[string]interface{}{"scoring": scoring},
+		})
+		if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(map[string]interface{}{"priority_weight": 50, "convoy_cap": 2})
+	e := NewEngineer(r)
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := DefaultScoreConfig()
+	want.PriorityWeight = 50
+	want.ConvoyCap = 2
+	if e.config.Scoring != want {
+		t.Errorf("Scoring = %+v, want %+v", e.config.Scoring, want)
+	}
+
+	write(map[string]interface{}{"author_cap": -1})
+	if err := NewEngineer(r).LoadConfig(); err == nil {
+		t.Error("expected error for negative author_cap")
+	}
+}
+
 func TestNewEngineer(t *testing.T) {
 	r := &rig.Rig{
 		Name: "test-rig",
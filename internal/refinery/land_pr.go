@@ -0,0 +1,323 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// prOutcome is what a landing pass found on an MR's pull request.
+type prOutcome string
+
+const (
+	prWaiting          prOutcome = "waiting"           // Checks, approvals or the head update pending
+	prReady            prOutcome = "ready"             // Required checks and approvals passed
+	prMerged           prOutcome = "merged"            // Merged on the forge
+	prClosed           prOutcome = "closed"            // Closed without merging
+	prConflict         prOutcome = "conflict"          // Conflicts with the base branch
+	prChecksFailed     prOutcome = "checks_failed"     // A required check failed
+	prChangesRequested prOutcome = "changes_requested" // A reviewer requested changes
+)
+
+// PR landing failure types reported in MERGE_FAILED.
+const (
+	PRFailureChecks = "checks"
+	PRFailureReview = "review"
+	PRFailureClosed = "closed"
+)
+
+// evaluatePR decides where a pull request stands against the landing
+// policy. head is the MR branch head the Refinery pushed; a PR whose head
+// hasn't caught up yet is still waiting.
+func evaluatePR(pr *forge.PullRequest, policy *config.PRLandingPolicy, head string) (prOutcome, string) {
+	switch pr.State {
+	case forge.PRMerged:
+		return prMerged, fmt.Sprintf("PR #%d merged", pr.Number)
+	case forge.PRClosed:
+		return prClosed, fmt.Sprintf("PR #%d was closed without merging", pr.Number)
+	}
+	if head != "" && pr.HeadSHA != head {
+		return prWaiting, fmt.Sprintf("waiting for PR #%d to pick up %.8s", pr.Number, head)
+	}
+	if pr.Conflicting() {
+		return prConflict, fmt.Sprintf("PR #%d conflicts with its base branch", pr.Number)
+	}
+
+	// Checks: the required ones, or every reported check.
+	states := make(map[string]string, len(pr.Checks))
+	for _, c := range pr.Checks {
+		// A check reported more than once (e.g., re-run) counts as failed
+		// only if no run passed.
+		if states[c.Name] != forge.CheckSuccess {
+			states[c.Name] = c.State
+		}
+	}
+	var required []string
+	if policy != nil {
+		required = policy.RequiredChecks
+	}
+	if len(required) == 0 {
+		// A freshly opened PR has no checks yet; CI hasn't had a chance
+		// to report, so don't mistake that for a clean bill.
+		if len(pr.Checks) == 0 {
+			return prWaiting, fmt.Sprintf("PR #%d: waiting for checks to be reported", pr.Number)
+		}
+		for _, c := range pr.Checks {
+			required = append(required, c.Name)
+		}
+	}
+	var failed, pending []string
+	for _, name := range required {
+		switch states[name] {
+		case forge.CheckSuccess:
+		case forge.CheckFailure:
+			failed = append(failed, name)
+		default: // Pending, or not reported yet
+			pending = append(pending, name)
+		}
+	}
+	if len(failed) > 0 {
+		return prChecksFailed, fmt.Sprintf("PR #%d: required check(s) failed: %s", pr.Number, strings.Join(dedupe(failed), ", "))
+	}
+
+	if pr.ChangesRequested {
+		return prChangesRequested, fmt.Sprintf("PR #%d: a reviewer requested changes", pr.Number)
+	}
+	if len(pending) > 0 {
+		return prWaiting, fmt.Sprintf("PR #%d: waiting on check(s): %s", pr.Number, strings.Join(dedupe(pending), ", "))
+	}
+	if policy != nil && pr.Approvals < policy.RequiredApprovals {
+		return prWaiting, fmt.Sprintf("PR #%d: waiting for approvals (%d/%d)", pr.Number, pr.Approvals, policy.RequiredApprovals)
+	}
+	return prReady, fmt.Sprintf("PR #%d ready to merge", pr.Number)
+}
+
+// prTransition maps a PR outcome to the MR status it moves the MR to:
+// merged and closed PRs close the MR, failures send it back to the worker
+// (open), and anything else keeps it claimed by the Refinery (in_progress).
+func prTransition(o prOutcome) (MRStatus, CloseReason) {
+	switch o {
+	case prMerged:
+		return MRClosed, CloseReasonMerged
+	case prClosed:
+		return MRClosed, CloseReasonRejected
+	case prConflict, prChecksFailed, prChangesRequested:
+		return MROpen, ""
+	default:
+		return MRInProgress, ""
+	}
+}
+
+// mrStatus derives an MR's status from its bead: closed beads are closed,
+// claimed ones are in progress.
+func mrStatus(issue *beads.Issue) MRStatus {
+	switch {
+	case issue.Status == "closed":
+		return MRClosed
+	case issue.Assignee != "":
+		return MRInProgress
+	default:
+		return MROpen
+	}
+}
+
+// syncPR opens the MR's pull request, or updates the one recorded on the MR
+// (number) or found for its branch, then evaluates it and merges it when it
+// is ready.
+func syncPR(ctx context.Context, f forge.Provider, number int, req forge.PRRequest, head string, policy *config.PRLandingPolicy) (*forge.PullRequest, prOutcome, string, error) {
+	var pr *forge.PullRequest
+	var err error
+	if number > 0 {
+		pr, err = f.GetPR(ctx, number)
+	} else {
+		pr, err = f.FindPR(ctx, req.Head, req.Base)
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	switch {
+	case pr == nil:
+		if pr, err = f.CreatePR(ctx, req); err != nil {
+			return nil, "", "", fmt.Errorf("opening PR: %w", err)
+		}
+	case pr.State == forge.PROpen && (pr.Title != req.Title || pr.Body != req.Body):
+		if err := f.UpdatePR(ctx, pr.Number, req); err != nil {
+			return nil, "", "", fmt.Errorf("updating PR #%d: %w", pr.Number, err)
+		}
+		pr.Title, pr.Body = req.Title, req.Body
+	}
+
+	outcome, msg := evaluatePR(pr, policy, head)
+	if outcome != prReady {
+		return pr, outcome, msg, nil
+	}
+
+	if err := f.MergePR(ctx, pr.Number, policy.Method(), pr.HeadSHA); err != nil {
+		// The forge may refuse for reasons we can't see (branch protection
+		// rules, a head that moved); try again next pass.
+		return pr, prWaiting, fmt.Sprintf("PR #%d: merge refused, will retry: %v", pr.Number, err), nil
+	}
+	if merged, err := f.GetPR(ctx, pr.Number); err == nil {
+		pr = merged
+	} else {
+		pr.State = forge.PRMerged
+	}
+	return pr, prMerged, fmt.Sprintf("PR #%d merged", pr.Number), nil
+}
+
+// landViaPR lands an MR through a forge pull request: it publishes the
+// branch, opens or updates the MR's PR, and merges it through the forge once
+// the required checks and approvals pass. One pass never blocks; MRs whose
+// PR is still waiting come back as PRPending and stay claimed.
+func (e *Engineer) landViaPR(ctx context.Context, mr *MRInfo) ProcessResult {
+	if e.forge == nil {
+		f, err := forge.ForURL(filepath.Dir(e.rig.Path), e.rig.GitURL)
+		if err != nil {
+			return ProcessResult{PRPending: true, Error: fmt.Sprintf("forge: %v", err)}
+		}
+		e.forge = f
+	}
+	mrBead, fields, err := e.showMR(mr.ID)
+	if err != nil {
+		return ProcessResult{PRPending: true, Error: err.Error()}
+	}
+	from := mrStatus(mrBead)
+
+	head, err := e.branchHead(mr.Branch)
+	if err != nil {
+		return ProcessResult{PRPending: true, Error: err.Error()}
+	}
+	if err := e.publishBranch(mr.Branch, head); err != nil {
+		return ProcessResult{PRPending: true, Error: err.Error()}
+	}
+
+	pr, outcome, msg, err := syncPR(ctx, e.forge, fields.PRNumber, e.prRequest(mr), head, e.config.PR)
+	if err != nil {
+		return ProcessResult{PRPending: true, Error: fmt.Sprintf("forge: %v", err)}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] %s: %s\n", mr.ID, msg)
+
+	to, reason := prTransition(outcome)
+	if err := ValidateTransition(from, to); err != nil {
+		return ProcessResult{PR: pr, PRPending: true, Error: fmt.Sprintf("%s: %v", msg, err)}
+	}
+
+	// A failure already reported for this head waits for the worker's fix
+	// instead of being reported again every pass.
+	reported := to == MROpen && fields.PRStatus == string(outcome) && fields.PRSHA == pr.HeadSHA
+
+	fields.PRNumber, fields.PRURL = pr.Number, pr.URL
+	fields.PRStatus, fields.PRSHA = string(outcome), pr.HeadSHA
+	if err := e.updateMRFields(mrBead, fields); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+	}
+
+	result := ProcessResult{PR: pr, Error: msg}
+	switch to {
+	case MRClosed:
+		if reason == CloseReasonMerged {
+			return ProcessResult{Success: true, MergeCommit: pr.MergeCommit, PR: pr}
+		}
+		result.PRFailure = PRFailureClosed
+
+	case MROpen:
+		if reported {
+			result.PRPending = true
+			result.Error = fmt.Sprintf("waiting for %s to fix: %s", mr.Worker, msg)
+			return result
+		}
+		switch outcome {
+		case prConflict:
+			result.Conflict = true
+		case prChecksFailed:
+			result.PRFailure = PRFailureChecks
+		case prChangesRequested:
+			result.PRFailure = PRFailureReview
+		}
+		if err := e.ReleaseMR(mr.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release MR %s: %v\n", mr.ID, err)
+		}
+
+	default:
+		result.PRPending = true
+		if from == MROpen {
+			if err := e.ClaimMR(mr.ID, e.rig.Name+"/refinery"); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to claim MR %s: %v\n", mr.ID, err)
+			}
+		}
+	}
+	return result
+}
+
+// publishBranch pushes the MR branch to origin for its pull request, unless
+// origin already has head. The Refinery owns remote pushes; polecat branches
+// are local to the shared repo.
+func (e *Engineer) publishBranch(branch, head string) error {
+	if remote, err := e.git.Rev("origin/" + branch); err == nil && remote == head {
+		return nil
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing %s to origin for its PR...\n", branch)
+	if err := e.git.Push("origin", branch, true); err != nil {
+		return fmt.Errorf("pushing %s: %w", branch, err)
+	}
+	return nil
+}
+
+// prRequest builds the MR's pull request. The title is the branch's commit
+// subject, so squash merges keep the polecat's conventional commit message.
+func (e *Engineer) prRequest(mr *MRInfo) forge.PRRequest {
+	title, rest := mr.Title, ""
+	if msg, err := e.git.GetBranchCommitMessage(mr.Branch); err == nil && strings.TrimSpace(msg) != "" {
+		title, rest, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+		rest = strings.TrimSpace(rest)
+	}
+	if title == "" {
+		title = mr.Branch
+	}
+
+	var body strings.Builder
+	if rest != "" {
+		body.WriteString(rest)
+		body.WriteString("\n\n")
+	}
+	body.WriteString("Landed by the Gas Town Refinery.\n\n")
+	fmt.Fprintf(&body, "- MR: %s\n", mr.ID)
+	if mr.SourceIssue != "" {
+		fmt.Fprintf(&body, "- Issue: %s\n", mr.SourceIssue)
+	}
+	if mr.Worker != "" {
+		fmt.Fprintf(&body, "- Worker: %s\n", mr.Worker)
+	}
+	return forge.PRRequest{Head: mr.Branch, Base: mr.Target, Title: title, Body: body.String()}
+}
+
+// sendMerged tells the Witness an MR landed. In push mode the Refinery agent
+// sends MERGED itself after pushing; PR landing happens inside gt, so gt
+// sends it.
+func (e *Engineer) sendMerged(mr *MRInfo, mergeCommit string) {
+	msg := protocol.NewMergedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, mergeCommit)
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGED to witness: %v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Notified witness: MERGED %s\n", mr.Worker)
+	}
+}
+
+func dedupe(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := names[:0]
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package refinery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
+)
+
+func newFakeForge() *forge.Fake {
+	return forge.NewFake(forge.Repo{Host: "forge.example", Owner: "acme", Name: "widgets"})
+}
+
+func testPRRequest() forge.PRRequest {
+	return forge.PRRequest{Head: "polecat/Toast/gt-abc", Base: "main", Title: "feat: widgets", Body: "head=polecat/Toast/gt-abc"}
+}
+
+func TestSyncPR_OpensWaitsAndMerges(t *testing.T) {
+	f := newFakeForge()
+	ctx := context.Background()
+	policy := &config.PRLandingPolicy{RequiredChecks: []string{"ci/test"}, RequiredApprovals: 1}
+	req := testPRRequest()
+
+	// First pass opens the PR; the forge hasn't reported the head yet.
+	pr, outcome, _, err := syncPR(ctx, f, 0, req, "aaa", policy)
+	if err != nil {
+		t.Fatalf("syncPR: %v", err)
+	}
+	if pr.Number != 1 || outcome != prWaiting {
+		t.Fatalf("first pass = #%d %s, want #1 waiting", pr.Number, outcome)
+	}
+
+	// Head reported, required check pending (an unrelated check failing
+	// doesn't matter).
+	f.PRs[1].HeadSHA = "aaa"
+	f.PRs[1].Checks = []forge.Check{{Name: "ci/test", State: forge.CheckPending}, {Name: "lint-advisory", State: forge.CheckFailure}}
+	_, outcome, msg, _ := syncPR(ctx, f, 1, req, "aaa", policy)
+	if outcome != prWaiting || !strings.Contains(msg, "ci/test") {
+		t.Errorf("pending check = %s %q, want waiting on ci/test", outcome, msg)
+	}
+
+	// Check passes, approval missing.
+	f.PRs[1].Checks[0].State = forge.CheckSuccess
+	if _, outcome, msg, _ = syncPR(ctx, f, 1, req, "aaa", policy); outcome != prWaiting || !strings.Contains(msg, "0/1") {
+		t.Errorf("no approval = %s %q, want waiting for approvals", outcome, msg)
+	}
+
+	// Approved: merged through the forge, guarded by the head.
+	f.PRs[1].Approvals = 1
+	pr, outcome, _, err = syncPR(ctx, f, 1, req, "aaa", policy)
+	if err != nil || outcome != prMerged {
+		t.Fatalf("ready pass = %s, %v; want merged", outcome, err)
+	}
+	if len(f.Merged) != 1 || f.Merged[0] != "1:squash:aaa" {
+		t.Errorf("merges = %v, want [1:squash:aaa]", f.Merged)
+	}
+	if pr.MergeCommit != "c0ffee" {
+		t.Errorf("MergeCommit = %q, want c0ffee", pr.MergeCommit)
+	}
+	if len(f.PRs) != 1 {
+		t.Errorf("opened %d PRs, want 1", len(f.PRs))
+	}
+}
+
+func TestSyncPR_AdoptsAndUpdatesExistingPR(t *testing.T) {
+	f := newFakeForge()
+	ctx := context.Background()
+	req := testPRRequest()
+	existing, _ := f.CreatePR(ctx, forge.PRRequest{Head: req.Head, Base: req.Base, Title: "wip", Body: req.Body})
+
+	req.Title = "feat: widgets v2"
+	pr, _, _, err := syncPR(ctx, f, 0, req, "", nil)
+	if err != nil {
+		t.Fatalf("syncPR: %v", err)
+	}
+	if pr.Number != existing.Number || len(f.PRs) != 1 {
+		t.Errorf("opened a new PR instead of adopting #%d", existing.Number)
+	}
+	if f.Updates != 1 || f.PRs[existing.Number].Title != req.Title {
+		t.Errorf("PR not updated: updates=%d title=%q", f.Updates, f.PRs[existing.Number].Title)
+	}
+
+	// Unchanged title and body: no update.
+	if _, _, _, err := syncPR(ctx, f, pr.Number, req, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if f.Updates != 1 {
+		t.Errorf("updates = %d, want 1", f.Updates)
+	}
+}
+
+func TestSyncPR_MergeRefusedRetries(t *testing.T) {
+	f := newFakeForge()
+	ctx := context.Background()
+	f.MergeErr = errors.New("base branch policy prohibits the merge")
+	req := testPRRequest()
+	pr, _ := f.CreatePR(ctx, req)
+	f.PRs[pr.Number].HeadSHA = "aaa"
+	f.PRs[pr.Number].Checks = []forge.Check{{Name: "ci/test", State: forge.CheckSuccess}}
+
+	_, outcome, msg, err := syncPR(ctx, f, pr.Number, req, "aaa", nil)
+	if err != nil {
+		t.Fatalf("syncPR: %v", err)
+	}
+	if outcome != prWaiting || !strings.Contains(msg, "will retry") {
+		t.Errorf("refused merge = %s %q, want waiting", outcome, msg)
+	}
+}
+
+func TestSyncPR_NoChecksReportedWaits(t *testing.T) {
+	f := newFakeForge()
+	ctx := context.Background()
+	req := testPRRequest()
+
+	// No required checks configured and none reported yet: opening the PR
+	// must not merge it before CI starts.
+	pr, outcome, msg, err := syncPR(ctx, f, 0, req, "", &config.PRLandingPolicy{})
+	if err != nil {
+		t.Fatalf("syncPR: %v", err)
+	}
+	if outcome != prWaiting || !strings.Contains(msg, "checks to be reported") {
+		t.Errorf("fresh PR = %s %q, want waiting for checks", outcome, msg)
+	}
+	if len(f.Merged) != 0 {
+		t.Errorf("merged a PR with no checks: %v", f.Merged)
+	}
+
+	// Once CI reports and passes, the PR lands.
+	f.PRs[pr.Number].Checks = []forge.Check{{Name: "ci/test", State: forge.CheckSuccess}}
+	if _, outcome, _, err = syncPR(ctx, f, pr.Number, req, "", &config.PRLandingPolicy{}); err != nil || outcome != prMerged {
+		t.Errorf("after checks pass = %s, %v; want merged", outcome, err)
+	}
+}
+
+func TestEvaluatePR(t *testing.T) {
+	policy := &config.PRLandingPolicy{RequiredApprovals: 1}
+	base := func() *forge.PullRequest {
+		return &forge.PullRequest{Number: 7, State: forge.PROpen, HeadSHA: "aaa", Approvals: 1,
+			Checks: []forge.Check{{Name: "build", State: forge.CheckSuccess}, {Name: "test", State: forge.CheckSuccess}}}
+	}
+	tests := []struct {
+		name   string
+		modify func(*forge.PullRequest)
+		head   string
+		want   prOutcome
+	}{
+		{"ready", func(*forge.PullRequest) {}, "aaa", prReady},
+		{"merged elsewhere", func(p *forge.PullRequest) { p.State = forge.PRMerged }, "aaa", prMerged},
+		{"closed", func(p *forge.PullRequest) { p.State = forge.PRClosed }, "aaa", prClosed},
+		{"stale head", func(*forge.PullRequest) {}, "bbb", prWaiting},
+		{"conflict", func(p *forge.PullRequest) { p.Mergeable = forge.MergeConflict }, "aaa", prConflict},
+		{"check failed", func(p *forge.PullRequest) { p.Checks[1].State = forge.CheckFailure }, "aaa", prChecksFailed},
+		{"rerun passed", func(p *forge.PullRequest) {
+			p.Checks = append(p.Checks, forge.Check{Name: "test", State: forge.CheckFailure})
+		}, "aaa", prReady},
+		{"check pending", func(p *forge.PullRequest) { p.Checks[0].State = forge.CheckPending }, "aaa", prWaiting},
+		{"changes requested", func(p *forge.PullRequest) { p.ChangesRequested = true }, "aaa", prChangesRequested},
+		{"needs approval", func(p *forge.PullRequest) { p.Approvals = 0 }, "aaa", prWaiting},
+		{"no checks reported", func(p *forge.PullRequest) { p.Checks = nil }, "aaa", prWaiting},
+	}
+	for _, tt := range tests {
+		pr := base()
+		tt.modify(pr)
+		if got, msg := evaluatePR(pr, policy, tt.head); got != tt.want {
+			t.Errorf("%s: evaluatePR = %s (%s), want %s", tt.name, got, msg, tt.want)
+		}
+	}
+
+	// A required check that was never reported is still pending.
+	pr := base()
+	if got, _ := evaluatePR(pr, &config.PRLandingPolicy{RequiredChecks: []string{"e2e"}}, "aaa"); got != prWaiting {
+		t.Errorf("unreported required check = %s, want waiting", got)
+	}
+}
+
+func TestPRTransition(t *testing.T) {
+	tests := []struct {
+		outcome    prOutcome
+		wantStatus MRStatus
+		wantReason CloseReason
+	}{
+		{prWaiting, MRInProgress, ""},
+		{prMerged, MRClosed, CloseReasonMerged},
+		{prClosed, MRClosed, CloseReasonRejected},
+		{prConflict, MROpen, ""},
+		{prChecksFailed, MROpen, ""},
+		{prChangesRequested, MROpen, ""},
+	}
+	for _, tt := range tests {
+		status, reason := prTransition(tt.outcome)
+		if status != tt.wantStatus || reason != tt.wantReason {
+			t.Errorf("prTransition(%s) = %s/%s, want %s/%s", tt.outcome, status, reason, tt.wantStatus, tt.wantReason)
+		}
+		// Every outcome is a valid move for a claimed MR...
+		if err := ValidateTransition(MRInProgress, status); err != nil {
+			t.Errorf("prTransition(%s): in_progress → %s: %v", tt.outcome, status, err)
+		}
+		// ...and none may reopen a closed one.
+		if status != MRClosed {
+			if err := ValidateTransition(MRClosed, status); err == nil {
+				t.Errorf("prTransition(%s): closed → %s allowed", tt.outcome, status)
+			}
+		}
+	}
+}
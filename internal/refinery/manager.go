@@ -7,7 +7,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -212,10 +211,18 @@ func (m *Manager) Stop() error {
 	return t.KillSession(sessionID)
 }
 
-// Queue returns the current merge queue.
+// Queue returns the current merge queue, in the order the Refinery will
+// process it. Held MRs ('gt mq hold') are left out, since they won't be
+// processed until released.
 // Uses beads merge-request issues as the source of truth (not git branches).
 // ZFC-compliant: beads is the source of truth, no state file.
 func (m *Manager) Queue() ([]QueueItem, error) {
+	return m.queue(false)
+}
+
+// queue lists the open MRs in processing order. Held MRs are included, after
+// the rest and without a position, only when includeHeld is set.
+func (m *Manager) queue(includeHeld bool) ([]QueueItem, error) {
 	// Query beads for open merge-request issues
 	// BeadsPath() returns the git-synced beads location
 	b := beads.New(m.rig.BeadsPath())
@@ -228,31 +235,37 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 		return nil, fmt.Errorf("querying merge queue from beads: %w", err)
 	}
 
-	// Score and sort issues by priority score (highest first)
-	now := time.Now()
-	type scoredIssue struct {
-		issue *beads.Issue
-		score float64
+	// Order issues as the Refinery will process them, with the rig's
+	// scoring weights
+	scoring := DefaultScoreConfig()
+	eng := NewEngineer(m.rig)
+	if err := eng.LoadConfig(); err == nil {
+		scoring = eng.Config().Scoring
 	}
-	scored := make([]scoredIssue, 0, len(issues))
+	now := time.Now()
+	byID := make(map[string]*beads.Issue, len(issues))
+	entries := make([]*QueueEntry, 0, len(issues))
 	for _, issue := range issues {
 		// Defensive filter: bd status filters can drift; queue must only include open MRs.
 		if issue == nil || issue.Status != "open" {
 			continue
 		}
-		score := m.calculateIssueScore(issue, now)
-		scored = append(scored, scoredIssue{issue: issue, score: score})
+		byID[issue.ID] = issue
+		entries = append(entries, IssueQueueEntry(issue, beads.ParseMRFields(issue), now))
 	}
-
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
-	})
+	ApplyConflictReport(m.rig.Path, entries, now)
 
 	// Convert scored issues to queue items
 	var items []QueueItem
 	pos := 1
-	for _, s := range scored {
-		mr := m.issueToMR(s.issue)
+	for _, e := range OrderQueue(entries, scoring) {
+		if e.Score.Held {
+			if mr := m.issueToMR(byID[e.ID]); includeHeld && mr != nil {
+				items = append(items, QueueItem{MR: mr, Age: formatAge(mr.CreatedAt)})
+			}
+			continue
+		}
+		mr := m.issueToMR(byID[e.ID])
 		if mr != nil {
 			items = append(items, QueueItem{
 				Position: pos,
@@ -266,39 +279,6 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 	return items, nil
 }
 
-// calculateIssueScore computes the priority score for an MR issue.
-// Higher scores mean higher priority (process first).
-func (m *Manager) calculateIssueScore(issue *beads.Issue, now time.Time) float64 {
-	fields := beads.ParseMRFields(issue)
-
-	// Parse MR creation time
-	mrCreatedAt := parseTime(issue.CreatedAt)
-	if mrCreatedAt.IsZero() {
-		mrCreatedAt = now // Fallback
-	}
-
-	// Build score input
-	input := ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
-	}
-
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
-
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime := parseTime(fields.ConvoyCreatedAt); !convoyTime.IsZero() {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
-	}
-
-	return ScoreMRWithDefaults(input)
-}
-
 // issueToMR converts a beads issue to a MergeRequest.
 func (m *Manager) issueToMR(issue *beads.Issue) *MergeRequest {
 	if issue == nil {
@@ -377,9 +357,10 @@ func (m *Manager) GetMR(id string) (*MergeRequest, error) {
 	return m.FindMR(id)
 }
 
-// FindMR finds a merge request by ID or branch name in the queue.
+// FindMR finds a merge request by ID or branch name among the open MRs,
+// held ones included.
 func (m *Manager) FindMR(idOrBranch string) (*MergeRequest, error) {
-	queue, err := m.Queue()
+	queue, err := m.queue(true)
 	if err != nil {
 		return nil, err
 	}
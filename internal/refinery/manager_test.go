@@ -141,6 +141,49 @@ func TestManager_Queue_FiltersClosedMergeRequests(t *testing.T) {
 	}
 }
 
+func TestManager_Queue_SkipsHeldMergeRequests(t *testing.T) {
+	mgr, rigPath := setupTestManager(t)
+	b := beads.New(rigPath)
+	if err := b.Init("gt"); err != nil {
+		t.Skipf("bd init unavailable in test environment: %v", err)
+	}
+
+	ready, err := b.Create(beads.CreateOptions{
+		Title:       "Ready MR",
+		Type:        "merge-request",
+		Description: beads.FormatMRFields(&beads.MRFields{Branch: "polecat/nux"}),
+	})
+	if err != nil {
+		t.Fatalf("create merge-request issue: %v", err)
+	}
+	held, err := b.Create(beads.CreateOptions{
+		Title:       "Held MR",
+		Type:        "merge-request",
+		Description: beads.FormatMRFields(&beads.MRFields{Branch: "polecat/toast", Hold: "release freeze"}),
+	})
+	if err != nil {
+		t.Fatalf("create held merge-request issue: %v", err)
+	}
+
+	queue, err := mgr.Queue()
+	if err != nil {
+		t.Fatalf("Queue() error: %v", err)
+	}
+	for _, item := range queue {
+		if item.MR != nil && item.MR.ID == held.ID {
+			t.Fatalf("queue gives held merge-request %s position %d", held.ID, item.Position)
+		}
+	}
+	if len(queue) != 1 || queue[0].MR.ID != ready.ID || queue[0].Position != 1 {
+		t.Fatalf("queue = %+v, want only %s at position 1", queue, ready.ID)
+	}
+
+	// A held MR can still be found, e.g. to reject it.
+	if mr, err := mgr.FindMR(held.ID); err != nil || mr.ID != held.ID {
+		t.Errorf("FindMR(%s) = %v, %v", held.ID, mr, err)
+	}
+}
+
 func TestManager_FindMR_NoBeads(t *testing.T) {
 	mgr, _ := setupTestManager(t)
 
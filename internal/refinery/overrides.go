@@ -0,0 +1,111 @@
+package refinery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// DefaultBoost is how many points gt mq bump adds when no amount is given:
+// one priority level at the default weights.
+const DefaultBoost = 100
+
+// BoostMR adds delta points to an MR's score (negative to demote) and
+// returns its new boost.
+func (e *Engineer) BoostMR(mrID string, delta int) (int, error) {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return 0, err
+	}
+	fields.Boost += delta
+	return fields.Boost, e.updateMRFields(mrBead, fields)
+}
+
+// SetBoost replaces an MR's boost; 0 clears it.
+func (e *Engineer) SetBoost(mrID string, boost int) error {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return err
+	}
+	fields.Boost = boost
+	return e.updateMRFields(mrBead, fields)
+}
+
+// PinMR pins an MR ahead of every unpinned MR, or unpins it.
+func (e *Engineer) PinMR(mrID string, pinned bool) error {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return err
+	}
+	fields.Pinned = pinned
+	return e.updateMRFields(mrBead, fields)
+}
+
+// HoldMR keeps an MR out of the ready queue for the given reason, or
+// releases it when reason is empty. A hold doesn't stop an MR the Refinery
+// has already claimed.
+func (e *Engineer) HoldMR(mrID, reason string) error {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return err
+	}
+	fields.Hold = reason
+	return e.updateMRFields(mrBead, fields)
+}
+
+// MRExplanation is why an MR sits where it does in the queue.
+type MRExplanation struct {
+	MR    *MRInfo        `json:"mr"`
+	Score ScoreBreakdown `json:"score"`
+	// Position is the MR's 1-based place among the ready MRs, or where it
+	// would sit if it were ready.
+	Position int `json:"position"`
+	// QueueLength is the number of MRs Position counts among.
+	QueueLength int `json:"queue_length"`
+	// Ready is false when the MR is held, claimed or blocked.
+	Ready  bool        `json:"ready"`
+	Config ScoreConfig `json:"config"`
+}
+
+// ExplainMR scores an MR against the rest of the ready queue.
+func (e *Engineer) ExplainMR(mrID string) (*MRExplanation, error) {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil || !beads.HasLabel(issue, "gt:merge-request") {
+		return nil, ErrMRNotFound
+	}
+	mr := issueToMRInfo(issue, fields)
+	mr.BlockedBy = e.firstOpenBlocker(issue)
+	e.applyConflictReport([]*MRInfo{mr}, time.Now())
+
+	ready, err := e.ListReadyMRs()
+	if err != nil {
+		return nil, err
+	}
+	x := &MRExplanation{MR: mr, Config: e.config.Scoring}
+	queue := ready
+	for _, r := range ready {
+		if r.ID == mr.ID {
+			x.Ready = true
+			break
+		}
+	}
+	if !x.Ready {
+		queue = append(queue, mr)
+	}
+
+	ordered, scores := OrderMRs(queue, e.config.Scoring, time.Now())
+	for i, r := range ordered {
+		if r.ID == mr.ID {
+			x.Position = i + 1
+			break
+		}
+	}
+	x.Score = scores[mr.ID]
+	x.QueueLength = len(queue)
+	return x, nil
+}
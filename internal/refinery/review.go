@@ -0,0 +1,486 @@
+package refinery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// Review statuses recorded in an MR's review_status field.
+const (
+	ReviewPending          = "pending"
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+	ReviewReworkRequested  = "rework_requested"
+)
+
+// ManualReviewer is the review policy reviewer that creates review tasks
+// without dispatching them.
+const ManualReviewer = "manual"
+
+// Review finding severities, most severe first.
+var ReviewSeverities = []string{"blocking", "major", "minor", "nit"}
+
+// ReviewFinding is one structured finding recorded by a reviewer.
+type ReviewFinding struct {
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+// String formats the finding for mail and terminal output.
+func (f ReviewFinding) String() string {
+	loc := f.File
+	if loc != "" && f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, f.Line)
+	}
+	if loc == "" {
+		return fmt.Sprintf("[%s] %s", f.Severity, f.Message)
+	}
+	return fmt.Sprintf("[%s] %s %s", f.Severity, loc, f.Message)
+}
+
+// ParseReviewFinding parses a finding given on the command line as
+// "severity:message", "severity:file:message" or "severity:file:line:message".
+func ParseReviewFinding(s string) (ReviewFinding, error) {
+	sev, rest, ok := strings.Cut(s, ":")
+	sev = strings.ToLower(strings.TrimSpace(sev))
+	if !ok || strings.TrimSpace(rest) == "" {
+		return ReviewFinding{}, fmt.Errorf("invalid finding %q: want severity:[file:[line:]]message", s)
+	}
+	if !isReviewSeverity(sev) {
+		return ReviewFinding{}, fmt.Errorf("invalid finding severity %q: want one of %s", sev, strings.Join(ReviewSeverities, ", "))
+	}
+
+	f := ReviewFinding{Severity: sev, Message: strings.TrimSpace(rest)}
+	parts := strings.SplitN(rest, ":", 3)
+	if len(parts) < 2 || strings.ContainsAny(parts[0], " \t") || strings.TrimSpace(parts[0]) == "" {
+		return f, nil // No file: the whole remainder is the message
+	}
+	if len(parts) == 3 {
+		if line, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil && line > 0 {
+			f.File, f.Line, f.Message = parts[0], line, strings.TrimSpace(parts[2])
+			return f, nil
+		}
+	}
+	if !strings.ContainsAny(parts[0], "./") {
+		return f, nil // Doesn't look like a path
+	}
+	f.File, f.Message = parts[0], strings.TrimSpace(strings.Join(parts[1:], ":"))
+	return f, nil
+}
+
+func isReviewSeverity(s string) bool {
+	for _, sev := range ReviewSeverities {
+		if s == sev {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeReviewFindings encodes findings for the MR's review_findings field.
+func EncodeReviewFindings(findings []ReviewFinding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DecodeReviewFindings decodes an MR's review_findings field. Malformed
+// values decode to no findings.
+func DecodeReviewFindings(s string) []ReviewFinding {
+	if s == "" {
+		return nil
+	}
+	var findings []ReviewFinding
+	if err := json.Unmarshal([]byte(s), &findings); err != nil {
+		return nil
+	}
+	return findings
+}
+
+// ReviewGateResult is the outcome of checking an MR against the review policy.
+type ReviewGateResult struct {
+	Approved bool   `json:"approved"`
+	Status   string `json:"status"` // disabled, approved, pending, rework_requested
+	HeadSHA  string `json:"head_sha,omitempty"`
+	Task     string `json:"task,omitempty"`
+	Message  string `json:"message"`
+}
+
+// reviewStep is what the gate does for an MR's current review state.
+type reviewStep int
+
+const (
+	reviewProceed reviewStep = iota // Approved at the current head
+	reviewRequest                   // No review of the current head: request one
+	reviewRework                    // Changes requested: send the polecat back
+	reviewWait                      // Review or rework in progress
+)
+
+// nextReviewStep decides the gate's step from the MR's review fields and
+// the branch head. A review applies only to the head it was requested for,
+// so any push after it starts over.
+func nextReviewStep(fields *beads.MRFields, head string, taskOpen bool) reviewStep {
+	if fields.ReviewStatus == "" || fields.ReviewSHA != head {
+		return reviewRequest
+	}
+	switch fields.ReviewStatus {
+	case ReviewApproved:
+		return reviewProceed
+	case ReviewChangesRequested:
+		return reviewRework
+	case ReviewPending:
+		if !taskOpen {
+			// The review task was closed without a verdict (reviewer gave
+			// up or died) - ask again.
+			return reviewRequest
+		}
+		return reviewWait
+	default:
+		return reviewWait
+	}
+}
+
+// ReviewGate checks an MR against the rig's review policy before merging.
+// It requests a review of the branch head when there is none, sends the
+// polecat back with REWORK_REQUEST when the review requested changes, and
+// reports Approved only when the current head has been approved.
+func (e *Engineer) ReviewGate(mr *MRInfo) (*ReviewGateResult, error) {
+	policy := e.config.Review
+	if policy == nil || !policy.Enabled {
+		return &ReviewGateResult{Approved: true, Status: "disabled", Message: "review not required"}, nil
+	}
+
+	mrBead, err := e.beads.Show(mr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", mr.ID, err)
+	}
+	fields := beads.ParseMRFields(mrBead)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+
+	head, err := e.branchHead(mr.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	taskOpen := false
+	if fields.ReviewTask != "" {
+		taskOpen, _ = e.IsBeadOpen(fields.ReviewTask)
+	}
+
+	result := &ReviewGateResult{Status: fields.ReviewStatus, HeadSHA: head, Task: fields.ReviewTask}
+	switch nextReviewStep(fields, head, taskOpen) {
+	case reviewProceed:
+		result.Approved = true
+		result.Message = fmt.Sprintf("approved by %s", fields.Reviewer)
+		return result, nil
+
+	case reviewRework:
+		if err := e.sendReviewRework(mr, fields); err != nil {
+			return nil, err
+		}
+		fields.ReviewStatus = ReviewReworkRequested
+		if err := e.updateMRFields(mrBead, fields); err != nil {
+			return nil, err
+		}
+		result.Status = ReviewReworkRequested
+		result.Message = fmt.Sprintf("changes requested by %s - sent back to %s", fields.Reviewer, mr.Worker)
+		return result, nil
+
+	case reviewWait:
+		result.Message = fmt.Sprintf("waiting on review task %s", fields.ReviewTask)
+		if fields.ReviewStatus == ReviewReworkRequested {
+			result.Message = fmt.Sprintf("waiting for %s to address review findings", mr.Worker)
+		}
+		return result, nil
+	}
+
+	// No review of the current head yet: supersede any earlier review and
+	// request a new one.
+	if taskOpen {
+		_ = e.beads.CloseWithReason("superseded: branch updated", fields.ReviewTask)
+		_ = e.beads.RemoveDependency(mr.ID, fields.ReviewTask)
+	}
+	taskID, err := e.createReviewTask(mr, head, policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.beads.AddDependency(mr.ID, taskID); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to block MR on review task: %v\n", err)
+	}
+
+	fields.ReviewStatus = ReviewPending
+	fields.ReviewSHA = head
+	fields.ReviewTask = taskID
+	fields.ReviewRequestedAt = time.Now().UTC().Format(time.RFC3339)
+	fields.ReviewedAt = ""
+	fields.Reviewer = ""
+	fields.ReviewFindings = ""
+	if err := e.updateMRFields(mrBead, fields); err != nil {
+		return nil, err
+	}
+
+	result.Status = ReviewPending
+	result.Task = taskID
+	result.Message = fmt.Sprintf("review requested (task %s)", taskID)
+	if policy.Reviewer == ManualReviewer {
+		result.Message += " - waiting for a manual reviewer"
+	} else if err := e.dispatchReview(taskID, mr, policy); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to dispatch review %s: %v\n", taskID, err)
+		result.Message += " - dispatch failed, sling it manually"
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: %s\n", mr.ID, result.Message)
+	return result, nil
+}
+
+// RecordReview records a reviewer's verdict on an MR and closes its review
+// task, which unblocks the MR. The verdict applies to the head the review
+// was requested for.
+func (e *Engineer) RecordReview(mrID, reviewer string, approve bool, findings []ReviewFinding, summary string) error {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return err
+	}
+
+	fields.ReviewStatus = ReviewChangesRequested
+	reason := fmt.Sprintf("changes requested (%d finding(s))", len(findings))
+	if approve {
+		fields.ReviewStatus = ReviewApproved
+		reason = "approved"
+	}
+	if summary != "" {
+		reason += ": " + summary
+	}
+	if fields.ReviewSHA == "" {
+		if head, err := e.branchHead(fields.Branch); err == nil {
+			fields.ReviewSHA = head
+		}
+	}
+	fields.Reviewer = reviewer
+	fields.ReviewedAt = time.Now().UTC().Format(time.RFC3339)
+	fields.ReviewFindings = EncodeReviewFindings(findings)
+	if err := e.updateMRFields(mrBead, fields); err != nil {
+		return err
+	}
+
+	if fields.ReviewTask != "" {
+		if err := e.beads.CloseWithReason(reason, fields.ReviewTask); err != nil {
+			return fmt.Errorf("closing review task %s: %w", fields.ReviewTask, err)
+		}
+	}
+	return nil
+}
+
+// ApproveMR approves an MR's current branch head on a human's authority,
+// overriding any pending review or requested changes.
+func (e *Engineer) ApproveMR(mrID, approver, reason string) error {
+	mrBead, fields, err := e.showMR(mrID)
+	if err != nil {
+		return err
+	}
+	head, err := e.branchHead(fields.Branch)
+	if err != nil {
+		return err
+	}
+
+	fields.ReviewStatus = ReviewApproved
+	fields.ReviewSHA = head
+	fields.Reviewer = approver
+	fields.ReviewedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := e.updateMRFields(mrBead, fields); err != nil {
+		return err
+	}
+
+	if fields.ReviewTask != "" {
+		if open, _ := e.IsBeadOpen(fields.ReviewTask); open {
+			closeReason := "approved by " + approver
+			if reason != "" {
+				closeReason += ": " + reason
+			}
+			if err := e.beads.CloseWithReason(closeReason, fields.ReviewTask); err != nil {
+				return fmt.Errorf("closing review task %s: %w", fields.ReviewTask, err)
+			}
+		}
+	}
+	return nil
+}
+
+// showMR fetches an MR bead and its fields.
+func (e *Engineer) showMR(mrID string) (*beads.Issue, *beads.MRFields, error) {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	if !beads.HasLabel(mrBead, "gt:merge-request") {
+		return nil, nil, fmt.Errorf("%s is not a merge request", mrID)
+	}
+	fields := beads.ParseMRFields(mrBead)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	return mrBead, fields, nil
+}
+
+// updateMRFields writes MR fields back to the bead's description.
+func (e *Engineer) updateMRFields(mrBead *beads.Issue, fields *beads.MRFields) error {
+	newDesc := beads.SetMRFields(mrBead, fields)
+	if err := e.beads.Update(mrBead.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s: %w", mrBead.ID, err)
+	}
+	return nil
+}
+
+// branchHead resolves an MR branch's head commit, preferring the local
+// branch (shared with polecats) over the remote-tracking one.
+func (e *Engineer) branchHead(branch string) (string, error) {
+	if branch == "" {
+		return "", fmt.Errorf("MR has no branch")
+	}
+	if sha, err := e.git.Rev(branch); err == nil {
+		return sha, nil
+	}
+	sha, err := e.git.Rev("origin/" + branch)
+	if err != nil {
+		return "", fmt.Errorf("resolving branch %s: %w", branch, err)
+	}
+	return sha, nil
+}
+
+// createReviewTask creates the task a reviewer works on and the MR is
+// blocked on until the verdict is recorded.
+func (e *Engineer) createReviewTask(mr *MRInfo, head string, policy *config.ReviewPolicy) (string, error) {
+	title := mr.Title
+	if title == "" {
+		title = mr.Branch
+	}
+
+	description := fmt.Sprintf(`Review merge request %s before it merges.
+
+## Metadata
+- MR: %s
+- Branch: %s
+- Head: %s
+- Target: %s
+- Source issue: %s
+- Worker: %s
+
+## Instructions
+1. Review the change: git diff origin/%s...%s
+2. Record the verdict (closes this task):
+   gt mq review record %s %s --approve
+   gt mq review record %s %s --request-changes \
+     --finding "blocking:path/to/file.go:42:what is wrong"
+
+Severities: %s. Requesting changes sends the findings back to the worker.`,
+		mr.ID,
+		mr.ID, mr.Branch, head, mr.Target, mr.SourceIssue, mr.Worker,
+		mr.Target, head,
+		e.rig.Name, mr.ID,
+		e.rig.Name, mr.ID,
+		strings.Join(ReviewSeverities, ", "),
+	)
+
+	task, err := e.beads.Create(beads.CreateOptions{
+		Title:       "Review MR: " + title,
+		Type:        "review",
+		Priority:    mr.Priority,
+		Description: description,
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating review task: %w", err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Created review task %s for %s (%s)\n", task.ID, mr.ID, policy.ReviewFormula())
+	return task.ID, nil
+}
+
+// dispatchReview slings the review formula on the review task to the
+// policy's reviewer, or to a fresh polecat in the rig.
+func (e *Engineer) dispatchReview(taskID string, mr *MRInfo, policy *config.ReviewPolicy) error {
+	target := policy.Reviewer
+	if target == "" {
+		target = e.rig.Name
+	}
+	args := []string{"sling", policy.ReviewFormula(), "--on", taskID, target,
+		"--var", "mr=" + mr.ID,
+		"--var", "rig=" + e.rig.Name,
+		"--var", "branch=" + mr.Branch,
+		"--var", "target=" + mr.Target,
+		"--no-convoy", "--no-merge",
+	}
+	if policy.Agent != "" {
+		args = append(args, "--agent", policy.Agent)
+	}
+
+	cmd := exec.Command("gt", args...) //nolint:gosec // G204: args are from trusted rig config and bead IDs
+	cmd.Dir = filepath.Dir(e.rig.Path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Dispatched review %s to %s\n", taskID, target)
+	return nil
+}
+
+// sendReviewRework sends the review findings back to the MR's polecat
+// through the Witness.
+func (e *Engineer) sendReviewRework(mr *MRInfo, fields *beads.MRFields) error {
+	var findings []string
+	for _, f := range DecodeReviewFindings(fields.ReviewFindings) {
+		findings = append(findings, f.String())
+	}
+	msg := protocol.NewReviewReworkRequestMessage(protocol.ReworkRequestPayload{
+		Branch:       mr.Branch,
+		Issue:        mr.SourceIssue,
+		Polecat:      mr.Worker,
+		Rig:          e.rig.Name,
+		TargetBranch: mr.Target,
+		MR:           mr.ID,
+		Reviewer:     fields.Reviewer,
+		Findings:     findings,
+	})
+	if err := e.router.Send(msg); err != nil {
+		return fmt.Errorf("sending REWORK_REQUEST to witness: %w", err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Sent review findings for %s back to %s\n", mr.ID, mr.Worker)
+	return nil
+}
+
+// ReviewLatency returns how long an MR's review took, or has been pending,
+// and whether it is still pending. ok is false when no review was requested.
+func ReviewLatency(fields *beads.MRFields, now time.Time) (latency time.Duration, pending bool, ok bool) {
+	if fields == nil || fields.ReviewRequestedAt == "" {
+		return 0, false, false
+	}
+	requested, err := time.Parse(time.RFC3339, fields.ReviewRequestedAt)
+	if err != nil {
+		return 0, false, false
+	}
+	if fields.ReviewStatus == ReviewPending {
+		return now.Sub(requested), true, true
+	}
+	reviewed, err := time.Parse(time.RFC3339, fields.ReviewedAt)
+	if err != nil || reviewed.Before(requested) {
+		return 0, false, false
+	}
+	return reviewed.Sub(requested), false, true
+}
@@ -0,0 +1,125 @@
+package refinery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestParseReviewFinding(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ReviewFinding
+		wantErr bool
+	}{
+		{in: "nit:missing doc comment", want: ReviewFinding{Severity: "nit", Message: "missing doc comment"}},
+		{in: "blocking:api/server.go:42:nil map write", want: ReviewFinding{Severity: "blocking", File: "api/server.go", Line: 42, Message: "nil map write"}},
+		{in: "Major:main.go:unchecked error", want: ReviewFinding{Severity: "major", File: "main.go", Message: "unchecked error"}},
+		{in: "minor:note: this reads oddly", want: ReviewFinding{Severity: "minor", Message: "note: this reads oddly"}},
+		{in: "blocking:races on shutdown: see Stop", want: ReviewFinding{Severity: "blocking", Message: "races on shutdown: see Stop"}},
+		{in: "critical:something", wantErr: true},
+		{in: "blocking:", wantErr: true},
+		{in: "no severity", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReviewFinding(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseReviewFinding(%q) = %+v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseReviewFinding(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReviewFinding(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReviewFindingsRoundTrip(t *testing.T) {
+	findings := []ReviewFinding{
+		{Severity: "blocking", File: "a.go", Line: 3, Message: "multi\nline"},
+		{Severity: "nit", Message: "typo"},
+	}
+	encoded := EncodeReviewFindings(findings)
+	if encoded == "" {
+		t.Fatal("EncodeReviewFindings returned empty string")
+	}
+
+	// The encoded value must survive the MR description's key: value format.
+	issue := &beads.Issue{Description: beads.FormatMRFields(&beads.MRFields{Branch: "b", ReviewFindings: encoded})}
+	fields := beads.ParseMRFields(issue)
+	got := DecodeReviewFindings(fields.ReviewFindings)
+	if len(got) != 2 || got[0] != findings[0] || got[1] != findings[1] {
+		t.Errorf("round trip = %+v, want %+v", got, findings)
+	}
+
+	if got[0].String() != "[blocking] a.go:3 multi\nline" {
+		t.Errorf("String() = %q", got[0].String())
+	}
+	if DecodeReviewFindings("not json") != nil {
+		t.Error("malformed findings should decode to nil")
+	}
+}
+
+func TestNextReviewStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   beads.MRFields
+		taskOpen bool
+		want     reviewStep
+	}{
+		{"no review yet", beads.MRFields{}, false, reviewRequest},
+		{"approved", beads.MRFields{ReviewStatus: ReviewApproved, ReviewSHA: "abc"}, false, reviewProceed},
+		{"approved older head", beads.MRFields{ReviewStatus: ReviewApproved, ReviewSHA: "old"}, false, reviewRequest},
+		{"pending", beads.MRFields{ReviewStatus: ReviewPending, ReviewSHA: "abc"}, true, reviewWait},
+		{"pending task closed", beads.MRFields{ReviewStatus: ReviewPending, ReviewSHA: "abc"}, false, reviewRequest},
+		{"changes requested", beads.MRFields{ReviewStatus: ReviewChangesRequested, ReviewSHA: "abc"}, false, reviewRework},
+		{"rework sent", beads.MRFields{ReviewStatus: ReviewReworkRequested, ReviewSHA: "abc"}, false, reviewWait},
+		{"rework pushed", beads.MRFields{ReviewStatus: ReviewReworkRequested, ReviewSHA: "old"}, false, reviewRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextReviewStep(&tt.fields, "abc", tt.taskOpen); got != tt.want {
+				t.Errorf("nextReviewStep() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewGateDisabled(t *testing.T) {
+	e := &Engineer{config: DefaultMergeQueueConfig()}
+	for _, policy := range []*config.ReviewPolicy{nil, {Enabled: false}} {
+		e.config.Review = policy
+		res, err := e.ReviewGate(&MRInfo{ID: "gt-mr1", Branch: "polecat/nux"})
+		if err != nil {
+			t.Fatalf("ReviewGate() error: %v", err)
+		}
+		if !res.Approved || res.Status != "disabled" {
+			t.Errorf("ReviewGate() = %+v, want approved (disabled)", res)
+		}
+	}
+}
+
+func TestReviewLatency(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	pending := &beads.MRFields{ReviewStatus: ReviewPending, ReviewRequestedAt: "2026-01-02T11:30:00Z"}
+	if d, isPending, ok := ReviewLatency(pending, now); !ok || !isPending || d != 30*time.Minute {
+		t.Errorf("pending latency = %v, %v, %v", d, isPending, ok)
+	}
+
+	done := &beads.MRFields{ReviewStatus: ReviewApproved, ReviewRequestedAt: "2026-01-02T10:00:00Z", ReviewedAt: "2026-01-02T10:12:00Z"}
+	if d, isPending, ok := ReviewLatency(done, now); !ok || isPending || d != 12*time.Minute {
+		t.Errorf("completed latency = %v, %v, %v", d, isPending, ok)
+	}
+
+	if _, _, ok := ReviewLatency(&beads.MRFields{}, now); ok {
+		t.Error("no review should report ok=false")
+	}
+}
@@ -4,39 +4,65 @@
 package refinery
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 // ScoreConfig contains tunable weights for MR priority scoring.
 // All weights are designed so higher scores = higher priority (process first).
+// Rigs override them under merge_queue.scoring in config.json.
 type ScoreConfig struct {
 	// BaseScore is the starting score before applying factors.
 	// Default: 1000 (keeps all scores positive)
-	BaseScore float64
+	BaseScore float64 `json:"base_score"`
 
 	// ConvoyAgeWeight is points added per hour of convoy age.
 	// Older convoys get priority to prevent starvation.
 	// Default: 10.0 (10 pts/hour = 240 pts/day)
-	ConvoyAgeWeight float64
+	ConvoyAgeWeight float64 `json:"convoy_age_weight"`
 
 	// PriorityWeight is multiplied by (4 - priority) so P0 gets most points.
 	// P0 adds 4*weight, P1 adds 3*weight, ..., P4 adds 0*weight.
 	// Default: 100.0 (P0 gets +400, P4 gets +0)
-	PriorityWeight float64
+	PriorityWeight float64 `json:"priority_weight"`
 
 	// RetryPenalty is subtracted per retry attempt to prevent thrashing.
 	// MRs that keep failing get deprioritized, giving repo state time to stabilize.
 	// Default: 50.0 (each retry loses 50 pts)
-	RetryPenalty float64
+	RetryPenalty float64 `json:"retry_penalty"`
 
 	// MRAgeWeight is points added per hour since MR submission.
 	// Minor factor for FIFO ordering within same priority/convoy.
 	// Default: 1.0 (1 pt/hour)
-	MRAgeWeight float64
+	MRAgeWeight float64 `json:"mr_age_weight"`
 
 	// MaxRetryPenalty caps the total retry penalty to prevent permanent deprioritization.
 	// Default: 300.0 (after 6 retries, penalty is capped)
-	MaxRetryPenalty float64
+	MaxRetryPenalty float64 `json:"max_retry_penalty"`
+
+	// ConvoyCap is how many MRs from one convoy may sit ahead in the queue
+	// before the next one from that convoy is penalized, so one big convoy
+	// can't starve the others. 0 disables the cap.
+	// Default: 0
+	ConvoyCap int `json:"convoy_cap"`
+
+	// AuthorCap is ConvoyCap for MRs from the same worker.
+	// Default: 0
+	AuthorCap int `json:"author_cap"`
+
+	// FairnessPenalty is subtracted for each MR from the same convoy or
+	// worker ahead in the queue beyond its cap.
+	// Default: 200.0 (two priority levels)
+	FairnessPenalty float64 `json:"fairness_penalty"`
+
+	// ConflictPenalty is subtracted for each other in-flight branch whose
+	// changed lines overlap the MR's, per the latest conflict prediction
+	// (gt mq conflicts), so the least-conflicting MRs land first.
+	// Default: 0 (off)
+	ConflictPenalty float64 `json:"conflict_penalty"`
 }
 
 // DefaultScoreConfig returns sensible defaults for MR scoring.
@@ -48,9 +74,37 @@ func DefaultScoreConfig() ScoreConfig {
 		RetryPenalty:    50.0,
 		MRAgeWeight:     1.0,
 		MaxRetryPenalty: 300.0,
+		FairnessPenalty: 200.0,
 	}
 }
 
+// Validate checks that weights and caps are not negative.
+func (c ScoreConfig) Validate() error {
+	for _, w := range []struct {
+		name  string
+		value float64
+	}{
+		{"convoy_age_weight", c.ConvoyAgeWeight},
+		{"priority_weight", c.PriorityWeight},
+		{"retry_penalty", c.RetryPenalty},
+		{"mr_age_weight", c.MRAgeWeight},
+		{"max_retry_penalty", c.MaxRetryPenalty},
+		{"fairness_penalty", c.FairnessPenalty},
+		{"conflict_penalty", c.ConflictPenalty},
+	} {
+		if w.value < 0 {
+			return fmt.Errorf("merge_queue.scoring.%s must not be negative, got %v", w.name, w.value)
+		}
+	}
+	if c.ConvoyCap < 0 {
+		return fmt.Errorf("merge_queue.scoring.convoy_cap must not be negative, got %d", c.ConvoyCap)
+	}
+	if c.AuthorCap < 0 {
+		return fmt.Errorf("merge_queue.scoring.author_cap must not be negative, got %d", c.AuthorCap)
+	}
+	return nil
+}
+
 // ScoreInput contains the data needed to score an MR.
 // This struct decouples scoring from the MR struct, allowing the
 // caller to provide convoy age from external lookups.
@@ -69,11 +123,74 @@ type ScoreInput struct {
 	// 0 = first attempt.
 	RetryCount int
 
+	// Boost is points added by gt mq bump (negative to demote).
+	Boost float64
+
+	// Overlaps is how many other in-flight branches change the same lines
+	// as the MR (see ConflictReport.HunkOverlaps).
+	Overlaps int
+
+	// Pinned MRs are processed ahead of all unpinned ones (gt mq pin).
+	Pinned bool
+
+	// Held MRs are not processed at all (gt mq hold).
+	Held bool
+
 	// Now is the current time (for deterministic testing).
 	// If zero, time.Now() is used.
 	Now time.Time
 }
 
+// ScoreBreakdown is a merge request's score split into the terms ScoreMR
+// sums, for showing why an MR sits where it does in the queue.
+type ScoreBreakdown struct {
+	Base         float64 `json:"base"`
+	ConvoyAge    float64 `json:"convoy_age"`
+	Priority     float64 `json:"priority"`
+	RetryPenalty float64 `json:"retry_penalty"` // Subtracted
+	MRAge        float64 `json:"mr_age"`
+	Boost        float64 `json:"boost,omitempty"`
+	Conflicts    float64 `json:"conflicts,omitempty"` // Subtracted
+	Fairness     float64 `json:"fairness,omitempty"`  // Subtracted; set by OrderQueue
+	Total        float64 `json:"total"`
+	Pinned       bool    `json:"pinned,omitempty"`
+	Held         bool    `json:"held,omitempty"`
+}
+
+// String renders the breakdown as the sum it is, e.g.
+// "1242.0 = 1000.0 base + 240.0 convoy age + 300.0 priority - 300.0 retries + 2.0 MR age".
+// Terms that are zero are left out; pinned and held MRs are marked.
+func (b ScoreBreakdown) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%.1f = %.1f base", b.Total, b.Base)
+	for _, term := range []struct {
+		value float64 // Signed as it adds to the total
+		name  string
+	}{
+		{b.ConvoyAge, "convoy age"},
+		{b.Priority, "priority"},
+		{-b.RetryPenalty, "retries"},
+		{b.MRAge, "MR age"},
+		{b.Boost, "boost"},
+		{-b.Conflicts, "conflicts"},
+		{-b.Fairness, "fairness"},
+	} {
+		switch {
+		case term.value > 0:
+			fmt.Fprintf(&sb, " + %.1f %s", term.value, term.name)
+		case term.value < 0:
+			fmt.Fprintf(&sb, " - %.1f %s", -term.value, term.name)
+		}
+	}
+	if b.Pinned {
+		sb.WriteString(" [pinned]")
+	}
+	if b.Held {
+		sb.WriteString(" [held]")
+	}
+	return sb.String()
+}
+
 // ScoreMR calculates the priority score for a merge request.
 // Higher scores mean higher priority (process first).
 //
@@ -84,20 +201,36 @@ type ScoreInput struct {
 //	      + PriorityWeight * (4 - priority)          // P0=+400, P4=+0
 //	      - min(RetryPenalty * retryCount, MaxRetryPenalty)  // Prevent thrashing
 //	      + MRAgeWeight * hoursOld(MR)               // FIFO tiebreaker
+//	      + boost                                    // gt mq bump
+//	      - ConflictPenalty * overlappingBranches    // Least-conflicting first
+//
+// Pins, holds and fairness caps order the queue around the score; see
+// OrderQueue.
 func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
+	return ExplainScore(input, config).Total
+}
+
+// ExplainScore calculates a merge request's score as ScoreMR does, keeping
+// each term.
+func ExplainScore(input ScoreInput, config ScoreConfig) ScoreBreakdown {
 	now := input.Now
 	if now.IsZero() {
 		now = time.Now()
 	}
 
-	score := config.BaseScore
+	b := ScoreBreakdown{
+		Base:   config.BaseScore,
+		Boost:  input.Boost,
+		Pinned: input.Pinned,
+		Held:   input.Held,
+	}
 
 	// Convoy age factor: prevent starvation of old convoys
 	if input.ConvoyCreatedAt != nil {
 		convoyAge := now.Sub(*input.ConvoyCreatedAt)
 		convoyHours := convoyAge.Hours()
 		if convoyHours > 0 {
-			score += config.ConvoyAgeWeight * convoyHours
+			b.ConvoyAge = config.ConvoyAgeWeight * convoyHours
 		}
 	}
 
@@ -109,23 +242,107 @@ func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
 	if priorityBonus > 4 {
 		priorityBonus = 4 // Clamp for invalid priorities < 0
 	}
-	score += config.PriorityWeight * float64(priorityBonus)
+	b.Priority = config.PriorityWeight * float64(priorityBonus)
 
 	// Retry penalty: prevent thrashing on repeatedly failing MRs
-	retryPenalty := config.RetryPenalty * float64(input.RetryCount)
-	if retryPenalty > config.MaxRetryPenalty {
-		retryPenalty = config.MaxRetryPenalty
+	b.RetryPenalty = config.RetryPenalty * float64(input.RetryCount)
+	if b.RetryPenalty > config.MaxRetryPenalty {
+		b.RetryPenalty = config.MaxRetryPenalty
 	}
-	score -= retryPenalty
 
 	// MR age factor: FIFO ordering as tiebreaker
 	mrAge := now.Sub(input.MRCreatedAt)
 	mrHours := mrAge.Hours()
 	if mrHours > 0 {
-		score += config.MRAgeWeight * mrHours
+		b.MRAge = config.MRAgeWeight * mrHours
 	}
 
-	return score
+	// Conflict penalty: land MRs that overlap fewer in-flight branches first
+	b.Conflicts = config.ConflictPenalty * float64(input.Overlaps)
+
+	b.Total = b.Base + b.ConvoyAge + b.Priority - b.RetryPenalty + b.MRAge + b.Boost - b.Conflicts
+	return b
+}
+
+// QueueEntry is a merge request as OrderQueue sees it.
+type QueueEntry struct {
+	ID     string
+	Branch string // For the conflict report
+	Convoy string // Convoy ID, for the convoy cap
+	Author string // Worker, for the author cap
+	Input  ScoreInput
+
+	// Score is the entry's score, including any fairness penalty, once
+	// ordered.
+	Score ScoreBreakdown
+}
+
+// OrderQueue orders merge requests the way the Refinery processes them:
+// pinned MRs first, then by score, with held MRs last. Fairness caps are
+// applied as the order is built: an MR is penalized for each MR from its
+// convoy (or worker) already ahead of it beyond ConvoyCap (AuthorCap), so
+// the next MR of a big convoy competes with everything else. Held MRs
+// neither pay nor count toward caps. Ties keep the input order.
+func OrderQueue(entries []*QueueEntry, config ScoreConfig) []*QueueEntry {
+	remaining := make([]*QueueEntry, len(entries))
+	copy(remaining, entries)
+	for _, e := range remaining {
+		e.Score = ExplainScore(e.Input, config)
+	}
+
+	convoyAhead := make(map[string]int)
+	authorAhead := make(map[string]int)
+	ordered := make([]*QueueEntry, 0, len(entries))
+	for len(remaining) > 0 {
+		best := -1
+		var bestScore ScoreBreakdown
+		for i, e := range remaining {
+			s := e.Score
+			if !s.Held {
+				s.Fairness = config.FairnessPenalty * float64(
+					overCap(convoyAhead, e.Convoy, config.ConvoyCap)+
+						overCap(authorAhead, e.Author, config.AuthorCap))
+				s.Total -= s.Fairness
+			}
+			if best < 0 || queuedBefore(s, bestScore) {
+				best, bestScore = i, s
+			}
+		}
+
+		e := remaining[best]
+		e.Score = bestScore
+		ordered = append(ordered, e)
+		if !bestScore.Held {
+			if e.Convoy != "" {
+				convoyAhead[e.Convoy]++
+			}
+			if e.Author != "" {
+				authorAhead[e.Author]++
+			}
+		}
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return ordered
+}
+
+// overCap returns how many MRs with the key are ahead beyond the cap.
+func overCap(ahead map[string]int, key string, limit int) int {
+	if key == "" || limit <= 0 || ahead[key] < limit {
+		return 0
+	}
+	return ahead[key] - limit + 1
+}
+
+// queuedBefore reports whether an MR scored a goes strictly ahead of one
+// scored b.
+func queuedBefore(a, b ScoreBreakdown) bool {
+	if a.Held != b.Held {
+		return !a.Held
+	}
+	if a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	return a.Total > b.Total
 }
 
 // ScoreMRWithDefaults is a convenience wrapper using default config.
@@ -141,12 +358,94 @@ func (mr *MRInfo) Score() float64 {
 
 // ScoreAt calculates the priority score at a specific time (for deterministic testing).
 func (mr *MRInfo) ScoreAt(now time.Time) float64 {
-	input := ScoreInput{
+	return ScoreMRWithDefaults(mr.scoreInput(now))
+}
+
+// ExplainScoreAt breaks down the priority score at a specific time using
+// default config.
+func (mr *MRInfo) ExplainScoreAt(now time.Time) ScoreBreakdown {
+	return ExplainScore(mr.scoreInput(now), DefaultScoreConfig())
+}
+
+// QueueEntry returns the MR's entry for OrderQueue at a specific time.
+func (mr *MRInfo) QueueEntry(now time.Time) *QueueEntry {
+	return &QueueEntry{ID: mr.ID, Branch: mr.Branch, Convoy: mr.ConvoyID, Author: mr.Worker, Input: mr.scoreInput(now)}
+}
+
+func (mr *MRInfo) scoreInput(now time.Time) ScoreInput {
+	return ScoreInput{
 		Priority:        mr.Priority,
 		MRCreatedAt:     mr.CreatedAt,
 		ConvoyCreatedAt: mr.ConvoyCreatedAt,
 		RetryCount:      mr.RetryCount,
+		Boost:           float64(mr.Boost),
+		Overlaps:        mr.Overlaps,
+		Pinned:          mr.Pinned,
+		Held:            mr.Hold != "",
 		Now:             now,
 	}
-	return ScoreMRWithDefaults(input)
+}
+
+// OrderMRs orders MRs with OrderQueue, returning each MR's score keyed by
+// ID alongside.
+func OrderMRs(mrs []*MRInfo, config ScoreConfig, now time.Time) ([]*MRInfo, map[string]ScoreBreakdown) {
+	byID := make(map[string]*MRInfo, len(mrs))
+	entries := make([]*QueueEntry, 0, len(mrs))
+	for _, mr := range mrs {
+		byID[mr.ID] = mr
+		entries = append(entries, mr.QueueEntry(now))
+	}
+	ordered := make([]*MRInfo, 0, len(mrs))
+	scores := make(map[string]ScoreBreakdown, len(mrs))
+	for _, e := range OrderQueue(entries, config) {
+		ordered = append(ordered, byID[e.ID])
+		scores[e.ID] = e.Score
+	}
+	return ordered, scores
+}
+
+// IssueQueueEntry returns a merge-request bead's entry for OrderQueue at a
+// specific time. fields may be nil.
+func IssueQueueEntry(issue *beads.Issue, fields *beads.MRFields, now time.Time) *QueueEntry {
+	mrCreatedAt := parseTime(issue.CreatedAt)
+	if mrCreatedAt.IsZero() {
+		mrCreatedAt = now // Fallback
+	}
+	e := &QueueEntry{
+		ID: issue.ID,
+		Input: ScoreInput{
+			Priority:    issue.Priority,
+			MRCreatedAt: mrCreatedAt,
+			Now:         now,
+		},
+	}
+	if fields != nil {
+		e.Branch = fields.Branch
+		e.Convoy = fields.ConvoyID
+		e.Author = fields.Worker
+		e.Input.RetryCount = fields.RetryCount
+		e.Input.Boost = float64(fields.Boost)
+		e.Input.Pinned = fields.Pinned
+		e.Input.Held = fields.Hold != ""
+		if fields.ConvoyCreatedAt != "" {
+			if convoyTime := parseTime(fields.ConvoyCreatedAt); !convoyTime.IsZero() {
+				e.Input.ConvoyCreatedAt = &convoyTime
+			}
+		}
+	}
+	return e
+}
+
+// ApplyConflictReport sets each entry's overlap count from the rig's latest
+// conflict report, if it is recent enough.
+func ApplyConflictReport(rigPath string, entries []*QueueEntry, now time.Time) {
+	report := recentConflictReport(rigPath, now)
+	if report == nil {
+		return
+	}
+	for _, e := range entries {
+		if e.Branch != "" {
+			e.Input.Overlaps = report.HunkOverlaps(e.Branch)
+		}
+	}
 }
@@ -0,0 +1,139 @@
+package refinery
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplainScoreSumsToScoreMR(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	convoy := now.Add(-24 * time.Hour)
+	input := ScoreInput{
+		Priority:        1,
+		MRCreatedAt:     now.Add(-2 * time.Hour),
+		ConvoyCreatedAt: &convoy,
+		RetryCount:      8,
+		Now:             now,
+	}
+	cfg := DefaultScoreConfig()
+
+	b := ExplainScore(input, cfg)
+	want := ScoreBreakdown{Base: 1000, ConvoyAge: 240, Priority: 300, RetryPenalty: 300, MRAge: 2, Total: 1242}
+	if b != want {
+		t.Errorf("ExplainScore = %+v, want %+v", b, want)
+	}
+	if got := ScoreMR(input, cfg); got != b.Total {
+		t.Errorf("ScoreMR = %v, want breakdown total %v", got, b.Total)
+	}
+	if got := b.String(); got != "1242.0 = 1000.0 base + 240.0 convoy age + 300.0 priority - 300.0 retries + 2.0 MR age" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestScoreBreakdownStringOverrides(t *testing.T) {
+	b := ScoreBreakdown{Base: 1000, Priority: 200, Boost: -50, Fairness: 200, Total: 950, Pinned: true}
+	if got := b.String(); got != "950.0 = 1000.0 base + 200.0 priority - 50.0 boost - 200.0 fairness [pinned]" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestOrderQueue(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	entry := func(id, convoy, author string, priority int) *QueueEntry {
+		return &QueueEntry{ID: id, Convoy: convoy, Author: author,
+			Input: ScoreInput{Priority: priority, MRCreatedAt: now, Now: now}}
+	}
+	ids := func(entries []*QueueEntry) string {
+		var s []string
+		for _, e := range entries {
+			s = append(s, e.ID)
+		}
+		return strings.Join(s, " ")
+	}
+
+	tests := []struct {
+		name   string
+		config func(*ScoreConfig)
+		setup  func(map[string]*QueueEntry)
+		want   string
+	}{
+		{
+			name: "by score",
+			want: "a1 a2 a3 b1 c1",
+		},
+		{
+			name:  "boost",
+			setup: func(e map[string]*QueueEntry) { e["c1"].Input.Boost = 250 },
+			want:  "c1 a1 a2 a3 b1",
+		},
+		{
+			name:  "pin beats score, hold goes last",
+			setup: func(e map[string]*QueueEntry) { e["c1"].Input.Pinned = true; e["a1"].Input.Held = true },
+			want:  "c1 a2 a3 b1 a1",
+		},
+		{
+			name:   "convoy cap",
+			config: func(c *ScoreConfig) { c.ConvoyCap = 1 },
+			want:   "a1 b1 c1 a2 a3",
+		},
+		{
+			name:   "author cap",
+			config: func(c *ScoreConfig) { c.AuthorCap = 2 },
+			want:   "a1 a2 b1 c1 a3",
+		},
+		{
+			name:   "held MRs don't count toward caps",
+			config: func(c *ScoreConfig) { c.ConvoyCap = 1 },
+			setup:  func(e map[string]*QueueEntry) { e["a1"].Input.Held = true },
+			want:   "a2 b1 c1 a3 a1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Convoy "big" has three P1 MRs from nux; b1 and c1 are P2.
+			entries := []*QueueEntry{
+				entry("a1", "big", "nux", 1),
+				entry("a2", "big", "nux", 1),
+				entry("a3", "big", "nux", 1),
+				entry("b1", "small", "ace", 2),
+				entry("c1", "", "toast", 2),
+			}
+			byID := make(map[string]*QueueEntry)
+			for _, e := range entries {
+				byID[e.ID] = e
+			}
+			if tt.setup != nil {
+				tt.setup(byID)
+			}
+			cfg := DefaultScoreConfig()
+			if tt.config != nil {
+				tt.config(&cfg)
+			}
+			if got := ids(OrderQueue(entries, cfg)); got != tt.want {
+				t.Errorf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderQueueFairnessInBreakdown(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	var entries []*QueueEntry
+	for _, id := range []string{"a1", "a2", "a3"} {
+		entries = append(entries, &QueueEntry{ID: id, Convoy: "big",
+			Input: ScoreInput{Priority: 1, MRCreatedAt: now, Now: now}})
+	}
+	cfg := DefaultScoreConfig()
+	cfg.ConvoyCap = 1
+
+	ordered := OrderQueue(entries, cfg)
+	for i, want := range []float64{0, 200, 400} {
+		if got := ordered[i].Score.Fairness; got != want {
+			t.Errorf("%s fairness = %v, want %v", ordered[i].ID, got, want)
+		}
+		if got := ordered[i].Score.Total; got != 1300-want {
+			t.Errorf("%s total = %v, want %v", ordered[i].ID, got, 1300-want)
+		}
+	}
+}
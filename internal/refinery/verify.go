@@ -0,0 +1,497 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+const (
+	// verifyLogRuns is how many run directories of stage logs are kept.
+	verifyLogRuns = 50
+
+	// verifyCacheEntries bounds the verified-tree cache.
+	verifyCacheEntries = 500
+
+	// verifyOutputLines is how much of a failing stage's output is sent to
+	// the polecat; the full output stays in the stage log.
+	verifyOutputLines = 60
+
+	// verifyTailBytes is how much output is buffered in memory per stage.
+	verifyTailBytes = 64 * 1024
+
+	// verifyWaitDelay bounds how long a timed-out stage may hold its output
+	// pipes open (e.g., background children of the shell) after being killed.
+	verifyWaitDelay = 5 * time.Second
+)
+
+// Pipeline returns the verification stages in run order: VerifyStages when
+// set, otherwise setup, build, typecheck, lint and test from the individual
+// commands (skipping empty ones). RunTests=false disables verification.
+func (c *MergeQueueConfig) Pipeline() []config.VerifyStage {
+	if !c.RunTests {
+		return nil
+	}
+	if len(c.VerifyStages) > 0 {
+		return c.VerifyStages
+	}
+	var stages []config.VerifyStage
+	for _, st := range []config.VerifyStage{
+		{Name: config.VerifyStageSetup, Command: c.SetupCommand},
+		{Name: config.VerifyStageBuild, Command: c.BuildCommand},
+		{Name: config.VerifyStageTypecheck, Command: c.TypecheckCommand},
+		{Name: config.VerifyStageLint, Command: c.LintCommand},
+		{Name: config.VerifyStageTest, Command: c.TestCommand},
+	} {
+		if strings.TrimSpace(st.Command) != "" {
+			stages = append(stages, st)
+		}
+	}
+	return stages
+}
+
+// StageResult is the outcome of one verification stage.
+type StageResult struct {
+	Name     string        `json:"name"`
+	Success  bool          `json:"success"`
+	Attempts int           `json:"attempts"`
+	Duration time.Duration `json:"duration"`
+	LogPath  string        `json:"log_path,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Output   string        `json:"-"` // Tail of the output, kept for failures
+
+	// Per-test findings of a test stage whose results could be parsed.
+	FailedTests []string `json:"failed_tests,omitempty"`
+	Flaky       []string `json:"flaky,omitempty"`       // Failed, then passed on this tree
+	Quarantined []string `json:"quarantined,omitempty"` // Failed but quarantined
+}
+
+// VerifyResult is the outcome of the verification pipeline.
+type VerifyResult struct {
+	Success bool          `json:"success"`
+	Cached  bool          `json:"cached,omitempty"` // Tree already passed; no stage ran
+	Tree    string        `json:"tree,omitempty"`
+	LogDir  string        `json:"log_dir,omitempty"`
+	Stages  []StageResult `json:"stages,omitempty"`
+	Failed  *StageResult  `json:"failed,omitempty"`
+}
+
+// Summary returns the short form recorded on the MR bead:
+// "passed", "cached" or "failed: <stage>".
+func (r *VerifyResult) Summary() string {
+	switch {
+	case r.Cached:
+		return "cached"
+	case r.Success:
+		return "passed"
+	case r.Failed != nil:
+		return "failed: " + r.Failed.Name
+	default:
+		return "failed"
+	}
+}
+
+// verify runs the verification pipeline against the tree checked out in the
+// Refinery worktree. Stage logs go to a directory named after runID (normally
+// the MR ID). A tree that already passed the same pipeline is not re-run.
+func (e *Engineer) verify(ctx context.Context, runID string) *VerifyResult {
+	stages := e.config.Pipeline()
+	res := &VerifyResult{Success: true}
+	if len(stages) == 0 {
+		return res
+	}
+
+	cacheKey := ""
+	if tree, err := e.git.Rev("HEAD^{tree}"); err == nil {
+		res.Tree = tree
+		cacheKey = verifyCacheKey(tree, stages)
+	}
+	cache := e.loadVerifyCache()
+	if e.config.VerifyCache && cacheKey != "" && cache.has(cacheKey) {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Tree %s already verified, skipping %d stage(s)\n", shortTree(res.Tree), len(stages))
+		res.Cached = true
+		return res
+	}
+
+	res.LogDir = filepath.Join(e.verifyLogRoot(), verifyRunName(runID, res.Tree))
+	_ = os.RemoveAll(res.LogDir)
+	if err := os.MkdirAll(res.LogDir, 0755); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: cannot create stage log dir: %v\n", err)
+		res.LogDir = ""
+	}
+
+	index := 0
+	for _, group := range groupStages(stages) {
+		results := e.runStageGroup(ctx, group, res.Tree, res.LogDir, index)
+		index += len(group)
+		base := len(res.Stages)
+		res.Stages = append(res.Stages, results.stages...)
+		if results.failed >= 0 {
+			res.Success = false
+			res.Failed = &res.Stages[base+results.failed]
+			break
+		}
+	}
+	pruneVerifyLogs(e.verifyLogRoot(), verifyLogRuns)
+
+	if res.Success && cacheKey != "" {
+		cache.add(cacheKey, time.Now())
+		if err := e.saveVerifyCache(cache); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save verify cache: %v\n", err)
+		}
+	}
+	return res
+}
+
+// groupStages splits the pipeline into run groups: each run of adjacent
+// parallel stages forms one group, every other stage is a group of its own.
+func groupStages(stages []config.VerifyStage) [][]config.VerifyStage {
+	var groups [][]config.VerifyStage
+	for i, st := range stages {
+		if st.Parallel && i > 0 && stages[i-1].Parallel {
+			groups[len(groups)-1] = append(groups[len(groups)-1], st)
+			continue
+		}
+		groups = append(groups, []config.VerifyStage{st})
+	}
+	return groups
+}
+
+// groupResult holds a group's stage results and the index of the stage whose
+// failure stopped the pipeline (-1 when all passed).
+type groupResult struct {
+	stages []StageResult
+	failed int
+}
+
+// runStageGroup runs the stages of a group concurrently. The first stage to
+// fail cancels the rest of the group.
+func (e *Engineer) runStageGroup(ctx context.Context, group []config.VerifyStage, tree, logDir string, index int) groupResult {
+	out := groupResult{stages: make([]StageResult, len(group)), failed: -1}
+	if len(group) == 1 {
+		out.stages[0] = e.runStage(ctx, group[0], tree, stageLogPath(logDir, index, group[0].Name))
+		if !out.stages[0].Success {
+			out.failed = 0
+		}
+		return out
+	}
+
+	names := make([]string, len(group))
+	for i, st := range group {
+		names[i] = st.Name
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Running stages in parallel: %s\n", strings.Join(names, ", "))
+
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for i, st := range group {
+		wg.Add(1)
+		go func(i int, st config.VerifyStage) {
+			defer wg.Done()
+			r := e.runStage(groupCtx, st, tree, stageLogPath(logDir, index+i, st.Name))
+			mu.Lock()
+			defer mu.Unlock()
+			out.stages[i] = r
+			if !r.Success && out.failed < 0 {
+				out.failed = i
+				cancel()
+			}
+		}(i, st)
+	}
+	wg.Wait()
+	return out
+}
+
+// runStage runs one stage, retrying test stages per RetryFlakyTests, and
+// writes its combined output to logPath (if set). When a test stage's
+// results can be parsed, retries rerun only the failing tests, failures of
+// quarantined tests are ignored, and the outcomes are added to the rig's
+// test history under tree.
+func (e *Engineer) runStage(ctx context.Context, st config.VerifyStage, tree, logPath string) (res StageResult) {
+	res = StageResult{Name: st.Name, LogPath: logPath}
+	if err := ValidateTestCommand(st.Command); err != nil {
+		res.Error = fmt.Sprintf("invalid %s command: %v", st.Name, err)
+		return res
+	}
+
+	maxAttempts := 1
+	if st.Name == config.VerifyStageTest && e.config.RetryFlakyTests > 1 {
+		maxAttempts = e.config.RetryFlakyTests
+	}
+
+	var logFile io.Writer = io.Discard
+	if logPath != "" {
+		f, err := os.Create(logPath) //nolint:gosec // G304: path is built from the rig's runtime dir
+		if err == nil {
+			defer f.Close()
+			logFile = f
+		}
+	}
+
+	var tests *testTracker
+	if st.Name == config.VerifyStageTest && st.Results != "none" {
+		tests = e.newTestTracker(st, tree)
+		defer func() {
+			tests.fill(&res)
+			tests.record(e.output)
+		}()
+	}
+
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		res.Attempts = attempt
+		command := st.Command
+		if attempt > 1 {
+			if rerun := tests.rerunCommand(); rerun != "" {
+				command = rerun
+			}
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying %s (attempt %d/%d)...\n", st.Name, attempt, maxAttempts)
+		}
+		_, _ = fmt.Fprintf(logFile, "=== %s (attempt %d/%d): %s\n", st.Name, attempt, maxAttempts, command)
+
+		// Trust boundary: stage commands come from the rig's config.json
+		// (operator-controlled infrastructure config), not from PR branches or
+		// user input. Shell execution is intentional for flexibility.
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Stage %s: %s\n", st.Name, command)
+		tail := &tailWriter{max: verifyTailBytes}
+		combined := io.MultiWriter(logFile, tail)
+		stdout := combined
+		var captured bytes.Buffer
+		if tests != nil {
+			tests.prepare(e.workDir)
+			stdout = io.MultiWriter(combined, &captured)
+		}
+		err := e.execStage(ctx, command, st.TimeoutDuration(), stdout, combined)
+
+		if tests != nil {
+			attributable := tests.observe(e.workDir, captured.Bytes(), err)
+			switch {
+			case err == nil && command != st.Command && len(tests.pending) > 0:
+				// The rerun passed without running the failing tests (e.g., the
+				// pattern matched nothing); fall back to rerunning everything.
+				tests.noRerun = true
+				err = fmt.Errorf("rerun did not run the failing tests")
+			case err != nil && attributable && len(tests.pending) == 0:
+				// Every failure was quarantined or passed on a rerun.
+				err = nil
+			}
+		}
+		if err == nil {
+			res.Success = true
+			res.Output = ""
+			_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Stage %s passed (%s)\n", st.Name, time.Since(start).Round(time.Second))
+			if tests != nil {
+				if ids := sortedKeys(tests.flaky); len(ids) > 0 {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Flaky tests (passed on rerun): %s\n", strings.Join(ids, ", "))
+				}
+				if ids := sortedKeys(tests.quarantined); len(ids) > 0 {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Ignored quarantined test failures: %s\n", strings.Join(ids, ", "))
+				}
+			}
+			return res
+		}
+		lastErr = err
+		res.Output = lastLines(string(tail.buf), verifyOutputLines)
+		if out := tests.failureOutput(); out != "" {
+			res.Output = out
+		}
+		_, _ = fmt.Fprintf(logFile, "=== %s: %v\n", st.Name, err)
+
+		// Timeouts and cancellation are not flaky failures; don't retry them.
+		if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		res.Error = fmt.Sprintf("%s canceled", st.Name)
+	case res.Attempts > 1:
+		res.Error = fmt.Sprintf("%s failed after %d attempts: %v", st.Name, res.Attempts, lastErr)
+	default:
+		res.Error = fmt.Sprintf("%s failed: %v", st.Name, lastErr)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Stage %s: %s\n", st.Name, res.Error)
+	return res
+}
+
+// execStage runs a stage command once under timeout (zero means none).
+func (e *Engineer) execStage(ctx context.Context, command string, timeout time.Duration, stdout, stderr io.Writer) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: stage commands are from trusted rig config
+	cmd.Dir = e.workDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = verifyWaitDelay
+	setStageProcessGroup(cmd)
+	err := cmd.Run()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// tailWriter keeps the last max bytes written to it.
+type tailWriter struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d earlier lines in the stage log)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// verifyLogRoot is where stage logs are kept: <rig>/.runtime/refinery/verify.
+func (e *Engineer) verifyLogRoot() string {
+	return filepath.Join(constants.RigRuntimePath(e.rig.Path), "refinery", "verify")
+}
+
+var unsafeRunName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// verifyRunName names a run's log directory after the MR, falling back to
+// the tree hash for runs without one.
+func verifyRunName(runID, tree string) string {
+	name := unsafeRunName.ReplaceAllString(runID, "-")
+	if name == "" || name == "." || name == ".." {
+		name = "tree-" + shortTree(tree)
+	}
+	return name
+}
+
+func stageLogPath(logDir string, index int, name string) string {
+	if logDir == "" {
+		return ""
+	}
+	return filepath.Join(logDir, fmt.Sprintf("%02d-%s.log", index+1, unsafeRunName.ReplaceAllString(name, "-")))
+}
+
+func shortTree(tree string) string {
+	if len(tree) > 12 {
+		return tree[:12]
+	}
+	return tree
+}
+
+// pruneVerifyLogs keeps the keep most recently modified run directories.
+func pruneVerifyLogs(root string, keep int) {
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) <= keep {
+		return
+	}
+	type run struct {
+		path string
+		mod  time.Time
+	}
+	var runs []run
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && entry.IsDir() {
+			runs = append(runs, run{filepath.Join(root, entry.Name()), info.ModTime()})
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].mod.After(runs[j].mod) })
+	for i := keep; i < len(runs); i++ {
+		_ = os.RemoveAll(runs[i].path)
+	}
+}
+
+// verifyCacheKey identifies a tree verified by a particular pipeline, so
+// changing a stage command invalidates earlier results.
+func verifyCacheKey(tree string, stages []config.VerifyStage) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n", tree)
+	for _, st := range stages {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\n", st.Name, st.Command)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyCache records which tree/pipeline pairs passed verification.
+type verifyCache struct {
+	Verified map[string]time.Time `json:"verified"`
+}
+
+func (c *verifyCache) has(key string) bool {
+	_, ok := c.Verified[key]
+	return ok
+}
+
+// add records key, evicting the oldest entries beyond verifyCacheEntries.
+func (c *verifyCache) add(key string, at time.Time) {
+	c.Verified[key] = at
+	if len(c.Verified) <= verifyCacheEntries {
+		return
+	}
+	keys := make([]string, 0, len(c.Verified))
+	for k := range c.Verified {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.Verified[keys[i]].Before(c.Verified[keys[j]]) })
+	for _, k := range keys[:len(keys)-verifyCacheEntries] {
+		delete(c.Verified, k)
+	}
+}
+
+func (e *Engineer) verifyCachePath() string {
+	return filepath.Join(constants.RigRuntimePath(e.rig.Path), "refinery", "verified.json")
+}
+
+// loadVerifyCache reads the verified-tree cache. A missing or unreadable
+// cache is empty.
+func (e *Engineer) loadVerifyCache() *verifyCache {
+	cache := &verifyCache{}
+	if data, err := os.ReadFile(e.verifyCachePath()); err == nil {
+		_ = json.Unmarshal(data, cache)
+	}
+	if cache.Verified == nil {
+		cache.Verified = make(map[string]time.Time)
+	}
+	return cache
+}
+
+func (e *Engineer) saveVerifyCache(cache *verifyCache) error {
+	return util.EnsureDirAndWriteJSON(e.verifyCachePath(), cache)
+}
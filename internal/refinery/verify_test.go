@@ -0,0 +1,314 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/testresults"
+)
+
+// newVerifyEngineer returns an Engineer whose worktree is a fresh git repo
+// with one commit, so verification has a tree to key its cache on.
+func newVerifyEngineer(t *testing.T, cfg *MergeQueueConfig) *Engineer {
+	t.Helper()
+	rigPath := t.TempDir()
+	work := filepath.Join(rigPath, "refinery", "rig")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: rigPath},
+		git:     git.NewGit(work),
+		config:  cfg,
+		workDir: work,
+		output:  &bytes.Buffer{},
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.SetupCommand = "npm ci"
+	cfg.LintCommand = "eslint ."
+	cfg.TestCommand = "npm test"
+
+	var names []string
+	for _, st := range cfg.Pipeline() {
+		names = append(names, st.Name)
+	}
+	if got := strings.Join(names, ","); got != "setup,lint,test" {
+		t.Errorf("derived pipeline = %s, want setup,lint,test", got)
+	}
+
+	cfg.VerifyStages = []config.VerifyStage{{Name: "e2e", Command: "make e2e"}}
+	if got := cfg.Pipeline(); len(got) != 1 || got[0].Name != "e2e" {
+		t.Errorf("explicit pipeline = %+v", got)
+	}
+
+	cfg.RunTests = false
+	if got := cfg.Pipeline(); len(got) != 0 {
+		t.Errorf("run_tests=false should disable verification, got %+v", got)
+	}
+}
+
+func TestGroupStages(t *testing.T) {
+	groups := groupStages([]config.VerifyStage{
+		{Name: "setup"},
+		{Name: "typecheck", Parallel: true},
+		{Name: "lint", Parallel: true},
+		{Name: "test"},
+		{Name: "e2e", Parallel: true},
+	})
+	var got []int
+	for _, g := range groups {
+		got = append(got, len(g))
+	}
+	if len(got) != 4 || got[0] != 1 || got[1] != 2 || got[2] != 1 || got[3] != 1 {
+		t.Errorf("group sizes = %v, want [1 2 1 1]", got)
+	}
+}
+
+func TestVerify_StopsAtFailingStage(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.VerifyStages = []config.VerifyStage{
+		{Name: "build", Command: "echo compiling"},
+		{Name: "lint", Command: "echo 'main.go:3: unused variable x'; exit 1"},
+		{Name: "test", Command: "echo should-not-run"},
+	}
+	e := newVerifyEngineer(t, cfg)
+
+	res := e.verify(context.Background(), "gt-mr1")
+	if res.Success {
+		t.Fatal("expected verification to fail")
+	}
+	if res.Failed == nil || res.Failed.Name != "lint" {
+		t.Fatalf("Failed = %+v, want lint", res.Failed)
+	}
+	if len(res.Stages) != 2 {
+		t.Errorf("ran %d stages, want 2 (test must not run)", len(res.Stages))
+	}
+	if !strings.Contains(res.Failed.Output, "unused variable x") {
+		t.Errorf("failure output = %q", res.Failed.Output)
+	}
+	if res.Summary() != "failed: lint" {
+		t.Errorf("Summary() = %q", res.Summary())
+	}
+
+	log, err := os.ReadFile(res.Failed.LogPath)
+	if err != nil {
+		t.Fatalf("reading stage log: %v", err)
+	}
+	if !strings.Contains(string(log), "unused variable x") {
+		t.Errorf("stage log missing output:\n%s", log)
+	}
+	if filepath.Base(res.LogDir) != "gt-mr1" {
+		t.Errorf("LogDir = %s, want .../gt-mr1", res.LogDir)
+	}
+}
+
+func TestVerify_CachesVerifiedTree(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	e := newVerifyEngineer(t, cfg)
+	marker := filepath.Join(t.TempDir(), "runs")
+	cfg.VerifyStages = []config.VerifyStage{{Name: "test", Command: "echo run >> " + marker}}
+
+	if res := e.verify(context.Background(), "gt-mr1"); !res.Success || res.Cached {
+		t.Fatalf("first run = %+v, want uncached success", res)
+	}
+	if res := e.verify(context.Background(), "gt-mr2"); !res.Success || !res.Cached {
+		t.Fatalf("second run = %+v, want cached success", res)
+	}
+
+	// Changing the pipeline invalidates the cache.
+	cfg.VerifyStages[0].Command += " # changed"
+	if res := e.verify(context.Background(), "gt-mr3"); res.Cached {
+		t.Error("changed pipeline should not hit the cache")
+	}
+
+	// Disabling the cache always runs.
+	cfg.VerifyCache = false
+	if res := e.verify(context.Background(), "gt-mr4"); res.Cached {
+		t.Error("verify_cache=false should not hit the cache")
+	}
+
+	data, _ := os.ReadFile(marker)
+	if n := strings.Count(string(data), "run"); n != 3 {
+		t.Errorf("stage ran %d times, want 3", n)
+	}
+}
+
+func TestVerify_ParallelGroupAndTimeout(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.VerifyStages = []config.VerifyStage{
+		{Name: "slow", Command: "sleep 5", Parallel: true, Timeout: "200ms"},
+		{Name: "fast", Command: "true", Parallel: true},
+	}
+	e := newVerifyEngineer(t, cfg)
+
+	res := e.verify(context.Background(), "gt-mr1")
+	if res.Success || res.Failed == nil || res.Failed.Name != "slow" {
+		t.Fatalf("result = %+v, want slow stage to fail", res)
+	}
+	if !strings.Contains(res.Failed.Error, "timed out") {
+		t.Errorf("Error = %q, want timeout", res.Failed.Error)
+	}
+	if !res.Stages[1].Success {
+		t.Errorf("fast stage = %+v, want success", res.Stages[1])
+	}
+	if res.Failed.Duration <= 0 {
+		t.Errorf("Duration = %v, want > 0", res.Failed.Duration)
+	}
+}
+
+func TestRunStage_RetriesOnlyTests(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.RetryFlakyTests = 3
+	e := newVerifyEngineer(t, cfg)
+
+	r := e.runStage(context.Background(), config.VerifyStage{Name: "build", Command: "exit 1"}, "", "")
+	if r.Attempts != 1 {
+		t.Errorf("build attempts = %d, want 1", r.Attempts)
+	}
+	r = e.runStage(context.Background(), config.VerifyStage{Name: "test", Command: "exit 1"}, "", "")
+	if r.Attempts != 3 || !strings.Contains(r.Error, "after 3 attempts") {
+		t.Errorf("test stage = %+v, want 3 attempts", r)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	in := strings.Repeat("line\n", 100)
+	out := lastLines(in, 10)
+	if strings.Count(out, "\n") != 10 || !strings.HasPrefix(out, "... (90 earlier lines") {
+		t.Errorf("lastLines = %q", out)
+	}
+}
+
+// writeGoJSON writes go test -json events for TestA (with the given status)
+// and a passing TestB, returning the file's path.
+func writeGoJSON(t *testing.T, dir, name, statusA string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := `{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"a_test.go:7: boom\n"}
+{"Action":"` + statusA + `","Package":"example.com/a","Test":"TestA"}
+{"Action":"pass","Package":"example.com/a","Test":"TestB"}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunStage_RerunsOnlyFailingTests(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.RetryFlakyTests = 3
+	e := newVerifyEngineer(t, cfg)
+	dir := t.TempDir()
+	fail := writeGoJSON(t, dir, "fail.json", "fail")
+	pass := writeGoJSON(t, dir, "pass.json", "pass")
+	args := filepath.Join(dir, "rerun-args")
+
+	st := config.VerifyStage{
+		Name:         "test",
+		Command:      "cat " + fail + "; exit 1",
+		RerunCommand: "echo {pattern} {packages} > " + args + "; cat " + pass,
+	}
+	r := e.runStage(context.Background(), st, "tree1", "")
+	if !r.Success || r.Attempts != 2 {
+		t.Fatalf("result = %+v, want success on the second attempt", r)
+	}
+	if len(r.Flaky) != 1 || r.Flaky[0] != "example.com/a.TestA" {
+		t.Errorf("Flaky = %v, want [example.com/a.TestA]", r.Flaky)
+	}
+	got, _ := os.ReadFile(args)
+	if strings.TrimSpace(string(got)) != "^(TestA)$ example.com/a" {
+		t.Errorf("rerun args = %q", got)
+	}
+
+	h, err := testresults.LoadHistory(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := h.Tests["example.com/a.TestA"]; rec == nil || rec.Flips != 1 {
+		t.Errorf("history for TestA = %+v, want one flip", rec)
+	}
+}
+
+func TestRunStage_PersistentTestFailure(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.RetryFlakyTests = 2
+	e := newVerifyEngineer(t, cfg)
+	fail := writeGoJSON(t, t.TempDir(), "fail.json", "fail")
+
+	st := config.VerifyStage{Name: "test", Command: "cat " + fail + "; exit 1", RerunCommand: "cat " + fail + "; exit 1"}
+	r := e.runStage(context.Background(), st, "tree1", "")
+	if r.Success {
+		t.Fatal("expected the stage to fail")
+	}
+	if len(r.FailedTests) != 1 || r.FailedTests[0] != "example.com/a.TestA" {
+		t.Errorf("FailedTests = %v", r.FailedTests)
+	}
+	if !strings.Contains(r.Output, "--- FAIL: example.com/a.TestA") || !strings.Contains(r.Output, "boom") {
+		t.Errorf("Output = %q, want the failing test and its output", r.Output)
+	}
+}
+
+func TestRunStage_IgnoresQuarantinedFailures(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	e := newVerifyEngineer(t, cfg)
+	fail := writeGoJSON(t, t.TempDir(), "fail.json", "fail")
+
+	q, _ := testresults.LoadQuarantine(e.rig.Path)
+	q.Tests["TestA"] = testresults.QuarantineEntry{Reason: "flaky"}
+	if err := q.Save(e.rig.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	r := e.runStage(context.Background(), config.VerifyStage{Name: "test", Command: "cat " + fail + "; exit 1"}, "tree1", "")
+	if !r.Success || r.Attempts != 1 {
+		t.Fatalf("result = %+v, want success despite the quarantined failure", r)
+	}
+	if len(r.Quarantined) != 1 || r.Quarantined[0] != "example.com/a.TestA" {
+		t.Errorf("Quarantined = %v", r.Quarantined)
+	}
+}
+
+func TestRunStage_UnattributableFailureRerunsEverything(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.RetryFlakyTests = 2
+	e := newVerifyEngineer(t, cfg)
+	dir := t.TempDir()
+	fail := writeGoJSON(t, dir, "fail.json", "fail")
+	marker := filepath.Join(dir, "rerun")
+
+	// A package that failed to build alongside the failing test means a
+	// passing rerun of TestA alone proves nothing.
+	st := config.VerifyStage{
+		Name:         "test",
+		Command:      "cat " + fail + `; echo '{"Action":"fail","Package":"example.com/b"}'; exit 1`,
+		RerunCommand: "touch " + marker,
+	}
+	r := e.runStage(context.Background(), st, "tree1", "")
+	if r.Success || r.Attempts != 2 {
+		t.Fatalf("result = %+v, want two full failing attempts", r)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("selective rerun ran despite an unattributable failure")
+	}
+}
@@ -0,0 +1,235 @@
+package refinery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/testresults"
+)
+
+const (
+	// goRerunCommand reruns failing Go tests when the stage has no
+	// rerun_command of its own.
+	goRerunCommand = "go test -json -run {pattern} {packages}"
+
+	// failedTestsShown and failedTestLines bound the per-test output sent
+	// to the polecat.
+	failedTestsShown = 5
+	failedTestLines  = 20
+)
+
+// testTracker follows the per-test results of a test stage across its
+// attempts. A failing test blocks the merge only if it is not quarantined
+// and never passed on this tree; a test that both failed and passed is
+// flaky. Failing tests are rerun on their own when the stage says how.
+type testTracker struct {
+	stage      config.VerifyStage
+	tree       string
+	rigPath    string
+	quarantine *testresults.Quarantine
+
+	format      string
+	outcomes    map[string][]testresults.Status
+	results     map[string]testresults.Result // Latest result per test ID
+	pending     map[string]testresults.Result // Failing, not quarantined, never passed
+	quarantined map[string]bool               // Quarantined tests that failed
+	flaky       map[string]bool
+
+	noRerun bool // An attempt failed outside any test; rerun everything
+}
+
+func (e *Engineer) newTestTracker(st config.VerifyStage, tree string) *testTracker {
+	q, err := testresults.LoadQuarantine(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: ignoring test quarantine: %v\n", err)
+	}
+	return &testTracker{
+		stage:       st,
+		tree:        tree,
+		rigPath:     e.rig.Path,
+		quarantine:  q,
+		format:      st.Results,
+		outcomes:    make(map[string][]testresults.Status),
+		results:     make(map[string]testresults.Result),
+		pending:     make(map[string]testresults.Result),
+		quarantined: make(map[string]bool),
+		flaky:       make(map[string]bool),
+	}
+}
+
+// prepare clears a stale results file before an attempt.
+func (t *testTracker) prepare(workDir string) {
+	if t.stage.ResultsFile != "" {
+		_ = os.Remove(filepath.Join(workDir, t.stage.ResultsFile))
+	}
+}
+
+// observe folds one attempt's results into the tracker. It reports whether
+// the attempt's outcome is fully explained by its per-test results: they
+// parsed, and a failed run had failing tests and no other errors.
+func (t *testTracker) observe(workDir string, stdout []byte, runErr error) bool {
+	data := stdout
+	if t.stage.ResultsFile != "" {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(workDir, t.stage.ResultsFile)); err != nil { //nolint:gosec // G304: path is from trusted rig config
+			t.noRerun = true
+			return false
+		}
+	}
+	report, err := testresults.Parse(t.format, data)
+	if err != nil {
+		t.noRerun = true
+		return false
+	}
+	t.format = report.Format
+
+	for _, r := range report.Tests {
+		id := r.ID()
+		prev := t.outcomes[id]
+		t.outcomes[id] = append(prev, r.Status)
+		t.results[id] = r
+		switch r.Status {
+		case testresults.StatusFail:
+			if t.quarantine.Has(r) {
+				t.quarantined[id] = true
+			} else if !containsStatus(prev, testresults.StatusPass) {
+				t.pending[id] = r
+			}
+			if containsStatus(prev, testresults.StatusPass) {
+				t.flaky[id] = true
+			}
+		case testresults.StatusPass:
+			if containsStatus(prev, testresults.StatusFail) {
+				t.flaky[id] = true
+				delete(t.pending, id)
+			}
+		}
+	}
+
+	attributable := len(report.Errors) == 0 && (runErr == nil || len(report.Failed()) > 0)
+	if !attributable {
+		t.noRerun = true
+	}
+	return attributable
+}
+
+// rerunCommand returns the command that reruns only the pending tests, or
+// "" to rerun the whole stage.
+func (t *testTracker) rerunCommand() string {
+	if t == nil || t.noRerun || len(t.pending) == 0 {
+		return ""
+	}
+	template := t.stage.RerunCommand
+	if template == "" {
+		if t.format != testresults.FormatGoJSON {
+			return ""
+		}
+		template = goRerunCommand
+	}
+
+	tests := t.pendingResults()
+	names := make([]string, len(tests))
+	for i, r := range tests {
+		names[i] = config.ShellQuote(r.Name)
+	}
+	var packages []string
+	for _, pkg := range testresults.Suites(tests) {
+		packages = append(packages, config.ShellQuote(pkg))
+	}
+	return strings.NewReplacer(
+		"{tests}", strings.Join(names, " "),
+		"{pattern}", config.ShellQuote(testresults.RerunPattern(tests)),
+		"{packages}", strings.Join(packages, " "),
+	).Replace(template)
+}
+
+func (t *testTracker) pendingResults() []testresults.Result {
+	tests := make([]testresults.Result, 0, len(t.pending))
+	for _, r := range t.pending {
+		tests = append(tests, r)
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].ID() < tests[j].ID() })
+	return tests
+}
+
+// failureOutput lists the tests still failing with the tail of their output.
+func (t *testTracker) failureOutput() string {
+	if t == nil {
+		return ""
+	}
+	tests := t.pendingResults()
+	if len(tests) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for i, r := range tests {
+		if i == failedTestsShown {
+			sb.WriteString(fmt.Sprintf("... and %d more failing tests\n", len(tests)-i))
+			break
+		}
+		sb.WriteString("--- FAIL: " + r.ID() + "\n")
+		// The latest result carries the output of the most recent run.
+		if out := strings.TrimSpace(t.results[r.ID()].Output); out != "" {
+			sb.WriteString(lastLines(out, failedTestLines) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// fill copies the tracker's findings into the stage result.
+func (t *testTracker) fill(res *StageResult) {
+	res.FailedTests = sortedKeys(t.pendingIDs())
+	res.Flaky = sortedKeys(t.flaky)
+	res.Quarantined = sortedKeys(t.quarantined)
+}
+
+func (t *testTracker) pendingIDs() map[string]bool {
+	ids := make(map[string]bool, len(t.pending))
+	for id := range t.pending {
+		ids[id] = true
+	}
+	return ids
+}
+
+// record adds the stage's outcomes to the rig's test history.
+func (t *testTracker) record(w io.Writer) {
+	if len(t.outcomes) == 0 {
+		return
+	}
+	h, err := testresults.LoadHistory(t.rigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "[Engineer] Warning: not recording test history: %v\n", err)
+		return
+	}
+	h.Record(t.tree, t.outcomes, time.Now())
+	if err := h.Save(t.rigPath); err != nil {
+		_, _ = fmt.Fprintf(w, "[Engineer] Warning: failed to save test history: %v\n", err)
+	}
+}
+
+func containsStatus(statuses []testresults.Status, s testresults.Status) bool {
+	for _, v := range statuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
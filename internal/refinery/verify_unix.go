@@ -0,0 +1,17 @@
+//go:build unix
+
+package refinery
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setStageProcessGroup runs a stage in its own process group so a timeout
+// kills everything the stage started, not just the shell.
+func setStageProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package refinery
+
+import "os/exec"
+
+// setStageProcessGroup is a no-op on Windows; a timeout kills the shell and
+// WaitDelay bounds how long its children can hold the output open.
+func setStageProcessGroup(cmd *exec.Cmd) {}
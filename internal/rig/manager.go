@@ -31,6 +31,35 @@ var (
 // EnsureMetadata and dolt routing as the town-level beads alias.
 var reservedRigNames = []string{"hq"}
 
+// ValidateName reports whether name can be used as a rig name. The name
+// becomes a directory under the town root, so path separators and ".."
+// are rejected along with the characters agent ID parsing relies on.
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New("rig name is empty")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("rig name %q must not contain path separators or \"..\"", name)
+	}
+
+	// Reject characters that break agent ID parsing.
+	// Agent IDs use format <prefix>-<rig>-<role>[-<name>] with hyphens as delimiters
+	if strings.ContainsAny(name, "-. ") {
+		sanitized := strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+		sanitized = strings.ToLower(sanitized)
+		return fmt.Errorf("rig name %q contains invalid characters; hyphens, dots, and spaces are reserved for agent ID parsing. Try %q instead (underscores are allowed)", name, sanitized)
+	}
+
+	// Reject reserved names that collide with town-level infrastructure.
+	// "hq" is special-cased by EnsureMetadata and dolt routing as the town-level alias.
+	for _, reserved := range reservedRigNames {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("rig name %q is reserved for town-level infrastructure", name)
+		}
+	}
+	return nil
+}
+
 // wrapCloneError wraps clone errors with helpful suggestions.
 // Detects common auth failures and suggests SSH as an alternative.
 func wrapCloneError(err error, gitURL string) error {
@@ -269,20 +298,8 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		return nil, ErrRigExists
 	}
 
-	// Validate rig name: reject characters that break agent ID parsing
-	// Agent IDs use format <prefix>-<rig>-<role>[-<name>] with hyphens as delimiters
-	if strings.ContainsAny(opts.Name, "-. ") {
-		sanitized := strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(opts.Name)
-		sanitized = strings.ToLower(sanitized)
-		return nil, fmt.Errorf("rig name %q contains invalid characters; hyphens, dots, and spaces are reserved for agent ID parsing. Try %q instead (underscores are allowed)", opts.Name, sanitized)
-	}
-
-	// Reject reserved names that collide with town-level infrastructure.
-	// "hq" is special-cased by EnsureMetadata and dolt routing as the town-level alias.
-	for _, reserved := range reservedRigNames {
-		if strings.EqualFold(opts.Name, reserved) {
-			return nil, fmt.Errorf("rig name %q is reserved for town-level infrastructure", opts.Name)
-		}
+	if err := ValidateName(opts.Name); err != nil {
+		return nil, err
 	}
 
 	rigPath := filepath.Join(m.townRoot, opts.Name)
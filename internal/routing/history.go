@@ -0,0 +1,131 @@
+package routing
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Outcome counts finished attempts.
+type Outcome struct {
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+}
+
+// History is the success record of rig/agent pairs, learned from the events
+// log. An attempt starts with a sling (or routed) event and succeeds when the
+// bead's worker runs gt done. It fails when the bead is slung again before
+// done (the previous worker died or was replaced) or when the merge of the
+// branch reported by done fails.
+type History struct {
+	pairs map[string]*Outcome // "rig/agent"
+	rigs  map[string]*Outcome // "rig", all agents
+}
+
+// NewHistory returns an empty history.
+func NewHistory() *History {
+	return &History{pairs: make(map[string]*Outcome), rigs: make(map[string]*Outcome)}
+}
+
+// Rate returns the smoothed success rate of agent on rig and the number of
+// finished attempts behind it. Pairs with no record fall back to the rig's
+// record across agents; with no record at all the rate is 0.5.
+// Smoothing ((s+1)/(n+2)) keeps a single failure from ruling a pair out.
+func (h *History) Rate(rig, agent string) (float64, int) {
+	o := h.pairs[rig+"/"+agent]
+	if o == nil || o.Success+o.Failure == 0 {
+		o = h.rigs[rig]
+	}
+	if o == nil {
+		return 0.5, 0
+	}
+	n := o.Success + o.Failure
+	return float64(o.Success+1) / float64(n+2), n
+}
+
+// Outcomes returns the per-pair record, keyed "rig/agent".
+func (h *History) Outcomes() map[string]Outcome {
+	out := make(map[string]Outcome, len(h.pairs))
+	for k, v := range h.pairs {
+		out[k] = *v
+	}
+	return out
+}
+
+func (h *History) record(rig, agent string, success bool) {
+	for _, key := range []struct {
+		m map[string]*Outcome
+		k string
+	}{{h.pairs, rig + "/" + agent}, {h.rigs, rig}} {
+		o := key.m[key.k]
+		if o == nil {
+			o = &Outcome{}
+			key.m[key.k] = o
+		}
+		if success {
+			o.Success++
+		} else {
+			o.Failure++
+		}
+	}
+}
+
+// LoadHistory reads the town's events log and returns what it learned from
+// events newer than since ago (zero means all). A missing log is empty history.
+func LoadHistory(townRoot string, since time.Duration) (*History, error) {
+	h := NewHistory()
+	f, err := os.Open(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	var evs []events.Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !cutoff.IsZero() {
+			if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil && ts.Before(cutoff) {
+				continue
+			}
+		}
+		evs = append(evs, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	h.learn(evs)
+	return h, nil
+}
+
+// learn replays events in order.
+func (h *History) learn(evs []events.Event) {
+	r := events.NewAttempts()
+	for i := range evs {
+		r.Add(&evs[i])
+	}
+	for _, a := range r.All {
+		switch {
+		case a.Superseded:
+			h.record(a.Rig, a.Agent, false)
+		case a.Done:
+			h.record(a.Rig, a.Agent, !a.MergeFailed)
+		}
+	}
+}
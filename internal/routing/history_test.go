@@ -0,0 +1,94 @@
+package routing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func writeEvents(t *testing.T, dir string, evs []events.Event) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, events.EventsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, e := range evs {
+		if e.Timestamp == "" {
+			e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+		}
+		if err := enc.Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-90 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	writeEvents(t, dir, []events.Event{
+		// Too old for the window.
+		{Timestamp: old, Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-0", "target": "gastown/polecats/A"}},
+		{Timestamp: old, Type: events.TypeDone, Payload: map[string]interface{}{"bead": "gt-0"}},
+
+		// Routed then slung: one attempt, agent from the routed event, done.
+		{Type: events.TypeRouted, Payload: map[string]interface{}{"bead": "gt-1", "target": "gastown", "agent": "gemini"}},
+		{Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-1", "target": "gastown/polecats/Toast"}},
+		{Type: events.TypeDone, Payload: map[string]interface{}{"bead": "gt-1", "branch": "polecat/Toast-1"}},
+
+		// Slung twice: the first attempt failed.
+		{Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-2", "target": "gastown/polecats/Nux", "agent": "claude"}},
+		{Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-2", "target": "gastown/polecats/Max", "agent": "gemini"}},
+		{Type: events.TypeDone, Payload: map[string]interface{}{"bead": "gt-2", "branch": "polecat/Max-1"}},
+
+		// Done, but the merge failed.
+		{Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-3", "target": "gastown/polecats/Rex", "agent": "claude"}},
+		{Type: events.TypeDone, Payload: map[string]interface{}{"bead": "gt-3", "branch": "polecat/Rex-1"}},
+		{Type: events.TypeMergeFailed, Payload: map[string]interface{}{"branch": "polecat/Rex-1"}},
+
+		// Town-level targets are ignored.
+		{Type: events.TypeSling, Payload: map[string]interface{}{"bead": "hq-1", "target": "mayor"}},
+	})
+
+	h, err := LoadHistory(dir, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	got := h.Outcomes()
+	if o := got["gastown/gemini"]; o.Success != 2 || o.Failure != 0 {
+		t.Errorf("gastown/gemini = %+v, want 2/0", o)
+	}
+	if o := got["gastown/claude"]; o.Success != 0 || o.Failure != 2 {
+		t.Errorf("gastown/claude = %+v, want 0/2", o)
+	}
+	if _, ok := got["gastown/"]; ok {
+		t.Errorf("old events were not filtered out: %+v", got)
+	}
+
+	rate, n := h.Rate("gastown", "gemini")
+	if n != 2 || rate != 0.75 {
+		t.Errorf("Rate(gemini) = %v over %d, want 0.75 over 2", rate, n)
+	}
+	// Unknown agent falls back to the rig record (2 successes, 2 failures).
+	if rate, n := h.Rate("gastown", "codex"); n != 4 || rate != 0.5 {
+		t.Errorf("Rate(codex) = %v over %d, want 0.5 over 4", rate, n)
+	}
+	if rate, n := h.Rate("elsewhere", "claude"); n != 0 || rate != 0.5 {
+		t.Errorf("Rate(unknown rig) = %v over %d", rate, n)
+	}
+}
+
+func TestLoadHistory_MissingLog(t *testing.T) {
+	h, err := LoadHistory(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(h.Outcomes()) != 0 {
+		t.Errorf("expected empty history")
+	}
+}
@@ -0,0 +1,369 @@
+// Package routing picks where a bead should run: which rig, which agent
+// preset, which account and, when identities declare skills, which polecat.
+//
+// Beads carry their requirements as labels:
+//
+//	lang:go        a language the rig or agent must know
+//	tool:docker    a tool the rig environment or agent must have
+//	cap:frontend   any declared language, tool or tag
+//	tier:medium    the most expensive cost tier allowed
+//	context:200k   the minimum context window
+//
+// A bead whose prefix belongs to a rig only routes to that rig; town-level
+// beads can go to any rig. Candidates that cannot satisfy every requirement
+// are rejected; the rest are scored on skill overlap, cost and the
+// historical success rate of the rig/agent pair recorded in the events log.
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Requirements are what a bead needs from whoever runs it.
+type Requirements struct {
+	Capabilities []string // lang:/tool:/cap: labels, values only
+	MaxTier      string   // from tier:, empty for no limit
+	MinContext   int      // from context:, 0 for no minimum
+}
+
+// RequirementsFromLabels parses the routing labels of a bead. Unknown labels
+// are ignored; malformed tier:/context: labels are errors.
+func RequirementsFromLabels(labels []string) (Requirements, error) {
+	var req Requirements
+	for _, label := range labels {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok || value == "" {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "lang", "tool", "cap":
+			req.Capabilities = append(req.Capabilities, strings.ToLower(value))
+		case "tier":
+			if config.CostTierRank(value) == 0 {
+				return req, fmt.Errorf("label %q: tier must be low, medium or high", label)
+			}
+			req.MaxTier = strings.ToLower(value)
+		case "context":
+			n, err := config.ParseContextTokens(value)
+			if err != nil {
+				return req, fmt.Errorf("label %q: %w", label, err)
+			}
+			req.MinContext = n
+		}
+	}
+	return req, nil
+}
+
+// Agent is an agent preset or custom agent that can run polecats.
+type Agent struct {
+	Name         string
+	Capabilities *config.Capabilities
+	Default      bool // the rig's default polecat agent
+	UsesAccounts bool // runs under a Claude account config dir
+}
+
+// Account is a Claude account handle.
+type Account struct {
+	Handle       string
+	Capabilities *config.Capabilities
+	Default      bool
+}
+
+// Polecat is a named polecat identity that is free to take work.
+type Polecat struct {
+	Name         string
+	Capabilities []string
+}
+
+// Rig is a candidate rig with the agents and free identities it can use.
+type Rig struct {
+	Name         string
+	Capabilities *config.Capabilities
+	OwnsBead     bool // the bead's prefix routes to this rig
+	Agents       []Agent
+	Polecats     []Polecat
+}
+
+// Input is everything the router decides from.
+type Input struct {
+	Bead         string
+	Requirements Requirements
+	Rigs         []Rig
+	Accounts     []Account
+	History      *History // nil means no history
+}
+
+// Decision is one routable choice with its score and the reasons for it.
+type Decision struct {
+	Rig     string   `json:"rig"`
+	Agent   string   `json:"agent"`
+	Account string   `json:"account,omitempty"`
+	Polecat string   `json:"polecat,omitempty"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// Target returns the sling target for the decision: "rig" or "rig/polecat".
+func (d *Decision) Target() string {
+	if d.Polecat != "" {
+		return d.Rig + "/" + d.Polecat
+	}
+	return d.Rig
+}
+
+// Result is the router's choice plus the ranked alternatives and rejections.
+type Result struct {
+	Best         *Decision  `json:"best"`
+	Alternatives []Decision `json:"alternatives,omitempty"`
+	Rejected     []string   `json:"rejected,omitempty"`
+}
+
+// Scoring weights.
+const (
+	weightHistory = 0.5  // multiplied by the smoothed success rate
+	weightSkill   = 0.1  // per requirement the agent itself declares
+	weightPolecat = 0.05 // per requirement a named identity declares
+	weightDefault = 0.05 // the rig's default agent
+	weightCheap   = 0.05 // per tier below high
+)
+
+// Route scores every rig/agent/account combination that satisfies the
+// requirements and returns the best one. It returns an error listing the
+// rejections when nothing qualifies.
+func Route(in Input) (*Result, error) {
+	result := &Result{}
+	var decisions []Decision
+
+	// A bead whose prefix belongs to a rig can only run there: polecats work
+	// in their rig's worktree (see the cross-rig guard in gt sling).
+	owner := ""
+	for _, r := range in.Rigs {
+		if r.OwnsBead {
+			owner = r.Name
+		}
+	}
+
+	for _, r := range in.Rigs {
+		if owner != "" && r.Name != owner {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("%s: bead belongs to rig %s", r.Name, owner))
+			continue
+		}
+		if len(r.Agents) == 0 {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("%s: no agents available", r.Name))
+			continue
+		}
+		for _, a := range r.Agents {
+			accounts := []*Account{nil}
+			if a.UsesAccounts && len(in.Accounts) > 0 {
+				accounts = accounts[:0]
+				for i := range in.Accounts {
+					accounts = append(accounts, &in.Accounts[i])
+				}
+			}
+			for _, acct := range accounts {
+				d, reject := evaluate(in, r, a, acct)
+				if reject != "" {
+					result.Rejected = append(result.Rejected, reject)
+					continue
+				}
+				decisions = append(decisions, *d)
+			}
+		}
+	}
+
+	if len(decisions) == 0 {
+		if len(result.Rejected) == 0 {
+			return result, fmt.Errorf("no rigs to route %s to", in.Bead)
+		}
+		return result, fmt.Errorf("no rig/agent satisfies the requirements of %s:\n  %s",
+			in.Bead, strings.Join(result.Rejected, "\n  "))
+	}
+
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if decisions[i].Score != decisions[j].Score {
+			return decisions[i].Score > decisions[j].Score
+		}
+		if decisions[i].Rig != decisions[j].Rig {
+			return decisions[i].Rig < decisions[j].Rig
+		}
+		if decisions[i].Agent != decisions[j].Agent {
+			return decisions[i].Agent < decisions[j].Agent
+		}
+		return decisions[i].Account < decisions[j].Account
+	})
+	result.Best = &decisions[0]
+	result.Alternatives = decisions[1:]
+	return result, nil
+}
+
+// evaluate checks one combination against the requirements and scores it.
+// It returns either a decision or a rejection reason.
+func evaluate(in Input, r Rig, a Agent, acct *Account) (*Decision, string) {
+	d := &Decision{Rig: r.Name, Agent: a.Name}
+	label := r.Name + "/" + a.Name
+	if acct != nil {
+		d.Account = acct.Handle
+		label += "@" + acct.Handle
+	}
+	req := in.Requirements
+
+	// Hard requirements.
+	var acctCaps *config.Capabilities
+	if acct != nil {
+		acctCaps = acct.Capabilities
+	}
+	// What the rig, agent and account don't cover, one polecat must.
+	var missing []string
+	for _, c := range req.Capabilities {
+		if !r.Capabilities.Has(c) && !a.Capabilities.Has(c) && !acctCaps.Has(c) {
+			missing = append(missing, c)
+		}
+	}
+	polecat, matched := bestPolecat(r.Polecats, req.Capabilities, missing)
+	if len(missing) > 0 && polecat == nil {
+		for _, c := range missing {
+			if !anyPolecatHas(r.Polecats, c) {
+				return nil, fmt.Sprintf("%s: missing %s", label, c)
+			}
+		}
+		return nil, fmt.Sprintf("%s: no single polecat has %s", label, strings.Join(missing, ", "))
+	}
+	tier := agentTier(a, acct)
+	if req.MaxTier != "" {
+		if tier == "" {
+			return nil, fmt.Sprintf("%s: cost tier unknown (bead allows up to %s)", label, req.MaxTier)
+		}
+		if config.CostTierRank(tier) > config.CostTierRank(req.MaxTier) {
+			return nil, fmt.Sprintf("%s: cost tier %s exceeds %s", label, tier, req.MaxTier)
+		}
+	}
+	if req.MinContext > 0 {
+		ctx := 0
+		if a.Capabilities != nil {
+			ctx = a.Capabilities.ContextTokens
+		}
+		if ctx < req.MinContext {
+			return nil, fmt.Sprintf("%s: context %d below required %d", label, ctx, req.MinContext)
+		}
+	}
+
+	// Soft preferences.
+	if r.OwnsBead {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("bead %s belongs to rig %s", in.Bead, r.Name))
+	} else {
+		var rigSkills []string
+		for _, c := range req.Capabilities {
+			if r.Capabilities.Has(c) {
+				rigSkills = append(rigSkills, c)
+			}
+		}
+		if len(rigSkills) > 0 {
+			d.Score += weightSkill * float64(len(rigSkills))
+			d.Reasons = append(d.Reasons, fmt.Sprintf("rig %s has %s", r.Name, strings.Join(rigSkills, ", ")))
+		}
+	}
+
+	var skills []string
+	for _, c := range req.Capabilities {
+		if a.Capabilities.Has(c) {
+			skills = append(skills, c)
+		}
+	}
+	if len(skills) > 0 {
+		d.Score += weightSkill * float64(len(skills))
+		d.Reasons = append(d.Reasons, fmt.Sprintf("agent %s declares %s", a.Name, strings.Join(skills, ", ")))
+	}
+
+	if polecat != nil {
+		d.Polecat = polecat.Name
+		d.Score += weightPolecat * float64(matched)
+		d.Reasons = append(d.Reasons, fmt.Sprintf("polecat %s declares %d of the required capabilities", polecat.Name, matched))
+	}
+
+	if a.Default {
+		d.Score += weightDefault
+		d.Reasons = append(d.Reasons, fmt.Sprintf("%s is the rig's default agent", a.Name))
+	}
+
+	if rank := config.CostTierRank(tier); rank > 0 {
+		d.Score += weightCheap * float64(3-rank)
+		if rank < 3 {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("cost tier %s", tier))
+		}
+	}
+
+	if in.History != nil {
+		rate, n := in.History.Rate(r.Name, a.Name)
+		d.Score += weightHistory * rate
+		if n > 0 {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("%.0f%% success over %d past runs", rate*100, n))
+		}
+	} else {
+		d.Score += weightHistory * 0.5
+	}
+
+	if acct != nil && acct.Default {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("account %s is the default", acct.Handle))
+		d.Score += 0.01
+	}
+	return d, ""
+}
+
+// agentTier returns the effective cost tier: the account's when it declares
+// one (e.g., a metered API account), otherwise the agent's.
+func agentTier(a Agent, acct *Account) string {
+	if acct != nil && acct.Capabilities != nil && acct.Capabilities.CostTier != "" {
+		return strings.ToLower(acct.Capabilities.CostTier)
+	}
+	if a.Capabilities != nil {
+		return strings.ToLower(a.Capabilities.CostTier)
+	}
+	return ""
+}
+
+func anyPolecatHas(polecats []Polecat, c string) bool {
+	for _, p := range polecats {
+		if hasFold(p.Capabilities, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// bestPolecat returns the identity matching the most requirements among
+// those that have every capability in must, or nil if none matches any.
+func bestPolecat(polecats []Polecat, reqs, must []string) (*Polecat, int) {
+	var best *Polecat
+	bestN := 0
+outer:
+	for i := range polecats {
+		for _, c := range must {
+			if !hasFold(polecats[i].Capabilities, c) {
+				continue outer
+			}
+		}
+		n := 0
+		for _, c := range reqs {
+			if hasFold(polecats[i].Capabilities, c) {
+				n++
+			}
+		}
+		if n > bestN || (n == bestN && n > 0 && polecats[i].Name < best.Name) {
+			best, bestN = &polecats[i], n
+		}
+	}
+	return best, bestN
+}
+
+func hasFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
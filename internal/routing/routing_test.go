@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestRequirementsFromLabels(t *testing.T) {
+	req, err := RequirementsFromLabels([]string{"lang:Go", "tool:docker", "cap:frontend", "tier:medium", "context:200k", "priority:high", "bug"})
+	if err != nil {
+		t.Fatalf("RequirementsFromLabels: %v", err)
+	}
+	if got := strings.Join(req.Capabilities, ","); got != "go,docker,frontend" {
+		t.Errorf("Capabilities = %q", got)
+	}
+	if req.MaxTier != "medium" {
+		t.Errorf("MaxTier = %q, want medium", req.MaxTier)
+	}
+	if req.MinContext != 200000 {
+		t.Errorf("MinContext = %d, want 200000", req.MinContext)
+	}
+
+	if _, err := RequirementsFromLabels([]string{"tier:cheap"}); err == nil {
+		t.Error("expected error for unknown tier")
+	}
+	if _, err := RequirementsFromLabels([]string{"context:lots"}); err == nil {
+		t.Error("expected error for bad context size")
+	}
+}
+
+func TestRoute_RejectsMissingCapabilities(t *testing.T) {
+	in := Input{
+		Bead:         "hq-1",
+		Requirements: Requirements{Capabilities: []string{"rust"}},
+		Rigs: []Rig{
+			{Name: "goapp", Capabilities: &config.Capabilities{Languages: []string{"go"}}, Agents: []Agent{{Name: "claude", Default: true}}},
+			{Name: "rustapp", Capabilities: &config.Capabilities{Languages: []string{"rust"}}, Agents: []Agent{{Name: "claude", Default: true}}},
+		},
+	}
+	res, err := Route(in)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Rig != "rustapp" {
+		t.Errorf("Best.Rig = %q, want rustapp", res.Best.Rig)
+	}
+	if len(res.Rejected) != 1 || !strings.Contains(res.Rejected[0], "goapp/claude: missing rust") {
+		t.Errorf("Rejected = %v", res.Rejected)
+	}
+
+	in.Requirements.Capabilities = []string{"haskell"}
+	if _, err := Route(in); err == nil || !strings.Contains(err.Error(), "missing haskell") {
+		t.Errorf("expected rejection error, got %v", err)
+	}
+}
+
+func TestRoute_OwnerRigOnly(t *testing.T) {
+	in := Input{
+		Bead: "gt-1",
+		Rigs: []Rig{
+			{Name: "gastown", OwnsBead: true, Agents: []Agent{{Name: "claude", Default: true}}},
+			{Name: "other", Agents: []Agent{{Name: "claude", Default: true}}},
+		},
+	}
+	res, err := Route(in)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Rig != "gastown" || len(res.Alternatives) != 0 {
+		t.Errorf("Best = %+v, alternatives = %d", res.Best, len(res.Alternatives))
+	}
+}
+
+func TestRoute_TierContextAndCost(t *testing.T) {
+	opus := Agent{Name: "claude-opus", Capabilities: &config.Capabilities{CostTier: "high", ContextTokens: 200000}}
+	haiku := Agent{Name: "claude-haiku", Capabilities: &config.Capabilities{CostTier: "low", ContextTokens: 200000}}
+	tiny := Agent{Name: "tiny", Capabilities: &config.Capabilities{CostTier: "low", ContextTokens: 8000}}
+	rig := Rig{Name: "app", OwnsBead: true, Agents: []Agent{opus, haiku, tiny}}
+
+	// Cheapest qualifying agent wins when nothing else differs.
+	res, err := Route(Input{Bead: "ap-1", Requirements: Requirements{MinContext: 100000}, Rigs: []Rig{rig}})
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Agent != "claude-haiku" {
+		t.Errorf("Best.Agent = %q, want claude-haiku", res.Best.Agent)
+	}
+	for _, r := range res.Rejected {
+		if !strings.Contains(r, "tiny") {
+			t.Errorf("unexpected rejection %q", r)
+		}
+	}
+
+	// tier:low rules out opus.
+	res, err = Route(Input{Bead: "ap-1", Requirements: Requirements{MaxTier: "low"}, Rigs: []Rig{rig}})
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	for _, alt := range append([]Decision{*res.Best}, res.Alternatives...) {
+		if alt.Agent == "claude-opus" {
+			t.Errorf("tier:low routed to opus")
+		}
+	}
+}
+
+func TestRoute_HistoryAndAccounts(t *testing.T) {
+	h := NewHistory()
+	for i := 0; i < 8; i++ {
+		h.record("app", "gemini", true)
+		h.record("app", "claude", false)
+	}
+	in := Input{
+		Bead: "ap-1",
+		Rigs: []Rig{{Name: "app", OwnsBead: true, Agents: []Agent{
+			{Name: "claude", Default: true, UsesAccounts: true},
+			{Name: "gemini", Capabilities: &config.Capabilities{Tags: []string{"general"}}},
+		}}},
+		Accounts: []Account{{Handle: "work", Default: true}, {Handle: "personal"}},
+		History:  h,
+	}
+	res, err := Route(in)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Agent != "gemini" || res.Best.Account != "" {
+		t.Errorf("Best = %+v, want gemini without account", res.Best)
+	}
+	// claude is tried once per account.
+	accounts := map[string]bool{}
+	for _, alt := range res.Alternatives {
+		if alt.Agent == "claude" {
+			accounts[alt.Account] = true
+		}
+	}
+	if !accounts["work"] || !accounts["personal"] {
+		t.Errorf("claude alternatives accounts = %v", accounts)
+	}
+	if res.Alternatives[0].Account != "work" {
+		t.Errorf("default account should rank first, got %q", res.Alternatives[0].Account)
+	}
+}
+
+func TestRoute_PrefersMatchingPolecat(t *testing.T) {
+	in := Input{
+		Bead:         "ap-1",
+		Requirements: Requirements{Capabilities: []string{"terraform"}},
+		Rigs: []Rig{{
+			Name: "infra", OwnsBead: true,
+			Agents:   []Agent{{Name: "claude", Default: true}},
+			Polecats: []Polecat{{Name: "Nux", Capabilities: []string{"go"}}, {Name: "Toast", Capabilities: []string{"Terraform"}}},
+		}},
+	}
+	res, err := Route(in)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Polecat != "Toast" || res.Best.Target() != "infra/Toast" {
+		t.Errorf("Best = %+v, want infra/Toast", res.Best)
+	}
+}
+
+func TestRoute_PolecatMustCoverRemainingRequirements(t *testing.T) {
+	in := Input{
+		Bead:         "ap-2",
+		Requirements: Requirements{Capabilities: []string{"terraform", "rust"}},
+		Rigs: []Rig{{
+			Name: "infra", OwnsBead: true,
+			Agents:   []Agent{{Name: "claude", Default: true}},
+			Polecats: []Polecat{{Name: "Nux", Capabilities: []string{"rust"}}, {Name: "Toast", Capabilities: []string{"terraform"}}},
+		}},
+	}
+	if _, err := Route(in); err == nil || !strings.Contains(err.Error(), "no single polecat has terraform, rust") {
+		t.Errorf("expected rejection for split capabilities, got %v", err)
+	}
+
+	// Once the rig covers one requirement, a polecat with the other will do.
+	in.Rigs[0].Capabilities = &config.Capabilities{Languages: []string{"rust"}}
+	res, err := Route(in)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if res.Best.Polecat != "Toast" {
+		t.Errorf("Best.Polecat = %q, want Toast", res.Best.Polecat)
+	}
+}
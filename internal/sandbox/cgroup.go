@@ -0,0 +1,214 @@
+package sandbox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// CgroupRootEnv overrides the parent cgroup under which session groups are
+// created. It must point at a cgroup v2 directory delegated to the user.
+const CgroupRootEnv = "GT_CGROUP_ROOT"
+
+// cpuPeriod is the cpu.max period in microseconds.
+const cpuPeriod = 100000
+
+// Cgroup is a cgroup v2 directory holding one agent session.
+type Cgroup struct {
+	Path string
+}
+
+// Counters are the cumulative limit-hit counters of a cgroup.
+type Counters struct {
+	OOMKills  int64 `json:"oom_kills"`
+	MemoryMax int64 `json:"memory_max"`
+	PidsMax   int64 `json:"pids_max"`
+}
+
+// Violation describes limit hits observed since the last check.
+type Violation struct {
+	Kind  string // "oom_kill", "memory_max" or "pids_max"
+	Count int64  // new hits since the previous counters
+}
+
+// Since returns the violations that occurred between prev and c.
+func (c Counters) Since(prev Counters) []Violation {
+	var out []Violation
+	add := func(kind string, now, before int64) {
+		if now > before {
+			out = append(out, Violation{Kind: kind, Count: now - before})
+		}
+	}
+	add("oom_kill", c.OOMKills, prev.OOMKills)
+	add("memory_max", c.MemoryMax, prev.MemoryMax)
+	add("pids_max", c.PidsMax, prev.PidsMax)
+	return out
+}
+
+// controllersFor returns the cgroup controllers a profile needs.
+func controllersFor(p *config.SandboxProfile) []string {
+	var ctrls []string
+	if p.CPUs > 0 {
+		ctrls = append(ctrls, "cpu")
+	}
+	if p.Memory != "" {
+		ctrls = append(ctrls, "memory")
+	}
+	if p.MaxPids > 0 {
+		ctrls = append(ctrls, "pids")
+	}
+	return ctrls
+}
+
+// NewCgroup creates a cgroup for name under parent and applies the
+// profile's limits. parent is created if missing and has the required
+// controllers enabled for its children.
+func NewCgroup(parent, name string, p *config.SandboxProfile) (*Cgroup, error) {
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("creating cgroup parent: %w", err)
+	}
+	if err := enableControllers(parent, controllersFor(p)); err != nil {
+		return nil, err
+	}
+
+	cg := &Cgroup{Path: filepath.Join(parent, name)}
+	if err := os.Mkdir(cg.Path, 0755); err != nil && !os.IsExist(err) {
+		return nil, fmt.Errorf("creating cgroup: %w", err)
+	}
+
+	if p.CPUs > 0 {
+		quota := int64(p.CPUs * cpuPeriod)
+		if err := cg.write("cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			_ = cg.Remove()
+			return nil, err
+		}
+	}
+	if p.Memory != "" {
+		bytes, err := p.MemoryBytes()
+		if err != nil {
+			_ = cg.Remove()
+			return nil, err
+		}
+		if err := cg.write("memory.max", strconv.FormatInt(bytes, 10)); err != nil {
+			_ = cg.Remove()
+			return nil, err
+		}
+	}
+	if p.MaxPids > 0 {
+		if err := cg.write("pids.max", strconv.Itoa(p.MaxPids)); err != nil {
+			_ = cg.Remove()
+			return nil, err
+		}
+	}
+	return cg, nil
+}
+
+// enableControllers turns on ctrls for the children of dir. The parent of
+// dir must already delegate them; the delegated user root usually does.
+func enableControllers(dir string, ctrls []string) error {
+	if len(ctrls) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for i, c := range ctrls {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString("+" + c)
+	}
+	path := filepath.Join(dir, "cgroup.subtree_control")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil { //nolint:gosec // G306: cgroupfs control file
+		return fmt.Errorf("enabling controllers %v in %s: %w", ctrls, dir, err)
+	}
+	return nil
+}
+
+func (c *Cgroup) write(file, value string) error {
+	if err := os.WriteFile(filepath.Join(c.Path, file), []byte(value), 0644); err != nil { //nolint:gosec // G306: cgroupfs control file
+		return fmt.Errorf("setting %s: %w", file, err)
+	}
+	return nil
+}
+
+// AddPID moves a process (and its future children) into the cgroup.
+func (c *Cgroup) AddPID(pid int) error {
+	return c.write("cgroup.procs", strconv.Itoa(pid))
+}
+
+// PIDs returns the processes currently in the cgroup.
+func (c *Cgroup) PIDs() ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(c.Path, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, line := range strings.Fields(string(data)) {
+		if n, err := strconv.Atoi(line); err == nil {
+			pids = append(pids, n)
+		}
+	}
+	return pids, nil
+}
+
+// Counters reads the cgroup's limit-hit counters. Missing event files
+// (controller not enabled) read as zero.
+func (c *Cgroup) Counters() (Counters, error) {
+	var out Counters
+	mem, err := readKeyed(filepath.Join(c.Path, "memory.events"))
+	if err != nil {
+		return out, err
+	}
+	pids, err := readKeyed(filepath.Join(c.Path, "pids.events"))
+	if err != nil {
+		return out, err
+	}
+	out.OOMKills = mem["oom_kill"]
+	out.MemoryMax = mem["max"]
+	out.PidsMax = pids["max"]
+	return out, nil
+}
+
+// Exists reports whether the cgroup directory is still present.
+func (c *Cgroup) Exists() bool {
+	_, err := os.Stat(c.Path)
+	return err == nil
+}
+
+// Remove deletes the cgroup. It fails if processes are still in it.
+// A missing cgroup is not an error.
+func (c *Cgroup) Remove() error {
+	if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing cgroup: %w", err)
+	}
+	return nil
+}
+
+// readKeyed parses a cgroup flat-keyed file ("key value" per line).
+func readKeyed(path string) (map[string]int64, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is a cgroupfs file under a trusted root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]int64{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	out := make(map[string]int64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			out[fields[0]] = n
+		}
+	}
+	return out, scanner.Err()
+}
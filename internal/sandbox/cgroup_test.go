@@ -0,0 +1,121 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestNewCgroup_WritesLimits(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "gastown")
+	p := &config.SandboxProfile{CPUs: 1.5, Memory: "512M", MaxPids: 256}
+
+	cg, err := NewCgroup(parent, "gt-myrig-Toast", p)
+	if err != nil {
+		t.Fatalf("NewCgroup() error = %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(parent, "cgroup.subtree_control")); got != "+cpu +memory +pids" {
+		t.Errorf("subtree_control = %q", got)
+	}
+	if got := readFile(t, filepath.Join(cg.Path, "cpu.max")); got != "150000 100000" {
+		t.Errorf("cpu.max = %q", got)
+	}
+	if got := readFile(t, filepath.Join(cg.Path, "memory.max")); got != "536870912" {
+		t.Errorf("memory.max = %q", got)
+	}
+	if got := readFile(t, filepath.Join(cg.Path, "pids.max")); got != "256" {
+		t.Errorf("pids.max = %q", got)
+	}
+}
+
+func TestNewCgroup_OnlyRequestedControllers(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "gastown")
+	cg, err := NewCgroup(parent, "s", &config.SandboxProfile{MaxPids: 64})
+	if err != nil {
+		t.Fatalf("NewCgroup() error = %v", err)
+	}
+	if got := readFile(t, filepath.Join(parent, "cgroup.subtree_control")); got != "+pids" {
+		t.Errorf("subtree_control = %q, want +pids", got)
+	}
+	if _, err := os.Stat(filepath.Join(cg.Path, "memory.max")); !os.IsNotExist(err) {
+		t.Error("memory.max should not be written without a memory limit")
+	}
+}
+
+func TestCgroup_CountersSince(t *testing.T) {
+	cg := &Cgroup{Path: t.TempDir()}
+
+	// No event files yet: all zero.
+	zero, err := cg.Counters()
+	if err != nil || zero != (Counters{}) {
+		t.Fatalf("Counters() = %+v, %v; want zero", zero, err)
+	}
+
+	_ = os.WriteFile(filepath.Join(cg.Path, "memory.events"),
+		[]byte("low 0\nhigh 0\nmax 7\noom 2\noom_kill 2\noom_group_kill 0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(cg.Path, "pids.events"), []byte("max 3\n"), 0644)
+
+	now, err := cg.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	want := Counters{OOMKills: 2, MemoryMax: 7, PidsMax: 3}
+	if now != want {
+		t.Errorf("Counters() = %+v, want %+v", now, want)
+	}
+
+	v := now.Since(Counters{OOMKills: 2, MemoryMax: 5})
+	var kinds []string
+	for _, x := range v {
+		kinds = append(kinds, x.Kind)
+	}
+	if strings.Join(kinds, ",") != "memory_max,pids_max" {
+		t.Errorf("Since() kinds = %v", kinds)
+	}
+	if v[0].Count != 2 || v[1].Count != 3 {
+		t.Errorf("Since() counts = %+v", v)
+	}
+	if len(now.Since(now)) != 0 {
+		t.Error("Since(self) should report nothing")
+	}
+}
+
+func TestCgroup_PIDsAndRemove(t *testing.T) {
+	cg := &Cgroup{Path: filepath.Join(t.TempDir(), "s")}
+	if err := os.Mkdir(cg.Path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cg.AddPID(4242); err != nil {
+		t.Fatalf("AddPID() error = %v", err)
+	}
+	pids, err := cg.PIDs()
+	if err != nil || len(pids) != 1 || pids[0] != 4242 {
+		t.Errorf("PIDs() = %v, %v", pids, err)
+	}
+
+	// Real cgroupfs dirs contain only control files; emulate removal of an
+	// empty group.
+	_ = os.Remove(filepath.Join(cg.Path, "cgroup.procs"))
+	if err := cg.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if cg.Exists() {
+		t.Error("cgroup should be gone after Remove")
+	}
+	if err := cg.Remove(); err != nil {
+		t.Errorf("Remove() on missing cgroup error = %v", err)
+	}
+}
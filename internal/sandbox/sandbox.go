@@ -0,0 +1,307 @@
+// Package sandbox applies optional Linux resource sandboxing to agent sessions.
+//
+// A rig opts in via the "sandbox" block of settings/config.json, choosing a
+// profile per role. At session start the agent command is rewritten to:
+//
+//  1. Enter its own cgroup v2 group, created with cpu.max, memory.max and
+//     pids.max set from the profile.
+//  2. Run under bwrap (bubblewrap) when the profile asks for a read-only
+//     filesystem or network denial. Everything is bind-mounted read-only
+//     except the worktree, the agent's home dir, town/rig runtime dirs,
+//     beads, the rig's git object store and any configured extra paths.
+//     The town secrets directory is hidden behind an empty tmpfs.
+//
+// Limit hits (OOM kills, pids.max rejections) are read back from the cgroup
+// event counters by the daemon and recorded as sandbox_violation events.
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// StateFile is the name of the per-agent sandbox state file, stored in the
+// agent's .runtime directory.
+const StateFile = "sandbox.json"
+
+// ErrUnsupported indicates the host cannot apply the requested sandbox.
+var ErrUnsupported = errors.New("sandbox unsupported on this host")
+
+// Paths locates the directories a sandboxed session must be able to write.
+type Paths struct {
+	TownRoot string // town root (e.g., ~/gt)
+	RigPath  string // rig root
+	AgentDir string // agent home (e.g., <rig>/polecats/<name>)
+	WorkDir  string // git worktree the agent works in
+}
+
+// Sandbox is the resolved sandbox for one agent session.
+type Sandbox struct {
+	Profile  *config.SandboxProfile
+	Required bool
+	Role     string
+	paths    Paths
+}
+
+// ForRole loads the rig's sandbox settings and returns the sandbox for role.
+// Returns nil when the rig has no settings, sandboxing is disabled, or no
+// profile applies to the role.
+func ForRole(paths Paths, role string) *Sandbox {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(paths.RigPath))
+	if err != nil || settings.Sandbox == nil {
+		return nil
+	}
+	profile := settings.Sandbox.ProfileFor(role)
+	if profile == nil {
+		return nil
+	}
+	return &Sandbox{
+		Profile:  profile,
+		Required: settings.Sandbox.Required,
+		Role:     role,
+		paths:    paths,
+	}
+}
+
+// WritablePaths returns the existing paths that stay writable under a
+// read-only filesystem profile.
+func (s *Sandbox) WritablePaths() []string {
+	candidates := []string{
+		s.paths.WorkDir,
+		s.paths.AgentDir,
+		"/tmp", // tmux sockets and agent scratch space
+	}
+	if s.paths.TownRoot != "" {
+		candidates = append(candidates,
+			filepath.Join(s.paths.TownRoot, ".runtime"),
+			filepath.Join(s.paths.TownRoot, ".beads"),
+			filepath.Join(s.paths.TownRoot, ".events.jsonl"),
+			filepath.Join(s.paths.TownRoot, ".events.jsonl.lock"),
+		)
+	}
+	if s.paths.RigPath != "" {
+		candidates = append(candidates,
+			filepath.Join(s.paths.RigPath, ".runtime"),
+			filepath.Join(s.paths.RigPath, ".beads"),
+			filepath.Join(s.paths.RigPath, "mayor", "rig", ".beads"),
+			// Worktree commits write objects into the shared repo.
+			filepath.Join(s.paths.RigPath, ".repo.git"),
+			filepath.Join(s.paths.RigPath, "mayor", "rig", ".git"),
+		)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".claude"),
+			filepath.Join(home, ".claude.json"),
+			filepath.Join(home, ".cache"),
+		)
+	}
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		candidates = append(candidates, dir)
+	}
+	candidates = append(candidates, s.Profile.WritablePaths...)
+
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range candidates {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// MaskedPaths returns the existing paths hidden behind an empty tmpfs in
+// the sandbox. The secrets master key under the town .runtime decrypts every
+// rig's secrets; agents get theirs materialized instead.
+func (s *Sandbox) MaskedPaths() []string {
+	if s.paths.TownRoot == "" {
+		return nil
+	}
+	p := filepath.Join(s.paths.TownRoot, ".runtime", "secrets")
+	if _, err := os.Stat(p); err != nil {
+		return nil
+	}
+	return []string{p}
+}
+
+// BwrapArgs returns the bubblewrap arguments that isolate the session.
+// Returns nil when the profile needs no namespaces.
+func (s *Sandbox) BwrapArgs() []string {
+	if !s.Profile.NeedsNamespaces() {
+		return nil
+	}
+	args := []string{"--die-with-parent", "--new-session"}
+	if s.Profile.ReadOnlyFS {
+		args = append(args, "--ro-bind", "/", "/")
+		for _, p := range s.WritablePaths() {
+			args = append(args, "--bind", p, p)
+		}
+	} else {
+		args = append(args, "--bind", "/", "/")
+	}
+	// Masks go after the binds so they cover them.
+	for _, p := range s.MaskedPaths() {
+		args = append(args, "--tmpfs", p)
+	}
+	args = append(args, "--dev-bind", "/dev", "/dev")
+	if s.Profile.DenyNetwork {
+		args = append(args, "--unshare-net")
+	}
+	if s.paths.WorkDir != "" {
+		args = append(args, "--chdir", s.paths.WorkDir)
+	}
+	return args
+}
+
+// WrapCommand wraps a shell command so it runs inside the sandbox's
+// namespaces. Profiles with only cgroup limits return command unchanged.
+func (s *Sandbox) WrapCommand(command string) (string, error) {
+	args := s.BwrapArgs()
+	if args == nil {
+		return command, nil
+	}
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return "", fmt.Errorf("%w: read_only_fs/deny_network need bwrap (bubblewrap) in PATH", ErrUnsupported)
+	}
+
+	parts := []string{config.ShellQuote(bwrap)}
+	for _, a := range args {
+		parts = append(parts, config.ShellQuote(a))
+	}
+	parts = append(parts, "--", "/bin/sh", "-c", config.ShellQuote(command))
+	return "exec " + strings.Join(parts, " "), nil
+}
+
+// State records the cgroup a session was placed in, so violations can be
+// tracked and the group removed when the session ends.
+type State struct {
+	Session    string    `json:"session"`
+	Role       string    `json:"role"`
+	CgroupPath string    `json:"cgroup_path,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	Counters   Counters  `json:"counters"`
+}
+
+// Apply prepares the sandbox for session and returns command rewritten to
+// run inside it. The session's cgroup is created up front and the command
+// enters it itself (echo $$ > cgroup.procs) before exec'ing bwrap, so every
+// process the agent spawns is covered from the first instruction.
+//
+// On error the returned command still carries whatever parts of the sandbox
+// could be applied, so callers with a non-required sandbox can warn and
+// continue with it.
+func (s *Sandbox) Apply(session, command string) (string, error) {
+	var errs []error
+
+	wrapped, err := s.WrapCommand(command)
+	if err != nil {
+		errs = append(errs, err)
+		wrapped = command
+	}
+
+	if hasLimits(s.Profile) {
+		cg, err := s.createCgroup(session)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			enter := "echo $$ > " + config.ShellQuote(filepath.Join(cg.Path, "cgroup.procs"))
+			if s.Required {
+				wrapped = enter + " && " + wrapped
+			} else {
+				wrapped = enter + " 2>/dev/null; " + wrapped
+			}
+		}
+	}
+	return wrapped, errors.Join(errs...)
+}
+
+// createCgroup creates the session's cgroup and records it in the state file.
+func (s *Sandbox) createCgroup(session string) (*Cgroup, error) {
+	parent, err := CgroupParent()
+	if err != nil {
+		return nil, err
+	}
+	// A stale group from a previous run of this session is reused.
+	cg, err := NewCgroup(parent, session, s.Profile)
+	if err != nil {
+		return nil, err
+	}
+	st := &State{
+		Session:    session,
+		Role:       s.Role,
+		CgroupPath: cg.Path,
+		StartedAt:  time.Now().UTC(),
+	}
+	if prev, _ := cg.Counters(); prev != (Counters{}) {
+		// Don't re-report hits from a previous run.
+		st.Counters = prev
+	}
+	if err := SaveState(s.paths.AgentDir, st); err != nil {
+		return nil, fmt.Errorf("saving sandbox state: %w", err)
+	}
+	return cg, nil
+}
+
+// hasLimits reports whether the profile sets any cgroup limit.
+func hasLimits(p *config.SandboxProfile) bool {
+	return p.CPUs > 0 || p.Memory != "" || p.MaxPids > 0
+}
+
+// StatePath returns the sandbox state path for an agent home dir.
+func StatePath(agentDir string) string {
+	return filepath.Join(agentDir, ".runtime", StateFile)
+}
+
+// LoadState reads an agent's sandbox state. Returns nil, nil if none exists.
+func LoadState(agentDir string) (*State, error) {
+	data, err := os.ReadFile(StatePath(agentDir)) //nolint:gosec // G304: path is constructed from trusted agentDir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing sandbox state: %w", err)
+	}
+	return &st, nil
+}
+
+// SaveState writes an agent's sandbox state.
+func SaveState(agentDir string, st *State) error {
+	return util.EnsureDirAndWriteJSON(StatePath(agentDir), st)
+}
+
+// Release removes the agent's cgroup (if empty) and its state file.
+// Safe to call when no sandbox was applied.
+func Release(agentDir string) error {
+	st, err := LoadState(agentDir)
+	if err != nil || st == nil {
+		return err
+	}
+	if st.CgroupPath != "" {
+		if err := (&Cgroup{Path: st.CgroupPath}).Remove(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(StatePath(agentDir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// setupRig writes rig settings with the given sandbox config and returns paths.
+func setupRig(t *testing.T, sb *config.SandboxConfig) Paths {
+	t.Helper()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "myrig")
+	agentDir := filepath.Join(rigPath, "polecats", "Toast")
+	workDir := filepath.Join(agentDir, "myrig")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewRigSettings()
+	settings.Sandbox = sb
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings() error = %v", err)
+	}
+	return Paths{TownRoot: townRoot, RigPath: rigPath, AgentDir: agentDir, WorkDir: workDir}
+}
+
+func TestForRole(t *testing.T) {
+	paths := setupRig(t, &config.SandboxConfig{
+		Enabled:  true,
+		Required: true,
+		Roles:    map[string]*config.SandboxProfile{"polecat": {MaxPids: 128}},
+	})
+
+	sb := ForRole(paths, "polecat")
+	if sb == nil || sb.Profile.MaxPids != 128 || !sb.Required {
+		t.Fatalf("ForRole(polecat) = %+v", sb)
+	}
+	if ForRole(paths, "crew") != nil {
+		t.Error("ForRole(crew) should be nil without a default profile")
+	}
+	if ForRole(Paths{RigPath: t.TempDir()}, "polecat") != nil {
+		t.Error("ForRole() without rig settings should be nil")
+	}
+}
+
+func TestBwrapArgs(t *testing.T) {
+	paths := setupRig(t, nil)
+	extra := t.TempDir()
+	sb := &Sandbox{
+		Profile: &config.SandboxProfile{
+			ReadOnlyFS:    true,
+			DenyNetwork:   true,
+			WritablePaths: []string{extra, "/does/not/exist"},
+		},
+		paths: paths,
+	}
+
+	args := strings.Join(sb.BwrapArgs(), " ")
+	for _, want := range []string{
+		"--ro-bind / /",
+		"--bind " + paths.WorkDir + " " + paths.WorkDir,
+		"--bind " + paths.AgentDir + " " + paths.AgentDir,
+		"--bind " + extra + " " + extra,
+		"--unshare-net",
+		"--chdir " + paths.WorkDir,
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("BwrapArgs() missing %q in %q", want, args)
+		}
+	}
+	if strings.Contains(args, "/does/not/exist") {
+		t.Error("BwrapArgs() should skip nonexistent writable paths")
+	}
+
+	limitsOnly := &Sandbox{Profile: &config.SandboxProfile{MaxPids: 10}, paths: paths}
+	if limitsOnly.BwrapArgs() != nil {
+		t.Error("limits-only profile should not need bwrap")
+	}
+	if got, err := limitsOnly.WrapCommand("claude"); err != nil || got != "claude" {
+		t.Errorf("WrapCommand() = %q, %v; want unchanged", got, err)
+	}
+}
+
+func TestBwrapArgs_MasksSecretsKey(t *testing.T) {
+	paths := setupRig(t, nil)
+	runtimeDir := filepath.Join(paths.TownRoot, ".runtime")
+	secretsDir := filepath.Join(runtimeDir, "secrets")
+	if err := os.MkdirAll(secretsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range []*config.SandboxProfile{
+		{ReadOnlyFS: true},
+		{DenyNetwork: true},
+	} {
+		sb := &Sandbox{Profile: profile, paths: paths}
+		args := sb.BwrapArgs()
+		mask, bind := -1, -1
+		for i := 0; i+1 < len(args); i++ {
+			switch {
+			case args[i] == "--tmpfs" && args[i+1] == secretsDir:
+				mask = i
+			case args[i] == "--bind" && (args[i+1] == runtimeDir || args[i+1] == "/"):
+				bind = i
+			}
+		}
+		if mask < 0 {
+			t.Errorf("%+v: BwrapArgs() does not mask %s: %q", profile, secretsDir, args)
+		} else if mask < bind {
+			t.Errorf("%+v: secrets mask precedes the bind it should cover: %q", profile, args)
+		}
+	}
+}
+
+func TestApply_EntersCgroupAndSavesState(t *testing.T) {
+	paths := setupRig(t, nil)
+	root := t.TempDir()
+	t.Setenv(CgroupRootEnv, root)
+
+	sb := &Sandbox{
+		Profile:  &config.SandboxProfile{Memory: "1G"},
+		Required: true,
+		Role:     "polecat",
+		paths:    paths,
+	}
+	cmd, err := sb.Apply("gt-myrig-Toast", "exec claude")
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	procs := filepath.Join(root, "gt-myrig-Toast", "cgroup.procs")
+	if want := "echo $$ > " + procs + " && exec claude"; cmd != want {
+		t.Errorf("Apply() = %q, want %q", cmd, want)
+	}
+
+	st, err := LoadState(paths.AgentDir)
+	if err != nil || st == nil {
+		t.Fatalf("LoadState() = %+v, %v", st, err)
+	}
+	if st.Session != "gt-myrig-Toast" || st.Role != "polecat" || st.CgroupPath != filepath.Dir(procs) {
+		t.Errorf("state = %+v", st)
+	}
+
+	// Not required: a failing cgroup write must not abort the command.
+	sb.Required = false
+	cmd, _ = sb.Apply("gt-myrig-Toast", "exec claude")
+	if !strings.Contains(cmd, "2>/dev/null; exec claude") {
+		t.Errorf("Apply() non-required = %q", cmd)
+	}
+}
+
+func TestScan_ReportsNewHitsAndReleasesEmptyGroups(t *testing.T) {
+	paths := setupRig(t, nil)
+	root := t.TempDir()
+	t.Setenv(CgroupRootEnv, root)
+
+	sb := &Sandbox{Profile: &config.SandboxProfile{MaxPids: 64}, Role: "polecat", paths: paths}
+	if _, err := sb.Apply("gt-myrig-Toast", "claude"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	cgPath := filepath.Join(root, "gt-myrig-Toast")
+	_ = os.WriteFile(filepath.Join(cgPath, "cgroup.procs"), []byte("1234\n"), 0644)
+	_ = os.WriteFile(filepath.Join(cgPath, "pids.events"), []byte("max 4\n"), 0644)
+
+	reports := Scan(paths.TownRoot)
+	if len(reports) != 1 || reports[0].Session != "gt-myrig-Toast" {
+		t.Fatalf("Scan() = %+v, want one report", reports)
+	}
+	if v := reports[0].Violations; len(v) != 1 || v[0].Kind != "pids_max" || v[0].Count != 4 {
+		t.Errorf("violations = %+v", v)
+	}
+
+	// Counters are persisted, so a second scan reports nothing new.
+	if again := Scan(paths.TownRoot); len(again) != 0 {
+		t.Errorf("second Scan() = %+v, want none", again)
+	}
+
+	// Once the cgroup is gone the state file is cleaned up.
+	_ = os.RemoveAll(cgPath)
+	Scan(paths.TownRoot)
+	if st, _ := LoadState(paths.AgentDir); st != nil {
+		t.Errorf("state should be released, got %+v", st)
+	}
+}
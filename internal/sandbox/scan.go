@@ -0,0 +1,68 @@
+package sandbox
+
+import (
+	"path/filepath"
+)
+
+// Report lists the new limit hits for one sandboxed session.
+type Report struct {
+	Session    string
+	Role       string
+	AgentDir   string
+	Violations []Violation
+}
+
+// Scan checks every sandboxed agent in the town for new limit hits since
+// the previous scan, updating the stored counters. Cgroups whose processes
+// have all exited are removed along with their state file.
+func Scan(townRoot string) []Report {
+	var reports []Report
+	for _, agentDir := range stateDirs(townRoot) {
+		st, err := LoadState(agentDir)
+		if err != nil || st == nil || st.CgroupPath == "" {
+			continue
+		}
+		cg := &Cgroup{Path: st.CgroupPath}
+		if !cg.Exists() {
+			_ = Release(agentDir)
+			continue
+		}
+
+		counters, err := cg.Counters()
+		if err != nil {
+			continue
+		}
+		if v := counters.Since(st.Counters); len(v) > 0 {
+			reports = append(reports, Report{
+				Session:    st.Session,
+				Role:       st.Role,
+				AgentDir:   agentDir,
+				Violations: v,
+			})
+			st.Counters = counters
+			_ = SaveState(agentDir, st)
+		}
+
+		if pids, err := cg.PIDs(); err == nil && len(pids) == 0 {
+			_ = Release(agentDir)
+		}
+	}
+	return reports
+}
+
+// stateDirs returns agent dirs in the town that have sandbox state.
+func stateDirs(townRoot string) []string {
+	var dirs []string
+	// Agent dirs sit one or two levels below a rig: <rig>/witness,
+	// <rig>/polecats/<name>, <rig>/crew/<name>, <rig>/refinery/rig.
+	for _, pattern := range []string{
+		filepath.Join(townRoot, "*", "*", ".runtime", StateFile),
+		filepath.Join(townRoot, "*", "*", "*", ".runtime", StateFile),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			dirs = append(dirs, filepath.Dir(filepath.Dir(m)))
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,39 @@
+package sandbox
+
+import "strings"
+
+// Support describes what sandboxing features the host provides.
+type Support struct {
+	CgroupV2     bool     // unified cgroup hierarchy mounted
+	Controllers  []string // controllers available to gastown's cgroup parent
+	CgroupParent string   // where session cgroups are created
+	Delegated    bool     // CgroupParent is writable by this user
+	Bwrap        string   // path to bwrap, empty if missing
+	UserNS       bool     // unprivileged user namespaces allowed
+	Problems     []string // human-readable reasons for missing features
+}
+
+// HasController reports whether a cgroup controller is available.
+func (s Support) HasController(name string) bool {
+	for _, c := range s.Controllers {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CanLimit reports whether cgroup resource limits can be applied.
+func (s Support) CanLimit() bool {
+	return s.CgroupV2 && s.Delegated
+}
+
+// CanIsolate reports whether filesystem and network isolation can be applied.
+func (s Support) CanIsolate() bool {
+	return s.Bwrap != "" && s.UserNS
+}
+
+// Summary returns a one-line description of the problems found.
+func (s Support) Summary() string {
+	return strings.Join(s.Problems, "; ")
+}
@@ -0,0 +1,131 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const cgroupFS = "/sys/fs/cgroup"
+
+// CgroupParent returns the directory under which session cgroups are
+// created: $GT_CGROUP_ROOT if set, otherwise "gastown" inside the systemd
+// user manager's delegated subtree (or the cgroup root when running as root).
+func CgroupParent() (string, error) {
+	if root := os.Getenv(CgroupRootEnv); root != "" {
+		return root, nil
+	}
+	if _, err := os.Stat(filepath.Join(cgroupFS, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("%w: cgroup v2 not mounted at %s", ErrUnsupported, cgroupFS)
+	}
+	if os.Geteuid() == 0 {
+		return filepath.Join(cgroupFS, "gastown"), nil
+	}
+
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", fmt.Errorf("reading /proc/self/cgroup: %w", err)
+	}
+	// Unified hierarchy line: "0::/user.slice/user-1000.slice/user@1000.service/..."
+	service := fmt.Sprintf("user@%d.service", os.Getuid())
+	for _, line := range strings.Split(string(data), "\n") {
+		path, ok := strings.CutPrefix(line, "0::")
+		if !ok {
+			continue
+		}
+		if idx := strings.Index(path, service); idx >= 0 {
+			return filepath.Join(cgroupFS, path[:idx+len(service)], "gastown"), nil
+		}
+	}
+	return "", fmt.Errorf("%w: no delegated cgroup (not running under a systemd user session); set %s", ErrUnsupported, CgroupRootEnv)
+}
+
+// Probe inspects the host for sandboxing support.
+func Probe() Support {
+	var s Support
+
+	if _, err := os.Stat(filepath.Join(cgroupFS, "cgroup.controllers")); err == nil {
+		s.CgroupV2 = true
+	} else if os.Getenv(CgroupRootEnv) == "" {
+		s.Problems = append(s.Problems, "cgroup v2 not mounted at "+cgroupFS)
+	}
+
+	if parent, err := CgroupParent(); err != nil {
+		if s.CgroupV2 {
+			s.Problems = append(s.Problems, err.Error())
+		}
+	} else {
+		s.CgroupParent = parent
+		if os.Getenv(CgroupRootEnv) != "" {
+			s.CgroupV2 = true
+		}
+		// The parent may not exist yet; its controllers come from its parent.
+		dir := parent
+		if _, err := os.Stat(dir); err != nil {
+			dir = filepath.Dir(parent)
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers")); err == nil {
+			s.Controllers = strings.Fields(string(data))
+		}
+		if dirWritable(dir) {
+			s.Delegated = true
+		} else {
+			s.Problems = append(s.Problems, "cgroup "+dir+" is not writable (controllers not delegated)")
+		}
+		for _, c := range []string{"cpu", "memory", "pids"} {
+			if !s.HasController(c) {
+				s.Problems = append(s.Problems, "cgroup controller "+c+" not available")
+			}
+		}
+	}
+
+	if path, err := exec.LookPath("bwrap"); err == nil {
+		s.Bwrap = path
+	} else {
+		s.Problems = append(s.Problems, "bwrap (bubblewrap) not found in PATH")
+	}
+
+	s.UserNS = userNamespacesEnabled()
+	if !s.UserNS {
+		s.Problems = append(s.Problems, "unprivileged user namespaces disabled")
+	}
+	return s
+}
+
+// userNamespacesEnabled checks the sysctls that gate unprivileged userns.
+func userNamespacesEnabled() bool {
+	if data, err := os.ReadFile("/proc/sys/user/max_user_namespaces"); err == nil {
+		if strings.TrimSpace(string(data)) == "0" {
+			return false
+		}
+	}
+	// Debian/Ubuntu-specific knob.
+	if data, err := os.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil {
+		if strings.TrimSpace(string(data)) == "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// dirWritable reports whether the current user can create entries in dir.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".gt-probe-*")
+	if err == nil {
+		name := f.Name()
+		_ = f.Close()
+		_ = os.Remove(name)
+		return true
+	}
+	// cgroupfs rejects regular files; fall back to mkdir/rmdir.
+	probe := filepath.Join(dir, fmt.Sprintf("gt-probe-%d", os.Getpid()))
+	if err := os.Mkdir(probe, 0755); err != nil {
+		return false
+	}
+	_ = os.Remove(probe)
+	return true
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package sandbox
+
+import "fmt"
+
+// CgroupParent is unsupported outside Linux.
+func CgroupParent() (string, error) {
+	return "", fmt.Errorf("%w: cgroups require Linux", ErrUnsupported)
+}
+
+// Probe reports that sandboxing is unavailable outside Linux.
+func Probe() Support {
+	return Support{Problems: []string{"sandboxing requires Linux (cgroup v2 and bubblewrap)"}}
+}
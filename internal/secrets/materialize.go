@@ -0,0 +1,272 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ManifestFile records which files were materialized into a worktree so
+// Scrub can remove exactly those files. It lives under the worktree's
+// gitignored .runtime/ directory.
+const ManifestFile = "secrets-manifest.json"
+
+// EnvFileName is the name of the per-agent env file sourced at session start.
+const EnvFileName = "secrets.env"
+
+// manifest lists materialized files relative to the worktree root.
+type manifest struct {
+	Files []string `json:"files"`
+}
+
+// Resolve returns the secrets visible to role in a rig.
+// Town secrets are loaded first, then rig secrets override them by name.
+// A rig store that can't be opened (e.g., sealed by another town's key) is
+// skipped with a warning so town secrets still resolve.
+// Returns nil, nil when no key is configured (no secrets in use).
+func Resolve(townRoot, rigPath, role string) ([]*Secret, error) {
+	key, err := LoadKey(townRoot, false)
+	if err != nil {
+		if errors.Is(err, ErrNoKey) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	store, err := Open(TownStorePath(townRoot), key)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]*Secret)
+	for _, sec := range store.List() {
+		merged[sec.Name] = sec
+	}
+	if rigPath != "" {
+		store, err := Open(RigStorePath(rigPath), key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping rig secrets in %s: %v\n", RigStorePath(rigPath), err)
+		} else {
+			for _, sec := range store.List() {
+				merged[sec.Name] = sec
+			}
+		}
+	}
+
+	var out []*Secret
+	for _, sec := range merged {
+		if sec.AppliesTo(role) {
+			out = append(out, sec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Materialize writes file-mode secrets for role into worktree.
+// Files are written with mode 0600, recorded in the worktree manifest so
+// they can be scrubbed later, and added to the repository's info/exclude so
+// git add -A can't commit them. Returns the number of files written.
+func Materialize(townRoot, rigPath, role, worktree string) (int, error) {
+	secs, err := Resolve(townRoot, rigPath, role)
+	if err != nil {
+		return 0, err
+	}
+
+	m := readManifest(worktree)
+	written := 0
+	for _, sec := range secs {
+		if !sec.IsFile() {
+			continue
+		}
+		rel, err := cleanRelPath(sec.File)
+		if err != nil {
+			return written, err
+		}
+		dst := filepath.Join(worktree, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return written, fmt.Errorf("creating dir for %s: %w", rel, err)
+		}
+		if err := excludeFromGit(worktree, rel); err != nil {
+			return written, fmt.Errorf("excluding secret file %s from git: %w", rel, err)
+		}
+		if err := util.AtomicWriteFile(dst, []byte(sec.Value), 0600); err != nil {
+			return written, fmt.Errorf("writing secret file %s: %w", rel, err)
+		}
+		m.add(rel)
+		written++
+	}
+
+	if written == 0 {
+		return 0, nil
+	}
+	if err := writeManifest(worktree, m); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// Scrub removes every file recorded in the worktree manifest.
+// File contents are overwritten before removal so a partially failed nuke
+// does not leave credentials behind. Missing files are ignored.
+func Scrub(worktree string) error {
+	m := readManifest(worktree)
+	var errs []string
+	for _, rel := range m.Files {
+		if err := shred(filepath.Join(worktree, rel)); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", rel, err))
+		}
+	}
+	if err := os.Remove(manifestPath(worktree)); err != nil && !os.IsNotExist(err) {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("scrubbing secrets: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// MaterializedFiles returns the worktree-relative paths of the secret files
+// recorded in the worktree manifest.
+func MaterializedFiles(worktree string) []string {
+	return readManifest(worktree).Files
+}
+
+// WriteEnvFile writes env-mode secrets to path as shell assignments
+// (mode 0600). Returns false without writing when there are none.
+// Values never appear on the agent's command line; the session sources
+// the file instead (see SourceEnvFile).
+func WriteEnvFile(path string, secs []*Secret) (bool, error) {
+	var b strings.Builder
+	for _, sec := range secs {
+		if sec.IsFile() {
+			continue
+		}
+		fmt.Fprintf(&b, "%s=%s\n", sec.Name, shellQuote(sec.Value))
+	}
+	if b.Len() == 0 {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, fmt.Errorf("creating env file dir: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(b.String()), 0600); err != nil {
+		return false, fmt.Errorf("writing env file: %w", err)
+	}
+	return true, nil
+}
+
+// SourceEnvFile prefixes command so it runs with the variables in envFile
+// exported. The file path, not the values, appears in the command.
+func SourceEnvFile(command, envFile string) string {
+	return "set -a && . " + shellQuote(envFile) + " && set +a && " + command
+}
+
+// EnvFilePath returns the env file location for an agent home directory
+// (e.g., polecats/<name>/), kept outside the git worktree.
+func EnvFilePath(agentDir string) string {
+	return filepath.Join(agentDir, ".runtime", EnvFileName)
+}
+
+// ScrubEnvFile removes an env file written by WriteEnvFile.
+func ScrubEnvFile(path string) error {
+	return shred(path)
+}
+
+// shred overwrites a regular file with zeros and removes it.
+func shred(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Mode().IsRegular() {
+		if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+			_, _ = f.Write(make([]byte, info.Size()))
+			_ = f.Close()
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// excludeFromGit adds rel to the info/exclude file of the repository
+// holding worktree, so the secret file never shows up as untracked. Linked
+// worktrees share the main repository's exclude file. A worktree outside
+// any git repository needs nothing.
+func excludeFromGit(worktree, rel string) error {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "info/exclude")
+	cmd.Dir = worktree
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktree, path)
+	}
+
+	pattern := "/" + filepath.ToSlash(rel)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from git for a trusted worktree
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, []byte(pattern+"\n")...)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644) //nolint:gosec // G306: exclude file is not secret
+}
+
+func manifestPath(worktree string) string {
+	return filepath.Join(worktree, ".runtime", ManifestFile)
+}
+
+func readManifest(worktree string) *manifest {
+	m := &manifest{}
+	data, err := os.ReadFile(manifestPath(worktree)) //nolint:gosec // G304: path is constructed from trusted worktree
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(data, m)
+	return m
+}
+
+func writeManifest(worktree string, m *manifest) error {
+	if err := util.EnsureDirAndWriteJSONWithPerm(manifestPath(worktree), m, 0600); err != nil {
+		return fmt.Errorf("writing secrets manifest: %w", err)
+	}
+	return nil
+}
+
+func (m *manifest) add(rel string) {
+	for _, f := range m.Files {
+		if f == rel {
+			return
+		}
+	}
+	m.Files = append(m.Files, rel)
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
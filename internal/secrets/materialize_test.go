@@ -0,0 +1,212 @@
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupStores creates a town with town- and rig-level secrets.
+func setupStores(t *testing.T) (townRoot, rigPath string) {
+	t.Helper()
+	t.Setenv(KeyEnv, "")
+	townRoot = t.TempDir()
+	rigPath = filepath.Join(townRoot, "myrig")
+
+	town, err := OpenTown(townRoot, true)
+	if err != nil {
+		t.Fatalf("OpenTown() error = %v", err)
+	}
+	_ = town.Set(Secret{Name: "SHARED_TOKEN", Value: "town-level-token"})
+	_ = town.Set(Secret{Name: "CREW_ONLY", Value: "crew-only-value", Roles: []string{"crew"}})
+	if err := town.Save(); err != nil {
+		t.Fatalf("town Save() error = %v", err)
+	}
+
+	rigStore, err := OpenRig(townRoot, rigPath, false)
+	if err != nil {
+		t.Fatalf("OpenRig() error = %v", err)
+	}
+	_ = rigStore.Set(Secret{Name: "SHARED_TOKEN", Value: "rig-level-token"})
+	_ = rigStore.Set(Secret{Name: "dotenv", Value: "DB_URL=postgres://u:pw@db\n", File: ".env"})
+	if err := rigStore.Save(); err != nil {
+		t.Fatalf("rig Save() error = %v", err)
+	}
+	return townRoot, rigPath
+}
+
+func TestResolve_RigOverridesTownAndScopesRoles(t *testing.T) {
+	townRoot, rigPath := setupStores(t)
+
+	secs, err := Resolve(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	byName := make(map[string]*Secret)
+	for _, s := range secs {
+		byName[s.Name] = s
+	}
+	if byName["SHARED_TOKEN"] == nil || byName["SHARED_TOKEN"].Value != "rig-level-token" {
+		t.Errorf("SHARED_TOKEN = %+v, want rig-level override", byName["SHARED_TOKEN"])
+	}
+	if _, ok := byName["CREW_ONLY"]; ok {
+		t.Error("CREW_ONLY should not be visible to polecat")
+	}
+	if _, ok := byName["dotenv"]; !ok {
+		t.Error("dotenv should be visible to polecat")
+	}
+}
+
+func TestResolve_SkipsForeignRigStore(t *testing.T) {
+	townRoot, rigPath := setupStores(t)
+	_, otherRig := setupStores(t) // Sealed with a different town's key
+
+	data, err := os.ReadFile(RigStorePath(otherRig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(RigStorePath(rigPath), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secs, err := Resolve(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v, want town secrets despite unreadable rig store", err)
+	}
+	if len(secs) != 1 || secs[0].Name != "SHARED_TOKEN" || secs[0].Value != "town-level-token" {
+		t.Errorf("Resolve() = %+v, want only the town SHARED_TOKEN", secs)
+	}
+}
+
+func TestResolve_NoKeyMeansNoSecrets(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	secs, err := Resolve(t.TempDir(), "", "polecat")
+	if err != nil || secs != nil {
+		t.Errorf("Resolve() = %v, %v; want nil, nil", secs, err)
+	}
+}
+
+func TestMaterializeAndScrub(t *testing.T) {
+	townRoot, rigPath := setupStores(t)
+	worktree := t.TempDir()
+
+	n, err := Materialize(townRoot, rigPath, "polecat", worktree)
+	if err != nil {
+		t.Fatalf("Materialize() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Materialize() wrote %d files, want 1", n)
+	}
+
+	envPath := filepath.Join(worktree, ".env")
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf(".env not materialized: %v", err)
+	}
+	if !strings.Contains(string(data), "postgres://u:pw@db") {
+		t.Errorf(".env content = %q", data)
+	}
+	if info, _ := os.Stat(envPath); info.Mode().Perm() != 0600 {
+		t.Errorf(".env perm = %o, want 0600", info.Mode().Perm())
+	}
+
+	if err := Scrub(worktree); err != nil {
+		t.Fatalf("Scrub() error = %v", err)
+	}
+	if _, err := os.Stat(envPath); !os.IsNotExist(err) {
+		t.Error(".env should be removed after Scrub")
+	}
+	if _, err := os.Stat(manifestPath(worktree)); !os.IsNotExist(err) {
+		t.Error("manifest should be removed after Scrub")
+	}
+}
+
+func TestMaterialize_ExcludedFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	townRoot, rigPath := setupStores(t)
+	worktree := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktree
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	// Rigs ignore .runtime/ (where the manifest lives) themselves.
+	excludePath := filepath.Join(worktree, ".git", "info", "exclude")
+	if err := os.WriteFile(excludePath, []byte(".runtime/"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Twice, to check the exclude entry isn't duplicated.
+	for i := 0; i < 2; i++ {
+		if _, err := Materialize(townRoot, rigPath, "polecat", worktree); err != nil {
+			t.Fatalf("Materialize() error = %v", err)
+		}
+	}
+
+	status := exec.Command("git", "status", "--porcelain", "--untracked-files=all")
+	status.Dir = worktree
+	out, err := status.Output()
+	if err != nil {
+		t.Fatalf("git status: %v", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("git status not clean after Materialize:\n%s", out)
+	}
+
+	exclude, err := os.ReadFile(excludePath)
+	if err != nil {
+		t.Fatalf("reading exclude: %v", err)
+	}
+	if n := strings.Count(string(exclude), "/.env\n"); n != 1 {
+		t.Errorf("exclude has %d /.env entries, want 1:\n%s", n, exclude)
+	}
+}
+
+func TestScrub_NoManifest(t *testing.T) {
+	if err := Scrub(t.TempDir()); err != nil {
+		t.Errorf("Scrub() with no manifest error = %v", err)
+	}
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".runtime", EnvFileName)
+	secs := []*Secret{
+		{Name: "TOKEN", Value: "it's-secret"},
+		{Name: "dotenv", Value: "ignored", File: ".env"},
+	}
+
+	wrote, err := WriteEnvFile(path, secs)
+	if err != nil || !wrote {
+		t.Fatalf("WriteEnvFile() = %v, %v", wrote, err)
+	}
+	data, _ := os.ReadFile(path)
+	if got, want := string(data), "TOKEN='it'\\''s-secret'\n"; got != want {
+		t.Errorf("env file = %q, want %q", got, want)
+	}
+
+	cmd := SourceEnvFile("claude", path)
+	if strings.Contains(cmd, "it's-secret") {
+		t.Error("SourceEnvFile leaked value into command")
+	}
+	if !strings.HasSuffix(cmd, "&& claude") {
+		t.Errorf("SourceEnvFile() = %q", cmd)
+	}
+
+	wrote, err = WriteEnvFile(filepath.Join(t.TempDir(), "none.env"), secs[1:])
+	if err != nil || wrote {
+		t.Errorf("WriteEnvFile(file-only) = %v, %v; want false, nil", wrote, err)
+	}
+}
@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinRedactLength is the shortest value that is redacted. Shorter values
+// ("1", "on", "dev") would shred unrelated output.
+const MinRedactLength = 6
+
+// redactorCacheTTL bounds how long a loaded redactor is reused. Event and
+// mail writers call LoadRedactor on every write; the TTL keeps that from
+// decrypting every store each time.
+const redactorCacheTTL = 30 * time.Second
+
+// Redactor replaces known secret values with a placeholder.
+// A nil *Redactor is valid and redacts nothing.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+type redactEntry struct {
+	value string
+	label string
+}
+
+// NewRedactor builds a redactor for the given secrets.
+// Multi-line values (e.g., a whole .env file) also register each
+// KEY=VALUE line's value so fragments echoed by tools are caught.
+func NewRedactor(secs []*Secret) *Redactor {
+	seen := make(map[string]bool)
+	var entries []redactEntry
+	add := func(value, name string) {
+		value = strings.TrimSpace(value)
+		if len(value) < MinRedactLength || seen[value] {
+			return
+		}
+		seen[value] = true
+		entries = append(entries, redactEntry{value: value, label: "[REDACTED:" + name + "]"})
+	}
+
+	for _, sec := range secs {
+		add(sec.Value, sec.Name)
+		if !strings.Contains(sec.Value, "\n") {
+			continue
+		}
+		for _, line := range strings.Split(sec.Value, "\n") {
+			if _, v, ok := strings.Cut(line, "="); ok {
+				add(strings.Trim(strings.TrimSpace(v), `"'`), sec.Name)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Longest first so a value containing another is replaced whole.
+	sort.Slice(entries, func(i, j int) bool { return len(entries[i].value) > len(entries[j].value) })
+	pairs := make([]string, 0, len(entries)*2)
+	for _, e := range entries {
+		pairs = append(pairs, e.value, e.label)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redact returns s with all known secret values replaced.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// RedactPayload returns a copy of an event payload with string values
+// (including those nested in maps and slices) redacted.
+func (r *Redactor) RedactPayload(p map[string]interface{}) map[string]interface{} {
+	if r == nil || p == nil {
+		return p
+	}
+	out := make(map[string]interface{}, len(p))
+	for k, v := range p {
+		out[k] = r.redactValue(v)
+	}
+	return out
+}
+
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return r.Redact(val)
+	case []string:
+		out := make([]string, len(val))
+		for i, s := range val {
+			out[i] = r.Redact(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			out[i] = r.redactValue(e)
+		}
+		return out
+	case map[string]interface{}:
+		return r.RedactPayload(val)
+	default:
+		return v
+	}
+}
+
+var redactorCache struct {
+	sync.Mutex
+	townRoot string
+	loaded   time.Time
+	redactor *Redactor
+}
+
+// LoadRedactor returns a redactor covering every secret in the town:
+// the town store plus each rig store. It is best-effort: when no key is
+// configured or a store cannot be read, it returns whatever it could load
+// (possibly nil). Results are cached briefly per town.
+func LoadRedactor(townRoot string) *Redactor {
+	if townRoot == "" {
+		return nil
+	}
+
+	redactorCache.Lock()
+	defer redactorCache.Unlock()
+	if redactorCache.townRoot == townRoot && time.Since(redactorCache.loaded) < redactorCacheTTL {
+		return redactorCache.redactor
+	}
+
+	r := loadRedactor(townRoot)
+	redactorCache.townRoot = townRoot
+	redactorCache.loaded = time.Now()
+	redactorCache.redactor = r
+	return r
+}
+
+// ResetRedactorCache drops the cached redactor so the next LoadRedactor
+// call re-reads the stores. Called after secrets are changed.
+func ResetRedactorCache() {
+	redactorCache.Lock()
+	defer redactorCache.Unlock()
+	redactorCache.townRoot = ""
+	redactorCache.redactor = nil
+}
+
+func loadRedactor(townRoot string) *Redactor {
+	// Fast path: no key file and no env key means secrets are not in use.
+	if os.Getenv(KeyEnv) == "" {
+		if _, err := os.Stat(KeyPath(townRoot)); err != nil {
+			return nil
+		}
+	}
+	key, err := LoadKey(townRoot, false)
+	if err != nil {
+		return nil
+	}
+
+	paths := []string{TownStorePath(townRoot)}
+	if rigStores, err := filepath.Glob(filepath.Join(townRoot, "*", ".runtime", "secrets.enc")); err == nil {
+		paths = append(paths, rigStores...)
+	}
+
+	var all []*Secret
+	for _, path := range paths {
+		store, err := Open(path, key)
+		if err != nil {
+			continue
+		}
+		all = append(all, store.List()...)
+	}
+	return NewRedactor(all)
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := NewRedactor([]*Secret{
+		{Name: "TOKEN", Value: "ghp_abcdef123456"},
+		{Name: "SHORT", Value: "abc"},
+		{Name: "dotenv", Value: "DB_PASSWORD=\"hunter2hunter2\"\nEMPTY=\n", File: ".env"},
+	})
+
+	got := r.Redact("push with ghp_abcdef123456 and pw hunter2hunter2 abc")
+	if strings.Contains(got, "ghp_abcdef123456") || strings.Contains(got, "hunter2hunter2") {
+		t.Errorf("Redact() leaked secret: %q", got)
+	}
+	if !strings.Contains(got, "[REDACTED:TOKEN]") || !strings.Contains(got, "[REDACTED:dotenv]") {
+		t.Errorf("Redact() missing placeholders: %q", got)
+	}
+	if !strings.HasSuffix(got, " abc") {
+		t.Errorf("short values must not be redacted: %q", got)
+	}
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	var r *Redactor
+	if got := r.Redact("plain"); got != "plain" {
+		t.Errorf("nil Redact() = %q", got)
+	}
+	if NewRedactor(nil) != nil {
+		t.Error("NewRedactor(nil) should be nil")
+	}
+}
+
+func TestRedactor_RedactPayload(t *testing.T) {
+	r := NewRedactor([]*Secret{{Name: "TOKEN", Value: "supersecret"}})
+	in := map[string]interface{}{
+		"reason":   "failed with supersecret",
+		"count":    3,
+		"sessions": []string{"a", "supersecret"},
+		"nested":   map[string]interface{}{"x": []interface{}{"supersecret"}},
+	}
+
+	out := r.RedactPayload(in)
+	if out["reason"] != "failed with [REDACTED:TOKEN]" {
+		t.Errorf("reason = %v", out["reason"])
+	}
+	if out["count"] != 3 {
+		t.Errorf("count = %v", out["count"])
+	}
+	if out["sessions"].([]string)[1] != "[REDACTED:TOKEN]" {
+		t.Errorf("sessions = %v", out["sessions"])
+	}
+	nested := out["nested"].(map[string]interface{})["x"].([]interface{})
+	if nested[0] != "[REDACTED:TOKEN]" {
+		t.Errorf("nested = %v", nested)
+	}
+	if in["reason"] != "failed with supersecret" {
+		t.Error("RedactPayload must not mutate its input")
+	}
+}
+
+func TestLoadRedactor_CoversRigStores(t *testing.T) {
+	townRoot, _ := setupStores(t)
+	ResetRedactorCache()
+	defer ResetRedactorCache()
+
+	r := LoadRedactor(townRoot)
+	got := r.Redact("town-level-token rig-level-token crew-only-value")
+	if strings.Contains(got, "-level-token") || strings.Contains(got, "crew-only-value") {
+		t.Errorf("LoadRedactor() missed values: %q", got)
+	}
+}
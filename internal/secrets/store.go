@@ -0,0 +1,377 @@
+// Package secrets provides an encrypted secrets store for towns and rigs.
+//
+// Secrets replace plaintext overlay files (.runtime/overlay/.env) and ad-hoc
+// setup hooks as the way credentials reach agent worktrees. Each store is a
+// single encrypted file:
+//
+//	<town>/.runtime/secrets/town.enc   <- town-wide secrets
+//	<rig>/.runtime/secrets.enc         <- rig-scoped secrets (override town)
+//
+// All stores in a town share one 256-bit key kept in
+// <town>/.runtime/secrets/master.key (mode 0600), or supplied via the
+// GT_SECRETS_KEY environment variable. Stores are sealed with NaCl
+// secretbox (XSalsa20-Poly1305) under a fresh random nonce on every save.
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// KeyEnv is the environment variable that overrides the on-disk master key.
+// The value must be 32 bytes encoded as hex or base64.
+const KeyEnv = "GT_SECRETS_KEY"
+
+// CipherSecretbox identifies the envelope cipher.
+const CipherSecretbox = "nacl-secretbox"
+
+// CurrentStoreVersion is the current schema version for encrypted stores.
+const CurrentStoreVersion = 1
+
+// Errors returned by the secrets store.
+var (
+	ErrNoKey       = errors.New("no secrets key configured (run 'gt secrets set' to create one)")
+	ErrInvalidName = errors.New("invalid secret name")
+	ErrNotFound    = errors.New("secret not found")
+	ErrDecrypt     = errors.New("cannot decrypt secrets store (wrong key?)")
+)
+
+// validName matches secret names. Names double as environment variable
+// names for env-mode secrets, so they are restricted to that alphabet.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Secret is a single named secret.
+type Secret struct {
+	// Name identifies the secret. For env-mode secrets this is also the
+	// environment variable name injected into sessions.
+	Name string `json:"name"`
+
+	// Value is the plaintext secret value.
+	Value string `json:"value"`
+
+	// Roles limits which agent roles receive the secret (e.g., "polecat", "crew").
+	// Empty means every role.
+	Roles []string `json:"roles,omitempty"`
+
+	// File, when set, materializes the value as a file at this path relative
+	// to the worktree root (e.g., ".env") instead of injecting an env var.
+	File string `json:"file,omitempty"`
+
+	// UpdatedAt is when the secret was last set.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsFile reports whether the secret is materialized as a file.
+func (s *Secret) IsFile() bool {
+	return s.File != ""
+}
+
+// AppliesTo reports whether the secret is scoped to the given role.
+func (s *Secret) AppliesTo(role string) bool {
+	if len(s.Roles) == 0 {
+		return true
+	}
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// envelope is the on-disk format of an encrypted store.
+type envelope struct {
+	Version int    `json:"version"`
+	Cipher  string `json:"cipher"`
+	Nonce   string `json:"nonce"`
+	Data    string `json:"data"`
+}
+
+// payload is the plaintext content sealed inside an envelope.
+type payload struct {
+	Secrets map[string]*Secret `json:"secrets"`
+}
+
+// Store is an in-memory view of one encrypted secrets file.
+type Store struct {
+	path    string
+	key     []byte
+	secrets map[string]*Secret
+}
+
+// KeyPath returns the path of the town master key.
+func KeyPath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "secrets", "master.key")
+}
+
+// TownStorePath returns the path of the town-wide secrets store.
+func TownStorePath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "secrets", "town.enc")
+}
+
+// RigStorePath returns the path of a rig's secrets store.
+func RigStorePath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "secrets.enc")
+}
+
+// LoadKey returns the town master key.
+// GT_SECRETS_KEY takes precedence over the key file. If no key exists and
+// create is true, a new random key is generated and written with mode 0600.
+// Returns ErrNoKey if no key exists and create is false.
+func LoadKey(townRoot string, create bool) ([]byte, error) {
+	if env := strings.TrimSpace(os.Getenv(KeyEnv)); env != "" {
+		return decodeKey(env)
+	}
+
+	path := KeyPath(townRoot)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err == nil {
+		return decodeKey(strings.TrimSpace(string(data)))
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading secrets key: %w", err)
+	}
+	if !create {
+		return nil, ErrNoKey
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating secrets key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating secrets dir: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("writing secrets key: %w", err)
+	}
+	return key, nil
+}
+
+// decodeKey parses a 32-byte key from hex or base64.
+func decodeKey(s string) ([]byte, error) {
+	if key, err := hex.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("secrets key must be 32 bytes encoded as hex or base64")
+}
+
+// Open loads the store at path using key.
+// A missing file yields an empty store that is created on Save.
+func Open(path string, key []byte) (*Store, error) {
+	s := &Store{
+		path:    path,
+		key:     key,
+		secrets: make(map[string]*Secret),
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: store paths are constructed from trusted roots
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading secrets store: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("parsing secrets store %s: %w", path, err)
+	}
+	if env.Cipher != CipherSecretbox {
+		return nil, fmt.Errorf("secrets store %s: unsupported cipher %q", path, env.Cipher)
+	}
+
+	plain, err := open(key, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var p payload
+	if err := json.Unmarshal(plain, &p); err != nil {
+		return nil, fmt.Errorf("parsing decrypted secrets: %w", err)
+	}
+	if p.Secrets != nil {
+		s.secrets = p.Secrets
+	}
+	return s, nil
+}
+
+// OpenTown opens the town-wide store.
+// If create is true, a master key is generated when none exists.
+func OpenTown(townRoot string, create bool) (*Store, error) {
+	key, err := LoadKey(townRoot, create)
+	if err != nil {
+		return nil, err
+	}
+	return Open(TownStorePath(townRoot), key)
+}
+
+// OpenRig opens a rig's store using the town master key.
+// If create is true, a master key is generated when none exists.
+func OpenRig(townRoot, rigPath string, create bool) (*Store, error) {
+	key, err := LoadKey(townRoot, create)
+	if err != nil {
+		return nil, err
+	}
+	return Open(RigStorePath(rigPath), key)
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save encrypts and atomically writes the store with mode 0600.
+func (s *Store) Save() error {
+	plain, err := json.Marshal(payload{Secrets: s.secrets})
+	if err != nil {
+		return fmt.Errorf("marshaling secrets: %w", err)
+	}
+
+	env, err := seal(s.key, plain)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling secrets envelope: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating secrets dir: %w", err)
+	}
+	if err := util.AtomicWriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing secrets store: %w", err)
+	}
+	return nil
+}
+
+// Set adds or replaces a secret.
+func (s *Store) Set(sec Secret) error {
+	if err := ValidateName(sec.Name); err != nil {
+		return err
+	}
+	if sec.File != "" {
+		clean, err := cleanRelPath(sec.File)
+		if err != nil {
+			return err
+		}
+		sec.File = clean
+	}
+	if sec.UpdatedAt.IsZero() {
+		sec.UpdatedAt = time.Now().UTC()
+	}
+	s.secrets[sec.Name] = &sec
+	return nil
+}
+
+// Get returns the named secret.
+func (s *Store) Get(name string) (*Secret, bool) {
+	sec, ok := s.secrets[name]
+	return sec, ok
+}
+
+// Delete removes the named secret. Returns false if it did not exist.
+func (s *Store) Delete(name string) bool {
+	if _, ok := s.secrets[name]; !ok {
+		return false
+	}
+	delete(s.secrets, name)
+	return true
+}
+
+// List returns all secrets sorted by name.
+func (s *Store) List() []*Secret {
+	out := make([]*Secret, 0, len(s.secrets))
+	for _, sec := range s.secrets {
+		out = append(out, sec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ValidateName checks that name is usable as a secret (and env var) name.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("%w %q: use letters, digits and underscores, not starting with a digit", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// cleanRelPath validates a worktree-relative materialization path.
+// Absolute paths and paths escaping the worktree are rejected.
+func cleanRelPath(p string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(p))
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." ||
+		strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("secret file path %q must be relative to the worktree", p)
+	}
+	return clean, nil
+}
+
+// seal encrypts plain with NaCl secretbox under a fresh random nonce.
+func seal(key, plain []byte) (*envelope, error) {
+	k, err := boxKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	return &envelope{
+		Version: CurrentStoreVersion,
+		Cipher:  CipherSecretbox,
+		Nonce:   base64.StdEncoding.EncodeToString(nonce[:]),
+		Data:    base64.StdEncoding.EncodeToString(secretbox.Seal(nil, plain, &nonce, k)),
+	}, nil
+}
+
+// open decrypts an envelope produced by seal.
+func open(key []byte, env envelope) ([]byte, error) {
+	k, err := boxKey(key)
+	if err != nil {
+		return nil, err
+	}
+	rawNonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil || len(rawNonce) != 24 {
+		return nil, fmt.Errorf("secrets store: invalid nonce")
+	}
+	var nonce [24]byte
+	copy(nonce[:], rawNonce)
+	data, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, fmt.Errorf("secrets store: invalid data encoding")
+	}
+	plain, ok := secretbox.Open(nil, data, &nonce, k)
+	if !ok {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func boxKey(key []byte) (*[32]byte, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+	var k [32]byte
+	copy(k[:], key)
+	return &k, nil
+}
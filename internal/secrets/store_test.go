@@ -0,0 +1,154 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadKey_CreatesKeyWithRestrictedPerms(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	townRoot := t.TempDir()
+
+	if _, err := LoadKey(townRoot, false); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("LoadKey(create=false) error = %v, want ErrNoKey", err)
+	}
+
+	key, err := LoadKey(townRoot, true)
+	if err != nil {
+		t.Fatalf("LoadKey(create=true) error = %v", err)
+	}
+	if len(key) != 32 {
+		t.Errorf("key length = %d, want 32", len(key))
+	}
+
+	info, err := os.Stat(KeyPath(townRoot))
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file perm = %o, want 0600", perm)
+	}
+
+	again, err := LoadKey(townRoot, false)
+	if err != nil {
+		t.Fatalf("LoadKey reload error = %v", err)
+	}
+	if string(again) != string(key) {
+		t.Error("reloaded key differs from created key")
+	}
+}
+
+func TestLoadKey_EnvOverride(t *testing.T) {
+	t.Setenv(KeyEnv, strings.Repeat("ab", 32))
+	key, err := LoadKey(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if key[0] != 0xab {
+		t.Errorf("key[0] = %x, want ab", key[0])
+	}
+
+	t.Setenv(KeyEnv, "too-short")
+	if _, err := LoadKey(t.TempDir(), false); err == nil {
+		t.Error("expected error for malformed env key")
+	}
+}
+
+func TestStore_RoundTripIsEncrypted(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	townRoot := t.TempDir()
+
+	store, err := OpenTown(townRoot, true)
+	if err != nil {
+		t.Fatalf("OpenTown() error = %v", err)
+	}
+	if err := store.Set(Secret{Name: "API_TOKEN", Value: "s3cr3t-value", Roles: []string{"polecat"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(TownStorePath(townRoot))
+	if err != nil {
+		t.Fatalf("reading store: %v", err)
+	}
+	if strings.Contains(string(raw), "s3cr3t-value") || strings.Contains(string(raw), "API_TOKEN") {
+		t.Error("store file contains plaintext")
+	}
+
+	reopened, err := OpenTown(townRoot, false)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	sec, ok := reopened.Get("API_TOKEN")
+	if !ok {
+		t.Fatal("secret missing after reopen")
+	}
+	if sec.Value != "s3cr3t-value" || !sec.AppliesTo("polecat") || sec.AppliesTo("crew") {
+		t.Errorf("unexpected secret after reopen: %+v", sec)
+	}
+}
+
+func TestStore_WrongKey(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	townRoot := t.TempDir()
+
+	store, err := OpenTown(townRoot, true)
+	if err != nil {
+		t.Fatalf("OpenTown() error = %v", err)
+	}
+	_ = store.Set(Secret{Name: "X_TOKEN", Value: "abcdefgh"})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	t.Setenv(KeyEnv, strings.Repeat("00", 32))
+	if _, err := OpenTown(townRoot, false); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("OpenTown with wrong key error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestStore_SetValidation(t *testing.T) {
+	store := &Store{secrets: make(map[string]*Secret)}
+
+	tests := []struct {
+		name    string
+		secret  Secret
+		wantErr bool
+	}{
+		{"valid env", Secret{Name: "DB_PASSWORD", Value: "x"}, false},
+		{"valid file", Secret{Name: "dotenv", Value: "x", File: "config/.env"}, false},
+		{"leading digit", Secret{Name: "1TOKEN", Value: "x"}, true},
+		{"dash", Secret{Name: "my-token", Value: "x"}, true},
+		{"absolute file", Secret{Name: "f", Value: "x", File: "/etc/passwd"}, true},
+		{"escaping file", Secret{Name: "f", Value: "x", File: "../outside"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.Set(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStore_DeleteAndList(t *testing.T) {
+	store := &Store{secrets: make(map[string]*Secret)}
+	_ = store.Set(Secret{Name: "B", Value: "2"})
+	_ = store.Set(Secret{Name: "A", Value: "1"})
+
+	list := store.List()
+	if len(list) != 2 || list[0].Name != "A" || list[1].Name != "B" {
+		t.Errorf("List() = %v, want sorted [A B]", list)
+	}
+	if !store.Delete("A") {
+		t.Error("Delete(A) = false, want true")
+	}
+	if store.Delete("A") {
+		t.Error("second Delete(A) = true, want false")
+	}
+}
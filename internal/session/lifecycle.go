@@ -8,6 +8,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -174,6 +175,20 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 		command = config.PrependEnv(command, cfg.ExtraEnv)
 	}
 
+	// Apply the rig's sandbox profile, if any (rig-level roles only).
+	if cfg.RigPath != "" {
+		var err error
+		command, err = ApplySandbox(sandbox.Paths{
+			TownRoot: cfg.TownRoot,
+			RigPath:  cfg.RigPath,
+			AgentDir: cfg.WorkDir,
+			WorkDir:  cfg.WorkDir,
+		}, cfg.Role, cfg.SessionID, command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 4. Create tmux session with command.
 	if err := t.NewSessionWithCommand(cfg.SessionID, cfg.WorkDir, command); err != nil {
 		return nil, fmt.Errorf("creating session: %w", err)
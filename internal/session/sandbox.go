@@ -0,0 +1,31 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+// ApplySandbox rewrites command to run inside the rig's sandbox for role,
+// if one is configured. Sandboxes that cannot be applied fail the start when
+// the rig marks them required; otherwise a warning is printed, a
+// sandbox_unavailable event is recorded and the session starts with
+// whatever limits could be applied.
+func ApplySandbox(paths sandbox.Paths, role, sessionID, command string) (string, error) {
+	sb := sandbox.ForRole(paths, role)
+	if sb == nil {
+		return command, nil
+	}
+	wrapped, err := sb.Apply(sessionID, command)
+	if err == nil {
+		return wrapped, nil
+	}
+	if sb.Required {
+		return "", fmt.Errorf("sandbox required but unavailable: %w", err)
+	}
+	fmt.Printf("Warning: sandbox not fully applied to %s: %v\n", sessionID, err)
+	_ = events.LogAudit(events.TypeSandboxUnavailable, sessionID,
+		events.SandboxUnavailablePayload(sessionID, role, err.Error()))
+	return wrapped, nil
+}
@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// eventTypes are the events metrics are computed from.
+var eventTypes = []string{
+	events.TypeSling, events.TypeRouted, events.TypeDone,
+	events.TypeMerged, events.TypeMergeFailed, events.TypeEscalationSent,
+}
+
+// CostsLogPath returns the path of the session costs log written by
+// gt costs record (~/.gt/costs.jsonl).
+func CostsLogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/tmp/gt-costs.jsonl" // Fallback
+	}
+	return filepath.Join(home, ".gt", "costs.jsonl")
+}
+
+// Load collects attempts since the given time from the town's events log
+// (including archived segments), every rig's merge-request beads and the
+// costs log. Rigs whose beads can't be listed are skipped. Costs already
+// rolled into daily digests carry no per-worker detail and are not counted.
+func Load(townRoot string, since time.Time) (*Collector, error) {
+	c := NewCollector()
+
+	var evs []events.Event
+	q := &events.Query{Types: eventTypes, Since: since, IncludeArchive: true}
+	if err := events.Scan(townRoot, q, func(e *events.Event) error {
+		evs = append(evs, *e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	c.AddEvents(evs)
+
+	rigs, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err == nil {
+		for name := range rigs.Rigs {
+			c.AddMRs(loadMRs(filepath.Join(townRoot, name), name, since))
+		}
+	}
+
+	costs, err := loadCosts(CostsLogPath(), since)
+	if err != nil {
+		return nil, err
+	}
+	c.AddCosts(costs)
+
+	c.DefaultAgent = func(rig string) string {
+		agent, _ := config.ResolveRoleAgentName("polecat", townRoot, filepath.Join(townRoot, rig))
+		return agent
+	}
+	return c, nil
+}
+
+// loadMRs lists a rig's merge-request beads created since the given time.
+func loadMRs(rigPath, rigName string, since time.Time) []MR {
+	issues, err := beads.New(rigPath).List(beads.ListOptions{
+		Label:    "gt:merge-request",
+		Status:   "all",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil
+	}
+	var mrs []MR
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, issue.CreatedAt)
+		if !since.IsZero() && created.Before(since) {
+			continue
+		}
+		rig := fields.Rig
+		if rig == "" {
+			rig = rigName
+		}
+		mrs = append(mrs, MR{
+			SourceIssue:  fields.SourceIssue,
+			Rig:          rig,
+			Worker:       fields.Worker,
+			CloseReason:  fields.CloseReason,
+			RetryCount:   fields.RetryCount,
+			ReviewStatus: fields.ReviewStatus,
+			CreatedAt:    created,
+		})
+	}
+	return mrs
+}
+
+// loadCosts reads the costs log entries that ended since the given time.
+// A missing log has no entries.
+func loadCosts(path string, since time.Time) ([]Cost, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the user's own costs log
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var costs []Cost
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry struct {
+			Role     string    `json:"role"`
+			Rig      string    `json:"rig"`
+			Worker   string    `json:"worker"`
+			CostUSD  float64   `json:"cost_usd"`
+			EndedAt  time.Time `json:"ended_at"`
+			WorkItem string    `json:"work_item"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !since.IsZero() && entry.EndedAt.Before(since) {
+			continue
+		}
+		costs = append(costs, Cost{
+			Role:     entry.Role,
+			Rig:      entry.Rig,
+			Worker:   entry.Worker,
+			CostUSD:  entry.CostUSD,
+			EndedAt:  entry.EndedAt,
+			WorkItem: entry.WorkItem,
+		})
+	}
+	return costs, scanner.Err()
+}
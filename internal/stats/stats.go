@@ -0,0 +1,280 @@
+// Package stats computes agent performance metrics from the town's events
+// log, merge-request beads and session costs: how much work each polecat
+// identity, agent preset and rig completes, how often it merges, how much
+// rework and escalation it needs, and what it costs.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Grouping selects what metrics are aggregated by.
+type Grouping string
+
+const (
+	ByIdentity Grouping = "identity" // Worker identity (rig/polecats/name)
+	ByAgent    Grouping = "agent"    // Agent preset (claude, codex, ...)
+	ByRig      Grouping = "rig"
+)
+
+// UnknownAgent groups attempts whose agent preset could not be determined.
+const UnknownAgent = "unknown"
+
+// Metrics summarizes the attempts of one group.
+type Metrics struct {
+	Key string `json:"key"`
+
+	Slung       int `json:"slung"`        // Attempts started (sling)
+	Completed   int `json:"completed"`    // Attempts that reached gt done
+	Merged      int `json:"merged"`       // Completed work that landed
+	MergeFailed int `json:"merge_failed"` // MRs rejected or failed to merge
+	Rework      int `json:"rework"`       // Work sent back: re-slung after done, or review rework
+	Conflicts   int `json:"conflicts"`    // Merge conflict cycles
+	Escalations int `json:"escalations"`  // Escalations raised by the worker
+
+	MergeSuccessRate float64 `json:"merge_success_rate"` // Merged / (merged + failed); 0 if none finished
+	EscalationRate   float64 `json:"escalation_rate"`    // Escalations per attempt
+
+	MedianTimeToDone time.Duration `json:"-"`                           // Sling to done
+	MedianDoneSecs   float64       `json:"median_time_to_done_seconds"` // MedianTimeToDone in seconds
+	CostUSD          float64       `json:"cost_usd"`
+	CostPerMerged    float64       `json:"cost_per_merged_usd"` // 0 if nothing merged
+}
+
+// MR is the part of a merge-request bead that stats reads.
+type MR struct {
+	SourceIssue  string
+	Rig          string
+	Worker       string // Polecat name
+	CloseReason  string // merged, rejected, conflict, superseded
+	RetryCount   int    // Conflict-resolution cycles
+	ReviewStatus string
+	CreatedAt    time.Time
+}
+
+// Cost is one session's cost, as recorded by gt costs record.
+type Cost struct {
+	Role     string
+	Rig      string
+	Worker   string
+	CostUSD  float64
+	EndedAt  time.Time
+	WorkItem string
+}
+
+// attempt is one run of a bead by one worker, with what stats adds to the
+// replayed events.
+type attempt struct {
+	*events.Attempt
+	counted           bool // Started by a sling (not MR or cost data alone)
+	rework, conflicts int
+	escalations       int
+	cost              float64
+}
+
+// Collector accumulates attempts from events, MRs and costs.
+type Collector struct {
+	replay   *events.Attempts
+	attempts []*attempt
+	of       map[*events.Attempt]*attempt
+
+	// DefaultAgent resolves the agent preset of attempts that didn't
+	// record one, from their rig. Optional.
+	DefaultAgent func(rig string) string
+}
+
+// NewCollector returns an empty collector.
+func NewCollector() *Collector {
+	return &Collector{replay: events.NewAttempts(), of: make(map[*events.Attempt]*attempt)}
+}
+
+// AddEvents replays events in log order.
+func (c *Collector) AddEvents(evs []events.Event) {
+	for i := range evs {
+		e := &evs[i]
+		c.replay.Add(e)
+		for _, a := range c.replay.All[len(c.of):] {
+			sa := &attempt{Attempt: a, counted: true}
+			c.of[a] = sa
+			c.attempts = append(c.attempts, sa)
+		}
+		if e.Type == events.TypeEscalationSent {
+			ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+			if a := c.openAttemptOf(e.Actor, ts); a != nil {
+				a.escalations++
+			}
+		}
+	}
+}
+
+// latest returns the bead's latest attempt seen in the events, or nil.
+func (c *Collector) latest(bead string) *attempt {
+	return c.of[c.replay.Latest(bead)]
+}
+
+// openAttemptOf returns identity's latest attempt started at or before t.
+func (c *Collector) openAttemptOf(identity string, t time.Time) *attempt {
+	for i := len(c.attempts) - 1; i >= 0; i-- {
+		a := c.attempts[i]
+		if a.Target == identity && !a.SlungAt.After(t) {
+			return a
+		}
+	}
+	return nil
+}
+
+// AddMRs folds merge-request outcomes into the attempts that produced them.
+// An MR with no matching attempt (its sling is older than the events read)
+// still counts toward its worker's merge record.
+func (c *Collector) AddMRs(mrs []MR) {
+	for _, mr := range mrs {
+		identity := identityOf(mr.Rig, "polecat", mr.Worker)
+		if identity == "" {
+			continue
+		}
+		a := c.latest(mr.SourceIssue)
+		if a == nil || a.Target != identity {
+			a = &attempt{Attempt: &events.Attempt{Bead: mr.SourceIssue, Rig: mr.Rig, Target: identity,
+				SlungAt: mr.CreatedAt, Done: true}}
+			c.attempts = append(c.attempts, a)
+		}
+		switch mr.CloseReason {
+		case "merged":
+			a.Merged = true
+		case "rejected":
+			a.MergeFailed = true
+		case "conflict":
+			a.MergeFailed = true
+			a.conflicts++
+		}
+		a.conflicts += mr.RetryCount
+		if mr.ReviewStatus == "changes_requested" || mr.ReviewStatus == "rework_requested" {
+			a.rework++
+		}
+	}
+}
+
+// AddCosts attributes session costs to attempts: to the attempt on the
+// session's work item if recorded, else to the worker's latest attempt
+// started before the session ended. Costs of workers with no attempt are
+// kept on cost-only records; town-level roles are skipped.
+func (c *Collector) AddCosts(costs []Cost) {
+	for _, cost := range costs {
+		identity := identityOf(cost.Rig, cost.Role, cost.Worker)
+		if identity == "" {
+			continue
+		}
+		a := c.latest(cost.WorkItem)
+		if a == nil || a.Target != identity {
+			a = c.openAttemptOf(identity, cost.EndedAt)
+		}
+		if a == nil {
+			a = &attempt{Attempt: &events.Attempt{Rig: cost.Rig, Target: identity, SlungAt: cost.EndedAt}}
+			c.attempts = append(c.attempts, a)
+		}
+		a.cost += cost.CostUSD
+	}
+}
+
+// Metrics aggregates the attempts by grouping, sorted by key. rig, when
+// set, keeps only that rig's attempts.
+func (c *Collector) Metrics(by Grouping, rig string) []Metrics {
+	groups := make(map[string]*Metrics)
+	durations := make(map[string][]time.Duration)
+	for _, a := range c.attempts {
+		if rig != "" && a.Rig != rig {
+			continue
+		}
+		key := c.keyOf(a, by)
+		m := groups[key]
+		if m == nil {
+			m = &Metrics{Key: key}
+			groups[key] = m
+		}
+		if a.counted {
+			m.Slung++
+		}
+		if a.Done && a.counted {
+			m.Completed++
+			if !a.DoneAt.IsZero() && a.DoneAt.After(a.SlungAt) {
+				durations[key] = append(durations[key], a.DoneAt.Sub(a.SlungAt))
+			}
+		}
+		switch {
+		case a.Merged:
+			m.Merged++
+		case a.MergeFailed:
+			m.MergeFailed++
+		}
+		m.Rework += a.rework + a.Reslung
+		m.Conflicts += a.conflicts
+		m.Escalations += a.escalations
+		m.CostUSD += a.cost
+	}
+
+	out := make([]Metrics, 0, len(groups))
+	for key, m := range groups {
+		if n := m.Merged + m.MergeFailed; n > 0 {
+			m.MergeSuccessRate = float64(m.Merged) / float64(n)
+		}
+		if m.Slung > 0 {
+			m.EscalationRate = float64(m.Escalations) / float64(m.Slung)
+		}
+		if m.Merged > 0 {
+			m.CostPerMerged = m.CostUSD / float64(m.Merged)
+		}
+		m.MedianTimeToDone = median(durations[key])
+		m.MedianDoneSecs = m.MedianTimeToDone.Seconds()
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (c *Collector) keyOf(a *attempt, by Grouping) string {
+	switch by {
+	case ByAgent:
+		if a.Agent != "" {
+			return a.Agent
+		}
+		if c.DefaultAgent != nil {
+			if agent := c.DefaultAgent(a.Rig); agent != "" {
+				return agent
+			}
+		}
+		return UnknownAgent
+	case ByRig:
+		return a.Rig
+	}
+	return a.Target
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	mid := len(ds) / 2
+	if len(ds)%2 == 0 {
+		return (ds[mid-1] + ds[mid]) / 2
+	}
+	return ds[mid]
+}
+
+// identityOf returns the worker identity of a rig worker in a role, or ""
+// for town-level and infrastructure roles.
+func identityOf(rig, role, worker string) string {
+	if rig == "" || worker == "" {
+		return ""
+	}
+	switch role {
+	case "polecat":
+		return rig + "/polecats/" + worker
+	case "crew":
+		return rig + "/crew/" + worker
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package stats
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+var t0 = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func ev(typ, actor string, at time.Duration, payload map[string]interface{}) events.Event {
+	return events.Event{Timestamp: t0.Add(at).Format(time.RFC3339), Type: typ, Actor: actor, Payload: payload}
+}
+
+func sampleCollector() *Collector {
+	c := NewCollector()
+	c.AddEvents([]events.Event{
+		// Toast (claude) finishes gt-1 in 2h and it merges.
+		ev(events.TypeSling, "mayor", 0, map[string]interface{}{"bead": "gt-1", "target": "gastown/polecats/Toast", "agent": "claude"}),
+		ev(events.TypeDone, "gastown/polecats/Toast", 2*time.Hour, map[string]interface{}{"bead": "gt-1", "branch": "polecat/Toast/gt-1"}),
+		ev(events.TypeMerged, "gastown/witness", 3*time.Hour, map[string]interface{}{"bead": "gt-1"}),
+
+		// Nux (routed to codex) escalates, finishes gt-2 in 4h, the merge
+		// fails, and the bead comes back to Toast.
+		ev(events.TypeRouted, "mayor", time.Hour, map[string]interface{}{"bead": "gt-2", "target": "gastown", "agent": "codex"}),
+		ev(events.TypeSling, "mayor", time.Hour, map[string]interface{}{"bead": "gt-2", "target": "gastown/polecats/Nux"}),
+		ev(events.TypeEscalationSent, "gastown/polecats/Nux", 2*time.Hour, map[string]interface{}{"severity": "medium"}),
+		ev(events.TypeDone, "gastown/polecats/Nux", 5*time.Hour, map[string]interface{}{"bead": "gt-2", "branch": "polecat/Nux/gt-2"}),
+		ev(events.TypeMergeFailed, "gastown/refinery", 6*time.Hour, map[string]interface{}{"branch": "polecat/Nux/gt-2"}),
+		ev(events.TypeSling, "mayor", 7*time.Hour, map[string]interface{}{"bead": "gt-2", "target": "gastown/polecats/Toast"}),
+
+		// Furiosa works on another rig with no agent recorded.
+		ev(events.TypeSling, "mayor", 0, map[string]interface{}{"bead": "bd-1", "target": "beads/polecats/Furiosa"}),
+	})
+	c.AddMRs([]MR{
+		{SourceIssue: "gt-1", Rig: "gastown", Worker: "Toast", CloseReason: "merged", RetryCount: 1},
+		// An MR whose sling predates the events read.
+		{SourceIssue: "gt-0", Rig: "gastown", Worker: "Nux", CloseReason: "merged", CreatedAt: t0.Add(-24 * time.Hour)},
+	})
+	c.AddCosts([]Cost{
+		{Role: "polecat", Rig: "gastown", Worker: "Toast", CostUSD: 3, EndedAt: t0.Add(2 * time.Hour)},
+		{Role: "polecat", Rig: "gastown", Worker: "Nux", CostUSD: 5, EndedAt: t0.Add(5 * time.Hour), WorkItem: "gt-2"},
+		{Role: "mayor", CostUSD: 100, EndedAt: t0},
+	})
+	return c
+}
+
+func byKey(ms []Metrics) map[string]Metrics {
+	out := make(map[string]Metrics, len(ms))
+	for _, m := range ms {
+		out[m.Key] = m
+	}
+	return out
+}
+
+func TestMetricsByIdentity(t *testing.T) {
+	got := byKey(sampleCollector().Metrics(ByIdentity, ""))
+
+	toast := got["gastown/polecats/Toast"]
+	if toast.Slung != 2 || toast.Completed != 1 || toast.Merged != 1 || toast.MergeFailed != 0 ||
+		toast.Conflicts != 1 || toast.CostUSD != 3 || toast.CostPerMerged != 3 {
+		t.Errorf("Toast = %+v", toast)
+	}
+	if toast.MedianTimeToDone != 2*time.Hour || toast.MedianDoneSecs != 7200 {
+		t.Errorf("Toast time to done = %v", toast.MedianTimeToDone)
+	}
+
+	nux := got["gastown/polecats/Nux"]
+	if nux.Slung != 1 || nux.Completed != 1 || nux.Merged != 1 || nux.MergeFailed != 1 ||
+		nux.Rework != 1 || nux.Escalations != 1 || nux.EscalationRate != 1 || nux.CostUSD != 5 {
+		t.Errorf("Nux = %+v", nux)
+	}
+	if nux.MergeSuccessRate != 0.5 {
+		t.Errorf("Nux merge success = %v, want 0.5", nux.MergeSuccessRate)
+	}
+	if _, ok := got[""]; ok {
+		t.Error("town-level costs should not be attributed")
+	}
+}
+
+func TestMetricsByAgentAndRig(t *testing.T) {
+	c := sampleCollector()
+	c.DefaultAgent = func(rig string) string {
+		if rig == "beads" {
+			return "gemini"
+		}
+		return ""
+	}
+
+	agents := byKey(c.Metrics(ByAgent, ""))
+	if m := agents["claude"]; m.Slung != 1 || m.Merged != 1 {
+		t.Errorf("claude = %+v", m)
+	}
+	if m := agents["codex"]; m.Slung != 1 || m.MergeFailed != 1 || m.Escalations != 1 {
+		t.Errorf("codex = %+v", m)
+	}
+	if m := agents["gemini"]; m.Slung != 1 {
+		t.Errorf("gemini (rig default) = %+v", m)
+	}
+	// Toast's re-sling of gt-2 and Nux's older MR recorded no agent.
+	if m := agents[UnknownAgent]; m.Slung != 1 || m.Merged != 1 {
+		t.Errorf("unknown = %+v", m)
+	}
+
+	rigs := byKey(c.Metrics(ByRig, ""))
+	if len(rigs) != 2 || rigs["gastown"].Slung != 3 || rigs["beads"].Slung != 1 {
+		t.Errorf("rigs = %+v", rigs)
+	}
+	if only := c.Metrics(ByRig, "beads"); len(only) != 1 || only[0].Key != "beads" {
+		t.Errorf("rig filter = %+v", only)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if got := median([]time.Duration{3, 1, 2, 10}); got != 2 {
+		t.Errorf("median = %v, want 2", got)
+	}
+	if got := median(nil); got != 0 {
+		t.Errorf("median(nil) = %v", got)
+	}
+}
+
+func TestLoadCosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "costs.jsonl")
+	data := `{"session_id":"gt-gastown-Toast","role":"polecat","rig":"gastown","worker":"Toast","cost_usd":1.25,"ended_at":"2026-10-01T10:00:00Z"}
+not json
+{"session_id":"gt-gastown-Nux","role":"polecat","rig":"gastown","worker":"Nux","cost_usd":9,"ended_at":"2026-09-01T10:00:00Z"}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	costs, err := loadCosts(path, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(costs) != 1 || costs[0].Worker != "Toast" || math.Abs(costs[0].CostUSD-1.25) > 1e-9 {
+		t.Errorf("costs = %+v", costs)
+	}
+	if costs, err := loadCosts(filepath.Join(t.TempDir(), "missing"), t0); err != nil || costs != nil {
+		t.Errorf("missing log = %+v, %v", costs, err)
+	}
+}
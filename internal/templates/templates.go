@@ -64,6 +64,7 @@ type RoleData struct {
 	IssuePrefix    string   // beads issue prefix
 	MayorSession   string   // e.g., "gt-ai-mayor" - dynamic mayor session name
 	DeaconSession  string   // e.g., "gt-ai-deacon" - dynamic deacon session name
+	Address        string   // mail address (for custom roles, e.g., "greenplace/reviewer")
 }
 
 // SpawnData contains information for spawn assignment messages.
@@ -148,6 +149,25 @@ func (t *Templates) RenderRole(role string, data RoleData) (string, error) {
 	return buf.String(), nil
 }
 
+// RenderRoleFile renders a role context template read from path, such as a
+// custom role's prompt template. It has the same data and functions as the
+// built-in role templates.
+func RenderRoleFile(path string, data RoleData) (string, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted town config
+	if err != nil {
+		return "", fmt.Errorf("reading role template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("parsing role template %s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering role template %s: %w", path, err)
+	}
+	return buf.String(), nil
+}
+
 // RenderMessage renders a message template.
 func (t *Templates) RenderMessage(name string, data interface{}) (string, error) {
 	templateName := name + ".md.tmpl"
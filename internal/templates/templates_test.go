@@ -1,6 +1,8 @@
 package templates
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -296,3 +298,21 @@ func TestGetAllRoleTemplates_ContentValidity(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderRoleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewer.md.tmpl")
+	if err := os.WriteFile(path, []byte("# {{ .Role }} for {{ .RigName }}\nMail: {{ .Address }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := RenderRoleFile(path, RoleData{Role: "reviewer", RigName: "greenplace", Address: "greenplace/reviewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "# reviewer for greenplace\nMail: greenplace/reviewer\n" {
+		t.Errorf("RenderRoleFile = %q", out)
+	}
+
+	if _, err := RenderRoleFile(filepath.Join(t.TempDir(), "missing.md.tmpl"), RoleData{}); err == nil {
+		t.Error("expected an error for a missing template")
+	}
+}
@@ -0,0 +1,222 @@
+package testresults
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// historyRetention drops tests not seen for this long.
+const historyRetention = 90 * 24 * time.Hour
+
+// TestRecord is what a rig's history knows about one test.
+type TestRecord struct {
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	Flips      int       `json:"flips"` // Runs where it both passed and failed on the same tree
+	LastStatus Status    `json:"last_status,omitempty"`
+	LastTree   string    `json:"last_tree,omitempty"`
+	LastFlip   time.Time `json:"last_flip,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// IsFlaky reports whether the test has ever flipped on an unchanged tree.
+func (r *TestRecord) IsFlaky() bool {
+	return r.Flips > 0
+}
+
+// History is a rig's per-test record across merge requests.
+type History struct {
+	Tests map[string]*TestRecord `json:"tests"`
+}
+
+// HistoryPath returns where a rig's test history is kept.
+func HistoryPath(rigPath string) string {
+	return filepath.Join(constants.RigRuntimePath(rigPath), "refinery", "test-history.json")
+}
+
+// LoadHistory reads a rig's test history. A missing file is an empty history.
+func LoadHistory(rigPath string) (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(HistoryPath(rigPath))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, h); err != nil {
+			return nil, fmt.Errorf("parsing test history: %w", err)
+		}
+	}
+	if h.Tests == nil {
+		h.Tests = make(map[string]*TestRecord)
+	}
+	return h, nil
+}
+
+// Save writes the history, dropping tests not seen recently.
+func (h *History) Save(rigPath string) error {
+	cutoff := time.Now().Add(-historyRetention)
+	for id, r := range h.Tests {
+		if r.LastSeen.Before(cutoff) {
+			delete(h.Tests, id)
+		}
+	}
+	return util.EnsureDirAndWriteJSON(HistoryPath(rigPath), h)
+}
+
+// Record folds one verification run on tree into the history. outcomes maps
+// each test ID to its statuses across the attempts of the run. A test flips
+// when it both passed and failed within the run, or when it ends up with a
+// different status than the last run on the same tree.
+func (h *History) Record(tree string, outcomes map[string][]Status, now time.Time) {
+	for id, statuses := range outcomes {
+		passed, failed := false, false
+		for _, s := range statuses {
+			switch s {
+			case StatusPass:
+				passed = true
+			case StatusFail:
+				failed = true
+			}
+		}
+		if !passed && !failed {
+			continue // Skipped throughout
+		}
+		r := h.Tests[id]
+		if r == nil {
+			r = &TestRecord{}
+			h.Tests[id] = r
+		}
+		final := statuses[len(statuses)-1]
+		if final == StatusSkip {
+			final = StatusPass
+			if failed && !passed {
+				final = StatusFail
+			}
+		}
+
+		r.Runs++
+		if failed {
+			r.Failures++
+		}
+		flipped := passed && failed
+		if !flipped && tree != "" && r.LastTree == tree && r.LastStatus != "" && r.LastStatus != final {
+			flipped = true
+		}
+		if flipped {
+			r.Flips++
+			r.LastFlip = now
+		}
+		r.LastStatus = final
+		r.LastTree = tree
+		r.LastSeen = now
+	}
+}
+
+// FlakyTest is a flaky test with its record.
+type FlakyTest struct {
+	ID string
+	*TestRecord
+}
+
+// Flaky returns the flaky tests, most flips first.
+func (h *History) Flaky() []FlakyTest {
+	var flaky []FlakyTest
+	for id, r := range h.Tests {
+		if r.IsFlaky() {
+			flaky = append(flaky, FlakyTest{ID: id, TestRecord: r})
+		}
+	}
+	sort.Slice(flaky, func(i, j int) bool {
+		if flaky[i].Flips != flaky[j].Flips {
+			return flaky[i].Flips > flaky[j].Flips
+		}
+		return flaky[i].ID < flaky[j].ID
+	})
+	return flaky
+}
+
+// QuarantineEntry records why and by whom a test was quarantined.
+type QuarantineEntry struct {
+	Reason string    `json:"reason,omitempty"`
+	By     string    `json:"by,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Quarantine is a rig's list of tests whose failures do not block merges.
+// Entries are test IDs ("suite.name") or bare names, which match the test
+// in any suite.
+type Quarantine struct {
+	Tests map[string]QuarantineEntry `json:"tests"`
+}
+
+// QuarantinePath returns where a rig's quarantine list is kept.
+func QuarantinePath(rigPath string) string {
+	return filepath.Join(rigPath, "settings", "quarantine.json")
+}
+
+// LoadQuarantine reads a rig's quarantine list. A missing file is empty.
+func LoadQuarantine(rigPath string) (*Quarantine, error) {
+	q := &Quarantine{}
+	data, err := os.ReadFile(QuarantinePath(rigPath))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, q); err != nil {
+			return nil, fmt.Errorf("parsing quarantine list: %w", err)
+		}
+	}
+	if q.Tests == nil {
+		q.Tests = make(map[string]QuarantineEntry)
+	}
+	return q, nil
+}
+
+// Save writes the quarantine list.
+func (q *Quarantine) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(QuarantinePath(rigPath), q)
+}
+
+// Has reports whether a test result is quarantined.
+func (q *Quarantine) Has(r Result) bool {
+	if q == nil {
+		return false
+	}
+	if _, ok := q.Tests[r.ID()]; ok {
+		return true
+	}
+	_, ok := q.Tests[r.Name]
+	return ok
+}
+
+// HasID reports whether the test with the given ID is quarantined, either by
+// its ID or by its bare name.
+func (q *Quarantine) HasID(id string) bool {
+	if q == nil {
+		return false
+	}
+	for entry := range q.Tests {
+		if id == entry || strings.HasSuffix(id, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// IDs returns the quarantined entries, sorted.
+func (q *Quarantine) IDs() []string {
+	ids := make([]string, 0, len(q.Tests))
+	for id := range q.Tests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,225 @@
+package testresults
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// goTestEvent is one line of go test -json output (see go doc test2json).
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// ParseGoJSON parses go test -json output. Lines that are not JSON events
+// (e.g., build errors interleaved on a shared stream) are ignored.
+func ParseGoJSON(data []byte) (*Report, error) {
+	report := &Report{}
+	type key struct{ pkg, test string }
+	index := make(map[key]int)
+	output := make(map[key]*strings.Builder)
+	failedPkgs := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev goTestEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		k := key{ev.Package, ev.Test}
+		switch ev.Action {
+		case "output":
+			b := output[k]
+			if b == nil {
+				b = &strings.Builder{}
+				output[k] = b
+			}
+			b.WriteString(ev.Output)
+		case "pass", "fail", "skip":
+			if ev.Test == "" {
+				if ev.Action == "fail" {
+					failedPkgs[ev.Package] = true
+				}
+				continue
+			}
+			r := Result{Suite: ev.Package, Name: ev.Test, Status: Status(ev.Action)}
+			if i, ok := index[k]; ok {
+				report.Tests[i] = r
+			} else {
+				index[k] = len(report.Tests)
+				report.Tests = append(report.Tests, r)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading go test output: %w", err)
+	}
+
+	for i := range report.Tests {
+		t := &report.Tests[i]
+		if b := output[key{t.Suite, t.Name}]; b != nil && t.Status == StatusFail {
+			t.Output = b.String()
+		}
+	}
+
+	// A package that failed without a failing test failed to build, panicked
+	// outside a test, or failed TestMain.
+	for pkg := range failedPkgs {
+		hasFailedTest := false
+		for _, t := range report.Tests {
+			if t.Suite == pkg && t.Status == StatusFail {
+				hasFailedTest = true
+				break
+			}
+		}
+		if !hasFailedTest {
+			msg := "package " + pkg + " failed"
+			if b := output[key{pkg, ""}]; b != nil {
+				msg += ":\n" + strings.TrimRight(b.String(), "\n")
+			}
+			report.Errors = append(report.Errors, msg)
+		}
+	}
+	return report, nil
+}
+
+// junitTestCase, junitSuite and junitSuites cover the common subset of the
+// JUnit XML dialects emitted by test runners.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failures  []junitDetail `xml:"failure"`
+	Errors    []junitDetail `xml:"error"`
+	Skipped   *junitDetail  `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+}
+
+type junitDetail struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSuite struct {
+	Name   string          `xml:"name,attr"`
+	Cases  []junitTestCase `xml:"testcase"`
+	Suites []junitSuite    `xml:"testsuite"`
+}
+
+// ParseJUnit parses a JUnit XML report (a <testsuites> or <testsuite> root).
+func ParseJUnit(data []byte) (*Report, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing JUnit XML: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "testsuites", "testsuite":
+	default:
+		return nil, fmt.Errorf("parsing JUnit XML: unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	report := &Report{}
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			suite := c.ClassName
+			if suite == "" {
+				suite = s.Name
+			}
+			r := Result{Suite: suite, Name: c.Name, Status: StatusPass}
+			switch {
+			case len(c.Failures) > 0 || len(c.Errors) > 0:
+				r.Status = StatusFail
+				var out strings.Builder
+				for _, d := range append(c.Failures, c.Errors...) {
+					if d.Message != "" {
+						out.WriteString(d.Message + "\n")
+					}
+					if body := strings.TrimSpace(d.Body); body != "" {
+						out.WriteString(body + "\n")
+					}
+				}
+				if c.SystemOut != "" {
+					out.WriteString(strings.TrimSpace(c.SystemOut) + "\n")
+				}
+				r.Output = out.String()
+			case c.Skipped != nil:
+				r.Status = StatusSkip
+			}
+			report.Tests = append(report.Tests, r)
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root.junitSuite)
+	return report, nil
+}
+
+var (
+	tapPlan = regexp.MustCompile(`^1\.\.\d+`)
+	tapLine = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\w+).*)?$`)
+
+	// tapNumbered only matches numbered points, so detection doesn't take
+	// plain go test output ("ok  <pkg> 0.1s") for TAP.
+	tapNumbered = regexp.MustCompile(`^(not )?ok \d+\b`)
+)
+
+// ParseTAP parses Test Anything Protocol output. Only top-level test points
+// are reported; indented subtests and YAML diagnostics become the output of
+// the test they follow.
+func ParseTAP(data []byte) (*Report, error) {
+	report := &Report{}
+	last := -1
+	for _, raw := range strings.Split(string(data), "\n") {
+		if raw != strings.TrimLeft(raw, " \t") {
+			// Indented: diagnostics or a subtest of the previous point.
+			if last >= 0 && report.Tests[last].Status == StatusFail {
+				report.Tests[last].Output += strings.TrimSpace(raw) + "\n"
+			}
+			continue
+		}
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "Bail out!") {
+			report.Errors = append(report.Errors, line)
+			continue
+		}
+		m := tapLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := m[3]
+		if name == "" {
+			name = "test " + m[2]
+		}
+		r := Result{Name: name, Status: StatusPass}
+		switch strings.ToUpper(m[4]) {
+		case "SKIP":
+			r.Status = StatusSkip
+		case "TODO":
+			// TODO points are expected to fail and never count.
+			r.Status = StatusSkip
+		default:
+			if m[1] != "" {
+				r.Status = StatusFail
+			}
+		}
+		report.Tests = append(report.Tests, r)
+		last = len(report.Tests) - 1
+	}
+	return report, nil
+}
@@ -0,0 +1,161 @@
+// Package testresults parses per-test results out of test runner output
+// (go test -json, JUnit XML and TAP) and keeps a per-rig record of how each
+// test behaved across merge requests, so the Refinery can tell a flaky test
+// from a real failure and skip quarantined ones.
+package testresults
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Result formats.
+const (
+	FormatGoJSON = "go-json"
+	FormatJUnit  = "junit"
+	FormatTAP    = "tap"
+)
+
+// Status is the outcome of one test.
+type Status string
+
+// Test outcomes.
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Result is the outcome of one test.
+type Result struct {
+	Suite  string // Go package, JUnit classname, empty for TAP
+	Name   string // Test name (Go subtests keep their "Parent/sub" form)
+	Status Status
+	Output string // Output captured for the test (failures only for JUnit)
+}
+
+// ID identifies the test across runs: "suite.name", or just the name when
+// the format has no suite.
+func (r Result) ID() string {
+	if r.Suite == "" {
+		return r.Name
+	}
+	return r.Suite + "." + r.Name
+}
+
+// Report is the parsed outcome of one test run.
+type Report struct {
+	Format string
+	Tests  []Result
+
+	// Errors are failures not attributable to a test (e.g., a Go package
+	// that failed to build). A report with errors cannot be retried test by
+	// test.
+	Errors []string
+}
+
+// Failed returns the failing tests.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, t := range r.Tests {
+		if t.Status == StatusFail {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// Counts returns the number of passed, failed and skipped tests.
+func (r *Report) Counts() (passed, failed, skipped int) {
+	for _, t := range r.Tests {
+		switch t.Status {
+		case StatusPass:
+			passed++
+		case StatusFail:
+			failed++
+		case StatusSkip:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Parse parses data in the given format. An empty format detects it.
+func Parse(format string, data []byte) (*Report, error) {
+	if format == "" {
+		format = Detect(data)
+		if format == "" {
+			return nil, fmt.Errorf("unrecognized test output format")
+		}
+	}
+	var (
+		report *Report
+		err    error
+	)
+	switch format {
+	case FormatGoJSON:
+		report, err = ParseGoJSON(data)
+	case FormatJUnit:
+		report, err = ParseJUnit(data)
+	case FormatTAP:
+		report, err = ParseTAP(data)
+	default:
+		return nil, fmt.Errorf("unknown test result format %q (want %s, %s or %s)", format, FormatGoJSON, FormatJUnit, FormatTAP)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Format = format
+	return report, nil
+}
+
+// Detect guesses the format of test runner output. It returns an empty
+// string when the output is in none of the supported formats.
+func Detect(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<testsuite")) {
+		return FormatJUnit
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, `{"Time":`) || strings.HasPrefix(line, `{"Action":`):
+			return FormatGoJSON
+		case strings.HasPrefix(line, "TAP version"), tapPlan.MatchString(line), tapNumbered.MatchString(line):
+			return FormatTAP
+		}
+	}
+	return ""
+}
+
+// RerunPattern returns a go test -run pattern that selects the top-level
+// tests of the given results.
+func RerunPattern(tests []Result) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, t := range tests {
+		top, _, _ := strings.Cut(t.Name, "/")
+		if !seen[top] {
+			seen[top] = true
+			names = append(names, top)
+		}
+	}
+	sort.Strings(names)
+	return "^(" + strings.Join(names, "|") + ")$"
+}
+
+// Suites returns the distinct suites (Go packages) of the given results.
+func Suites(tests []Result) []string {
+	seen := make(map[string]bool)
+	var suites []string
+	for _, t := range tests {
+		if t.Suite != "" && !seen[t.Suite] {
+			seen[t.Suite] = true
+			suites = append(suites, t.Suite)
+		}
+	}
+	sort.Strings(suites)
+	return suites
+}
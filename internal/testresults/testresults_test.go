@@ -0,0 +1,226 @@
+package testresults
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const goJSONSample = `{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK","Elapsed":0}
+{"Action":"run","Package":"example.com/a","Test":"TestBad"}
+{"Action":"output","Package":"example.com/a","Test":"TestBad","Output":"    a_test.go:9: got 1, want 2\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad/sub","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad","Elapsed":0}
+{"Action":"skip","Package":"example.com/a","Test":"TestSkip","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.1}
+# example.com/b
+b.go:3:2: undefined: x
+{"Action":"output","Package":"example.com/b","Output":"FAIL\texample.com/b [build failed]\n"}
+{"Action":"fail","Package":"example.com/b","Elapsed":0}
+`
+
+func TestParseGoJSON(t *testing.T) {
+	report, err := Parse("", []byte(goJSONSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != FormatGoJSON {
+		t.Errorf("Format = %q, want go-json", report.Format)
+	}
+	passed, failed, skipped := report.Counts()
+	if passed != 1 || failed != 2 || skipped != 1 {
+		t.Errorf("counts = %d/%d/%d, want 1/2/1", passed, failed, skipped)
+	}
+	bad := report.Failed()[1]
+	if bad.ID() != "example.com/a.TestBad" || !strings.Contains(bad.Output, "want 2") {
+		t.Errorf("failed test = %+v", bad)
+	}
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "example.com/b") {
+		t.Errorf("Errors = %q, want the build failure of example.com/b", report.Errors)
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="auth">
+    <testcase classname="auth.Login" name="accepts valid"/>
+    <testcase classname="auth.Login" name="rejects expired">
+      <failure message="expected 401">stack trace here</failure>
+    </testcase>
+    <testsuite name="nested">
+      <testcase name="skipped one"><skipped/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+	report, err := Parse("", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != FormatJUnit {
+		t.Errorf("Format = %q, want junit", report.Format)
+	}
+	if len(report.Tests) != 3 {
+		t.Fatalf("got %d tests, want 3", len(report.Tests))
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].ID() != "auth.Login.rejects expired" {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if !strings.Contains(failed[0].Output, "expected 401") || !strings.Contains(failed[0].Output, "stack trace") {
+		t.Errorf("Output = %q", failed[0].Output)
+	}
+	if report.Tests[2].Suite != "nested" || report.Tests[2].Status != StatusSkip {
+		t.Errorf("nested test = %+v", report.Tests[2])
+	}
+
+	if _, err := ParseJUnit([]byte("<html></html>")); err == nil {
+		t.Error("expected an error for a non-JUnit root")
+	}
+}
+
+func TestParseTAP(t *testing.T) {
+	data := `TAP version 13
+1..5
+ok 1 - adds numbers
+not ok 2 - divides by zero
+  ---
+  message: panic
+  ...
+ok 3 - network # SKIP offline
+not ok 4 - later # TODO not done
+ok 5
+`
+	report, err := Parse("", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != FormatTAP {
+		t.Errorf("Format = %q, want tap", report.Format)
+	}
+	passed, failed, skipped := report.Counts()
+	if passed != 2 || failed != 1 || skipped != 2 {
+		t.Errorf("counts = %d/%d/%d, want 2/1/2", passed, failed, skipped)
+	}
+	bad := report.Failed()[0]
+	if bad.Name != "divides by zero" || !strings.Contains(bad.Output, "message: panic") {
+		t.Errorf("failed test = %+v", bad)
+	}
+	if report.Tests[4].Name != "test 5" {
+		t.Errorf("unnamed point = %q, want %q", report.Tests[4].Name, "test 5")
+	}
+
+	report, _ = ParseTAP([]byte("ok 1 - a\nBail out! database down\n"))
+	if len(report.Errors) != 1 {
+		t.Errorf("Errors = %q, want the bail out", report.Errors)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		`{"Time":"2024-01-01T00:00:00Z","Action":"start","Package":"x"}`: FormatGoJSON,
+		"<testsuite name=\"x\"></testsuite>":                             FormatJUnit,
+		"1..2\nok 1\nok 2":                                               FormatTAP,
+		// Plain go test output must not be taken for TAP.
+		"ok  \texample.com/a\t0.01s\nFAIL\texample.com/b\t0.02s": "",
+		"": "",
+	}
+	for in, want := range tests {
+		if got := Detect([]byte(in)); got != want {
+			t.Errorf("Detect(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRerunPattern(t *testing.T) {
+	got := RerunPattern([]Result{
+		{Suite: "a", Name: "TestB/sub"},
+		{Suite: "a", Name: "TestB"},
+		{Suite: "b", Name: "TestA"},
+	})
+	if got != "^(TestA|TestB)$" {
+		t.Errorf("RerunPattern = %q", got)
+	}
+}
+
+func TestHistory_RecordFlips(t *testing.T) {
+	rig := t.TempDir()
+	h, err := LoadHistory(rig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	// Failed then passed within one run: flaky.
+	h.Record("tree1", map[string][]Status{
+		"a.TestFlaky": {StatusFail, StatusPass},
+		"a.TestOK":    {StatusPass},
+	}, now)
+	// A different status than the last run on the same tree: flaky.
+	h.Record("tree2", map[string][]Status{"a.TestOK": {StatusPass}, "a.TestTree": {StatusPass}}, now)
+	h.Record("tree2", map[string][]Status{"a.TestTree": {StatusFail}}, now)
+	// A status change on a new tree is a regression, not a flip.
+	h.Record("tree3", map[string][]Status{"a.TestOK": {StatusFail}}, now)
+
+	if err := h.Save(rig); err != nil {
+		t.Fatal(err)
+	}
+	h, err = LoadHistory(rig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, f := range h.Flaky() {
+		ids = append(ids, f.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a.TestFlaky,a.TestTree" {
+		t.Errorf("flaky = %s, want a.TestFlaky,a.TestTree", got)
+	}
+	if r := h.Tests["a.TestOK"]; r.Runs != 3 || r.Failures != 1 || r.Flips != 0 {
+		t.Errorf("TestOK record = %+v", r)
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	rig := t.TempDir()
+	q, err := LoadQuarantine(rig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Tests["example.com/a.TestExact"] = QuarantineEntry{Reason: "races"}
+	q.Tests["TestAnywhere"] = QuarantineEntry{}
+	if err := q.Save(rig); err != nil {
+		t.Fatal(err)
+	}
+	q, err = LoadQuarantine(rig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		r    Result
+		want bool
+	}{
+		{Result{Suite: "example.com/a", Name: "TestExact"}, true},
+		{Result{Suite: "example.com/b", Name: "TestExact"}, false},
+		{Result{Suite: "example.com/b", Name: "TestAnywhere"}, true},
+		{Result{Name: "TestAnywhere"}, true},
+	}
+	for _, c := range cases {
+		if got := q.Has(c.r); got != c.want {
+			t.Errorf("Has(%s) = %v, want %v", c.r.ID(), got, c.want)
+		}
+		if got := q.HasID(c.r.ID()); got != c.want {
+			t.Errorf("HasID(%s) = %v, want %v", c.r.ID(), got, c.want)
+		}
+	}
+	if q.Tests["example.com/a.TestExact"].Reason != "races" {
+		t.Error("reason not persisted")
+	}
+	var nilQ *Quarantine
+	if nilQ.Has(Result{Name: "TestAnywhere"}) {
+		t.Error("nil quarantine should match nothing")
+	}
+}
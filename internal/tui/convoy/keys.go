@@ -11,6 +11,7 @@ type KeyMap struct {
 	Top      key.Binding
 	Bottom   key.Binding
 	Toggle   key.Binding // expand/collapse
+	Graph    key.Binding // list/dependency graph view
 	Help     key.Binding
 	Quit     key.Binding
 }
@@ -46,6 +47,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("enter", " "),
 			key.WithHelp("enter/space", "expand/collapse"),
 		),
+		Graph: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "dependency graph"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -66,7 +71,7 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown},
-		{k.Top, k.Bottom, k.Toggle},
+		{k.Top, k.Bottom, k.Toggle, k.Graph},
 		{k.Help, k.Quit},
 	}
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	convoypkg "github.com/steveyegge/gastown/internal/convoy"
 )
 
 // convoyIDPattern validates convoy IDs.
@@ -32,12 +33,20 @@ type IssueItem struct {
 
 // ConvoyItem represents a convoy with its tracked issues.
 type ConvoyItem struct {
-	ID       string
-	Title    string
-	Status   string
-	Issues   []IssueItem
-	Progress string // e.g., "2/5"
-	Expanded bool
+	ID          string
+	Title       string
+	Status      string
+	Issues      []IssueItem
+	Progress    string // e.g., "2/5"
+	Expanded    bool
+	After       []string // Convoys this one waits on (blocks relations)
+	WaitingOn   []string // Those not landed yet
+	InFlight    int      // Tracked issues dispatched and not closed
+	MaxInFlight int      // 0 = unlimited
+	Depth       int      // Depth in the convoy dependency graph
+
+	index      int // Position in the list view
+	graphIndex int // Position in the graph view
 }
 
 // Model is the bubbletea model for the convoy TUI.
@@ -48,14 +57,15 @@ type Model struct {
 	err       error
 
 	// UI state
-	keys     KeyMap
-	help     help.Model
-	showHelp bool
-	width    int
-	height   int
+	keys      KeyMap
+	help      help.Model
+	showHelp  bool
+	graphView bool // Order convoys by dependency depth, indented
+	width     int
+	height    int
 
 	// mu protects all fields read by View() from concurrent access:
-	// convoys, cursor, err, showHelp, help, width, height.
+	// convoys, cursor, err, showHelp, graphView, help, width, height.
 	// Write lock is held during Update mutations; read lock during View/render.
 	mu sync.RWMutex
 }
@@ -104,30 +114,117 @@ func loadConvoys(townBeads string) ([]ConvoyItem, error) {
 	}
 
 	var rawConvoys []struct {
-		ID     string `json:"id"`
-		Title  string `json:"title"`
-		Status string `json:"status"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Description string `json:"description"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &rawConvoys); err != nil {
 		return nil, fmt.Errorf("parsing convoy list: %w", err)
 	}
 
 	convoys := make([]ConvoyItem, 0, len(rawConvoys))
-	for _, rc := range rawConvoys {
-		issues, completed, total := loadTrackedIssues(townBeads, rc.ID)
+	blockedBy := make(map[string][]string, len(rawConvoys))
+	for i, rc := range rawConvoys {
+		issues, completed, total, inFlight := loadTrackedIssues(townBeads, rc.ID)
+		after, waitingOn := loadBlockers(townBeads, rc.ID)
+		blockedBy[rc.ID] = after
 		convoys = append(convoys, ConvoyItem{
-			ID:       rc.ID,
-			Title:    rc.Title,
-			Status:   rc.Status,
-			Issues:   issues,
-			Progress: fmt.Sprintf("%d/%d", completed, total),
-			Expanded: false,
+			ID:          rc.ID,
+			Title:       rc.Title,
+			Status:      rc.Status,
+			Issues:      issues,
+			Progress:    fmt.Sprintf("%d/%d", completed, total),
+			Expanded:    false,
+			After:       after,
+			WaitingOn:   waitingOn,
+			InFlight:    inFlight,
+			MaxInFlight: convoypkg.ParseMaxInFlight(rc.Description),
+			index:       i,
 		})
 	}
 
+	// A cycle leaves every convoy at depth 0; the graph view is then flat.
+	if depths, err := convoypkg.Depths(blockedBy); err == nil {
+		for i := range convoys {
+			convoys[i].Depth = depths[convoys[i].ID]
+		}
+	}
+	orderGraph(convoys)
+
 	return convoys, nil
 }
 
+// orderGraph sets each convoy's graph view position: depth-first from the
+// convoys that wait on nothing, each convoy right under the first convoy one
+// level up that it waits on.
+func orderGraph(convoys []ConvoyItem) {
+	children := make(map[string][]int)
+	for i, c := range convoys {
+		for _, b := range c.After {
+			children[b] = append(children[b], i)
+		}
+	}
+
+	next := 0
+	placed := make([]bool, len(convoys))
+	var place func(i int)
+	place = func(i int) {
+		placed[i] = true
+		convoys[i].graphIndex = next
+		next++
+		for _, k := range children[convoys[i].ID] {
+			if !placed[k] && convoys[k].Depth == convoys[i].Depth+1 {
+				place(k)
+			}
+		}
+	}
+	for i := range convoys {
+		if convoys[i].Depth == 0 {
+			place(i)
+		}
+	}
+	// Anything left (blockers missing from the list) goes at the end.
+	for i := range convoys {
+		if !placed[i] {
+			place(i)
+		}
+	}
+}
+
+// loadBlockers loads the convoys a convoy waits on, and which of them have
+// not landed yet.
+func loadBlockers(townBeads, convoyID string) (after, waitingOn []string) {
+	if !convoyIDPattern.MatchString(convoyID) {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), subprocessTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "bd", "dep", "list", convoyID, "--direction=down", "--type=blocks", "--json")
+	cmd.Dir = townBeads
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return nil, nil
+	}
+
+	var blockers []convoypkg.Blocker
+	if err := json.Unmarshal(stdout.Bytes(), &blockers); err != nil {
+		return nil, nil
+	}
+	for _, b := range blockers {
+		id := extractIssueID(b.ID)
+		after = append(after, id)
+		if !b.Landed() {
+			waitingOn = append(waitingOn, id)
+		}
+	}
+	return after, waitingOn
+}
+
 // extractIssueID strips the external:prefix:id wrapper from bead IDs.
 // bd dep add wraps cross-rig IDs as "external:prefix:id" for routing,
 // but consumers need the raw bead ID for display and lookups.
@@ -141,11 +238,12 @@ func extractIssueID(id string) string {
 	return id
 }
 
-// loadTrackedIssues loads issues tracked by a convoy.
-func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
+// loadTrackedIssues loads issues tracked by a convoy. Returns the issues
+// and the completed, total and in-flight counts.
+func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int, int) {
 	// Validate convoy ID for safety
 	if !convoyIDPattern.MatchString(convoyID) {
-		return nil, 0, 0
+		return nil, 0, 0, 0
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), subprocessTimeout)
@@ -158,16 +256,12 @@ func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
 	cmd.Stdout = &stdout
 
 	if err := cmd.Run(); err != nil {
-		return nil, 0, 0
+		return nil, 0, 0, 0
 	}
 
-	var tracked []struct {
-		ID     string `json:"id"`
-		Title  string `json:"title"`
-		Status string `json:"status"`
-	}
+	var tracked []trackedRecord
 	if err := json.Unmarshal(stdout.Bytes(), &tracked); err != nil {
-		return nil, 0, 0
+		return nil, 0, 0, 0
 	}
 
 	// Extract raw issue IDs and refresh status via cross-rig lookup.
@@ -179,11 +273,11 @@ func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
 	freshStatus := refreshIssueStatus(ctx, tracked)
 
 	issues := make([]IssueItem, 0, len(tracked))
-	completed := 0
+	completed, inFlight := 0, 0
 	for _, t := range tracked {
-		status := t.Status
+		status, assignee := t.Status, t.Assignee
 		if fresh, ok := freshStatus[t.ID]; ok {
-			status = fresh
+			status, assignee = fresh.Status, fresh.Assignee
 		}
 		issues = append(issues, IssueItem{
 			ID:     t.ID,
@@ -193,6 +287,9 @@ func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
 		if status == "closed" {
 			completed++
 		}
+		if convoypkg.IsInFlight(status, assignee) {
+			inFlight++
+		}
 	}
 
 	// Sort by status (open first, then closed)
@@ -203,16 +300,20 @@ func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
 		return issues[i].Status != "closed" // open comes first
 	})
 
-	return issues, completed, len(issues)
+	return issues, completed, len(issues), inFlight
+}
+
+// trackedRecord is a tracked issue as returned by bd dep list and bd show.
+type trackedRecord struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee"`
 }
 
 // refreshIssueStatus does a batch bd show to get current status for tracked issues.
-// Returns a map from issue ID to current status.
-func refreshIssueStatus(ctx context.Context, tracked []struct {
-	ID     string `json:"id"`
-	Title  string `json:"title"`
-	Status string `json:"status"`
-}) map[string]string {
+// Returns a map from issue ID to its current record.
+func refreshIssueStatus(ctx context.Context, tracked []trackedRecord) map[string]trackedRecord {
 	if len(tracked) == 0 {
 		return nil
 	}
@@ -231,17 +332,14 @@ func refreshIssueStatus(ctx context.Context, tracked []struct {
 		return nil
 	}
 
-	var issues []struct {
-		ID     string `json:"id"`
-		Status string `json:"status"`
-	}
+	var issues []trackedRecord
 	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
 		return nil
 	}
 
-	result := make(map[string]string, len(issues))
+	result := make(map[string]trackedRecord, len(issues))
 	for _, issue := range issues {
-		result[issue.ID] = issue.Status
+		result[issue.ID] = issue
 	}
 	return result
 }
@@ -261,6 +359,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mu.Lock()
 		m.err = msg.err
 		m.convoys = msg.convoys
+		m.arrangeLocked()
 		m.mu.Unlock()
 		return m, nil
 
@@ -310,6 +409,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.mu.Unlock()
 			return m, nil
 
+		case key.Matches(msg, m.keys.Graph):
+			m.mu.Lock()
+			m.graphView = !m.graphView
+			m.arrangeLocked()
+			m.cursor = 0
+			m.mu.Unlock()
+			return m, nil
+
 		// Number keys for direct convoy access
 		case msg.String() >= "1" && msg.String() <= "9":
 			n := int(msg.String()[0] - '0')
@@ -373,6 +480,18 @@ func (m *Model) toggleExpandLocked() {
 	}
 }
 
+// arrangeLocked orders convoys for the current view: list order, or the
+// graph view's, where convoys follow the ones they wait on.
+// Caller must hold m.mu write lock.
+func (m *Model) arrangeLocked() {
+	sort.SliceStable(m.convoys, func(i, j int) bool {
+		if m.graphView {
+			return m.convoys[i].graphIndex < m.convoys[j].graphIndex
+		}
+		return m.convoys[i].index < m.convoys[j].index
+	})
+}
+
 // jumpToConvoyLocked moves the cursor to a specific convoy by index.
 // Caller must hold m.mu write lock.
 func (m *Model) jumpToConvoyLocked(convoyIdx int) {
@@ -0,0 +1,43 @@
+package convoy
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGraphViewOrdersConvoysUnderBlockers(t *testing.T) {
+	// List order: ui, schema, api. ui waits on api, api on schema.
+	convoys := []ConvoyItem{
+		{ID: "hq-cv-ui", Title: "UI", Status: "open", After: []string{"hq-cv-api"}, WaitingOn: []string{"hq-cv-api"}, Depth: 2, index: 0},
+		{ID: "hq-cv-schema", Title: "Schema", Status: "open", Depth: 0, MaxInFlight: 2, InFlight: 1, index: 1},
+		{ID: "hq-cv-api", Title: "API", Status: "open", After: []string{"hq-cv-schema"}, WaitingOn: []string{"hq-cv-schema"}, Depth: 1, index: 2},
+	}
+	orderGraph(convoys)
+
+	m := New("/tmp/fake-beads")
+	m.Update(fetchConvoysMsg{convoys: convoys})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+
+	var ids []string
+	for _, c := range m.convoys {
+		ids = append(ids, c.ID)
+	}
+	if got := strings.Join(ids, " "); got != "hq-cv-schema hq-cv-api hq-cv-ui" {
+		t.Errorf("graph order = %s", got)
+	}
+
+	view := m.View()
+	for _, want := range []string{"dependency graph", "1/2 in flight", "└▶ ▶ 2. ⏸ hq-cv-api", "waiting on hq-cv-schema"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Toggling back restores list order.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if m.convoys[0].ID != "hq-cv-ui" {
+		t.Errorf("list order not restored: first = %s", m.convoys[0].ID)
+	}
+}
@@ -30,6 +30,9 @@ var (
 	progressStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("8")) // gray
 
+	waitingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("13")) // magenta
+
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("8"))
 
@@ -43,7 +46,11 @@ func (m *Model) renderView() string {
 	var b strings.Builder
 
 	// Title
-	b.WriteString(titleStyle.Render("Convoys"))
+	if m.graphView {
+		b.WriteString(titleStyle.Render("Convoys (dependency graph)"))
+	} else {
+		b.WriteString(titleStyle.Render("Convoys"))
+	}
 	b.WriteString("\n\n")
 
 	// Error message
@@ -70,7 +77,15 @@ func (m *Model) renderView() string {
 		}
 
 		statusIcon := statusToIcon(c.Status)
-		line := fmt.Sprintf("%s %d. %s %s: %s %s",
+		if len(c.WaitingOn) > 0 && c.Status != "closed" {
+			statusIcon = "⏸"
+		}
+		indent := ""
+		if m.graphView && c.Depth > 0 {
+			indent = strings.Repeat("    ", c.Depth-1) + "└▶ "
+		}
+		line := fmt.Sprintf("%s%s %d. %s %s: %s %s",
+			indent,
 			expandIcon,
 			ci+1,
 			statusIcon,
@@ -78,6 +93,12 @@ func (m *Model) renderView() string {
 			c.Title,
 			progressStyle.Render(fmt.Sprintf("(%s)", c.Progress)),
 		)
+		if c.MaxInFlight > 0 && c.Status != "closed" {
+			line += progressStyle.Render(fmt.Sprintf(" %d/%d in flight", c.InFlight, c.MaxInFlight))
+		}
+		if len(c.WaitingOn) > 0 && c.Status != "closed" {
+			line += waitingStyle.Render(" waiting on " + strings.Join(c.WaitingOn, ", "))
+		}
 
 		if isSelected {
 			b.WriteString(selectedStyle.Render(line))
@@ -128,7 +149,7 @@ func (m *Model) renderView() string {
 	if m.showHelp {
 		b.WriteString(m.help.View(m.keys))
 	} else {
-		b.WriteString(helpStyle.Render("j/k:navigate  enter:expand  1-9:jump  q:quit  ?:help"))
+		b.WriteString(helpStyle.Render("j/k:navigate  enter:expand  1-9:jump  d:graph  q:quit  ?:help"))
 	}
 
 	return b.String()
@@ -0,0 +1,98 @@
+package mq
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the key bindings for the merge queue TUI.
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Top     key.Binding
+	Bottom  key.Binding
+	NextRig key.Binding
+	PrevRig key.Binding
+	Preview key.Binding // diff/log of the selected MR
+	Reject  key.Binding
+	Bump    key.Binding // boost the score (gt mq bump)
+	Retry   key.Binding // release the claim
+	Session key.Binding // open the owning polecat's session
+	Refresh key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g", "top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G", "bottom"),
+		),
+		NextRig: key.NewBinding(
+			key.WithKeys("tab", "right", "l"),
+			key.WithHelp("tab", "next rig"),
+		),
+		PrevRig: key.NewBinding(
+			key.WithKeys("shift+tab", "left", "h"),
+			key.WithHelp("shift+tab", "previous rig"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "diff/log preview"),
+		),
+		Reject: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "reject"),
+		),
+		Bump: key.NewBinding(
+			key.WithKeys("+", "b"),
+			key.WithHelp("+/b", "bump score"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry (release claim)"),
+		),
+		Session: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "open polecat session"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("ctrl+r", "R"),
+			key.WithHelp("R", "refresh"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to show in the help view.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Preview, k.Reject, k.Bump, k.Retry, k.Session, k.Quit, k.Help}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Top, k.Bottom},
+		{k.NextRig, k.PrevRig, k.Preview, k.Refresh},
+		{k.Reject, k.Bump, k.Retry, k.Session},
+		{k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,523 @@
+// Package mq provides the interactive merge queue view (gt mq -i).
+package mq
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// refreshInterval is how often the current rig's queue is reloaded.
+const refreshInterval = 5 * time.Second
+
+// maxReasonLen caps the length of a reject reason.
+const maxReasonLen = 200
+
+// Group is the section of the view an MR is listed in.
+type Group int
+
+const (
+	GroupReady      Group = iota // Unclaimed and unblocked, in processing order
+	GroupBlocked                 // Waiting on an open bead
+	GroupInProgress              // Claimed by the Refinery
+	GroupAnomalous               // Stale claim or missing branch
+)
+
+// String returns the section heading for a group.
+func (g Group) String() string {
+	switch g {
+	case GroupReady:
+		return "Ready"
+	case GroupBlocked:
+		return "Blocked"
+	case GroupInProgress:
+		return "In progress"
+	case GroupAnomalous:
+		return "Anomalies"
+	default:
+		return "?"
+	}
+}
+
+// Queue is one rig's open merge requests and the anomalies found in them.
+type Queue struct {
+	MRs       []*refinery.MRInfo
+	Anomalies []*refinery.MRAnomaly
+	// Scoring is the rig's merge_queue.scoring (zero value: defaults).
+	Scoring refinery.ScoreConfig
+}
+
+// Source loads rigs' merge queues and acts on their MRs. gt mq -i backs
+// it with each rig's Refinery engineer.
+type Source interface {
+	Load(rig string) (*Queue, error)
+	Preview(rig string, mr *refinery.MRInfo) (string, error)
+	Reject(rig, id, reason string) error
+	// Bump adds refinery.DefaultBoost to the MR's score (gt mq bump).
+	Bump(rig, id string) error
+	Retry(rig, id string) error
+	// SessionCmd returns the command that attaches to the polecat that
+	// owns the MR.
+	SessionCmd(rig string, mr *refinery.MRInfo) *exec.Cmd
+}
+
+// Item is one MR in the view.
+type Item struct {
+	MR        *refinery.MRInfo
+	Group     Group
+	Anomalies []*refinery.MRAnomaly
+	Score     refinery.ScoreBreakdown
+}
+
+// Model is the bubbletea model for the merge queue TUI.
+type Model struct {
+	source Source
+	rigs   []string
+	rig    int // Index of the rig shown
+
+	items   []Item
+	cursor  int
+	loaded  bool
+	err     error
+	status  string // Result of the last action
+	now     time.Time
+	preview string // Diff/log of previewID
+	// previewID is the MR whose preview is shown; empty when the preview
+	// is closed.
+	previewID string
+
+	// Reject prompt
+	rejecting bool
+	reason    []rune
+
+	// UI state
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+
+	// mu protects all fields read by View() from concurrent access.
+	// Write lock is held during Update mutations; read lock during View/render.
+	mu sync.RWMutex
+}
+
+// New creates a merge queue TUI over the given rigs, starting with the
+// first.
+func New(source Source, rigs []string) *Model {
+	return &Model{
+		source: source,
+		rigs:   rigs,
+		keys:   DefaultKeyMap(),
+		help:   help.New(),
+		now:    time.Now(),
+	}
+}
+
+// Init initializes the model.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.load(m.currentRig()), tick())
+}
+
+// loadMsg is the result of loading a rig's queue.
+type loadMsg struct {
+	rig   string
+	queue *Queue
+	err   error
+	at    time.Time
+}
+
+// tickMsg triggers a periodic reload.
+type tickMsg time.Time
+
+// previewMsg is the result of loading an MR's diff/log.
+type previewMsg struct {
+	id   string
+	text string
+	err  error
+}
+
+// actionMsg is the result of acting on an MR.
+type actionMsg struct {
+	done string // Past tense, e.g. "rejected gt-mr-1"
+	err  error
+}
+
+// sessionMsg is sent when the attached session returns.
+type sessionMsg struct{ err error }
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *Model) currentRig() string {
+	if len(m.rigs) == 0 {
+		return ""
+	}
+	return m.rigs[m.rig]
+}
+
+// load loads a rig's queue in the background.
+func (m *Model) load(rig string) tea.Cmd {
+	if rig == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		q, err := m.source.Load(rig)
+		return loadMsg{rig: rig, queue: q, err: err, at: time.Now()}
+	}
+}
+
+// act runs an action on an MR in the background and reports it.
+func act(done string, fn func() error) tea.Cmd {
+	return func() tea.Msg {
+		return actionMsg{done: done, err: fn()}
+	}
+}
+
+// BuildItems groups and scores a queue's MRs at now. Within each group MRs
+// are in queue order (see refinery.OrderQueue), so the Ready group lists
+// MRs as the Refinery will take them.
+func BuildItems(q *Queue, now time.Time) []Item {
+	if q == nil {
+		return nil
+	}
+	anomalies := make(map[string][]*refinery.MRAnomaly)
+	for _, a := range q.Anomalies {
+		anomalies[a.ID] = append(anomalies[a.ID], a)
+	}
+	scoring := q.Scoring
+	if scoring == (refinery.ScoreConfig{}) {
+		scoring = refinery.DefaultScoreConfig()
+	}
+
+	// Only ready MRs compete under the fairness caps
+	var ready []*refinery.MRInfo
+	for _, mr := range q.MRs {
+		if len(anomalies[mr.ID]) == 0 && mr.Assignee == "" && mr.BlockedBy == "" {
+			ready = append(ready, mr)
+		}
+	}
+	ready, scores := refinery.OrderMRs(ready, scoring, now)
+	rank := make(map[string]int, len(ready))
+	for i, mr := range ready {
+		rank[mr.ID] = i
+	}
+
+	items := make([]Item, 0, len(q.MRs))
+	for _, mr := range q.MRs {
+		score, ok := scores[mr.ID]
+		if !ok {
+			score = refinery.ExplainScore(mr.QueueEntry(now).Input, scoring)
+		}
+		item := Item{MR: mr, Anomalies: anomalies[mr.ID], Score: score}
+		switch {
+		case len(item.Anomalies) > 0:
+			item.Group = GroupAnomalous
+		case mr.Assignee != "":
+			item.Group = GroupInProgress
+		case mr.BlockedBy != "":
+			item.Group = GroupBlocked
+		default:
+			item.Group = GroupReady
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Group != items[j].Group {
+			return items[i].Group < items[j].Group
+		}
+		if items[i].Group == GroupReady {
+			return rank[items[i].MR.ID] < rank[items[j].MR.ID]
+		}
+		return items[i].Score.Total > items[j].Score.Total
+	})
+	return items
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.mu.Lock()
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		m.mu.Unlock()
+		return m, nil
+
+	case loadMsg:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if msg.rig != m.currentRig() {
+			return m, nil // Stale load for a rig no longer shown
+		}
+		m.setQueueLocked(msg.queue, msg.err, msg.at)
+		return m, nil
+
+	case tickMsg:
+		m.mu.RLock()
+		rig := m.currentRig()
+		m.mu.RUnlock()
+		return m, tea.Batch(m.load(rig), tick())
+
+	case previewMsg:
+		m.mu.Lock()
+		if msg.id == m.previewID {
+			if msg.err != nil {
+				m.preview = fmt.Sprintf("preview unavailable: %v", msg.err)
+			} else {
+				m.preview = msg.text
+			}
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case actionMsg:
+		m.mu.Lock()
+		if msg.err != nil {
+			m.status = "error: " + msg.err.Error()
+		} else {
+			m.status = msg.done
+		}
+		rig := m.currentRig()
+		m.mu.Unlock()
+		return m, m.load(rig)
+
+	case sessionMsg:
+		m.mu.Lock()
+		if msg.err != nil {
+			m.status = "session: " + msg.err.Error()
+		}
+		rig := m.currentRig()
+		m.mu.Unlock()
+		return m, m.load(rig)
+
+	case tea.KeyMsg:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.rejecting {
+			return m, m.updateRejectLocked(msg)
+		}
+		return m, m.handleKeyLocked(msg)
+	}
+
+	return m, nil
+}
+
+// setQueueLocked replaces the shown queue, keeping the cursor on the same
+// MR when it is still listed.
+// Caller must hold m.mu write lock.
+func (m *Model) setQueueLocked(q *Queue, err error, at time.Time) {
+	selected := m.selectedIDLocked()
+	m.err = err
+	m.loaded = true
+	m.now = at
+	if err != nil {
+		return
+	}
+	m.items = BuildItems(q, at)
+	m.cursor = 0
+	for i, item := range m.items {
+		if item.MR.ID == selected {
+			m.cursor = i
+		}
+	}
+	if m.cursor >= len(m.items) && len(m.items) > 0 {
+		m.cursor = len(m.items) - 1
+	}
+}
+
+// selectedLocked returns the MR under the cursor, or nil.
+// Caller must hold m.mu (read or write).
+func (m *Model) selectedLocked() *Item {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return nil
+	}
+	return &m.items[m.cursor]
+}
+
+func (m *Model) selectedIDLocked() string {
+	if item := m.selectedLocked(); item != nil {
+		return item.MR.ID
+	}
+	return ""
+}
+
+// handleKeyLocked handles a key outside the reject prompt.
+// Caller must hold m.mu write lock.
+func (m *Model) handleKeyLocked(msg tea.KeyMsg) tea.Cmd {
+	rig := m.currentRig()
+	item := m.selectedLocked()
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		if m.previewID != "" && msg.String() == "esc" {
+			m.previewID, m.preview = "", ""
+			return nil
+		}
+		return tea.Quit
+
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = !m.showHelp
+
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m.followPreviewLocked()
+
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		return m.followPreviewLocked()
+
+	case key.Matches(msg, m.keys.Top):
+		m.cursor = 0
+		return m.followPreviewLocked()
+
+	case key.Matches(msg, m.keys.Bottom):
+		if len(m.items) > 0 {
+			m.cursor = len(m.items) - 1
+		}
+		return m.followPreviewLocked()
+
+	case key.Matches(msg, m.keys.NextRig), key.Matches(msg, m.keys.PrevRig):
+		if len(m.rigs) < 2 {
+			return nil
+		}
+		step := 1
+		if key.Matches(msg, m.keys.PrevRig) {
+			step = len(m.rigs) - 1
+		}
+		m.rig = (m.rig + step) % len(m.rigs)
+		m.items, m.cursor, m.loaded, m.err, m.status = nil, 0, false, nil, ""
+		m.previewID, m.preview = "", ""
+		return m.load(m.currentRig())
+
+	case key.Matches(msg, m.keys.Refresh):
+		return m.load(rig)
+
+	case key.Matches(msg, m.keys.Preview):
+		if item == nil {
+			return nil
+		}
+		if m.previewID == item.MR.ID {
+			m.previewID, m.preview = "", ""
+			return nil
+		}
+		return m.openPreviewLocked(item)
+
+	case key.Matches(msg, m.keys.Reject):
+		if item == nil {
+			return nil
+		}
+		m.rejecting = true
+		m.reason = m.reason[:0]
+		return nil
+
+	case key.Matches(msg, m.keys.Bump):
+		if item == nil {
+			return nil
+		}
+		id := item.MR.ID
+		return act(fmt.Sprintf("bumped %s by +%d", id, refinery.DefaultBoost), func() error {
+			return m.source.Bump(rig, id)
+		})
+
+	case key.Matches(msg, m.keys.Retry):
+		if item == nil {
+			return nil
+		}
+		if item.MR.Assignee == "" {
+			m.status = item.MR.ID + " is not claimed; the Refinery will pick it up"
+			return nil
+		}
+		id := item.MR.ID
+		return act("released "+id+" for retry", func() error {
+			return m.source.Retry(rig, id)
+		})
+
+	case key.Matches(msg, m.keys.Session):
+		if item == nil {
+			return nil
+		}
+		if item.MR.Worker == "" {
+			m.status = item.MR.ID + " has no owning polecat"
+			return nil
+		}
+		return tea.ExecProcess(m.source.SessionCmd(rig, item.MR), func(err error) tea.Msg {
+			return sessionMsg{err: err}
+		})
+	}
+	return nil
+}
+
+// updateRejectLocked handles a key in the reject prompt.
+// Caller must hold m.mu write lock.
+func (m *Model) updateRejectLocked(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.rejecting = false
+		return nil
+	case tea.KeyEnter:
+		reason := strings.TrimSpace(string(m.reason))
+		item := m.selectedLocked()
+		if reason == "" || item == nil {
+			return nil
+		}
+		m.rejecting = false
+		rig, id := m.currentRig(), item.MR.ID
+		return act("rejected "+id, func() error {
+			return m.source.Reject(rig, id, reason)
+		})
+	case tea.KeyBackspace:
+		if len(m.reason) > 0 {
+			m.reason = m.reason[:len(m.reason)-1]
+		}
+	case tea.KeySpace, tea.KeyRunes:
+		if len(m.reason)+len(msg.Runes) <= maxReasonLen {
+			m.reason = append(m.reason, msg.Runes...)
+		}
+	}
+	return nil
+}
+
+// openPreviewLocked shows the preview for an MR, loading it in the
+// background.
+// Caller must hold m.mu write lock.
+func (m *Model) openPreviewLocked(item *Item) tea.Cmd {
+	rig, mr := m.currentRig(), item.MR
+	m.previewID, m.preview = mr.ID, "loading..."
+	return func() tea.Msg {
+		text, err := m.source.Preview(rig, mr)
+		return previewMsg{id: mr.ID, text: text, err: err}
+	}
+}
+
+// followPreviewLocked moves an open preview to the MR under the cursor.
+// Caller must hold m.mu write lock.
+func (m *Model) followPreviewLocked() tea.Cmd {
+	item := m.selectedLocked()
+	if m.previewID == "" || item == nil || item.MR.ID == m.previewID {
+		return nil
+	}
+	return m.openPreviewLocked(item)
+}
+
+// View renders the model.
+// Acquires read lock to safely access all View-visible fields.
+func (m *Model) View() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.renderView()
+}
@@ -0,0 +1,174 @@
+package mq
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// fakeSource records actions instead of touching beads or git.
+type fakeSource struct {
+	queue   *Queue
+	actions []string
+}
+
+func (f *fakeSource) Load(rig string) (*Queue, error) { return f.queue, nil }
+
+func (f *fakeSource) Preview(rig string, mr *refinery.MRInfo) (string, error) {
+	return "abc123 fix the thing\n\n 1 file changed", nil
+}
+
+func (f *fakeSource) Reject(rig, id, reason string) error {
+	f.actions = append(f.actions, "reject "+rig+" "+id+" "+reason)
+	return nil
+}
+
+func (f *fakeSource) Bump(rig, id string) error {
+	f.actions = append(f.actions, "bump "+rig+" "+id)
+	return nil
+}
+
+func (f *fakeSource) Retry(rig, id string) error {
+	f.actions = append(f.actions, "retry "+rig+" "+id)
+	return nil
+}
+
+func (f *fakeSource) SessionCmd(rig string, mr *refinery.MRInfo) *exec.Cmd {
+	return exec.Command("true")
+}
+
+func testQueue(now time.Time) *Queue {
+	return &Queue{
+		MRs: []*refinery.MRInfo{
+			{ID: "gt-mr-low", Branch: "polecat/nux/gt-1", Worker: "nux", Priority: 3, CreatedAt: now.Add(-time.Hour)},
+			{ID: "gt-mr-blocked", Branch: "polecat/ace/gt-2", Worker: "ace", Priority: 0, BlockedBy: "gt-9", CreatedAt: now},
+			{ID: "gt-mr-high", Branch: "polecat/toast/gt-3", Worker: "toast", Priority: 1, CreatedAt: now},
+			{ID: "gt-mr-claimed", Branch: "polecat/max/gt-4", Worker: "max", Priority: 2, Assignee: "gastown/refinery", CreatedAt: now},
+			{ID: "gt-mr-orphan", Branch: "polecat/gone/gt-5", Priority: 2, CreatedAt: now},
+		},
+		Anomalies: []*refinery.MRAnomaly{
+			{ID: "gt-mr-orphan", Branch: "polecat/gone/gt-5", Type: "orphaned-branch", Severity: "critical", Detail: "MR branch is missing"},
+		},
+	}
+}
+
+// run feeds a command's messages back into the model, as the bubbletea
+// runtime would.
+func run(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			run(m, c)
+		}
+	default:
+		_, next := m.Update(msg)
+		run(m, next)
+	}
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func press(m *Model, keys ...string) {
+	for _, k := range keys {
+		_, cmd := m.Update(keyMsg(k))
+		run(m, cmd)
+	}
+}
+
+func TestBuildItemsGroupsAndOrders(t *testing.T) {
+	now := time.Now()
+	items := BuildItems(testQueue(now), now)
+
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Group.String()+":"+item.MR.ID)
+	}
+	want := "Ready:gt-mr-high Ready:gt-mr-low Blocked:gt-mr-blocked In progress:gt-mr-claimed Anomalies:gt-mr-orphan"
+	if strings.Join(got, " ") != want {
+		t.Errorf("items = %s\nwant    %s", strings.Join(got, " "), want)
+	}
+	if items[0].Score.Priority != 300 {
+		t.Errorf("P1 priority term = %v, want 300", items[0].Score.Priority)
+	}
+}
+
+func TestBuildItemsHonorsOverrides(t *testing.T) {
+	now := time.Now()
+	q := testQueue(now)
+	q.MRs[0].Pinned = true   // gt-mr-low jumps the higher-priority MR
+	q.MRs[2].Hold = "freeze" // gt-mr-high stays ready but goes last
+	q.MRs = append(q.MRs, &refinery.MRInfo{ID: "gt-mr-mid", Worker: "ace", Priority: 2, CreatedAt: now})
+
+	var got []string
+	for _, item := range BuildItems(q, now) {
+		if item.Group == GroupReady {
+			got = append(got, item.MR.ID)
+		}
+	}
+	if want := "gt-mr-low gt-mr-mid gt-mr-high"; strings.Join(got, " ") != want {
+		t.Errorf("ready = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestModelViewAndActions(t *testing.T) {
+	src := &fakeSource{queue: testQueue(time.Now())}
+	m := New(src, []string{"gastown", "beads"})
+	run(m, m.load("gastown")) // Init, without the periodic refresh
+
+	view := m.View()
+	for _, want := range []string{"[gastown]", "Ready (2)", "waiting on gt-9", "claimed by gastown/refinery", "orphaned-branch", "score 1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Preview follows the cursor.
+	press(m, "enter")
+	if !strings.Contains(m.View(), "abc123 fix the thing") {
+		t.Errorf("preview not shown:\n%s", m.View())
+	}
+
+	// Bump the second ready MR, then retry the claimed one.
+	press(m, "j", "+", "j", "j", "r")
+	// Reject the orphan with a reason.
+	press(m, "G", "x", "s", "t", "a", "l", "e", "enter")
+
+	want := []string{
+		"bump gastown gt-mr-low",
+		"retry gastown gt-mr-claimed",
+		"reject gastown gt-mr-orphan stale",
+	}
+	if strings.Join(src.actions, "|") != strings.Join(want, "|") {
+		t.Errorf("actions = %q, want %q", src.actions, want)
+	}
+	if !strings.Contains(m.View(), "rejected gt-mr-orphan") {
+		t.Errorf("status not shown:\n%s", m.View())
+	}
+
+	// Retry on an unclaimed MR does nothing but explain.
+	press(m, "g", "r")
+	if len(src.actions) != 3 || !strings.Contains(m.View(), "not claimed") {
+		t.Errorf("retry on unclaimed MR: actions %q\n%s", src.actions, m.View())
+	}
+
+	// Tab switches rigs.
+	press(m, "tab")
+	if !strings.Contains(m.View(), "[beads]") {
+		t.Errorf("rig not switched:\n%s", m.View())
+	}
+}
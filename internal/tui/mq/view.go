@@ -0,0 +1,278 @@
+package mq
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the merge queue TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	rigStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8"))
+
+	currentRigStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15"))
+
+	groupStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("14")) // cyan
+
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("15"))
+
+	rowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15"))
+
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")) // gray
+
+	warnStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")) // yellow
+
+	statusStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")) // green
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")) // red
+)
+
+// previewLines caps how much of a diff/log preview is shown.
+const previewLines = 30
+
+// renderView renders the entire view.
+// Caller must hold m.mu.
+func (m *Model) renderView() string {
+	var b strings.Builder
+
+	// Title, with the rig tabs
+	b.WriteString(titleStyle.Render("Merge queue"))
+	for i, rig := range m.rigs {
+		b.WriteString("  ")
+		if i == m.rig {
+			b.WriteString(currentRigStyle.Render("[" + rig + "]"))
+		} else {
+			b.WriteString(rigStyle.Render(rig))
+		}
+	}
+	b.WriteString("\n\n")
+
+	switch {
+	case len(m.rigs) == 0:
+		b.WriteString("No rigs found.\n")
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n")
+	case !m.loaded:
+		b.WriteString(dimStyle.Render("Loading..."))
+		b.WriteString("\n")
+	case len(m.items) == 0:
+		b.WriteString("No open merge requests.\n")
+	}
+
+	// MRs by group
+	group := Group(-1)
+	for i, item := range m.items {
+		if item.Group != group {
+			group = item.Group
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(groupStyle.Render(fmt.Sprintf("%s (%d)", group, m.countLocked(group))))
+			b.WriteString("\n")
+		}
+		line := m.renderRow(item)
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(rowStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	// Selected MR details
+	if item := m.selectedLocked(); item != nil {
+		b.WriteString("\n")
+		b.WriteString(m.renderDetails(item))
+	}
+
+	// Status of the last action
+	if m.status != "" {
+		b.WriteString("\n")
+		if strings.HasPrefix(m.status, "error:") || strings.HasPrefix(m.status, "session:") {
+			b.WriteString(errorStyle.Render(m.status))
+		} else {
+			b.WriteString(statusStyle.Render(m.status))
+		}
+		b.WriteString("\n")
+	}
+
+	// Reject prompt or help footer
+	b.WriteString("\n")
+	switch {
+	case m.rejecting:
+		reason := string(m.reason)
+		if reason == "" {
+			reason = helpStyle.Render("reason")
+		}
+		b.WriteString(fmt.Sprintf("Reject %s: %s█", m.selectedIDLocked(), reason))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("enter:reject  esc:cancel"))
+	case m.showHelp:
+		b.WriteString(m.help.View(m.keys))
+	default:
+		b.WriteString(helpStyle.Render("j/k:navigate  tab:rig  enter:preview  x:reject  +:bump  r:retry  s:session  q:quit  ?:help"))
+	}
+
+	return b.String()
+}
+
+// countLocked returns how many MRs are in a group.
+// Caller must hold m.mu.
+func (m *Model) countLocked(g Group) int {
+	n := 0
+	for _, item := range m.items {
+		if item.Group == g {
+			n++
+		}
+	}
+	return n
+}
+
+// renderRow renders one MR's row.
+func (m *Model) renderRow(item Item) string {
+	mr := item.MR
+	worker := mr.Worker
+	if worker == "" {
+		worker = "-"
+	}
+	age := "?"
+	if !mr.CreatedAt.IsZero() {
+		age = formatAge(m.now.Sub(mr.CreatedAt))
+	}
+	line := fmt.Sprintf("  %-14s P%d %7.1f  %-32s %-10s %4s",
+		truncate(mr.ID, 14),
+		mr.Priority,
+		item.Score.Total,
+		truncate(mr.Branch, 32),
+		truncate(worker, 10),
+		age,
+	)
+	switch {
+	case mr.Hold != "":
+		line += warnStyle.Render(" held: " + mr.Hold)
+	case mr.Pinned:
+		line += statusStyle.Render(" pinned")
+	}
+	switch item.Group {
+	case GroupBlocked:
+		line += dimStyle.Render(" waiting on " + mr.BlockedBy)
+	case GroupInProgress:
+		line += dimStyle.Render(" claimed by " + mr.Assignee)
+	case GroupAnomalous:
+		var types []string
+		for _, a := range item.Anomalies {
+			types = append(types, a.Type)
+		}
+		line += warnStyle.Render(" " + strings.Join(types, ", "))
+	}
+	return line
+}
+
+// renderDetails renders the selected MR's score breakdown, anomalies and
+// preview.
+func (m *Model) renderDetails(item *Item) string {
+	var b strings.Builder
+	mr := item.MR
+
+	title := mr.ID
+	if mr.Title != "" {
+		title += ": " + mr.Title
+	}
+	b.WriteString(currentRigStyle.Render(truncate(title, 100)))
+	b.WriteString("\n")
+
+	b.WriteString(dimStyle.Render("score " + item.Score.String()))
+	b.WriteString("\n")
+
+	target := mr.Target
+	if target == "" {
+		target = "(default)"
+	}
+	fmt.Fprintf(&b, "%s → %s", mr.Branch, target)
+	if mr.SourceIssue != "" {
+		fmt.Fprintf(&b, "  issue %s", mr.SourceIssue)
+	}
+	if mr.ConvoyID != "" {
+		fmt.Fprintf(&b, "  convoy %s", mr.ConvoyID)
+	}
+	if mr.RetryCount > 0 {
+		fmt.Fprintf(&b, "  %d retries", mr.RetryCount)
+	}
+	b.WriteString("\n")
+	if mr.PRURL != "" {
+		b.WriteString(dimStyle.Render("PR: " + mr.PRURL))
+		b.WriteString("\n")
+	}
+
+	for _, a := range item.Anomalies {
+		line := fmt.Sprintf("⚠ %s (%s): %s", a.Type, a.Severity, a.Detail)
+		if a.Age > 0 {
+			line += fmt.Sprintf(", %s", formatAge(a.Age))
+		}
+		b.WriteString(warnStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	if m.previewID == mr.ID {
+		b.WriteString("\n")
+		lines := strings.Split(strings.TrimRight(m.preview, "\n"), "\n")
+		if len(lines) > previewLines {
+			lines = append(lines[:previewLines], fmt.Sprintf("... %d more lines", len(lines)-previewLines))
+		}
+		for _, line := range lines {
+			b.WriteString("  ")
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// formatAge formats a duration in its largest whole unit (5m, 3h).
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// truncate shortens a string to the given rune length, preserving UTF-8.
+func truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
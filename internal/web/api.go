@@ -10,11 +10,16 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 
@@ -1145,7 +1150,7 @@ func (h *APIHandler) runBdCommand(ctx context.Context, timeout time.Duration, ar
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Acquire semaphore slot — shared with runGtCommand/runGhCommand.
+	// Acquire semaphore slot — shared with runGtCommand.
 	select {
 	case h.cmdSem <- struct{}{}:
 		defer func() { <-h.cmdSem }()
@@ -1353,7 +1358,6 @@ type PRShowResponse struct {
 	HeadRef      string   `json:"head_ref"`
 	Labels       []string `json:"labels,omitempty"`
 	Checks       []string `json:"checks,omitempty"`
-	RawOutput    string   `json:"raw_output,omitempty"`
 }
 
 // handlePRShow returns details for a specific PR.
@@ -1380,9 +1384,10 @@ func (h *APIHandler) handlePRShow(w http.ResponseWriter, r *http.Request) {
 			h.sendError(w, "PR URL cannot contain null bytes or newlines", http.StatusBadRequest)
 			return
 		}
-		// Allow any https:// URL, not just github.com — supports GitHub Enterprise.
-		// gh CLI validates against the configured host and rejects non-GitHub API responses,
-		// limiting SSRF risk. Localhost-only deployment further reduces exposure.
+		// Allow any https:// URL, not just github.com — supports GitHub Enterprise
+		// and self-hosted GitLab/Gitea. Only hosts with a known or configured forge
+		// are contacted, limiting SSRF risk. Localhost-only deployment further
+		// reduces exposure.
 		if !strings.HasPrefix(prURL, "https://") {
 			h.sendError(w, "PR URL must start with https://", http.StatusBadRequest)
 			return
@@ -1398,139 +1403,82 @@ func (h *APIHandler) handlePRShow(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var args []string
+	townRoot, _ := workspace.Find(h.workDir)
+
+	var target forge.Repo
+	var prNumber int
 	if prURL != "" {
-		args = []string{"pr", "view", prURL, "--json", "number,title,state,author,url,body,createdAt,updatedAt,additions,deletions,changedFiles,mergeable,baseRefName,headRefName,labels,statusCheckRollup"}
+		var err error
+		if target, prNumber, err = forge.ParsePRURL(prURL); err != nil {
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	} else {
-		args = []string{"pr", "view", number, "--repo", repo, "--json", "number,title,state,author,url,body,createdAt,updatedAt,additions,deletions,changedFiles,mergeable,baseRefName,headRefName,labels,statusCheckRollup"}
+		prNumber, _ = strconv.Atoi(number)
+		target = repoForRef(townRoot, repo)
 	}
 
-	output, err := h.runGhCommand(r.Context(), 15*time.Second, args)
+	provider, err := forge.ForRepo(townRoot, target)
 	if err != nil {
 		h.sendError(w, "Failed to fetch PR: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Parse the JSON output
-	resp := parsePRShowOutput(output)
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
-}
-
-// runGhCommand executes a gh command with the given args.
-func (h *APIHandler) runGhCommand(ctx context.Context, timeout time.Duration, args []string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
-
-	// Acquire semaphore slot — shared with runGtCommand/runBdCommand.
-	select {
-	case h.cmdSem <- struct{}{}:
-		defer func() { <-h.cmdSem }()
-	case <-ctx.Done():
-		return "", fmt.Errorf("command slot unavailable: %w", ctx.Err())
-	}
-
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	if h.workDir != "" {
-		cmd.Dir = h.workDir
+	pr, err := provider.GetPR(ctx, prNumber)
+	if err != nil {
+		h.sendError(w, "Failed to fetch PR: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
-	cmd.Stdin = nil
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(prShowResponse(pr))
+}
 
-	output := stdout.String()
-	if stderr.Len() > 0 {
-		if output != "" {
-			output += "\n"
+// repoForRef resolves an owner/repo reference to a repository, preferring a
+// registered rig with that repository (whose forge host is then known) and
+// falling back to github.com.
+func repoForRef(townRoot, ref string) forge.Repo {
+	if townRoot != "" {
+		if rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json")); err == nil {
+			for _, entry := range rigsConfig.Rigs {
+				if r, err := forge.ParseRepoURL(entry.GitURL); err == nil && strings.EqualFold(r.FullName(), ref) {
+					return r
+				}
+			}
 		}
-		output += stderr.String()
 	}
-
-	if ctx.Err() == context.DeadlineExceeded {
-		return output, fmt.Errorf("command timed out after %v", timeout)
-	}
-
-	if err != nil {
-		return output, fmt.Errorf("command failed: %v", err)
-	}
-
-	return output, nil
+	owner, name, _ := strings.Cut(ref, "/")
+	return forge.Repo{Host: "github.com", Owner: owner, Name: name}
 }
 
-// parsePRShowOutput parses the JSON output from "gh pr view --json".
-func parsePRShowOutput(jsonStr string) PRShowResponse {
+// prShowResponse converts a forge pull request to the /api/pr/show response.
+func prShowResponse(pr *forge.PullRequest) PRShowResponse {
 	resp := PRShowResponse{
-		RawOutput: jsonStr,
-	}
-
-	var data struct {
-		Number       int    `json:"number"`
-		Title        string `json:"title"`
-		State        string `json:"state"`
-		Author       struct {
-			Login string `json:"login"`
-		} `json:"author"`
-		URL          string `json:"url"`
-		Body         string `json:"body"`
-		CreatedAt    string `json:"createdAt"`
-		UpdatedAt    string `json:"updatedAt"`
-		Additions    int    `json:"additions"`
-		Deletions    int    `json:"deletions"`
-		ChangedFiles int    `json:"changedFiles"`
-		Mergeable    string `json:"mergeable"`
-		BaseRefName  string `json:"baseRefName"`
-		HeadRefName  string `json:"headRefName"`
-		Labels       []struct {
-			Name string `json:"name"`
-		} `json:"labels"`
-		StatusCheckRollup []struct {
-			Name       string `json:"name"`
-			Status     string `json:"status"`
-			Conclusion string `json:"conclusion"`
-		} `json:"statusCheckRollup"`
-	}
-
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-		return resp
-	}
-
-	resp.Number = data.Number
-	resp.Title = data.Title
-	resp.State = data.State
-	resp.Author = data.Author.Login
-	resp.URL = data.URL
-	resp.Body = data.Body
-	resp.CreatedAt = data.CreatedAt
-	resp.UpdatedAt = data.UpdatedAt
-	resp.Additions = data.Additions
-	resp.Deletions = data.Deletions
-	resp.ChangedFiles = data.ChangedFiles
-	resp.Mergeable = data.Mergeable
-	resp.BaseRef = data.BaseRefName
-	resp.HeadRef = data.HeadRefName
-
-	for _, label := range data.Labels {
-		resp.Labels = append(resp.Labels, label.Name)
-	}
-
-	for _, check := range data.StatusCheckRollup {
-		status := check.Name + ": "
-		if check.Conclusion != "" {
-			status += check.Conclusion
-		} else {
-			status += check.Status
-		}
-		resp.Checks = append(resp.Checks, status)
+		Number:       pr.Number,
+		Title:        pr.Title,
+		State:        pr.State,
+		Author:       pr.Author,
+		URL:          pr.URL,
+		Body:         pr.Body,
+		Additions:    pr.Additions,
+		Deletions:    pr.Deletions,
+		ChangedFiles: pr.ChangedFiles,
+		Mergeable:    pr.Mergeable,
+		BaseRef:      pr.BaseRef,
+		HeadRef:      pr.HeadRef,
+		Labels:       pr.Labels,
+	}
+	if !pr.CreatedAt.IsZero() {
+		resp.CreatedAt = pr.CreatedAt.Format(time.RFC3339)
+	}
+	if !pr.UpdatedAt.IsZero() {
+		resp.UpdatedAt = pr.UpdatedAt.Format(time.RFC3339)
+	}
+	for _, check := range pr.Checks {
+		resp.Checks = append(resp.Checks, check.Name+": "+check.State)
 	}
-
-	// Clear raw output if parsing succeeded
-	resp.RawOutput = ""
-
 	return resp
 }
 
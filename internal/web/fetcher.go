@@ -14,8 +14,13 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/customrole"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/stats"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -45,6 +50,10 @@ var fetcherRunCmd = runCmd
 
 // runBdCmd executes a bd command with the configured cmdTimeout in the specified beads directory.
 func (f *LiveConvoyFetcher) runBdCmd(beadsDir string, args ...string) (*bytes.Buffer, error) {
+	if out, ok := beads.ReadSQL(beads.ResolveBeadsDir(beadsDir), args...); ok {
+		return bytes.NewBuffer(out), nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), f.cmdTimeout)
 	defer cancel()
 
@@ -127,15 +136,24 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 	}
 
 	var convoys []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		CreatedAt string `json:"created_at"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Description string `json:"description"`
+		CreatedAt   string `json:"created_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return nil, fmt.Errorf("parsing convoy list: %w", err)
 	}
 
+	// Cycle times for ETAs; without a readable events log there are none.
+	now := time.Now()
+	cycleTimes, err := convoy.LoadCycleTimes(f.townRoot, now.Add(-convoy.CycleTimeWindow))
+	if err != nil {
+		log.Printf("warning: reading events log for convoy ETAs: %v", err)
+		cycleTimes = convoy.NewCycleTimes()
+	}
+
 	// Build convoy rows with activity data
 	rows := make([]ConvoyRow, 0, len(convoys))
 	for _, c := range convoys {
@@ -210,6 +228,7 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 
 		// Get tracked issues for expandable view
 		row.TrackedIssues = make([]TrackedIssue, len(tracked))
+		issues := make([]convoy.Issue, len(tracked))
 		for i, t := range tracked {
 			row.TrackedIssues[i] = TrackedIssue{
 				ID:       t.ID,
@@ -217,7 +236,19 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 				Status:   t.Status,
 				Assignee: t.Assignee,
 			}
+			issues[i] = convoy.Issue{ID: t.ID, Status: t.Status, Assignee: t.Assignee}
+		}
+
+		// Forecast landing against the deadline, if any
+		forecast := cycleTimes.Forecast(f.townRoot, issues, convoy.ParseMaxInFlight(c.Description),
+			convoy.ParseDeadline(c.Description), now)
+		if forecast.Deadline != nil {
+			row.Deadline = forecast.Deadline.Local().Format("Jan 2 15:04")
+		}
+		if forecast.ETA != nil && forecast.Remaining > 0 {
+			row.ETA = forecast.ETA.Local().Format("Jan 2 15:04")
 		}
+		row.SLA = string(forecast.SLA)
 
 		rows = append(rows, row)
 	}
@@ -513,7 +544,8 @@ func calculateWorkStatus(completed, total int, activityColor string) string {
 	}
 }
 
-// FetchMergeQueue fetches open PRs from registered rigs.
+// FetchMergeQueue fetches open PRs from registered rigs, from whichever
+// forge each rig's git URL points at.
 func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 	// Load registered rigs from config
 	rigsConfigPath := filepath.Join(f.townRoot, "mayor", "rigs.json")
@@ -525,13 +557,13 @@ func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 	var result []MergeQueueRow
 
 	for rigName, entry := range rigsConfig.Rigs {
-		// Convert git URL to owner/repo format for gh CLI
-		repoPath := gitURLToRepoPath(entry.GitURL)
-		if repoPath == "" {
+		provider, err := forge.ForURL(f.townRoot, entry.GitURL)
+		if err != nil {
+			// No known forge for this rig (local remote, unconfigured host)
 			continue
 		}
 
-		prs, err := f.fetchPRsForRepo(repoPath, rigName)
+		prs, err := f.fetchPRsForRepo(provider, rigName)
 		if err != nil {
 			// Non-fatal: continue with other repos
 			continue
@@ -542,54 +574,14 @@ func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 	return result, nil
 }
 
-// gitURLToRepoPath converts a git URL to owner/repo format.
-// Supports HTTPS (https://github.com/owner/repo.git) and
-// SSH (git@github.com:owner/repo.git) formats.
-func gitURLToRepoPath(gitURL string) string {
-	// Handle HTTPS format: https://github.com/owner/repo.git
-	if strings.HasPrefix(gitURL, "https://github.com/") {
-		path := strings.TrimPrefix(gitURL, "https://github.com/")
-		path = strings.TrimSuffix(path, ".git")
-		return path
-	}
-
-	// Handle SSH format: git@github.com:owner/repo.git
-	if strings.HasPrefix(gitURL, "git@github.com:") {
-		path := strings.TrimPrefix(gitURL, "git@github.com:")
-		path = strings.TrimSuffix(path, ".git")
-		return path
-	}
-
-	// Unsupported format
-	return ""
-}
-
-// prResponse represents the JSON response from gh pr list.
-type prResponse struct {
-	Number            int    `json:"number"`
-	Title             string `json:"title"`
-	URL               string `json:"url"`
-	Mergeable         string `json:"mergeable"`
-	StatusCheckRollup []struct {
-		State      string `json:"state"`
-		Status     string `json:"status"`
-		Conclusion string `json:"conclusion"`
-	} `json:"statusCheckRollup"`
-}
-
 // fetchPRsForRepo fetches open PRs for a single repo.
-func (f *LiveConvoyFetcher) fetchPRsForRepo(repoFull, repoShort string) ([]MergeQueueRow, error) {
-	stdout, err := runCmd(f.ghCmdTimeout, "gh", "pr", "list",
-		"--repo", repoFull,
-		"--state", "open",
-		"--json", "number,title,url,mergeable,statusCheckRollup")
-	if err != nil {
-		return nil, fmt.Errorf("fetching PRs for %s: %w", repoFull, err)
-	}
+func (f *LiveConvoyFetcher) fetchPRsForRepo(provider forge.Provider, repoShort string) ([]MergeQueueRow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), f.ghCmdTimeout)
+	defer cancel()
 
-	var prs []prResponse
-	if err := json.Unmarshal(stdout.Bytes(), &prs); err != nil {
-		return nil, fmt.Errorf("parsing PRs for %s: %w", repoFull, err)
+	prs, err := provider.ListPRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching PRs for %s: %w", provider.Repo(), err)
 	}
 
 	result := make([]MergeQueueRow, 0, len(prs))
@@ -601,8 +593,8 @@ func (f *LiveConvoyFetcher) fetchPRsForRepo(repoFull, repoShort string) ([]Merge
 			URL:    pr.URL,
 		}
 
-		// Determine CI status from statusCheckRollup
-		row.CIStatus = determineCIStatus(pr.StatusCheckRollup)
+		// Determine CI status from the head commit's checks
+		row.CIStatus = determineCIStatus(pr.Checks)
 
 		// Determine mergeable status
 		row.Mergeable = determineMergeableStatus(pr.Mergeable)
@@ -617,11 +609,7 @@ func (f *LiveConvoyFetcher) fetchPRsForRepo(repoFull, repoShort string) ([]Merge
 }
 
 // determineCIStatus evaluates the overall CI status from status checks.
-func determineCIStatus(checks []struct {
-	State      string `json:"state"`
-	Status     string `json:"status"`
-	Conclusion string `json:"conclusion"`
-}) string {
+func determineCIStatus(checks []forge.Check) string {
 	if len(checks) == 0 {
 		return "pending"
 	}
@@ -630,25 +618,13 @@ func determineCIStatus(checks []struct {
 	hasPending := false
 
 	for _, check := range checks {
-		// Check conclusion first (for completed checks)
-		switch check.Conclusion {
-		case "failure", "cancelled", "timed_out", "action_required": //nolint:misspell // GitHub API returns "cancelled" (British spelling)
+		switch check.State {
+		case forge.CheckFailure:
 			hasFailure = true
-		case "success", "skipped", "neutral":
+		case forge.CheckSuccess:
 			// Pass
 		default:
-			// Check status for in-progress checks
-			switch check.Status {
-			case "queued", "in_progress", "waiting", "pending", "requested":
-				hasPending = true
-			}
-			// Also check state field
-			switch check.State {
-			case "FAILURE", "ERROR":
-				hasFailure = true
-			case "PENDING", "EXPECTED":
-				hasPending = true
-			}
+			hasPending = true
 		}
 	}
 
@@ -661,12 +637,12 @@ func determineCIStatus(checks []struct {
 	return "pass"
 }
 
-// determineMergeableStatus converts GitHub's mergeable field to display value.
+// determineMergeableStatus converts a forge's mergeability to display value.
 func determineMergeableStatus(mergeable string) string {
-	switch strings.ToUpper(mergeable) {
-	case "MERGEABLE":
+	switch mergeable {
+	case forge.MergeClean:
 		return "ready"
-	case "CONFLICTING":
+	case forge.MergeConflict:
 		return "conflict"
 	default:
 		return "pending"
@@ -715,6 +691,13 @@ func (f *LiveConvoyFetcher) FetchWorkers() ([]WorkerRow, error) {
 	// Pre-fetch merge queue count to determine refinery idle status
 	mergeQueueCount := f.getMergeQueueCount()
 
+	// Custom role sessions don't follow the canonical session name format
+	rigNames := make([]string, 0, len(registeredRigs))
+	for rigName := range registeredRigs {
+		rigNames = append(rigNames, rigName)
+	}
+	customSessions := customRoleSessions(f.townRoot, rigNames)
+
 	var workers []WorkerRow
 	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
 
@@ -730,6 +713,35 @@ func (f *LiveConvoyFetcher) FetchWorkers() ([]WorkerRow, error) {
 
 		sessionName := parts[0]
 
+		// Rig-scoped custom role agents are listed under their role name
+		if mgr, ok := customSessions[sessionName]; ok {
+			if mgr.RigName() == "" {
+				continue
+			}
+			var activityUnix int64
+			if _, err := fmt.Sscanf(parts[1], "%d", &activityUnix); err != nil || activityUnix == 0 {
+				continue
+			}
+			activityTime := time.Unix(activityUnix, 0)
+			var issueID, issueTitle string
+			if issue, ok := assignedIssues[mgr.Address()]; ok {
+				issueID = issue.ID
+				issueTitle = issue.Title
+			}
+			workers = append(workers, WorkerRow{
+				Name:         mgr.Role().Role,
+				Rig:          mgr.RigName(),
+				SessionID:    sessionName,
+				LastActivity: activity.Calculate(activityTime),
+				StatusHint:   f.getWorkerStatusHint(sessionName),
+				IssueID:      issueID,
+				IssueTitle:   issueTitle,
+				WorkStatus:   calculateWorkerWorkStatus(time.Since(activityTime), issueID, mgr.Role().Role, f.staleThreshold, f.stuckThreshold),
+				AgentType:    mgr.Role().Role,
+			})
+			continue
+		}
+
 		// Filter for gt-<rig>-<polecat> pattern
 		// Parse session name using canonical parser
 		identity, err := session.ParseSessionName(sessionName)
@@ -802,6 +814,17 @@ func (f *LiveConvoyFetcher) FetchWorkers() ([]WorkerRow, error) {
 	return workers, nil
 }
 
+// customRoleSessions maps the session names of the town's custom role agents
+// (in the given rigs, plus town-scoped ones) to their managers.
+func customRoleSessions(townRoot string, rigs []string) map[string]*customrole.Manager {
+	managers, _ := customrole.ForTown(townRoot, rigs)
+	sessions := make(map[string]*customrole.Manager, len(managers))
+	for _, m := range managers {
+		sessions[m.SessionName()] = m
+	}
+	return sessions
+}
+
 // assignedIssue holds issue info for the assigned issues map.
 type assignedIssue struct {
 	ID    string
@@ -1324,6 +1347,14 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 		return nil, nil // tmux not running or no sessions
 	}
 
+	var rigNames []string
+	if rigsConfig, err := config.LoadRigsConfig(filepath.Join(f.townRoot, "mayor", "rigs.json")); err == nil {
+		for rigName := range rigsConfig.Rigs {
+			rigNames = append(rigNames, rigName)
+		}
+	}
+	customSessions := customRoleSessions(f.townRoot, rigNames)
+
 	var rows []SessionRow
 	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
 		if line == "" {
@@ -1334,8 +1365,9 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 		parts := strings.SplitN(line, ":", 2)
 		name := parts[0]
 
-		// Only include gt-* sessions
-		if !strings.HasPrefix(name, "gt-") {
+		// Only include gt-* sessions and custom role sessions
+		mgr, isCustom := customSessions[name]
+		if !strings.HasPrefix(name, "gt-") && !isCustom {
 			continue
 		}
 
@@ -1353,7 +1385,10 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 		}
 
 		// Detect role from session name using canonical parser
-		if identity, err := session.ParseSessionName(name); err == nil {
+		if isCustom {
+			row.Rig = mgr.RigName()
+			row.Role = mgr.Role().Role
+		} else if identity, err := session.ParseSessionName(name); err == nil {
 			row.Rig = identity.Rig
 			row.Role = string(identity.Role)
 			row.Worker = identity.Name
@@ -1586,6 +1621,41 @@ func (f *LiveConvoyFetcher) FetchIssues() ([]IssueRow, error) {
 	return rows, nil
 }
 
+// agentStatsWindow is how far back the Agent Performance panel looks.
+const agentStatsWindow = 30 * 24 * time.Hour
+
+// FetchAgentStats returns per-agent-preset performance over the last 30 days.
+func (f *LiveConvoyFetcher) FetchAgentStats() ([]AgentStatsRow, error) {
+	c, err := stats.Load(f.townRoot, time.Now().Add(-agentStatsWindow))
+	if err != nil {
+		return nil, fmt.Errorf("loading agent stats: %w", err)
+	}
+	var rows []AgentStatsRow
+	for _, m := range c.Metrics(stats.ByAgent, "") {
+		row := AgentStatsRow{
+			Agent:        m.Key,
+			Slung:        m.Slung,
+			Merged:       m.Merged,
+			MergeRate:    "-",
+			Rework:       m.Rework,
+			Escalations:  m.Escalations,
+			TimeToDone:   "-",
+			CostPerMerge: "-",
+		}
+		if m.Merged+m.MergeFailed > 0 {
+			row.MergeRate = fmt.Sprintf("%.0f%%", m.MergeSuccessRate*100)
+		}
+		if m.MedianTimeToDone > 0 {
+			row.TimeToDone = formatUptime(m.MedianTimeToDone)
+		}
+		if m.Merged > 0 {
+			row.CostPerMerge = fmt.Sprintf("$%.2f", m.CostPerMerged)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
 // FetchActivity returns recent activity from the event log.
 func (f *LiveConvoyFetcher) FetchActivity() ([]ActivityRow, error) {
 	eventsPath := filepath.Join(f.townRoot, ".events.jsonl")
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/forge"
 )
 
 func TestCalculateWorkStatus(t *testing.T) {
@@ -87,12 +88,8 @@ func TestCalculateWorkStatus(t *testing.T) {
 func TestDetermineCIStatus(t *testing.T) {
 	tests := []struct {
 		name   string
-		checks []struct {
-			State      string `json:"state"`
-			Status     string `json:"status"`
-			Conclusion string `json:"conclusion"`
-		}
-		want string
+		checks []forge.Check
+		want   string
 	}{
 		{
 			name:   "pending when no checks",
@@ -101,116 +98,40 @@ func TestDetermineCIStatus(t *testing.T) {
 		},
 		{
 			name: "pass when all success",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "success"},
-				{Conclusion: "success"},
-			},
-			want: "pass",
-		},
-		{
-			name: "pass with skipped checks",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "success"},
-				{Conclusion: "skipped"},
+			checks: []forge.Check{
+				{Name: "build", State: forge.CheckSuccess},
+				{Name: "test", State: forge.CheckSuccess},
 			},
 			want: "pass",
 		},
 		{
 			name: "fail when any failure",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "success"},
-				{Conclusion: "failure"},
-			},
-			want: "fail",
-		},
-		{
-			name: "fail when cancelled",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "cancelled"},
+			checks: []forge.Check{
+				{Name: "build", State: forge.CheckSuccess},
+				{Name: "test", State: forge.CheckFailure},
 			},
 			want: "fail",
 		},
 		{
-			name: "fail when timed_out",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "timed_out"},
-			},
-			want: "fail",
-		},
-		{
-			name: "pending when in_progress",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "success"},
-				{Status: "in_progress"},
-			},
-			want: "pending",
-		},
-		{
-			name: "pending when queued",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Status: "queued"},
+			name: "pending when any pending",
+			checks: []forge.Check{
+				{Name: "build", State: forge.CheckSuccess},
+				{Name: "test", State: forge.CheckPending},
 			},
 			want: "pending",
 		},
 		{
-			name: "fail from state FAILURE",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{State: "FAILURE"},
-			},
-			want: "fail",
-		},
-		{
-			name: "pending from state PENDING",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{State: "PENDING"},
+			name: "pending when state unknown",
+			checks: []forge.Check{
+				{Name: "build"},
 			},
 			want: "pending",
 		},
 		{
 			name: "failure takes precedence over pending",
-			checks: []struct {
-				State      string `json:"state"`
-				Status     string `json:"status"`
-				Conclusion string `json:"conclusion"`
-			}{
-				{Conclusion: "failure"},
-				{Status: "in_progress"},
+			checks: []forge.Check{
+				{Name: "build", State: forge.CheckFailure},
+				{Name: "test", State: forge.CheckPending},
 			},
 			want: "fail",
 		},
@@ -232,12 +153,9 @@ func TestDetermineMergeableStatus(t *testing.T) {
 		mergeable string
 		want      string
 	}{
-		{"ready when MERGEABLE", "MERGEABLE", "ready"},
-		{"ready when lowercase mergeable", "mergeable", "ready"},
-		{"conflict when CONFLICTING", "CONFLICTING", "conflict"},
-		{"conflict when lowercase conflicting", "conflicting", "conflict"},
-		{"pending when UNKNOWN", "UNKNOWN", "pending"},
-		{"pending when empty", "", "pending"},
+		{"ready when clean", forge.MergeClean, "ready"},
+		{"conflict when conflicting", forge.MergeConflict, "conflict"},
+		{"pending when unknown", "", "pending"},
 		{"pending when other value", "something_else", "pending"},
 	}
 
@@ -252,6 +170,29 @@ func TestDetermineMergeableStatus(t *testing.T) {
 	}
 }
 
+func TestFetchPRsForRepo(t *testing.T) {
+	f := forge.NewFake(forge.Repo{Host: "git.example.com", Owner: "acme", Name: "widgets"})
+	f.PRs[1] = &forge.PullRequest{Number: 1, Title: "green", State: forge.PROpen, Mergeable: forge.MergeClean,
+		Checks: []forge.Check{{Name: "ci", State: forge.CheckSuccess}}}
+	f.PRs[2] = &forge.PullRequest{Number: 2, Title: "conflicted", State: forge.PROpen, Mergeable: forge.MergeConflict}
+	f.PRs[3] = &forge.PullRequest{Number: 3, Title: "merged", State: forge.PRMerged}
+
+	fetcher := &LiveConvoyFetcher{ghCmdTimeout: time.Second}
+	rows, err := fetcher.fetchPRsForRepo(f, "widgets")
+	if err != nil {
+		t.Fatalf("fetchPRsForRepo: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2 open PRs: %+v", len(rows), rows)
+	}
+	if rows[0].Repo != "widgets" || rows[0].CIStatus != "pass" || rows[0].ColorClass != "mq-green" {
+		t.Errorf("row 0 = %+v, want passing widgets PR", rows[0])
+	}
+	if rows[1].Mergeable != "conflict" || rows[1].ColorClass != "mq-red" {
+		t.Errorf("row 1 = %+v, want conflicting PR", rows[1])
+	}
+}
+
 func TestDetermineColorClass(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -33,6 +33,7 @@ type ConvoyFetcher interface {
 	FetchMayor() (*MayorStatus, error)
 	FetchIssues() ([]IssueRow, error)
 	FetchActivity() ([]ActivityRow, error)
+	FetchAgentStats() ([]AgentStatsRow, error)
 }
 
 // ConvoyHandler handles HTTP requests for the convoy dashboard.
@@ -81,11 +82,12 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		mayor       *MayorStatus
 		issues      []IssueRow
 		activity    []ActivityRow
+		agentStats  []AgentStatsRow
 		wg          sync.WaitGroup
 	)
 
 	// Run all fetches in parallel with error logging
-	wg.Add(14)
+	wg.Add(15)
 
 	go func() {
 		defer wg.Done()
@@ -199,6 +201,14 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			log.Printf("dashboard: FetchActivity failed: %v", err)
 		}
 	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		agentStats, err = h.fetcher.FetchAgentStats()
+		if err != nil {
+			log.Printf("dashboard: FetchAgentStats failed: %v", err)
+		}
+	}()
 
 	// Wait for fetches or timeout
 	done := make(chan struct{})
@@ -237,6 +247,7 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Mayor:       mayor,
 		Issues:      enrichIssuesWithAssignees(issues, hooks),
 		Activity:    activity,
+		AgentStats:  agentStats,
 		Summary:     summary,
 		Tunnel:      tunnelStatus,
 		Expand:      expandPanel,
@@ -32,6 +32,7 @@ type MockConvoyFetcher struct {
 	Mayor       *MayorStatus
 	Issues      []IssueRow
 	Activity    []ActivityRow
+	AgentStats  []AgentStatsRow
 	Error       error
 }
 
@@ -91,6 +92,10 @@ func (m *MockConvoyFetcher) FetchActivity() ([]ActivityRow, error) {
 	return m.Activity, nil
 }
 
+func (m *MockConvoyFetcher) FetchAgentStats() ([]AgentStatsRow, error) {
+	return m.AgentStats, nil
+}
+
 func TestConvoyHandler_RendersTemplate(t *testing.T) {
 	mock := &MockConvoyFetcher{
 		Convoys: []ConvoyRow{
@@ -1059,6 +1064,10 @@ func (m *MockConvoyFetcherWithErrors) FetchActivity() ([]ActivityRow, error) {
 	return nil, nil
 }
 
+func (m *MockConvoyFetcherWithErrors) FetchAgentStats() ([]AgentStatsRow, error) {
+	return nil, nil
+}
+
 // TestConvoyHandler_TemplateErrorReturns500 verifies that template execution errors
 // return a proper 500 status code, not 200 (which would happen if we wrote directly
 // to the ResponseWriter and it failed mid-execution).
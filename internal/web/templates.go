@@ -29,6 +29,7 @@ type ConvoyData struct {
 	Mayor       *MayorStatus
 	Issues      []IssueRow
 	Activity    []ActivityRow
+	AgentStats  []AgentStatsRow
 	Summary     *DashboardSummary
 	Tunnel      *TunnelStatus
 	Expand      string // Panel to show fullscreen (from ?expand=name)
@@ -44,6 +45,19 @@ type RigRow struct {
 	HasRefinery  bool
 }
 
+// AgentStatsRow summarizes one agent preset's recent performance
+// (see gt stats agents --by agent).
+type AgentStatsRow struct {
+	Agent        string
+	Slung        int
+	Merged       int
+	MergeRate    string // e.g. "92%", "-" if nothing finished merging
+	Rework       int
+	Escalations  int
+	TimeToDone   string // Median, e.g. "3h"
+	CostPerMerge string // e.g. "$1.20", "-" if nothing merged
+}
+
 // DogRow represents a Deacon helper worker.
 type DogRow struct {
 	Name       string // Dog name (e.g., "alpha")
@@ -181,7 +195,7 @@ type WorkerRow struct {
 	IssueID      string        // Currently assigned issue ID (e.g., "hq-1234")
 	IssueTitle   string        // Issue title (truncated)
 	WorkStatus   string        // working, stale, stuck, idle
-	AgentType    string        // "polecat" (ephemeral sessions) or "refinery" (permanent)
+	AgentType    string        // "polecat" (ephemeral sessions), "refinery" (permanent), or a custom role name
 }
 
 // MergeQueueRow represents a PR in the merge queue.
@@ -206,6 +220,9 @@ type ConvoyRow struct {
 	Total         int
 	LastActivity  activity.Info
 	TrackedIssues []TrackedIssue
+	Deadline      string // Formatted deadline, empty if none
+	ETA           string // Formatted forecast, empty if unknown
+	SLA           string // "on_track", "at_risk", "overdue", "unknown", or empty without a deadline
 }
 
 // TrackedIssue represents an issue tracked by a convoy.
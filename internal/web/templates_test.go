@@ -173,6 +173,64 @@ func TestConvoyTemplate_ProgressDisplay(t *testing.T) {
 	}
 }
 
+func TestConvoyTemplate_DeadlineDisplay(t *testing.T) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	data := ConvoyData{
+		Convoys: []ConvoyRow{
+			{
+				ID:       "hq-cv-late",
+				Title:    "Late",
+				Status:   "open",
+				Deadline: "Nov 1 23:59",
+				ETA:      "Nov 3 10:00",
+				SLA:      "at_risk",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.ExecuteTemplate(&buf, "convoy.html", data)
+	if err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"Nov 1 23:59", "ETA Nov 3 10:00", "At risk"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Template should display %q", want)
+		}
+	}
+}
+
+func TestConvoyTemplate_AgentStatsPanel(t *testing.T) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	data := ConvoyData{
+		AgentStats: []AgentStatsRow{
+			{Agent: "codex", Slung: 12, Merged: 9, MergeRate: "90%", TimeToDone: "2h15m", CostPerMerge: "$1.40"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "convoy.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"Agent Performance", "codex", "90%", "2h15m", "$1.40"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Template should display %q", want)
+		}
+	}
+}
+
 func TestConvoyTemplate_StatusIndicators(t *testing.T) {
 	tmpl, err := LoadTemplates()
 	if err != nil {
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Delivery request headers.
+const (
+	HeaderEvent     = "X-Gastown-Event"     // Event type
+	HeaderDelivery  = "X-Gastown-Delivery"  // Delivery ID, stable across retries
+	HeaderAttempt   = "X-Gastown-Attempt"   // 1-based attempt number
+	HeaderTimestamp = "X-Gastown-Timestamp" // Unix seconds the request was signed
+	HeaderSignature = "X-Gastown-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+)
+
+// Envelope is the JSON body of a delivery.
+type Envelope struct {
+	ID      string       `json:"id"`      // Delivery ID
+	Webhook string       `json:"webhook"` // Subscription ID
+	Event   events.Event `json:"event"`
+}
+
+// Result describes one delivery attempt.
+type Result struct {
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// OK reports whether the receiver accepted the delivery (2xx).
+func (r *Result) OK() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Retryable reports whether a failed attempt is worth retrying: network
+// errors, timeouts, rate limiting and server errors. Other 4xx responses
+// mean the receiver rejected the request and will do so again.
+func (r *Result) Retryable() bool {
+	if r.OK() {
+		return false
+	}
+	if r.StatusCode == 0 {
+		return true
+	}
+	return r.StatusCode >= 500 || r.StatusCode == http.StatusRequestTimeout || r.StatusCode == http.StatusTooManyRequests
+}
+
+// Sign returns the signature header value for a body signed at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery's signature, as a receiver would. Requests signed
+// more than tolerance ago are rejected to prevent replays (0 = no check).
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s header", HeaderTimestamp)
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("signature timestamp outside tolerance (%s)", age.Round(time.Second))
+		}
+	}
+	want := Sign(secret, ts, body)
+	if !hmac.Equal([]byte(want), []byte(header.Get(HeaderSignature))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Send makes one signed delivery attempt of env to the subscription's URL.
+func Send(ctx context.Context, client *http.Client, sub *Subscription, env *Envelope, attempt int) *Result {
+	start := time.Now()
+	result := &Result{}
+	defer func() { result.Duration = time.Since(start) }()
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		result.Error = fmt.Sprintf("encoding delivery: %v", err)
+		return result
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = fmt.Sprintf("building request: %v", err)
+		return result
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gastown-webhooks")
+	req.Header.Set(HeaderEvent, env.Event.Type)
+	req.Header.Set(HeaderDelivery, env.ID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+	if !result.OK() {
+		// Keep a snippet of the receiver's explanation for the dead-letter log.
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		result.Error = strings.TrimSpace(fmt.Sprintf("HTTP %d %s", resp.StatusCode, snippet))
+	}
+	return result
+}
+
+// NewEnvelope wraps an event for delivery to a subscription with a fresh
+// delivery ID.
+func NewEnvelope(sub *Subscription, e *events.Event) (*Envelope, error) {
+	id, err := randomHex("whd-", 8)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{ID: id, Webhook: sub.ID, Event: *e}, nil
+}
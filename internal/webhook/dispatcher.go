@@ -0,0 +1,351 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+const (
+	// StateFile records the dispatcher's position in the events log and its
+	// pending retries, relative to the town root.
+	StateFile = "daemon/webhooks-state.json"
+
+	// DeadLetterFile logs deliveries that failed permanently, relative to
+	// the town root.
+	DeadLetterFile = "daemon/webhooks-dead.jsonl"
+
+	defaultPollInterval = 2 * time.Second
+	defaultBaseBackoff  = 10 * time.Second
+	defaultMaxBackoff   = 10 * time.Minute
+	requestTimeout      = 10 * time.Second
+)
+
+// pendingDelivery is a delivery waiting for its next attempt.
+type pendingDelivery struct {
+	Envelope    *Envelope `json:"envelope"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastStatus  int       `json:"last_status,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// dispatchState is persisted so a daemon restart neither drops nor repeats
+// deliveries.
+type dispatchState struct {
+	Offset  int64              `json:"offset"` // Bytes of the events log consumed
+	Pending []*pendingDelivery `json:"pending,omitempty"`
+}
+
+// DeadLetter is a delivery that exhausted its attempts or was rejected.
+type DeadLetter struct {
+	Envelope
+	URL        string    `json:"url"`
+	Attempts   int       `json:"attempts"`
+	LastStatus int       `json:"last_status,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// Dispatcher tails the events log and delivers matching events to the
+// configured subscriptions. It runs as a background goroutine in the daemon.
+//
+// Delivery is at-least-once: receivers should dedupe on the delivery ID.
+// Retries mean deliveries for one subscription may arrive out of order.
+type Dispatcher struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+	client   *http.Client
+
+	PollInterval time.Duration
+	BaseBackoff  time.Duration // Delay before the first retry; doubles per attempt
+	MaxBackoff   time.Duration
+
+	state *dispatchState
+	now   func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher for a town.
+func NewDispatcher(townRoot string, logger func(format string, args ...interface{})) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		townRoot:     townRoot,
+		logger:       logger,
+		client:       &http.Client{Timeout: requestTimeout},
+		PollInterval: defaultPollInterval,
+		BaseBackoff:  defaultBaseBackoff,
+		MaxBackoff:   defaultMaxBackoff,
+		now:          time.Now,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start begins the dispatcher goroutine.
+func (d *Dispatcher) Start() error {
+	d.wg.Add(1)
+	go d.run()
+	return nil
+}
+
+// Stop gracefully stops the dispatcher. Pending retries are kept in the
+// state file and resume on the next start.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// run is the main dispatcher loop.
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Poll(); err != nil {
+				d.logger("webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// Poll reads events appended since the last poll, queues deliveries for
+// matching subscriptions and makes the attempts that are due. The
+// configuration is reloaded each poll, so subscription changes apply
+// without restarting the daemon.
+func (d *Dispatcher) Poll() error {
+	cfg, err := LoadConfig(d.townRoot)
+	if err != nil {
+		return err
+	}
+
+	if d.state == nil {
+		if d.state, err = loadState(d.townRoot); err != nil {
+			return err
+		}
+	}
+
+	changed, err := d.readEvents(cfg)
+	if err != nil {
+		return err
+	}
+	if d.deliverDue(cfg) {
+		changed = true
+	}
+	if changed {
+		if err := util.EnsureDirAndWriteJSON(filepath.Join(d.townRoot, StateFile), d.state); err != nil {
+			return fmt.Errorf("saving webhook state: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadState reads the dispatcher state. Without a state file the offset is
+// -1, meaning "start at the end of the log".
+func loadState(townRoot string) (*dispatchState, error) {
+	data, err := os.ReadFile(filepath.Join(townRoot, StateFile))
+	if os.IsNotExist(err) {
+		return &dispatchState{Offset: -1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading webhook state: %w", err)
+	}
+	var state dispatchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing webhook state: %w", err)
+	}
+	return &state, nil
+}
+
+// readEvents queues deliveries for events appended to the log since the
+// last read, reporting whether the state changed.
+func (d *Dispatcher) readEvents(cfg *Config) (bool, error) {
+	f, err := os.Open(filepath.Join(d.townRoot, events.EventsFile))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	size := info.Size()
+
+	switch {
+	case d.state.Offset < 0:
+		// First run: deliver only events from now on, not the whole history.
+		d.state.Offset = size
+		return true, nil
+	case size < d.state.Offset:
+		// The log was rewritten (KRC prune); its old offsets are meaningless.
+		d.logger("webhooks: events log shrank (%d → %d bytes), resuming at end", d.state.Offset, size)
+		d.state.Offset = size
+		return true, nil
+	case size == d.state.Offset:
+		return false, nil
+	}
+	if _, err := f.Seek(d.state.Offset, io.SeekStart); err != nil {
+		return false, err
+	}
+	reader := bufio.NewReader(f)
+	changed := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break // EOF, or a partial line still being written
+		}
+		d.state.Offset += int64(len(line))
+		changed = true
+
+		var e events.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue // Skip malformed lines
+		}
+		for _, sub := range cfg.Subscriptions {
+			if !sub.Matches(&e) {
+				continue
+			}
+			env, err := NewEnvelope(sub, &e)
+			if err != nil {
+				return changed, err
+			}
+			d.state.Pending = append(d.state.Pending, &pendingDelivery{Envelope: env, NextAttempt: d.now()})
+		}
+	}
+	return changed, nil
+}
+
+// deliverDue attempts the pending deliveries that are due, reporting
+// whether any were attempted or dropped.
+func (d *Dispatcher) deliverDue(cfg *Config) bool {
+	now := d.now()
+	changed := false
+	kept := d.state.Pending[:0]
+	for _, p := range d.state.Pending {
+		sub := cfg.Get(p.Envelope.Webhook)
+		if sub == nil || sub.Disabled {
+			changed = true
+			continue // Subscription removed or disabled: drop its backlog
+		}
+		if p.NextAttempt.After(now) || d.ctx.Err() != nil {
+			kept = append(kept, p)
+			continue
+		}
+
+		changed = true
+		result := Send(d.ctx, d.client, sub, p.Envelope, p.Attempts+1)
+		if d.ctx.Err() != nil {
+			kept = append(kept, p) // Interrupted by shutdown: not a real attempt
+			continue
+		}
+		p.Attempts++
+		if result.OK() {
+			continue
+		}
+		p.LastStatus, p.LastError = result.StatusCode, result.Error
+		if !result.Retryable() || p.Attempts >= cfg.maxAttempts() {
+			d.deadLetter(sub, p)
+			continue
+		}
+		p.NextAttempt = now.Add(d.backoff(p.Attempts))
+		kept = append(kept, p)
+	}
+	d.state.Pending = kept
+	return changed
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
+
+func (d *Dispatcher) deadLetter(sub *Subscription, p *pendingDelivery) {
+	d.logger("webhooks: delivery %s of %s to %s failed after %d attempt(s): %s",
+		p.Envelope.ID, p.Envelope.Event.Type, sub.ID, p.Attempts, p.LastError)
+	dl := DeadLetter{
+		Envelope:   *p.Envelope,
+		URL:        sub.URL,
+		Attempts:   p.Attempts,
+		LastStatus: p.LastStatus,
+		LastError:  p.LastError,
+		FailedAt:   d.now().UTC(),
+	}
+	if err := appendDeadLetter(d.townRoot, &dl); err != nil {
+		d.logger("webhooks: writing dead letter: %v", err)
+	}
+}
+
+func appendDeadLetter(townRoot string, dl *DeadLetter) error {
+	path := filepath.Join(townRoot, DeadLetterFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// ReadDeadLetters returns the dead-letter log, oldest first.
+func ReadDeadLetters(townRoot string) ([]DeadLetter, error) {
+	f, err := os.Open(filepath.Join(townRoot, DeadLetterFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening dead-letter log: %w", err)
+	}
+	defer f.Close()
+
+	var out []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var dl DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+			continue // Skip malformed lines
+		}
+		out = append(out, dl)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading dead-letter log: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,156 @@
+// Package webhook delivers town events to external HTTP endpoints.
+//
+// Subscriptions live in settings/webhooks.json. The daemon's Dispatcher tails
+// the raw events log (.events.jsonl) and POSTs each matching event as signed
+// JSON, retrying failed deliveries with exponential backoff. Deliveries that
+// exhaust their attempts are appended to a dead-letter log.
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ConfigFile is the subscriptions file, relative to the town root. It holds
+// signing secrets, so it is written 0600 and ignored by the HQ .gitignore.
+const ConfigFile = "settings/webhooks.json"
+
+// DefaultMaxAttempts is the number of delivery attempts before an event is
+// dead-lettered.
+const DefaultMaxAttempts = 6
+
+// Subscription sends events matching its filters to a URL.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"` // Event type patterns (glob); empty = all
+	Rig       string    `json:"rig,omitempty"`    // Only events concerning this rig
+	Secret    string    `json:"secret"`           // HMAC-SHA256 signing key
+	Disabled  bool      `json:"disabled,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Config is the town's webhook configuration.
+type Config struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+
+	// MaxAttempts bounds delivery attempts per event (0 = DefaultMaxAttempts).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+}
+
+// ConfigPath returns the path of the subscriptions file.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, ConfigFile)
+}
+
+// LoadConfig reads the webhook configuration. A missing file is an empty
+// configuration.
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot))
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading webhook config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing webhook config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// SaveConfig writes the webhook configuration.
+func SaveConfig(townRoot string, cfg *Config) error {
+	if err := util.EnsureDirAndWriteJSONWithPerm(ConfigPath(townRoot), cfg, 0600); err != nil {
+		return fmt.Errorf("writing webhook config: %w", err)
+	}
+	return nil
+}
+
+// Get returns the subscription with the given ID, or nil.
+func (c *Config) Get(id string) *Subscription {
+	for _, s := range c.Subscriptions {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// Add validates a subscription, fills in its ID and secret when unset, and
+// adds it to the configuration.
+func (c *Config) Add(s *Subscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: want http(s)://host/path", s.URL)
+	}
+	if s.ID == "" {
+		if s.ID, err = randomHex("wh-", 4); err != nil {
+			return err
+		}
+	}
+	if c.Get(s.ID) != nil {
+		return fmt.Errorf("webhook %s already exists", s.ID)
+	}
+	if s.Secret == "" {
+		if s.Secret, err = NewSecret(); err != nil {
+			return err
+		}
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	c.Subscriptions = append(c.Subscriptions, s)
+	return nil
+}
+
+// Remove deletes the subscription with the given ID, reporting whether it
+// existed.
+func (c *Config) Remove(id string) bool {
+	for i, s := range c.Subscriptions {
+		if s.ID == id {
+			c.Subscriptions = append(c.Subscriptions[:i], c.Subscriptions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) maxAttempts() int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// Matches reports whether the subscription wants the event.
+func (s *Subscription) Matches(e *events.Event) bool {
+	if s.Disabled {
+		return false
+	}
+	q := events.Query{Types: s.Events, Rig: s.Rig}
+	return q.Match(e)
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	return randomHex("whsec_", 24)
+}
+
+func randomHex(prefix string, n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random ID: %w", err)
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// receiver is a local HTTP endpoint that verifies signatures and records
+// deliveries, answering with the next queued status code (200 when empty).
+type receiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	got      []Envelope
+	attempts []string
+}
+
+func newReceiver(t *testing.T, secret string, statuses ...int) (*receiver, *httptest.Server) {
+	r := &receiver{t: t, secret: secret, statuses: statuses}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	if err := Verify(r.secret, req.Header, body, time.Minute); err != nil {
+		r.t.Errorf("delivery failed verification: %v", err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		r.t.Errorf("delivery body: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, env)
+	r.attempts = append(r.attempts, req.Header.Get(HeaderAttempt))
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) deliveries() []Envelope {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Envelope(nil), r.got...)
+}
+
+func appendEvent(t *testing.T, townRoot, eventType, actor string, payload map[string]interface{}) {
+	t.Helper()
+	data, _ := json.Marshal(events.Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: events.VisibilityFeed,
+	})
+	f, err := os.OpenFile(filepath.Join(townRoot, events.EventsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupTown(t *testing.T, subs ...*Subscription) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	cfg := &Config{MaxAttempts: 3}
+	for _, s := range subs {
+		if err := cfg.Add(s); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if err := SaveConfig(townRoot, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	// Existing history is not replayed to new subscriptions.
+	appendEvent(t, townRoot, events.TypeMerged, "gastown/refinery", map[string]interface{}{"mr": "gt-old"})
+	return townRoot
+}
+
+func newTestDispatcher(t *testing.T, townRoot string) *Dispatcher {
+	d := NewDispatcher(townRoot, t.Logf)
+	d.BaseBackoff = 0
+	d.MaxBackoff = 0
+	return d
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"whd-1"}`)
+	ts := time.Now().Unix()
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "0")
+	header.Set(HeaderSignature, Sign("s3cret", 0, body))
+	if err := Verify("s3cret", header, body, 0); err != nil {
+		t.Errorf("Verify without tolerance: %v", err)
+	}
+	if err := Verify("s3cret", header, body, time.Minute); err == nil {
+		t.Error("Verify accepted a stale signature")
+	}
+
+	header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	header.Set(HeaderSignature, Sign("s3cret", ts, body))
+	if err := Verify("s3cret", header, body, time.Minute); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := Verify("other", header, body, time.Minute); err == nil {
+		t.Error("Verify accepted the wrong secret")
+	}
+	if err := Verify("s3cret", header, []byte(`{"id":"whd-2"}`), time.Minute); err == nil {
+		t.Error("Verify accepted a tampered body")
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	sub := &Subscription{Events: []string{events.TypeMerged, "escalation_*"}, Rig: "gastown"}
+	tests := []struct {
+		e    events.Event
+		want bool
+	}{
+		{events.Event{Type: events.TypeMerged, Actor: "gastown/refinery"}, true},
+		{events.Event{Type: events.TypeEscalationSent, Actor: "mayor", Payload: map[string]interface{}{"rig": "gastown"}}, true},
+		{events.Event{Type: events.TypeMerged, Actor: "beads/refinery"}, false},
+		{events.Event{Type: events.TypeSling, Actor: "gastown/refinery"}, false},
+	}
+	for _, tt := range tests {
+		if got := sub.Matches(&tt.e); got != tt.want {
+			t.Errorf("Matches(%s by %s) = %v, want %v", tt.e.Type, tt.e.Actor, got, tt.want)
+		}
+	}
+	sub.Disabled = true
+	if sub.Matches(&tests[0].e) {
+		t.Error("disabled subscription matched")
+	}
+}
+
+func TestConfigAdd(t *testing.T) {
+	cfg := &Config{}
+	if err := cfg.Add(&Subscription{URL: "ftp://example.com"}); err == nil {
+		t.Error("Add accepted a non-HTTP URL")
+	}
+	sub := &Subscription{URL: "https://example.com/hook"}
+	if err := cfg.Add(sub); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if sub.ID == "" || sub.Secret == "" || sub.CreatedAt.IsZero() {
+		t.Errorf("Add did not fill defaults: %+v", sub)
+	}
+	if !cfg.Remove(sub.ID) || cfg.Remove(sub.ID) {
+		t.Error("Remove should succeed once")
+	}
+}
+
+func TestDispatcher_DeliversMatchingEvents(t *testing.T) {
+	recv, srv := newReceiver(t, "s3cret")
+	townRoot := setupTown(t, &Subscription{ID: "wh-1", URL: srv.URL, Events: []string{events.TypeMerged}, Rig: "gastown", Secret: "s3cret"})
+	d := newTestDispatcher(t, townRoot)
+
+	if err := d.Poll(); err != nil { // Starts at the end of the log
+		t.Fatalf("Poll: %v", err)
+	}
+	appendEvent(t, townRoot, events.TypeMerged, "gastown/refinery", map[string]interface{}{"mr": "gt-mr-1"})
+	appendEvent(t, townRoot, events.TypeMerged, "beads/refinery", map[string]interface{}{"mr": "bd-mr-1"})
+	appendEvent(t, townRoot, events.TypeSling, "mayor", map[string]interface{}{"target": "gastown/polecats/Toast"})
+	if err := d.Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	got := recv.deliveries()
+	if len(got) != 1 {
+		t.Fatalf("got %d deliveries, want 1: %+v", len(got), got)
+	}
+	if got[0].Webhook != "wh-1" || got[0].Event.Payload["mr"] != "gt-mr-1" || got[0].ID == "" {
+		t.Errorf("unexpected delivery %+v", got[0])
+	}
+
+	// A restarted dispatcher resumes from the saved offset.
+	appendEvent(t, townRoot, events.TypeMerged, "gastown/refinery", map[string]interface{}{"mr": "gt-mr-2"})
+	if err := newTestDispatcher(t, townRoot).Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if got := recv.deliveries(); len(got) != 2 || got[1].Event.Payload["mr"] != "gt-mr-2" {
+		t.Errorf("after restart got %+v", got)
+	}
+}
+
+func TestDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	recv, srv := newReceiver(t, "s3cret", 503, 503, 503, 500)
+	townRoot := setupTown(t, &Subscription{ID: "wh-1", URL: srv.URL, Secret: "s3cret"})
+	d := newTestDispatcher(t, townRoot)
+	if err := d.Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	appendEvent(t, townRoot, events.TypeEscalationSent, "mayor", nil)
+	for i := 0; i < 3; i++ {
+		if err := d.Poll(); err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+	}
+	got := recv.deliveries()
+	if len(got) != 3 {
+		t.Fatalf("got %d attempts, want 3 (MaxAttempts)", len(got))
+	}
+	if got[0].ID != got[2].ID {
+		t.Error("delivery ID changed across retries")
+	}
+	if recv.attempts[2] != "3" {
+		t.Errorf("third attempt header = %q", recv.attempts[2])
+	}
+
+	dead, err := ReadDeadLetters(townRoot)
+	if err != nil {
+		t.Fatalf("ReadDeadLetters: %v", err)
+	}
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastStatus != 503 || dead[0].Event.Type != events.TypeEscalationSent {
+		t.Errorf("dead letters = %+v", dead)
+	}
+	if len(d.state.Pending) != 0 {
+		t.Errorf("pending after dead-lettering: %d", len(d.state.Pending))
+	}
+}
+
+func TestDispatcher_RejectedIsNotRetried(t *testing.T) {
+	recv, srv := newReceiver(t, "s3cret", http.StatusUnauthorized)
+	townRoot := setupTown(t, &Subscription{ID: "wh-1", URL: srv.URL, Secret: "s3cret"})
+	d := newTestDispatcher(t, townRoot)
+	_ = d.Poll()
+
+	appendEvent(t, townRoot, events.TypeMerged, "gastown/refinery", nil)
+	_ = d.Poll()
+	_ = d.Poll()
+
+	if n := len(recv.deliveries()); n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+	dead, _ := ReadDeadLetters(townRoot)
+	if len(dead) != 1 || dead[0].LastStatus != http.StatusUnauthorized {
+		t.Errorf("dead letters = %+v", dead)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(t.TempDir(), t.Logf)
+	d.BaseBackoff = time.Second
+	d.MaxBackoff = 5 * time.Second
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := d.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
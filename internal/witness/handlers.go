@@ -11,6 +11,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/nudge"
@@ -306,6 +307,10 @@ func HandleMerged(workDir, rigName string, msg *mail.Message) *HandlerResult {
 	// The work is confirmed merged at this point, so convoys tracking this issue
 	// can potentially close even if polecat cleanup is blocked.
 	if onMain && payload.IssueID != "" {
+		// Record the landing; convoy ETAs learn cycle times from these.
+		_ = events.LogFeed(events.TypeMerged, rigName+"/witness",
+			events.MergedPayload(payload.IssueID, rigName, payload.PolecatName, payload.Branch))
+
 		townRoot, _ := workspace.Find(workDir)
 		if townRoot != "" {
 			convoy.CheckConvoysForIssue(townRoot, payload.IssueID, "witness", nil)
@@ -400,12 +405,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit with 'gt done'.`,
 			payload.Branch,
 			payload.IssueID,
 			payload.FailureType,
 			payload.Error,
+			formatMergeFailureOutput(payload),
 		),
 	}
 
@@ -421,6 +427,19 @@ Please fix the issue and resubmit with 'gt done'.`,
 	return result
 }
 
+// formatMergeFailureOutput renders the failing stage's log location and
+// output for the polecat. Returns an empty string when there is none.
+func formatMergeFailureOutput(payload *MergeFailedPayload) string {
+	var sb strings.Builder
+	if payload.LogPath != "" {
+		sb.WriteString(fmt.Sprintf("Full log: %s\n", payload.LogPath))
+	}
+	if payload.Output != "" {
+		sb.WriteString(fmt.Sprintf("\nOutput of the %s stage:\n%s\n", payload.Stage, payload.Output))
+	}
+	return sb.String()
+}
+
 // HandleSwarmStart processes a SWARM_START message from the Mayor.
 // Creates a swarm tracking wisp to monitor batch polecat work.
 func HandleSwarmStart(workDir string, msg *mail.Message) *HandlerResult {
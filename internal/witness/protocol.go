@@ -95,6 +95,9 @@ type MergeFailedPayload struct {
 	IssueID     string
 	FailureType string // "build", "test", "lint", etc.
 	Error       string
+	Stage       string // Verification stage that failed, if any
+	LogPath     string // Where the Refinery stored the stage logs
+	Output      string // Tail of the failing stage's output
 	FailedAt    time.Time
 }
 
@@ -247,6 +250,9 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 //	Issue: <issue-id>
 //	FailureType: <type>
 //	Error: <error-message>
+//	Stage: <stage>          (optional)
+//	Log: <path>             (optional)
+//	Output:                 (optional, rest of body)
 func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 	matches := PatternMergeFailed.FindStringSubmatch(subject)
 	if len(matches) < 2 {
@@ -258,9 +264,15 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 		FailedAt:    time.Now(),
 	}
 
-	// Parse body for structured fields
-	for _, line := range strings.Split(body, "\n") {
+	// Parse body for structured fields. A trailing "Output:" block carries
+	// the failing stage's output verbatim.
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
+		if line == "Output:" {
+			payload.Output = strings.TrimRight(strings.Join(lines[i+1:], "\n"), "\n")
+			break
+		}
 		switch {
 		case strings.HasPrefix(line, "Branch:"):
 			payload.Branch = strings.TrimSpace(strings.TrimPrefix(line, "Branch:"))
@@ -270,6 +282,10 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "FailureType:"))
 		case strings.HasPrefix(line, "Error:"):
 			payload.Error = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
+		case strings.HasPrefix(line, "Stage:"):
+			payload.Stage = strings.TrimSpace(strings.TrimPrefix(line, "Stage:"))
+		case strings.HasPrefix(line, "Log:"):
+			payload.LogPath = strings.TrimSpace(strings.TrimPrefix(line, "Log:"))
 		}
 	}
 
@@ -193,6 +193,31 @@ Error: unit tests failed with 3 errors`
 	}
 }
 
+func TestParseMergeFailed_StageOutput(t *testing.T) {
+	body := `Branch: feature-nux
+Failure-Type: build
+Error: build failed: exit status 1
+Stage: build
+Log: /rig/.runtime/refinery/verify/gt-mr1/01-build.log
+Output:
+./main.go:10:2: undefined: foo
+Error: not a header`
+
+	payload, err := ParseMergeFailed("MERGE_FAILED nux", body)
+	if err != nil {
+		t.Fatalf("ParseMergeFailed() error = %v", err)
+	}
+	if payload.Stage != "build" || payload.LogPath != "/rig/.runtime/refinery/verify/gt-mr1/01-build.log" {
+		t.Errorf("Stage = %q, LogPath = %q", payload.Stage, payload.LogPath)
+	}
+	if payload.Error != "build failed: exit status 1" {
+		t.Errorf("Error = %q; output lines must not override headers", payload.Error)
+	}
+	if payload.Output != "./main.go:10:2: undefined: foo\nError: not a header" {
+		t.Errorf("Output = %q", payload.Output)
+	}
+}
+
 func TestParseMergeFailed_MinimalBody(t *testing.T) {
 	subject := "MERGE_FAILED ace"
 	body := "FailureType: build"